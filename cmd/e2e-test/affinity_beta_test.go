@@ -30,7 +30,6 @@ import (
 	"k8s.io/ingress-gce/pkg/e2e"
 	"k8s.io/ingress-gce/pkg/e2e/adapter"
 	"k8s.io/ingress-gce/pkg/fuzz"
-	"k8s.io/ingress-gce/pkg/fuzz/features"
 )
 
 func TestAffinityBeta(t *testing.T) {
@@ -96,7 +95,7 @@ func TestAffinityBeta(t *testing.T) {
 		vip := ing.Status.LoadBalancer.Ingress[0].IP
 		t.Logf("Ingress %s VIP = %s", ingKey, vip)
 
-		params := &fuzz.GCLBForVIPParams{VIP: vip, Validators: fuzz.FeatureValidators(features.All)}
+		params := &fuzz.GCLBForVIPParams{VIP: vip, Validators: fuzz.FeatureValidators(fuzz.RegisteredFeatures())}
 		gclb, err := fuzz.GCLBForVIP(context.Background(), Framework.Cloud, params)
 		if err != nil {
 			t.Fatalf("fuzz.GCLBForVIP(_, _, %q) = %v, want nil; fail to get GCP resources for LB with IP(%q)", vip, err, vip)
@@ -151,7 +150,7 @@ func TestAffinityBeta(t *testing.T) {
 			SkipDefaultBackend: true,
 		}
 		t.Logf("Waiting for GCLB resources to be deleted (%s)", ingKey)
-		if err := e2e.WaitForGCLBDeletion(ctx, Framework.Cloud, gclb, deleteOptions); err != nil {
+		if err := e2e.WaitForGCLBDeletion(ctx, Framework.Cloud, s, gclb, deleteOptions); err != nil {
 			t.Errorf("e2e.WaitForGCLBDeletion(_, _, %q, %#v) = %v, want nil", gclb.VIP, deleteOptions, err)
 		}
 		t.Logf("GCLB resources deleted (%s)", ingKey)
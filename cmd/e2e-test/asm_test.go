@@ -168,7 +168,7 @@ func TestASMServiceAndDestinationRule(t *testing.T) {
 					}
 				} else {
 					if negName, ok := negStatus.NetworkEndpointGroups[strconv.Itoa(int(porterPort))]; ok {
-						if err := e2e.WaitForNegs(ctx, Framework.Cloud, negName, negStatus.Zones, false, 6); err != nil {
+						if err := e2e.WaitForNegs(ctx, Framework.Cloud, s, negName, negStatus.Zones, false, 6); err != nil {
 							t.Errorf("Failed to wait Negs, error: %s", err)
 						}
 					} else {
@@ -220,7 +220,7 @@ func TestASMServiceAndDestinationRule(t *testing.T) {
 						if !ok {
 							t.Fatalf("DestinationRule annotation doesn't contain the desired NEG status, want: %d, have: %v", porterPort, negNames)
 						}
-						if err := e2e.WaitForNegs(ctx, Framework.Cloud, negName, zones, false, endpointCount); err != nil {
+						if err := e2e.WaitForNegs(ctx, Framework.Cloud, s, negName, zones, false, endpointCount); err != nil {
 							t.Errorf("Failed to wait Negs, error: %s", err)
 						}
 					}
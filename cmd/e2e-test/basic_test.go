@@ -20,8 +20,10 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/networking/v1"
+	compositemetrics "k8s.io/ingress-gce/pkg/composite/metrics"
 	"k8s.io/ingress-gce/pkg/e2e"
 	"k8s.io/ingress-gce/pkg/e2e/adapter"
 	"k8s.io/ingress-gce/pkg/fuzz"
@@ -67,7 +69,7 @@ func testBasicOS(t *testing.T, os e2e.OS) {
 		},
 	} {
 		tc := tc // Capture tc as we are running this in parallel.
-		Framework.RunWithSandbox(tc.desc, t, func(t *testing.T, s *e2e.Sandbox) {
+		Framework.RunWithSandboxFixed(tc.desc, t, func(t *testing.T, s *e2e.Sandbox) {
 			t.Parallel()
 
 			ctx := context.Background()
@@ -78,12 +80,11 @@ func testBasicOS(t *testing.T, os e2e.OS) {
 			}
 			t.Logf("Echo service created (%s/%s)", s.Namespace, "service-1")
 
-			crud := adapter.IngressCRUD{C: Framework.Clientset}
 			tc.ing.Namespace = s.Namespace // namespace depends on sandbox
-			if _, err = crud.Create(tc.ing); err != nil {
-				t.Fatalf("error creating Ingress spec: %v", err)
+			if _, err = e2e.EnsureIngress(s, tc.ing); err != nil {
+				t.Fatalf("error ensuring Ingress spec: %v", err)
 			}
-			t.Logf("Ingress created (%s/%s)", s.Namespace, tc.ing.Name)
+			t.Logf("Ingress ensured (%s/%s)", s.Namespace, tc.ing.Name)
 
 			ing, err := e2e.WaitForIngress(s, tc.ing, nil, nil)
 			if err != nil {
@@ -97,6 +98,10 @@ func testBasicOS(t *testing.T, os e2e.OS) {
 				t.Fatalf("e2e.WhiteboxTest(%s/%s, ...) = %v, want nil", ing.Namespace, ing.Name, err)
 			}
 
+			if s.Adopt() {
+				t.Logf("Keeping Ingress %s/%s to be adopted by the next run", ing.Namespace, ing.Name)
+				return
+			}
 			deleteOptions := &fuzz.GCLBDeleteOptions{
 				SkipDefaultBackend: true,
 			}
@@ -107,6 +112,63 @@ func testBasicOS(t *testing.T, os e2e.OS) {
 	}
 }
 
+// TestBasicAPICallBudget checks the number of GCE API calls the controller
+// makes to create an Ingress and to resync it once it is in sync. It does
+// not run in parallel, as the calls of the controller cover all Ingresses.
+func TestBasicAPICallBudget(t *testing.T) {
+	Framework.RunWithSandbox("api-call-budget", t, func(t *testing.T, s *e2e.Sandbox) {
+		ctx := context.Background()
+
+		if _, err := e2e.CreateEchoService(s, "service-1", nil); err != nil {
+			t.Fatalf("error creating echo service: %v", err)
+		}
+		ing := fuzz.NewIngressBuilder(s.Namespace, "ingress-1", "").
+			AddPath("test.com", "/", "service-1", v1.ServiceBackendPort{Number: 80}).
+			Build()
+
+		// The frontend and the backend of the service are created once. The
+		// default backend may be created as well.
+		Framework.ExpectControllerAPICallBudget(t, compositemetrics.Budget{
+			"BackendService_create":  2,
+			"UrlMap_create":          1,
+			"TargetHttpProxy_create": 1,
+			"ForwardingRule_create":  1,
+		}, func() {
+			if _, err := e2e.EnsureIngress(s, ing); err != nil {
+				t.Fatalf("error ensuring Ingress spec: %v", err)
+			}
+			var err error
+			if ing, err = e2e.WaitForIngress(s, ing, nil, nil); err != nil {
+				t.Fatalf("error waiting for Ingress to stabilize: %v", err)
+			}
+		})
+
+		// Resyncs of an Ingress which is in sync do not modify anything. The
+		// controller resyncs every 30s by default.
+		Framework.ExpectControllerAPICallBudget(t, compositemetrics.Budget{
+			"BackendService_create":  0,
+			"BackendService_update":  0,
+			"HealthCheck_update":     0,
+			"UrlMap_update":          0,
+			"TargetHttpProxy_create": 0,
+			"ForwardingRule_create":  0,
+		}, func() {
+			time.Sleep(2 * time.Minute)
+		})
+
+		gclb, err := e2e.WhiteboxTest(ing, nil, Framework.Cloud, "", s)
+		if err != nil {
+			t.Fatalf("e2e.WhiteboxTest(%s/%s, ...) = %v, want nil", ing.Namespace, ing.Name, err)
+		}
+		deleteOptions := &fuzz.GCLBDeleteOptions{
+			SkipDefaultBackend: true,
+		}
+		if err := e2e.WaitForIngressDeletion(ctx, gclb, s, ing, deleteOptions); err != nil {
+			t.Errorf("e2e.WaitForIngressDeletion(..., %q, nil) = %v, want nil", ing.Name, err)
+		}
+	})
+}
+
 // TestBasicStaticIP tests that the static-ip annotation works as expected.
 func TestBasicStaticIP(t *testing.T) {
 	ctx := context.Background()
@@ -301,7 +363,7 @@ func TestFrontendResourceDeletion(t *testing.T) {
 				CheckHttpsFrontendResources: tc.disableHTTPS,
 			}
 			// Wait for unused frontend resources to be deleted.
-			if err := e2e.WaitForFrontendResourceDeletion(ctx, Framework.Cloud, gclb, deleteOptions); err != nil {
+			if err := e2e.WaitForFrontendResourceDeletion(ctx, Framework.Cloud, s, gclb, deleteOptions); err != nil {
 				t.Errorf("e2e.WaitForFrontendResourceDeletion(..., %q, _) = %v, want nil", ingKey, err)
 			}
 			if gclb, err = e2e.WhiteboxTest(ing, nil, Framework.Cloud, "", s); err != nil {
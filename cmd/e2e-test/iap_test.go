@@ -18,6 +18,8 @@ package main
 
 import (
 	"context"
+	"io/ioutil"
+	"strings"
 	"testing"
 
 	v1 "k8s.io/api/networking/v1"
@@ -26,27 +28,42 @@ import (
 	"k8s.io/ingress-gce/pkg/e2e"
 	"k8s.io/ingress-gce/pkg/e2e/adapter"
 	"k8s.io/ingress-gce/pkg/fuzz"
-	"k8s.io/ingress-gce/pkg/fuzz/features"
 )
 
 // TODO(rramkumar): Add transition test.
 
-// TODO(rramkumar): Add test for positive case.
+type iapTestCase struct {
+	desc     string
+	beConfig *backendconfig.BackendConfig
+}
 
 func TestIAP(t *testing.T) {
 	t.Parallel()
 
-	for _, tc := range []struct {
-		desc     string
-		beConfig *backendconfig.BackendConfig
-	}{
+	testCases := []iapTestCase{
 		{
 			desc: "http one path w/ IAP.",
 			beConfig: fuzz.NewBackendConfigBuilder("", "backendconfig-1").
 				SetIAPConfig(false, "").
 				Build(),
 		},
-	} {
+	}
+	// The IAP validator checks that unauthenticated requests are redirected
+	// to the sign-in page and that the service account of the tests passes.
+	if flags.iapClientID != "" {
+		secret, err := ioutil.ReadFile(flags.iapClientSecretFile)
+		if err != nil {
+			t.Fatalf("Error reading -iapClientSecretFile: %v", err)
+		}
+		beConfig := fuzz.NewBackendConfigBuilder("", "backendconfig-1").
+			SetIAPConfig(true, "").
+			Build()
+		beConfig.Spec.Iap.OAuthClientCredentials.ClientID = flags.iapClientID
+		beConfig.Spec.Iap.OAuthClientCredentials.ClientSecret = strings.TrimSpace(string(secret))
+		testCases = append(testCases, iapTestCase{desc: "http one path w/ IAP enabled.", beConfig: beConfig})
+	}
+
+	for _, tc := range testCases {
 		tc := tc // Capture tc as we are running this in parallel.
 		Framework.RunWithSandbox(tc.desc, t, func(t *testing.T, s *e2e.Sandbox) {
 			t.Parallel()
@@ -87,7 +104,7 @@ func TestIAP(t *testing.T) {
 
 			vip := ing.Status.LoadBalancer.Ingress[0].IP
 			t.Logf("Ingress %s/%s VIP = %s", s.Namespace, ing.Name, vip)
-			params := &fuzz.GCLBForVIPParams{VIP: vip, Validators: fuzz.FeatureValidators(features.All)}
+			params := &fuzz.GCLBForVIPParams{VIP: vip, Validators: fuzz.FeatureValidators(fuzz.RegisteredFeatures())}
 			gclb, err := fuzz.GCLBForVIP(context.Background(), Framework.Cloud, params)
 			if err != nil {
 				t.Fatalf("Error getting GCP resources for LB with IP = %q: %v", vip, err)
@@ -102,7 +119,7 @@ func TestIAP(t *testing.T) {
 				SkipDefaultBackend: true,
 			}
 			t.Logf("Waiting for GCLB resources to be deleted (%s/%s)", s.Namespace, ing.Name)
-			if err := e2e.WaitForGCLBDeletion(ctx, Framework.Cloud, gclb, deleteOptions); err != nil {
+			if err := e2e.WaitForGCLBDeletion(ctx, Framework.Cloud, s, gclb, deleteOptions); err != nil {
 				t.Errorf("e2e.WaitForGCLBDeletion(...) = %v, want nil", err)
 			}
 			t.Logf("GCLB resources deleted (%s/%s)", s.Namespace, ing.Name)
@@ -28,6 +28,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/ingress-gce/pkg/e2e"
+	"k8s.io/ingress-gce/pkg/fuzz/features"
 	_ "k8s.io/ingress-gce/pkg/klog"
 	"k8s.io/ingress-gce/pkg/version"
 	"k8s.io/klog"
@@ -47,9 +48,14 @@ var (
 		network             string
 		seed                int64
 		destroySandboxes    bool
+		adoptSandboxes      bool
 		handleSIGINT        bool
 		gceEndpointOverride string
 		createILBSubnet     bool
+		iapClientID         string
+		iapClientSecretFile string
+		waitTimeoutScale    float64
+		controllerMetrics   string
 	}
 
 	Framework *e2e.Framework
@@ -69,9 +75,14 @@ func init() {
 	flag.StringVar(&flags.network, "network", "", "GCP network name (e.g. default)")
 	flag.Int64Var(&flags.seed, "seed", -1, "random seed")
 	flag.BoolVar(&flags.destroySandboxes, "destroySandboxes", true, "set to false to leave sandboxed resources for debugging")
+	flag.BoolVar(&flags.adoptSandboxes, "adoptSandboxes", false, "set to true to keep the fixed sandboxes and their GCP resources after the tests and adopt them on the next run")
 	flag.BoolVar(&flags.handleSIGINT, "handleSIGINT", true, "catch SIGINT to perform clean")
 	flag.StringVar(&flags.gceEndpointOverride, "gce-endpoint-override", "", "If set, talks to a different GCE API Endpoint. By default it talks to https://www.googleapis.com/compute/v1/")
 	flag.BoolVar(&flags.createILBSubnet, "createILBSubnet", false, "If set, creates a proxy subnet for the L7 ILB")
+	flag.StringVar(&flags.iapClientID, "iapClientID", "", "If set, OAuth client ID used to test IAP. The service account of the tests must be allowed by IAP")
+	flag.StringVar(&flags.iapClientSecretFile, "iapClientSecretFile", "", "path to the file containing the OAuth client secret of -iapClientID")
+	flag.Float64Var(&flags.waitTimeoutScale, "waitTimeoutScale", 1, "multiplies the timeouts of the wait helpers, e.g. 0.5 to fail faster or 2 for slow projects")
+	flag.StringVar(&flags.controllerMetrics, "controllerMetricsURL", "", "If set, URL of the metrics endpoint of the controller (e.g. http://localhost:8081/metrics), used to check its GCE API call budget")
 }
 
 // TestMain is the entrypoint for the end-to-end test suite. This is where
@@ -122,14 +133,21 @@ func TestMain(m *testing.M) {
 	klog.Infof("Using random seed = %d", flags.seed)
 
 	Framework = e2e.NewFramework(kubeconfig, e2e.Options{
-		Project:             flags.project,
-		Region:              flags.region,
-		Network:             flags.network,
-		Seed:                flags.seed,
-		DestroySandboxes:    flags.destroySandboxes,
-		GceEndpointOverride: flags.gceEndpointOverride,
-		CreateILBSubnet:     flags.createILBSubnet,
+		Project:              flags.project,
+		Region:               flags.region,
+		Network:              flags.network,
+		Seed:                 flags.seed,
+		DestroySandboxes:     flags.destroySandboxes,
+		AdoptSandboxes:       flags.adoptSandboxes,
+		GceEndpointOverride:  flags.gceEndpointOverride,
+		CreateILBSubnet:      flags.createILBSubnet,
+		WaitConfigs:          e2e.ScaledWaitConfigs(flags.waitTimeoutScale),
+		ControllerMetricsURL: flags.controllerMetrics,
 	})
+	if flags.iapClientID != "" {
+		// Check that the service account of the tests passes IAP.
+		features.IAP.IDToken = features.MetadataIDToken
+	}
 	if flags.handleSIGINT {
 		Framework.CatchSIGINT()
 	}
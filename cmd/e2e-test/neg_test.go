@@ -216,7 +216,7 @@ func TestNEGTransition(t *testing.T) {
 					if len(gclb.NetworkEndpointGroup) != 0 {
 						t.Errorf("NegGC = true, expected 0 negs for gclb %v, got %d", gclb, len(gclb.NetworkEndpointGroup))
 					}
-					if err = e2e.WaitForNEGDeletion(ctx, s.ValidatorEnv.Cloud(), previousGCLBState, nil); err != nil {
+					if err = e2e.WaitForNEGDeletion(ctx, s.ValidatorEnv.Cloud(), s, previousGCLBState, nil); err != nil {
 						t.Errorf("Error waiting for NEGDeletion: %v", err)
 					}
 				} else {
@@ -333,7 +333,7 @@ func TestNEGSyncEndpoints(t *testing.T) {
 					t.Logf("GCLB resources created (%s/%s)", s.Namespace, ing.Name)
 					vip := ing.Status.LoadBalancer.Ingress[0].IP
 					t.Logf("Ingress %s/%s VIP = %s", s.Namespace, ing.Name, vip)
-					if err = e2e.WaitForDistinctHosts(ctx, vip, int(replicas), true); err != nil {
+					if err = e2e.WaitForDistinctHosts(ctx, s, vip, int(replicas), true); err != nil {
 						t.Errorf("error waiting for Ingress to response from %v backends: %v", replicas, err)
 					}
 				}
@@ -347,9 +347,9 @@ func TestNEGSyncEndpoints(t *testing.T) {
 				// validate neg configurations
 				for port, negName := range negStatus.NetworkEndpointGroups {
 					if tc.expectHealthyServicePort.Has(port) {
-						e2e.WaitForNegs(ctx, Framework.Cloud, negName, negStatus.Zones, true, int(replicas))
+						e2e.WaitForNegs(ctx, Framework.Cloud, s, negName, negStatus.Zones, true, int(replicas))
 					} else if tc.expectServicePort.Has(port) {
-						e2e.WaitForNegs(ctx, Framework.Cloud, negName, negStatus.Zones, false, int(replicas))
+						e2e.WaitForNegs(ctx, Framework.Cloud, s, negName, negStatus.Zones, false, int(replicas))
 					} else {
 						t.Errorf("Unexpected port %v and NEG %q in NEG Status %v", port, negName, negStatus)
 					}
@@ -468,7 +468,7 @@ func TestNegCRDTransitions(t *testing.T) {
 				}
 
 				for port, negName := range negStatus.NetworkEndpointGroups {
-					err := e2e.WaitForNegs(ctx, Framework.Cloud, negName, negStatus.Zones, false, int(tc.replicas))
+					err := e2e.WaitForNegs(ctx, Framework.Cloud, s, negName, negStatus.Zones, false, int(tc.replicas))
 					if err != nil {
 						t.Fatalf("Error: e2e.WaitForNegs service %s/%s neg port/name %s/%s", serviceName, s.Namespace, port, negName)
 					}
@@ -523,7 +523,7 @@ func TestNegCRDErrorEvents(t *testing.T) {
 		}
 
 		for port, negName := range negStatus.NetworkEndpointGroups {
-			err := e2e.WaitForNegs(ctx, Framework.Cloud, negName, negStatus.Zones, false, int(replicas))
+			err := e2e.WaitForNegs(ctx, Framework.Cloud, s, negName, negStatus.Zones, false, int(replicas))
 			if err != nil {
 				t.Fatalf("Error: e2e.WaitForNegs service %s/%s neg port/name %s/%s", svc1, s.Namespace, port, negName)
 			}
@@ -586,7 +586,7 @@ func TestNegDisruptive(t *testing.T) {
 			t.Fatalf("Error: e2e.WaitForNegCRs(%s,%+v) = %s, want nil", gcSvcName, expectedNegAttrs, err)
 		}
 		for port, negName := range negStatus.NetworkEndpointGroups {
-			err := e2e.WaitForNegs(ctx, Framework.Cloud, negName, negStatus.Zones, false, int(replicas))
+			err := e2e.WaitForNegs(ctx, Framework.Cloud, s, negName, negStatus.Zones, false, int(replicas))
 			if err != nil {
 				t.Fatalf("Error: e2e.WaitForNegs service %s/%s neg port/name %s/%s", gcSvcName, s.Namespace, port, negName)
 			}
@@ -725,7 +725,7 @@ func TestNegDisruptive(t *testing.T) {
 				}
 
 				for port, negName := range negStatus.NetworkEndpointGroups {
-					err := e2e.WaitForNegs(ctx, Framework.Cloud, negName, negStatus.Zones, false, int(replicas))
+					err := e2e.WaitForNegs(ctx, Framework.Cloud, s, negName, negStatus.Zones, false, int(replicas))
 					if err != nil {
 						t.Fatalf("Error: e2e.WaitForNegs service %s/%s neg port/name %s/%s", serviceName, s.Namespace, port, negName)
 					}
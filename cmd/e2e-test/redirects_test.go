@@ -130,7 +130,7 @@ func TestHttpsRedirects(t *testing.T) {
 				// Check just for URL map deletion.  This URL map should have been created in another transition test case
 				// TODO(shance): uncomment this once GC has been fixed
 				//if tc.config != nil && !tc.config.Enabled {
-				//	if err := e2e.WaitForRedirectURLMapDeletion(ctx, Framework.Cloud, gclb); err != nil {
+				//	if err := e2e.WaitForRedirectURLMapDeletion(ctx, Framework.Cloud, s, gclb); err != nil {
 				//		t.Errorf("WaitForRedirectURLMapDeletion(%v) = %v", gclb, err)
 				//	}
 				//}
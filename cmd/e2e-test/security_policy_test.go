@@ -237,7 +237,7 @@ func TestSecurityPolicyTransition(t *testing.T) {
 
 				t.Logf("Waiting %v for security policy to be updated on relevant backend service", policyUpdateTimeout)
 				if err := wait.Poll(policyUpdateInterval, policyUpdateTimeout, func() (bool, error) {
-					params := &fuzz.GCLBForVIPParams{VIP: vip, Validators: fuzz.FeatureValidators(features.All)}
+					params := &fuzz.GCLBForVIPParams{VIP: vip, Validators: fuzz.FeatureValidators(fuzz.RegisteredFeatures())}
 					gclb, err = fuzz.GCLBForVIP(ctx, Framework.Cloud, params)
 					if err != nil {
 						t.Fatalf("fuzz.GCLBForVIP(..., %q, %q) = _, %v; want _, nil", vip, features.SecurityPolicy, err)
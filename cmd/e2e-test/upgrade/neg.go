@@ -187,7 +187,7 @@ func negValidate(t *testing.T, s *e2e.Sandbox, framework *e2e.Framework, replica
 	// validate neg configurations
 	for port, negName := range negStatus.NetworkEndpointGroups {
 		ctx := context.Background()
-		if err := e2e.WaitForNegs(ctx, framework.Cloud, negName, negStatus.Zones, false, int(replicas)); err != nil {
+		if err := e2e.WaitForNegs(ctx, framework.Cloud, s, negName, negStatus.Zones, false, int(replicas)); err != nil {
 			t.Errorf("Unexpected port %v and NEG %q in NEG Status %v", port, negName, negStatus)
 		}
 	}
@@ -95,6 +95,10 @@ func healthCheck(w http.ResponseWriter, r *http.Request) {
 }
 
 func echo(w http.ResponseWriter, r *http.Request) {
+	if isWebSocketUpgrade(r) {
+		serveWebSocket(w, r)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	setHeadersFromQueryString(w, r)
 
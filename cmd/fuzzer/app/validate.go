@@ -33,8 +33,9 @@ import (
 	"k8s.io/ingress-gce/pkg/e2e"
 	"k8s.io/ingress-gce/pkg/fuzz"
 	"k8s.io/ingress-gce/pkg/fuzz/features"
-	"k8s.io/ingress-gce/pkg/fuzz/whitebox"
 
+	// Register the built-in whitebox tests.
+	_ "k8s.io/ingress-gce/pkg/fuzz/whitebox"
 	// Pull in the auth library for GCP.
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -48,6 +49,10 @@ var (
 		listFeatures bool
 		featureRegex string
 		project      string
+		region       string
+		network      string
+		endpoint     string
+		iapAllowed   bool
 	}
 	// ValidateFlagSet is the flag set for the validate subcommand.
 	ValidateFlagSet = flag.NewFlagSet("validate", flag.ExitOnError)
@@ -64,6 +69,10 @@ func init() {
 	ValidateFlagSet.BoolVar(&validateOptions.listFeatures, "listFeatures", false, "list features available to be validated")
 	ValidateFlagSet.StringVar(&validateOptions.featureRegex, "featureRegex", "", "features matching regex will be included in validation")
 	ValidateFlagSet.StringVar(&validateOptions.project, "project", "", "GCP project where the load balancer will be created")
+	ValidateFlagSet.StringVar(&validateOptions.region, "region", "", "GCP region of the cluster, required for regional Ingress classes")
+	ValidateFlagSet.StringVar(&validateOptions.network, "network", "", "GCP network of the cluster, required for regional Ingress classes")
+	ValidateFlagSet.BoolVar(&validateOptions.iapAllowed, "checkIAPAllowed", false, "(optional) check that the service account of the metadata server passes IAP")
+	ValidateFlagSet.StringVar(&validateOptions.endpoint, "computeAPIEndpoint", "", "(optional) base URL of the GA compute API, defaults to the public one")
 
 	// Merges in the global flags into the subcommand FlagSet.
 	flag.VisitAll(func(f *flag.Flag) {
@@ -75,7 +84,7 @@ func init() {
 func Validate() {
 	if validateOptions.listFeatures {
 		fmt.Println("Feature names:")
-		for _, f := range features.All {
+		for _, f := range fuzz.RegisteredFeatures() {
 			fmt.Println(f.Name())
 		}
 		os.Exit(0)
@@ -93,17 +102,21 @@ func Validate() {
 		}
 	}
 
+	if validateOptions.iapAllowed {
+		features.IAP.IDToken = features.MetadataIDToken
+	}
+
 	config, err := clientcmd.BuildConfigFromFlags("", validateOptions.kubeconfig)
 	if err != nil {
 		panic(err.Error())
 	}
 
-	gce, err := e2e.NewCloud(validateOptions.project, "")
+	gce, err := e2e.NewCloud(validateOptions.project, validateOptions.endpoint)
 	if err != nil {
 		panic(err)
 	}
 
-	env, err := fuzz.NewDefaultValidatorEnv(config, validateOptions.ns, gce)
+	env, err := fuzz.NewDefaultValidatorEnv(config, validateOptions.ns, gce, validateOptions.region, validateOptions.network)
 
 	if err != nil {
 		panic(err)
@@ -111,10 +124,10 @@ func Validate() {
 
 	var fs []fuzz.Feature
 	if validateOptions.featureRegex == "" {
-		fs = features.All
+		fs = fuzz.RegisteredFeatures()
 	} else {
 		fregexp := regexp.MustCompile(validateOptions.featureRegex)
-		for _, f := range features.All {
+		for _, f := range fuzz.RegisteredFeatures() {
 			if fregexp.Match([]byte(f.Name())) {
 				fs = append(fs, f)
 			}
@@ -134,7 +147,7 @@ func Validate() {
 
 	fmt.Printf("Ingress =\n%s\n\n", pretty.Sprint(*ing))
 
-	iv, err := fuzz.NewIngressValidator(env, ing, nil, whitebox.AllTests, nil, fs)
+	iv, err := fuzz.NewIngressValidator(env, ing, nil, fuzz.RegisteredWhiteboxTests(), nil, fs)
 	if err != nil {
 		panic(err)
 	}
@@ -147,7 +160,7 @@ func Validate() {
 	}
 
 	vip := ing.Status.LoadBalancer.Ingress[0].IP
-	params := &fuzz.GCLBForVIPParams{VIP: vip, Validators: fuzz.FeatureValidators(features.All)}
+	params := fuzz.GCLBForVIPParamsForIngress(env, ing, vip, fuzz.FeatureValidators(fuzz.RegisteredFeatures()))
 	gclb, err := fuzz.GCLBForVIP(context.Background(), gce, params)
 	if err != nil {
 		panic(err)
@@ -18,12 +18,17 @@ package app
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	cloudprovider "k8s.io/cloud-provider"
@@ -34,7 +39,9 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 
+	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/flags"
+	"k8s.io/ingress-gce/pkg/multiproject"
 	"k8s.io/ingress-gce/pkg/ratelimit"
 	"k8s.io/ingress-gce/pkg/utils"
 )
@@ -67,59 +74,146 @@ func NewKubeConfig() (*rest.Config, error) {
 	return clientcmd.BuildConfigFromFlags(flags.F.APIServerHost, flags.F.KubeConfigFile)
 }
 
-// NewGCEClient returns a client to the GCE environment. This will block until
-// a valid configuration file can be read.
-func NewGCEClient() *gce.Cloud {
-	var configReader func() io.Reader
-	if flags.F.ConfigFilePath != "" {
-		klog.Infof("Reading config from path %q", flags.F.ConfigFilePath)
-		config, err := os.Open(flags.F.ConfigFilePath)
+// NewGCEClient returns a client to the GCE environment, and its rate limiter.
+// This will block until a valid configuration file can be read.
+// The settings and utilization of the rate limiter are exported as metrics.
+func NewGCEClient() (*gce.Cloud, *ratelimit.GCERateLimiter) {
+	config, err := readGCEConfig(flags.F.ConfigFilePath)
+	if err != nil {
+		klog.Fatalf("%v", err)
+	}
+	// Configure GCE rate limiting
+	rl, err := ratelimit.NewGCERateLimiter(flags.F.GCERateLimit.Values(), flags.F.GCEOperationPollInterval)
+	if err != nil {
+		klog.Fatalf("Error configuring rate limiting: %v", err)
+	}
+	prometheus.MustRegister(rl)
+	// Calls to resources of other projects share the limits of the cloud.
+	composite.SetProjectRateLimiter(rl)
+
+	// Creating the cloud interface involves resolving the metadata server to get
+	// an oauth token. If this fails, the token provider assumes it's not on GCE.
+	// No errors are thrown. So we need to keep retrying till it works because
+	// we know we're on GCE.
+	for {
+		cloud, err := newGCEClient(config, rl)
+		if err == nil {
+			return cloud, rl
+		}
+		klog.Warningf("%v, retrying", err)
+		time.Sleep(cloudClientRetryInterval)
+	}
+}
+
+// NewProjectRouter returns the router which maps namespaces to the clouds of
+// their projects, as configured by the namespace project config flag. The
+// clouds of other projects share the rate limiter rl of the given cloud, so
+// that all calls of the controller are within the configured limits, and
+// exported in its metrics.
+func NewProjectRouter(cloud *gce.Cloud, rl *ratelimit.GCERateLimiter) *multiproject.Router {
+	if flags.F.NamespaceProjectConfigPath == "" {
+		return multiproject.NewSingleProjectRouter(cloud)
+	}
+	klog.Infof("Reading namespace project config from path %q", flags.F.NamespaceProjectConfigPath)
+	f, err := os.Open(flags.F.NamespaceProjectConfigPath)
+	if err != nil {
+		klog.Fatalf("%v", err)
+	}
+	defer f.Close()
+	config, err := multiproject.LoadConfig(f)
+	if err != nil {
+		klog.Fatalf("Error while reading namespace project config (%q): %v", flags.F.NamespaceProjectConfigPath, err)
+	}
+	router, err := multiproject.NewRouter(cloud, config, func(project multiproject.ProjectConfig) (*gce.Cloud, error) {
+		if project.ConfigFilePath == "" {
+			return nil, fmt.Errorf("configFilePath must be set for project %q", project.ProjectID)
+		}
+		config, err := readGCEConfig(project.ConfigFilePath)
 		if err != nil {
-			klog.Fatalf("%v", err)
+			return nil, err
+		}
+		return newGCEClient(config, rl)
+	})
+	if err != nil {
+		klog.Fatalf("Error while creating project router: %v", err)
+	}
+	return router
+}
+
+// readGCEConfig returns the gce config read from the given file, with the
+// compute API endpoint of the flags if any. It returns nil if no file is
+// given.
+func readGCEConfig(configFilePath string) ([]byte, error) {
+	var allConfig []byte
+	if configFilePath != "" {
+		klog.Infof("Reading config from path %q", configFilePath)
+		config, err := os.Open(configFilePath)
+		if err != nil {
+			return nil, err
 		}
 		defer config.Close()
 
-		allConfig, err := ioutil.ReadAll(config)
+		allConfig, err = ioutil.ReadAll(config)
 		if err != nil {
-			klog.Fatalf("Error while reading config (%q): %v", flags.F.ConfigFilePath, err)
+			return nil, fmt.Errorf("error while reading config (%q): %v", configFilePath, err)
 		}
 		klog.V(4).Infof("Cloudprovider config file contains: %q", string(allConfig))
-
-		configReader = generateConfigReaderFunc(allConfig)
 	} else {
 		klog.V(2).Infof("No cloudprovider config file provided, using default values.")
-		configReader = func() io.Reader { return nil }
 	}
-
-	// Creating the cloud interface involves resolving the metadata server to get
-	// an oauth token. If this fails, the token provider assumes it's not on GCE.
-	// No errors are thrown. So we need to keep retrying till it works because
-	// we know we're on GCE.
-	for {
-		provider, err := cloudprovider.GetCloudProvider("gce", configReader())
-		if err == nil {
-			cloud := provider.(*gce.Cloud)
-			// Configure GCE rate limiting
-			rl, err := ratelimit.NewGCERateLimiter(flags.F.GCERateLimit.Values(), flags.F.GCEOperationPollInterval)
-			if err != nil {
-				klog.Fatalf("Error configuring rate limiting: %v", err)
-			}
-			cloud.SetRateLimiter(rl)
-			// If this controller is scheduled on a node without compute/rw
-			// it won't be allowed to list backends. We can assume that the
-			// user has no need for Ingress in this case. If they grant
-			// permissions to the node they will have to restart the controller
-			// manually to re-create the client.
-			// TODO: why do we bail with success out if there is a permission error???
-			if _, err = cloud.ListGlobalBackendServices(); err == nil || utils.IsHTTPErrorCode(err, http.StatusForbidden) {
-				return cloud
-			}
-			klog.Warningf("Failed to list backend services, retrying: %v", err)
-		} else {
-			klog.Warningf("Failed to get cloud provider, retrying: %v", err)
+	if flags.F.ComputeAPIEndpoint != "" {
+		var err error
+		allConfig, err = withComputeAPIEndpoint(allConfig, flags.F.ComputeAPIEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid compute API endpoint: %v", err)
 		}
-		time.Sleep(cloudClientRetryInterval)
+		klog.Infof("Using compute API endpoint %q", flags.F.ComputeAPIEndpoint)
+	}
+	return allConfig, nil
+}
+
+// newGCEClient returns a client to the GCE environment configured by the
+// given gce config, whose calls are limited by rl.
+func newGCEClient(config []byte, rl *ratelimit.GCERateLimiter) (*gce.Cloud, error) {
+	var configReader io.Reader
+	if config != nil {
+		configReader = generateConfigReaderFunc(config)()
+	}
+	provider, err := cloudprovider.GetCloudProvider("gce", configReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cloud provider: %v", err)
+	}
+	cloud := provider.(*gce.Cloud)
+	cloud.SetRateLimiter(rl)
+	// If this controller is scheduled on a node without compute/rw
+	// it won't be allowed to list backends. We can assume that the
+	// user has no need for Ingress in this case. If they grant
+	// permissions to the node they will have to restart the controller
+	// manually to re-create the client.
+	// TODO: why do we bail with success out if there is a permission error???
+	if _, err = cloud.ListGlobalBackendServices(); err != nil && !utils.IsHTTPErrorCode(err, http.StatusForbidden) {
+		return nil, fmt.Errorf("failed to list backend services: %v", err)
+	}
+	return cloud, nil
+}
+
+// withComputeAPIEndpoint returns the gce config with the api-endpoint set to
+// the given compute API base URL, overriding the one of the config if any.
+func withComputeAPIEndpoint(config []byte, endpoint string) ([]byte, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%q is not an http(s) URL", endpoint)
+	}
+	// The client appends the resource paths to the endpoint.
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
 	}
+	// A variable defined again in a later section overrides the earlier one.
+	override := fmt.Sprintf("\n[global]\napi-endpoint = %s\n", strconv.Quote(endpoint))
+	return append(append([]byte{}, config...), override...), nil
 }
 
 type readerFunc func() io.Reader
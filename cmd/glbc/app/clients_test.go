@@ -19,7 +19,13 @@ package app
 import (
 	"bytes"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+
+	gcfg "gopkg.in/gcfg.v1"
+	"k8s.io/ingress-gce/pkg/flags"
+	"k8s.io/legacy-cloud-providers/gce"
 )
 
 // TestGenerateConfigReader tests the generated reader func returns the same
@@ -37,3 +43,98 @@ func TestGenerateConfigReaderFunc(t *testing.T) {
 		}
 	}
 }
+
+func TestWithComputeAPIEndpoint(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		config   string
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{
+			desc:     "no config",
+			endpoint: "https://restricted.googleapis.com/compute/v1/",
+			want:     "https://restricted.googleapis.com/compute/v1/",
+		},
+		{
+			desc:     "override the config",
+			config:   "[global]\napi-endpoint = https://www.googleapis.com/compute/v1/\nproject-id = my-project\n",
+			endpoint: "http://localhost:8080/compute/v1",
+			want:     "http://localhost:8080/compute/v1/",
+		},
+		{
+			desc:     "not a URL",
+			endpoint: "restricted.googleapis.com",
+			wantErr:  true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			config, err := withComputeAPIEndpoint([]byte(tc.config), tc.endpoint)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("withComputeAPIEndpoint(_, %q) = %v, want error %v", tc.endpoint, err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			cfg := &gce.ConfigFile{}
+			if err := gcfg.ReadStringInto(cfg, string(config)); err != nil {
+				t.Fatalf("Error while reading config %q: %v", config, err)
+			}
+			if cfg.Global.APIEndpoint != tc.want {
+				t.Errorf("api-endpoint = %q, want %q", cfg.Global.APIEndpoint, tc.want)
+			}
+			if tc.config != "" && cfg.Global.ProjectID != "my-project" {
+				t.Errorf("project-id = %q, want the one of the config", cfg.Global.ProjectID)
+			}
+		})
+	}
+}
+
+func TestReadGCEConfig(t *testing.T) {
+	defer func(endpoint string) { flags.F.ComputeAPIEndpoint = endpoint }(flags.F.ComputeAPIEndpoint)
+	dir, err := ioutil.TempDir("", "gce-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "gce.conf")
+	if err := ioutil.WriteFile(path, []byte("[global]\nproject-id = my-project\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		path     string
+		endpoint string
+		wantErr  bool
+	}{
+		{desc: "no config", path: ""},
+		{desc: "config", path: path},
+		{desc: "config with compute API endpoint", path: path, endpoint: "https://restricted.googleapis.com/compute/v1/"},
+		// Errors are returned to the project router rather than exiting.
+		{desc: "missing config", path: filepath.Join(dir, "missing.conf"), wantErr: true},
+		{desc: "invalid compute API endpoint", path: path, endpoint: "restricted.googleapis.com", wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			flags.F.ComputeAPIEndpoint = tc.endpoint
+			config, err := readGCEConfig(tc.path)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("readGCEConfig(%q) = %v, want error %v", tc.path, err, tc.wantErr)
+			}
+			if tc.wantErr || tc.path == "" {
+				return
+			}
+			cfg := &gce.ConfigFile{}
+			if err := gcfg.ReadStringInto(cfg, string(config)); err != nil {
+				t.Fatalf("Error while reading config %q: %v", config, err)
+			}
+			if cfg.Global.ProjectID != "my-project" {
+				t.Errorf("project-id = %q, want %q", cfg.Global.ProjectID, "my-project")
+			}
+			if cfg.Global.APIEndpoint != tc.endpoint {
+				t.Errorf("api-endpoint = %q, want %q", cfg.Global.APIEndpoint, tc.endpoint)
+			}
+		})
+	}
+}
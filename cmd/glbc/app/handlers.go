@@ -17,9 +17,11 @@ limitations under the License.
 package app
 
 import (
+	"crypto/subtle"
 	"flag"
 	"fmt"
 	"html/template"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"os/signal"
@@ -94,6 +96,97 @@ func healthCheckHandler(checker func() context.HealthCheckResults) http.HandlerF
 	}
 }
 
+// GCExplainHandler returns a handler which lists the decisions garbage
+// collection would currently take for the GCE resources of the cluster, and
+// the ownership evidence they are based on. Nothing is deleted.
+func GCExplainHandler(lbc *controller.LoadBalancerController) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		decisions, err := lbc.ExplainGC()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, decision := range decisions {
+			fmt.Fprintln(w, decision)
+		}
+	}
+}
+
+// GCHandler returns a handler which lists the garbage collection decisions
+// like GCExplainHandler on an authenticated GET, and triggers a garbage
+// collection pass on an authenticated POST. Both list the GCE resources of
+// the cluster.
+func GCHandler(lbc *controller.LoadBalancerController, token string) http.HandlerFunc {
+	explain := authenticated(token, GCExplainHandler(lbc))
+	trigger := authenticated(token, func(w http.ResponseWriter, r *http.Request) {
+		lbc.TriggerGC()
+		w.WriteHeader(http.StatusAccepted)
+	})
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			explain(w, r)
+		case http.MethodPost:
+			trigger(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// ResyncHandler returns a handler which triggers an immediate resync of all
+// Ingresses on an authenticated POST.
+func ResyncHandler(lbc *controller.LoadBalancerController, token string) http.HandlerFunc {
+	trigger := authenticated(token, func(w http.ResponseWriter, r *http.Request) {
+		lbc.Resync()
+		w.WriteHeader(http.StatusAccepted)
+	})
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		trigger(w, r)
+	}
+}
+
+// ReadDebugToken returns the bearer token of the debug endpoints, read from
+// the given file. The token is empty if path is empty.
+func ReadDebugToken(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("debug token file %q is empty", path)
+	}
+	return token, nil
+}
+
+// authenticated returns a handler which calls h only if the request carries
+// the bearer token. Requests are forbidden if the token is empty.
+func authenticated(token string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.Error(w, "debug endpoint is disabled, set -debug-token-file to enable it", http.StatusForbidden)
+			return
+		}
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			klog.Warningf("Rejected unauthenticated request %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		klog.Infof("Accepted request %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		h(w, r)
+	}
+}
+
 func flagHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthenticated(t *testing.T) {
+	for _, tc := range []struct {
+		desc       string
+		token      string
+		header     string
+		wantStatus int
+	}{
+		{
+			desc:       "disabled",
+			header:     "Bearer ",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			desc:       "missing token",
+			token:      "secret",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "wrong token",
+			token:      "secret",
+			header:     "Bearer other",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "valid token",
+			token:      "secret",
+			header:     "Bearer secret",
+			wantStatus: http.StatusAccepted,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			called := false
+			h := authenticated(tc.token, func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusAccepted)
+			})
+			req := httptest.NewRequest(http.MethodPost, "/debug/resync", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			h(rec, req)
+			if rec.Code != tc.wantStatus {
+				t.Errorf("authenticated() returned status %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if wantCalled := tc.wantStatus == http.StatusAccepted; called != wantCalled {
+				t.Errorf("authenticated() called handler = %v, want %v", called, wantCalled)
+			}
+		})
+	}
+}
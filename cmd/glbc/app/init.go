@@ -19,6 +19,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"text/template"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
@@ -32,6 +33,43 @@ import (
 	"k8s.io/klog"
 )
 
+// DefaultConfigName parses the value of a flag naming a default
+// FrontendConfig or BackendConfig. It returns nil if the flag is not set.
+func DefaultConfigName(flagName, value string) *types.NamespacedName {
+	if value == "" {
+		return nil
+	}
+	name, err := utils.ToNamespacedName(value)
+	if err != nil {
+		klog.Fatalf("Failed to parse --%s: %v", flagName, err)
+	}
+	return &name
+}
+
+// ValidateRestrictedNodeAccess returns an error if --enable-restricted-node-access
+// is combined with flags requiring access to the nodes.
+func ValidateRestrictedNodeAccess() error {
+	if !flags.F.EnableRestrictedNodeAccess {
+		return nil
+	}
+	if !flags.F.EnableReadinessReflector {
+		return fmt.Errorf("--enable-readiness-reflector is required, the health status of NEGs replaces the health checks of the nodes")
+	}
+	if flags.F.RunL4Controller {
+		return fmt.Errorf("the L4 controller uses instance groups and cannot run with --enable-restricted-node-access")
+	}
+	return nil
+}
+
+// StatusHostnameTemplate parses the template of the hostname published in the
+// status of Ingresses. It returns nil if the template is not set.
+func StatusHostnameTemplate(value string) (*template.Template, error) {
+	if value == "" {
+		return nil, nil
+	}
+	return template.New("hostname").Option("missingkey=error").Parse(value)
+}
+
 // DefaultBackendServicePort returns the ServicePort which will be
 // used as the default backend for load balancers.
 func DefaultBackendServicePort(kubeClient kubernetes.Interface) utils.ServicePort {
@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+
+	"k8s.io/ingress-gce/pkg/flags"
+)
+
+func TestValidateRestrictedNodeAccess(t *testing.T) {
+	defer func(restricted, reflector, l4 bool) {
+		flags.F.EnableRestrictedNodeAccess = restricted
+		flags.F.EnableReadinessReflector = reflector
+		flags.F.RunL4Controller = l4
+	}(flags.F.EnableRestrictedNodeAccess, flags.F.EnableReadinessReflector, flags.F.RunL4Controller)
+
+	for _, tc := range []struct {
+		desc       string
+		restricted bool
+		reflector  bool
+		l4         bool
+		wantErr    bool
+	}{
+		{desc: "disabled", reflector: false, l4: true},
+		{desc: "enabled", restricted: true, reflector: true},
+		{desc: "without readiness reflector", restricted: true, wantErr: true},
+		{desc: "with L4 controller", restricted: true, reflector: true, l4: true, wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			flags.F.EnableRestrictedNodeAccess = tc.restricted
+			flags.F.EnableReadinessReflector = tc.reflector
+			flags.F.RunL4Controller = tc.l4
+			if err := ValidateRestrictedNodeAccess(); (err != nil) != tc.wantErr {
+				t.Errorf("ValidateRestrictedNodeAccess() = %v, want error: %t", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -20,14 +20,18 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"os"
 	"time"
 
 	flag "github.com/spf13/pflag"
+	"golang.org/x/oauth2/google"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/ingress-gce/pkg/frontendconfig"
 	"k8s.io/ingress-gce/pkg/ingparams"
 	"k8s.io/ingress-gce/pkg/psc"
+	"k8s.io/ingress-gce/pkg/quota"
 	"k8s.io/ingress-gce/pkg/serviceattachment"
 	"k8s.io/ingress-gce/pkg/svcneg"
 	"k8s.io/klog"
@@ -52,11 +56,14 @@ import (
 
 	"k8s.io/ingress-gce/cmd/glbc/app"
 	"k8s.io/ingress-gce/pkg/backendconfig"
+	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/crd"
+	"k8s.io/ingress-gce/pkg/eventlog"
 	"k8s.io/ingress-gce/pkg/firewalls"
 	"k8s.io/ingress-gce/pkg/flags"
 	_ "k8s.io/ingress-gce/pkg/klog"
 	"k8s.io/ingress-gce/pkg/l4"
+	"k8s.io/ingress-gce/pkg/loadbalancers"
 	"k8s.io/ingress-gce/pkg/version"
 )
 
@@ -78,6 +85,18 @@ func main() {
 
 	klog.V(2).Infof("Flags = %+v", flags.F)
 	defer klog.Flush()
+	if flags.F.AuditLog != "" {
+		auditLog := os.Stdout
+		if flags.F.AuditLog != "-" {
+			f, err := os.OpenFile(flags.F.AuditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if err != nil {
+				klog.Fatalf("Failed to open audit log %q: %v", flags.F.AuditLog, err)
+			}
+			defer f.Close()
+			auditLog = f
+		}
+		composite.SetAuditSink(composite.NewJSONAuditSink(auditLog))
+	}
 	// Create kube-config that uses protobufs to communicate with API server.
 	kubeConfigForProtobuf, err := app.NewKubeConfigForProtobuf()
 	if err != nil {
@@ -184,20 +203,56 @@ func main() {
 	}
 	kubeSystemUID := kubeSystemNS.GetUID()
 
-	cloud := app.NewGCEClient()
+	cloud, rl := app.NewGCEClient()
+	if err := loadbalancers.ValidateNetLBHealthCheckPolicy(flags.F.L4NetLBHealthCheckPolicy); err != nil {
+		klog.Fatalf("Invalid --l4-netlb-health-check-policy: %v", err)
+	}
+
+	if err := app.ValidateRestrictedNodeAccess(); err != nil {
+		klog.Fatalf("Invalid --enable-restricted-node-access: %v", err)
+	}
+
+	statusHostnameTemplate, err := app.StatusHostnameTemplate(flags.F.IngressStatusHostnameTemplate)
+	if err != nil {
+		klog.Fatalf("Invalid --ingress-status-hostname-template: %v", err)
+	}
+	var eventLogSink *eventlog.Sink
+	if flags.F.EventsLogName != "" {
+		client, err := google.DefaultClient(context.Background(), eventlog.Scope)
+		if err != nil {
+			klog.Fatalf("Failed to create Cloud Logging client: %v", err)
+		}
+		eventLogSink = eventlog.NewSink(client, cloud.ProjectID(), flags.F.EventsLogName, cloud.Region(), flags.F.ClusterName)
+		go eventLogSink.Run(wait.NeverStop)
+	}
 	defaultBackendServicePort := app.DefaultBackendServicePort(kubeClient)
 	ctxConfig := ingctx.ControllerContextConfig{
 		Namespace:             flags.F.WatchNamespace,
 		ResyncPeriod:          flags.F.ResyncPeriod,
+		ResyncSpread:          flags.F.ResyncSpread,
 		NumL4Workers:          flags.F.NumL4Workers,
 		DefaultBackendSvcPort: defaultBackendServicePort,
 		HealthCheckPath:       flags.F.HealthCheckPath,
 		FrontendConfigEnabled: flags.F.EnableFrontendConfig,
-		EnableASMConfigMap:    flags.F.EnableASMConfigMapBasedConfig,
-		ASMConfigMapNamespace: flags.F.ASMConfigMapBasedConfigNamespace,
-		ASMConfigMapName:      flags.F.ASMConfigMapBasedConfigCMName,
+		DefaultFrontendConfig: app.DefaultConfigName("default-frontend-config", flags.F.DefaultFrontendConfig),
+		DefaultBackendConfig:  app.DefaultConfigName("default-backend-config", flags.F.DefaultBackendConfig),
+		DefaultBackendTimeout: flags.F.DefaultBackendTimeout,
+		NamespaceLimits: quota.Limits{
+			Ingresses:    flags.F.MaxIngressesPerNamespace,
+			Certificates: flags.F.MaxCertificatesPerNamespace,
+			NEGServices:  flags.F.MaxNEGServicesPerNamespace,
+		},
+		SyncDeadline:             flags.F.SyncDeadline,
+		StatusHostnameTemplate:   statusHostnameTemplate,
+		EventAggregationInterval: flags.F.EventAggregationInterval,
+		WatchSecrets:             flags.F.WatchSecrets,
+		EventLogSink:             eventLogSink,
+		EnableASMConfigMap:       flags.F.EnableASMConfigMapBasedConfig,
+		ASMConfigMapNamespace:    flags.F.ASMConfigMapBasedConfigNamespace,
+		ASMConfigMapName:         flags.F.ASMConfigMapBasedConfigCMName,
 	}
 	ctx := ingctx.NewControllerContext(kubeConfig, kubeClient, backendConfigClient, frontendConfigClient, svcNegClient, ingParamsClient, svcAttachmentClient, cloud, namer, kubeSystemUID, ctxConfig)
+	ctx.ProjectRouter = app.NewProjectRouter(cloud, rl)
 	go app.RunHTTPServer(ctx.HealthCheck)
 
 	if !flags.F.LeaderElection.LeaderElect {
@@ -263,6 +318,12 @@ func runControllers(ctx *ingctx.ControllerContext) {
 	stopCh := make(chan struct{})
 	ctx.Init()
 	lbc := controller.NewLoadBalancerController(ctx, stopCh)
+	debugToken, err := app.ReadDebugToken(flags.F.DebugTokenFile)
+	if err != nil {
+		klog.Fatalf("Failed to read debug token: %v", err)
+	}
+	http.HandleFunc("/debug/gc", app.GCHandler(lbc, debugToken))
+	http.HandleFunc("/debug/resync", app.ResyncHandler(lbc, debugToken))
 	if ctx.EnableASMConfigMap {
 		ctx.ASMConfigController.RegisterInformer(ctx.ConfigMapInformer, func() {
 			lbc.Stop(false) // We want to trigger a restart, don't have to clean up all the resources.
@@ -332,6 +393,7 @@ func runControllers(ctx *ingctx.ControllerContext) {
 		flags.F.EnableNonGCPMode,
 		enableAsm,
 		asmServiceNEGSkipNamespaces,
+		flags.F.MaxNEGServicesPerNamespace,
 	)
 
 	ctx.AddHealthCheck("neg-controller", negController.IsHealthy)
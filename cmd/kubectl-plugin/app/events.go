@@ -0,0 +1,55 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// recentSyncErrors returns the most recent warning events of the given object,
+// newest first, which are the errors of its last syncs.
+func recentSyncErrors(client kubernetes.Interface, kind, namespace, name string, limit int) ([]string, error) {
+	selector := fields.OneTermEqualSelector("involvedObject.name", name).String()
+	list, err := client.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	var events []v1.Event
+	for _, e := range list.Items {
+		if e.InvolvedObject.Kind == kind && e.InvolvedObject.Name == name && e.Type == v1.EventTypeWarning {
+			events = append(events, e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[j].LastTimestamp.Before(&events[i].LastTimestamp)
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	var result []string
+	for _, e := range events {
+		result = append(result, fmt.Sprintf("%s %s (x%d): %s", e.LastTimestamp.UTC().Format("2006-01-02T15:04:05Z"), e.Reason, e.Count, e.Message))
+	}
+	return result, nil
+}
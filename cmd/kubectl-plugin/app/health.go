@@ -0,0 +1,36 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/compute/v1"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+// gceHealth implements healthGetter for a real GCE cloud.
+type gceHealth struct {
+	cloud *gce.Cloud
+}
+
+// BackendHealth implements healthGetter.
+func (g *gceHealth) BackendHealth(bsKey *meta.Key, group string) (*compute.BackendServiceGroupHealth, error) {
+	if bsKey.Type() == meta.Regional {
+		return g.cloud.GetRegionalBackendServiceHealth(bsKey.Name, bsKey.Region, group)
+	}
+	return g.cloud.GetGlobalBackendServiceHealth(bsKey.Name, group)
+}
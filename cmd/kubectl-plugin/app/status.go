@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	flag "github.com/spf13/pflag"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/compute/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+var (
+	options struct {
+		kubeconfig string
+		namespace  string
+		project    string
+		region     string
+		endpoint   string
+		events     int
+	}
+	// FlagSet is the flag set of the plugin.
+	FlagSet = flag.NewFlagSet("kubectl-ingress_gce", flag.ExitOnError)
+)
+
+func init() {
+	FlagSet.StringVar(&options.kubeconfig, "kubeconfig", "", "path to the kubeconfig file, defaults to the kubectl one")
+	FlagSet.StringVarP(&options.namespace, "namespace", "n", "", "namespace of the object, defaults to the namespace of the current context")
+	FlagSet.StringVar(&options.project, "project", "", "GCP project of the load balancer")
+	FlagSet.StringVar(&options.region, "region", "", "GCP region of the cluster")
+	FlagSet.StringVar(&options.endpoint, "compute-api-endpoint", "", "base URL of the GA compute API, defaults to the public one")
+	FlagSet.IntVar(&options.events, "events", 5, "number of recent sync errors to print")
+	FlagSet.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: kubectl ingress-gce (ingress|service) NAME [flags]\n\n")
+		FlagSet.PrintDefaults()
+	}
+}
+
+// Run prints the GCE resource tree, the health of the backends and the recent
+// sync errors of the Ingress or Service named by args.
+func Run(w io.Writer, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected (ingress|service) NAME, got %v", args)
+	}
+	kind, name := args[0], args[1]
+	for _, o := range []struct {
+		val, flag string
+	}{
+		{options.project, "--project"},
+		{options.region, "--region"},
+	} {
+		if o.val == "" {
+			return fmt.Errorf("you must specify the %s flag", o.flag)
+		}
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = options.kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{})
+	namespace := options.namespace
+	if namespace == "" {
+		var err error
+		if namespace, _, err = clientConfig.Namespace(); err != nil {
+			return err
+		}
+	}
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	tokenSource, err := google.DefaultTokenSource(context.Background(), compute.ComputeScope)
+	if err != nil {
+		return err
+	}
+	gceCloud, err := gce.CreateGCECloud(&gce.CloudConfig{
+		ProjectID:   options.project,
+		Region:      options.region,
+		APIEndpoint: options.endpoint,
+		TokenSource: tokenSource,
+	})
+	if err != nil {
+		return err
+	}
+	r := &resolver{cloud: composite.NewCloud(gceCloud), health: &gceHealth{cloud: gceCloud}}
+
+	var tree *Node
+	var eventKind string
+	switch kind {
+	case "ingress", "ing":
+		ing, err := client.NetworkingV1().Ingresses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		tree, eventKind = r.ingressTree(ing), "Ingress"
+	case "service", "svc":
+		svc, err := client.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		tree, eventKind = r.serviceTree(svc), "Service"
+	default:
+		return fmt.Errorf("unsupported kind %q, must be ingress or service", kind)
+	}
+	tree.Print(w)
+
+	syncErrors, err := recentSyncErrors(client, eventKind, namespace, name, options.events)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	if len(syncErrors) == 0 {
+		fmt.Fprintln(w, "No recent sync errors.")
+		return nil
+	}
+	fmt.Fprintln(w, "Recent sync errors:")
+	for _, e := range syncErrors {
+		fmt.Fprintln(w, "  "+e)
+	}
+	return nil
+}
@@ -0,0 +1,351 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/compute/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/ingress-gce/pkg/annotations"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/loadbalancers/features"
+	"k8s.io/ingress-gce/pkg/utils"
+	namer_util "k8s.io/ingress-gce/pkg/utils/namer"
+)
+
+// namerPrefix is the prefix of the names of the resources created by the
+// controller.
+const namerPrefix = "k8s"
+
+// Node is a GCE resource in the tree of resources of an Ingress or Service.
+type Node struct {
+	Kind string
+	Name string
+	// Details are one line facts about the resource, e.g. its health.
+	Details []string
+	// Err is set if the resource could not be read.
+	Err      error
+	Children []*Node
+}
+
+// Print writes the tree rooted at the node to w.
+func (n *Node) Print(w io.Writer) {
+	n.print(w, "")
+}
+
+func (n *Node) print(w io.Writer, indent string) {
+	line := fmt.Sprintf("%s%s %s", indent, n.Kind, n.Name)
+	if len(n.Details) > 0 {
+		line += " (" + strings.Join(n.Details, ", ") + ")"
+	}
+	if n.Err != nil {
+		line += fmt.Sprintf(" ERROR: %v", n.Err)
+	}
+	fmt.Fprintln(w, line)
+	for _, c := range n.Children {
+		c.print(w, indent+"  ")
+	}
+}
+
+// healthGetter returns the health of the endpoints of a backend of a backend
+// service.
+type healthGetter interface {
+	BackendHealth(bsKey *meta.Key, group string) (*compute.BackendServiceGroupHealth, error)
+}
+
+// resolver resolves the tree of GCE resources of an Ingress or Service by
+// following the links between the resources, starting at the ones recorded in
+// the status annotations.
+type resolver struct {
+	cloud    composite.Cloud
+	health   healthGetter
+	versions *features.ResourceVersions
+}
+
+// ingressTree returns the tree of GCE resources of the given Ingress.
+func (r *resolver) ingressTree(ing *networkingv1.Ingress) *Node {
+	root := &Node{Kind: "Ingress", Name: ing.Namespace + "/" + ing.Name}
+	var scope meta.KeyType = meta.Global
+	if utils.IsRegionalIngress(ing) {
+		scope = meta.Regional
+	}
+	r.versions = features.VersionsFromIngress(ing)
+
+	for _, k := range []string{annotations.HttpForwardingRuleKey, annotations.HttpsForwardingRuleKey} {
+		if name, ok := ing.Annotations[k]; ok {
+			root.Children = append(root.Children, r.forwardingRuleNode(name, scope))
+		}
+	}
+	if len(root.Children) == 0 {
+		root.Details = append(root.Details, "no forwarding rule in the status annotations")
+	}
+	return root
+}
+
+// serviceTree returns the tree of GCE resources of the given L4 Service.
+func (r *resolver) serviceTree(svc *v1.Service) *Node {
+	root := &Node{Kind: "Service", Name: svc.Namespace + "/" + svc.Name}
+	r.versions = features.GAResourceVersions
+
+	for _, k := range []string{annotations.TCPForwardingRuleKey, annotations.UDPForwardingRuleKey} {
+		if name, ok := svc.Annotations[k]; ok {
+			root.Children = append(root.Children, r.forwardingRuleNode(name, meta.Regional))
+		}
+	}
+	for _, k := range []string{annotations.FirewallRuleKey, annotations.FirewallRuleForHealthcheckKey} {
+		if name, ok := svc.Annotations[k]; ok {
+			root.Children = append(root.Children, &Node{Kind: "Firewall", Name: name})
+		}
+	}
+	if len(root.Children) == 0 {
+		root.Details = append(root.Details, "no forwarding rule in the status annotations")
+	}
+	return root
+}
+
+func (r *resolver) forwardingRuleNode(name string, scope meta.KeyType) *Node {
+	n := &Node{Kind: "ForwardingRule", Name: name}
+	key, err := r.cloud.CreateKey(name, scope)
+	if err != nil {
+		n.Err = err
+		return n
+	}
+	fr, err := r.cloud.GetForwardingRule(context.TODO(), key, r.versions.ForwardingRule)
+	if err != nil {
+		n.Err = err
+		return n
+	}
+	n.Details = append(n.Details, namingDetails(name)...)
+	n.Details = append(n.Details, fmt.Sprintf("%s %s", fr.IPAddress, fr.IPProtocol))
+	if fr.PortRange != "" {
+		n.Details = append(n.Details, "ports "+fr.PortRange)
+	} else if len(fr.Ports) > 0 {
+		n.Details = append(n.Details, "ports "+strings.Join(fr.Ports, ","))
+	}
+	if fr.Target != "" {
+		n.Children = append(n.Children, r.targetProxyNode(fr.Target))
+	}
+	if fr.BackendService != "" {
+		n.Children = append(n.Children, r.backendServiceNode(fr.BackendService))
+	}
+	return n
+}
+
+func (r *resolver) targetProxyNode(link string) *Node {
+	id, err := cloud.ParseResourceURL(link)
+	if err != nil {
+		return &Node{Kind: "TargetProxy", Name: link, Err: err}
+	}
+	name := id.Key.Name
+	switch id.Resource {
+	case "targetHttpProxies":
+		n := &Node{Kind: "TargetHttpProxy", Name: name}
+		tp, err := r.cloud.GetTargetHttpProxy(context.TODO(), id.Key, r.versions.TargetHttpProxy)
+		if err != nil {
+			n.Err = err
+			return n
+		}
+		n.Details = namingDetails(name)
+		n.Children = append(n.Children, r.urlMapNode(tp.UrlMap))
+		return n
+	case "targetHttpsProxies":
+		n := &Node{Kind: "TargetHttpsProxy", Name: name}
+		tp, err := r.cloud.GetTargetHttpsProxy(context.TODO(), id.Key, r.versions.TargetHttpsProxy)
+		if err != nil {
+			n.Err = err
+			return n
+		}
+		n.Details = namingDetails(name)
+		for _, certLink := range tp.SslCertificates {
+			n.Children = append(n.Children, r.sslCertificateNode(certLink))
+		}
+		n.Children = append(n.Children, r.urlMapNode(tp.UrlMap))
+		return n
+	}
+	return &Node{Kind: "TargetProxy", Name: name, Details: []string{"unsupported resource " + id.Resource}}
+}
+
+func (r *resolver) sslCertificateNode(link string) *Node {
+	id, err := cloud.ParseResourceURL(link)
+	if err != nil {
+		return &Node{Kind: "SslCertificate", Name: link, Err: err}
+	}
+	n := &Node{Kind: "SslCertificate", Name: id.Key.Name}
+	cert, err := r.cloud.GetSslCertificate(context.TODO(), id.Key, r.versions.SslCertificate)
+	if err != nil {
+		n.Err = err
+		return n
+	}
+	if cert.ExpireTime != "" {
+		n.Details = append(n.Details, "expires "+cert.ExpireTime)
+	}
+	return n
+}
+
+func (r *resolver) urlMapNode(link string) *Node {
+	id, err := cloud.ParseResourceURL(link)
+	if err != nil {
+		return &Node{Kind: "UrlMap", Name: link, Err: err}
+	}
+	n := &Node{Kind: "UrlMap", Name: id.Key.Name}
+	um, err := r.cloud.GetUrlMap(context.TODO(), id.Key, r.versions.UrlMap)
+	if err != nil {
+		n.Err = err
+		return n
+	}
+	n.Details = namingDetails(id.Key.Name)
+	for _, bsLink := range urlMapBackendServices(um) {
+		n.Children = append(n.Children, r.backendServiceNode(bsLink))
+	}
+	return n
+}
+
+// urlMapBackendServices returns the links of the backend services referenced
+// by the URL map, sorted and without duplicates.
+func urlMapBackendServices(um *composite.UrlMap) []string {
+	links := map[string]bool{}
+	if um.DefaultService != "" {
+		links[um.DefaultService] = true
+	}
+	for _, pm := range um.PathMatchers {
+		if pm.DefaultService != "" {
+			links[pm.DefaultService] = true
+		}
+		for _, pr := range pm.PathRules {
+			if pr.Service != "" {
+				links[pr.Service] = true
+			}
+		}
+	}
+	var result []string
+	for link := range links {
+		result = append(result, link)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (r *resolver) backendServiceNode(link string) *Node {
+	id, err := cloud.ParseResourceURL(link)
+	if err != nil {
+		return &Node{Kind: "BackendService", Name: link, Err: err}
+	}
+	n := &Node{Kind: "BackendService", Name: id.Key.Name}
+	bs, err := r.cloud.GetBackendService(context.TODO(), id.Key, r.versions.BackendService)
+	if err != nil {
+		n.Err = err
+		return n
+	}
+	n.Details = append(n.Details, bs.Protocol)
+	for _, hcLink := range bs.HealthChecks {
+		n.Children = append(n.Children, r.healthCheckNode(hcLink))
+	}
+	for _, be := range bs.Backends {
+		n.Children = append(n.Children, r.backendNode(id.Key, be.Group))
+	}
+	return n
+}
+
+func (r *resolver) healthCheckNode(link string) *Node {
+	id, err := cloud.ParseResourceURL(link)
+	if err != nil {
+		return &Node{Kind: "HealthCheck", Name: link, Err: err}
+	}
+	n := &Node{Kind: "HealthCheck", Name: id.Key.Name}
+	hc, err := r.cloud.GetHealthCheck(context.TODO(), id.Key, r.versions.HealthCheck)
+	if err != nil {
+		n.Err = err
+		return n
+	}
+	n.Details = append(n.Details, hc.Type)
+	switch {
+	case hc.HttpHealthCheck != nil:
+		n.Details = append(n.Details, fmt.Sprintf("port %d path %s", hc.HttpHealthCheck.Port, hc.HttpHealthCheck.RequestPath))
+	case hc.HttpsHealthCheck != nil:
+		n.Details = append(n.Details, fmt.Sprintf("port %d path %s", hc.HttpsHealthCheck.Port, hc.HttpsHealthCheck.RequestPath))
+	case hc.Http2HealthCheck != nil:
+		n.Details = append(n.Details, fmt.Sprintf("port %d path %s", hc.Http2HealthCheck.Port, hc.Http2HealthCheck.RequestPath))
+	case hc.TcpHealthCheck != nil:
+		n.Details = append(n.Details, fmt.Sprintf("port %d", hc.TcpHealthCheck.Port))
+	}
+	return n
+}
+
+func (r *resolver) backendNode(bsKey *meta.Key, group string) *Node {
+	kind, name := "Backend", group
+	if id, err := cloud.ParseResourceURL(group); err == nil {
+		name = id.Key.Name
+		if id.Key.Zone != "" {
+			name = id.Key.Zone + "/" + name
+		}
+		switch id.Resource {
+		case "instanceGroups":
+			kind = "InstanceGroup"
+		case "networkEndpointGroups":
+			kind = "NetworkEndpointGroup"
+		}
+	}
+	n := &Node{Kind: kind, Name: name}
+	if r.health == nil {
+		return n
+	}
+	health, err := r.health.BackendHealth(bsKey, group)
+	if err != nil {
+		n.Err = err
+		return n
+	}
+	n.Details = healthSummary(health)
+	return n
+}
+
+// healthSummary returns the number of endpoints by health state, sorted by
+// state.
+func healthSummary(health *compute.BackendServiceGroupHealth) []string {
+	counts := map[string]int{}
+	for _, hs := range health.HealthStatus {
+		counts[hs.HealthState]++
+	}
+	if len(counts) == 0 {
+		return []string{"no endpoints"}
+	}
+	var result []string
+	for state, count := range counts {
+		result = append(result, fmt.Sprintf("%s: %d", state, count))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// namingDetails describes the naming scheme of the name of a frontend
+// resource.
+func namingDetails(name string) []string {
+	if c, ok := namer_util.ParseV2FrontendName(namerPrefix, name); ok {
+		return []string{"v2 naming scheme, load balancer " + c.Remainder}
+	}
+	if c := namer_util.NewNamer("", "").ParseName(name); c.ClusterName != "" {
+		return []string{"v1 naming scheme, cluster " + c.ClusterName}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/compute/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/ingress-gce/pkg/annotations"
+	"k8s.io/ingress-gce/pkg/composite"
+)
+
+const testProject = "test-project"
+
+type fakeHealth map[string][]string
+
+func (f fakeHealth) BackendHealth(bsKey *meta.Key, group string) (*compute.BackendServiceGroupHealth, error) {
+	health := &compute.BackendServiceGroupHealth{}
+	for _, state := range f[group] {
+		health.HealthStatus = append(health.HealthStatus, &compute.HealthStatus{HealthState: state})
+	}
+	return health, nil
+}
+
+func TestIngressTree(t *testing.T) {
+	fake := composite.NewFake(testProject, "us-central1")
+	link := func(collection, name string) string {
+		return cloud.SelfLink(meta.VersionGA, testProject, collection, meta.GlobalKey(name))
+	}
+	negLink := cloud.SelfLink(meta.VersionGA, testProject, "networkEndpointGroups", meta.ZonalKey("k8s1-neg", "us-central1-a"))
+
+	for _, create := range []func() error{
+		func() error {
+			return fake.CreateHealthCheck(context.TODO(), meta.GlobalKey("k8s1-hc"), &composite.HealthCheck{
+				Name:            "k8s1-hc",
+				Type:            "HTTP",
+				HttpHealthCheck: &composite.HTTPHealthCheck{Port: 8080, RequestPath: "/healthz"},
+			})
+		},
+		func() error {
+			return fake.CreateBackendService(context.TODO(), meta.GlobalKey("k8s1-bs"), &composite.BackendService{
+				Name:         "k8s1-bs",
+				Protocol:     "HTTP",
+				HealthChecks: []string{link("healthChecks", "k8s1-hc")},
+				Backends:     []*composite.Backend{{Group: negLink}},
+			})
+		},
+		func() error {
+			return fake.CreateUrlMap(context.TODO(), meta.GlobalKey("k8s2-um-abc-default-foo-xyz"), &composite.UrlMap{
+				Name:           "k8s2-um-abc-default-foo-xyz",
+				DefaultService: link("backendServices", "k8s1-bs"),
+			})
+		},
+		func() error {
+			return fake.CreateTargetHttpProxy(context.TODO(), meta.GlobalKey("k8s2-tp-abc-default-foo-xyz"), &composite.TargetHttpProxy{
+				Name:   "k8s2-tp-abc-default-foo-xyz",
+				UrlMap: link("urlMaps", "k8s2-um-abc-default-foo-xyz"),
+			})
+		},
+		func() error {
+			return fake.CreateForwardingRule(context.TODO(), meta.GlobalKey("k8s2-fr-abc-default-foo-xyz"), &composite.ForwardingRule{
+				Name:       "k8s2-fr-abc-default-foo-xyz",
+				IPAddress:  "1.2.3.4",
+				IPProtocol: "TCP",
+				PortRange:  "80-80",
+				Target:     link("targetHttpProxies", "k8s2-tp-abc-default-foo-xyz"),
+			})
+		},
+	} {
+		if err := create(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo",
+			Annotations: map[string]string{
+				annotations.HttpForwardingRuleKey:  "k8s2-fr-abc-default-foo-xyz",
+				annotations.HttpsForwardingRuleKey: "k8s2-fs-abc-default-foo-xyz",
+			},
+		},
+	}
+	r := &resolver{cloud: fake, health: fakeHealth{negLink: {"HEALTHY", "UNHEALTHY", "HEALTHY"}}}
+	var buf bytes.Buffer
+	r.ingressTree(ing).Print(&buf)
+
+	want := []string{
+		"Ingress default/foo",
+		"  ForwardingRule k8s2-fr-abc-default-foo-xyz (v2 naming scheme, load balancer abc-default-foo-xyz, 1.2.3.4 TCP, ports 80-80)",
+		"    TargetHttpProxy k8s2-tp-abc-default-foo-xyz (v2 naming scheme, load balancer abc-default-foo-xyz)",
+		"      UrlMap k8s2-um-abc-default-foo-xyz (v2 naming scheme, load balancer abc-default-foo-xyz)",
+		"        BackendService k8s1-bs (HTTP)",
+		"          HealthCheck k8s1-hc (HTTP, port 8080 path /healthz)",
+		"          NetworkEndpointGroup us-central1-a/k8s1-neg (HEALTHY: 2, UNHEALTHY: 1)",
+	}
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	// The HTTPS forwarding rule does not exist, its error is printed.
+	if len(got) != len(want)+1 || !strings.HasPrefix(got[len(want)], "  ForwardingRule k8s2-fs-abc-default-foo-xyz ERROR: ") {
+		t.Fatalf("ingressTree() =\n%s\nwant\n%s\n  ForwardingRule k8s2-fs-abc-default-foo-xyz ERROR: ...", buf.String(), strings.Join(want, "\n"))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ingressTree() line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
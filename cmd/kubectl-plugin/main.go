@@ -0,0 +1,38 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-ingress_gce is a kubectl plugin which prints the GCE resources,
+// backend health and recent sync errors of an Ingress or Service.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/ingress-gce/cmd/kubectl-plugin/app"
+
+	// Pull in the auth library for GCP.
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+)
+
+func main() {
+	app.FlagSet.Parse(os.Args[1:])
+	if err := app.Run(os.Stdout, app.FlagSet.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -3,6 +3,7 @@ module k8s.io/ingress-gce
 go 1.13
 
 require (
+	cloud.google.com/go v0.81.0
 	github.com/GoogleCloudPlatform/k8s-cloud-provider v1.15.0
 	github.com/go-openapi/spec v0.19.3
 	github.com/google/go-cmp v0.5.5
@@ -10,11 +11,12 @@ require (
 	github.com/kr/pretty v0.2.0
 	github.com/prometheus/client_golang v1.7.1
 	github.com/prometheus/client_model v0.2.0
+	github.com/prometheus/common v0.10.0
 	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.6.1
 	golang.org/x/oauth2 v0.0.0-20210427180440-81ed05c6b58c
 	google.golang.org/api v0.46.0
-	gopkg.in/gcfg.v1 v1.2.3 // indirect
+	gopkg.in/gcfg.v1 v1.2.3
 	gopkg.in/warnings.v0 v0.1.2 // indirect
 	istio.io/api v0.0.0-20190809125725-591cf32c1d0e
 	k8s.io/api v0.19.0
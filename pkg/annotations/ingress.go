@@ -17,7 +17,9 @@ limitations under the License.
 package annotations
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strconv"
 
 	v1 "k8s.io/api/networking/v1"
@@ -49,11 +51,33 @@ const (
 	// responsibility to create/delete it.
 	RegionalStaticIPNameKey = "kubernetes.io/ingress.regional-static-ip-name"
 
+	// RegionalStaticIPSubnetKey tells the Ingress controller to reserve a
+	// regional internal static ip in the subnet of the given name for the
+	// forwarding rules of a gce-internal Ingress. Unlike an ephemeral ip, the
+	// ip is kept when the forwarding rules are recreated. The controller
+	// manages this ip and releases it when the Ingress is deleted. It is
+	// ignored if RegionalStaticIPNameKey is specified.
+	RegionalStaticIPSubnetKey = "kubernetes.io/ingress.regional-static-ip-subnet"
+
+	// IPVersionKey selects the IP version of the forwarding rules of the
+	// Ingress. If unset or set to "IPV4", the load balancer is exposed on an
+	// IPv4 address. If set to "IPV6", only an IPv6 forwarding rule is created
+	// and the status of the Ingress reports only its IPv6 address. IPv6 is
+	// only supported by global external Ingresses. A static IP specified with
+	// GlobalStaticIPNameKey must be of the same IP version.
+	IPVersionKey = "kubernetes.io/ingress.ip-version"
+	// IPv4Version and IPv6Version are the valid values of IPVersionKey.
+	IPv4Version = "IPV4"
+	IPv6Version = "IPV6"
+
 	// PreSharedCertKey represents the specific pre-shared SSL
 	// certificate for the Ingress controller to use. The controller *does not*
 	// manage this certificate, it is the users responsibility to create/delete it.
 	// In GCP, the Ingress controller assigns the SSL certificate with this name
-	// to the target proxies of the Ingress.
+	// to the target proxies of the Ingress. Certificates of other projects are
+	// referenced by their URL, e.g.
+	// "projects/security-project/global/sslCertificates/my-cert". The
+	// service account of the controller must be allowed to get them.
 	PreSharedCertKey = "ingress.gcp.kubernetes.io/pre-shared-cert"
 
 	// IngressClassKey picks a specific "class" for the Ingress. The controller
@@ -63,6 +87,13 @@ const (
 	GceIngressClass      = "gce"
 	GceMultiIngressClass = "gce-multi-cluster"
 	GceL7ILBIngressClass = "gce-internal"
+	// GceSSLProxyIngressClass is the class for Ingresses backed by a global
+	// SSL proxy load balancer. TLS is terminated by a target SSL proxy and the
+	// traffic is forwarded as TCP (or SSL for HTTPS app protocols) to the
+	// default backend of the Ingress. This is intended for non-HTTP TLS
+	// workloads such as MQTT or AMQPS. The backend service port must not be
+	// shared with Ingresses of other classes, as they require HTTP backends.
+	GceSSLProxyIngressClass = "gce-ssl-proxy"
 
 	// Label key to denote which GCE zone a Kubernetes node is in.
 	ZoneKey     = "failure-domain.beta.kubernetes.io/zone"
@@ -86,6 +117,25 @@ const (
 	//     networking.gke.io/v1beta1.FrontendConfig: 'my-frontendconfig'
 	FrontendConfigKey = "networking.gke.io/v1beta1.FrontendConfig"
 
+	// UrlMapSwapKey tells the controller to apply changes to the URL map of
+	// the Ingress by swapping URL maps instead of updating the URL map in
+	// place. If set to "true", the new URL map is built and validated under
+	// an alternate name, the target proxies are then moved to it and the
+	// previous URL map is deleted. This is only supported by global Ingresses
+	// with the v2 frontend naming scheme.
+	UrlMapSwapKey = "networking.gke.io/url-map-swap"
+
+	// PathBackendConfigsKey is the annotation key used by controller to
+	// attach a BackendConfig to the backend of specific paths of the
+	// Ingress. The value is a stringified JSON map from the path, as written
+	// in the Ingress rules, to the name of a BackendConfig in the namespace
+	// of the Ingress. The BackendConfig replaces the one of the Service port
+	// for the given paths only, and is materialized as a separate backend
+	// service. Examples:
+	// - annotations:
+	//     networking.gke.io/path-backend-configs: '{"/static/*": "cdn-config"}'
+	PathBackendConfigsKey = "networking.gke.io/path-backend-configs"
+
 	// UrlMapKey is the annotation key used by controller to record GCP URL map.
 	UrlMapKey = StatusPrefix + "/url-map"
 	// UrlMapKey is the annotation key used by controller to record GCP URL map used for Https Redirects only.
@@ -102,10 +152,21 @@ const (
 	// TargetHttpsProxyKey is the annotation key used by controller to record
 	// GCP target https proxy.
 	TargetHttpsProxyKey = StatusPrefix + "/https-target-proxy"
+	// TargetSslProxyKey is the annotation key used by controller to record
+	// GCP target ssl proxy.
+	TargetSslProxyKey = StatusPrefix + "/ssl-target-proxy"
+	// SslPolicyKey is the annotation key used by controller to record the
+	// resource path of the GCP SSL policy of the target https proxy.
+	SslPolicyKey = StatusPrefix + "/ssl-policy"
 	// SSLCertKey is the annotation key used by controller to record GCP ssl cert.
 	SSLCertKey = StatusPrefix + "/ssl-cert"
 	// StaticIPKey is the annotation key used by controller to record GCP static ip.
 	StaticIPKey = StatusPrefix + "/static-ip"
+	// ReadyConditionKey is the annotation key used by controller to record
+	// the Ready condition of the Ingress, once the load balancer serves the
+	// hosts and paths of the Ingress. It is only set if ingress warm-up is
+	// enabled.
+	ReadyConditionKey = StatusPrefix + "/ready"
 )
 
 // Ingress represents ingress annotations.
@@ -176,6 +237,26 @@ func (ing *Ingress) RegionalStaticIPName() string {
 	return val
 }
 
+// RegionalStaticIPSubnet returns the name of the subnet in which the
+// controller reserves the static ip of an internal Ingress. Empty by default.
+func (ing *Ingress) RegionalStaticIPSubnet() string {
+	return ing.v[RegionalStaticIPSubnetKey]
+}
+
+// IPVersion returns the IP version of the forwarding rules. IPv4Version by
+// default.
+func (ing *Ingress) IPVersion() (string, error) {
+	val, ok := ing.v[IPVersionKey]
+	if !ok {
+		return IPv4Version, nil
+	}
+	switch val {
+	case IPv4Version, IPv6Version:
+		return val, nil
+	}
+	return "", fmt.Errorf("invalid value %q for annotation %s, must be one of %q or %q", val, IPVersionKey, IPv4Version, IPv6Version)
+}
+
 func (ing *Ingress) IngressClass() string {
 	val, ok := ing.v[IngressClassKey]
 	if !ok {
@@ -198,6 +279,20 @@ func (ing *Ingress) SuppressFirewallXPNError() bool {
 	return v
 }
 
+// UrlMapSwap returns true if changes to the URL map must be applied by
+// swapping URL maps. False by default.
+func (ing *Ingress) UrlMapSwap() bool {
+	val, ok := ing.v[UrlMapSwapKey]
+	if !ok {
+		return false
+	}
+	v, err := strconv.ParseBool(val)
+	if err != nil {
+		return false
+	}
+	return v
+}
+
 func (ing *Ingress) FrontendConfig() string {
 	val, ok := ing.v[FrontendConfigKey]
 	if !ok {
@@ -205,3 +300,22 @@ func (ing *Ingress) FrontendConfig() string {
 	}
 	return val
 }
+
+// PathBackendConfigs returns the names of the BackendConfigs attached to the
+// backends of the paths of the Ingress, keyed by path. Nil if unset.
+func (ing *Ingress) PathBackendConfigs() (map[string]string, error) {
+	val, ok := ing.v[PathBackendConfigsKey]
+	if !ok {
+		return nil, nil
+	}
+	configs := map[string]string{}
+	if err := json.Unmarshal([]byte(val), &configs); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation %q: %v", PathBackendConfigsKey, val, err)
+	}
+	for path, name := range configs {
+		if name == "" {
+			return nil, fmt.Errorf("invalid %s annotation: empty BackendConfig name for path %q", PathBackendConfigsKey, path)
+		}
+	}
+	return configs, nil
+}
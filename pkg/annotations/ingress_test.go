@@ -17,6 +17,7 @@ limitations under the License.
 package annotations
 
 import (
+	"reflect"
 	"testing"
 
 	v1 "k8s.io/api/networking/v1"
@@ -31,6 +32,7 @@ func TestIngress(t *testing.T) {
 		useNamedTLS  string
 		staticIPName string
 		ingressClass string
+		urlMapSwap   bool
 		wantErr      bool
 	}{
 		{
@@ -63,6 +65,7 @@ func TestIngress(t *testing.T) {
 						IngressClassKey:       "gce",
 						PreSharedCertKey:      "shared-cert-key",
 						GlobalStaticIPNameKey: "1.2.3.4",
+						UrlMapSwapKey:         "true",
 					},
 				},
 			},
@@ -70,6 +73,7 @@ func TestIngress(t *testing.T) {
 			useNamedTLS:  "shared-cert-key",
 			staticIPName: "1.2.3.4",
 			ingressClass: "gce",
+			urlMapSwap:   true,
 		},
 	} {
 		ing := FromIngress(tc.ing)
@@ -90,5 +94,83 @@ func TestIngress(t *testing.T) {
 		if x := ing.IngressClass(); x != tc.ingressClass {
 			t.Errorf("ingress %+v; IngressClass() = %v, want %v", tc.ing, x, tc.ingressClass)
 		}
+		if x := ing.UrlMapSwap(); x != tc.urlMapSwap {
+			t.Errorf("ingress %+v; UrlMapSwap() = %v, want %v", tc.ing, x, tc.urlMapSwap)
+		}
+	}
+}
+
+func TestIPVersion(t *testing.T) {
+	for _, tc := range []struct {
+		desc        string
+		annotations map[string]string
+		want        string
+		wantErr     bool
+	}{
+		{
+			desc: "unset defaults to IPv4",
+			want: IPv4Version,
+		},
+		{
+			desc:        "IPv4",
+			annotations: map[string]string{IPVersionKey: IPv4Version},
+			want:        IPv4Version,
+		},
+		{
+			desc:        "IPv6",
+			annotations: map[string]string{IPVersionKey: IPv6Version},
+			want:        IPv6Version,
+		},
+		{
+			desc:        "invalid",
+			annotations: map[string]string{IPVersionKey: "ipv6"},
+			wantErr:     true,
+		},
+	} {
+		ing := FromIngress(&v1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}})
+		got, err := ing.IPVersion()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: IPVersion() = %v, wantErr = %v", tc.desc, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("%s: IPVersion() = %q, want %q", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestPathBackendConfigs(t *testing.T) {
+	for _, tc := range []struct {
+		desc        string
+		annotations map[string]string
+		want        map[string]string
+		wantErr     bool
+	}{
+		{
+			desc: "unset",
+		},
+		{
+			desc:        "paths",
+			annotations: map[string]string{PathBackendConfigsKey: `{"/static/*": "cdn", "/api": "no-cdn"}`},
+			want:        map[string]string{"/static/*": "cdn", "/api": "no-cdn"},
+		},
+		{
+			desc:        "invalid JSON",
+			annotations: map[string]string{PathBackendConfigsKey: `/static/*: cdn`},
+			wantErr:     true,
+		},
+		{
+			desc:        "empty name",
+			annotations: map[string]string{PathBackendConfigsKey: `{"/static/*": ""}`},
+			wantErr:     true,
+		},
+	} {
+		ing := FromIngress(&v1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}})
+		got, err := ing.PathBackendConfigs()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: PathBackendConfigs() = %v, wantErr = %v", tc.desc, err, tc.wantErr)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: PathBackendConfigs() = %v, want %v", tc.desc, got, tc.want)
+		}
 	}
 }
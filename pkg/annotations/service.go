@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
@@ -62,12 +63,25 @@ const (
 	// BackendConfigKey is GA version of backend config key.
 	BackendConfigKey = "cloud.google.com/backend-config"
 
+	// PortRangeKey is the annotation key of a contiguous range of ports
+	// forwarded by the external forwarding rule of a LoadBalancer service
+	// migrated to a regional backend service, e.g. "30000-32767", instead of
+	// the range spanned by the ports of the service. The range must contain
+	// the ports of the service.
+	PortRangeKey = "networking.gke.io/l4-port-range"
+
 	// ProtocolHTTP protocol for a service
 	ProtocolHTTP AppProtocol = "HTTP"
 	// ProtocolHTTPS protocol for a service
 	ProtocolHTTPS AppProtocol = "HTTPS"
 	// ProtocolHTTP2 protocol for a service
 	ProtocolHTTP2 AppProtocol = "HTTP2"
+	// ProtocolTCP is the protocol of backends behind a target SSL proxy which
+	// receive plain TCP. It cannot be set through the app-protocols annotation.
+	ProtocolTCP AppProtocol = "TCP"
+	// ProtocolSSL is the protocol of backends behind a target SSL proxy which
+	// receive TLS. It cannot be set through the app-protocols annotation.
+	ProtocolSSL AppProtocol = "SSL"
 
 	// ServiceStatusPrefix is the prefix used in annotations used to record
 	// debug information in the Service annotations. This is applicable to L4 ILB services.
@@ -266,6 +280,31 @@ func (svc *Service) ApplicationProtocols() (map[string]AppProtocol, error) {
 	return portToProtos, err
 }
 
+// PortRange returns the first and last port of the port range annotation, and
+// whether the annotation is set.
+func (svc *Service) PortRange() (int32, int32, bool, error) {
+	val, ok := svc.v[PortRangeKey]
+	if !ok {
+		return 0, 0, false, nil
+	}
+	parts := strings.Split(val, "-")
+	if len(parts) != 2 {
+		return 0, 0, true, fmt.Errorf("invalid port range %q, must be of the form <first>-<last>", val)
+	}
+	var ports [2]int32
+	for i, part := range parts {
+		port, err := strconv.ParseInt(strings.TrimSpace(part), 10, 32)
+		if err != nil || port < 1 || port > 65535 {
+			return 0, 0, true, fmt.Errorf("invalid port %q in port range %q", part, val)
+		}
+		ports[i] = int32(port)
+	}
+	if ports[0] > ports[1] {
+		return 0, 0, true, fmt.Errorf("invalid port range %q, first port is greater than last port", val)
+	}
+	return ports[0], ports[1], true, nil
+}
+
 var (
 	ErrBackendConfigNoneFound         = errors.New("no BackendConfig's found in annotation")
 	ErrBackendConfigInvalidJSON       = errors.New("BackendConfig annotation is invalid json")
@@ -316,6 +316,73 @@ func TestService(t *testing.T) {
 	}
 }
 
+func TestPortRange(t *testing.T) {
+	for _, tc := range []struct {
+		desc      string
+		value     *string
+		wantFirst int32
+		wantLast  int32
+		wantErr   bool
+	}{
+		{
+			desc: "not set",
+		},
+		{
+			desc:      "range",
+			value:     strPtr("30000-32767"),
+			wantFirst: 30000,
+			wantLast:  32767,
+		},
+		{
+			desc:      "single port",
+			value:     strPtr("8080-8080"),
+			wantFirst: 8080,
+			wantLast:  8080,
+		},
+		{
+			desc:    "single number",
+			value:   strPtr("8080"),
+			wantErr: true,
+		},
+		{
+			desc:    "reversed",
+			value:   strPtr("9000-8000"),
+			wantErr: true,
+		},
+		{
+			desc:    "out of range",
+			value:   strPtr("1-65536"),
+			wantErr: true,
+		},
+		{
+			desc:    "not a number",
+			value:   strPtr("a-b"),
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc := &v1.Service{}
+			if tc.value != nil {
+				svc.Annotations = map[string]string{PortRangeKey: *tc.value}
+			}
+			first, last, ok, err := FromService(svc).PortRange()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("PortRange() = %v, want error %v", err, tc.wantErr)
+			}
+			if ok != (tc.value != nil) {
+				t.Errorf("PortRange() = _, _, %v, want %v", ok, tc.value != nil)
+			}
+			if !tc.wantErr && (first != tc.wantFirst || last != tc.wantLast) {
+				t.Errorf("PortRange() = %d, %d, want %d, %d", first, last, tc.wantFirst, tc.wantLast)
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
 func TestBackendConfigs(t *testing.T) {
 	testcases := []struct {
 		desc            string
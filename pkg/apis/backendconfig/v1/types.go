@@ -46,6 +46,11 @@ type BackendConfigSpec struct {
 	HealthCheck          *HealthCheckConfig          `json:"healthCheck,omitempty"`
 	// Logging specifies the configuration for access logs.
 	Logging *LogConfig `json:"logging,omitempty"`
+	// RateLimit specifies per client IP rate limiting enforced through a
+	// Cloud Armor security policy managed by the controller.
+	RateLimit *RateLimitConfig `json:"rateLimit,omitempty"`
+	// Balancing overrides the balancing mode of instance group backends.
+	Balancing *BalancingConfig `json:"balancing,omitempty"`
 }
 
 // BackendConfigStatus is the status for a BackendConfig resource
@@ -120,6 +125,39 @@ type SecurityPolicyConfig struct {
 	Name string `json:"name"`
 }
 
+// RateLimitConfig contains configuration for per client IP rate limiting.
+// The controller materializes it as a Cloud Armor security policy that is
+// owned by the backend service. It cannot be combined with SecurityPolicy.
+// +k8s:openapi-gen=true
+type RateLimitConfig struct {
+	// RequestsPerMinute is the number of requests a single client IP may send
+	// per minute before being throttled.
+	RequestsPerMinute int64 `json:"requestsPerMinute"`
+	// BanDurationSec is the number of seconds a client IP exceeding the limit
+	// is banned for. If unset or zero, excess requests are only throttled.
+	BanDurationSec *int64 `json:"banDurationSec,omitempty"`
+	// AdaptiveProtection enables or disables Cloud Armor Adaptive Protection,
+	// the layer 7 DDoS defense, on the managed security policy. If unset, the
+	// setting of the policy is not reconciled.
+	AdaptiveProtection *bool `json:"adaptiveProtection,omitempty"`
+}
+
+// BalancingConfig contains the balancing mode settings used for instance
+// group backends. It has no effect on NEG backends. Note that GCE rejects
+// different balancing modes for the same instance group, so all services
+// sharing the cluster instance groups should agree on the mode.
+// +k8s:openapi-gen=true
+type BalancingConfig struct {
+	// BalancingMode is either RATE or UTILIZATION.
+	BalancingMode string `json:"balancingMode,omitempty"`
+	// MaxRatePerInstance is the target requests per second per instance.
+	// Only valid with the RATE balancing mode.
+	MaxRatePerInstance *float64 `json:"maxRatePerInstance,omitempty"`
+	// MaxUtilization is the target CPU utilization of the instance group in
+	// [0, 1]. Only valid with the UTILIZATION balancing mode.
+	MaxUtilization *float64 `json:"maxUtilization,omitempty"`
+}
+
 // ConnectionDrainingConfig contains configuration for connection draining.
 // For now the draining timeout. May manage more settings in the future.
 // +k8s:openapi-gen=true
@@ -166,6 +204,14 @@ type HealthCheckConfig struct {
 	// RequestPath is a health check parameter. See
 	// https://cloud.google.com/compute/docs/reference/rest/v1/healthChecks.
 	RequestPath *string `json:"requestPath,omitempty"`
+	// PortSpecification is either USE_SERVING_PORT, to probe NEG endpoints
+	// on their serving port, or USE_FIXED_PORT, which requires Port. See
+	// https://cloud.google.com/compute/docs/reference/rest/v1/healthChecks.
+	PortSpecification *string `json:"portSpecification,omitempty"`
+	// ProxyHeader is either NONE or PROXY_V1, to prepend a PROXY protocol
+	// header to the probes. See
+	// https://cloud.google.com/compute/docs/reference/rest/v1/healthChecks.
+	ProxyHeader *string `json:"proxyHeader,omitempty"`
 }
 
 // LogConfig contains configuration for logging.
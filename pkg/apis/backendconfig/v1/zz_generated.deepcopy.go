@@ -133,6 +133,16 @@ func (in *BackendConfigSpec) DeepCopyInto(out *BackendConfigSpec) {
 		*out = new(LogConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimitConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Balancing != nil {
+		in, out := &in.Balancing, &out.Balancing
+		*out = new(BalancingConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -162,6 +172,32 @@ func (in *BackendConfigStatus) DeepCopy() *BackendConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BalancingConfig) DeepCopyInto(out *BalancingConfig) {
+	*out = *in
+	if in.MaxRatePerInstance != nil {
+		in, out := &in.MaxRatePerInstance, &out.MaxRatePerInstance
+		*out = new(float64)
+		**out = **in
+	}
+	if in.MaxUtilization != nil {
+		in, out := &in.MaxUtilization, &out.MaxUtilization
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BalancingConfig.
+func (in *BalancingConfig) DeepCopy() *BalancingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BalancingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CDNConfig) DeepCopyInto(out *CDNConfig) {
 	*out = *in
@@ -284,6 +320,16 @@ func (in *HealthCheckConfig) DeepCopyInto(out *HealthCheckConfig) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.PortSpecification != nil {
+		in, out := &in.PortSpecification, &out.PortSpecification
+		*out = new(string)
+		**out = **in
+	}
+	if in.ProxyHeader != nil {
+		in, out := &in.ProxyHeader, &out.ProxyHeader
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -355,6 +401,32 @@ func (in *OAuthClientCredentials) DeepCopy() *OAuthClientCredentials {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitConfig) DeepCopyInto(out *RateLimitConfig) {
+	*out = *in
+	if in.BanDurationSec != nil {
+		in, out := &in.BanDurationSec, &out.BanDurationSec
+		*out = new(int64)
+		**out = **in
+	}
+	if in.AdaptiveProtection != nil {
+		in, out := &in.AdaptiveProtection, &out.AdaptiveProtection
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitConfig.
+func (in *RateLimitConfig) DeepCopy() *RateLimitConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityPolicyConfig) DeepCopyInto(out *SecurityPolicyConfig) {
 	*out = *in
@@ -31,6 +31,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 	return map[string]common.OpenAPIDefinition{
 		"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.BackendConfig":              schema_pkg_apis_backendconfig_v1_BackendConfig(ref),
 		"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.BackendConfigSpec":          schema_pkg_apis_backendconfig_v1_BackendConfigSpec(ref),
+		"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.BalancingConfig":            schema_pkg_apis_backendconfig_v1_BalancingConfig(ref),
 		"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.CDNConfig":                  schema_pkg_apis_backendconfig_v1_CDNConfig(ref),
 		"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.CacheKeyPolicy":             schema_pkg_apis_backendconfig_v1_CacheKeyPolicy(ref),
 		"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.ConnectionDrainingConfig":   schema_pkg_apis_backendconfig_v1_ConnectionDrainingConfig(ref),
@@ -39,6 +40,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.IAPConfig":                  schema_pkg_apis_backendconfig_v1_IAPConfig(ref),
 		"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.LogConfig":                  schema_pkg_apis_backendconfig_v1_LogConfig(ref),
 		"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.OAuthClientCredentials":     schema_pkg_apis_backendconfig_v1_OAuthClientCredentials(ref),
+		"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.RateLimitConfig":            schema_pkg_apis_backendconfig_v1_RateLimitConfig(ref),
 		"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.SecurityPolicyConfig":       schema_pkg_apis_backendconfig_v1_SecurityPolicyConfig(ref),
 		"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.SessionAffinityConfig":      schema_pkg_apis_backendconfig_v1_SessionAffinityConfig(ref),
 	}
@@ -141,11 +143,57 @@ func schema_pkg_apis_backendconfig_v1_BackendConfigSpec(ref common.ReferenceCall
 							Ref:         ref("k8s.io/ingress-gce/pkg/apis/backendconfig/v1.LogConfig"),
 						},
 					},
+					"rateLimit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RateLimit specifies per client IP rate limiting enforced through a Cloud Armor security policy managed by the controller.",
+							Ref:         ref("k8s.io/ingress-gce/pkg/apis/backendconfig/v1.RateLimitConfig"),
+						},
+					},
+					"balancing": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Balancing overrides the balancing mode of instance group backends.",
+							Ref:         ref("k8s.io/ingress-gce/pkg/apis/backendconfig/v1.BalancingConfig"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.CDNConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.ConnectionDrainingConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.CustomRequestHeadersConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.HealthCheckConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.IAPConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.LogConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.SecurityPolicyConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.SessionAffinityConfig"},
+			"k8s.io/ingress-gce/pkg/apis/backendconfig/v1.BalancingConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.CDNConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.ConnectionDrainingConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.CustomRequestHeadersConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.HealthCheckConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.IAPConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.LogConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.RateLimitConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.SecurityPolicyConfig", "k8s.io/ingress-gce/pkg/apis/backendconfig/v1.SessionAffinityConfig"},
+	}
+}
+
+func schema_pkg_apis_backendconfig_v1_BalancingConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BalancingConfig contains the balancing mode settings used for instance group backends. It has no effect on NEG backends. Note that GCE rejects different balancing modes for the same instance group, so all services sharing the cluster instance groups should agree on the mode.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"balancingMode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BalancingMode is either RATE or UTILIZATION.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"maxRatePerInstance": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxRatePerInstance is the target requests per second per instance. Only valid with the RATE balancing mode.",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+					"maxUtilization": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxUtilization is the target CPU utilization of the instance group in [0, 1]. Only valid with the UTILIZATION balancing mode.",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -340,6 +388,20 @@ func schema_pkg_apis_backendconfig_v1_HealthCheckConfig(ref common.ReferenceCall
 							Format:      "",
 						},
 					},
+					"portSpecification": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PortSpecification is either USE_SERVING_PORT, to probe NEG endpoints on their serving port, or USE_FIXED_PORT, which requires Port. See https://cloud.google.com/compute/docs/reference/rest/v1/healthChecks.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"proxyHeader": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ProxyHeader is either NONE or PROXY_V1, to prepend a PROXY protocol header to the probes. See https://cloud.google.com/compute/docs/reference/rest/v1/healthChecks.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -435,6 +497,41 @@ func schema_pkg_apis_backendconfig_v1_OAuthClientCredentials(ref common.Referenc
 	}
 }
 
+func schema_pkg_apis_backendconfig_v1_RateLimitConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RateLimitConfig contains configuration for per client IP rate limiting. The controller materializes it as a Cloud Armor security policy that is owned by the backend service. It cannot be combined with SecurityPolicy.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"requestsPerMinute": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RequestsPerMinute is the number of requests a single client IP may send per minute before being throttled.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"banDurationSec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BanDurationSec is the number of seconds a client IP exceeding the limit is banned for. If unset or zero, excess requests are only throttled.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"adaptiveProtection": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AdaptiveProtection enables or disables Cloud Armor Adaptive Protection, the layer 7 DDoS defense, on the managed security policy. If unset, the setting of the policy is not reconciled.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"requestsPerMinute"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_backendconfig_v1_SecurityPolicyConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
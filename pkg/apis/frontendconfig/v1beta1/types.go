@@ -49,7 +49,29 @@ type HttpsRedirectConfig struct {
 }
 
 // FrontendConfigStatus is the status for a FrontendConfig resource
-type FrontendConfigStatus struct{}
+// +k8s:openapi-gen=true
+type FrontendConfigStatus struct {
+	// TargetProxies are the names of the target proxies of the Ingresses
+	// that reference the FrontendConfig.
+	TargetProxies []string `json:"targetProxies,omitempty"`
+	// Ingresses are the statuses of the Ingresses that reference the
+	// FrontendConfig.
+	Ingresses []FrontendConfigIngressStatus `json:"ingresses,omitempty"`
+}
+
+// FrontendConfigIngressStatus is the status of the FrontendConfig for an
+// Ingress that references it.
+// +k8s:openapi-gen=true
+type FrontendConfigIngressStatus struct {
+	// Name is the namespaced name of the Ingress.
+	Name string `json:"name"`
+	// SslPolicy is the resource path of the SSL policy applied to the
+	// target HTTPS proxy of the Ingress, if one is set.
+	SslPolicy string `json:"sslPolicy,omitempty"`
+	// LastError is the error of the last failed sync of the Ingress. It is
+	// cleared by a successful sync.
+	LastError string `json:"lastError,omitempty"`
+}
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
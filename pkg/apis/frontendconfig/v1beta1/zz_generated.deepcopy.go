@@ -30,7 +30,7 @@ func (in *FrontendConfig) DeepCopyInto(out *FrontendConfig) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -52,6 +52,22 @@ func (in *FrontendConfig) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FrontendConfigIngressStatus) DeepCopyInto(out *FrontendConfigIngressStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontendConfigIngressStatus.
+func (in *FrontendConfigIngressStatus) DeepCopy() *FrontendConfigIngressStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FrontendConfigIngressStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FrontendConfigList) DeepCopyInto(out *FrontendConfigList) {
 	*out = *in
@@ -114,6 +130,16 @@ func (in *FrontendConfigSpec) DeepCopy() *FrontendConfigSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FrontendConfigStatus) DeepCopyInto(out *FrontendConfigStatus) {
 	*out = *in
+	if in.TargetProxies != nil {
+		in, out := &in.TargetProxies, &out.TargetProxies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ingresses != nil {
+		in, out := &in.Ingresses, &out.Ingresses
+		*out = make([]FrontendConfigIngressStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -41,6 +41,36 @@ type GCPIngressParamsSpec struct {
 	// The default is external load balancing, so Internal will default to false.
 	// +required
 	Internal bool `json:"internal"`
+
+	// DefaultFrontendConfig is the FrontendConfig, in the form namespace/name,
+	// applied to Ingresses of the class that do not reference one.
+	// +optional
+	DefaultFrontendConfig string `json:"defaultFrontendConfig,omitempty"`
+
+	// DefaultBackendTimeoutSec is the timeout, in seconds, of backend
+	// services created for Ingresses of the class. It takes precedence over
+	// the controller default and is overridden by BackendConfig.
+	// +optional
+	DefaultBackendTimeoutSec *int64 `json:"defaultBackendTimeoutSec,omitempty"`
+
+	// NamespaceLimits override the per-namespace limits of the controller
+	// for Ingresses of the class.
+	// +optional
+	NamespaceLimits *NamespaceLimits `json:"namespaceLimits,omitempty"`
+}
+
+// NamespaceLimits are limits on the resources provisioned per namespace.
+// Unset fields use the controller default, zero means unlimited.
+// +k8s:openapi-gen=true
+type NamespaceLimits struct {
+	// MaxIngresses is the maximum number of Ingresses per namespace.
+	// +optional
+	MaxIngresses *int32 `json:"maxIngresses,omitempty"`
+
+	// MaxCertificates is the maximum number of certificates, TLS secrets
+	// and pre-shared certificates, used by the Ingresses of a namespace.
+	// +optional
+	MaxCertificates *int32 `json:"maxCertificates,omitempty"`
 }
 
 // GCPIngressParamsStatus is the status for a GCPIngressParams resource
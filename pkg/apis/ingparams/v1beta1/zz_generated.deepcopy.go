@@ -29,7 +29,7 @@ func (in *GCPIngressParams) DeepCopyInto(out *GCPIngressParams) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 	return
 }
@@ -88,6 +88,16 @@ func (in *GCPIngressParamsList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GCPIngressParamsSpec) DeepCopyInto(out *GCPIngressParamsSpec) {
 	*out = *in
+	if in.DefaultBackendTimeoutSec != nil {
+		in, out := &in.DefaultBackendTimeoutSec, &out.DefaultBackendTimeoutSec
+		*out = new(int64)
+		**out = **in
+	}
+	if in.NamespaceLimits != nil {
+		in, out := &in.NamespaceLimits, &out.NamespaceLimits
+		*out = new(NamespaceLimits)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -116,3 +126,29 @@ func (in *GCPIngressParamsStatus) DeepCopy() *GCPIngressParamsStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceLimits) DeepCopyInto(out *NamespaceLimits) {
+	*out = *in
+	if in.MaxIngresses != nil {
+		in, out := &in.MaxIngresses, &out.MaxIngresses
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxCertificates != nil {
+		in, out := &in.MaxCertificates, &out.MaxCertificates
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceLimits.
+func (in *NamespaceLimits) DeepCopy() *NamespaceLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceLimits)
+	in.DeepCopyInto(out)
+	return out
+}
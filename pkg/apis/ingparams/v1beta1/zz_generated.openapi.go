@@ -31,6 +31,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 	return map[string]common.OpenAPIDefinition{
 		"k8s.io/ingress-gce/pkg/apis/ingparams/v1beta1.GCPIngressParams":     schema_pkg_apis_ingparams_v1beta1_GCPIngressParams(ref),
 		"k8s.io/ingress-gce/pkg/apis/ingparams/v1beta1.GCPIngressParamsSpec": schema_pkg_apis_ingparams_v1beta1_GCPIngressParamsSpec(ref),
+		"k8s.io/ingress-gce/pkg/apis/ingparams/v1beta1.NamespaceLimits":      schema_pkg_apis_ingparams_v1beta1_NamespaceLimits(ref),
 	}
 }
 
@@ -92,9 +93,58 @@ func schema_pkg_apis_ingparams_v1beta1_GCPIngressParamsSpec(ref common.Reference
 							Format:      "",
 						},
 					},
+					"defaultFrontendConfig": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DefaultFrontendConfig is the FrontendConfig, in the form namespace/name, applied to Ingresses of the class that do not reference one.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"defaultBackendTimeoutSec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DefaultBackendTimeoutSec is the timeout, in seconds, of backend services created for Ingresses of the class. It takes precedence over the controller default and is overridden by BackendConfig.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"namespaceLimits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NamespaceLimits override the per-namespace limits of the controller for Ingresses of the class.",
+							Ref:         ref("k8s.io/ingress-gce/pkg/apis/ingparams/v1beta1.NamespaceLimits"),
+						},
+					},
 				},
 				Required: []string{"internal"},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/ingress-gce/pkg/apis/ingparams/v1beta1.NamespaceLimits"},
+	}
+}
+
+func schema_pkg_apis_ingparams_v1beta1_NamespaceLimits(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "NamespaceLimits are limits on the resources provisioned per namespace. Unset fields use the controller default, zero means unlimited.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"maxIngresses": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxIngresses is the maximum number of Ingresses per namespace.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"maxCertificates": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxCertificates is the maximum number of certificates, TLS secrets and pre-shared certificates, used by the Ingresses of a namespace.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
 	}
 }
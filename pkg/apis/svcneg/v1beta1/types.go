@@ -54,6 +54,63 @@ type ServiceNetworkEndpointGroupStatus struct {
 	// Last time the NEG syncer syncs associated NEGs.
 	// +optional
 	LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Published is the NEG of the service port as published in the
+	// cloud.google.com/neg-status annotation of the service. It is updated
+	// from the same status as the annotation.
+	// +optional
+	Published *PublishedNegStatus `json:"published,omitempty"`
+
+	// EndpointDistribution is the distribution of the endpoints of the NEGs
+	// across zones compared to the distribution of the nodes, as of the last
+	// sync.
+	// +optional
+	EndpointDistribution *EndpointDistribution `json:"endpointDistribution,omitempty"`
+}
+
+// EndpointDistribution describes how the endpoints of the NEGs are spread
+// across zones compared to the nodes of the cluster.
+// +k8s:openapi-gen=true
+type EndpointDistribution struct {
+	// Zones are the endpoint and node counts of every zone with endpoints
+	// or nodes.
+	// +optional
+	// +listType=map
+	// +listMapKey=zone
+	Zones []ZoneEndpointCount `json:"zones,omitempty"`
+
+	// Skewed is true if the share of the endpoints of some zone differs
+	// heavily from its share of the nodes. Skewed services are imbalanced
+	// with the RATE balancing mode.
+	Skewed bool `json:"skewed"`
+}
+
+// ZoneEndpointCount is the number of endpoints and nodes in a zone.
+// +k8s:openapi-gen=true
+type ZoneEndpointCount struct {
+	// Zone is the name of the zone.
+	Zone string `json:"zone"`
+
+	// Endpoints is the number of endpoints in the zone.
+	Endpoints int32 `json:"endpoints"`
+
+	// Nodes is the number of ready nodes in the zone.
+	Nodes int32 `json:"nodes"`
+}
+
+// PublishedNegStatus describes the NEGs of a service port for consumption by
+// other controllers.
+// +k8s:openapi-gen=true
+type PublishedNegStatus struct {
+	// Name is the name of the NEGs.
+	Name string `json:"name"`
+
+	// Port is the service port served by the NEGs.
+	Port int32 `json:"port"`
+
+	// Zones are the zones in which the NEGs exist.
+	// +optional
+	Zones []string `json:"zones,omitempty"`
 }
 
 // NegObjectReference is the object reference to the NEG resource in GCE
@@ -69,6 +126,9 @@ type NegObjectReference struct {
 	// NetworkEndpointType: Type of network endpoints in this network
 	// endpoint group.
 	NetworkEndpointType NetworkEndpointType `json:"networkEndpointType,omitempty"`
+
+	// Zone is the zone of the NEG resource.
+	Zone string `json:"zone,omitempty"`
 }
 
 // +k8s:openapi-gen=true
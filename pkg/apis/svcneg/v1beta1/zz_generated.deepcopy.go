@@ -41,6 +41,27 @@ func (in *Condition) DeepCopy() *Condition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointDistribution) DeepCopyInto(out *EndpointDistribution) {
+	*out = *in
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]ZoneEndpointCount, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointDistribution.
+func (in *EndpointDistribution) DeepCopy() *EndpointDistribution {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointDistribution)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NegObjectReference) DeepCopyInto(out *NegObjectReference) {
 	*out = *in
@@ -57,6 +78,27 @@ func (in *NegObjectReference) DeepCopy() *NegObjectReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublishedNegStatus) DeepCopyInto(out *PublishedNegStatus) {
+	*out = *in
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublishedNegStatus.
+func (in *PublishedNegStatus) DeepCopy() *PublishedNegStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PublishedNegStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceNetworkEndpointGroup) DeepCopyInto(out *ServiceNetworkEndpointGroup) {
 	*out = *in
@@ -150,6 +192,16 @@ func (in *ServiceNetworkEndpointGroupStatus) DeepCopyInto(out *ServiceNetworkEnd
 		}
 	}
 	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	if in.Published != nil {
+		in, out := &in.Published, &out.Published
+		*out = new(PublishedNegStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EndpointDistribution != nil {
+		in, out := &in.EndpointDistribution, &out.EndpointDistribution
+		*out = new(EndpointDistribution)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -162,3 +214,19 @@ func (in *ServiceNetworkEndpointGroupStatus) DeepCopy() *ServiceNetworkEndpointG
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneEndpointCount) DeepCopyInto(out *ZoneEndpointCount) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneEndpointCount.
+func (in *ZoneEndpointCount) DeepCopy() *ZoneEndpointCount {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneEndpointCount)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -30,9 +30,12 @@ import (
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
 		"k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.Condition":                         schema_pkg_apis_svcneg_v1beta1_Condition(ref),
+		"k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.EndpointDistribution":              schema_pkg_apis_svcneg_v1beta1_EndpointDistribution(ref),
 		"k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.NegObjectReference":                schema_pkg_apis_svcneg_v1beta1_NegObjectReference(ref),
+		"k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.PublishedNegStatus":                schema_pkg_apis_svcneg_v1beta1_PublishedNegStatus(ref),
 		"k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.ServiceNetworkEndpointGroup":       schema_pkg_apis_svcneg_v1beta1_ServiceNetworkEndpointGroup(ref),
 		"k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.ServiceNetworkEndpointGroupStatus": schema_pkg_apis_svcneg_v1beta1_ServiceNetworkEndpointGroupStatus(ref),
+		"k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.ZoneEndpointCount":                 schema_pkg_apis_svcneg_v1beta1_ZoneEndpointCount(ref),
 	}
 }
 
@@ -93,6 +96,50 @@ func schema_pkg_apis_svcneg_v1beta1_Condition(ref common.ReferenceCallback) comm
 	}
 }
 
+func schema_pkg_apis_svcneg_v1beta1_EndpointDistribution(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "EndpointDistribution describes how the endpoints of the NEGs are spread across zones compared to the nodes of the cluster.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"zones": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"zone",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Zones are the endpoint and node counts of every zone with endpoints or nodes.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.ZoneEndpointCount"),
+									},
+								},
+							},
+						},
+					},
+					"skewed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Skewed is true if the share of the endpoints of some zone differs heavily from its share of the nodes. Skewed services are imbalanced with the RATE balancing mode.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"skewed"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.ZoneEndpointCount"},
+	}
+}
+
 func schema_pkg_apis_svcneg_v1beta1_NegObjectReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -121,6 +168,13 @@ func schema_pkg_apis_svcneg_v1beta1_NegObjectReference(ref common.ReferenceCallb
 							Format:      "",
 						},
 					},
+					"zone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Zone is the zone of the NEG resource.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"id"},
 			},
@@ -128,6 +182,48 @@ func schema_pkg_apis_svcneg_v1beta1_NegObjectReference(ref common.ReferenceCallb
 	}
 }
 
+func schema_pkg_apis_svcneg_v1beta1_PublishedNegStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PublishedNegStatus describes the NEGs of a service port for consumption by other controllers.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of the NEGs.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"port": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Port is the service port served by the NEGs.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"zones": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Zones are the zones in which the NEGs exist.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"name", "port"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_svcneg_v1beta1_ServiceNetworkEndpointGroup(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -225,10 +321,57 @@ func schema_pkg_apis_svcneg_v1beta1_ServiceNetworkEndpointGroupStatus(ref common
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
+					"published": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Published is the NEG of the service port as published in the cloud.google.com/neg-status annotation of the service. It is updated from the same status as the annotation.",
+							Ref:         ref("k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.PublishedNegStatus"),
+						},
+					},
+					"endpointDistribution": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EndpointDistribution is the distribution of the endpoints of the NEGs across zones compared to the distribution of the nodes, as of the last sync.",
+							Ref:         ref("k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.EndpointDistribution"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.Time", "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.Condition", "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.NegObjectReference"},
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time", "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.Condition", "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.EndpointDistribution", "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.NegObjectReference", "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1.PublishedNegStatus"},
+	}
+}
+
+func schema_pkg_apis_svcneg_v1beta1_ZoneEndpointCount(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ZoneEndpointCount is the number of endpoints and nodes in a zone.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"zone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Zone is the name of the zone.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"endpoints": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Endpoints is the number of endpoints in the zone.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"nodes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Nodes is the number of ready nodes in the zone.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"zone", "endpoints", "nodes"},
+			},
+		},
 	}
 }
@@ -21,6 +21,7 @@ import (
 
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 
 	"k8s.io/ingress-gce/pkg/annotations"
@@ -70,11 +71,16 @@ func GetBackendConfigForServicePort(backendConfigLister cache.Store, svc *apiv1.
 		return nil, ErrNoBackendConfigForPort
 	}
 
+	return GetBackendConfig(backendConfigLister, types.NamespacedName{Namespace: svc.Namespace, Name: configName})
+}
+
+// GetBackendConfig returns the BackendConfig with the given name.
+func GetBackendConfig(backendConfigLister cache.Store, name types.NamespacedName) (*backendconfigv1.BackendConfig, error) {
 	obj, exists, err := backendConfigLister.Get(
 		&backendconfigv1.BackendConfig{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      configName,
-				Namespace: svc.Namespace,
+				Name:      name.Name,
+				Namespace: name.Namespace,
 			},
 		})
 	if err != nil {
@@ -19,6 +19,7 @@ package backendconfig
 import (
 	"context"
 	"fmt"
+	"math"
 
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -30,6 +31,11 @@ const (
 	OAuthClientSecretKey = "client_secret"
 )
 
+var supportedBalancingModes = map[string]bool{
+	"RATE":        true,
+	"UTILIZATION": true,
+}
+
 var supportedAffinities = map[string]bool{
 	"NONE":             true,
 	"CLIENT_IP":        true,
@@ -53,6 +59,22 @@ func Validate(kubeClient kubernetes.Interface, beConfig *backendconfigv1.Backend
 		return err
 	}
 
+	if err := validateRateLimit(beConfig); err != nil {
+		return err
+	}
+
+	if err := validateBalancing(beConfig); err != nil {
+		return err
+	}
+
+	if err := validateTimeout(beConfig); err != nil {
+		return err
+	}
+
+	if err := validateHealthCheck(beConfig); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -122,3 +144,103 @@ func validateLogging(beConfig *backendconfigv1.BackendConfig) error {
 
 	return nil
 }
+
+func validateRateLimit(beConfig *backendconfigv1.BackendConfig) error {
+	if beConfig.Spec.RateLimit == nil {
+		return nil
+	}
+
+	if beConfig.Spec.SecurityPolicy != nil {
+		return fmt.Errorf("rateLimit and securityPolicy cannot be specified at the same time")
+	}
+
+	if beConfig.Spec.RateLimit.RequestsPerMinute <= 0 {
+		return fmt.Errorf("unsupported RequestsPerMinute: %d, should be greater than 0",
+			beConfig.Spec.RateLimit.RequestsPerMinute)
+	}
+
+	if beConfig.Spec.RateLimit.BanDurationSec != nil && *beConfig.Spec.RateLimit.BanDurationSec < 0 {
+		return fmt.Errorf("unsupported BanDurationSec: %d, should be greater than or equal to 0",
+			*beConfig.Spec.RateLimit.BanDurationSec)
+	}
+
+	return nil
+}
+
+func validateBalancing(beConfig *backendconfigv1.BackendConfig) error {
+	balancing := beConfig.Spec.Balancing
+	if balancing == nil {
+		return nil
+	}
+
+	if !supportedBalancingModes[balancing.BalancingMode] {
+		return fmt.Errorf("unsupported BalancingMode: %q, should be one of RATE or UTILIZATION", balancing.BalancingMode)
+	}
+
+	if balancing.MaxRatePerInstance != nil {
+		if balancing.BalancingMode != "RATE" {
+			return fmt.Errorf("MaxRatePerInstance can only be set with the RATE balancing mode")
+		}
+		if *balancing.MaxRatePerInstance <= 0 {
+			return fmt.Errorf("unsupported MaxRatePerInstance: %f, should be greater than 0", *balancing.MaxRatePerInstance)
+		}
+	}
+
+	if balancing.MaxUtilization != nil {
+		if balancing.BalancingMode != "UTILIZATION" {
+			return fmt.Errorf("MaxUtilization can only be set with the UTILIZATION balancing mode")
+		}
+		if *balancing.MaxUtilization < 0.0 || *balancing.MaxUtilization > 1.0 {
+			return fmt.Errorf("unsupported MaxUtilization: %f, should be between 0.0 and 1.0", *balancing.MaxUtilization)
+		}
+	}
+
+	return nil
+}
+
+// maxTimeoutSec is the maximum backend service timeout accepted by GCE.
+const maxTimeoutSec = math.MaxInt32
+
+// ValidateTimeoutSec returns an error if sec is not a backend service
+// timeout accepted by GCE.
+func ValidateTimeoutSec(sec int64) error {
+	if sec < 1 || sec > maxTimeoutSec {
+		return fmt.Errorf("unsupported TimeoutSec: %d, should be between 1 and %d", sec, maxTimeoutSec)
+	}
+	return nil
+}
+
+func validateTimeout(beConfig *backendconfigv1.BackendConfig) error {
+	if beConfig.Spec.TimeoutSec == nil {
+		return nil
+	}
+	return ValidateTimeoutSec(*beConfig.Spec.TimeoutSec)
+}
+
+func validateHealthCheck(beConfig *backendconfigv1.BackendConfig) error {
+	healthCheck := beConfig.Spec.HealthCheck
+	if healthCheck == nil {
+		return nil
+	}
+
+	if healthCheck.PortSpecification != nil {
+		switch *healthCheck.PortSpecification {
+		case "USE_SERVING_PORT":
+			if healthCheck.Port != nil {
+				return fmt.Errorf("Port cannot be set with the USE_SERVING_PORT PortSpecification")
+			}
+		case "USE_FIXED_PORT":
+			if healthCheck.Port == nil {
+				return fmt.Errorf("Port is required with the USE_FIXED_PORT PortSpecification")
+			}
+		default:
+			return fmt.Errorf("unsupported PortSpecification: %q, should be one of USE_SERVING_PORT or USE_FIXED_PORT", *healthCheck.PortSpecification)
+		}
+	}
+
+	if healthCheck.ProxyHeader != nil && *healthCheck.ProxyHeader != "NONE" && *healthCheck.ProxyHeader != "PROXY_V1" {
+		return fmt.Errorf("unsupported ProxyHeader: %q, should be one of NONE or PROXY_V1", *healthCheck.ProxyHeader)
+	}
+
+	return nil
+}
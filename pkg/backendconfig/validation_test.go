@@ -328,3 +328,252 @@ func TestValidateLogging(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRateLimit(t *testing.T) {
+	for _, tc := range []struct {
+		desc        string
+		spec        backendconfigv1.BackendConfigSpec
+		expectError bool
+	}{
+		{
+			desc: "nil rate limit config",
+			spec: backendconfigv1.BackendConfigSpec{},
+		},
+		{
+			desc: "valid rate limit",
+			spec: backendconfigv1.BackendConfigSpec{
+				RateLimit: &backendconfigv1.RateLimitConfig{
+					RequestsPerMinute: 100,
+					BanDurationSec:    testutils.Int64ToPtr(600),
+				},
+			},
+		},
+		{
+			desc: "zero requests per minute",
+			spec: backendconfigv1.BackendConfigSpec{
+				RateLimit: &backendconfigv1.RateLimitConfig{},
+			},
+			expectError: true,
+		},
+		{
+			desc: "negative ban duration",
+			spec: backendconfigv1.BackendConfigSpec{
+				RateLimit: &backendconfigv1.RateLimitConfig{
+					RequestsPerMinute: 100,
+					BanDurationSec:    testutils.Int64ToPtr(-1),
+				},
+			},
+			expectError: true,
+		},
+		{
+			desc: "rate limit with security policy",
+			spec: backendconfigv1.BackendConfigSpec{
+				SecurityPolicy: &backendconfigv1.SecurityPolicyConfig{Name: "policy-1"},
+				RateLimit: &backendconfigv1.RateLimitConfig{
+					RequestsPerMinute: 100,
+				},
+			},
+			expectError: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			beConfig := &backendconfigv1.BackendConfig{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: tc.spec,
+			}
+			err := Validate(fake.NewSimpleClientset(), beConfig)
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error but got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Did not expect error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateBalancing(t *testing.T) {
+	for _, tc := range []struct {
+		desc        string
+		balancing   *backendconfigv1.BalancingConfig
+		expectError bool
+	}{
+		{
+			desc: "nil balancing config",
+		},
+		{
+			desc: "valid rate",
+			balancing: &backendconfigv1.BalancingConfig{
+				BalancingMode:      "RATE",
+				MaxRatePerInstance: testutils.Float64ToPtr(100),
+			},
+		},
+		{
+			desc: "valid utilization",
+			balancing: &backendconfigv1.BalancingConfig{
+				BalancingMode:  "UTILIZATION",
+				MaxUtilization: testutils.Float64ToPtr(0.6),
+			},
+		},
+		{
+			desc:        "unsupported mode",
+			balancing:   &backendconfigv1.BalancingConfig{BalancingMode: "CONNECTION"},
+			expectError: true,
+		},
+		{
+			desc: "max utilization with rate",
+			balancing: &backendconfigv1.BalancingConfig{
+				BalancingMode:  "RATE",
+				MaxUtilization: testutils.Float64ToPtr(0.6),
+			},
+			expectError: true,
+		},
+		{
+			desc: "max rate with utilization",
+			balancing: &backendconfigv1.BalancingConfig{
+				BalancingMode:      "UTILIZATION",
+				MaxRatePerInstance: testutils.Float64ToPtr(100),
+			},
+			expectError: true,
+		},
+		{
+			desc: "invalid max utilization",
+			balancing: &backendconfigv1.BalancingConfig{
+				BalancingMode:  "UTILIZATION",
+				MaxUtilization: testutils.Float64ToPtr(1.5),
+			},
+			expectError: true,
+		},
+		{
+			desc: "invalid max rate",
+			balancing: &backendconfigv1.BalancingConfig{
+				BalancingMode:      "RATE",
+				MaxRatePerInstance: testutils.Float64ToPtr(0),
+			},
+			expectError: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			beConfig := &backendconfigv1.BackendConfig{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: backendconfigv1.BackendConfigSpec{Balancing: tc.balancing},
+			}
+			err := Validate(fake.NewSimpleClientset(), beConfig)
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error but got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Did not expect error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		desc        string
+		timeoutSec  *int64
+		expectError bool
+	}{
+		{
+			desc: "nil timeout",
+		},
+		{
+			desc:       "valid timeout",
+			timeoutSec: testutils.Int64ToPtr(3600),
+		},
+		{
+			desc:        "zero timeout",
+			timeoutSec:  testutils.Int64ToPtr(0),
+			expectError: true,
+		},
+		{
+			desc:        "negative timeout",
+			timeoutSec:  testutils.Int64ToPtr(-1),
+			expectError: true,
+		},
+		{
+			desc:        "timeout too long",
+			timeoutSec:  testutils.Int64ToPtr(maxTimeoutSec + 1),
+			expectError: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			beConfig := &backendconfigv1.BackendConfig{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: backendconfigv1.BackendConfigSpec{TimeoutSec: tc.timeoutSec},
+			}
+			err := Validate(fake.NewSimpleClientset(), beConfig)
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error but got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Did not expect error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateHealthCheck(t *testing.T) {
+	servingPort, fixedPort, namedPort := "USE_SERVING_PORT", "USE_FIXED_PORT", "USE_NAMED_PORT"
+	proxyV1, proxyV2 := "PROXY_V1", "PROXY_V2"
+	for _, tc := range []struct {
+		desc        string
+		healthCheck *backendconfigv1.HealthCheckConfig
+		expectError bool
+	}{
+		{
+			desc: "nil health check",
+		},
+		{
+			desc:        "serving port",
+			healthCheck: &backendconfigv1.HealthCheckConfig{PortSpecification: &servingPort, ProxyHeader: &proxyV1},
+		},
+		{
+			desc:        "serving port with port",
+			healthCheck: &backendconfigv1.HealthCheckConfig{PortSpecification: &servingPort, Port: testutils.Int64ToPtr(8080)},
+			expectError: true,
+		},
+		{
+			desc:        "fixed port",
+			healthCheck: &backendconfigv1.HealthCheckConfig{PortSpecification: &fixedPort, Port: testutils.Int64ToPtr(8080)},
+		},
+		{
+			desc:        "fixed port without port",
+			healthCheck: &backendconfigv1.HealthCheckConfig{PortSpecification: &fixedPort},
+			expectError: true,
+		},
+		{
+			desc:        "unsupported port specification",
+			healthCheck: &backendconfigv1.HealthCheckConfig{PortSpecification: &namedPort},
+			expectError: true,
+		},
+		{
+			desc:        "unsupported proxy header",
+			healthCheck: &backendconfigv1.HealthCheckConfig{ProxyHeader: &proxyV2},
+			expectError: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			beConfig := &backendconfigv1.BackendConfig{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: backendconfigv1.BackendConfigSpec{HealthCheck: tc.healthCheck},
+			}
+			err := Validate(fake.NewSimpleClientset(), beConfig)
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error but got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Did not expect error but got: %v", err)
+			}
+		})
+	}
+}
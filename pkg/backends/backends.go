@@ -14,6 +14,7 @@ limitations under the License.
 package backends
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -31,12 +32,19 @@ import (
 
 const (
 	DefaultConnectionDrainingTimeoutSeconds = 30
+
+	// maxBackendsPerService is the maximum number of backends (instance
+	// groups or NEGs) of a backend service, see
+	// https://cloud.google.com/load-balancing/docs/quotas#backend_services.
+	maxBackendsPerService = 50
 )
 
 // Backends handles CRUD operations for backends.
 type Backends struct {
 	cloud *gce.Cloud
-	namer namer.BackendNamer
+	// compositeCloud manages the backend services in cloud.
+	compositeCloud composite.Cloud
+	namer          namer.BackendNamer
 }
 
 // Backends is a Pool.
@@ -47,9 +55,19 @@ var _ Pool = (*Backends)(nil)
 // - namer: produces names for backends.
 func NewPool(cloud *gce.Cloud, namer namer.BackendNamer) *Backends {
 	return &Backends{
-		cloud: cloud,
-		namer: namer,
+		cloud:          cloud,
+		compositeCloud: composite.NewCloud(cloud),
+		namer:          namer,
+	}
+}
+
+// checkBackendCount returns an error if the backend service of sp would have
+// more backends than GCE allows, which GCE rejects with a generic error.
+func checkBackendCount(sp utils.ServicePort, count int) error {
+	if count > maxBackendsPerService {
+		return fmt.Errorf("backend service %s for %v would have %d backends, exceeding the GCE limit of %d backends (one per zone and cluster) per backend service", sp.BackendName(), sp.ID, count, maxBackendsPerService)
 	}
+	return nil
 }
 
 // ensureDescription updates the BackendService Description with the expected value
@@ -65,7 +83,7 @@ func ensureDescription(be *composite.BackendService, sp *utils.ServicePort) (nee
 }
 
 // Create implements Pool.
-func (b *Backends) Create(sp utils.ServicePort, hcLink string) (*composite.BackendService, error) {
+func (b *Backends) Create(ctx context.Context, sp utils.ServicePort, hcLink string) (*composite.BackendService, error) {
 	name := sp.BackendName()
 	namedPort := &compute.NamedPort{
 		Name: b.namer.NamedPort(sp.NodePort),
@@ -92,25 +110,28 @@ func (b *Backends) Create(sp utils.ServicePort, hcLink string) (*composite.Backe
 		// This enables l7-ILB and advanced traffic management features
 		be.LoadBalancingScheme = "INTERNAL_MANAGED"
 	}
+	if sp.DefaultTimeoutSec > 0 {
+		be.TimeoutSec = sp.DefaultTimeoutSec
+	}
 
 	ensureDescription(be, &sp)
 	scope := features.ScopeFromServicePort(&sp)
-	key, err := composite.CreateKey(b.cloud, name, scope)
+	key, err := b.compositeCloud.CreateKey(name, scope)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := composite.CreateBackendService(b.cloud, key, be); err != nil {
+	if err := b.compositeCloud.CreateBackendService(ctx, key, be); err != nil {
 		return nil, err
 	}
 	// Note: We need to perform a GCE call to re-fetch the object we just created
 	// so that the "Fingerprint" field is filled in. This is needed to update the
 	// object without error.
-	return b.Get(name, version, scope)
+	return b.Get(ctx, name, version, scope)
 }
 
 // Update implements Pool.
-func (b *Backends) Update(be *composite.BackendService) error {
+func (b *Backends) Update(ctx context.Context, be *composite.BackendService) error {
 	// Ensure the backend service has the proper version before updating.
 	be.Version = features.VersionFromDescription(be.Description)
 	scope, err := composite.ScopeFromSelfLink(be.SelfLink)
@@ -118,23 +139,23 @@ func (b *Backends) Update(be *composite.BackendService) error {
 		return err
 	}
 
-	key, err := composite.CreateKey(b.cloud, be.Name, scope)
+	key, err := b.compositeCloud.CreateKey(be.Name, scope)
 	if err != nil {
 		return err
 	}
-	if err := composite.UpdateBackendService(b.cloud, key, be); err != nil {
+	if err := b.compositeCloud.UpdateBackendService(ctx, key, be); err != nil {
 		return err
 	}
 	return nil
 }
 
 // Get implements Pool.
-func (b *Backends) Get(name string, version meta.Version, scope meta.KeyType) (*composite.BackendService, error) {
-	key, err := composite.CreateKey(b.cloud, name, scope)
+func (b *Backends) Get(ctx context.Context, name string, version meta.Version, scope meta.KeyType) (*composite.BackendService, error) {
+	key, err := b.compositeCloud.CreateKey(name, scope)
 	if err != nil {
 		return nil, err
 	}
-	be, err := composite.GetBackendService(b.cloud, key, version)
+	be, err := b.compositeCloud.GetBackendService(ctx, key, version)
 	if err != nil {
 		return nil, err
 	}
@@ -144,7 +165,7 @@ func (b *Backends) Get(name string, version meta.Version, scope meta.KeyType) (*
 	versionRequired := features.VersionFromDescription(be.Description)
 
 	if features.IsLowerVersion(versionRequired, version) {
-		be, err = composite.GetBackendService(b.cloud, key, versionRequired)
+		be, err = b.compositeCloud.GetBackendService(ctx, key, versionRequired)
 		if err != nil {
 			return nil, err
 		}
@@ -153,14 +174,14 @@ func (b *Backends) Get(name string, version meta.Version, scope meta.KeyType) (*
 }
 
 // Delete implements Pool.
-func (b *Backends) Delete(name string, version meta.Version, scope meta.KeyType) error {
+func (b *Backends) Delete(ctx context.Context, name string, version meta.Version, scope meta.KeyType) error {
 	klog.V(2).Infof("Deleting backend service %v", name)
 
-	key, err := composite.CreateKey(b.cloud, name, scope)
+	key, err := b.compositeCloud.CreateKey(name, scope)
 	if err != nil {
 		return err
 	}
-	err = composite.DeleteBackendService(b.cloud, key, version)
+	err = b.compositeCloud.DeleteBackendService(ctx, key, version)
 	if err != nil {
 		if utils.IsHTTPErrorCode(err, http.StatusNotFound) || utils.IsInUsedByError(err) {
 			klog.Infof("DeleteBackendService(_, %v, %v) = %v; ignorable error", key, version, err)
@@ -174,8 +195,8 @@ func (b *Backends) Delete(name string, version meta.Version, scope meta.KeyType)
 }
 
 // Health implements Pool.
-func (b *Backends) Health(name string, version meta.Version, scope meta.KeyType) (string, error) {
-	be, err := b.Get(name, version, scope)
+func (b *Backends) Health(ctx context.Context, name string, version meta.Version, scope meta.KeyType) (string, error) {
+	be, err := b.Get(ctx, name, version, scope)
 	if err != nil {
 		return "Unknown", fmt.Errorf("error getting backend service %s: %w", name, err)
 	}
@@ -217,13 +238,13 @@ func (b *Backends) Health(name string, version meta.Version, scope meta.KeyType)
 }
 
 // List lists all backends managed by this controller.
-func (b *Backends) List(key *meta.Key, version meta.Version) ([]*composite.BackendService, error) {
+func (b *Backends) List(ctx context.Context, key *meta.Key, version meta.Version) ([]*composite.BackendService, error) {
 	// TODO: for consistency with the rest of this sub-package this method
 	// should return a list of backend ports.
 	var backends []*composite.BackendService
 	var err error
 
-	backends, err = composite.ListBackendServices(b.cloud, key, version)
+	backends, err = b.compositeCloud.ListBackendServicesWithFilter(ctx, key, version, composite.ListFilter(b.namer.NamePrefix(), ""))
 	if err != nil {
 		return nil, err
 	}
@@ -245,13 +266,13 @@ func (b *Backends) List(key *meta.Key, version meta.Version) ([]*composite.Backe
 }
 
 // EnsureL4BackendService creates or updates the backend service with the given name.
-func (b *Backends) EnsureL4BackendService(name, hcLink, protocol, sessionAffinity, scheme string, nm types.NamespacedName, version meta.Version) (*composite.BackendService, error) {
+func (b *Backends) EnsureL4BackendService(ctx context.Context, name, hcLink, protocol, sessionAffinity, scheme string, nm types.NamespacedName, version meta.Version) (*composite.BackendService, error) {
 	klog.V(2).Infof("EnsureL4BackendService(%v, %v, %v): checking existing backend service", name, scheme, protocol)
-	key, err := composite.CreateKey(b.cloud, name, meta.Regional)
+	key, err := b.compositeCloud.CreateKey(name, meta.Regional)
 	if err != nil {
 		return nil, err
 	}
-	bs, err := composite.GetBackendService(b.cloud, key, meta.VersionGA)
+	bs, err := b.compositeCloud.GetBackendService(ctx, key, meta.VersionGA)
 	if err != nil && !utils.IsNotFoundError(err) {
 		return nil, err
 	}
@@ -278,7 +299,7 @@ func (b *Backends) EnsureL4BackendService(name, hcLink, protocol, sessionAffinit
 	// Create backend service if none was found
 	if bs == nil {
 		klog.V(2).Infof("EnsureL4BackendService: creating backend service %v", name)
-		err := composite.CreateBackendService(b.cloud, key, expectedBS)
+		err := b.compositeCloud.CreateBackendService(ctx, key, expectedBS)
 		if err != nil {
 			return nil, err
 		}
@@ -286,7 +307,7 @@ func (b *Backends) EnsureL4BackendService(name, hcLink, protocol, sessionAffinit
 		// We need to perform a GCE call to re-fetch the object we just created
 		// so that the "Fingerprint" field is filled in. This is needed to update the
 		// object without error. The lookup is also needed to populate the selfLink.
-		return composite.GetBackendService(b.cloud, key, meta.VersionGA)
+		return b.compositeCloud.GetBackendService(ctx, key, meta.VersionGA)
 	}
 
 	if backendSvcEqual(expectedBS, bs) {
@@ -299,11 +320,21 @@ func (b *Backends) EnsureL4BackendService(name, hcLink, protocol, sessionAffinit
 	klog.V(2).Infof("EnsureL4BackendService: updating backend service %v", name)
 	// Set fingerprint for optimistic locking
 	expectedBS.Fingerprint = bs.Fingerprint
-	if err := composite.UpdateBackendService(b.cloud, key, expectedBS); err != nil {
+	if err := b.compositeCloud.UpdateBackendService(ctx, key, expectedBS); err != nil {
 		return nil, err
 	}
 	klog.V(2).Infof("EnsureL4BackendService: updated backend service %v successfully", name)
-	return composite.GetBackendService(b.cloud, key, meta.VersionGA)
+	return b.compositeCloud.GetBackendService(ctx, key, meta.VersionGA)
+}
+
+// l4BackendServiceFields are the fields of an L4 backend service compared by
+// backendSvcEqual.
+var l4BackendServiceFields = []utils.FieldSpec{
+	{Path: "Protocol"},
+	{Path: "Description"},
+	{Path: "SessionAffinity"},
+	{Path: "LoadBalancingScheme"},
+	{Path: "HealthChecks", Unordered: true},
 }
 
 // backendSvcEqual returns true if the 2 BackendService objects are equal.
@@ -312,9 +343,5 @@ func (b *Backends) EnsureL4BackendService(name, hcLink, protocol, sessionAffinit
 // service will not be updated. The list of backends is not checked either,
 // since that is handled by the neg-linker.
 func backendSvcEqual(a, b *composite.BackendService) bool {
-	return a.Protocol == b.Protocol &&
-		a.Description == b.Description &&
-		a.SessionAffinity == b.SessionAffinity &&
-		a.LoadBalancingScheme == b.LoadBalancingScheme &&
-		utils.EqualStringSets(a.HealthChecks, b.HealthChecks)
+	return len(utils.DiffFields(a, b, l4BackendServiceFields)) == 0
 }
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"k8s.io/ingress-gce/pkg/composite"
+)
+
+func TestBackendsDelete(t *testing.T) {
+	t.Parallel()
+
+	fakeCloud := composite.NewFake("test-project", "us-central1")
+	pool := &Backends{compositeCloud: fakeCloud, namer: defaultNamer}
+	key := meta.GlobalKey("bs")
+	if err := fakeCloud.CreateBackendService(context.TODO(), key, &composite.BackendService{}); err != nil {
+		t.Fatalf("CreateBackendService(%v) = %v", key, err)
+	}
+
+	// Errors other than not found are returned.
+	injected := fmt.Errorf("injected")
+	fakeCloud.InjectError("BackendService", composite.FakeOpDelete, injected)
+	if err := pool.Delete(context.TODO(), "bs", meta.VersionGA, meta.Global); err != injected {
+		t.Errorf("Delete() = %v, want %v", err, injected)
+	}
+	fakeCloud.SetHook("BackendService", composite.FakeOpDelete, nil)
+
+	if err := pool.Delete(context.TODO(), "bs", meta.VersionGA, meta.Global); err != nil {
+		t.Errorf("Delete() = %v, want nil", err)
+	}
+	if _, err := fakeCloud.GetBackendService(context.TODO(), key, meta.VersionGA); err == nil {
+		t.Errorf("GetBackendService(%v) = _, nil after Delete(), want not found", key)
+	}
+	// A backend service which does not exist is already deleted.
+	if err := pool.Delete(context.TODO(), "bs", meta.VersionGA, meta.Global); err != nil {
+		t.Errorf("Delete() of deleted backend service = %v, want nil", err)
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"k8s.io/ingress-gce/pkg/annotations"
+	"k8s.io/ingress-gce/pkg/backends/features"
+	"k8s.io/ingress-gce/pkg/backends/metrics"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/utils"
+	"k8s.io/ingress-gce/pkg/utils/common"
+	"k8s.io/klog"
+)
+
+// backendCacheKey identifies the backend service of a ServicePort.
+type backendCacheKey struct {
+	// port is the name of the backend service, which is derived from the
+	// port.
+	port     string
+	protocol annotations.AppProtocol
+	scope    meta.KeyType
+}
+
+func newBackendCacheKey(sp *utils.ServicePort) backendCacheKey {
+	return backendCacheKey{
+		port:     sp.BackendName(),
+		protocol: sp.Protocol,
+		scope:    features.ScopeFromServicePort(sp),
+	}
+}
+
+// backendCacheEntry is a backend service which was found in sync with the
+// given inputs.
+type backendCacheEntry struct {
+	// be is nil for entries restored from a Fingerprint.
+	be *composite.BackendService
+	// fingerprint is a hash of the ServicePort and health check link.
+	fingerprint string
+	// verified is the last time the backend service was fetched from GCE.
+	verified time.Time
+}
+
+// Fingerprint records that the backend service of a ServicePort was found in
+// sync with the inputs of the given fingerprint. Fingerprints of the cache are
+// handed off to the next leader, which then skips fetching these backend
+// services until they are older than the verify period.
+type Fingerprint struct {
+	Name        string                  `json:"name"`
+	Protocol    annotations.AppProtocol `json:"protocol"`
+	Scope       meta.KeyType            `json:"scope"`
+	Fingerprint string                  `json:"fingerprint"`
+	Verified    time.Time               `json:"verified"`
+}
+
+// backendServiceCache remembers the backend services which were in sync with
+// their ServicePort, so that they are not fetched again while their inputs do
+// not change. Entries are dropped whenever a sync changes or fails to sync the
+// backend service and are verified against GCE once they are older than the
+// verify period. A nil cache or a zero verify period disables caching.
+type backendServiceCache struct {
+	lock         sync.Mutex
+	verifyPeriod time.Duration
+	entries      map[backendCacheKey]*backendCacheEntry
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+func newBackendServiceCache(verifyPeriod time.Duration) *backendServiceCache {
+	return &backendServiceCache{
+		verifyPeriod: verifyPeriod,
+		entries:      map[backendCacheKey]*backendCacheEntry{},
+		now:          time.Now,
+	}
+}
+
+func (c *backendServiceCache) enabled() bool {
+	return c != nil && c.verifyPeriod > 0
+}
+
+// lookup returns true if the backend service of the ServicePort was in sync
+// with the same inputs less than a verify period ago. Otherwise it returns the
+// cached backend service, if any, which must be verified against the backend
+// service fetched from GCE.
+func (c *backendServiceCache) lookup(sp utils.ServicePort, hcLink string) (bool, *backendCacheEntry) {
+	if !c.enabled() {
+		return false, nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[newBackendCacheKey(&sp)]
+	if !ok || entry.fingerprint == "" || entry.fingerprint != backendFingerprint(sp, hcLink) {
+		metrics.CacheLookups.WithLabelValues(metrics.CacheMiss).Inc()
+		return false, nil
+	}
+	if c.now().Sub(entry.verified) >= c.verifyPeriod {
+		metrics.CacheLookups.WithLabelValues(metrics.CacheVerify).Inc()
+		return false, entry
+	}
+	metrics.CacheLookups.WithLabelValues(metrics.CacheHit).Inc()
+	return true, nil
+}
+
+// verify checks the cache entry of the ServicePort against the backend
+// service fetched from GCE. An entry which no longer matches the backend
+// service, as it was modified outside of the controller, is evicted.
+func (c *backendServiceCache) verify(sp utils.ServicePort, entry *backendCacheEntry, be *composite.BackendService) {
+	metrics.CacheEntryAge.Observe(c.now().Sub(entry.verified).Seconds())
+	if entry.be == nil || equalBackendServices(entry.be, be) {
+		return
+	}
+	klog.V(2).Infof("Cached backend service %s was modified outside of the controller", be.Name)
+	metrics.CacheStaleEntries.Inc()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := newBackendCacheKey(&sp)
+	if c.entries[key] == entry {
+		delete(c.entries, key)
+		metrics.CacheEntries.Set(float64(len(c.entries)))
+	}
+}
+
+// add caches a backend service which was fetched from GCE and found in sync.
+// Backend services of ServicePorts with a rate limit are not cached, as their
+// managed security policy is a separate resource which must be reconciled on
+// every sync.
+func (c *backendServiceCache) add(sp utils.ServicePort, hcLink string, be *composite.BackendService) {
+	if !c.enabled() || hasRateLimit(sp) {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entries[newBackendCacheKey(&sp)] = &backendCacheEntry{be: be, fingerprint: backendFingerprint(sp, hcLink), verified: c.now()}
+	metrics.CacheEntries.Set(float64(len(c.entries)))
+}
+
+// fingerprints returns the fingerprints of the entries which are still
+// within the verify period.
+func (c *backendServiceCache) fingerprints() []Fingerprint {
+	if !c.enabled() {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var fingerprints []Fingerprint
+	for key, entry := range c.entries {
+		if entry.fingerprint == "" || c.now().Sub(entry.verified) >= c.verifyPeriod {
+			continue
+		}
+		fingerprints = append(fingerprints, Fingerprint{
+			Name:        key.port,
+			Protocol:    key.protocol,
+			Scope:       key.scope,
+			Fingerprint: entry.fingerprint,
+			Verified:    entry.verified,
+		})
+	}
+	sort.Slice(fingerprints, func(i, j int) bool { return fingerprints[i].Name < fingerprints[j].Name })
+	return fingerprints
+}
+
+// restore adds entries for the given fingerprints, unless the cache already
+// has an entry for their backend service.
+func (c *backendServiceCache) restore(fingerprints []Fingerprint) {
+	if !c.enabled() {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, f := range fingerprints {
+		key := backendCacheKey{port: f.Name, protocol: f.Protocol, scope: f.Scope}
+		if _, ok := c.entries[key]; ok {
+			continue
+		}
+		c.entries[key] = &backendCacheEntry{fingerprint: f.Fingerprint, verified: f.Verified}
+	}
+	metrics.CacheEntries.Set(float64(len(c.entries)))
+}
+
+// invalidate drops the entries of the backend service with the given name.
+func (c *backendServiceCache) invalidate(name string, scope meta.KeyType) {
+	if !c.enabled() {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key := range c.entries {
+		if key.port == name && key.scope == scope {
+			delete(c.entries, key)
+		}
+	}
+	metrics.CacheEntries.Set(float64(len(c.entries)))
+}
+
+// hasRateLimit returns true if the BackendConfig of the ServicePort sets a
+// rate limit.
+func hasRateLimit(sp utils.ServicePort) bool {
+	return sp.BackendConfig != nil && sp.BackendConfig.Spec.RateLimit != nil
+}
+
+// backendFingerprint returns a hash of the inputs of the backend service of
+// the ServicePort. The ServicePort includes its BackendConfig, so that any
+// change of the BackendConfig, e.g. of its security policy, changes the
+// fingerprint and the backend service is synced again. It is empty if the
+// inputs cannot be hashed.
+func backendFingerprint(sp utils.ServicePort, hcLink string) string {
+	spJSON, err := json.Marshal(sp)
+	if err != nil {
+		klog.Errorf("Failed to fingerprint service port %v: %v", sp.ID, err)
+		return ""
+	}
+	return common.ContentHash(string(spJSON)+";"+hcLink, 16)
+}
+
+// equalBackendServices returns true if both backend services have the same
+// content in GCE.
+func equalBackendServices(a, b *composite.BackendService) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	if aErr != nil || bErr != nil {
+		return false
+	}
+	return string(aJSON) == string(bJSON)
+}
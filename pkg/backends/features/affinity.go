@@ -17,13 +17,16 @@ limitations under the License.
 package features
 
 import (
-	"reflect"
-
 	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/klog"
 )
 
+var affinityFields = []utils.FieldSpec{
+	{Path: "SessionAffinity", ServerDefault: "NONE"},
+	{Path: "AffinityCookieTtlSec"},
+}
+
 // EnsureAffinity reads the sessionAffinity and AffinityCookieTtlSec configuration
 // specified in the ServicePort.BackendConfig and applies it to the BackendService.
 // It returns true if there were existing settings on the BackendService
@@ -34,7 +37,7 @@ func EnsureAffinity(sp utils.ServicePort, be *composite.BackendService) bool {
 	}
 	beTemp := &composite.BackendService{}
 	applyAffinitySettings(sp, beTemp)
-	if !reflect.DeepEqual(beTemp.AffinityCookieTtlSec, be.AffinityCookieTtlSec) || beTemp.SessionAffinity != be.SessionAffinity {
+	if len(utils.DiffFields(beTemp, be, affinityFields)) > 0 {
 		applyAffinitySettings(sp, be)
 		klog.V(2).Infof("Updated SessionAffinity settings for service %v/%v.", sp.ID.Service.Namespace, sp.ID.Service.Name)
 		return true
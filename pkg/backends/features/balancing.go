@@ -0,0 +1,36 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+var balancingFields = []utils.FieldSpec{
+	{Path: "BalancingMode"},
+	{Path: "MaxRatePerInstance"},
+	// GCE reports a maximum utilization of 0.8 for backends which do not
+	// set one.
+	{Path: "MaxUtilization", ServerDefault: 0.8},
+}
+
+// BalancingChanged returns true if the balancing settings of want differ
+// from those of have, the backend as reported by GCE.
+func BalancingChanged(want, have *composite.Backend) bool {
+	return len(utils.DiffFields(want, have, balancingFields)) > 0
+}
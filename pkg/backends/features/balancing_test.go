@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"testing"
+
+	"k8s.io/ingress-gce/pkg/composite"
+)
+
+func TestBalancingChanged(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		want *composite.Backend
+		have *composite.Backend
+		diff bool
+	}{
+		{
+			desc: "utilization without target, GCE default reported",
+			want: &composite.Backend{BalancingMode: "UTILIZATION"},
+			have: &composite.Backend{BalancingMode: "UTILIZATION", MaxUtilization: 0.8},
+		},
+		{
+			desc: "rate, GCE default utilization reported",
+			want: &composite.Backend{BalancingMode: "RATE", MaxRatePerInstance: 100},
+			have: &composite.Backend{BalancingMode: "RATE", MaxRatePerInstance: 100, MaxUtilization: 0.8},
+		},
+		{
+			desc: "utilization target changed",
+			want: &composite.Backend{BalancingMode: "UTILIZATION", MaxUtilization: 0.6},
+			have: &composite.Backend{BalancingMode: "UTILIZATION", MaxUtilization: 0.8},
+			diff: true,
+		},
+		{
+			desc: "mode changed",
+			want: &composite.Backend{BalancingMode: "RATE", MaxRatePerInstance: 100},
+			have: &composite.Backend{BalancingMode: "UTILIZATION", MaxUtilization: 0.8},
+			diff: true,
+		},
+		{
+			desc: "fields not owned by balancing are ignored",
+			want: &composite.Backend{BalancingMode: "UTILIZATION"},
+			have: &composite.Backend{BalancingMode: "UTILIZATION", CapacityScaler: 1, Group: "ig"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := BalancingChanged(tc.want, tc.have); got != tc.diff {
+				t.Errorf("BalancingChanged() = %t, want %t", got, tc.diff)
+			}
+		})
+	}
+}
@@ -17,13 +17,45 @@ limitations under the License.
 package features
 
 import (
-	"reflect"
+	"fmt"
 
 	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/klog"
 )
 
+var (
+	cdnFields = []utils.FieldSpec{
+		{Path: "EnableCDN"},
+	}
+	// cacheKeyPolicyFields does not include the rest of CdnPolicy, which is
+	// filled in by GCE and would otherwise never match.
+	cacheKeyPolicyFields = []utils.FieldSpec{
+		{Path: "CdnPolicy.CacheKeyPolicy.IncludeHost"},
+		{Path: "CdnPolicy.CacheKeyPolicy.IncludeProtocol"},
+		{Path: "CdnPolicy.CacheKeyPolicy.IncludeQueryString"},
+		{Path: "CdnPolicy.CacheKeyPolicy.QueryStringBlacklist"},
+		{Path: "CdnPolicy.CacheKeyPolicy.QueryStringWhitelist"},
+	}
+)
+
+// ValidateCDN returns an error if the CDN configuration specified in the
+// ServicePort.BackendConfig cannot be applied to the type of backend the
+// ServicePort is served by. Catching this before the backend service is
+// synced gives users an actionable error instead of a GCE API error.
+func ValidateCDN(sp utils.ServicePort) error {
+	if sp.BackendConfig == nil || sp.BackendConfig.Spec.Cdn == nil || !sp.BackendConfig.Spec.Cdn.Enabled {
+		return nil
+	}
+	switch {
+	case sp.L7ILBEnabled:
+		return fmt.Errorf("cdn is not supported for internal HTTP(S) load balancer backends")
+	case sp.VMIPNEGEnabled:
+		return fmt.Errorf("cdn is not supported for GCE_VM_IP NEG backends")
+	}
+	return nil
+}
+
 // EnsureCDN reads the CDN configuration specified in the ServicePort.BackendConfig
 // and applies it to the BackendService. It returns true if there were existing
 // settings on the BackendService that were overwritten.
@@ -33,8 +65,12 @@ func EnsureCDN(sp utils.ServicePort, be *composite.BackendService) bool {
 	}
 	beTemp := &composite.BackendService{}
 	applyCDNSettings(sp, beTemp)
-	// Only compare CdnPolicy if it was specified.
-	if (beTemp.CdnPolicy != nil && !reflect.DeepEqual(beTemp.CdnPolicy, be.CdnPolicy)) || beTemp.EnableCDN != be.EnableCDN {
+	mask := append([]utils.FieldSpec{}, cdnFields...)
+	// Only compare the cache key policy if it was specified.
+	if beTemp.CdnPolicy != nil {
+		mask = append(mask, cacheKeyPolicyFields...)
+	}
+	if len(utils.DiffFields(beTemp, be, mask)) > 0 {
 		applyCDNSettings(sp, be)
 		klog.V(2).Infof("Updated CDN settings for service %v/%v.", sp.ID.Service.Namespace, sp.ID.Service.Name)
 		return true
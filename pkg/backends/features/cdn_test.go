@@ -139,6 +139,33 @@ func TestEnsureCDN(t *testing.T) {
 			},
 			updateExpected: true,
 		},
+		{
+			desc: "settings are identical except for server defaults, no update needed",
+			sp: utils.ServicePort{
+				BackendConfig: &backendconfigv1.BackendConfig{
+					Spec: backendconfigv1.BackendConfigSpec{
+						Cdn: &backendconfigv1.CDNConfig{
+							Enabled: true,
+							CachePolicy: &backendconfigv1.CacheKeyPolicy{
+								IncludeHost:          true,
+								QueryStringWhitelist: []string{},
+							},
+						},
+					},
+				},
+			},
+			be: &composite.BackendService{
+				EnableCDN: true,
+				CdnPolicy: &composite.BackendServiceCdnPolicy{
+					CacheKeyPolicy: &composite.CacheKeyPolicy{
+						IncludeHost: true,
+					},
+					CacheMode:               "USE_ORIGIN_HEADERS",
+					SignedUrlCacheMaxAgeSec: 3600,
+				},
+			},
+			updateExpected: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -150,3 +177,57 @@ func TestEnsureCDN(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCDN(t *testing.T) {
+	cdnEnabled := &backendconfigv1.BackendConfig{
+		Spec: backendconfigv1.BackendConfigSpec{
+			Cdn: &backendconfigv1.CDNConfig{Enabled: true},
+		},
+	}
+	cdnDisabled := &backendconfigv1.BackendConfig{
+		Spec: backendconfigv1.BackendConfigSpec{
+			Cdn: &backendconfigv1.CDNConfig{Enabled: false},
+		},
+	}
+	testCases := []struct {
+		desc      string
+		sp        utils.ServicePort
+		expectErr bool
+	}{
+		{
+			desc: "no backend config",
+			sp:   utils.ServicePort{L7ILBEnabled: true},
+		},
+		{
+			desc: "cdn enabled on instance group backend",
+			sp:   utils.ServicePort{BackendConfig: cdnEnabled},
+		},
+		{
+			desc: "cdn enabled on NEG backend",
+			sp:   utils.ServicePort{NEGEnabled: true, BackendConfig: cdnEnabled},
+		},
+		{
+			desc:      "cdn enabled on L7-ILB backend",
+			sp:        utils.ServicePort{NEGEnabled: true, L7ILBEnabled: true, BackendConfig: cdnEnabled},
+			expectErr: true,
+		},
+		{
+			desc: "cdn disabled on L7-ILB backend",
+			sp:   utils.ServicePort{NEGEnabled: true, L7ILBEnabled: true, BackendConfig: cdnDisabled},
+		},
+		{
+			desc:      "cdn enabled on GCE_VM_IP NEG backend",
+			sp:        utils.ServicePort{VMIPNEGEnabled: true, BackendConfig: cdnEnabled},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := ValidateCDN(tc.sp)
+			if gotErr := err != nil; gotErr != tc.expectErr {
+				t.Errorf("ValidateCDN() = %v, want error: %t", err, tc.expectErr)
+			}
+		})
+	}
+}
@@ -16,13 +16,15 @@ limitations under the License.
 package features
 
 import (
-	"reflect"
-
 	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/klog"
 )
 
+var customRequestHeadersFields = []utils.FieldSpec{
+	{Path: "CustomRequestHeaders"},
+}
+
 // EnsureCustomRequestHeaders reads the CustomRequestHeaders configuration specified in the ServicePort.BackendConfig
 // and applies it to the BackendService. It returns true if there were existing
 // settings on the BackendService that were overwritten.
@@ -32,7 +34,7 @@ func EnsureCustomRequestHeaders(sp utils.ServicePort, be *composite.BackendServi
 	}
 	beTemp := &composite.BackendService{}
 	applyCustomRequestHeaders(sp, beTemp)
-	if !reflect.DeepEqual(beTemp.CustomRequestHeaders, be.CustomRequestHeaders) {
+	if len(utils.DiffFields(beTemp, be, customRequestHeadersFields)) > 0 {
 		applyCustomRequestHeaders(sp, be)
 		klog.V(2).Infof("Updated Custom Request Headers for service %v/%v.", sp.ID.Service.Namespace, sp.ID.Service.Name)
 		return true
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"testing"
+
+	computealpha "google.golang.org/api/compute/v0.alpha"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+// TestFieldMasks checks that the masks of all features resolve against the
+// resource they apply to, a BackendService or a Backend, and that their server
+// defaults have the type of the field.
+func TestFieldMasks(t *testing.T) {
+	masks := map[interface{}][][]utils.FieldSpec{
+		&composite.BackendService{}: {
+			affinityFields,
+			cdnFields,
+			cacheKeyPolicyFields,
+			customRequestHeadersFields,
+			drainingFields,
+			iapFields,
+			loggingFields,
+			timeoutFields,
+		},
+		&composite.Backend{}: {
+			balancingFields,
+		},
+		&computealpha.SecurityPolicyRule{}: {
+			rateLimitRuleFields,
+		},
+	}
+	for obj, objMasks := range masks {
+		for _, f := range flattenMasks(objMasks) {
+			t.Run(f.Path, func(t *testing.T) {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("DiffFields() panicked: %v", r)
+					}
+				}()
+				utils.DiffFields(obj, obj, []utils.FieldSpec{f})
+			})
+		}
+	}
+}
+
+func flattenMasks(masks [][]utils.FieldSpec) []utils.FieldSpec {
+	var ret []utils.FieldSpec
+	for _, mask := range masks {
+		ret = append(ret, mask...)
+	}
+	return ret
+}
@@ -17,13 +17,15 @@ limitations under the License.
 package features
 
 import (
-	"reflect"
-
 	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/klog"
 )
 
+var drainingFields = []utils.FieldSpec{
+	{Path: "ConnectionDraining.DrainingTimeoutSec"},
+}
+
 // EnsureDraining reads the ConnectionDraining configuration specified in
 // the ServicePort.BackendConfig and applies it to the BackendService.
 // It returns true if there were existing settings on the BackendService
@@ -34,7 +36,7 @@ func EnsureDraining(sp utils.ServicePort, be *composite.BackendService) bool {
 	}
 	beTemp := &composite.BackendService{}
 	applyDrainingSettings(sp, beTemp)
-	if !reflect.DeepEqual(beTemp.ConnectionDraining, be.ConnectionDraining) {
+	if len(utils.DiffFields(beTemp, be, drainingFields)) > 0 {
 		applyDrainingSettings(sp, be)
 		klog.V(2).Infof("Updated ConnectionDraining settings for service %v/%v.", sp.ID.Service.Namespace, sp.ID.Service.Name)
 		return true
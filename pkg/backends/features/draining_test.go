@@ -97,6 +97,18 @@ func TestEnsureDraining(t *testing.T) {
 			},
 			updateExpected: true,
 		},
+		{
+			desc: "zero connection draining timeout and no settings on backend service, no update needed",
+			sp: utils.ServicePort{
+				BackendConfig: &backendconfigv1.BackendConfig{
+					Spec: backendconfigv1.BackendConfigSpec{
+						ConnectionDraining: &backendconfigv1.ConnectionDrainingConfig{},
+					},
+				},
+			},
+			be:             &composite.BackendService{},
+			updateExpected: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	computealpha "google.golang.org/api/compute/v0.alpha"
+
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+// FakeSecurityPolicyClient is an in-memory SecurityPolicyClient.
+type FakeSecurityPolicyClient struct {
+	lock     sync.Mutex
+	Policies map[string]*computealpha.SecurityPolicy
+}
+
+// NewFakeSecurityPolicyClient creates a fake for security policies.
+func NewFakeSecurityPolicyClient() *FakeSecurityPolicyClient {
+	return &FakeSecurityPolicyClient{Policies: make(map[string]*computealpha.SecurityPolicy)}
+}
+
+func (f *FakeSecurityPolicyClient) Get(ctx context.Context, name string) (*computealpha.SecurityPolicy, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	policy, ok := f.Policies[name]
+	if !ok {
+		return nil, utils.FakeGoogleAPINotFoundErr()
+	}
+	return policy, nil
+}
+
+func (f *FakeSecurityPolicyClient) Insert(ctx context.Context, policy *computealpha.SecurityPolicy) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.Policies[policy.Name]; ok {
+		return fmt.Errorf("security policy %s already exists", policy.Name)
+	}
+	f.Policies[policy.Name] = policy
+	return nil
+}
+
+func (f *FakeSecurityPolicyClient) Patch(ctx context.Context, policy *computealpha.SecurityPolicy) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	existing, ok := f.Policies[policy.Name]
+	if !ok {
+		return utils.FakeGoogleAPINotFoundErr()
+	}
+	if policy.AdaptiveProtectionConfig != nil {
+		existing.AdaptiveProtectionConfig = policy.AdaptiveProtectionConfig
+	}
+	return nil
+}
+
+func (f *FakeSecurityPolicyClient) AddRule(ctx context.Context, name string, rule *computealpha.SecurityPolicyRule) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	policy, ok := f.Policies[name]
+	if !ok {
+		return utils.FakeGoogleAPINotFoundErr()
+	}
+	for _, r := range policy.Rules {
+		if r.Priority == rule.Priority {
+			return fmt.Errorf("security policy %s already has a rule with priority %d", name, rule.Priority)
+		}
+	}
+	policy.Rules = append(policy.Rules, rule)
+	return nil
+}
+
+func (f *FakeSecurityPolicyClient) PatchRule(ctx context.Context, name string, rule *computealpha.SecurityPolicyRule) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	policy, ok := f.Policies[name]
+	if !ok {
+		return utils.FakeGoogleAPINotFoundErr()
+	}
+	for i, r := range policy.Rules {
+		if r.Priority == rule.Priority {
+			policy.Rules[i] = rule
+			return nil
+		}
+	}
+	return fmt.Errorf("security policy %s has no rule with priority %d", name, rule.Priority)
+}
+
+func (f *FakeSecurityPolicyClient) Delete(ctx context.Context, name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.Policies[name]; !ok {
+		return utils.FakeGoogleAPINotFoundErr()
+	}
+	delete(f.Policies, name)
+	return nil
+}
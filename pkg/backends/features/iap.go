@@ -25,6 +25,12 @@ import (
 	"k8s.io/klog"
 )
 
+var iapFields = []utils.FieldSpec{
+	{Path: "Iap.Enabled"},
+	{Path: "Iap.Oauth2ClientId"},
+	{Path: "Iap.Oauth2ClientSecretSha256"},
+}
+
 // EnsureIAP reads the IAP configuration specified in the BackendConfig
 // and applies it to the BackendService if it is stale. It returns true
 // if there were existing settings on the BackendService that were overwritten.
@@ -37,7 +43,7 @@ func EnsureIAP(sp utils.ServicePort, be *composite.BackendService) bool {
 	// We need to compare the SHA256 of the client secret instead of the client secret itself
 	// since that field is redacted when getting a BackendService.
 	beTemp.Iap.Oauth2ClientSecretSha256 = fmt.Sprintf("%x", sha256.Sum256([]byte(beTemp.Iap.Oauth2ClientSecret)))
-	if be.Iap == nil || beTemp.Iap.Enabled != be.Iap.Enabled || beTemp.Iap.Oauth2ClientId != be.Iap.Oauth2ClientId || beTemp.Iap.Oauth2ClientSecretSha256 != be.Iap.Oauth2ClientSecretSha256 {
+	if len(utils.DiffFields(beTemp, be, iapFields)) > 0 {
 		applyIAPSettings(sp, be)
 		klog.V(2).Infof("Updated IAP settings for service %v/%v.", sp.ID.Service.Namespace, sp.ID.Service.Name)
 		return true
@@ -24,6 +24,11 @@ import (
 	"k8s.io/klog"
 )
 
+var loggingFields = []utils.FieldSpec{
+	{Path: "LogConfig.Enable"},
+	{Path: "LogConfig.SampleRate"},
+}
+
 // EnsureLogging reads the log configurations specified in the ServicePort.BackendConfig
 // and applies it to the BackendService. It returns true if there were existing settings
 // on the BackendService that were overwritten.
@@ -36,16 +41,15 @@ func EnsureLogging(sp utils.ServicePort, be *composite.BackendService) bool {
 		klog.V(3).Infof("Logging continues to stay disabled for service %s (port %d), skipping update", svcKey, sp.Port)
 		return false
 	}
-	var existingLogConfig *composite.BackendServiceLogConfig
+	existing := &composite.BackendService{}
 	if be.LogConfig != nil {
-		existingLogConfig = &composite.BackendServiceLogConfig{
+		existing.LogConfig = &composite.BackendServiceLogConfig{
 			Enable:     be.LogConfig.Enable,
 			SampleRate: be.LogConfig.SampleRate,
 		}
 	}
 	ensureBackendServiceLogConfig(sp, be)
-	if existingLogConfig == nil || existingLogConfig.Enable != be.LogConfig.Enable ||
-		existingLogConfig.SampleRate != be.LogConfig.SampleRate {
+	if len(utils.DiffFields(be, existing, loggingFields)) > 0 {
 		klog.V(2).Infof("Updated Logging settings for service %s (port %d) to (Enable: %t, SampleRate: %f)", svcKey, sp.Port, be.LogConfig.Enable, be.LogConfig.SampleRate)
 		return true
 	}
@@ -0,0 +1,377 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	computealpha "google.golang.org/api/compute/v0.alpha"
+	"k8s.io/klog"
+	"k8s.io/legacy-cloud-providers/gce"
+
+	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+const (
+	// RateLimitPolicyDescription is set on the Cloud Armor security policies
+	// created by the controller to materialize BackendConfig rate limits.
+	// Policies without this description are never modified or deleted.
+	RateLimitPolicyDescription = "Rate limit policy managed by the GCE L7 load balancer controller"
+
+	// rateLimitRulePriority is the priority of the throttle rule.
+	rateLimitRulePriority = 1000
+	// defaultRulePriority is the priority of the mandatory default rule.
+	defaultRulePriority = 2147483647
+	// rateLimitIntervalSec is the interval over which requests are counted.
+	rateLimitIntervalSec = 60
+)
+
+// SecurityPolicyClient manages global Cloud Armor security policies.
+// Rate limiting options are only exposed by the alpha compute API.
+type SecurityPolicyClient interface {
+	Get(ctx context.Context, name string) (*computealpha.SecurityPolicy, error)
+	Insert(ctx context.Context, policy *computealpha.SecurityPolicy) error
+	Patch(ctx context.Context, policy *computealpha.SecurityPolicy) error
+	// AddRule adds a rule at a priority which has no rule yet.
+	AddRule(ctx context.Context, name string, rule *computealpha.SecurityPolicyRule) error
+	// PatchRule replaces the rule at the priority of the given rule.
+	PatchRule(ctx context.Context, name string, rule *computealpha.SecurityPolicyRule) error
+	Delete(ctx context.Context, name string) error
+}
+
+// NewSecurityPolicyClient returns a SecurityPolicyClient backed by the alpha
+// compute API of the given cloud. Calls share the rate limiter, metrics and
+// audit log of the composite types.
+func NewSecurityPolicyClient(gceCloud *gce.Cloud) SecurityPolicyClient {
+	return &securityPolicyClient{cloud: gceCloud}
+}
+
+type securityPolicyClient struct {
+	cloud *gce.Cloud
+}
+
+func (c *securityPolicyClient) service() *computealpha.SecurityPoliciesService {
+	return c.cloud.ComputeServices().Alpha.SecurityPolicies
+}
+
+// start blocks until the call of the given method on the named policy is
+// accepted by the shared rate limiter.
+func (c *securityPolicyClient) start(ctx context.Context, method, request, name string, mutating bool) (composite.CallObserver, error) {
+	call := &composite.Call{
+		Service:  "SecurityPolicies",
+		Method:   method,
+		Resource: "SecurityPolicy",
+		Request:  request,
+		Key:      meta.GlobalKey(name),
+		Version:  meta.VersionAlpha,
+		Mutating: mutating,
+	}
+	return call.Start(ctx, c.cloud.ProjectID())
+}
+
+// do runs a mutating call on the named policy and waits for its operation.
+func (c *securityPolicyClient) do(ctx context.Context, method, request, name string, call func(ctx context.Context) (*computealpha.Operation, error)) error {
+	ctx, cancel := composite.ContextWithCallTimeout(ctx)
+	defer cancel()
+	mc, err := c.start(ctx, method, request, name, true)
+	if err != nil {
+		return err
+	}
+	op, err := call(ctx)
+	if err == nil {
+		err = composite.WaitForCompletion(ctx, c.cloud, c.cloud.ProjectID(), op)
+	}
+	return mc.Observe(err)
+}
+
+func (c *securityPolicyClient) Get(ctx context.Context, name string) (*computealpha.SecurityPolicy, error) {
+	ctx, cancel := composite.ContextWithCallTimeout(ctx)
+	defer cancel()
+	mc, err := c.start(ctx, "Get", "get", name, false)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := c.service().Get(c.cloud.ProjectID(), name).Context(ctx).Do()
+	return policy, mc.Observe(err)
+}
+
+func (c *securityPolicyClient) Insert(ctx context.Context, policy *computealpha.SecurityPolicy) error {
+	return c.do(ctx, "Insert", "create", policy.Name, func(ctx context.Context) (*computealpha.Operation, error) {
+		return c.service().Insert(c.cloud.ProjectID(), policy).Context(ctx).Do()
+	})
+}
+
+func (c *securityPolicyClient) Patch(ctx context.Context, policy *computealpha.SecurityPolicy) error {
+	return c.do(ctx, "Patch", "patch", policy.Name, func(ctx context.Context) (*computealpha.Operation, error) {
+		return c.service().Patch(c.cloud.ProjectID(), policy.Name, policy).Context(ctx).Do()
+	})
+}
+
+func (c *securityPolicyClient) AddRule(ctx context.Context, name string, rule *computealpha.SecurityPolicyRule) error {
+	return c.do(ctx, "AddRule", "add_rule", name, func(ctx context.Context) (*computealpha.Operation, error) {
+		return c.service().AddRule(c.cloud.ProjectID(), name, rule).Context(ctx).Do()
+	})
+}
+
+func (c *securityPolicyClient) PatchRule(ctx context.Context, name string, rule *computealpha.SecurityPolicyRule) error {
+	return c.do(ctx, "PatchRule", "patch_rule", name, func(ctx context.Context) (*computealpha.Operation, error) {
+		return c.service().PatchRule(c.cloud.ProjectID(), name, rule).Priority(rule.Priority).Context(ctx).Do()
+	})
+}
+
+func (c *securityPolicyClient) Delete(ctx context.Context, name string) error {
+	return c.do(ctx, "Delete", "delete", name, func(ctx context.Context) (*computealpha.Operation, error) {
+		return c.service().Delete(c.cloud.ProjectID(), name).Context(ctx).Do()
+	})
+}
+
+// EnsureRateLimit ensures that the backend service is protected by a managed
+// security policy matching the rate limit in the BackendConfig. The managed
+// policy shares the name of the backend service. If the rate limit was removed
+// from the BackendConfig, or the BackendConfig from the service port, the
+// managed policy is detached and deleted.
+func EnsureRateLimit(ctx context.Context, gceCloud *gce.Cloud, client SecurityPolicyClient, sp utils.ServicePort, be *composite.BackendService) error {
+	var rateLimit *backendconfigv1.RateLimitConfig
+	if sp.BackendConfig != nil {
+		rateLimit = sp.BackendConfig.Spec.RateLimit
+	}
+	if rateLimit == nil {
+		return removeRateLimit(ctx, gceCloud, client, sp, be)
+	}
+
+	if be.Scope != meta.Global {
+		return fmt.Errorf("rate limiting not supported for %s backend service %s", be.Scope, be.Name)
+	}
+
+	policyName := be.Name
+	desiredRule := rateLimitRule(rateLimit)
+	policy, err := client.Get(ctx, policyName)
+	switch {
+	case utils.IsNotFoundError(err):
+		klog.V(2).Infof("Creating rate limit security policy %s for backend service %s (%s:%s)", policyName, be.Name, sp.ID.Service.String(), sp.ID.Port.String())
+		policy = &computealpha.SecurityPolicy{
+			Name:        policyName,
+			Description: RateLimitPolicyDescription,
+			Rules:       []*computealpha.SecurityPolicyRule{desiredRule, defaultAllowRule()},
+		}
+		if rateLimit.AdaptiveProtection != nil && *rateLimit.AdaptiveProtection {
+			policy.AdaptiveProtectionConfig = adaptiveProtectionConfig(true)
+		}
+		if err := client.Insert(ctx, policy); err != nil {
+			return fmt.Errorf("failed to create rate limit security policy %s: %v", policyName, err)
+		}
+	case err != nil:
+		return err
+	case policy.Description != RateLimitPolicyDescription:
+		return fmt.Errorf("security policy %s already exists and is not managed by the controller", policyName)
+	default:
+		switch {
+		case ruleAtPriority(policy, desiredRule.Priority) == nil:
+			// GCE rejects patching a priority without a rule, e.g. if the
+			// rule was removed by hand.
+			klog.V(2).Infof("Adding rate limit rule to security policy %s (%s:%s)", policyName, sp.ID.Service.String(), sp.ID.Port.String())
+			if err := client.AddRule(ctx, policyName, desiredRule); err != nil {
+				return fmt.Errorf("failed to add rate limit rule to security policy %s: %v", policyName, err)
+			}
+		case !hasRule(policy, desiredRule):
+			klog.V(2).Infof("Updating rate limit rule in security policy %s (%s:%s)", policyName, sp.ID.Service.String(), sp.ID.Port.String())
+			if err := client.PatchRule(ctx, policyName, desiredRule); err != nil {
+				return fmt.Errorf("failed to update rate limit rule in security policy %s: %v", policyName, err)
+			}
+		}
+		if enable := rateLimit.AdaptiveProtection; enable != nil && adaptiveProtectionEnabled(policy) != *enable {
+			klog.V(2).Infof("Setting adaptive protection of security policy %s to %v (%s:%s)", policyName, *enable, sp.ID.Service.String(), sp.ID.Port.String())
+			patch := &computealpha.SecurityPolicy{
+				Name:                     policyName,
+				Fingerprint:              policy.Fingerprint,
+				AdaptiveProtectionConfig: adaptiveProtectionConfig(*enable),
+			}
+			if err := client.Patch(ctx, patch); err != nil {
+				return fmt.Errorf("failed to update adaptive protection of security policy %s: %v", policyName, err)
+			}
+		}
+	}
+
+	existingPolicyName, err := utils.KeyName(be.SecurityPolicy)
+	// The parser returns error for empty values.
+	if be.SecurityPolicy != "" && err != nil {
+		return err
+	}
+	if existingPolicyName == policyName {
+		return nil
+	}
+	klog.V(2).Infof("Set security policy in backend service %s (%s:%s) to rate limit policy %q", be.Name, sp.ID.Service.String(), sp.ID.Port.String(), policyName)
+	if err := composite.SetSecurityPolicy(ctx, gceCloud, be, policyName); err != nil {
+		return fmt.Errorf("failed to set security policy %q for backend service %s (%s:%s): %v", policyName, be.Name, sp.ID.Service.String(), sp.ID.Port.String(), err)
+	}
+	be.SecurityPolicy = cloud.SelfLink(meta.VersionGA, gceCloud.ProjectID(), "securityPolicies", meta.GlobalKey(policyName))
+	return nil
+}
+
+// removeRateLimit detaches and deletes the managed rate limit policy if it is
+// still attached to the backend service. Policies not created by the
+// controller are left untouched.
+func removeRateLimit(ctx context.Context, gceCloud *gce.Cloud, client SecurityPolicyClient, sp utils.ServicePort, be *composite.BackendService) error {
+	if be.SecurityPolicy == "" || be.Scope != meta.Global {
+		return nil
+	}
+	existingPolicyName, err := utils.KeyName(be.SecurityPolicy)
+	if err != nil {
+		return err
+	}
+	if existingPolicyName != be.Name {
+		return nil
+	}
+	policy, err := client.Get(ctx, existingPolicyName)
+	if utils.IsNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if policy.Description != RateLimitPolicyDescription {
+		return nil
+	}
+
+	klog.V(2).Infof("Detaching rate limit security policy %s from backend service %s (%s:%s)", existingPolicyName, be.Name, sp.ID.Service.String(), sp.ID.Port.String())
+	if err := composite.SetSecurityPolicy(ctx, gceCloud, be, ""); err != nil {
+		return fmt.Errorf("failed to detach security policy %q from backend service %s (%s:%s): %v", existingPolicyName, be.Name, sp.ID.Service.String(), sp.ID.Port.String(), err)
+	}
+	be.SecurityPolicy = ""
+	return DeleteRateLimitPolicy(ctx, client, existingPolicyName)
+}
+
+// DeleteRateLimitPolicy deletes the named security policy if it exists and
+// is managed by the controller.
+func DeleteRateLimitPolicy(ctx context.Context, client SecurityPolicyClient, name string) error {
+	policy, err := client.Get(ctx, name)
+	if utils.IsNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if policy.Description != RateLimitPolicyDescription {
+		return nil
+	}
+	klog.V(2).Infof("Deleting rate limit security policy %s", name)
+	if err := client.Delete(ctx, name); err != nil && !utils.IsNotFoundError(err) {
+		return fmt.Errorf("failed to delete rate limit security policy %s: %v", name, err)
+	}
+	return nil
+}
+
+// rateLimitRule returns the throttle rule for the given rate limit. If a ban
+// duration is set, clients exceeding the limit are banned for that duration.
+func rateLimitRule(rateLimit *backendconfigv1.RateLimitConfig) *computealpha.SecurityPolicyRule {
+	threshold := &computealpha.SecurityPolicyRuleRateLimitOptionsThreshold{
+		Count:       rateLimit.RequestsPerMinute,
+		IntervalSec: rateLimitIntervalSec,
+	}
+	rule := &computealpha.SecurityPolicyRule{
+		Priority:    rateLimitRulePriority,
+		Description: "Rate limit per client IP",
+		Action:      "throttle",
+		Match:       matchAllSourceIPs(),
+		RateLimitOptions: &computealpha.SecurityPolicyRuleRateLimitOptions{
+			ConformAction:      "allow",
+			ExceedAction:       "deny(429)",
+			EnforceOnKey:       "IP",
+			RateLimitThreshold: threshold,
+		},
+	}
+	if rateLimit.BanDurationSec != nil && *rateLimit.BanDurationSec > 0 {
+		rule.Action = "rate_based_ban"
+		rule.RateLimitOptions.BanDurationSec = *rateLimit.BanDurationSec
+	}
+	return rule
+}
+
+// adaptiveProtectionConfig returns the adaptive protection settings which
+// enable or disable the layer 7 DDoS defense.
+func adaptiveProtectionConfig(enable bool) *computealpha.SecurityPolicyAdaptiveProtectionConfig {
+	return &computealpha.SecurityPolicyAdaptiveProtectionConfig{
+		Layer7DdosDefenseConfig: &computealpha.SecurityPolicyAdaptiveProtectionConfigLayer7DdosDefenseConfig{
+			Enable: enable,
+			// Send false explicitly to disable the defense.
+			ForceSendFields: []string{"Enable"},
+		},
+	}
+}
+
+// adaptiveProtectionEnabled returns true if the layer 7 DDoS defense of the
+// policy is enabled.
+func adaptiveProtectionEnabled(policy *computealpha.SecurityPolicy) bool {
+	config := policy.AdaptiveProtectionConfig
+	return config != nil && config.Layer7DdosDefenseConfig != nil && config.Layer7DdosDefenseConfig.Enable
+}
+
+// defaultAllowRule returns the lowest priority rule every policy must have.
+func defaultAllowRule() *computealpha.SecurityPolicyRule {
+	return &computealpha.SecurityPolicyRule{
+		Priority:    defaultRulePriority,
+		Description: "Default rule",
+		Action:      "allow",
+		Match:       matchAllSourceIPs(),
+	}
+}
+
+func matchAllSourceIPs() *computealpha.SecurityPolicyRuleMatcher {
+	return &computealpha.SecurityPolicyRuleMatcher{
+		VersionedExpr: "SRC_IPS_V1",
+		Config: &computealpha.SecurityPolicyRuleMatcherConfig{
+			SrcIpRanges: []string{"*"},
+		},
+	}
+}
+
+// rateLimitRuleFields are the fields of the rate limit rule owned by the
+// controller.
+var rateLimitRuleFields = []utils.FieldSpec{
+	{Path: "Action"},
+	{Path: "Match.VersionedExpr"},
+	{Path: "Match.Config.SrcIpRanges", Unordered: true},
+	{Path: "RateLimitOptions.ConformAction"},
+	{Path: "RateLimitOptions.ExceedAction"},
+	{Path: "RateLimitOptions.EnforceOnKey"},
+	{Path: "RateLimitOptions.RateLimitThreshold.Count"},
+	{Path: "RateLimitOptions.RateLimitThreshold.IntervalSec"},
+	{Path: "RateLimitOptions.BanDurationSec"},
+}
+
+// ruleAtPriority returns the rule of the policy at the given priority, or nil
+// if there is none.
+func ruleAtPriority(policy *computealpha.SecurityPolicy, priority int64) *computealpha.SecurityPolicyRule {
+	for _, rule := range policy.Rules {
+		if rule.Priority == priority {
+			return rule
+		}
+	}
+	return nil
+}
+
+// hasRule returns true if the policy contains a rule equivalent to the
+// desired one at the same priority.
+func hasRule(policy *computealpha.SecurityPolicy, desired *computealpha.SecurityPolicyRule) bool {
+	rule := ruleAtPriority(policy, desired.Priority)
+	return rule != nil && len(utils.DiffFields(desired, rule, rateLimitRuleFields)) == 0
+}
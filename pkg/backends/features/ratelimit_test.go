@@ -0,0 +1,229 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	computealpha "google.golang.org/api/compute/v0.alpha"
+	"google.golang.org/api/compute/v1"
+	"k8s.io/legacy-cloud-providers/gce"
+
+	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
+	"k8s.io/ingress-gce/pkg/composite"
+	testutils "k8s.io/ingress-gce/pkg/test"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+func TestEnsureRateLimit(t *testing.T) {
+	const beName = "be-name"
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	var attached *compute.SecurityPolicyReference
+	setCalls := 0
+	(fakeGCE.Compute().(*cloud.MockGCE)).MockBackendServices.SetSecurityPolicyHook = func(_ context.Context, _ *meta.Key, ref *compute.SecurityPolicyReference, _ *cloud.MockBackendServices) error {
+		attached = ref
+		setCalls++
+		return nil
+	}
+	client := NewFakeSecurityPolicyClient()
+	be := &composite.BackendService{Name: beName, Scope: meta.Global}
+	spWith := func(rateLimit *backendconfigv1.RateLimitConfig) utils.ServicePort {
+		return utils.ServicePort{BackendConfig: &backendconfigv1.BackendConfig{
+			Spec: backendconfigv1.BackendConfigSpec{RateLimit: rateLimit},
+		}}
+	}
+
+	// Create the managed policy and attach it.
+	if err := EnsureRateLimit(context.TODO(), fakeGCE, client, spWith(&backendconfigv1.RateLimitConfig{RequestsPerMinute: 100}), be); err != nil {
+		t.Fatalf("EnsureRateLimit()=%v, want nil", err)
+	}
+	policy, ok := client.Policies[beName]
+	if !ok {
+		t.Fatalf("security policy %s not created", beName)
+	}
+	if len(policy.Rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(policy.Rules))
+	}
+	if got := policy.Rules[0]; got.Action != "throttle" || got.RateLimitOptions.RateLimitThreshold.Count != 100 {
+		t.Errorf("got rule %+v, want throttle rule with count 100", got)
+	}
+	if attached == nil || setCalls != 1 {
+		t.Fatalf("security policy not attached to backend service, set calls = %d", setCalls)
+	}
+
+	// A ban duration switches the rule to a rate based ban.
+	if err := EnsureRateLimit(context.TODO(), fakeGCE, client, spWith(&backendconfigv1.RateLimitConfig{RequestsPerMinute: 50, BanDurationSec: testutils.Int64ToPtr(600)}), be); err != nil {
+		t.Fatalf("EnsureRateLimit()=%v, want nil", err)
+	}
+	if got := client.Policies[beName].Rules[0]; got.Action != "rate_based_ban" || got.RateLimitOptions.BanDurationSec != 600 || got.RateLimitOptions.RateLimitThreshold.Count != 50 {
+		t.Errorf("got rule %+v, want rate based ban rule with count 50 and ban 600s", got)
+	}
+	if setCalls != 1 {
+		t.Errorf("got %d set calls, want no additional calls for an attached policy", setCalls)
+	}
+
+	// Removing the rate limit detaches and deletes the managed policy.
+	if err := EnsureRateLimit(context.TODO(), fakeGCE, client, spWith(nil), be); err != nil {
+		t.Fatalf("EnsureRateLimit()=%v, want nil", err)
+	}
+	if attached != nil {
+		t.Errorf("security policy %v still attached, want nil", attached)
+	}
+	if _, ok := client.Policies[beName]; ok {
+		t.Errorf("security policy %s not deleted", beName)
+	}
+
+	// Removing the BackendConfig also detaches and deletes the managed policy.
+	if err := EnsureRateLimit(context.TODO(), fakeGCE, client, spWith(&backendconfigv1.RateLimitConfig{RequestsPerMinute: 100}), be); err != nil {
+		t.Fatalf("EnsureRateLimit()=%v, want nil", err)
+	}
+	if attached == nil {
+		t.Fatalf("security policy not attached to backend service")
+	}
+	if err := EnsureRateLimit(context.TODO(), fakeGCE, client, utils.ServicePort{}, be); err != nil {
+		t.Fatalf("EnsureRateLimit()=%v, want nil", err)
+	}
+	if attached != nil {
+		t.Errorf("security policy %v still attached, want nil", attached)
+	}
+	if _, ok := client.Policies[beName]; ok {
+		t.Errorf("security policy %s not deleted", beName)
+	}
+}
+
+func TestEnsureRateLimitUnmanagedPolicy(t *testing.T) {
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	client := NewFakeSecurityPolicyClient()
+	client.Policies["be-name"] = &computealpha.SecurityPolicy{Name: "be-name"}
+	sp := utils.ServicePort{BackendConfig: &backendconfigv1.BackendConfig{
+		Spec: backendconfigv1.BackendConfigSpec{RateLimit: &backendconfigv1.RateLimitConfig{RequestsPerMinute: 100}},
+	}}
+
+	if err := EnsureRateLimit(context.TODO(), fakeGCE, client, sp, &composite.BackendService{Name: "be-name", Scope: meta.Global}); err == nil {
+		t.Errorf("EnsureRateLimit()=nil, want error for unmanaged policy")
+	}
+	if err := EnsureRateLimit(context.TODO(), fakeGCE, client, sp, &composite.BackendService{Name: "be-name", Scope: meta.Regional}); err == nil {
+		t.Errorf("EnsureRateLimit()=nil, want error for regional backend service")
+	}
+}
+
+func TestEnsureRateLimitRule(t *testing.T) {
+	const beName = "be-name"
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	(fakeGCE.Compute().(*cloud.MockGCE)).MockBackendServices.SetSecurityPolicyHook = func(context.Context, *meta.Key, *compute.SecurityPolicyReference, *cloud.MockBackendServices) error {
+		return nil
+	}
+	sp := utils.ServicePort{BackendConfig: &backendconfigv1.BackendConfig{
+		Spec: backendconfigv1.BackendConfigSpec{RateLimit: &backendconfigv1.RateLimitConfig{RequestsPerMinute: 100}},
+	}}
+
+	for _, tc := range []struct {
+		desc  string
+		rules []*computealpha.SecurityPolicyRule
+	}{
+		{
+			desc:  "rule removed by hand is added",
+			rules: []*computealpha.SecurityPolicyRule{defaultAllowRule()},
+		},
+		{
+			desc:  "outdated rule is patched",
+			rules: []*computealpha.SecurityPolicyRule{rateLimitRule(&backendconfigv1.RateLimitConfig{RequestsPerMinute: 50}), defaultAllowRule()},
+		},
+	} {
+		client := NewFakeSecurityPolicyClient()
+		client.Policies[beName] = &computealpha.SecurityPolicy{Name: beName, Description: RateLimitPolicyDescription, Rules: tc.rules}
+		be := &composite.BackendService{Name: beName, Scope: meta.Global}
+		if err := EnsureRateLimit(context.TODO(), fakeGCE, client, sp, be); err != nil {
+			t.Fatalf("%s: EnsureRateLimit()=%v, want nil", tc.desc, err)
+		}
+		policy := client.Policies[beName]
+		if len(policy.Rules) != 2 {
+			t.Errorf("%s: got %d rules, want 2", tc.desc, len(policy.Rules))
+		}
+		if !hasRule(policy, rateLimitRule(&backendconfigv1.RateLimitConfig{RequestsPerMinute: 100})) {
+			t.Errorf("%s: got rules %+v, want throttle rule with count 100", tc.desc, policy.Rules)
+		}
+	}
+}
+
+func TestEnsureRateLimitAdaptiveProtection(t *testing.T) {
+	const beName = "be-name"
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	(fakeGCE.Compute().(*cloud.MockGCE)).MockBackendServices.SetSecurityPolicyHook = func(context.Context, *meta.Key, *compute.SecurityPolicyReference, *cloud.MockBackendServices) error {
+		return nil
+	}
+	client := NewFakeSecurityPolicyClient()
+	be := &composite.BackendService{Name: beName, Scope: meta.Global}
+	spWith := func(adaptiveProtection *bool) utils.ServicePort {
+		return utils.ServicePort{BackendConfig: &backendconfigv1.BackendConfig{
+			Spec: backendconfigv1.BackendConfigSpec{RateLimit: &backendconfigv1.RateLimitConfig{RequestsPerMinute: 100, AdaptiveProtection: adaptiveProtection}},
+		}}
+	}
+
+	for _, step := range []struct {
+		desc               string
+		adaptiveProtection *bool
+		want               bool
+	}{
+		{desc: "created enabled", adaptiveProtection: testutils.BoolToPtr(true), want: true},
+		{desc: "unset is not reconciled", want: true},
+		{desc: "disabled", adaptiveProtection: testutils.BoolToPtr(false), want: false},
+		{desc: "enabled", adaptiveProtection: testutils.BoolToPtr(true), want: true},
+	} {
+		if err := EnsureRateLimit(context.TODO(), fakeGCE, client, spWith(step.adaptiveProtection), be); err != nil {
+			t.Fatalf("%s: EnsureRateLimit()=%v, want nil", step.desc, err)
+		}
+		if got := adaptiveProtectionEnabled(client.Policies[beName]); got != step.want {
+			t.Errorf("%s: adaptive protection enabled = %v, want %v", step.desc, got, step.want)
+		}
+	}
+}
+
+func TestHasRule(t *testing.T) {
+	desired := rateLimitRule(&backendconfigv1.RateLimitConfig{RequestsPerMinute: 100})
+	for _, tc := range []struct {
+		desc   string
+		mutate func(rule *computealpha.SecurityPolicyRule)
+		want   bool
+	}{
+		{desc: "same rule", mutate: func(*computealpha.SecurityPolicyRule) {}, want: true},
+		{desc: "fields filled in by GCE", mutate: func(rule *computealpha.SecurityPolicyRule) {
+			rule.Kind = "compute#securityPolicyRule"
+			rule.Description = "edited description"
+			rule.Match.Config.ForceSendFields = []string{"SrcIpRanges"}
+		}, want: true},
+		{desc: "different count", mutate: func(rule *computealpha.SecurityPolicyRule) {
+			rule.RateLimitOptions.RateLimitThreshold.Count = 50
+		}, want: false},
+		{desc: "different action", mutate: func(rule *computealpha.SecurityPolicyRule) {
+			rule.Action = "allow"
+		}, want: false},
+		{desc: "different priority", mutate: func(rule *computealpha.SecurityPolicyRule) {
+			rule.Priority++
+		}, want: false},
+	} {
+		rule := rateLimitRule(&backendconfigv1.RateLimitConfig{RequestsPerMinute: 100})
+		tc.mutate(rule)
+		policy := &computealpha.SecurityPolicy{Rules: []*computealpha.SecurityPolicyRule{rule, defaultAllowRule()}}
+		if got := hasRule(policy, desired); got != tc.want {
+			t.Errorf("%s: hasRule() = %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
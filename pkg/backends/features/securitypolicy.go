@@ -17,10 +17,12 @@ limitations under the License.
 package features
 
 import (
+	"context"
 	"fmt"
 
 	"k8s.io/klog"
 
+	cloudprovider "github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
 	"k8s.io/legacy-cloud-providers/gce"
 
@@ -30,7 +32,7 @@ import (
 
 // EnsureSecurityPolicy ensures the security policy link on backend service.
 // TODO(mrhohn): Emit event when attach/detach security policy to backend service.
-func EnsureSecurityPolicy(cloud *gce.Cloud, sp utils.ServicePort, be *composite.BackendService) error {
+func EnsureSecurityPolicy(ctx context.Context, cloud *gce.Cloud, sp utils.ServicePort, be *composite.BackendService) error {
 	if sp.BackendConfig.Spec.SecurityPolicy == nil {
 		return nil
 	}
@@ -50,8 +52,12 @@ func EnsureSecurityPolicy(cloud *gce.Cloud, sp utils.ServicePort, be *composite.
 	}
 
 	klog.V(2).Infof("Set security policy in backend service %s (%s:%s) to %q", be.Name, sp.ID.Service.String(), sp.ID.Port.String(), desiredPolicyName)
-	if err := composite.SetSecurityPolicy(cloud, be, desiredPolicyName); err != nil {
+	if err := composite.SetSecurityPolicy(ctx, cloud, be, desiredPolicyName); err != nil {
 		return fmt.Errorf("failed to set security policy %q for backend service %s (%s:%s): %v", desiredPolicyName, be.Name, sp.ID.Service.String(), sp.ID.Port.String(), err)
 	}
+	be.SecurityPolicy = ""
+	if desiredPolicyName != "" {
+		be.SecurityPolicy = cloudprovider.SelfLink(meta.VersionGA, cloud.ProjectID(), "securityPolicies", meta.GlobalKey(desiredPolicyName))
+	}
 	return nil
 }
@@ -148,7 +148,7 @@ func TestEnsureSecurityPolicy(t *testing.T) {
 
 			(fakeGCE.Compute().(*cloud.MockGCE)).MockBackendServices.SetSecurityPolicyHook = setSecurityPolicyHook
 
-			err := EnsureSecurityPolicy(fakeGCE, utils.ServicePort{BackendConfig: tc.desiredConfig}, tc.currentBackendService)
+			err := EnsureSecurityPolicy(context.TODO(), fakeGCE, utils.ServicePort{BackendConfig: tc.desiredConfig}, tc.currentBackendService)
 			if !tc.expectError && err != nil {
 				t.Errorf("EnsureSecurityPolicy()=%v, want nil", err)
 			}
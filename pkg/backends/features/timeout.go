@@ -17,13 +17,15 @@ limitations under the License.
 package features
 
 import (
-	"reflect"
-
 	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/klog"
 )
 
+var timeoutFields = []utils.FieldSpec{
+	{Path: "TimeoutSec", ServerDefault: int64(30)},
+}
+
 // EnsureTimeout reads the TimeoutSec configuration specified in the ServicePort.BackendConfig
 // and applies it to the BackendService. It returns true if there were existing
 // settings on the BackendService that were overwritten.
@@ -33,7 +35,7 @@ func EnsureTimeout(sp utils.ServicePort, be *composite.BackendService) bool {
 	}
 	beTemp := &composite.BackendService{}
 	applyTimeoutSettings(sp, beTemp)
-	if !reflect.DeepEqual(beTemp.TimeoutSec, be.TimeoutSec) {
+	if len(utils.DiffFields(beTemp, be, timeoutFields)) > 0 {
 		applyTimeoutSettings(sp, be)
 		klog.V(2).Infof("Updated Timeout settings for service %v/%v.", sp.ID.Service.Namespace, sp.ID.Service.Name)
 		return true
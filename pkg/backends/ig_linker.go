@@ -14,12 +14,15 @@ limitations under the License.
 package backends
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
 	"k8s.io/apimachinery/pkg/util/sets"
+	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
+	"k8s.io/ingress-gce/pkg/backends/features"
 	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/instances"
 	"k8s.io/ingress-gce/pkg/utils"
@@ -80,7 +83,7 @@ func NewInstanceGroupLinker(
 }
 
 // Link implements Link.
-func (l *instanceGroupLinker) Link(sp utils.ServicePort, groups []GroupKey) error {
+func (l *instanceGroupLinker) Link(ctx context.Context, sp utils.ServicePort, groups []GroupKey) error {
 	var igLinks []string
 	for _, group := range groups {
 		ig, err := l.instancePool.Get(sp.IGName(), group.Zone)
@@ -93,28 +96,49 @@ func (l *instanceGroupLinker) Link(sp utils.ServicePort, groups []GroupKey) erro
 	// ig_linker only supports L7 HTTP(s) External Load Balancer
 	// Hardcoded here since IGs are not supported for non GA-Global right now
 	// TODO(shance): find a way to remove hardcoded values
-	be, err := l.backendPool.Get(sp.BackendName(), meta.VersionGA, meta.Global)
+	be, err := l.backendPool.Get(ctx, sp.BackendName(), meta.VersionGA, meta.Global)
 	if err != nil {
 		return err
 	}
 
+	if sp.BackendConfig != nil && sp.BackendConfig.Spec.Balancing != nil {
+		return l.linkWithBalancing(ctx, sp, be, igLinks, sp.BackendConfig.Spec.Balancing)
+	}
+
 	addIGs, err := getInstanceGroupsToAdd(be, igLinks)
 	if err != nil {
 		return err
 	}
 
-	if len(addIGs) == 0 {
-		return nil
-	}
-
 	originalIGBackends := []*composite.Backend{}
+	// resetIGs are the instance groups of backends which do not use one of
+	// the default balancing settings, e.g. after the balancing settings were
+	// removed from the BackendConfig.
+	var resetIGs []string
 	for _, backend := range be.Backends {
 		// Backend service is not able to point to NEG and IG at the same time.
 		// Filter IG backends here.
-		if strings.Contains(backend.Group, "instanceGroups") {
+		if !strings.Contains(backend.Group, "instanceGroups") {
+			continue
+		}
+		if hasDefaultBalancing(backend) {
 			originalIGBackends = append(originalIGBackends, backend)
+		} else {
+			resetIGs = append(resetIGs, backend.Group)
 		}
 	}
+	if len(resetIGs) > 0 {
+		klog.V(2).Infof("Resetting balancing settings of backend service %s backends %v to the default", be.Name, resetIGs)
+		addIGs = append(addIGs, resetIGs...)
+	}
+
+	if len(addIGs) == 0 {
+		return nil
+	}
+
+	if err := checkBackendCount(sp, len(originalIGBackends)+len(addIGs)); err != nil {
+		return err
+	}
 
 	// We first try to create the backend with balancingMode=RATE.  If this	+ return addIGs
 	// fails, it's mostly likely because there are existing backends with
@@ -128,7 +152,7 @@ func (l *instanceGroupLinker) Link(sp utils.ServicePort, groups []GroupKey) erro
 		newBackends := getBackendsForIGs(addIGs, bm)
 		be.Backends = append(originalIGBackends, newBackends...)
 
-		if err := l.backendPool.Update(be); err != nil {
+		if err := l.backendPool.Update(ctx, be); err != nil {
 			if utils.IsHTTPErrorCode(err, http.StatusBadRequest) {
 				klog.V(2).Infof("Updating backend service backends with balancing mode %v failed, will try another mode. err:%v", bm, err)
 				errs = append(errs, err.Error())
@@ -147,6 +171,78 @@ func (l *instanceGroupLinker) Link(sp utils.ServicePort, groups []GroupKey) erro
 	return fmt.Errorf("received errors when updating backend service: %v", strings.Join(errs, "\n"))
 }
 
+// linkWithBalancing links the instance groups using the balancing settings
+// from the BackendConfig. Unlike the default path, existing instance group
+// backends are updated to match the settings and no fallback mode is tried.
+func (l *instanceGroupLinker) linkWithBalancing(ctx context.Context, sp utils.ServicePort, be *composite.BackendService, igLinks []string, balancing *backendconfigv1.BalancingConfig) error {
+	addIGs, err := getInstanceGroupsToAdd(be, igLinks)
+	if err != nil {
+		return err
+	}
+
+	needUpdate := len(addIGs) > 0
+	igBackends := []*composite.Backend{}
+	for _, backend := range be.Backends {
+		// Backend service is not able to point to NEG and IG at the same time.
+		// Filter IG backends here.
+		if strings.Contains(backend.Group, "instanceGroups") {
+			needUpdate = applyBalancing(backend, balancing) || needUpdate
+			igBackends = append(igBackends, backend)
+		}
+	}
+	if !needUpdate {
+		return nil
+	}
+
+	for _, backend := range getBackendsForIGs(addIGs, BalancingMode(balancing.BalancingMode)) {
+		applyBalancing(backend, balancing)
+		igBackends = append(igBackends, backend)
+	}
+	if err := checkBackendCount(sp, len(igBackends)); err != nil {
+		return err
+	}
+	be.Backends = igBackends
+	klog.V(2).Infof("Updating backend service %s backends with balancing mode %v", be.Name, balancing.BalancingMode)
+	return l.backendPool.Update(ctx, be)
+}
+
+// applyBalancing sets the balancing settings on the backend and returns true
+// if the backend was modified.
+func applyBalancing(b *composite.Backend, balancing *backendconfigv1.BalancingConfig) bool {
+	var maxRate, maxUtilization float64
+	switch BalancingMode(balancing.BalancingMode) {
+	case Rate:
+		maxRate = maxRPS
+		if balancing.MaxRatePerInstance != nil {
+			maxRate = *balancing.MaxRatePerInstance
+		}
+	case Utilization:
+		if balancing.MaxUtilization != nil {
+			maxUtilization = *balancing.MaxUtilization
+		}
+	}
+	want := &composite.Backend{BalancingMode: balancing.BalancingMode, MaxRatePerInstance: maxRate, MaxUtilization: maxUtilization}
+	if !features.BalancingChanged(want, b) {
+		return false
+	}
+	b.BalancingMode = balancing.BalancingMode
+	b.MaxRatePerInstance = maxRate
+	b.MaxUtilization = maxUtilization
+	return true
+}
+
+// hasDefaultBalancing returns true if the backend uses the balancing settings
+// of one of the modes tried for backends without balancing settings in the
+// BackendConfig.
+func hasDefaultBalancing(b *composite.Backend) bool {
+	for _, bm := range []BalancingMode{Rate, Utilization} {
+		if !features.BalancingChanged(getBackendsForIGs([]string{b.Group}, bm)[0], b) {
+			return true
+		}
+	}
+	return false
+}
+
 func getBackendsForIGs(igLinks []string, bm BalancingMode) []*composite.Backend {
 	var backends []*composite.Backend
 	for _, igLink := range igLinks {
@@ -25,7 +25,9 @@ import (
 	"google.golang.org/api/googleapi"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/ingress-gce/pkg/annotations"
+	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
 	"k8s.io/ingress-gce/pkg/backends/features"
+	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/instances"
 	"k8s.io/ingress-gce/pkg/test"
 	"k8s.io/ingress-gce/pkg/utils"
@@ -60,9 +62,9 @@ func TestLink(t *testing.T) {
 	}
 
 	// Mimic the syncer creating the backend.
-	linker.backendPool.Create(sp, "fake-health-check-link")
+	linker.backendPool.Create(context.TODO(), sp, "fake-health-check-link")
 
-	if err := linker.Link(sp, []GroupKey{{Zone: defaultZone}}); err != nil {
+	if err := linker.Link(context.TODO(), sp, []GroupKey{{Zone: defaultZone}}); err != nil {
 		t.Fatalf("%v", err)
 	}
 
@@ -104,9 +106,9 @@ func TestLinkWithCreationModeError(t *testing.T) {
 		}
 
 		// Mimic the syncer creating the backend.
-		linker.backendPool.Create(sp, "fake-health-check-link")
+		linker.backendPool.Create(context.TODO(), sp, "fake-health-check-link")
 
-		if err := linker.Link(sp, []GroupKey{{Zone: defaultZone}}); err != nil {
+		if err := linker.Link(context.TODO(), sp, []GroupKey{{Zone: defaultZone}}); err != nil {
 			t.Fatalf("%v", err)
 		}
 
@@ -124,6 +126,108 @@ func TestLinkWithCreationModeError(t *testing.T) {
 				t.Fatalf("Wrong balancing mode, expected %v got %v", modes[(i+1)%len(modes)], b.BalancingMode)
 			}
 		}
-		linker.backendPool.Delete(sp.BackendName(), features.VersionFromServicePort(&sp), features.ScopeFromServicePort(&sp))
+		linker.backendPool.Delete(context.TODO(), sp.BackendName(), features.VersionFromServicePort(&sp), features.ScopeFromServicePort(&sp))
+	}
+}
+
+func TestLinkWithBalancingOverride(t *testing.T) {
+	fakeIGs := instances.NewFakeInstanceGroups(sets.NewString(), defaultNamer)
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	fakeNodePool := instances.NewNodePool(fakeIGs, defaultNamer, &test.FakeRecorderSource{}, utils.GetBasePath(fakeGCE))
+	linker := newTestIGLinker(fakeGCE, fakeNodePool)
+
+	sp := utils.ServicePort{NodePort: 8080, Protocol: annotations.ProtocolHTTP, BackendNamer: defaultNamer}
+
+	// Mimic the instance group being created
+	if _, err := linker.instancePool.EnsureInstanceGroupsAndPorts(defaultNamer.InstanceGroup(), []int64{sp.NodePort}); err != nil {
+		t.Fatalf("Did not expect error when ensuring IG for ServicePort %+v: %v", sp, err)
+	}
+
+	// Mimic the syncer creating the backend.
+	linker.backendPool.Create(context.TODO(), sp, "fake-health-check-link")
+
+	// Link with the default balancing mode first.
+	if err := linker.Link(context.TODO(), sp, []GroupKey{{Zone: defaultZone}}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	for _, tc := range []struct {
+		desc               string
+		balancing          *backendconfigv1.BalancingConfig
+		wantMode           string
+		wantMaxRate        float64
+		wantMaxUtilization float64
+	}{
+		{
+			desc: "utilization with custom target",
+			balancing: &backendconfigv1.BalancingConfig{
+				BalancingMode:  "UTILIZATION",
+				MaxUtilization: test.Float64ToPtr(0.6),
+			},
+			wantMode:           "UTILIZATION",
+			wantMaxUtilization: 0.6,
+		},
+		{
+			desc:        "utilization override removed",
+			wantMode:    "RATE",
+			wantMaxRate: maxRPS,
+		},
+		{
+			desc: "rate with custom target",
+			balancing: &backendconfigv1.BalancingConfig{
+				BalancingMode:      "RATE",
+				MaxRatePerInstance: test.Float64ToPtr(100),
+			},
+			wantMode:    "RATE",
+			wantMaxRate: 100,
+		},
+		{
+			desc:        "rate override removed",
+			wantMode:    "RATE",
+			wantMaxRate: maxRPS,
+		},
+		{
+			desc:        "rate with default target",
+			balancing:   &backendconfigv1.BalancingConfig{BalancingMode: "RATE"},
+			wantMode:    "RATE",
+			wantMaxRate: maxRPS,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			sp.BackendConfig = &backendconfigv1.BackendConfig{
+				Spec: backendconfigv1.BackendConfigSpec{Balancing: tc.balancing},
+			}
+			if err := linker.Link(context.TODO(), sp, []GroupKey{{Zone: defaultZone}}); err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			be, err := fakeGCE.GetGlobalBackendService(sp.BackendName())
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			if len(be.Backends) != 1 {
+				t.Fatalf("Expected 1 backend, got %d", len(be.Backends))
+			}
+			b := be.Backends[0]
+			if b.BalancingMode != tc.wantMode || b.MaxRatePerInstance != tc.wantMaxRate || b.MaxUtilization != tc.wantMaxUtilization {
+				t.Errorf("Got backend (mode=%v, maxRate=%v, maxUtilization=%v), want (mode=%v, maxRate=%v, maxUtilization=%v)",
+					b.BalancingMode, b.MaxRatePerInstance, b.MaxUtilization, tc.wantMode, tc.wantMaxRate, tc.wantMaxUtilization)
+			}
+		})
+	}
+}
+
+func TestApplyBalancing(t *testing.T) {
+	// GCE reports a maximum utilization of 0.8 for backends which do not set
+	// one, which must not trigger an update on every sync.
+	b := &composite.Backend{BalancingMode: "UTILIZATION", MaxUtilization: 0.8}
+	if applyBalancing(b, &backendconfigv1.BalancingConfig{BalancingMode: "UTILIZATION"}) {
+		t.Errorf("applyBalancing() = true for the GCE default maximum utilization, want false")
+	}
+	if !applyBalancing(b, &backendconfigv1.BalancingConfig{BalancingMode: "UTILIZATION", MaxUtilization: test.Float64ToPtr(0.6)}) {
+		t.Errorf("applyBalancing() = false for a changed maximum utilization, want true")
+	}
+	if b.MaxUtilization != 0.6 {
+		t.Errorf("applyBalancing() set maximum utilization %v, want 0.6", b.MaxUtilization)
 	}
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package backends
 
 import (
+	"context"
+
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
 	api_v1 "k8s.io/api/core/v1"
 	"k8s.io/ingress-gce/pkg/composite"
@@ -34,17 +36,17 @@ type GroupKey struct {
 // Backend Services.
 type Pool interface {
 	// Get a composite BackendService given a required version.
-	Get(name string, version meta.Version, scope meta.KeyType) (*composite.BackendService, error)
+	Get(ctx context.Context, name string, version meta.Version, scope meta.KeyType) (*composite.BackendService, error)
 	// Create a composite BackendService and returns it.
-	Create(sp utils.ServicePort, hcLink string) (*composite.BackendService, error)
+	Create(ctx context.Context, sp utils.ServicePort, hcLink string) (*composite.BackendService, error)
 	// Update a BackendService given the composite type.
-	Update(be *composite.BackendService) error
+	Update(ctx context.Context, be *composite.BackendService) error
 	// Delete a BackendService given its name.
-	Delete(name string, version meta.Version, scope meta.KeyType) error
+	Delete(ctx context.Context, name string, version meta.Version, scope meta.KeyType) error
 	// Get the health of a BackendService given its name.
-	Health(name string, version meta.Version, scope meta.KeyType) (string, error)
+	Health(ctx context.Context, name string, version meta.Version, scope meta.KeyType) (string, error)
 	// Get a list of BackendService names that are managed by this pool.
-	List(key *meta.Key, version meta.Version) ([]*composite.BackendService, error)
+	List(ctx context.Context, key *meta.Key, version meta.Version) ([]*composite.BackendService, error)
 }
 
 // Syncer is an interface to sync Kubernetes services to GCE BackendServices.
@@ -53,19 +55,28 @@ type Syncer interface {
 	Init(p ProbeProvider)
 	// Sync a BackendService. Implementations should only create the BackendService
 	// but not its groups.
-	Sync(svcPorts []utils.ServicePort) error
+	Sync(ctx context.Context, svcPorts []utils.ServicePort) error
 	// GC garbage collects unused BackendService's
-	GC(svcPorts []utils.ServicePort) error
+	GC(ctx context.Context, svcPorts []utils.ServicePort) error
+	// ExplainGC returns the decisions GC would take for the BackendServices,
+	// without deleting any.
+	ExplainGC(ctx context.Context, svcPorts []utils.ServicePort) ([]utils.GCDecision, error)
 	// Status returns the status of a BackendService given its name.
-	Status(name string, version meta.Version, scope meta.KeyType) (string, error)
+	Status(ctx context.Context, name string, version meta.Version, scope meta.KeyType) (string, error)
 	// Shutdown cleans up all BackendService's previously synced.
-	Shutdown() error
+	Shutdown(ctx context.Context) error
+	// Fingerprints returns the fingerprints of the BackendServices which are
+	// cached as in sync, to be handed off to the next leader.
+	Fingerprints() []Fingerprint
+	// RestoreFingerprints caches the BackendServices of fingerprints handed
+	// off by the previous leader as in sync.
+	RestoreFingerprints(fingerprints []Fingerprint)
 }
 
 // Linker is an interface to link backends with their associated groups.
 type Linker interface {
 	// Link a BackendService to its groups.
-	Link(sp utils.ServicePort, groups []GroupKey) error
+	Link(ctx context.Context, sp utils.ServicePort, groups []GroupKey) error
 }
 
 // NEGGetter is an interface to retrieve NEG object
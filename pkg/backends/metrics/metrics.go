@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	backendsSubsystem    = "backends"
+	cacheLookupsKey      = "backend_service_cache_lookups_total"
+	cacheStaleEntriesKey = "backend_service_cache_stale_entries_total"
+	cacheEntryAgeKey     = "backend_service_cache_entry_age_seconds"
+	cacheEntriesKey      = "backend_service_cache_entries"
+
+	// CacheHit is a lookup served from the cache.
+	CacheHit = "hit"
+	// CacheMiss is a lookup of a backend service which is not cached or
+	// whose inputs changed since it was cached.
+	CacheMiss = "miss"
+	// CacheVerify is a lookup of an entry which is due for verification.
+	CacheVerify = "verify"
+)
+
+var (
+	cacheLookupsMetricsLabels = []string{
+		"result", // hit, miss or verify
+	}
+
+	// CacheLookups counts the lookups of the backend service cache.
+	CacheLookups = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: backendsSubsystem,
+			Name:      cacheLookupsKey,
+			Help:      "Number of lookups of the backend service cache",
+		},
+		cacheLookupsMetricsLabels,
+	)
+
+	// CacheStaleEntries counts the cache entries which no longer matched the
+	// backend service in GCE when they were verified.
+	CacheStaleEntries = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Subsystem: backendsSubsystem,
+			Name:      cacheStaleEntriesKey,
+			Help:      "Number of backend service cache entries found stale on verification",
+		},
+	)
+
+	// CacheEntryAge observes the age of the cache entries when they are
+	// verified.
+	CacheEntryAge = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Subsystem: backendsSubsystem,
+			Name:      cacheEntryAgeKey,
+			Help:      "Age of backend service cache entries when they are verified",
+			// custom buckets - [1s, 2s, 4s, 8s, 16s, 32s, 64s, 128s, 256s(~4min), 512s(~8min), 1024s(~17min), 2048 (~34min), 4096(~68min), +Inf]
+			Buckets: prometheus.ExponentialBuckets(1, 2, 13),
+		},
+	)
+
+	// CacheEntries is the number of backend services in the cache.
+	CacheEntries = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: backendsSubsystem,
+			Name:      cacheEntriesKey,
+			Help:      "Number of backend services in the backend service cache",
+		},
+	)
+)
+
+var register sync.Once
+
+func RegisterMetrics() {
+	register.Do(func() {
+		prometheus.MustRegister(CacheLookups)
+		prometheus.MustRegister(CacheStaleEntries)
+		prometheus.MustRegister(CacheEntryAge)
+		prometheus.MustRegister(CacheEntries)
+	})
+}
@@ -14,6 +14,8 @@ limitations under the License.
 package backends
 
 import (
+	"context"
+
 	"k8s.io/apimachinery/pkg/util/sets"
 	befeatures "k8s.io/ingress-gce/pkg/backends/features"
 	"k8s.io/ingress-gce/pkg/composite"
@@ -27,7 +29,8 @@ import (
 type negLinker struct {
 	backendPool Pool
 	negGetter   NEGGetter
-	cloud       *gce.Cloud
+	// compositeCloud manages the backend services linked to NEGs.
+	compositeCloud composite.Cloud
 }
 
 // negLinker is a Linker
@@ -38,14 +41,14 @@ func NewNEGLinker(
 	negGetter NEGGetter,
 	cloud *gce.Cloud) Linker {
 	return &negLinker{
-		backendPool: backendPool,
-		negGetter:   negGetter,
-		cloud:       cloud,
+		backendPool:    backendPool,
+		negGetter:      negGetter,
+		compositeCloud: composite.NewCloud(cloud),
 	}
 }
 
 // Link implements Link.
-func (l *negLinker) Link(sp utils.ServicePort, groups []GroupKey) error {
+func (l *negLinker) Link(ctx context.Context, sp utils.ServicePort, groups []GroupKey) error {
 	version := befeatures.VersionFromServicePort(&sp)
 	var negs []*composite.NetworkEndpointGroup
 	var err error
@@ -54,7 +57,7 @@ func (l *negLinker) Link(sp utils.ServicePort, groups []GroupKey) error {
 		// Otherwise, get the name from svc port.
 		negName := group.Name
 		if negName == "" {
-			negName = sp.BackendName()
+			negName = sp.NEGName()
 		}
 		neg, err := l.negGetter.GetNetworkEndpointGroup(negName, group.Zone, version)
 		if err != nil {
@@ -66,11 +69,11 @@ func (l *negLinker) Link(sp utils.ServicePort, groups []GroupKey) error {
 	beName := sp.BackendName()
 	scope := befeatures.ScopeFromServicePort(&sp)
 
-	key, err := composite.CreateKey(l.cloud, beName, scope)
+	key, err := l.compositeCloud.CreateKey(beName, scope)
 	if err != nil {
 		return err
 	}
-	backendService, err := composite.GetBackendService(l.cloud, key, version)
+	backendService, err := l.compositeCloud.GetBackendService(ctx, key, version)
 	if err != nil {
 		return err
 	}
@@ -90,8 +93,11 @@ func (l *negLinker) Link(sp utils.ServicePort, groups []GroupKey) error {
 
 	if !oldBackends.Equal(newBackends) {
 		klog.V(2).Infof("Backends changed for service port %s, removing: %s and adding: %s", sp.ID, oldBackends.Difference(newBackends), newBackends.Difference(oldBackends))
+		if err := checkBackendCount(sp, len(targetBackends)); err != nil {
+			return err
+		}
 		backendService.Backends = targetBackends
-		return composite.UpdateBackendService(l.cloud, key, backendService)
+		return l.compositeCloud.UpdateBackendService(ctx, key, backendService)
 	}
 	return nil
 }
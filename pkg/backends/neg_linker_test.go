@@ -14,6 +14,8 @@ limitations under the License.
 package backends
 
 import (
+	"context"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -39,7 +41,7 @@ func newTestNEGLinker(fakeNEG negtypes.NetworkEndpointGroupCloud, fakeGCE *gce.C
 	(fakeGCE.Compute().(*cloud.MockGCE)).MockAlphaRegionBackendServices.UpdateHook = mock.UpdateAlphaRegionBackendServiceHook
 	(fakeGCE.Compute().(*cloud.MockGCE)).MockBetaRegionBackendServices.UpdateHook = mock.UpdateBetaRegionBackendServiceHook
 	(fakeGCE.Compute().(*cloud.MockGCE)).MockRegionBackendServices.UpdateHook = mock.UpdateRegionBackendServiceHook
-	return &negLinker{fakeBackendPool, fakeNEG, fakeGCE}
+	return &negLinker{fakeBackendPool, fakeNEG, composite.NewCloud(fakeGCE)}
 }
 
 func TestLinkBackendServiceToNEG(t *testing.T) {
@@ -66,7 +68,7 @@ func TestLinkBackendServiceToNEG(t *testing.T) {
 			BackendNamer: defaultNamer},
 	} {
 		// Mimic how the syncer would create the backend.
-		if _, err := linker.backendPool.Create(svcPort, "fake-healthcheck-link"); err != nil {
+		if _, err := linker.backendPool.Create(context.TODO(), svcPort, "fake-healthcheck-link"); err != nil {
 			t.Fatalf("Failed to create backend service to NEG for svcPort %v: %v", svcPort, err)
 		}
 
@@ -86,7 +88,7 @@ func TestLinkBackendServiceToNEG(t *testing.T) {
 			}
 		}
 
-		if err := linker.Link(svcPort, zones); err != nil {
+		if err := linker.Link(context.TODO(), svcPort, zones); err != nil {
 			t.Fatalf("Failed to link backend service to NEG for svcPort %v: %v", svcPort, err)
 		}
 
@@ -97,7 +99,7 @@ func TestLinkBackendServiceToNEG(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Failed to create composite key - %v", err)
 		}
-		bs, err := composite.GetBackendService(fakeGCE, key, version)
+		bs, err := composite.GetBackendService(context.TODO(), fakeGCE, key, version)
 		if err != nil {
 			t.Fatalf("Failed to retrieve backend service using key %+v for svcPort %v: %v", key, svcPort, err)
 		}
@@ -119,3 +121,47 @@ func TestLinkBackendServiceToNEG(t *testing.T) {
 		}
 	}
 }
+
+func TestLinkBackendServiceToNEGBackendLimit(t *testing.T) {
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	fakeNEG := negtypes.NewFakeNetworkEndpointGroupCloud("test-subnetwork", "test-network")
+	linker := newTestNEGLinker(fakeNEG, fakeGCE)
+
+	svcPort := utils.ServicePort{
+		ID:           utils.ServicePortID{Service: types.NamespacedName{Namespace: "ns", Name: "name"}},
+		Port:         80,
+		NodePort:     30001,
+		Protocol:     annotations.ProtocolHTTP,
+		TargetPort:   "port",
+		NEGEnabled:   true,
+		BackendNamer: defaultNamer,
+	}
+	if _, err := linker.backendPool.Create(context.TODO(), svcPort, "fake-healthcheck-link"); err != nil {
+		t.Fatalf("Failed to create backend service to NEG for svcPort %v: %v", svcPort, err)
+	}
+	var zones []GroupKey
+	for i := 0; i <= maxBackendsPerService; i++ {
+		zone := fmt.Sprintf("zone%d", i)
+		neg := &composite.NetworkEndpointGroup{Name: svcPort.BackendName(), Version: befeatures.VersionFromServicePort(&svcPort)}
+		if err := fakeNEG.CreateNetworkEndpointGroup(neg, zone); err != nil {
+			t.Fatalf("unexpected error creating NEG for svcPort %v: %v", svcPort, err)
+		}
+		zones = append(zones, GroupKey{Zone: zone})
+	}
+
+	err := linker.Link(context.TODO(), svcPort, zones)
+	if err == nil || !strings.Contains(err.Error(), "exceeding the GCE limit") {
+		t.Fatalf("linker.Link(%v, %d zones) = %v, want backend limit error", svcPort.ID, len(zones), err)
+	}
+	key, err := composite.CreateKey(fakeGCE, svcPort.BackendName(), befeatures.ScopeFromServicePort(&svcPort))
+	if err != nil {
+		t.Fatalf("Failed to create composite key - %v", err)
+	}
+	bs, err := composite.GetBackendService(context.TODO(), fakeGCE, key, befeatures.VersionFromServicePort(&svcPort))
+	if err != nil {
+		t.Fatalf("Failed to retrieve backend service %v: %v", key, err)
+	}
+	if len(bs.Backends) != 0 {
+		t.Errorf("Got %d backends in backend service %s, want none", len(bs.Backends), bs.Name)
+	}
+}
@@ -14,14 +14,16 @@ limitations under the License.
 package backends
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/ingress-gce/pkg/backends/features"
+	"k8s.io/ingress-gce/pkg/backends/metrics"
 	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/flags"
 	"k8s.io/ingress-gce/pkg/healthchecks"
 	lbfeatures "k8s.io/ingress-gce/pkg/loadbalancers/features"
 	"k8s.io/ingress-gce/pkg/utils"
@@ -35,6 +37,12 @@ type backendSyncer struct {
 	healthChecker healthchecks.HealthChecker
 	prober        ProbeProvider
 	cloud         *gce.Cloud
+	// securityPolicies manages the Cloud Armor policies created for
+	// BackendConfig rate limits.
+	securityPolicies features.SecurityPolicyClient
+	// cache remembers the backend services which are in sync, to avoid
+	// fetching them on every sync.
+	cache *backendServiceCache
 }
 
 // backendSyncer is a Syncer
@@ -44,10 +52,13 @@ func NewBackendSyncer(
 	backendPool Pool,
 	healthChecker healthchecks.HealthChecker,
 	cloud *gce.Cloud) Syncer {
+	metrics.RegisterMetrics()
 	return &backendSyncer{
-		backendPool:   backendPool,
-		healthChecker: healthChecker,
-		cloud:         cloud,
+		backendPool:      backendPool,
+		healthChecker:    healthChecker,
+		cloud:            cloud,
+		securityPolicies: features.NewSecurityPolicyClient(cloud),
+		cache:            newBackendServiceCache(flags.F.BackendServiceCacheVerifyPeriod),
 	}
 }
 
@@ -57,10 +68,10 @@ func (s *backendSyncer) Init(pp ProbeProvider) {
 }
 
 // Sync implements Syncer.
-func (s *backendSyncer) Sync(svcPorts []utils.ServicePort) error {
+func (s *backendSyncer) Sync(ctx context.Context, svcPorts []utils.ServicePort) error {
 	for _, sp := range svcPorts {
 		klog.V(3).Infof("Sync: backend %+v", sp)
-		if err := s.ensureBackendService(sp); err != nil {
+		if err := s.ensureBackendService(ctx, sp); err != nil {
 			return err
 		}
 	}
@@ -68,7 +79,7 @@ func (s *backendSyncer) Sync(svcPorts []utils.ServicePort) error {
 }
 
 // ensureBackendService will update or create a BackendService for the given port.
-func (s *backendSyncer) ensureBackendService(sp utils.ServicePort) error {
+func (s *backendSyncer) ensureBackendService(ctx context.Context, sp utils.ServicePort) error {
 	// We must track the ports even if creating the backends failed, because
 	// we might've created health-check for them.
 	be := &composite.BackendService{}
@@ -76,14 +87,24 @@ func (s *backendSyncer) ensureBackendService(sp utils.ServicePort) error {
 	version := features.VersionFromServicePort(&sp)
 	scope := features.ScopeFromServicePort(&sp)
 
-	be, getErr := s.backendPool.Get(beName, version, scope)
-
 	// Ensure health check for backend service exists.
-	hcLink, err := s.ensureHealthCheck(sp)
+	hcLink, err := s.ensureHealthCheck(ctx, sp)
 	if err != nil {
 		return fmt.Errorf("error ensuring health check: %w", err)
 	}
 
+	inSync, cached := s.cache.lookup(sp, hcLink)
+	if inSync {
+		klog.V(4).Infof("Backend service %v is in sync, skipping", beName)
+		return nil
+	}
+	be, getErr := s.backendPool.Get(ctx, beName, version, scope)
+	if cached != nil && getErr == nil {
+		s.cache.verify(sp, cached, be)
+	}
+	// The backend service is cached again only if this sync finds it in sync.
+	s.cache.invalidate(beName, scope)
+
 	// Verify existence of a backend service for the proper port
 	// but do not specify any backends for it (IG / NEG).
 	if getErr != nil {
@@ -92,12 +113,14 @@ func (s *backendSyncer) ensureBackendService(sp utils.ServicePort) error {
 		}
 		// Only create the backend service if the error was 404.
 		klog.V(2).Infof("Creating backend service for port %v named %v", sp.NodePort, beName)
-		be, err = s.backendPool.Create(sp, hcLink)
+		be, err = s.backendPool.Create(ctx, sp, hcLink)
 		if err != nil {
 			return err
 		}
 	}
+	created := getErr != nil
 
+	oldHCLink := getHealthCheckLink(be)
 	needUpdate := ensureProtocol(be, sp)
 	needUpdate = ensureHealthCheckLink(be, hcLink) || needUpdate
 	needUpdate = ensureDescription(be, &sp) || needUpdate
@@ -112,100 +135,194 @@ func (s *backendSyncer) ensureBackendService(sp utils.ServicePort) error {
 	}
 
 	if needUpdate {
-		if err := s.backendPool.Update(be); err != nil {
+		if err := s.backendPool.Update(ctx, be); err != nil {
+			return err
+		}
+	}
+	// The previous health check is only released once the backend service
+	// moved to the new one, so that switching to or from a shared health
+	// check is hitless.
+	if !utils.EqualResourceIDs(oldHCLink, hcLink) {
+		if err := s.releaseHealthCheck(ctx, beName, oldHCLink, scope); err != nil {
 			return err
 		}
 	}
 
+	oldSecurityPolicy := be.SecurityPolicy
+	// Scope is used to validate if cloud armor security policy feature is
+	// available. meta.Key is not needed as security policy supported only for
+	// global backends.
+	be.Scope = scope
+	// The rate limit policy is reconciled first so that a managed policy
+	// being replaced by a user provided one is released beforehand. It is
+	// also reconciled without a BackendConfig, so that a managed policy is
+	// released once the BackendConfig is removed from the service.
+	if err := features.EnsureRateLimit(ctx, s.cloud, s.securityPolicies, sp, be); err != nil {
+		return err
+	}
 	if sp.BackendConfig != nil {
-		// Scope is used to validate if cloud armor security policy feature is
-		// available. meta.Key is not needed as security policy supported only for
-		// global backends.
-		be.Scope = scope
-		if err := features.EnsureSecurityPolicy(s.cloud, sp, be); err != nil {
+		if err := features.EnsureSecurityPolicy(ctx, s.cloud, sp, be); err != nil {
 			return err
 		}
 	}
 
+	// Backend services which were modified are fetched again on the next
+	// sync, as their fingerprint changed.
+	if !created && !needUpdate && be.SecurityPolicy == oldSecurityPolicy {
+		s.cache.add(sp, hcLink, be)
+	}
 	return nil
 }
 
+// Fingerprints implements Syncer.
+func (s *backendSyncer) Fingerprints() []Fingerprint {
+	return s.cache.fingerprints()
+}
+
+// RestoreFingerprints implements Syncer.
+func (s *backendSyncer) RestoreFingerprints(fingerprints []Fingerprint) {
+	s.cache.restore(fingerprints)
+}
+
 // GC implements Syncer.
-func (s *backendSyncer) GC(svcPorts []utils.ServicePort) error {
+func (s *backendSyncer) GC(ctx context.Context, svcPorts []utils.ServicePort) error {
 	knownPorts, err := knownPortsFromServicePorts(s.cloud, svcPorts)
 	if err != nil {
 		return err
 	}
+	backends, err := s.listGCCandidates(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.gc(ctx, backends, knownPorts); err != nil {
+		return fmt.Errorf("error GCing Backends: %w", err)
+	}
+	return nil
+}
 
-	// TODO(shance): Refactor out empty key field
-	key, err := composite.CreateKey(s.cloud, "", meta.Regional)
+// ExplainGC implements Syncer.
+func (s *backendSyncer) ExplainGC(ctx context.Context, svcPorts []utils.ServicePort) ([]utils.GCDecision, error) {
+	knownPorts, err := knownPortsFromServicePorts(s.cloud, svcPorts)
 	if err != nil {
-		return fmt.Errorf("error creating l7 ilb key: %w", err)
+		return nil, err
+	}
+	backends, err := s.listGCCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var decisions []utils.GCDecision
+	for _, be := range backends {
+		decision, _, err := s.gcDecision(be, knownPorts)
+		if err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, decision)
 	}
-	ilbBackends, err := s.backendPool.List(key, lbfeatures.L7ILBVersions().BackendService)
+	return decisions, nil
+}
+
+// listGCCandidates returns the regional and global backend services of the
+// project.
+func (s *backendSyncer) listGCCandidates(ctx context.Context) ([]*composite.BackendService, error) {
+	// TODO(shance): Refactor out empty key field
+	key, err := composite.CreateKey(s.cloud, "", meta.Regional)
 	if err != nil {
-		return fmt.Errorf("error listing regional backends: %w", err)
+		return nil, fmt.Errorf("error creating l7 ilb key: %w", err)
 	}
-	err = s.gc(ilbBackends, knownPorts)
+	ilbBackends, err := s.backendPool.List(ctx, key, lbfeatures.L7ILBVersions().BackendService)
 	if err != nil {
-		return fmt.Errorf("error GCing regional Backends: %w", err)
+		return nil, fmt.Errorf("error listing regional backends: %w", err)
 	}
 
 	// Requires an empty name field until it is refactored out
 	key, err = composite.CreateKey(s.cloud, "", meta.Global)
 	if err != nil {
-		return fmt.Errorf("error creating l7 ilb key: %w", err)
+		return nil, fmt.Errorf("error creating l7 ilb key: %w", err)
 	}
-	backends, err := s.backendPool.List(key, meta.VersionGA)
+	backends, err := s.backendPool.List(ctx, key, meta.VersionGA)
 	if err != nil {
-		return fmt.Errorf("error listing backends: %w", err)
+		return nil, fmt.Errorf("error listing backends: %w", err)
+	}
+	return append(ilbBackends, backends...), nil
+}
+
+// gcDecision decides whether be is garbage collected given the known service
+// ports, and returns its scope.
+func (s *backendSyncer) gcDecision(be *composite.BackendService, knownPorts map[string]utils.ServicePort) (utils.GCDecision, meta.KeyType, error) {
+	decision := utils.GCDecision{Kind: "BackendService", Name: be.Name}
+	scope, err := composite.ScopeFromSelfLink(be.SelfLink)
+	if err != nil {
+		return decision, scope, err
 	}
-	err = s.gc(backends, knownPorts)
+	decision.Scope = string(scope)
+	// Skip L4 LB backend services
+	// backendSyncer currently only GC backend services for L7 XLB/ILB.
+	// L4 LB is GC as part of the deletion flow as there is no shared backend services among L4 ILBs.
+	if strings.Contains(be.Description, utils.L4ILBServiceDescKey) {
+		decision.Evidence = "backend service of an L4 LoadBalancer, deleted with its Service"
+		return decision, scope, nil
+	}
+	key, err := composite.CreateKey(s.cloud, be.Name, scope)
 	if err != nil {
-		return fmt.Errorf("error GCing Backends: %w", err)
+		return decision, scope, err
 	}
-
-	return nil
+	if sp, ok := knownPorts[key.String()]; ok {
+		decision.Evidence = fmt.Sprintf("backend of service port %v of an Ingress", sp.ID)
+		return decision, scope, nil
+	}
+	decision.Evidence = "not the backend of any service port of an Ingress"
+	if owner := utils.DescriptionFromString(be.Description).Owner(); owner != "" {
+		decision.Evidence = fmt.Sprintf("owner %s is not the backend of any service port of an Ingress", owner)
+	}
+	decision.Delete = true
+	return decision, scope, nil
 }
 
-// gc deletes the provided backends
-func (s *backendSyncer) gc(backends []*composite.BackendService, knownPorts sets.String) error {
+// gc deletes the provided backends which are not the backend of a known port.
+func (s *backendSyncer) gc(ctx context.Context, backends []*composite.BackendService, knownPorts map[string]utils.ServicePort) error {
 	for _, be := range backends {
-		// Skip L4 LB backend services
-		// backendSyncer currently only GC backend services for L7 XLB/ILB.
-		// L4 LB is GC as part of the deletion flow as there is no shared backend services among L4 ILBs.
-		if strings.Contains(be.Description, utils.L4ILBServiceDescKey) {
-			continue
-		}
-		var key *meta.Key
-		name := be.Name
-		scope, err := composite.ScopeFromSelfLink(be.SelfLink)
+		decision, scope, err := s.gcDecision(be, knownPorts)
 		if err != nil {
 			return err
 		}
-		if key, err = composite.CreateKey(s.cloud, name, scope); err != nil {
-			return err
-		}
-		if knownPorts.Has(key.String()) {
+		klog.V(4).Infof("GC decision: %v", decision)
+		if !decision.Delete {
 			continue
 		}
+		name := be.Name
 		klog.V(2).Infof("GCing backendService for port %s", name)
-		err = s.backendPool.Delete(name, be.Version, scope)
+		s.cache.invalidate(name, scope)
+		err = s.backendPool.Delete(ctx, name, be.Version, scope)
 		if err != nil {
 			klog.Errorf("backendPool.Delete(%v, %v, %v) = %v", name, be.Version, scope, err)
 			return err
 		}
 
-		if err := s.healthChecker.Delete(name, scope); err != nil {
+		if err := s.healthChecker.Delete(ctx, name, scope); err != nil {
 			return err
 		}
+		// Release the shared health check of the backend service, if any.
+		if hcName, err := utils.KeyName(getHealthCheckLink(be)); err == nil && hcName != name {
+			if err := s.releaseSharedHealthCheck(ctx, name, hcName); err != nil {
+				return err
+			}
+		}
+
+		// Rate limit policies are named after the backend service they protect.
+		if scope == meta.Global && be.SecurityPolicy != "" {
+			if policyName, err := utils.KeyName(be.SecurityPolicy); err == nil && policyName == name {
+				if err := features.DeleteRateLimitPolicy(ctx, s.securityPolicies, policyName); err != nil {
+					return err
+				}
+			}
+		}
 	}
 	return nil
 }
 
 // TODO: (shance) add unit tests
-func knownPortsFromServicePorts(cloud *gce.Cloud, svcPorts []utils.ServicePort) (sets.String, error) {
-	knownPorts := sets.NewString()
+func knownPortsFromServicePorts(cloud *gce.Cloud, svcPorts []utils.ServicePort) (map[string]utils.ServicePort, error) {
+	knownPorts := map[string]utils.ServicePort{}
 
 	for _, sp := range svcPorts {
 		name := sp.BackendName()
@@ -213,7 +330,7 @@ func knownPortsFromServicePorts(cloud *gce.Cloud, svcPorts []utils.ServicePort)
 		if err != nil {
 			return nil, err
 		}
-		knownPorts.Insert(key.String())
+		knownPorts[key.String()] = sp
 
 	}
 
@@ -221,19 +338,19 @@ func knownPortsFromServicePorts(cloud *gce.Cloud, svcPorts []utils.ServicePort)
 }
 
 // Status implements Syncer.
-func (s *backendSyncer) Status(name string, version meta.Version, scope meta.KeyType) (string, error) {
-	return s.backendPool.Health(name, version, scope)
+func (s *backendSyncer) Status(ctx context.Context, name string, version meta.Version, scope meta.KeyType) (string, error) {
+	return s.backendPool.Health(ctx, name, version, scope)
 }
 
 // Shutdown implements Syncer.
-func (s *backendSyncer) Shutdown() error {
-	if err := s.GC([]utils.ServicePort{}); err != nil {
+func (s *backendSyncer) Shutdown(ctx context.Context) error {
+	if err := s.GC(ctx, []utils.ServicePort{}); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (s *backendSyncer) ensureHealthCheck(sp utils.ServicePort) (string, error) {
+func (s *backendSyncer) ensureHealthCheck(ctx context.Context, sp utils.ServicePort) (string, error) {
 	var probe *v1.Probe
 	var err error
 
@@ -243,7 +360,49 @@ func (s *backendSyncer) ensureHealthCheck(sp utils.ServicePort) (string, error)
 			return "", fmt.Errorf("Error getting prober: %w", err)
 		}
 	}
-	return s.healthChecker.SyncServicePort(&sp, probe)
+	return s.healthChecker.SyncServicePort(ctx, &sp, probe)
+}
+
+// releaseHealthCheck releases the health check of the given link, which the
+// backend service no longer uses. Dedicated health checks are named after
+// their backend service and are deleted, any other health check is shared.
+func (s *backendSyncer) releaseHealthCheck(ctx context.Context, beName, hcLink string, scope meta.KeyType) error {
+	hcName, err := utils.KeyName(hcLink)
+	if err != nil {
+		// The backend service had no valid health check.
+		return nil
+	}
+	if hcName == beName {
+		return s.healthChecker.Delete(ctx, hcName, scope)
+	}
+	return s.releaseSharedHealthCheck(ctx, beName, hcName)
+}
+
+// releaseSharedHealthCheck deletes the shared health check hcName, which the
+// backend service beName no longer uses, unless another backend service of
+// the cluster still uses it. The users are derived from the backend services
+// in GCE rather than tracked in memory, so that they survive restarts of the
+// controller.
+func (s *backendSyncer) releaseSharedHealthCheck(ctx context.Context, beName, hcName string) error {
+	// Requires an empty name field until it is refactored out
+	key, err := composite.CreateKey(s.cloud, "", meta.Global)
+	if err != nil {
+		return err
+	}
+	backends, err := s.backendPool.List(ctx, key, meta.VersionGA)
+	if err != nil {
+		return fmt.Errorf("error listing backends: %w", err)
+	}
+	for _, be := range backends {
+		if be.Name == beName {
+			continue
+		}
+		if name, err := utils.KeyName(getHealthCheckLink(be)); err == nil && name == hcName {
+			klog.V(3).Infof("Shared health check %q is still used by backend service %q", hcName, be.Name)
+			return nil
+		}
+	}
+	return s.healthChecker.Delete(ctx, hcName, meta.Global)
 }
 
 // getHealthCheckLink gets the Healthcheck link off the BackendService
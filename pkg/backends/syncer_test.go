@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
@@ -34,9 +35,14 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/ingress-gce/pkg/annotations"
+	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
 	"k8s.io/ingress-gce/pkg/backends/features"
 	"k8s.io/ingress-gce/pkg/composite"
+	compositemetrics "k8s.io/ingress-gce/pkg/composite/metrics"
+	"k8s.io/ingress-gce/pkg/events"
+	"k8s.io/ingress-gce/pkg/flags"
 	"k8s.io/ingress-gce/pkg/healthchecks"
+	"k8s.io/ingress-gce/pkg/test"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/ingress-gce/pkg/utils/namer"
 	"k8s.io/legacy-cloud-providers/gce"
@@ -102,11 +108,11 @@ func (p *portset) check(fakeGCE *gce.Cloud) error {
 		}
 
 		if found {
-			if _, err := composite.GetBackendService(fakeGCE, key, features.VersionFromServicePort(&sp)); err != nil {
+			if _, err := composite.GetBackendService(context.TODO(), fakeGCE, key, features.VersionFromServicePort(&sp)); err != nil {
 				return fmt.Errorf("backend for port %+v should exist, but got: %v", sp.NodePort, err)
 			}
 		} else {
-			bs, err := composite.GetBackendService(fakeGCE, key, features.VersionFromServicePort(&sp))
+			bs, err := composite.GetBackendService(context.TODO(), fakeGCE, key, features.VersionFromServicePort(&sp))
 			if err == nil || !utils.IsHTTPErrorCode(err, http.StatusNotFound) {
 				if sp.VMIPNEGEnabled {
 					// It is expected that these Backends should not get cleaned up in the GC loop.
@@ -137,14 +143,15 @@ var (
 )
 
 func newTestSyncer(fakeGCE *gce.Cloud) *backendSyncer {
-	fakeHealthChecks := healthchecks.NewHealthChecker(fakeGCE, "/", defaultBackendSvc)
+	fakeHealthChecks := healthchecks.NewHealthChecker(fakeGCE, "/", defaultBackendSvc, events.RecorderProducerMock{})
 
 	fakeBackendPool := NewPool(fakeGCE, defaultNamer)
 
 	syncer := &backendSyncer{
-		backendPool:   fakeBackendPool,
-		healthChecker: fakeHealthChecks,
-		cloud:         fakeGCE,
+		backendPool:      fakeBackendPool,
+		healthChecker:    fakeHealthChecks,
+		cloud:            fakeGCE,
+		securityPolicies: features.NewFakeSecurityPolicyClient(),
 	}
 
 	probes := map[utils.ServicePort]*api_v1.Probe{{NodePort: 443, Protocol: annotations.ProtocolHTTPS, BackendNamer: defaultNamer}: existingProbe}
@@ -175,13 +182,13 @@ func TestSync(t *testing.T) {
 
 	for _, sp := range testCases {
 		t.Run(fmt.Sprintf("Port: %v Protocol: %v", sp.NodePort, sp.Protocol), func(t *testing.T) {
-			if err := syncer.Sync([]utils.ServicePort{sp}); err != nil {
+			if err := syncer.Sync(context.TODO(), []utils.ServicePort{sp}); err != nil {
 				t.Fatalf("Unexpected error when syncing backend with port %v: %v", sp.NodePort, err)
 			}
 			beName := sp.BackendName()
 
 			// Check that the new backend has the right port
-			be, err := syncer.backendPool.Get(beName, features.VersionFromServicePort(&sp), features.ScopeFromServicePort(&sp))
+			be, err := syncer.backendPool.Get(context.TODO(), beName, features.VersionFromServicePort(&sp), features.ScopeFromServicePort(&sp))
 			if err != nil {
 				t.Fatalf("Did not find expected backend with port %v", sp.NodePort)
 			}
@@ -189,7 +196,7 @@ func TestSync(t *testing.T) {
 				t.Fatalf("Backend %v has wrong port %v, expected %v", be.Name, be.Port, sp)
 			}
 
-			hc, err := syncer.healthChecker.Get(beName, features.VersionFromServicePort(&sp), features.ScopeFromServicePort(&sp))
+			hc, err := syncer.healthChecker.Get(context.TODO(), beName, features.VersionFromServicePort(&sp), features.ScopeFromServicePort(&sp))
 			if err != nil {
 				t.Fatalf("Unexpected err when querying fake healthchecker: %v", err)
 			}
@@ -210,10 +217,10 @@ func TestSyncUpdateHTTPS(t *testing.T) {
 	syncer := newTestSyncer(fakeGCE)
 
 	p := utils.ServicePort{NodePort: 3000, Protocol: annotations.ProtocolHTTP, BackendNamer: defaultNamer}
-	syncer.Sync([]utils.ServicePort{p})
+	syncer.Sync(context.TODO(), []utils.ServicePort{p})
 	beName := p.BackendName()
 
-	be, err := syncer.backendPool.Get(beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
+	be, err := syncer.backendPool.Get(context.TODO(), beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
 	if err != nil {
 		t.Fatalf("Unexpected err: %v", err)
 	}
@@ -223,16 +230,16 @@ func TestSyncUpdateHTTPS(t *testing.T) {
 	}
 
 	// Assert the proper health check was created
-	hc, _ := syncer.healthChecker.Get(beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
+	hc, _ := syncer.healthChecker.Get(context.TODO(), beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
 	if hc == nil || hc.Protocol() != p.Protocol {
 		t.Fatalf("Expected %s health check, received %v: ", p.Protocol, hc)
 	}
 
 	// Update service port to encrypted
 	p.Protocol = annotations.ProtocolHTTPS
-	syncer.Sync([]utils.ServicePort{p})
+	syncer.Sync(context.TODO(), []utils.ServicePort{p})
 
-	be, err = syncer.backendPool.Get(beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
+	be, err = syncer.backendPool.Get(context.TODO(), beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
 	if err != nil {
 		t.Fatalf("Unexpected err retrieving backend service after update: %v", err)
 	}
@@ -243,7 +250,7 @@ func TestSyncUpdateHTTPS(t *testing.T) {
 	}
 
 	// Assert the proper health check was created
-	hc, _ = syncer.healthChecker.Get(beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
+	hc, _ = syncer.healthChecker.Get(context.TODO(), beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
 	if hc == nil || hc.Protocol() != p.Protocol {
 		t.Fatalf("Expected %s health check, received %v: ", p.Protocol, hc)
 	}
@@ -254,10 +261,10 @@ func TestSyncUpdateHTTP2(t *testing.T) {
 	syncer := newTestSyncer(fakeGCE)
 
 	p := utils.ServicePort{NodePort: 3000, Protocol: annotations.ProtocolHTTP, BackendNamer: defaultNamer}
-	syncer.Sync([]utils.ServicePort{p})
+	syncer.Sync(context.TODO(), []utils.ServicePort{p})
 	beName := p.BackendName()
 
-	be, err := syncer.backendPool.Get(beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
+	be, err := syncer.backendPool.Get(context.TODO(), beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
 	if err != nil {
 		t.Fatalf("Unexpected err: %v", err)
 	}
@@ -267,16 +274,16 @@ func TestSyncUpdateHTTP2(t *testing.T) {
 	}
 
 	// Assert the proper health check was created
-	hc, _ := syncer.healthChecker.Get(beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
+	hc, _ := syncer.healthChecker.Get(context.TODO(), beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
 	if hc == nil || hc.Protocol() != p.Protocol {
 		t.Fatalf("Expected %s health check, received %v: ", p.Protocol, hc)
 	}
 
 	// Update service port to HTTP2
 	p.Protocol = annotations.ProtocolHTTP2
-	syncer.Sync([]utils.ServicePort{p})
+	syncer.Sync(context.TODO(), []utils.ServicePort{p})
 
-	beBeta, err := syncer.backendPool.Get(beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
+	beBeta, err := syncer.backendPool.Get(context.TODO(), beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
 	if err != nil {
 		t.Fatalf("Unexpected err retrieving backend service after update: %v", err)
 	}
@@ -287,7 +294,7 @@ func TestSyncUpdateHTTP2(t *testing.T) {
 	}
 
 	// Assert the proper health check was created
-	hc, _ = syncer.healthChecker.Get(beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
+	hc, _ = syncer.healthChecker.Get(context.TODO(), beName, features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
 	if hc == nil || hc.Protocol() != p.Protocol {
 		t.Fatalf("Expected %s health check, received %v: ", p.Protocol, hc)
 	}
@@ -308,7 +315,7 @@ func TestGC(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := syncer.Sync(ps.existingPorts()); err != nil {
+	if err := syncer.Sync(context.TODO(), ps.existingPorts()); err != nil {
 		t.Fatalf("syncer.Sync(%+v) = %v, want nil ", ps.existingPorts(), err)
 	}
 
@@ -317,7 +324,7 @@ func TestGC(t *testing.T) {
 	}
 
 	// Run a no-op GC (i.e nothing is actually cleaned up)
-	if err := syncer.GC(ps.existingPorts()); err != nil {
+	if err := syncer.GC(context.TODO(), ps.existingPorts()); err != nil {
 		t.Fatalf("syncer.GC(%+v) = %v, want nil", ps.existingPorts(), err)
 	}
 
@@ -330,7 +337,7 @@ func TestGC(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := syncer.GC(ps.existingPorts()); err != nil {
+	if err := syncer.GC(context.TODO(), ps.existingPorts()); err != nil {
 		t.Fatalf("syncer.GC(%+v) = %v, want nil", ps.existingPorts(), err)
 	}
 
@@ -339,6 +346,82 @@ func TestGC(t *testing.T) {
 	}
 }
 
+// TestSyncAPICallBudget guards against syncs whose number of GCE API calls
+// grows faster than the number of backends.
+func TestSyncAPICallBudget(t *testing.T) {
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	syncer := newTestSyncer(fakeGCE)
+	syncer.cache = newBackendServiceCache(time.Hour)
+
+	const numPorts = 10
+	var svcPorts []utils.ServicePort
+	for i := 0; i < numPorts; i++ {
+		svcPorts = append(svcPorts, utils.ServicePort{NodePort: int64(30000 + i), Protocol: annotations.ProtocolHTTP, BackendNamer: defaultNamer})
+	}
+	sync := func() {
+		if err := syncer.Sync(context.TODO(), svcPorts); err != nil {
+			t.Fatalf("syncer.Sync(%v) = %v", svcPorts, err)
+		}
+	}
+
+	// Each backend service is looked up, which fails with NotFound, then
+	// created and fetched again.
+	test.ExpectAPICallBudget(t, compositemetrics.Budget{
+		"BackendService_create":     numPorts,
+		"BackendService_get":        2 * numPorts,
+		compositemetrics.AnyRequest: 3 * numPorts,
+	}, sync)
+	// Created backend services are fetched once more before they are cached.
+	calls := test.ExpectAPICallBudget(t, compositemetrics.Budget{
+		"BackendService_get":        numPorts,
+		compositemetrics.AnyRequest: numPorts,
+	}, sync)
+	if got := calls["BackendService_get"]; got != numPorts {
+		t.Errorf("BackendService_get calls = %d, want %d", got, numPorts)
+	}
+	// Unchanged backend services are then served from the cache.
+	test.ExpectAPICallBudget(t, compositemetrics.Budget{compositemetrics.AnyRequest: 0}, sync)
+}
+
+// Test that ExplainGC reports the backends GC would delete without deleting them.
+func TestExplainGC(t *testing.T) {
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	syncer := newTestSyncer(fakeGCE)
+
+	svcNodePorts := []utils.ServicePort{
+		{NodePort: 81, Protocol: annotations.ProtocolHTTP, BackendNamer: defaultNamer},
+		{NodePort: 82, Protocol: annotations.ProtocolHTTPS, BackendNamer: defaultNamer},
+	}
+	ps := newPortset(svcNodePorts)
+	if err := ps.add(svcNodePorts); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncer.Sync(context.TODO(), ps.existingPorts()); err != nil {
+		t.Fatalf("syncer.Sync(%+v) = %v, want nil ", ps.existingPorts(), err)
+	}
+
+	decisions, err := syncer.ExplainGC(context.TODO(), svcNodePorts[:1])
+	if err != nil {
+		t.Fatalf("syncer.ExplainGC(%+v) = %v, want nil", svcNodePorts[:1], err)
+	}
+	want := map[string]bool{
+		svcNodePorts[0].BackendName(): false,
+		svcNodePorts[1].BackendName(): true,
+	}
+	got := map[string]bool{}
+	for _, d := range decisions {
+		got[d.Name] = d.Delete
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("syncer.ExplainGC(%+v) decisions = %v, want %v", svcNodePorts[:1], decisions, want)
+	}
+
+	// Check that nothing was deleted
+	if err := ps.check(fakeGCE); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // Test GC with both ELB and ILBs. Add in an L4 ILB NEG which should not be deleted as part of GC.
 func TestGCMixed(t *testing.T) {
 	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
@@ -358,7 +441,7 @@ func TestGCMixed(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := syncer.Sync(ps.existingPorts()); err != nil {
+	if err := syncer.Sync(context.TODO(), ps.existingPorts()); err != nil {
 		t.Fatalf("syncer.Sync(%+v) = %v, want nil ", ps.existingPorts(), err)
 	}
 
@@ -367,7 +450,7 @@ func TestGCMixed(t *testing.T) {
 	}
 
 	// Run a no-op GC (i.e nothing is actually cleaned up)
-	if err := syncer.GC(ps.existingPorts()); err != nil {
+	if err := syncer.GC(context.TODO(), ps.existingPorts()); err != nil {
 		t.Fatalf("syncer.GC(%+v) = %v, want nil", ps.existingPorts(), err)
 	}
 
@@ -380,7 +463,7 @@ func TestGCMixed(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := syncer.GC(ps.existingPorts()); err != nil {
+	if err := syncer.GC(context.TODO(), ps.existingPorts()); err != nil {
 		t.Fatalf("syncer.GC(%+v) = %v, want nil", ps.existingPorts(), err)
 	}
 
@@ -486,19 +569,19 @@ func TestSyncQuota(t *testing.T) {
 				return false, nil
 			}
 
-			if err := syncer.Sync(tc.oldPorts); err != nil {
+			if err := syncer.Sync(context.TODO(), tc.oldPorts); err != nil {
 				t.Errorf("Expected backend pool to add node ports, err: %v", err)
 			}
 
 			// Ensuring these ports again without first Garbage Collecting goes over
 			// the set quota. Expect an error here, until GC is called.
-			err := syncer.Sync(tc.newPorts)
+			err := syncer.Sync(context.TODO(), tc.newPorts)
 			if tc.expectSyncErr && err == nil {
 				t.Errorf("Expect initial sync to go over quota, but received no error")
 			}
 
-			syncer.GC(tc.newPorts)
-			if err := syncer.Sync(tc.newPorts); err != nil {
+			syncer.GC(context.TODO(), tc.newPorts)
+			if err := syncer.Sync(context.TODO(), tc.newPorts); err != nil {
 				t.Errorf("Expected backend pool to add node ports, err: %v", err)
 			}
 
@@ -509,6 +592,43 @@ func TestSyncQuota(t *testing.T) {
 	}
 }
 
+func TestSyncDefaultTimeout(t *testing.T) {
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	syncer := newTestSyncer(fakeGCE)
+
+	sp := utils.ServicePort{NodePort: 80, Protocol: annotations.ProtocolHTTP, BackendNamer: defaultNamer, DefaultTimeoutSec: 600}
+	if err := syncer.Sync(context.TODO(), []utils.ServicePort{sp}); err != nil {
+		t.Fatalf("syncer.Sync(%+v) = %v", sp, err)
+	}
+	be, err := fakeGCE.GetGlobalBackendService(sp.BackendName())
+	if err != nil {
+		t.Fatalf("GetGlobalBackendService(%s) = %v", sp.BackendName(), err)
+	}
+	if be.TimeoutSec != 600 {
+		t.Errorf("TimeoutSec = %d, want 600", be.TimeoutSec)
+	}
+
+	// The default only applies to new backend services.
+	sp.DefaultTimeoutSec = 900
+	if err := syncer.Sync(context.TODO(), []utils.ServicePort{sp}); err != nil {
+		t.Fatalf("syncer.Sync(%+v) = %v", sp, err)
+	}
+	if be, _ := fakeGCE.GetGlobalBackendService(sp.BackendName()); be.TimeoutSec != 600 {
+		t.Errorf("TimeoutSec = %d after changing the default, want 600", be.TimeoutSec)
+	}
+
+	// The BackendConfig timeout takes precedence.
+	timeout := int64(45)
+	sp = utils.ServicePort{NodePort: 81, Protocol: annotations.ProtocolHTTP, BackendNamer: defaultNamer, DefaultTimeoutSec: 600,
+		BackendConfig: &backendconfigv1.BackendConfig{Spec: backendconfigv1.BackendConfigSpec{TimeoutSec: &timeout}}}
+	if err := syncer.Sync(context.TODO(), []utils.ServicePort{sp}); err != nil {
+		t.Fatalf("syncer.Sync(%+v) = %v", sp, err)
+	}
+	if be, _ := fakeGCE.GetGlobalBackendService(sp.BackendName()); be.TimeoutSec != timeout {
+		t.Errorf("TimeoutSec = %d, want %d from the BackendConfig", be.TimeoutSec, timeout)
+	}
+}
+
 func TestSyncNEG(t *testing.T) {
 	// Convert a BackendPool from non-NEG to NEG.
 	// Expect the old BackendServices to be GC'ed
@@ -516,7 +636,7 @@ func TestSyncNEG(t *testing.T) {
 	syncer := newTestSyncer(fakeGCE)
 
 	svcPort := utils.ServicePort{NodePort: 81, Protocol: annotations.ProtocolHTTP, BackendNamer: defaultNamer}
-	if err := syncer.Sync([]utils.ServicePort{svcPort}); err != nil {
+	if err := syncer.Sync(context.TODO(), []utils.ServicePort{svcPort}); err != nil {
 		t.Errorf("Expected backend pool to add node ports, err: %v", err)
 	}
 
@@ -528,7 +648,7 @@ func TestSyncNEG(t *testing.T) {
 
 	// Convert to NEG
 	svcPort.NEGEnabled = true
-	if err := syncer.Sync([]utils.ServicePort{svcPort}); err != nil {
+	if err := syncer.Sync(context.TODO(), []utils.ServicePort{svcPort}); err != nil {
 		t.Errorf("Expected backend pool to add node ports, err: %v", err)
 	}
 
@@ -538,20 +658,20 @@ func TestSyncNEG(t *testing.T) {
 		t.Fatalf("Failed to get backend service with name %v: %v", negName, err)
 	}
 	// GC should garbage collect the Backend on the old naming schema
-	syncer.GC([]utils.ServicePort{svcPort})
+	syncer.GC(context.TODO(), []utils.ServicePort{svcPort})
 
-	bs, err := syncer.backendPool.Get(nodePortName, features.VersionFromServicePort(&svcPort), features.ScopeFromServicePort(&svcPort))
+	bs, err := syncer.backendPool.Get(context.TODO(), nodePortName, features.VersionFromServicePort(&svcPort), features.ScopeFromServicePort(&svcPort))
 	if err == nil {
 		t.Fatalf("Expected not to get BackendService with name %v, got: %+v", nodePortName, bs)
 	}
 
 	// Convert back to non-NEG
 	svcPort.NEGEnabled = false
-	if err := syncer.Sync([]utils.ServicePort{svcPort}); err != nil {
+	if err := syncer.Sync(context.TODO(), []utils.ServicePort{svcPort}); err != nil {
 		t.Errorf("Expected backend pool to add node ports, err: %v", err)
 	}
 
-	syncer.GC([]utils.ServicePort{svcPort})
+	syncer.GC(context.TODO(), []utils.ServicePort{svcPort})
 
 	_, err = fakeGCE.GetGlobalBackendService(nodePortName)
 	if err != nil {
@@ -559,13 +679,296 @@ func TestSyncNEG(t *testing.T) {
 	}
 }
 
+func TestSyncSharedHealthChecks(t *testing.T) {
+	oldFlag := flags.F.EnableSharedHealthChecks
+	defer func() { flags.F.EnableSharedHealthChecks = oldFlag }()
+
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	syncer := newTestSyncer(fakeGCE)
+
+	newNEGPort := func(name string) utils.ServicePort {
+		return utils.ServicePort{
+			ID:           utils.ServicePortID{Service: types.NamespacedName{Namespace: "ns", Name: name}},
+			Port:         80,
+			NodePort:     30000,
+			Protocol:     annotations.ProtocolHTTP,
+			NEGEnabled:   true,
+			BackendNamer: defaultNamer,
+		}
+	}
+	spA, spB := newNEGPort("svc-a"), newNEGPort("svc-b")
+	hcName := func(sp utils.ServicePort) string {
+		t.Helper()
+		be, err := syncer.backendPool.Get(context.TODO(), sp.BackendName(), features.VersionFromServicePort(&sp), features.ScopeFromServicePort(&sp))
+		if err != nil {
+			t.Fatalf("backendPool.Get(%q) = %v", sp.BackendName(), err)
+		}
+		name, err := utils.KeyName(getHealthCheckLink(be))
+		if err != nil {
+			t.Fatalf("Invalid health check link on backend service %q: %v", be.Name, err)
+		}
+		return name
+	}
+	hcExists := func(name string) bool {
+		_, err := syncer.healthChecker.Get(context.TODO(), name, meta.VersionBeta, meta.Global)
+		return err == nil
+	}
+
+	// Start with a dedicated health check.
+	flags.F.EnableSharedHealthChecks = false
+	if err := syncer.Sync(context.TODO(), []utils.ServicePort{spA}); err != nil {
+		t.Fatalf("syncer.Sync() = %v", err)
+	}
+	if got := hcName(spA); got != spA.BackendName() {
+		t.Fatalf("Backend service %q uses health check %q, want dedicated health check", spA.BackendName(), got)
+	}
+
+	// Both backend services move to a single shared health check and the
+	// dedicated health check is deleted.
+	flags.F.EnableSharedHealthChecks = true
+	if err := syncer.Sync(context.TODO(), []utils.ServicePort{spA, spB}); err != nil {
+		t.Fatalf("syncer.Sync() = %v", err)
+	}
+	shared := hcName(spA)
+	if got := hcName(spB); got != shared || shared == spA.BackendName() {
+		t.Fatalf("Backend services use health checks %q and %q, want a single shared health check", shared, got)
+	}
+	if hcExists(spA.BackendName()) {
+		t.Errorf("Dedicated health check %q exists, want deleted", spA.BackendName())
+	}
+
+	// The shared health check is kept while a backend service uses it, also
+	// after a restart of the controller.
+	syncer = newTestSyncer(fakeGCE)
+	if err := syncer.GC(context.TODO(), []utils.ServicePort{spB}); err != nil {
+		t.Fatalf("syncer.GC() = %v", err)
+	}
+	if !hcExists(shared) {
+		t.Fatalf("Shared health check %q was deleted while still in use", shared)
+	}
+
+	// Disabling sharing moves the backend service back to a dedicated health
+	// check and releases the last reference to the shared one.
+	flags.F.EnableSharedHealthChecks = false
+	if err := syncer.Sync(context.TODO(), []utils.ServicePort{spB}); err != nil {
+		t.Fatalf("syncer.Sync() = %v", err)
+	}
+	if got := hcName(spB); got != spB.BackendName() {
+		t.Errorf("Backend service %q uses health check %q, want dedicated health check", spB.BackendName(), got)
+	}
+	if hcExists(shared) {
+		t.Errorf("Shared health check %q exists, want deleted", shared)
+	}
+}
+
+func TestSyncBackendServiceCache(t *testing.T) {
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	mockGCE := fakeGCE.Compute().(*cloud.MockGCE)
+	mockGCE.MockBackendServices.UpdateHook = mock.UpdateBackendServiceHook
+	gets := 0
+	mockGCE.MockBackendServices.GetHook = func(ctx context.Context, key *meta.Key, m *cloud.MockBackendServices) (bool, *compute.BackendService, error) {
+		gets++
+		return false, nil, nil
+	}
+
+	syncer := newTestSyncer(fakeGCE)
+	now := time.Now()
+	syncer.cache = newBackendServiceCache(time.Minute)
+	syncer.cache.now = func() time.Time { return now }
+
+	sp := utils.ServicePort{NodePort: 80, Protocol: annotations.ProtocolHTTP, BackendNamer: defaultNamer}
+	beName := sp.BackendName()
+	sync := func(sp utils.ServicePort, wantGets int) {
+		t.Helper()
+		if err := syncer.Sync(context.TODO(), []utils.ServicePort{sp}); err != nil {
+			t.Fatalf("syncer.Sync(%v/%v) = %v", sp.NodePort, sp.Protocol, err)
+		}
+		if gets != wantGets {
+			t.Errorf("Sync(%v/%v) fetched backend services %d times, want %d", sp.NodePort, sp.Protocol, gets, wantGets)
+		}
+	}
+
+	// The created backend service is only cached once a sync found it in sync.
+	// Creating and updating backend services fetches them again.
+	sync(sp, 2)
+	sync(sp, 3)
+	sync(sp, 3)
+
+	// Changes outside of the controller are only noticed on verification.
+	be, err := syncer.backendPool.Get(context.TODO(), beName, meta.VersionGA, meta.Global)
+	if err != nil {
+		t.Fatalf("backendPool.Get(%q) = %v", beName, err)
+	}
+	be.Protocol = string(annotations.ProtocolHTTPS)
+	if err := syncer.backendPool.Update(context.TODO(), be); err != nil {
+		t.Fatalf("backendPool.Update(%q) = %v", beName, err)
+	}
+	gets = 0
+	sync(sp, 0)
+	if be, _ = syncer.backendPool.Get(context.TODO(), beName, meta.VersionGA, meta.Global); be.Protocol != string(annotations.ProtocolHTTPS) {
+		t.Errorf("Protocol of cached backend service = %q, want unchanged %q", be.Protocol, annotations.ProtocolHTTPS)
+	}
+	gets = 0
+	now = now.Add(time.Minute)
+	sync(sp, 2)
+	if be, _ = syncer.backendPool.Get(context.TODO(), beName, meta.VersionGA, meta.Global); be.Protocol != string(sp.Protocol) {
+		t.Errorf("Protocol of verified backend service = %q, want %q", be.Protocol, sp.Protocol)
+	}
+	gets = 0
+	sync(sp, 1)
+	sync(sp, 1)
+
+	// Changed inputs are not served from the cache.
+	gets = 0
+	sp.Protocol = annotations.ProtocolHTTPS
+	sync(sp, 2)
+
+	if err := syncer.GC(context.TODO(), []utils.ServicePort{}); err != nil {
+		t.Fatalf("syncer.GC() = %v", err)
+	}
+	if len(syncer.cache.entries) != 0 {
+		t.Errorf("Cache has %d entries after GC, want 0", len(syncer.cache.entries))
+	}
+}
+
+func TestSyncBackendServiceCacheFeatures(t *testing.T) {
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	mockGCE := fakeGCE.Compute().(*cloud.MockGCE)
+	gets := 0
+	mockGCE.MockBackendServices.GetHook = func(ctx context.Context, key *meta.Key, m *cloud.MockBackendServices) (bool, *compute.BackendService, error) {
+		gets++
+		return false, nil, nil
+	}
+	mockGCE.MockBackendServices.SetSecurityPolicyHook = func(context.Context, *meta.Key, *compute.SecurityPolicyReference, *cloud.MockBackendServices) error {
+		return nil
+	}
+
+	syncer := newTestSyncer(fakeGCE)
+	syncer.cache = newBackendServiceCache(time.Minute)
+	now := time.Now()
+	syncer.cache.now = func() time.Time { return now }
+
+	sp := utils.ServicePort{NodePort: 80, Protocol: annotations.ProtocolHTTP, BackendNamer: defaultNamer}
+	beName := sp.BackendName()
+	sync := func(sp utils.ServicePort) {
+		t.Helper()
+		gets = 0
+		if err := syncer.Sync(context.TODO(), []utils.ServicePort{sp}); err != nil {
+			t.Fatalf("syncer.Sync() = %v", err)
+		}
+	}
+	sync(sp)
+	sync(sp)
+	sync(sp)
+	if gets != 0 {
+		t.Fatalf("Sync() fetched backend services %d times, want the backend service to be cached", gets)
+	}
+
+	// A change of the BackendConfig is synced.
+	timeoutSec := int64(42)
+	sp.BackendConfig = &backendconfigv1.BackendConfig{Spec: backendconfigv1.BackendConfigSpec{TimeoutSec: &timeoutSec}}
+	sync(sp)
+	if gets == 0 {
+		t.Errorf("Sync() did not fetch the backend service after its BackendConfig changed")
+	}
+	if be, _ := syncer.backendPool.Get(context.TODO(), beName, meta.VersionGA, meta.Global); be.TimeoutSec != timeoutSec {
+		t.Errorf("TimeoutSec of backend service = %d, want %d", be.TimeoutSec, timeoutSec)
+	}
+
+	// Backend services with a rate limit are never cached, so that their
+	// managed security policy is reconciled on every sync.
+	sp.BackendConfig = &backendconfigv1.BackendConfig{Spec: backendconfigv1.BackendConfigSpec{RateLimit: &backendconfigv1.RateLimitConfig{RequestsPerMinute: 100}}}
+	sync(sp)
+	sync(sp)
+	if gets == 0 {
+		t.Errorf("Sync() did not fetch the backend service with a rate limit")
+	}
+	if err := syncer.securityPolicies.Delete(context.TODO(), beName); err != nil {
+		t.Fatalf("securityPolicies.Delete(%q) = %v", beName, err)
+	}
+	sync(sp)
+	if _, err := syncer.securityPolicies.Get(context.TODO(), beName); err != nil {
+		t.Errorf("securityPolicies.Get(%q) = %v, want the managed policy to be created again", beName, err)
+	}
+}
+
+func TestBackendServiceCacheVerify(t *testing.T) {
+	c := newBackendServiceCache(time.Minute)
+	sp := utils.ServicePort{NodePort: 80, Protocol: annotations.ProtocolHTTP, BackendNamer: defaultNamer}
+	be := &composite.BackendService{Name: sp.BackendName(), Protocol: "HTTP"}
+	c.add(sp, "hc", be)
+	entry := c.entries[newBackendCacheKey(&sp)]
+
+	c.verify(sp, entry, &composite.BackendService{Name: sp.BackendName(), Protocol: "HTTP"})
+	if len(c.entries) != 1 {
+		t.Errorf("Cache has %d entries after verifying an unchanged backend service, want 1", len(c.entries))
+	}
+	c.verify(sp, entry, &composite.BackendService{Name: sp.BackendName(), Protocol: "HTTPS"})
+	if len(c.entries) != 0 {
+		t.Errorf("Cache has %d entries after verifying a modified backend service, want 0", len(c.entries))
+	}
+}
+
+func TestBackendServiceCacheHandoff(t *testing.T) {
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	mockGCE := fakeGCE.Compute().(*cloud.MockGCE)
+	gets := 0
+	mockGCE.MockBackendServices.GetHook = func(ctx context.Context, key *meta.Key, m *cloud.MockBackendServices) (bool, *compute.BackendService, error) {
+		gets++
+		return false, nil, nil
+	}
+	now := time.Now()
+	newSyncer := func() *backendSyncer {
+		syncer := newTestSyncer(fakeGCE)
+		syncer.cache = newBackendServiceCache(time.Minute)
+		syncer.cache.now = func() time.Time { return now }
+		return syncer
+	}
+
+	sp := utils.ServicePort{NodePort: 80, Protocol: annotations.ProtocolHTTP, BackendNamer: defaultNamer}
+	leader := newSyncer()
+	for i := 0; i < 2; i++ {
+		if err := leader.Sync(context.TODO(), []utils.ServicePort{sp}); err != nil {
+			t.Fatalf("leader.Sync() = %v", err)
+		}
+	}
+	fingerprints := leader.Fingerprints()
+	if len(fingerprints) != 1 {
+		t.Fatalf("leader.Fingerprints() = %+v, want 1 fingerprint", fingerprints)
+	}
+
+	// The next leader does not fetch backend services handed off in sync.
+	next := newSyncer()
+	next.RestoreFingerprints(fingerprints)
+	gets = 0
+	if err := next.Sync(context.TODO(), []utils.ServicePort{sp}); err != nil {
+		t.Fatalf("next.Sync() = %v", err)
+	}
+	if gets != 0 {
+		t.Errorf("next.Sync() fetched backend services %d times, want 0", gets)
+	}
+
+	// Fingerprints older than the verify period are not handed off.
+	now = now.Add(time.Minute)
+	if got := next.Fingerprints(); len(got) != 0 {
+		t.Errorf("next.Fingerprints() = %+v, want none", got)
+	}
+	gets = 0
+	if err := next.Sync(context.TODO(), []utils.ServicePort{sp}); err != nil {
+		t.Fatalf("next.Sync() = %v", err)
+	}
+	if gets == 0 {
+		t.Errorf("next.Sync() did not fetch the backend service of an expired fingerprint")
+	}
+}
+
 func TestShutdown(t *testing.T) {
 	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
 	syncer := newTestSyncer(fakeGCE)
 
 	// Sync a backend and verify that it doesn't exist after Shutdown()
-	syncer.Sync([]utils.ServicePort{{NodePort: 80, BackendNamer: defaultNamer}})
-	syncer.Shutdown()
+	syncer.Sync(context.TODO(), []utils.ServicePort{{NodePort: 80, BackendNamer: defaultNamer}})
+	syncer.Shutdown(context.TODO())
 	if _, err := fakeGCE.GetGlobalBackendService(defaultNamer.IGBackend(80)); err == nil {
 		t.Fatalf("%v", err)
 	}
@@ -586,8 +989,8 @@ func TestEnsureBackendServiceProtocol(t *testing.T) {
 			t.Run(
 				fmt.Sprintf("Updating Port:%v Protocol:%v to Port:%v Protocol:%v", oldPort.NodePort, oldPort.Protocol, newPort.NodePort, newPort.Protocol),
 				func(t *testing.T) {
-					syncer.Sync([]utils.ServicePort{oldPort})
-					be, err := syncer.backendPool.Get(oldPort.BackendName(), features.VersionFromServicePort(&oldPort), features.ScopeFromServicePort(&oldPort))
+					syncer.Sync(context.TODO(), []utils.ServicePort{oldPort})
+					be, err := syncer.backendPool.Get(context.TODO(), oldPort.BackendName(), features.VersionFromServicePort(&oldPort), features.ScopeFromServicePort(&oldPort))
 					if err != nil {
 						t.Fatalf("%v", err)
 					}
@@ -630,8 +1033,8 @@ func TestEnsureBackendServiceDescription(t *testing.T) {
 			t.Run(
 				fmt.Sprintf("Updating Port:%v Protocol:%v to Port:%v Protocol:%v", oldPort.NodePort, oldPort.Protocol, newPort.NodePort, newPort.Protocol),
 				func(t *testing.T) {
-					syncer.Sync([]utils.ServicePort{oldPort})
-					be, err := syncer.backendPool.Get(oldPort.BackendName(), features.VersionFromServicePort(&oldPort), features.ScopeFromServicePort(&oldPort))
+					syncer.Sync(context.TODO(), []utils.ServicePort{oldPort})
+					be, err := syncer.backendPool.Get(context.TODO(), oldPort.BackendName(), features.VersionFromServicePort(&oldPort), features.ScopeFromServicePort(&oldPort))
 					if err != nil {
 						t.Fatalf("%v", err)
 					}
@@ -653,13 +1056,45 @@ func TestEnsureBackendServiceDescription(t *testing.T) {
 	}
 }
 
+func TestEnsureBackendServiceDescriptionBackendConfig(t *testing.T) {
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	syncer := newTestSyncer(fakeGCE)
+
+	timeout := int64(30)
+	bc := &backendconfigv1.BackendConfig{}
+	bc.Namespace, bc.Name = "ns", "config-1"
+	p := utils.ServicePort{NodePort: 80, Protocol: annotations.ProtocolHTTP, ID: utils.ServicePortID{Port: networkingv1.ServiceBackendPort{Number: 1}}, BackendNamer: defaultNamer, BackendConfig: bc}
+	syncer.Sync(context.TODO(), []utils.ServicePort{p})
+	be, err := syncer.backendPool.Get(context.TODO(), p.BackendName(), features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	desc := utils.DescriptionFromString(be.Description)
+	if desc.BackendConfig != "ns/config-1" || desc.BackendConfigHash == "" {
+		t.Fatalf("Description %q does not record BackendConfig ns/config-1 and its hash", be.Description)
+	}
+	if ensureDescription(be, &p) {
+		t.Fatalf("Expected ensureDescription for the same BackendConfig to return false")
+	}
+
+	updated := bc.DeepCopy()
+	updated.Spec.TimeoutSec = &timeout
+	p.BackendConfig = updated
+	if !ensureDescription(be, &p) {
+		t.Fatalf("Expected ensureDescription for an updated BackendConfig to return true")
+	}
+	if got := utils.DescriptionFromString(be.Description).BackendConfigHash; got == desc.BackendConfigHash {
+		t.Errorf("BackendConfig hash %q did not change with the BackendConfig spec", got)
+	}
+}
+
 func TestEnsureBackendServiceHealthCheckLink(t *testing.T) {
 	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
 	syncer := newTestSyncer(fakeGCE)
 
 	p := utils.ServicePort{NodePort: 80, Protocol: annotations.ProtocolHTTP, ID: utils.ServicePortID{Port: networkingv1.ServiceBackendPort{Number: 1}}, BackendNamer: defaultNamer}
-	syncer.Sync([]utils.ServicePort{p})
-	be, err := syncer.backendPool.Get(p.BackendName(), features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
+	syncer.Sync(context.TODO(), []utils.ServicePort{p})
+	be, err := syncer.backendPool.Get(context.TODO(), p.BackendName(), features.VersionFromServicePort(&p), features.ScopeFromServicePort(&p))
 	if err != nil {
 		t.Fatalf("%v", err)
 	}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package signing produces Cloud CDN signed URLs and signed cookies from a
+// signing key stored in a Kubernetes Secret, so that applications and tests
+// do not need to reimplement the signing algorithm.
+//
+// The Secret holds the name of the key under KeyNameKey and the 128 bit key,
+// base64url encoded as accepted by gcloud, under KeyValueKey.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// KeyNameKey is the key of the Secret data which holds the name of the
+	// signing key, as added to the backend.
+	KeyNameKey = "keyName"
+	// KeyValueKey is the key of the Secret data which holds the base64url
+	// encoded value of the signing key.
+	KeyValueKey = "keyValue"
+	// CookieName is the name of the Cloud CDN signed cookie.
+	CookieName = "Cloud-CDN-Cookie"
+	// keyLength is the length in bytes of a Cloud CDN signing key.
+	keyLength = 16
+)
+
+// Key is a Cloud CDN signing key.
+type Key struct {
+	// Name is the name of the key on the backend.
+	Name string
+	// Value is the decoded value of the key.
+	Value []byte
+}
+
+// KeyFromSecret returns the signing key stored in the given Secret.
+func KeyFromSecret(secret *v1.Secret) (*Key, error) {
+	name := strings.TrimSpace(string(secret.Data[KeyNameKey]))
+	if name == "" {
+		return nil, fmt.Errorf("secret %s/%s has no %q", secret.Namespace, secret.Name, KeyNameKey)
+	}
+	encoded := strings.TrimSpace(string(secret.Data[KeyValueKey]))
+	value, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("secret %s/%s has an invalid %q: %v", secret.Namespace, secret.Name, KeyValueKey, err)
+	}
+	if len(value) != keyLength {
+		return nil, fmt.Errorf("secret %s/%s has a %q of %d bytes, want %d", secret.Namespace, secret.Name, KeyValueKey, len(value), keyLength)
+	}
+	return &Key{Name: name, Value: value}, nil
+}
+
+// SignURL returns the given URL signed to be valid until expires.
+func (k *Key) SignURL(url string, expires time.Time) string {
+	sep := "?"
+	if strings.Contains(url, "?") {
+		sep = "&"
+	}
+	toSign := fmt.Sprintf("%s%sExpires=%d&KeyName=%s", url, sep, expires.Unix(), k.Name)
+	return fmt.Sprintf("%s&Signature=%s", toSign, k.sign(toSign))
+}
+
+// SignURLPrefix returns the query parameters which, appended to any URL
+// starting with the given prefix, make it valid until expires.
+func (k *Key) SignURLPrefix(urlPrefix string, expires time.Time) string {
+	toSign := fmt.Sprintf("URLPrefix=%s&Expires=%d&KeyName=%s", base64.URLEncoding.EncodeToString([]byte(urlPrefix)), expires.Unix(), k.Name)
+	return fmt.Sprintf("%s&Signature=%s", toSign, k.sign(toSign))
+}
+
+// SignCookie returns the signed cookie which makes requests for URLs starting
+// with the given prefix valid until expires.
+func (k *Key) SignCookie(urlPrefix string, expires time.Time) *http.Cookie {
+	toSign := fmt.Sprintf("URLPrefix=%s:Expires=%d:KeyName=%s", base64.URLEncoding.EncodeToString([]byte(urlPrefix)), expires.Unix(), k.Name)
+	return &http.Cookie{
+		Name:    CookieName,
+		Value:   fmt.Sprintf("%s:Signature=%s", toSign, k.sign(toSign)),
+		Expires: expires,
+	}
+}
+
+// sign returns the base64url encoded HMAC-SHA1 signature of s.
+func (k *Key) sign(s string) string {
+	mac := hmac.New(sha1.New, k.Value)
+	mac.Write([]byte(s))
+	return base64.URLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signing
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newSecret(data map[string]string) *v1.Secret {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cdn-key"},
+		Data:       map[string][]byte{},
+	}
+	for k, v := range data {
+		secret.Data[k] = []byte(v)
+	}
+	return secret
+}
+
+func TestKeyFromSecret(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		data    map[string]string
+		wantErr bool
+	}{
+		{
+			desc: "valid key",
+			data: map[string]string{KeyNameKey: "my-key", KeyValueKey: "nZtRohdNF9m3cKM24IcK4w==\n"},
+		},
+		{
+			desc:    "missing key name",
+			data:    map[string]string{KeyValueKey: "nZtRohdNF9m3cKM24IcK4w=="},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid encoding",
+			data:    map[string]string{KeyNameKey: "my-key", KeyValueKey: "not base64!"},
+			wantErr: true,
+		},
+		{
+			desc:    "wrong key length",
+			data:    map[string]string{KeyNameKey: "my-key", KeyValueKey: "bm90MTZieXRlcw=="},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			key, err := KeyFromSecret(newSecret(tc.data))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("KeyFromSecret() = %v, want error %t", err, tc.wantErr)
+			}
+			if err == nil && key.Name != "my-key" {
+				t.Errorf("KeyFromSecret().Name = %q, want %q", key.Name, "my-key")
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	key, err := KeyFromSecret(newSecret(map[string]string{KeyNameKey: "my-key", KeyValueKey: "nZtRohdNF9m3cKM24IcK4w=="}))
+	if err != nil {
+		t.Fatalf("KeyFromSecret() = %v", err)
+	}
+	expires := time.Unix(1600000000, 0)
+
+	for _, tc := range []struct {
+		desc string
+		got  string
+		want string
+	}{
+		{
+			desc: "signed URL",
+			got:  key.SignURL("https://example.com/foo", expires),
+			want: "https://example.com/foo?Expires=1600000000&KeyName=my-key&Signature=d_HwNLW-yaUx7gDOI52F4IsaoaY=",
+		},
+		{
+			desc: "signed URL with query",
+			got:  key.SignURL("https://example.com/foo?a=b", expires),
+			want: "https://example.com/foo?a=b&Expires=1600000000&KeyName=my-key&Signature=nqkdXzCT--yp-TRH23ILeKGMABE=",
+		},
+		{
+			desc: "signed URL prefix",
+			got:  key.SignURLPrefix("https://example.com/foo/", expires),
+			want: "URLPrefix=aHR0cHM6Ly9leGFtcGxlLmNvbS9mb28v&Expires=1600000000&KeyName=my-key&Signature=EGu8fReUXLfw7OB5cx-VYPcCkPo=",
+		},
+		{
+			desc: "signed cookie",
+			got:  key.SignCookie("https://example.com/foo/", expires).String(),
+			want: "Cloud-CDN-Cookie=URLPrefix=aHR0cHM6Ly9leGFtcGxlLmNvbS9mb28v:Expires=1600000000:KeyName=my-key:Signature=hLT95QEUOCy1qEVLq7MISPvSdGo=; Expires=Sun, 13 Sep 2020 12:26:40 GMT",
+		},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %q, want %q", tc.desc, tc.got, tc.want)
+		}
+	}
+}
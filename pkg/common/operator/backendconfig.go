@@ -7,6 +7,7 @@ import (
 	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
 
 	api_v1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
 )
 
 // doesServiceReferenceBackendConfig returns true if the passed in Service directly references
@@ -36,3 +37,22 @@ func doesServiceReferenceBackendConfig(svc *api_v1.Service, beConfig *backendcon
 	}
 	return false
 }
+
+// doesIngressReferenceBackendConfig returns true if the passed in Ingress
+// attaches the passed in BackendConfig to some of its paths.
+func doesIngressReferenceBackendConfig(ing *v1.Ingress, beConfig *backendconfigv1.BackendConfig) bool {
+	if ing.Namespace != beConfig.Namespace {
+		return false
+	}
+	pathBackendConfigs, err := annotations.FromIngress(ing).PathBackendConfigs()
+	if err != nil {
+		klog.Errorf("Failed to get path BackendConfig names from ingress %s/%s: %v", ing.Namespace, ing.Name, err)
+		return false
+	}
+	for _, name := range pathBackendConfigs {
+		if name == beConfig.Name {
+			return true
+		}
+	}
+	return false
+}
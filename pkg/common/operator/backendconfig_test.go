@@ -6,6 +6,9 @@ import (
 	"k8s.io/ingress-gce/pkg/backendconfig"
 
 	api_v1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/ingress-gce/pkg/annotations"
 )
 
 func TestDoesServiceReferenceBackendConfig(t *testing.T) {
@@ -58,3 +61,47 @@ func TestDoesServiceReferenceBackendConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestDoesIngressReferenceBackendConfig(t *testing.T) {
+	ingress := func(namespace, value string) *v1.Ingress {
+		return &v1.Ingress{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Annotations: map[string]string{annotations.PathBackendConfigsKey: value},
+		}}
+	}
+	for _, tc := range []struct {
+		desc     string
+		ing      *v1.Ingress
+		expected bool
+	}{
+		{
+			desc:     "ingress without path backend configs",
+			ing:      &v1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "test"}},
+			expected: false,
+		},
+		{
+			desc:     "ingress with test backend config",
+			ing:      ingress("test", `{"/static/*": "config-test"}`),
+			expected: true,
+		},
+		{
+			desc:     "ingress with test backend config in a different namespace",
+			ing:      ingress("other", `{"/static/*": "config-test"}`),
+			expected: false,
+		},
+		{
+			desc:     "ingress with a different backend config",
+			ing:      ingress("test", `{"/static/*": "config-other"}`),
+			expected: false,
+		},
+		{
+			desc:     "ingress with invalid path backend configs",
+			ing:      ingress("test", `config-test`),
+			expected: false,
+		},
+	} {
+		if result := doesIngressReferenceBackendConfig(tc.ing, backendconfig.TestBackendConfig); result != tc.expected {
+			t.Errorf("%s: doesIngressReferenceBackendConfig() = %v, want %v", tc.desc, result, tc.expected)
+		}
+	}
+}
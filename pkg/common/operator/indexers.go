@@ -0,0 +1,164 @@
+package operator
+
+import (
+	api_v1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/ingress-gce/pkg/annotations"
+	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+// Names of the indexes of the informers of Ingresses, Services and
+// BackendConfigs. The indexed values are the namespaced names, as returned by
+// utils.ServiceKeyFunc, of the referenced objects.
+const (
+	// ServiceIndex indexes Ingresses by the Services of their backends.
+	ServiceIndex = "service"
+	// BackendConfigIndex indexes Ingresses and Services by the BackendConfigs
+	// they reference.
+	BackendConfigIndex = "backendConfig"
+	// SecretIndex indexes Ingresses by their TLS Secrets and BackendConfigs
+	// by the Secret of their IAP OAuth client credentials.
+	SecretIndex = "secret"
+)
+
+// IngressIndexers returns the indexers of the Ingress informer.
+func IngressIndexers() cache.Indexers {
+	return cache.Indexers{
+		ServiceIndex:       ingressServices,
+		BackendConfigIndex: ingressBackendConfigs,
+		SecretIndex:        ingressSecrets,
+	}
+}
+
+// ServiceIndexers returns the indexers of the Service informer.
+func ServiceIndexers() cache.Indexers {
+	return cache.Indexers{BackendConfigIndex: serviceBackendConfigs}
+}
+
+// BackendConfigIndexers returns the indexers of the BackendConfig informer.
+func BackendConfigIndexers() cache.Indexers {
+	return cache.Indexers{SecretIndex: backendConfigSecrets}
+}
+
+func ingressServices(obj interface{}) ([]string, error) {
+	ing, ok := obj.(*v1.Ingress)
+	if !ok {
+		return nil, nil
+	}
+	keys := sets.NewString()
+	utils.TraverseIngressBackends(ing, func(id utils.ServicePortID) bool {
+		keys.Insert(utils.ServiceKeyFunc(id.Service.Namespace, id.Service.Name))
+		return false
+	})
+	return keys.List(), nil
+}
+
+func ingressBackendConfigs(obj interface{}) ([]string, error) {
+	ing, ok := obj.(*v1.Ingress)
+	if !ok {
+		return nil, nil
+	}
+	// Invalid annotations are reported by the sync of the Ingress.
+	pathBackendConfigs, _ := annotations.FromIngress(ing).PathBackendConfigs()
+	keys := sets.NewString()
+	for _, name := range pathBackendConfigs {
+		keys.Insert(utils.ServiceKeyFunc(ing.Namespace, name))
+	}
+	return keys.List(), nil
+}
+
+func ingressSecrets(obj interface{}) ([]string, error) {
+	ing, ok := obj.(*v1.Ingress)
+	if !ok {
+		return nil, nil
+	}
+	keys := sets.NewString()
+	for _, tls := range ing.Spec.TLS {
+		if tls.SecretName != "" {
+			keys.Insert(utils.ServiceKeyFunc(ing.Namespace, tls.SecretName))
+		}
+	}
+	return keys.List(), nil
+}
+
+func serviceBackendConfigs(obj interface{}) ([]string, error) {
+	svc, ok := obj.(*api_v1.Service)
+	if !ok {
+		return nil, nil
+	}
+	// Invalid annotations are reported by the sync of the Ingresses.
+	backendConfigNames, err := annotations.FromService(svc).GetBackendConfigs()
+	if err != nil || backendConfigNames == nil {
+		return nil, nil
+	}
+	keys := sets.NewString()
+	if backendConfigNames.Default != "" {
+		keys.Insert(utils.ServiceKeyFunc(svc.Namespace, backendConfigNames.Default))
+	}
+	for _, name := range backendConfigNames.Ports {
+		keys.Insert(utils.ServiceKeyFunc(svc.Namespace, name))
+	}
+	return keys.List(), nil
+}
+
+func backendConfigSecrets(obj interface{}) ([]string, error) {
+	beConfig, ok := obj.(*backendconfigv1.BackendConfig)
+	if !ok || beConfig.Spec.Iap == nil || beConfig.Spec.Iap.OAuthClientCredentials == nil {
+		return nil, nil
+	}
+	if name := beConfig.Spec.Iap.OAuthClientCredentials.SecretName; name != "" {
+		return []string{utils.ServiceKeyFunc(beConfig.Namespace, name)}, nil
+	}
+	return nil, nil
+}
+
+// IngressesByIndex returns the Ingresses of the given index of the indexer
+// which reference the object with the given namespace and name.
+func IngressesByIndex(indexer cache.Indexer, index, namespace, name string) ([]*v1.Ingress, error) {
+	objs, err := indexer.ByIndex(index, utils.ServiceKeyFunc(namespace, name))
+	if err != nil {
+		return nil, err
+	}
+	var ret []*v1.Ingress
+	for _, obj := range objs {
+		if ing, ok := obj.(*v1.Ingress); ok {
+			ret = append(ret, ing)
+		}
+	}
+	return ret, nil
+}
+
+// ServicesByIndex returns the Services of the given index of the indexer
+// which reference the object with the given namespace and name.
+func ServicesByIndex(indexer cache.Indexer, index, namespace, name string) ([]*api_v1.Service, error) {
+	objs, err := indexer.ByIndex(index, utils.ServiceKeyFunc(namespace, name))
+	if err != nil {
+		return nil, err
+	}
+	var ret []*api_v1.Service
+	for _, obj := range objs {
+		if svc, ok := obj.(*api_v1.Service); ok {
+			ret = append(ret, svc)
+		}
+	}
+	return ret, nil
+}
+
+// BackendConfigsByIndex returns the BackendConfigs of the given index of the
+// indexer which reference the object with the given namespace and name.
+func BackendConfigsByIndex(indexer cache.Indexer, index, namespace, name string) ([]*backendconfigv1.BackendConfig, error) {
+	objs, err := indexer.ByIndex(index, utils.ServiceKeyFunc(namespace, name))
+	if err != nil {
+		return nil, err
+	}
+	var ret []*backendconfigv1.BackendConfig
+	for _, obj := range objs {
+		if beConfig, ok := obj.(*backendconfigv1.BackendConfig); ok {
+			ret = append(ret, beConfig)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,87 @@
+package operator
+
+import (
+	"reflect"
+	"testing"
+
+	api_v1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/ingress-gce/pkg/annotations"
+	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
+)
+
+func TestIndexers(t *testing.T) {
+	ing := &v1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "ing",
+			Annotations: map[string]string{annotations.PathBackendConfigsKey: `{"/static/*": "cdn"}`},
+		},
+		Spec: v1.IngressSpec{
+			DefaultBackend: &v1.IngressBackend{
+				Service: &v1.IngressServiceBackend{Name: "svc", Port: v1.ServiceBackendPort{Number: 80}},
+			},
+			TLS: []v1.IngressTLS{{SecretName: "tls"}},
+		},
+	}
+	svc := &api_v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "svc",
+			Annotations: map[string]string{annotations.BackendConfigKey: `{"default": "config"}`},
+		},
+	}
+	beConfig := &backendconfigv1.BackendConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "config"},
+		Spec: backendconfigv1.BackendConfigSpec{
+			Iap: &backendconfigv1.IAPConfig{
+				OAuthClientCredentials: &backendconfigv1.OAuthClientCredentials{SecretName: "iap"},
+			},
+		},
+	}
+
+	ingIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, IngressIndexers())
+	ingIndexer.Add(ing)
+	svcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, ServiceIndexers())
+	svcIndexer.Add(svc)
+	beConfigIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, BackendConfigIndexers())
+	beConfigIndexer.Add(beConfig)
+
+	for _, tc := range []struct {
+		desc  string
+		index string
+		name  string
+		want  []*v1.Ingress
+	}{
+		{desc: "by service", index: ServiceIndex, name: "svc", want: []*v1.Ingress{ing}},
+		{desc: "by other service", index: ServiceIndex, name: "other"},
+		{desc: "by path BackendConfig", index: BackendConfigIndex, name: "cdn", want: []*v1.Ingress{ing}},
+		{desc: "by TLS secret", index: SecretIndex, name: "tls", want: []*v1.Ingress{ing}},
+	} {
+		got, err := IngressesByIndex(ingIndexer, tc.index, "ns", tc.name)
+		if err != nil {
+			t.Fatalf("%s: IngressesByIndex() = %v", tc.desc, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: IngressesByIndex() = %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+
+	svcs, err := ServicesByIndex(svcIndexer, BackendConfigIndex, "ns", "config")
+	if err != nil {
+		t.Fatalf("ServicesByIndex() = %v", err)
+	}
+	if want := []*api_v1.Service{svc}; !reflect.DeepEqual(svcs, want) {
+		t.Errorf("ServicesByIndex() = %v, want %v", svcs, want)
+	}
+
+	beConfigs, err := BackendConfigsByIndex(beConfigIndexer, SecretIndex, "ns", "iap")
+	if err != nil {
+		t.Fatalf("BackendConfigsByIndex() = %v", err)
+	}
+	if want := []*backendconfigv1.BackendConfig{beConfig}; !reflect.DeepEqual(beConfigs, want) {
+		t.Errorf("BackendConfigsByIndex() = %v, want %v", beConfigs, want)
+	}
+}
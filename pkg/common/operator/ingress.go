@@ -75,6 +75,14 @@ func (op *IngressesOperator) ReferencesBackendConfig(beConfig *backendconfigv1.B
 	var i []*v1.Ingress
 	svcs := svcsOp.ReferencesBackendConfig(beConfig).AsList()
 	for _, ing := range op.i {
+		if doesIngressReferenceBackendConfig(ing, beConfig) {
+			key := fmt.Sprintf("%s/%s", ing.Namespace, ing.Name)
+			if !dupes[key] {
+				i = append(i, ing)
+				dupes[key] = true
+			}
+			continue
+		}
 		for _, svc := range svcs {
 			key := fmt.Sprintf("%s/%s", ing.Namespace, ing.Name)
 			if doesIngressReferenceService(ing, svc) && !dupes[key] {
@@ -0,0 +1,235 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"k8s.io/klog"
+)
+
+const (
+	// AuditOutcomeSuccess is the outcome of a call which succeeded.
+	AuditOutcomeSuccess = "success"
+	// AuditOutcomeError is the outcome of a call which failed.
+	AuditOutcomeError = "error"
+
+	// syncIDDescriptionKey is the key of the ID of the controller sync in
+	// the description of a resource.
+	syncIDDescriptionKey = "kubernetes.io/sync-id"
+)
+
+// auditIgnoredFields are the fields of a resource which are set by GCE and
+// are not part of the diff of an update.
+var auditIgnoredFields = map[string]bool{
+	"creationTimestamp": true,
+	"fingerprint":       true,
+	"id":                true,
+	"kind":              true,
+	"region":            true,
+	"selfLink":          true,
+}
+
+// AuditRecord describes a mutating call to the GCE API.
+type AuditRecord struct {
+	// Time is the time at which the call returned.
+	Time time.Time `json:"time"`
+	// Resource is the kind of the resource, e.g. BackendService.
+	Resource string `json:"resource"`
+	// Operation is the mutation, e.g. create.
+	Operation string `json:"operation"`
+	Name      string `json:"name"`
+	Region    string `json:"region,omitempty"`
+	Zone      string `json:"zone,omitempty"`
+	Version   string `json:"version"`
+	// SyncID is the ID of the controller sync which made the call. It is
+	// only known if the sync records it in the description of the resource.
+	SyncID string `json:"syncID,omitempty"`
+	// Diff summarizes the change, e.g. the fields changed by an update.
+	Diff    string `json:"diff,omitempty"`
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+}
+
+// AuditSink receives a record of every mutating call to the GCE API.
+type AuditSink interface {
+	Record(r *AuditRecord)
+}
+
+var (
+	auditLock sync.RWMutex
+	auditSink AuditSink
+)
+
+// SetAuditSink sets the sink which receives a record of every mutating call
+// to the GCE API. A nil sink disables auditing.
+func SetAuditSink(s AuditSink) {
+	auditLock.Lock()
+	defer auditLock.Unlock()
+	auditSink = s
+}
+
+func getAuditSink() AuditSink {
+	auditLock.RLock()
+	defer auditLock.RUnlock()
+	return auditSink
+}
+
+// jsonAuditSink writes every record as a line of JSON.
+type jsonAuditSink struct {
+	lock sync.Mutex
+	enc  *json.Encoder
+}
+
+// NewJSONAuditSink returns an AuditSink which writes every record to w as a
+// line of JSON.
+func NewJSONAuditSink(w io.Writer) AuditSink {
+	return &jsonAuditSink{enc: json.NewEncoder(w)}
+}
+
+// Record implements AuditSink.
+func (s *jsonAuditSink) Record(r *AuditRecord) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.enc.Encode(r); err != nil {
+		klog.Errorf("Failed to write audit record %+v: %v", r, err)
+	}
+}
+
+// observer observes the result of a call to the GCE API.
+type observer interface {
+	Observe(err error) error
+}
+
+// auditedCall records the result of a mutating call in the audit sink in
+// addition to the metrics.
+type auditedCall struct {
+	mc     observer
+	sink   AuditSink
+	key    *meta.Key
+	record *AuditRecord
+}
+
+// newAuditedCall returns an observer which records the result of the call in
+// the audit sink, if any. description is the description of the resource
+// sent to GCE. diff is only called if auditing is enabled.
+func newAuditedCall(mc observer, resource, operation string, key *meta.Key, version meta.Version, description string, diff func() string) observer {
+	sink := getAuditSink()
+	if sink == nil {
+		return mc
+	}
+	record := &AuditRecord{
+		Resource:  resource,
+		Operation: operation,
+		Version:   string(version),
+		SyncID:    auditSyncID(description),
+	}
+	if diff != nil {
+		record.Diff = diff()
+	}
+	return &auditedCall{mc: mc, sink: sink, key: key, record: record}
+}
+
+// Observe implements observer.
+func (a *auditedCall) Observe(err error) error {
+	err = a.mc.Observe(err)
+	// Some calls only set the name of the key once the call is observed.
+	a.record.Name = a.key.Name
+	a.record.Region = a.key.Region
+	a.record.Zone = a.key.Zone
+	a.record.Time = time.Now()
+	a.record.Outcome = AuditOutcomeSuccess
+	if err != nil {
+		a.record.Outcome = AuditOutcomeError
+		a.record.Error = err.Error()
+	}
+	a.sink.Record(a.record)
+	return err
+}
+
+// auditSyncID returns the ID of the controller sync recorded in the given
+// resource description, if any.
+func auditSyncID(description string) string {
+	var desc map[string]interface{}
+	if err := json.Unmarshal([]byte(description), &desc); err != nil {
+		return ""
+	}
+	syncID, _ := desc[syncIDDescriptionKey].(string)
+	return syncID
+}
+
+// auditDiff summarizes the fields in which the resource desired differs from
+// the resource existing as returned by get.
+func auditDiff(get func() (interface{}, error), desired interface{}) string {
+	existing, err := get()
+	if err != nil {
+		return fmt.Sprintf("unknown, failed to get existing resource: %v", err)
+	}
+	existingFields, err := auditFields(existing)
+	if err != nil {
+		return fmt.Sprintf("unknown: %v", err)
+	}
+	desiredFields, err := auditFields(desired)
+	if err != nil {
+		return fmt.Sprintf("unknown: %v", err)
+	}
+	changed := map[string]bool{}
+	for field, value := range desiredFields {
+		if !reflect.DeepEqual(existingFields[field], value) {
+			changed[field] = true
+		}
+	}
+	for field := range existingFields {
+		if _, ok := desiredFields[field]; !ok {
+			changed[field] = true
+		}
+	}
+	if len(changed) == 0 {
+		return "no changes"
+	}
+	var fields []string
+	for field := range changed {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return "changed " + strings.Join(fields, ", ")
+}
+
+// auditFields returns the JSON fields of the resource r which are not set by
+// GCE.
+func auditFields(r interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for field := range auditIgnoredFields {
+		delete(fields, field)
+	}
+	return fields, nil
+}
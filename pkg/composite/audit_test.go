@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+func TestAudit(t *testing.T) {
+	var buf bytes.Buffer
+	SetAuditSink(NewJSONAuditSink(&buf))
+	defer SetAuditSink(nil)
+
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	key := meta.GlobalKey("audit-test")
+	bs := &BackendService{Name: key.Name, Version: meta.VersionGA, TimeoutSec: 30,
+		Description: `{"kubernetes.io/ingress-name": "ns/ing", "kubernetes.io/sync-id": "abc123"}`}
+	if err := CreateBackendService(context.TODO(), fakeGCE, key, bs); err != nil {
+		t.Fatalf("CreateBackendService(%v) = %v", key, err)
+	}
+	bs.TimeoutSec = 60
+	if err := UpdateBackendService(context.TODO(), fakeGCE, key, bs); err != nil {
+		t.Fatalf("UpdateBackendService(%v) = %v", key, err)
+	}
+	if _, err := GetBackendService(context.TODO(), fakeGCE, key, meta.VersionGA); err != nil {
+		t.Fatalf("GetBackendService(%v) = %v", key, err)
+	}
+	missingKey := meta.GlobalKey("audit-test-missing")
+	if err := DeleteBackendService(context.TODO(), fakeGCE, missingKey, meta.VersionGA); err == nil {
+		t.Fatalf("DeleteBackendService(%v) = nil, want error", missingKey)
+	}
+
+	want := []AuditRecord{
+		{Resource: "BackendService", Operation: "create", Name: key.Name, Version: "ga", SyncID: "abc123", Outcome: AuditOutcomeSuccess},
+		{Resource: "BackendService", Operation: "update", Name: key.Name, Version: "ga", SyncID: "abc123", Diff: "changed timeoutSec", Outcome: AuditOutcomeSuccess},
+		{Resource: "BackendService", Operation: "delete", Name: missingKey.Name, Version: "ga", Outcome: AuditOutcomeError},
+	}
+	dec := json.NewDecoder(&buf)
+	i := 0
+	for ; dec.More(); i++ {
+		var got AuditRecord
+		if err := dec.Decode(&got); err != nil {
+			t.Fatalf("Decode() = %v", err)
+		}
+		if i >= len(want) {
+			t.Fatalf("Got unexpected audit record %+v", got)
+		}
+		if got.Time.IsZero() {
+			t.Errorf("Audit record %d has no time", i)
+		}
+		if (got.Error != "") != (want[i].Outcome == AuditOutcomeError) {
+			t.Errorf("Audit record %d has error %q, want error for outcome %q", i, got.Error, want[i].Outcome)
+		}
+		got.Time, got.Error = want[i].Time, ""
+		if got != want[i] {
+			t.Errorf("Audit record %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+	if i != len(want) {
+		t.Errorf("Got %d audit records, want %d", i, len(want))
+	}
+}
+
+func TestAuditCall(t *testing.T) {
+	var buf bytes.Buffer
+	SetAuditSink(NewJSONAuditSink(&buf))
+	defer SetAuditSink(nil)
+
+	key := meta.GlobalKey("policy")
+	for _, mutating := range []bool{false, true} {
+		call := &Call{Service: "SecurityPolicies", Method: "PatchRule", Resource: "SecurityPolicy", Request: "patch_rule", Key: key, Version: meta.VersionAlpha, Mutating: mutating}
+		mc, err := call.Start(context.Background(), "test-project")
+		if err != nil {
+			t.Fatalf("Start() = %v", err)
+		}
+		if err := mc.Observe(nil); err != nil {
+			t.Fatalf("Observe(nil) = %v", err)
+		}
+	}
+
+	var got AuditRecord
+	dec := json.NewDecoder(&buf)
+	if err := dec.Decode(&got); err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	got.Time = time.Time{}
+	want := AuditRecord{Resource: "SecurityPolicy", Operation: "patch_rule", Name: key.Name, Version: "alpha", Outcome: AuditOutcomeSuccess}
+	if got != want {
+		t.Errorf("Audit record = %+v, want %+v", got, want)
+	}
+	if dec.More() {
+		t.Errorf("Got an audit record for a call which is not mutating")
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"time"
+
+	cloudprovider "github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	compositemetrics "k8s.io/ingress-gce/pkg/composite/metrics"
+	"k8s.io/ingress-gce/pkg/flags"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+// callTimeout bounds a call to the GCE API, including the wait for its
+// operation to complete.
+const callTimeout = time.Hour
+
+// ContextWithCallTimeout returns the context of a call to the GCE API made on
+// behalf of ctx, e.g. the context of a sync. The call is cancelled once ctx is
+// done, or after callTimeout.
+func ContextWithCallTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, callTimeout)
+}
+
+// CallObserver observes the result of a call to the GCE API.
+type CallObserver interface {
+	Observe(err error) error
+}
+
+// Call describes a call to the GCE API which is not made through the
+// composite types, e.g. to a resource which has no composite type.
+type Call struct {
+	// Service and Method name the call in the compute API, e.g.
+	// "SecurityPolicies" and "PatchRule". They key the rate limiter.
+	Service string
+	Method  string
+	// Resource and Request label the metrics and audit records of the call,
+	// e.g. "SecurityPolicy" and "patch_rule".
+	Resource string
+	Request  string
+	Key      *meta.Key
+	Version  meta.Version
+	// Mutating calls are recorded in the audit log along with the ID of the
+	// sync found in Description, the description of the resource sent to
+	// GCE, if any.
+	Mutating    bool
+	Description string
+}
+
+// Start blocks until the rate limiter shared with the gce.Cloud accepts the
+// call in the given project. It returns the observer which records the
+// metrics of the call and, for mutating calls, its audit record.
+func (c *Call) Start(ctx context.Context, project string) (CallObserver, error) {
+	rlk := &cloudprovider.RateLimitKey{
+		ProjectID: project,
+		Operation: c.Method,
+		Version:   c.Version,
+		Service:   c.Service,
+	}
+	if err := sharedRateLimiter().Accept(ctx, rlk); err != nil {
+		return nil, err
+	}
+	mc := compositemetrics.NewMetricContext(c.Resource, c.Request, c.Key.Region, c.Key.Zone, string(c.Version))
+	if !c.Mutating {
+		return mc, nil
+	}
+	return newAuditedCall(mc, c.Resource, c.Request, c.Key, c.Version, c.Description, nil), nil
+}
+
+// WaitForCompletion blocks until op, an operation of any API version
+// returned by a call started with Call.Start, completes. The operation is
+// polled through the shared rate limiter.
+func WaitForCompletion(ctx context.Context, gceCloud *gce.Cloud, project string, op interface{}) error {
+	services := gceCloud.ComputeServices()
+	s := &cloudprovider.Service{
+		GA:            services.GA,
+		Alpha:         services.Alpha,
+		Beta:          services.Beta,
+		ProjectRouter: &cloudprovider.SingleProjectRouter{ID: project},
+		RateLimiter: &cloudprovider.MinimumRateLimiter{
+			RateLimiter: sharedRateLimiter(),
+			Minimum:     flags.F.GCEOperationPollInterval,
+		},
+	}
+	return s.WaitForCompletion(ctx, op)
+}
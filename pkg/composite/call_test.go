@@ -0,0 +1,55 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/compute/v1"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+func TestCallCancelledWithContext(t *testing.T) {
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	// The insert hangs until its context is done, like a GCE operation which
+	// never completes.
+	fakeGCE.Compute().(*cloud.MockGCE).MockBackendServices.InsertHook = func(ctx context.Context, _ *meta.Key, _ *compute.BackendService, _ *cloud.MockBackendServices) (bool, error) {
+		<-ctx.Done()
+		return true, ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	key := meta.GlobalKey("cancel-test")
+	done := make(chan error)
+	go func() {
+		done <- CreateBackendService(ctx, fakeGCE, key, &BackendService{Name: key.Name, Version: meta.VersionGA})
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("CreateBackendService() = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("CreateBackendService() was not cancelled with its context")
+	}
+}
@@ -18,6 +18,7 @@ limitations under the License.
 package composite
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
@@ -31,10 +32,17 @@ import (
 )
 
 // SetUrlMapForTargetHttpsProxy() sets the UrlMap for a target https proxy
-func SetUrlMapForTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, urlMapLink string) error {
-	ctx, cancel := cloud.ContextWithCallTimeout()
+func SetUrlMapForTargetHttpsProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, urlMapLink string) error {
+	return withTransientRetry(ctx, "SetUrlMapForTargetHttpsProxy "+key.Name, func() error {
+		return setUrlMapForTargetHttpsProxy(ctx, gceCloud, key, targetHttpsProxy, urlMapLink)
+	})
+}
+
+func setUrlMapForTargetHttpsProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, urlMapLink string) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := metrics.NewMetricContext("TargetHttpsProxy", "set_url_map", key.Region, key.Zone, string(targetHttpsProxy.Version))
+	mc := newAuditedCall(metrics.NewMetricContext("TargetHttpsProxy", "set_url_map", key.Region, key.Zone, string(targetHttpsProxy.Version)),
+		"TargetHttpsProxy", "set_url_map", key, targetHttpsProxy.Version, targetHttpsProxy.Description, func() string { return "urlMap " + urlMapLink })
 
 	// Set name in case it is not present in the key
 	key.Name = targetHttpsProxy.Name
@@ -69,10 +77,17 @@ func SetUrlMapForTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, targetHttp
 }
 
 // SetSslCertificateForTargetHttpsProxy() sets the SSL Certificate for a target https proxy
-func SetSslCertificateForTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, sslCertURLs []string) error {
-	ctx, cancel := cloud.ContextWithCallTimeout()
+func SetSslCertificateForTargetHttpsProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, sslCertURLs []string) error {
+	return withTransientRetry(ctx, "SetSslCertificateForTargetHttpsProxy "+key.Name, func() error {
+		return setSslCertificateForTargetHttpsProxy(ctx, gceCloud, key, targetHttpsProxy, sslCertURLs)
+	})
+}
+
+func setSslCertificateForTargetHttpsProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, sslCertURLs []string) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := metrics.NewMetricContext("TargetHttpsProxy", "set_ssl_certificate", key.Region, key.Zone, string(targetHttpsProxy.Version))
+	mc := newAuditedCall(metrics.NewMetricContext("TargetHttpsProxy", "set_ssl_certificate", key.Region, key.Zone, string(targetHttpsProxy.Version)),
+		"TargetHttpsProxy", "set_ssl_certificate", key, targetHttpsProxy.Version, targetHttpsProxy.Description, func() string { return fmt.Sprintf("sslCertificates %v", sslCertURLs) })
 
 	// Set name in case it is not present in the key
 	key.Name = targetHttpsProxy.Name
@@ -110,10 +125,17 @@ func SetSslCertificateForTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, ta
 }
 
 // SetSslPolicyForTargetHttpsProxy() sets the url map for a target proxy
-func SetSslPolicyForTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, SslPolicyLink string) error {
-	ctx, cancel := cloud.ContextWithCallTimeout()
+func SetSslPolicyForTargetHttpsProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, SslPolicyLink string) error {
+	return withTransientRetry(ctx, "SetSslPolicyForTargetHttpsProxy "+key.Name, func() error {
+		return setSslPolicyForTargetHttpsProxy(ctx, gceCloud, key, targetHttpsProxy, SslPolicyLink)
+	})
+}
+
+func setSslPolicyForTargetHttpsProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, SslPolicyLink string) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := metrics.NewMetricContext("TargetHttpProxy", "set_url_map", key.Region, key.Zone, string(targetHttpsProxy.Version))
+	mc := newAuditedCall(metrics.NewMetricContext("TargetHttpProxy", "set_url_map", key.Region, key.Zone, string(targetHttpsProxy.Version)),
+		"TargetHttpsProxy", "set_ssl_policy", key, targetHttpsProxy.Version, targetHttpsProxy.Description, func() string { return "sslPolicy " + SslPolicyLink })
 
 	// Set name in case it is not present in the key
 	key.Name = targetHttpsProxy.Name
@@ -148,10 +170,17 @@ func SetSslPolicyForTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, targetH
 }
 
 // SetUrlMapForTargetHttpProxy() sets the url map for a target proxy
-func SetUrlMapForTargetHttpProxy(gceCloud *gce.Cloud, key *meta.Key, targetHttpProxy *TargetHttpProxy, urlMapLink string) error {
-	ctx, cancel := cloud.ContextWithCallTimeout()
+func SetUrlMapForTargetHttpProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, targetHttpProxy *TargetHttpProxy, urlMapLink string) error {
+	return withTransientRetry(ctx, "SetUrlMapForTargetHttpProxy "+key.Name, func() error {
+		return setUrlMapForTargetHttpProxy(ctx, gceCloud, key, targetHttpProxy, urlMapLink)
+	})
+}
+
+func setUrlMapForTargetHttpProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, targetHttpProxy *TargetHttpProxy, urlMapLink string) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := metrics.NewMetricContext("TargetHttpProxy", "set_url_map", key.Region, key.Zone, string(targetHttpProxy.Version))
+	mc := newAuditedCall(metrics.NewMetricContext("TargetHttpProxy", "set_url_map", key.Region, key.Zone, string(targetHttpProxy.Version)),
+		"TargetHttpProxy", "set_url_map", key, targetHttpProxy.Version, targetHttpProxy.Description, func() string { return "urlMap " + urlMapLink })
 
 	// Set name in case it is not present in the key
 	key.Name = targetHttpProxy.Name
@@ -186,10 +215,17 @@ func SetUrlMapForTargetHttpProxy(gceCloud *gce.Cloud, key *meta.Key, targetHttpP
 }
 
 // SetProxyForForwardingRule() sets the target proxy for a forwarding rule
-func SetProxyForForwardingRule(gceCloud *gce.Cloud, key *meta.Key, forwardingRule *ForwardingRule, targetProxyLink string) error {
-	ctx, cancel := cloud.ContextWithCallTimeout()
+func SetProxyForForwardingRule(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, forwardingRule *ForwardingRule, targetProxyLink string) error {
+	return withTransientRetry(ctx, "SetProxyForForwardingRule "+key.Name, func() error {
+		return setProxyForForwardingRule(ctx, gceCloud, key, forwardingRule, targetProxyLink)
+	})
+}
+
+func setProxyForForwardingRule(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, forwardingRule *ForwardingRule, targetProxyLink string) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := metrics.NewMetricContext("ForwardingRule", "set_proxy", key.Region, key.Zone, string(forwardingRule.Version))
+	mc := newAuditedCall(metrics.NewMetricContext("ForwardingRule", "set_proxy", key.Region, key.Zone, string(forwardingRule.Version)),
+		"ForwardingRule", "set_proxy", key, forwardingRule.Version, forwardingRule.Description, func() string { return "target " + targetProxyLink })
 
 	// Set name in case it is not present in the key
 	key.Name = forwardingRule.Name
@@ -224,15 +260,22 @@ func SetProxyForForwardingRule(gceCloud *gce.Cloud, key *meta.Key, forwardingRul
 }
 
 // SetSecurityPolicy sets the cloud armor security policy for a backend service.
-func SetSecurityPolicy(gceCloud *gce.Cloud, backendService *BackendService, securityPolicy string) error {
+func SetSecurityPolicy(ctx context.Context, gceCloud *gce.Cloud, backendService *BackendService, securityPolicy string) error {
+	return withTransientRetry(ctx, "SetSecurityPolicy "+backendService.Name, func() error {
+		return setSecurityPolicy(ctx, gceCloud, backendService, securityPolicy)
+	})
+}
+
+func setSecurityPolicy(ctx context.Context, gceCloud *gce.Cloud, backendService *BackendService, securityPolicy string) error {
 	key := meta.GlobalKey(backendService.Name)
 	if backendService.Scope != meta.Global {
 		return fmt.Errorf("cloud armor security policies not supported for %s backend service %s", backendService.Scope, backendService.Name)
 	}
 
-	ctx, cancel := cloud.ContextWithCallTimeout()
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := metrics.NewMetricContext("BackendService", "set_security_policy", key.Region, key.Zone, string(backendService.Version))
+	mc := newAuditedCall(metrics.NewMetricContext("BackendService", "set_security_policy", key.Region, key.Zone, string(backendService.Version)),
+		"BackendService", "set_security_policy", key, backendService.Version, "", func() string { return fmt.Sprintf("securityPolicy %q", securityPolicy) })
 
 	switch backendService.Version {
 	case meta.VersionAlpha:
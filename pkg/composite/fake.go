@@ -0,0 +1,751 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/filter"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/googleapi"
+)
+
+// Operation names understood by Fake hooks. Resource names are the composite
+// type names, e.g. "BackendService". Both match the labels used by the
+// composite metrics.
+const (
+	FakeOpCreate            = "create"
+	FakeOpUpdate            = "update"
+	FakeOpDelete            = "delete"
+	FakeOpGet               = "get"
+	FakeOpList              = "list"
+	FakeOpSetUrlMap         = "set_url_map"
+	FakeOpSetSslCertificate = "set_ssl_certificate"
+	FakeOpSetSslPolicy      = "set_ssl_policy"
+	FakeOpSetProxy          = "set_proxy"
+	FakeOpSetSecurityPolicy = "set_security_policy"
+)
+
+// FakeHook is invoked before a Fake operation is applied. A non-nil error
+// fails the operation with that error and leaves the store untouched.
+type FakeHook func(key *meta.Key) error
+
+// fakeOperation identifies an operation on a resource type.
+type fakeOperation struct {
+	resource string
+	op       string
+}
+
+// fakeObjectKey identifies a stored object. The scope of an object is
+// carried by its key.
+type fakeObjectKey struct {
+	resource string
+	key      meta.Key
+}
+
+// Fake is an in-memory implementation of Cloud for unit tests.
+//
+// Objects are stored by resource type and key, so the same name can exist
+// independently in different scopes. As in GCE, an object is visible
+// through every API version; reads drop the fields the requested version
+// does not have and report the version and a self link for it. Unlike the mocks behind gce.NewFakeGCECloud, updates
+// need no extra hooks and every operation can be failed through SetHook
+// or InjectError.
+type Fake struct {
+	projectID string
+	region    string
+
+	lock    sync.Mutex
+	objects map[fakeObjectKey]interface{}
+	hooks   map[fakeOperation]FakeHook
+	calls   map[fakeOperation]int
+}
+
+var _ Cloud = (*Fake)(nil)
+
+// NewFake returns an empty Fake for the given project and region.
+func NewFake(projectID, region string) *Fake {
+	return &Fake{
+		projectID: projectID,
+		region:    region,
+		objects:   make(map[fakeObjectKey]interface{}),
+		hooks:     make(map[fakeOperation]FakeHook),
+		calls:     make(map[fakeOperation]int),
+	}
+}
+
+// SetHook installs hook for op on resource, replacing any previous hook.
+// A nil hook removes it.
+func (f *Fake) SetHook(resource, op string, hook FakeHook) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if hook == nil {
+		delete(f.hooks, fakeOperation{resource, op})
+		return
+	}
+	f.hooks[fakeOperation{resource, op}] = hook
+}
+
+// InjectError makes every subsequent op on resource fail with err.
+func (f *Fake) InjectError(resource, op string, err error) {
+	f.SetHook(resource, op, func(*meta.Key) error { return err })
+}
+
+// Calls returns the number of times op was invoked on resource, including
+// calls that failed.
+func (f *Fake) Calls(resource, op string) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.calls[fakeOperation{resource, op}]
+}
+
+// CreateKey implements Cloud.
+func (f *Fake) CreateKey(name string, scope meta.KeyType) (*meta.Key, error) {
+	switch scope {
+	case meta.Regional:
+		if f.region == "" {
+			return nil, fmt.Errorf("error getting region")
+		}
+		return meta.RegionalKey(name, f.region), nil
+	case meta.Global:
+		return meta.GlobalKey(name), nil
+	}
+	return nil, fmt.Errorf("invalid resource type: %s", scope)
+}
+
+// begin records a call and runs its hook. The hook runs without the lock
+// held so that it may call back into the Fake. Like a GCE call, the call
+// fails if ctx is done.
+func (f *Fake) begin(ctx context.Context, resource, op string, key *meta.Key) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.lock.Lock()
+	f.calls[fakeOperation{resource, op}]++
+	hook := f.hooks[fakeOperation{resource, op}]
+	f.lock.Unlock()
+	if hook == nil {
+		return nil
+	}
+	return hook(key)
+}
+
+func (f *Fake) selfLink(version meta.Version, collection string, key *meta.Key) string {
+	return cloud.SelfLink(version, f.projectID, collection, key)
+}
+
+// scopedKey returns the key of an object named name in the scope of key.
+func (f *Fake) scopedKey(key *meta.Key, name string) *meta.Key {
+	k := *key
+	k.Name = name
+	return &k
+}
+
+// store saves a deep copy of obj. The caller must hold the lock.
+func (f *Fake) store(resource string, key *meta.Key, obj interface{}) error {
+	stored := reflect.New(reflect.TypeOf(obj).Elem()).Interface()
+	if err := copyViaJSON(stored, obj); err != nil {
+		return err
+	}
+	f.objects[fakeObjectKey{resource, *key}] = stored
+	return nil
+}
+
+func (f *Fake) insert(ctx context.Context, resource string, key *meta.Key, obj interface{}) error {
+	if err := f.begin(ctx, resource, FakeOpCreate, key); err != nil {
+		return err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.objects[fakeObjectKey{resource, *key}]; ok {
+		return &googleapi.Error{Code: http.StatusConflict, Message: fmt.Sprintf("%s %v already exists", resource, key)}
+	}
+	return f.store(resource, key, obj)
+}
+
+func (f *Fake) update(ctx context.Context, resource string, key *meta.Key, obj interface{}) error {
+	if err := f.begin(ctx, resource, FakeOpUpdate, key); err != nil {
+		return err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.objects[fakeObjectKey{resource, *key}]; !ok {
+		return fakeNotFound(resource, key)
+	}
+	return f.store(resource, key, obj)
+}
+
+// modify loads the stored object into obj, applies mutate and stores the
+// result.
+func (f *Fake) modify(ctx context.Context, resource, op string, key *meta.Key, obj interface{}, mutate func()) error {
+	if err := f.begin(ctx, resource, op, key); err != nil {
+		return err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	stored, ok := f.objects[fakeObjectKey{resource, *key}]
+	if !ok {
+		return fakeNotFound(resource, key)
+	}
+	if err := copyViaJSON(obj, stored); err != nil {
+		return err
+	}
+	mutate()
+	return f.store(resource, key, obj)
+}
+
+func (f *Fake) delete(ctx context.Context, resource string, key *meta.Key) error {
+	if err := f.begin(ctx, resource, FakeOpDelete, key); err != nil {
+		return err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.objects[fakeObjectKey{resource, *key}]; !ok {
+		return fakeNotFound(resource, key)
+	}
+	delete(f.objects, fakeObjectKey{resource, *key})
+	return nil
+}
+
+func (f *Fake) get(ctx context.Context, resource string, version meta.Version, key *meta.Key, obj interface{}) error {
+	if err := f.begin(ctx, resource, FakeOpGet, key); err != nil {
+		return err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	stored, ok := f.objects[fakeObjectKey{resource, *key}]
+	if !ok {
+		return fakeNotFound(resource, key)
+	}
+	return readVersion(obj, stored, version)
+}
+
+// list copies every object of resource in the scope of key, ordered by
+// name and read through version, into objects returned by newObj.
+func (f *Fake) list(ctx context.Context, resource string, version meta.Version, key *meta.Key, newObj func() interface{}) error {
+	if err := f.begin(ctx, resource, FakeOpList, key); err != nil {
+		return err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var keys []fakeObjectKey
+	for k := range f.objects {
+		if k.resource == resource && k.key.Type() == key.Type() && k.key.Region == key.Region && k.key.Zone == key.Zone {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].key.Name < keys[j].key.Name })
+	for _, k := range keys {
+		if err := readVersion(newObj(), f.objects[k], version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readVersion copies the stored object into obj, dropping the fields which
+// version does not have, as GCE does when an object is read through an
+// older API version. An unset version is GA.
+func readVersion(obj, stored interface{}, version meta.Version) error {
+	convert := "ToGA"
+	switch version {
+	case meta.VersionAlpha:
+		convert = "ToAlpha"
+	case meta.VersionBeta:
+		convert = "ToBeta"
+	}
+	out := reflect.ValueOf(stored).MethodByName(convert).Call(nil)
+	if err, _ := out[1].Interface().(error); err != nil {
+		return err
+	}
+	return copyViaJSON(obj, out[0].Interface())
+}
+
+func fakeNotFound(resource string, key *meta.Key) error {
+	return &googleapi.Error{Code: http.StatusNotFound, Message: fmt.Sprintf("%s %v not found", resource, key)}
+}
+
+// SetSecurityPolicy implements Cloud.
+func (f *Fake) SetSecurityPolicy(ctx context.Context, backendService *BackendService, securityPolicy string) error {
+	key := meta.GlobalKey(backendService.Name)
+	if backendService.Scope != meta.Global {
+		return fmt.Errorf("cloud armor security policies not supported for %s backend service %s", backendService.Scope, backendService.Name)
+	}
+	var link string
+	if securityPolicy != "" {
+		link = f.selfLink(backendService.Version, "securityPolicies", meta.GlobalKey(securityPolicy))
+	}
+	obj := &BackendService{}
+	return f.modify(ctx, "BackendService", FakeOpSetSecurityPolicy, key, obj, func() { obj.SecurityPolicy = link })
+}
+
+// SetProxyForForwardingRule implements Cloud.
+func (f *Fake) SetProxyForForwardingRule(ctx context.Context, key *meta.Key, forwardingRule *ForwardingRule, targetProxyLink string) error {
+	key = f.scopedKey(key, forwardingRule.Name)
+	obj := &ForwardingRule{}
+	return f.modify(ctx, "ForwardingRule", FakeOpSetProxy, key, obj, func() { obj.Target = targetProxyLink })
+}
+
+// SetUrlMapForTargetHttpProxy implements Cloud.
+func (f *Fake) SetUrlMapForTargetHttpProxy(ctx context.Context, key *meta.Key, targetHttpProxy *TargetHttpProxy, urlMapLink string) error {
+	key = f.scopedKey(key, targetHttpProxy.Name)
+	obj := &TargetHttpProxy{}
+	return f.modify(ctx, "TargetHttpProxy", FakeOpSetUrlMap, key, obj, func() { obj.UrlMap = urlMapLink })
+}
+
+// SetUrlMapForTargetHttpsProxy implements Cloud.
+func (f *Fake) SetUrlMapForTargetHttpsProxy(ctx context.Context, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, urlMapLink string) error {
+	key = f.scopedKey(key, targetHttpsProxy.Name)
+	obj := &TargetHttpsProxy{}
+	return f.modify(ctx, "TargetHttpsProxy", FakeOpSetUrlMap, key, obj, func() { obj.UrlMap = urlMapLink })
+}
+
+// SetSslCertificateForTargetHttpsProxy implements Cloud.
+func (f *Fake) SetSslCertificateForTargetHttpsProxy(ctx context.Context, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, sslCertURLs []string) error {
+	key = f.scopedKey(key, targetHttpsProxy.Name)
+	obj := &TargetHttpsProxy{}
+	return f.modify(ctx, "TargetHttpsProxy", FakeOpSetSslCertificate, key, obj, func() { obj.SslCertificates = sslCertURLs })
+}
+
+// SetSslPolicyForTargetHttpsProxy implements Cloud.
+func (f *Fake) SetSslPolicyForTargetHttpsProxy(ctx context.Context, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, sslPolicyLink string) error {
+	key = f.scopedKey(key, targetHttpsProxy.Name)
+	obj := &TargetHttpsProxy{}
+	return f.modify(ctx, "TargetHttpsProxy", FakeOpSetSslPolicy, key, obj, func() { obj.SslPolicy = sslPolicyLink })
+}
+
+// CreateAddress implements Cloud.
+func (f *Fake) CreateAddress(ctx context.Context, key *meta.Key, address *Address) error {
+	obj := *address
+	obj.Name = key.Name
+	obj.SelfLink = f.selfLink(address.Version, "addresses", key)
+	return f.insert(ctx, "Address", key, &obj)
+}
+
+// DeleteAddress implements Cloud.
+func (f *Fake) DeleteAddress(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return f.delete(ctx, "Address", key)
+}
+
+// GetAddress implements Cloud.
+func (f *Fake) GetAddress(ctx context.Context, key *meta.Key, version meta.Version) (*Address, error) {
+	obj := &Address{}
+	if err := f.get(ctx, "Address", version, key, obj); err != nil {
+		return nil, err
+	}
+	obj.Version = version
+	obj.SelfLink = f.selfLink(version, "addresses", key)
+	return obj, nil
+}
+
+// ListAddresses implements Cloud.
+func (f *Fake) ListAddresses(ctx context.Context, key *meta.Key, version meta.Version) ([]*Address, error) {
+	result := []*Address{}
+	err := f.list(ctx, "Address", version, key, func() interface{} {
+		obj := &Address{}
+		result = append(result, obj)
+		return obj
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range result {
+		obj.Version = version
+		obj.SelfLink = f.selfLink(version, "addresses", f.scopedKey(key, obj.Name))
+	}
+	return result, nil
+}
+
+// CreateBackendService implements Cloud.
+func (f *Fake) CreateBackendService(ctx context.Context, key *meta.Key, backendService *BackendService) error {
+	obj := *backendService
+	obj.Name = key.Name
+	obj.SelfLink = f.selfLink(backendService.Version, "backendServices", key)
+	return f.insert(ctx, "BackendService", key, &obj)
+}
+
+// UpdateBackendService implements Cloud.
+func (f *Fake) UpdateBackendService(ctx context.Context, key *meta.Key, backendService *BackendService) error {
+	obj := *backendService
+	obj.Name = key.Name
+	obj.SelfLink = f.selfLink(backendService.Version, "backendServices", key)
+	return f.update(ctx, "BackendService", key, &obj)
+}
+
+// DeleteBackendService implements Cloud.
+func (f *Fake) DeleteBackendService(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return f.delete(ctx, "BackendService", key)
+}
+
+// GetBackendService implements Cloud.
+func (f *Fake) GetBackendService(ctx context.Context, key *meta.Key, version meta.Version) (*BackendService, error) {
+	obj := &BackendService{}
+	if err := f.get(ctx, "BackendService", version, key, obj); err != nil {
+		return nil, err
+	}
+	obj.Version = version
+	obj.SelfLink = f.selfLink(version, "backendServices", key)
+	return obj, nil
+}
+
+// ListBackendServices implements Cloud.
+func (f *Fake) ListBackendServices(ctx context.Context, key *meta.Key, version meta.Version) ([]*BackendService, error) {
+	result := []*BackendService{}
+	err := f.list(ctx, "BackendService", version, key, func() interface{} {
+		obj := &BackendService{}
+		result = append(result, obj)
+		return obj
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range result {
+		obj.Version = version
+		obj.SelfLink = f.selfLink(version, "backendServices", f.scopedKey(key, obj.Name))
+	}
+	return result, nil
+}
+
+// ListBackendServicesWithFilter implements Cloud.
+func (f *Fake) ListBackendServicesWithFilter(ctx context.Context, key *meta.Key, version meta.Version, fl *filter.F) ([]*BackendService, error) {
+	all, err := f.ListBackendServices(ctx, key, version)
+	if err != nil {
+		return nil, err
+	}
+	result := []*BackendService{}
+	for _, obj := range all {
+		if fl.Match(obj) {
+			result = append(result, obj)
+		}
+	}
+	return result, nil
+}
+
+// CreateForwardingRule implements Cloud.
+func (f *Fake) CreateForwardingRule(ctx context.Context, key *meta.Key, forwardingRule *ForwardingRule) error {
+	obj := *forwardingRule
+	obj.Name = key.Name
+	obj.SelfLink = f.selfLink(forwardingRule.Version, "forwardingRules", key)
+	return f.insert(ctx, "ForwardingRule", key, &obj)
+}
+
+// DeleteForwardingRule implements Cloud.
+func (f *Fake) DeleteForwardingRule(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return f.delete(ctx, "ForwardingRule", key)
+}
+
+// GetForwardingRule implements Cloud.
+func (f *Fake) GetForwardingRule(ctx context.Context, key *meta.Key, version meta.Version) (*ForwardingRule, error) {
+	obj := &ForwardingRule{}
+	if err := f.get(ctx, "ForwardingRule", version, key, obj); err != nil {
+		return nil, err
+	}
+	obj.Version = version
+	obj.SelfLink = f.selfLink(version, "forwardingRules", key)
+	return obj, nil
+}
+
+// ListForwardingRules implements Cloud.
+func (f *Fake) ListForwardingRules(ctx context.Context, key *meta.Key, version meta.Version) ([]*ForwardingRule, error) {
+	result := []*ForwardingRule{}
+	err := f.list(ctx, "ForwardingRule", version, key, func() interface{} {
+		obj := &ForwardingRule{}
+		result = append(result, obj)
+		return obj
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range result {
+		obj.Version = version
+		obj.SelfLink = f.selfLink(version, "forwardingRules", f.scopedKey(key, obj.Name))
+	}
+	return result, nil
+}
+
+// CreateHealthCheck implements Cloud.
+func (f *Fake) CreateHealthCheck(ctx context.Context, key *meta.Key, healthCheck *HealthCheck) error {
+	obj := *healthCheck
+	obj.Name = key.Name
+	obj.SelfLink = f.selfLink(healthCheck.Version, "healthChecks", key)
+	return f.insert(ctx, "HealthCheck", key, &obj)
+}
+
+// UpdateHealthCheck implements Cloud.
+func (f *Fake) UpdateHealthCheck(ctx context.Context, key *meta.Key, healthCheck *HealthCheck) error {
+	obj := *healthCheck
+	obj.Name = key.Name
+	obj.SelfLink = f.selfLink(healthCheck.Version, "healthChecks", key)
+	return f.update(ctx, "HealthCheck", key, &obj)
+}
+
+// DeleteHealthCheck implements Cloud.
+func (f *Fake) DeleteHealthCheck(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return f.delete(ctx, "HealthCheck", key)
+}
+
+// GetHealthCheck implements Cloud.
+func (f *Fake) GetHealthCheck(ctx context.Context, key *meta.Key, version meta.Version) (*HealthCheck, error) {
+	obj := &HealthCheck{}
+	if err := f.get(ctx, "HealthCheck", version, key, obj); err != nil {
+		return nil, err
+	}
+	obj.Version = version
+	obj.SelfLink = f.selfLink(version, "healthChecks", key)
+	return obj, nil
+}
+
+// ListHealthChecks implements Cloud.
+func (f *Fake) ListHealthChecks(ctx context.Context, key *meta.Key, version meta.Version) ([]*HealthCheck, error) {
+	result := []*HealthCheck{}
+	err := f.list(ctx, "HealthCheck", version, key, func() interface{} {
+		obj := &HealthCheck{}
+		result = append(result, obj)
+		return obj
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range result {
+		obj.Version = version
+		obj.SelfLink = f.selfLink(version, "healthChecks", f.scopedKey(key, obj.Name))
+	}
+	return result, nil
+}
+
+// CreateSslCertificate implements Cloud.
+func (f *Fake) CreateSslCertificate(ctx context.Context, key *meta.Key, sslCertificate *SslCertificate) error {
+	obj := *sslCertificate
+	obj.Name = key.Name
+	obj.SelfLink = f.selfLink(sslCertificate.Version, "sslCertificates", key)
+	return f.insert(ctx, "SslCertificate", key, &obj)
+}
+
+// DeleteSslCertificate implements Cloud.
+func (f *Fake) DeleteSslCertificate(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return f.delete(ctx, "SslCertificate", key)
+}
+
+// GetSslCertificate implements Cloud.
+func (f *Fake) GetSslCertificate(ctx context.Context, key *meta.Key, version meta.Version) (*SslCertificate, error) {
+	obj := &SslCertificate{}
+	if err := f.get(ctx, "SslCertificate", version, key, obj); err != nil {
+		return nil, err
+	}
+	obj.Version = version
+	obj.SelfLink = f.selfLink(version, "sslCertificates", key)
+	return obj, nil
+}
+
+// GetSslCertificateOfProject implements Cloud. Certificates of other
+// projects are added with AddSslCertificateOfProject.
+func (f *Fake) GetSslCertificateOfProject(ctx context.Context, project string, key *meta.Key) (*SslCertificate, error) {
+	if project == f.projectID {
+		return f.GetSslCertificate(ctx, key, meta.VersionGA)
+	}
+	obj := &SslCertificate{}
+	if err := f.get(ctx, fakeProjectResource("SslCertificate", project), meta.VersionGA, key, obj); err != nil {
+		return nil, err
+	}
+	obj.Version = meta.VersionGA
+	obj.SelfLink = cloud.SelfLink(meta.VersionGA, project, "sslCertificates", key)
+	return obj, nil
+}
+
+// AddSslCertificateOfProject adds a GA SslCertificate to another project.
+func (f *Fake) AddSslCertificateOfProject(project string, key *meta.Key, sslCertificate *SslCertificate) error {
+	return f.insert(context.TODO(), fakeProjectResource("SslCertificate", project), key, sslCertificate)
+}
+
+// fakeProjectResource returns the resource under which the objects of
+// another project are stored.
+func fakeProjectResource(resource, project string) string {
+	return fmt.Sprintf("%s:%s", resource, project)
+}
+
+// ListSslCertificates implements Cloud.
+func (f *Fake) ListSslCertificates(ctx context.Context, key *meta.Key, version meta.Version) ([]*SslCertificate, error) {
+	result := []*SslCertificate{}
+	err := f.list(ctx, "SslCertificate", version, key, func() interface{} {
+		obj := &SslCertificate{}
+		result = append(result, obj)
+		return obj
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range result {
+		obj.Version = version
+		obj.SelfLink = f.selfLink(version, "sslCertificates", f.scopedKey(key, obj.Name))
+	}
+	return result, nil
+}
+
+// CreateTargetHttpProxy implements Cloud.
+func (f *Fake) CreateTargetHttpProxy(ctx context.Context, key *meta.Key, targetHttpProxy *TargetHttpProxy) error {
+	obj := *targetHttpProxy
+	obj.Name = key.Name
+	obj.SelfLink = f.selfLink(targetHttpProxy.Version, "targetHttpProxies", key)
+	return f.insert(ctx, "TargetHttpProxy", key, &obj)
+}
+
+// DeleteTargetHttpProxy implements Cloud.
+func (f *Fake) DeleteTargetHttpProxy(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return f.delete(ctx, "TargetHttpProxy", key)
+}
+
+// GetTargetHttpProxy implements Cloud.
+func (f *Fake) GetTargetHttpProxy(ctx context.Context, key *meta.Key, version meta.Version) (*TargetHttpProxy, error) {
+	obj := &TargetHttpProxy{}
+	if err := f.get(ctx, "TargetHttpProxy", version, key, obj); err != nil {
+		return nil, err
+	}
+	obj.Version = version
+	obj.SelfLink = f.selfLink(version, "targetHttpProxies", key)
+	return obj, nil
+}
+
+// ListTargetHttpProxies implements Cloud.
+func (f *Fake) ListTargetHttpProxies(ctx context.Context, key *meta.Key, version meta.Version) ([]*TargetHttpProxy, error) {
+	result := []*TargetHttpProxy{}
+	err := f.list(ctx, "TargetHttpProxy", version, key, func() interface{} {
+		obj := &TargetHttpProxy{}
+		result = append(result, obj)
+		return obj
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range result {
+		obj.Version = version
+		obj.SelfLink = f.selfLink(version, "targetHttpProxies", f.scopedKey(key, obj.Name))
+	}
+	return result, nil
+}
+
+// CreateTargetHttpsProxy implements Cloud.
+func (f *Fake) CreateTargetHttpsProxy(ctx context.Context, key *meta.Key, targetHttpsProxy *TargetHttpsProxy) error {
+	obj := *targetHttpsProxy
+	obj.Name = key.Name
+	obj.SelfLink = f.selfLink(targetHttpsProxy.Version, "targetHttpsProxies", key)
+	return f.insert(ctx, "TargetHttpsProxy", key, &obj)
+}
+
+// DeleteTargetHttpsProxy implements Cloud.
+func (f *Fake) DeleteTargetHttpsProxy(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return f.delete(ctx, "TargetHttpsProxy", key)
+}
+
+// GetTargetHttpsProxy implements Cloud.
+func (f *Fake) GetTargetHttpsProxy(ctx context.Context, key *meta.Key, version meta.Version) (*TargetHttpsProxy, error) {
+	obj := &TargetHttpsProxy{}
+	if err := f.get(ctx, "TargetHttpsProxy", version, key, obj); err != nil {
+		return nil, err
+	}
+	obj.Version = version
+	obj.SelfLink = f.selfLink(version, "targetHttpsProxies", key)
+	return obj, nil
+}
+
+// ListTargetHttpsProxies implements Cloud.
+func (f *Fake) ListTargetHttpsProxies(ctx context.Context, key *meta.Key, version meta.Version) ([]*TargetHttpsProxy, error) {
+	result := []*TargetHttpsProxy{}
+	err := f.list(ctx, "TargetHttpsProxy", version, key, func() interface{} {
+		obj := &TargetHttpsProxy{}
+		result = append(result, obj)
+		return obj
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range result {
+		obj.Version = version
+		obj.SelfLink = f.selfLink(version, "targetHttpsProxies", f.scopedKey(key, obj.Name))
+	}
+	return result, nil
+}
+
+// CreateUrlMap implements Cloud.
+func (f *Fake) CreateUrlMap(ctx context.Context, key *meta.Key, urlMap *UrlMap) error {
+	obj := *urlMap
+	obj.Name = key.Name
+	obj.SelfLink = f.selfLink(urlMap.Version, "urlMaps", key)
+	return f.insert(ctx, "UrlMap", key, &obj)
+}
+
+// UpdateUrlMap implements Cloud.
+func (f *Fake) UpdateUrlMap(ctx context.Context, key *meta.Key, urlMap *UrlMap) error {
+	obj := *urlMap
+	obj.Name = key.Name
+	obj.SelfLink = f.selfLink(urlMap.Version, "urlMaps", key)
+	return f.update(ctx, "UrlMap", key, &obj)
+}
+
+// DeleteUrlMap implements Cloud.
+func (f *Fake) DeleteUrlMap(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return f.delete(ctx, "UrlMap", key)
+}
+
+// GetUrlMap implements Cloud.
+func (f *Fake) GetUrlMap(ctx context.Context, key *meta.Key, version meta.Version) (*UrlMap, error) {
+	obj := &UrlMap{}
+	if err := f.get(ctx, "UrlMap", version, key, obj); err != nil {
+		return nil, err
+	}
+	obj.Version = version
+	obj.SelfLink = f.selfLink(version, "urlMaps", key)
+	return obj, nil
+}
+
+// ListUrlMaps implements Cloud.
+func (f *Fake) ListUrlMaps(ctx context.Context, key *meta.Key, version meta.Version) ([]*UrlMap, error) {
+	result := []*UrlMap{}
+	err := f.list(ctx, "UrlMap", version, key, func() interface{} {
+		obj := &UrlMap{}
+		result = append(result, obj)
+		return obj
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range result {
+		obj.Version = version
+		obj.SelfLink = f.selfLink(version, "urlMaps", f.scopedKey(key, obj.Name))
+	}
+	return result, nil
+}
+
+// ListUrlMapsWithFilter implements Cloud.
+func (f *Fake) ListUrlMapsWithFilter(ctx context.Context, key *meta.Key, version meta.Version, fl *filter.F) ([]*UrlMap, error) {
+	all, err := f.ListUrlMaps(ctx, key, version)
+	if err != nil {
+		return nil, err
+	}
+	result := []*UrlMap{}
+	for _, obj := range all {
+		if fl.Match(obj) {
+			result = append(result, obj)
+		}
+	}
+	return result, nil
+}
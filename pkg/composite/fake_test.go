@@ -0,0 +1,227 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/googleapi"
+)
+
+func isHTTPCode(err error, code int) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	return ok && apiErr.Code == code
+}
+
+func TestFakeBackendService(t *testing.T) {
+	t.Parallel()
+	f := NewFake("test-project", "us-central1")
+	globalKey := meta.GlobalKey("bs")
+	regionalKey := meta.RegionalKey("bs", "us-central1")
+
+	if err := f.CreateBackendService(context.TODO(), globalKey, &BackendService{Version: meta.VersionBeta, Protocol: "HTTP"}); err != nil {
+		t.Fatalf("CreateBackendService(%v) = %v", globalKey, err)
+	}
+	if err := f.CreateBackendService(context.TODO(), globalKey, &BackendService{}); !isHTTPCode(err, http.StatusConflict) {
+		t.Errorf("CreateBackendService(%v) on existing = %v, want conflict", globalKey, err)
+	}
+	// The same name in a different scope is a different object.
+	if _, err := f.GetBackendService(context.TODO(), regionalKey, meta.VersionGA); !isHTTPCode(err, http.StatusNotFound) {
+		t.Errorf("GetBackendService(%v) = %v, want not found", regionalKey, err)
+	}
+	if err := f.CreateBackendService(context.TODO(), regionalKey, &BackendService{Protocol: "HTTP2"}); err != nil {
+		t.Fatalf("CreateBackendService(%v) = %v", regionalKey, err)
+	}
+
+	// Objects are visible through every version.
+	bs, err := f.GetBackendService(context.TODO(), globalKey, meta.VersionGA)
+	if err != nil {
+		t.Fatalf("GetBackendService(%v) = %v", globalKey, err)
+	}
+	if bs.Version != meta.VersionGA || bs.Protocol != "HTTP" || bs.Name != "bs" {
+		t.Errorf("GetBackendService(%v) = %+v, want GA HTTP backend service bs", globalKey, bs)
+	}
+	if want := "https://www.googleapis.com/compute/v1/projects/test-project/global/backendServices/bs"; bs.SelfLink != want {
+		t.Errorf("SelfLink = %q, want %q", bs.SelfLink, want)
+	}
+
+	// Mutating a returned object does not change the store.
+	bs.Protocol = "HTTPS"
+	if got, _ := f.GetBackendService(context.TODO(), globalKey, meta.VersionGA); got.Protocol != "HTTP" {
+		t.Errorf("Protocol = %q after mutating a copy, want HTTP", got.Protocol)
+	}
+	if err := f.UpdateBackendService(context.TODO(), globalKey, bs); err != nil {
+		t.Fatalf("UpdateBackendService(%v) = %v", globalKey, err)
+	}
+	if got, _ := f.GetBackendService(context.TODO(), globalKey, meta.VersionGA); got.Protocol != "HTTPS" {
+		t.Errorf("Protocol = %q after update, want HTTPS", got.Protocol)
+	}
+
+	bs.Scope = meta.Global
+	if err := f.SetSecurityPolicy(context.TODO(), bs, "policy"); err != nil {
+		t.Fatalf("SetSecurityPolicy() = %v", err)
+	}
+	if got, _ := f.GetBackendService(context.TODO(), globalKey, meta.VersionGA); got.SecurityPolicy == "" {
+		t.Errorf("SecurityPolicy not set")
+	}
+
+	list, err := f.ListBackendServices(context.TODO(), meta.RegionalKey("", "us-central1"), meta.VersionGA)
+	if err != nil || len(list) != 1 || list[0].Protocol != "HTTP2" {
+		t.Errorf("ListBackendServices(regional) = %+v, %v, want the regional backend service", list, err)
+	}
+
+	if err := f.DeleteBackendService(context.TODO(), globalKey, meta.VersionGA); err != nil {
+		t.Fatalf("DeleteBackendService(%v) = %v", globalKey, err)
+	}
+	if err := f.DeleteBackendService(context.TODO(), globalKey, meta.VersionGA); !isHTTPCode(err, http.StatusNotFound) {
+		t.Errorf("DeleteBackendService(%v) twice = %v, want not found", globalKey, err)
+	}
+	if err := f.UpdateBackendService(context.TODO(), globalKey, bs); !isHTTPCode(err, http.StatusNotFound) {
+		t.Errorf("UpdateBackendService(%v) after delete = %v, want not found", globalKey, err)
+	}
+}
+
+func TestFakeTargetProxySetters(t *testing.T) {
+	t.Parallel()
+	f := NewFake("test-project", "us-central1")
+	key := meta.GlobalKey("proxy")
+	proxy := &TargetHttpsProxy{Name: "proxy"}
+	if err := f.CreateTargetHttpsProxy(context.TODO(), key, proxy); err != nil {
+		t.Fatalf("CreateTargetHttpsProxy(%v) = %v", key, err)
+	}
+	if err := f.SetUrlMapForTargetHttpsProxy(context.TODO(), meta.GlobalKey(""), proxy, "um"); err != nil {
+		t.Fatalf("SetUrlMapForTargetHttpsProxy() = %v", err)
+	}
+	if err := f.SetSslCertificateForTargetHttpsProxy(context.TODO(), key, proxy, []string{"cert"}); err != nil {
+		t.Fatalf("SetSslCertificateForTargetHttpsProxy() = %v", err)
+	}
+	if err := f.SetSslPolicyForTargetHttpsProxy(context.TODO(), key, proxy, "policy"); err != nil {
+		t.Fatalf("SetSslPolicyForTargetHttpsProxy() = %v", err)
+	}
+	got, err := f.GetTargetHttpsProxy(context.TODO(), key, meta.VersionGA)
+	if err != nil {
+		t.Fatalf("GetTargetHttpsProxy(%v) = %v", key, err)
+	}
+	if got.UrlMap != "um" || len(got.SslCertificates) != 1 || got.SslCertificates[0] != "cert" || got.SslPolicy != "policy" {
+		t.Errorf("GetTargetHttpsProxy(%v) = %+v, want url map, certificate and policy set", key, got)
+	}
+	if err := f.SetUrlMapForTargetHttpProxy(context.TODO(), key, &TargetHttpProxy{Name: "proxy"}, "um"); !isHTTPCode(err, http.StatusNotFound) {
+		t.Errorf("SetUrlMapForTargetHttpProxy() on missing proxy = %v, want not found", err)
+	}
+}
+
+func TestFakeHooks(t *testing.T) {
+	t.Parallel()
+	f := NewFake("test-project", "us-central1")
+	key := meta.GlobalKey("um")
+	injected := fmt.Errorf("injected")
+
+	f.InjectError("UrlMap", FakeOpCreate, injected)
+	if err := f.CreateUrlMap(context.TODO(), key, &UrlMap{}); err != injected {
+		t.Errorf("CreateUrlMap() = %v, want %v", err, injected)
+	}
+	if _, err := f.GetUrlMap(context.TODO(), key, meta.VersionGA); !isHTTPCode(err, http.StatusNotFound) {
+		t.Errorf("GetUrlMap() after failed create = %v, want not found", err)
+	}
+
+	f.SetHook("UrlMap", FakeOpCreate, nil)
+	if err := f.CreateUrlMap(context.TODO(), key, &UrlMap{}); err != nil {
+		t.Fatalf("CreateUrlMap() = %v", err)
+	}
+	if got := f.Calls("UrlMap", FakeOpCreate); got != 2 {
+		t.Errorf("Calls(create) = %d, want 2", got)
+	}
+
+	// Hooks may call back into the fake, e.g. to simulate a concurrent
+	// deletion.
+	f.SetHook("UrlMap", FakeOpUpdate, func(k *meta.Key) error {
+		return f.DeleteUrlMap(context.TODO(), k, meta.VersionGA)
+	})
+	if err := f.UpdateUrlMap(context.TODO(), key, &UrlMap{}); !isHTTPCode(err, http.StatusNotFound) {
+		t.Errorf("UpdateUrlMap() = %v, want not found", err)
+	}
+}
+
+func TestFakeReadDropsFieldsOfOtherVersions(t *testing.T) {
+	t.Parallel()
+	f := NewFake("test-project", "us-central1")
+	key := meta.RegionalKey("bs", "us-central1")
+	bs := &BackendService{Version: meta.VersionAlpha, Protocol: "TCP", Subsetting: &Subsetting{Policy: "CONSISTENT_HASH_SUBSETTING"}}
+	if err := f.CreateBackendService(context.TODO(), key, bs); err != nil {
+		t.Fatalf("CreateBackendService(%v) = %v", key, err)
+	}
+
+	alpha, err := f.GetBackendService(context.TODO(), key, meta.VersionAlpha)
+	if err != nil || alpha.Subsetting == nil || alpha.Subsetting.Policy != "CONSISTENT_HASH_SUBSETTING" {
+		t.Errorf("GetBackendService(%v, alpha) = %+v, %v, want subsetting", key, alpha, err)
+	}
+	ga, err := f.GetBackendService(context.TODO(), key, meta.VersionGA)
+	if err != nil {
+		t.Fatalf("GetBackendService(%v, GA) = %v", key, err)
+	}
+	if ga.Subsetting != nil || ga.Protocol != "TCP" {
+		t.Errorf("GetBackendService(%v, GA) = %+v, want TCP backend service without subsetting", key, ga)
+	}
+}
+
+func TestFakeListWithFilter(t *testing.T) {
+	t.Parallel()
+	f := NewFake("test-project", "us-central1")
+	for _, name := range []string{"k8s-um-a", "k8s-um-b", "other"} {
+		if err := f.CreateUrlMap(context.TODO(), meta.GlobalKey(name), &UrlMap{}); err != nil {
+			t.Fatalf("CreateUrlMap(%q) = %v", name, err)
+		}
+	}
+	list, err := f.ListUrlMapsWithFilter(context.TODO(), meta.GlobalKey(""), meta.VersionGA, ListFilter("k8s-um-", ""))
+	if err != nil {
+		t.Fatalf("ListUrlMapsWithFilter() = %v", err)
+	}
+	var names []string
+	for _, um := range list {
+		names = append(names, um.Name)
+	}
+	if len(names) != 2 || names[0] != "k8s-um-a" || names[1] != "k8s-um-b" {
+		t.Errorf("ListUrlMapsWithFilter() = %v, want [k8s-um-a k8s-um-b]", names)
+	}
+}
+
+func TestFakeSslCertificateOfProject(t *testing.T) {
+	t.Parallel()
+	f := NewFake("test-project", "us-central1")
+	key := meta.GlobalKey("cert")
+	if err := f.CreateSslCertificate(context.TODO(), key, &SslCertificate{Version: meta.VersionGA}); err != nil {
+		t.Fatalf("CreateSslCertificate() = %v", err)
+	}
+	if err := f.AddSslCertificateOfProject("other-project", key, &SslCertificate{Description: "other"}); err != nil {
+		t.Fatalf("AddSslCertificateOfProject() = %v", err)
+	}
+
+	if _, err := f.GetSslCertificateOfProject(context.TODO(), "test-project", key); err != nil {
+		t.Errorf("GetSslCertificateOfProject(test-project) = %v, want nil", err)
+	}
+	cert, err := f.GetSslCertificateOfProject(context.TODO(), "other-project", key)
+	if err != nil || cert.Description != "other" {
+		t.Errorf("GetSslCertificateOfProject(other-project) = %+v, %v, want certificate of other-project", cert, err)
+	}
+	if _, err := f.GetSslCertificateOfProject(context.TODO(), "third-project", key); !isHTTPCode(err, http.StatusNotFound) {
+		t.Errorf("GetSslCertificateOfProject(third-project) = %v, want not found", err)
+	}
+}
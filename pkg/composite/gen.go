@@ -19,6 +19,7 @@ limitations under the License.
 package composite
 
 import (
+	"context"
 	"fmt"
 
 	cloudprovider "github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
@@ -3575,10 +3576,17 @@ type WeightedBackendService struct {
 	NullFields      []string `json:"-"`
 }
 
-func CreateAddress(gceCloud *gce.Cloud, key *meta.Key, address *Address) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func CreateAddress(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, address *Address) error {
+	return withTransientRetry(ctx, "CreateAddress "+key.Name, func() error {
+		return createAddress(ctx, gceCloud, key, address)
+	})
+}
+
+func createAddress(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, address *Address) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("Address", "create", key.Region, key.Zone, string(address.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("Address", "create", key.Region, key.Zone, string(address.Version)),
+		"Address", "create", key, address.Version, address.Description, nil)
 
 	switch address.Version {
 	case meta.VersionAlpha:
@@ -3626,10 +3634,17 @@ func CreateAddress(gceCloud *gce.Cloud, key *meta.Key, address *Address) error {
 	}
 }
 
-func DeleteAddress(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func DeleteAddress(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	return withTransientRetry(ctx, "DeleteAddress "+key.Name, func() error {
+		return deleteAddress(ctx, gceCloud, key, version)
+	})
+}
+
+func deleteAddress(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("Address", "delete", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("Address", "delete", key.Region, key.Zone, string(version)),
+		"Address", "delete", key, version, "", nil)
 
 	switch version {
 	case meta.VersionAlpha:
@@ -3662,8 +3677,8 @@ func DeleteAddress(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) err
 	}
 }
 
-func GetAddress(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*Address, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func GetAddress(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*Address, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("Address", "get", key.Region, key.Zone, string(version))
 
@@ -3698,8 +3713,8 @@ func GetAddress(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*Addr
 			gceObj, err = gceCloud.Compute().GlobalAddresses().Get(ctx, key)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 	compositeType, err := toAddress(gceObj)
 	if err != nil {
@@ -3712,8 +3727,14 @@ func GetAddress(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*Addr
 	return compositeType, nil
 }
 
-func ListAddresses(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*Address, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func ListAddresses(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*Address, error) {
+	return ListAddressesWithFilter(ctx, gceCloud, key, version, filter.None)
+}
+
+// ListAddressesWithFilter lists the Addresss matching fl.
+// The filter is evaluated by the GCE API, see ListFilter.
+func ListAddressesWithFilter(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, fl *filter.F) ([]*Address, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("Address", "list", key.Region, key.Zone, string(version))
 
@@ -3724,32 +3745,32 @@ func ListAddresses(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing alpha region Address")
-			gceObjs, err = gceCloud.Compute().AlphaAddresses().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaAddresses().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing alpha Address")
-			gceObjs, err = gceCloud.Compute().AlphaGlobalAddresses().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaGlobalAddresses().List(ctx, fl)
 		}
 	case meta.VersionBeta:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing beta region Address")
-			gceObjs, err = gceCloud.Compute().BetaAddresses().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaAddresses().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing beta Address")
-			gceObjs, err = gceCloud.Compute().BetaGlobalAddresses().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaGlobalAddresses().List(ctx, fl)
 		}
 	default:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing ga region Address")
-			gceObjs, err = gceCloud.Compute().Addresses().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().Addresses().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing ga Address")
-			gceObjs, err = gceCloud.Compute().GlobalAddresses().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().GlobalAddresses().List(ctx, fl)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 
 	compositeObjs, err := toAddressList(gceObjs)
@@ -3853,10 +3874,17 @@ func (address *Address) ToGA() (*compute.Address, error) {
 	return ga, nil
 }
 
-func CreateBackendService(gceCloud *gce.Cloud, key *meta.Key, backendService *BackendService) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func CreateBackendService(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, backendService *BackendService) error {
+	return withTransientRetry(ctx, "CreateBackendService "+key.Name, func() error {
+		return createBackendService(ctx, gceCloud, key, backendService)
+	})
+}
+
+func createBackendService(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, backendService *BackendService) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("BackendService", "create", key.Region, key.Zone, string(backendService.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("BackendService", "create", key.Region, key.Zone, string(backendService.Version)),
+		"BackendService", "create", key, backendService.Version, backendService.Description, nil)
 
 	switch backendService.Version {
 	case meta.VersionAlpha:
@@ -3904,10 +3932,22 @@ func CreateBackendService(gceCloud *gce.Cloud, key *meta.Key, backendService *Ba
 	}
 }
 
-func UpdateBackendService(gceCloud *gce.Cloud, key *meta.Key, backendService *BackendService) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func UpdateBackendService(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, backendService *BackendService) error {
+	return withTransientRetry(ctx, "UpdateBackendService "+key.Name, func() error {
+		return updateBackendService(ctx, gceCloud, key, backendService)
+	})
+}
+
+func updateBackendService(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, backendService *BackendService) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("BackendService", "update", key.Region, key.Zone, string(backendService.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("BackendService", "update", key.Region, key.Zone, string(backendService.Version)),
+		"BackendService", "update", key, backendService.Version, backendService.Description, func() string {
+			return auditDiff(func() (interface{}, error) {
+				existing, err := GetBackendService(ctx, gceCloud, key, backendService.Version)
+				return existing, err
+			}, backendService)
+		})
 	switch backendService.Version {
 	case meta.VersionAlpha:
 		alpha, err := backendService.ToAlpha()
@@ -3951,10 +3991,17 @@ func UpdateBackendService(gceCloud *gce.Cloud, key *meta.Key, backendService *Ba
 	}
 }
 
-func DeleteBackendService(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func DeleteBackendService(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	return withTransientRetry(ctx, "DeleteBackendService "+key.Name, func() error {
+		return deleteBackendService(ctx, gceCloud, key, version)
+	})
+}
+
+func deleteBackendService(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("BackendService", "delete", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("BackendService", "delete", key.Region, key.Zone, string(version)),
+		"BackendService", "delete", key, version, "", nil)
 
 	switch version {
 	case meta.VersionAlpha:
@@ -3987,8 +4034,8 @@ func DeleteBackendService(gceCloud *gce.Cloud, key *meta.Key, version meta.Versi
 	}
 }
 
-func GetBackendService(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*BackendService, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func GetBackendService(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*BackendService, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("BackendService", "get", key.Region, key.Zone, string(version))
 
@@ -4023,8 +4070,8 @@ func GetBackendService(gceCloud *gce.Cloud, key *meta.Key, version meta.Version)
 			gceObj, err = gceCloud.Compute().BackendServices().Get(ctx, key)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 	compositeType, err := toBackendService(gceObj)
 	if err != nil {
@@ -4034,8 +4081,14 @@ func GetBackendService(gceCloud *gce.Cloud, key *meta.Key, version meta.Version)
 	return compositeType, nil
 }
 
-func ListBackendServices(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*BackendService, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func ListBackendServices(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*BackendService, error) {
+	return ListBackendServicesWithFilter(ctx, gceCloud, key, version, filter.None)
+}
+
+// ListBackendServicesWithFilter lists the BackendServices matching fl.
+// The filter is evaluated by the GCE API, see ListFilter.
+func ListBackendServicesWithFilter(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, fl *filter.F) ([]*BackendService, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("BackendService", "list", key.Region, key.Zone, string(version))
 
@@ -4046,32 +4099,32 @@ func ListBackendServices(gceCloud *gce.Cloud, key *meta.Key, version meta.Versio
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing alpha region BackendService")
-			gceObjs, err = gceCloud.Compute().AlphaRegionBackendServices().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaRegionBackendServices().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing alpha BackendService")
-			gceObjs, err = gceCloud.Compute().AlphaBackendServices().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaBackendServices().List(ctx, fl)
 		}
 	case meta.VersionBeta:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing beta region BackendService")
-			gceObjs, err = gceCloud.Compute().BetaRegionBackendServices().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaRegionBackendServices().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing beta BackendService")
-			gceObjs, err = gceCloud.Compute().BetaBackendServices().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaBackendServices().List(ctx, fl)
 		}
 	default:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing ga region BackendService")
-			gceObjs, err = gceCloud.Compute().RegionBackendServices().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().RegionBackendServices().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing ga BackendService")
-			gceObjs, err = gceCloud.Compute().BackendServices().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().BackendServices().List(ctx, fl)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 
 	compositeObjs, err := toBackendServiceList(gceObjs)
@@ -4214,10 +4267,17 @@ func (backendService *BackendService) ToGA() (*compute.BackendService, error) {
 	return ga, nil
 }
 
-func CreateForwardingRule(gceCloud *gce.Cloud, key *meta.Key, forwardingRule *ForwardingRule) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func CreateForwardingRule(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, forwardingRule *ForwardingRule) error {
+	return withTransientRetry(ctx, "CreateForwardingRule "+key.Name, func() error {
+		return createForwardingRule(ctx, gceCloud, key, forwardingRule)
+	})
+}
+
+func createForwardingRule(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, forwardingRule *ForwardingRule) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("ForwardingRule", "create", key.Region, key.Zone, string(forwardingRule.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("ForwardingRule", "create", key.Region, key.Zone, string(forwardingRule.Version)),
+		"ForwardingRule", "create", key, forwardingRule.Version, forwardingRule.Description, nil)
 
 	switch forwardingRule.Version {
 	case meta.VersionAlpha:
@@ -4265,10 +4325,17 @@ func CreateForwardingRule(gceCloud *gce.Cloud, key *meta.Key, forwardingRule *Fo
 	}
 }
 
-func DeleteForwardingRule(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func DeleteForwardingRule(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	return withTransientRetry(ctx, "DeleteForwardingRule "+key.Name, func() error {
+		return deleteForwardingRule(ctx, gceCloud, key, version)
+	})
+}
+
+func deleteForwardingRule(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("ForwardingRule", "delete", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("ForwardingRule", "delete", key.Region, key.Zone, string(version)),
+		"ForwardingRule", "delete", key, version, "", nil)
 
 	switch version {
 	case meta.VersionAlpha:
@@ -4301,8 +4368,8 @@ func DeleteForwardingRule(gceCloud *gce.Cloud, key *meta.Key, version meta.Versi
 	}
 }
 
-func GetForwardingRule(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*ForwardingRule, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func GetForwardingRule(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*ForwardingRule, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("ForwardingRule", "get", key.Region, key.Zone, string(version))
 
@@ -4337,8 +4404,8 @@ func GetForwardingRule(gceCloud *gce.Cloud, key *meta.Key, version meta.Version)
 			gceObj, err = gceCloud.Compute().GlobalForwardingRules().Get(ctx, key)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 	compositeType, err := toForwardingRule(gceObj)
 	if err != nil {
@@ -4351,8 +4418,14 @@ func GetForwardingRule(gceCloud *gce.Cloud, key *meta.Key, version meta.Version)
 	return compositeType, nil
 }
 
-func ListForwardingRules(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*ForwardingRule, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func ListForwardingRules(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*ForwardingRule, error) {
+	return ListForwardingRulesWithFilter(ctx, gceCloud, key, version, filter.None)
+}
+
+// ListForwardingRulesWithFilter lists the ForwardingRules matching fl.
+// The filter is evaluated by the GCE API, see ListFilter.
+func ListForwardingRulesWithFilter(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, fl *filter.F) ([]*ForwardingRule, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("ForwardingRule", "list", key.Region, key.Zone, string(version))
 
@@ -4363,32 +4436,32 @@ func ListForwardingRules(gceCloud *gce.Cloud, key *meta.Key, version meta.Versio
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing alpha region ForwardingRule")
-			gceObjs, err = gceCloud.Compute().AlphaForwardingRules().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaForwardingRules().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing alpha ForwardingRule")
-			gceObjs, err = gceCloud.Compute().AlphaGlobalForwardingRules().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaGlobalForwardingRules().List(ctx, fl)
 		}
 	case meta.VersionBeta:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing beta region ForwardingRule")
-			gceObjs, err = gceCloud.Compute().BetaForwardingRules().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaForwardingRules().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing beta ForwardingRule")
-			gceObjs, err = gceCloud.Compute().BetaGlobalForwardingRules().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaGlobalForwardingRules().List(ctx, fl)
 		}
 	default:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing ga region ForwardingRule")
-			gceObjs, err = gceCloud.Compute().ForwardingRules().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().ForwardingRules().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing ga ForwardingRule")
-			gceObjs, err = gceCloud.Compute().GlobalForwardingRules().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().GlobalForwardingRules().List(ctx, fl)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 
 	compositeObjs, err := toForwardingRuleList(gceObjs)
@@ -4492,10 +4565,17 @@ func (forwardingRule *ForwardingRule) ToGA() (*compute.ForwardingRule, error) {
 	return ga, nil
 }
 
-func CreateHealthCheck(gceCloud *gce.Cloud, key *meta.Key, healthCheck *HealthCheck) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func CreateHealthCheck(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, healthCheck *HealthCheck) error {
+	return withTransientRetry(ctx, "CreateHealthCheck "+key.Name, func() error {
+		return createHealthCheck(ctx, gceCloud, key, healthCheck)
+	})
+}
+
+func createHealthCheck(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, healthCheck *HealthCheck) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("HealthCheck", "create", key.Region, key.Zone, string(healthCheck.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("HealthCheck", "create", key.Region, key.Zone, string(healthCheck.Version)),
+		"HealthCheck", "create", key, healthCheck.Version, healthCheck.Description, nil)
 
 	switch healthCheck.Version {
 	case meta.VersionAlpha:
@@ -4543,10 +4623,22 @@ func CreateHealthCheck(gceCloud *gce.Cloud, key *meta.Key, healthCheck *HealthCh
 	}
 }
 
-func UpdateHealthCheck(gceCloud *gce.Cloud, key *meta.Key, healthCheck *HealthCheck) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func UpdateHealthCheck(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, healthCheck *HealthCheck) error {
+	return withTransientRetry(ctx, "UpdateHealthCheck "+key.Name, func() error {
+		return updateHealthCheck(ctx, gceCloud, key, healthCheck)
+	})
+}
+
+func updateHealthCheck(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, healthCheck *HealthCheck) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("HealthCheck", "update", key.Region, key.Zone, string(healthCheck.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("HealthCheck", "update", key.Region, key.Zone, string(healthCheck.Version)),
+		"HealthCheck", "update", key, healthCheck.Version, healthCheck.Description, func() string {
+			return auditDiff(func() (interface{}, error) {
+				existing, err := GetHealthCheck(ctx, gceCloud, key, healthCheck.Version)
+				return existing, err
+			}, healthCheck)
+		})
 	switch healthCheck.Version {
 	case meta.VersionAlpha:
 		alpha, err := healthCheck.ToAlpha()
@@ -4590,10 +4682,17 @@ func UpdateHealthCheck(gceCloud *gce.Cloud, key *meta.Key, healthCheck *HealthCh
 	}
 }
 
-func DeleteHealthCheck(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func DeleteHealthCheck(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	return withTransientRetry(ctx, "DeleteHealthCheck "+key.Name, func() error {
+		return deleteHealthCheck(ctx, gceCloud, key, version)
+	})
+}
+
+func deleteHealthCheck(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("HealthCheck", "delete", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("HealthCheck", "delete", key.Region, key.Zone, string(version)),
+		"HealthCheck", "delete", key, version, "", nil)
 
 	switch version {
 	case meta.VersionAlpha:
@@ -4626,8 +4725,8 @@ func DeleteHealthCheck(gceCloud *gce.Cloud, key *meta.Key, version meta.Version)
 	}
 }
 
-func GetHealthCheck(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*HealthCheck, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func GetHealthCheck(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*HealthCheck, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("HealthCheck", "get", key.Region, key.Zone, string(version))
 
@@ -4662,8 +4761,8 @@ func GetHealthCheck(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*
 			gceObj, err = gceCloud.Compute().HealthChecks().Get(ctx, key)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 	compositeType, err := toHealthCheck(gceObj)
 	if err != nil {
@@ -4673,8 +4772,14 @@ func GetHealthCheck(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*
 	return compositeType, nil
 }
 
-func ListHealthChecks(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*HealthCheck, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func ListHealthChecks(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*HealthCheck, error) {
+	return ListHealthChecksWithFilter(ctx, gceCloud, key, version, filter.None)
+}
+
+// ListHealthChecksWithFilter lists the HealthChecks matching fl.
+// The filter is evaluated by the GCE API, see ListFilter.
+func ListHealthChecksWithFilter(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, fl *filter.F) ([]*HealthCheck, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("HealthCheck", "list", key.Region, key.Zone, string(version))
 
@@ -4685,32 +4790,32 @@ func ListHealthChecks(gceCloud *gce.Cloud, key *meta.Key, version meta.Version)
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing alpha region HealthCheck")
-			gceObjs, err = gceCloud.Compute().AlphaRegionHealthChecks().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaRegionHealthChecks().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing alpha HealthCheck")
-			gceObjs, err = gceCloud.Compute().AlphaHealthChecks().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaHealthChecks().List(ctx, fl)
 		}
 	case meta.VersionBeta:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing beta region HealthCheck")
-			gceObjs, err = gceCloud.Compute().BetaRegionHealthChecks().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaRegionHealthChecks().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing beta HealthCheck")
-			gceObjs, err = gceCloud.Compute().BetaHealthChecks().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaHealthChecks().List(ctx, fl)
 		}
 	default:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing ga region HealthCheck")
-			gceObjs, err = gceCloud.Compute().RegionHealthChecks().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().RegionHealthChecks().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing ga HealthCheck")
-			gceObjs, err = gceCloud.Compute().HealthChecks().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().HealthChecks().List(ctx, fl)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 
 	compositeObjs, err := toHealthCheckList(gceObjs)
@@ -4996,10 +5101,17 @@ func (networkEndpoint *NetworkEndpoint) ToGA() (*compute.NetworkEndpoint, error)
 	return ga, nil
 }
 
-func CreateNetworkEndpointGroup(gceCloud *gce.Cloud, key *meta.Key, networkEndpointGroup *NetworkEndpointGroup) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func CreateNetworkEndpointGroup(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, networkEndpointGroup *NetworkEndpointGroup) error {
+	return withTransientRetry(ctx, "CreateNetworkEndpointGroup "+key.Name, func() error {
+		return createNetworkEndpointGroup(ctx, gceCloud, key, networkEndpointGroup)
+	})
+}
+
+func createNetworkEndpointGroup(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, networkEndpointGroup *NetworkEndpointGroup) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("NetworkEndpointGroup", "create", key.Region, key.Zone, string(networkEndpointGroup.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("NetworkEndpointGroup", "create", key.Region, key.Zone, string(networkEndpointGroup.Version)),
+		"NetworkEndpointGroup", "create", key, networkEndpointGroup.Version, networkEndpointGroup.Description, nil)
 	switch key.Type() {
 	case meta.Zonal:
 	default:
@@ -5031,10 +5143,17 @@ func CreateNetworkEndpointGroup(gceCloud *gce.Cloud, key *meta.Key, networkEndpo
 	}
 }
 
-func DeleteNetworkEndpointGroup(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func DeleteNetworkEndpointGroup(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	return withTransientRetry(ctx, "DeleteNetworkEndpointGroup "+key.Name, func() error {
+		return deleteNetworkEndpointGroup(ctx, gceCloud, key, version)
+	})
+}
+
+func deleteNetworkEndpointGroup(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("NetworkEndpointGroup", "delete", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("NetworkEndpointGroup", "delete", key.Region, key.Zone, string(version)),
+		"NetworkEndpointGroup", "delete", key, version, "", nil)
 	switch key.Type() {
 	case meta.Zonal:
 	default:
@@ -5054,8 +5173,8 @@ func DeleteNetworkEndpointGroup(gceCloud *gce.Cloud, key *meta.Key, version meta
 	}
 }
 
-func GetNetworkEndpointGroup(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*NetworkEndpointGroup, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func GetNetworkEndpointGroup(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*NetworkEndpointGroup, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("NetworkEndpointGroup", "get", key.Region, key.Zone, string(version))
 
@@ -5078,8 +5197,8 @@ func GetNetworkEndpointGroup(gceCloud *gce.Cloud, key *meta.Key, version meta.Ve
 		klog.V(3).Infof("Getting ga zonal NetworkEndpointGroup %v", key.Name)
 		gceObj, err = gceCloud.Compute().NetworkEndpointGroups().Get(ctx, key)
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 	compositeType, err := toNetworkEndpointGroup(gceObj)
 	if err != nil {
@@ -5090,8 +5209,14 @@ func GetNetworkEndpointGroup(gceCloud *gce.Cloud, key *meta.Key, version meta.Ve
 	return compositeType, nil
 }
 
-func ListNetworkEndpointGroups(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*NetworkEndpointGroup, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func ListNetworkEndpointGroups(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*NetworkEndpointGroup, error) {
+	return ListNetworkEndpointGroupsWithFilter(ctx, gceCloud, key, version, filter.None)
+}
+
+// ListNetworkEndpointGroupsWithFilter lists the NetworkEndpointGroups matching fl.
+// The filter is evaluated by the GCE API, see ListFilter.
+func ListNetworkEndpointGroupsWithFilter(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, fl *filter.F) ([]*NetworkEndpointGroup, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("NetworkEndpointGroup", "list", key.Region, key.Zone, string(version))
 
@@ -5105,16 +5230,16 @@ func ListNetworkEndpointGroups(gceCloud *gce.Cloud, key *meta.Key, version meta.
 	switch version {
 	case meta.VersionAlpha:
 		klog.V(3).Infof("Listing alpha zoneNetworkEndpointGroup")
-		gceObjs, err = gceCloud.Compute().AlphaNetworkEndpointGroups().List(ctx, key.Zone, filter.None)
+		gceObjs, err = gceCloud.Compute().AlphaNetworkEndpointGroups().List(ctx, key.Zone, fl)
 	case meta.VersionBeta:
 		klog.V(3).Infof("Listing beta zoneNetworkEndpointGroup")
-		gceObjs, err = gceCloud.Compute().BetaNetworkEndpointGroups().List(ctx, key.Zone, filter.None)
+		gceObjs, err = gceCloud.Compute().BetaNetworkEndpointGroups().List(ctx, key.Zone, fl)
 	default:
 		klog.V(3).Infof("Listing ga zoneNetworkEndpointGroup")
-		gceObjs, err = gceCloud.Compute().NetworkEndpointGroups().List(ctx, key.Zone, filter.None)
+		gceObjs, err = gceCloud.Compute().NetworkEndpointGroups().List(ctx, key.Zone, fl)
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 
 	compositeObjs, err := toNetworkEndpointGroupList(gceObjs)
@@ -5127,10 +5252,11 @@ func ListNetworkEndpointGroups(gceCloud *gce.Cloud, key *meta.Key, version meta.
 	return compositeObjs, nil
 }
 
-func AttachNetworkEndpoints(gceCloud *gce.Cloud, key *meta.Key, version meta.Version, req *NetworkEndpointGroupsAttachEndpointsRequest) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func AttachNetworkEndpoints(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, req *NetworkEndpointGroupsAttachEndpointsRequest) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("NetworkEndpointGroup", "attach", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("NetworkEndpointGroup", "attach", key.Region, key.Zone, string(version)),
+		"NetworkEndpointGroup", "attach", key, version, "", func() string { return fmt.Sprintf("attach %d endpoints", len(req.NetworkEndpoints)) })
 
 	switch key.Type() {
 	case meta.Zonal:
@@ -5163,10 +5289,11 @@ func AttachNetworkEndpoints(gceCloud *gce.Cloud, key *meta.Key, version meta.Ver
 	}
 }
 
-func DetachNetworkEndpoints(gceCloud *gce.Cloud, key *meta.Key, version meta.Version, req *NetworkEndpointGroupsDetachEndpointsRequest) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func DetachNetworkEndpoints(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, req *NetworkEndpointGroupsDetachEndpointsRequest) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("NetworkEndpointGroup", "detach", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("NetworkEndpointGroup", "detach", key.Region, key.Zone, string(version)),
+		"NetworkEndpointGroup", "detach", key, version, "", func() string { return fmt.Sprintf("detach %d endpoints", len(req.NetworkEndpoints)) })
 
 	switch key.Type() {
 	case meta.Zonal:
@@ -5199,8 +5326,8 @@ func DetachNetworkEndpoints(gceCloud *gce.Cloud, key *meta.Key, version meta.Ver
 	}
 }
 
-func ListNetworkEndpoints(gceCloud *gce.Cloud, key *meta.Key, version meta.Version, req *NetworkEndpointGroupsListEndpointsRequest) ([]*NetworkEndpointWithHealthStatus, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func ListNetworkEndpoints(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, req *NetworkEndpointGroupsListEndpointsRequest) ([]*NetworkEndpointWithHealthStatus, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("NetworkEndpointGroup", "list", key.Region, key.Zone, string(version))
 
@@ -5236,8 +5363,8 @@ func ListNetworkEndpoints(gceCloud *gce.Cloud, key *meta.Key, version meta.Versi
 		klog.V(3).Infof("Listing ga zonal NetworkEndpointGroup %v", key.Name)
 		gceObjs, err = gceCloud.Compute().NetworkEndpointGroups().ListNetworkEndpoints(ctx, key, gareq, filter.None)
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 
 	compositeObjs, err := toNetworkEndpointWithHealthStatusList(gceObjs)
@@ -5250,8 +5377,8 @@ func ListNetworkEndpoints(gceCloud *gce.Cloud, key *meta.Key, version meta.Versi
 	return compositeObjs, nil
 }
 
-func AggregatedListNetworkEndpointGroup(gceCloud *gce.Cloud, version meta.Version) (map[*meta.Key]*NetworkEndpointGroup, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func AggregatedListNetworkEndpointGroup(ctx context.Context, gceCloud *gce.Cloud, version meta.Version) (map[*meta.Key]*NetworkEndpointGroup, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("NetworkEndpointGroup", "aggregateList", "", "", string(version))
 
@@ -5262,8 +5389,8 @@ func AggregatedListNetworkEndpointGroup(gceCloud *gce.Cloud, version meta.Versio
 	case meta.VersionAlpha:
 		klog.V(3).Infof("Aggregate List of alpha zonal NetworkEndpointGroup")
 		alphaMap, err := gceCloud.Compute().AlphaNetworkEndpointGroups().AggregatedList(ctx, filter.None)
-		if err != nil {
-			return nil, mc.Observe(err)
+		if err := mc.Observe(err); err != nil {
+			return nil, err
 		}
 		// Convert from map to list
 		alphaList := []*computealpha.NetworkEndpointGroup{}
@@ -5274,8 +5401,8 @@ func AggregatedListNetworkEndpointGroup(gceCloud *gce.Cloud, version meta.Versio
 	case meta.VersionBeta:
 		klog.V(3).Infof("Aggregate List of beta zonal NetworkEndpointGroup")
 		betaMap, err := gceCloud.Compute().BetaNetworkEndpointGroups().AggregatedList(ctx, filter.None)
-		if err != nil {
-			return nil, mc.Observe(err)
+		if err := mc.Observe(err); err != nil {
+			return nil, err
 		}
 		// Convert from map to list
 		betaList := []*computebeta.NetworkEndpointGroup{}
@@ -5286,8 +5413,8 @@ func AggregatedListNetworkEndpointGroup(gceCloud *gce.Cloud, version meta.Versio
 	default:
 		klog.V(3).Infof("Aggregate List of ga zonal NetworkEndpointGroup")
 		gaMap, err := gceCloud.Compute().NetworkEndpointGroups().AggregatedList(ctx, filter.None)
-		if err != nil {
-			return nil, mc.Observe(err)
+		if err := mc.Observe(err); err != nil {
+			return nil, err
 		}
 		// Convert from map to list
 		gaList := []*compute.NetworkEndpointGroup{}
@@ -5768,10 +5895,17 @@ func (networkEndpointWithHealthStatus *NetworkEndpointWithHealthStatus) ToGA() (
 	return ga, nil
 }
 
-func CreateSslCertificate(gceCloud *gce.Cloud, key *meta.Key, sslCertificate *SslCertificate) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func CreateSslCertificate(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, sslCertificate *SslCertificate) error {
+	return withTransientRetry(ctx, "CreateSslCertificate "+key.Name, func() error {
+		return createSslCertificate(ctx, gceCloud, key, sslCertificate)
+	})
+}
+
+func createSslCertificate(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, sslCertificate *SslCertificate) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("SslCertificate", "create", key.Region, key.Zone, string(sslCertificate.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("SslCertificate", "create", key.Region, key.Zone, string(sslCertificate.Version)),
+		"SslCertificate", "create", key, sslCertificate.Version, sslCertificate.Description, nil)
 
 	switch sslCertificate.Version {
 	case meta.VersionAlpha:
@@ -5819,10 +5953,17 @@ func CreateSslCertificate(gceCloud *gce.Cloud, key *meta.Key, sslCertificate *Ss
 	}
 }
 
-func DeleteSslCertificate(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func DeleteSslCertificate(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	return withTransientRetry(ctx, "DeleteSslCertificate "+key.Name, func() error {
+		return deleteSslCertificate(ctx, gceCloud, key, version)
+	})
+}
+
+func deleteSslCertificate(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("SslCertificate", "delete", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("SslCertificate", "delete", key.Region, key.Zone, string(version)),
+		"SslCertificate", "delete", key, version, "", nil)
 
 	switch version {
 	case meta.VersionAlpha:
@@ -5855,8 +5996,8 @@ func DeleteSslCertificate(gceCloud *gce.Cloud, key *meta.Key, version meta.Versi
 	}
 }
 
-func GetSslCertificate(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*SslCertificate, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func GetSslCertificate(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*SslCertificate, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("SslCertificate", "get", key.Region, key.Zone, string(version))
 
@@ -5891,8 +6032,8 @@ func GetSslCertificate(gceCloud *gce.Cloud, key *meta.Key, version meta.Version)
 			gceObj, err = gceCloud.Compute().SslCertificates().Get(ctx, key)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 	compositeType, err := toSslCertificate(gceObj)
 	if err != nil {
@@ -5902,8 +6043,14 @@ func GetSslCertificate(gceCloud *gce.Cloud, key *meta.Key, version meta.Version)
 	return compositeType, nil
 }
 
-func ListSslCertificates(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*SslCertificate, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func ListSslCertificates(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*SslCertificate, error) {
+	return ListSslCertificatesWithFilter(ctx, gceCloud, key, version, filter.None)
+}
+
+// ListSslCertificatesWithFilter lists the SslCertificates matching fl.
+// The filter is evaluated by the GCE API, see ListFilter.
+func ListSslCertificatesWithFilter(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, fl *filter.F) ([]*SslCertificate, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("SslCertificate", "list", key.Region, key.Zone, string(version))
 
@@ -5914,32 +6061,32 @@ func ListSslCertificates(gceCloud *gce.Cloud, key *meta.Key, version meta.Versio
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing alpha region SslCertificate")
-			gceObjs, err = gceCloud.Compute().AlphaRegionSslCertificates().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaRegionSslCertificates().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing alpha SslCertificate")
-			gceObjs, err = gceCloud.Compute().AlphaSslCertificates().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaSslCertificates().List(ctx, fl)
 		}
 	case meta.VersionBeta:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing beta region SslCertificate")
-			gceObjs, err = gceCloud.Compute().BetaRegionSslCertificates().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaRegionSslCertificates().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing beta SslCertificate")
-			gceObjs, err = gceCloud.Compute().BetaSslCertificates().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaSslCertificates().List(ctx, fl)
 		}
 	default:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing ga region SslCertificate")
-			gceObjs, err = gceCloud.Compute().RegionSslCertificates().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().RegionSslCertificates().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing ga SslCertificate")
-			gceObjs, err = gceCloud.Compute().SslCertificates().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().SslCertificates().List(ctx, fl)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 
 	compositeObjs, err := toSslCertificateList(gceObjs)
@@ -6043,10 +6190,17 @@ func (sslCertificate *SslCertificate) ToGA() (*compute.SslCertificate, error) {
 	return ga, nil
 }
 
-func CreateTargetHttpProxy(gceCloud *gce.Cloud, key *meta.Key, targetHttpProxy *TargetHttpProxy) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func CreateTargetHttpProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, targetHttpProxy *TargetHttpProxy) error {
+	return withTransientRetry(ctx, "CreateTargetHttpProxy "+key.Name, func() error {
+		return createTargetHttpProxy(ctx, gceCloud, key, targetHttpProxy)
+	})
+}
+
+func createTargetHttpProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, targetHttpProxy *TargetHttpProxy) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("TargetHttpProxy", "create", key.Region, key.Zone, string(targetHttpProxy.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("TargetHttpProxy", "create", key.Region, key.Zone, string(targetHttpProxy.Version)),
+		"TargetHttpProxy", "create", key, targetHttpProxy.Version, targetHttpProxy.Description, nil)
 
 	switch targetHttpProxy.Version {
 	case meta.VersionAlpha:
@@ -6094,10 +6248,17 @@ func CreateTargetHttpProxy(gceCloud *gce.Cloud, key *meta.Key, targetHttpProxy *
 	}
 }
 
-func DeleteTargetHttpProxy(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func DeleteTargetHttpProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	return withTransientRetry(ctx, "DeleteTargetHttpProxy "+key.Name, func() error {
+		return deleteTargetHttpProxy(ctx, gceCloud, key, version)
+	})
+}
+
+func deleteTargetHttpProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("TargetHttpProxy", "delete", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("TargetHttpProxy", "delete", key.Region, key.Zone, string(version)),
+		"TargetHttpProxy", "delete", key, version, "", nil)
 
 	switch version {
 	case meta.VersionAlpha:
@@ -6130,8 +6291,8 @@ func DeleteTargetHttpProxy(gceCloud *gce.Cloud, key *meta.Key, version meta.Vers
 	}
 }
 
-func GetTargetHttpProxy(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*TargetHttpProxy, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func GetTargetHttpProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*TargetHttpProxy, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("TargetHttpProxy", "get", key.Region, key.Zone, string(version))
 
@@ -6166,8 +6327,8 @@ func GetTargetHttpProxy(gceCloud *gce.Cloud, key *meta.Key, version meta.Version
 			gceObj, err = gceCloud.Compute().TargetHttpProxies().Get(ctx, key)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 	compositeType, err := toTargetHttpProxy(gceObj)
 	if err != nil {
@@ -6177,8 +6338,14 @@ func GetTargetHttpProxy(gceCloud *gce.Cloud, key *meta.Key, version meta.Version
 	return compositeType, nil
 }
 
-func ListTargetHttpProxies(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*TargetHttpProxy, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func ListTargetHttpProxies(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*TargetHttpProxy, error) {
+	return ListTargetHttpProxiesWithFilter(ctx, gceCloud, key, version, filter.None)
+}
+
+// ListTargetHttpProxiesWithFilter lists the TargetHttpProxys matching fl.
+// The filter is evaluated by the GCE API, see ListFilter.
+func ListTargetHttpProxiesWithFilter(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, fl *filter.F) ([]*TargetHttpProxy, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("TargetHttpProxy", "list", key.Region, key.Zone, string(version))
 
@@ -6189,32 +6356,32 @@ func ListTargetHttpProxies(gceCloud *gce.Cloud, key *meta.Key, version meta.Vers
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing alpha region TargetHttpProxy")
-			gceObjs, err = gceCloud.Compute().AlphaRegionTargetHttpProxies().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaRegionTargetHttpProxies().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing alpha TargetHttpProxy")
-			gceObjs, err = gceCloud.Compute().AlphaTargetHttpProxies().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaTargetHttpProxies().List(ctx, fl)
 		}
 	case meta.VersionBeta:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing beta region TargetHttpProxy")
-			gceObjs, err = gceCloud.Compute().BetaRegionTargetHttpProxies().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaRegionTargetHttpProxies().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing beta TargetHttpProxy")
-			gceObjs, err = gceCloud.Compute().BetaTargetHttpProxies().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaTargetHttpProxies().List(ctx, fl)
 		}
 	default:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing ga region TargetHttpProxy")
-			gceObjs, err = gceCloud.Compute().RegionTargetHttpProxies().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().RegionTargetHttpProxies().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing ga TargetHttpProxy")
-			gceObjs, err = gceCloud.Compute().TargetHttpProxies().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().TargetHttpProxies().List(ctx, fl)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 
 	compositeObjs, err := toTargetHttpProxyList(gceObjs)
@@ -6318,10 +6485,17 @@ func (targetHttpProxy *TargetHttpProxy) ToGA() (*compute.TargetHttpProxy, error)
 	return ga, nil
 }
 
-func CreateTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy *TargetHttpsProxy) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func CreateTargetHttpsProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy *TargetHttpsProxy) error {
+	return withTransientRetry(ctx, "CreateTargetHttpsProxy "+key.Name, func() error {
+		return createTargetHttpsProxy(ctx, gceCloud, key, targetHttpsProxy)
+	})
+}
+
+func createTargetHttpsProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy *TargetHttpsProxy) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("TargetHttpsProxy", "create", key.Region, key.Zone, string(targetHttpsProxy.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("TargetHttpsProxy", "create", key.Region, key.Zone, string(targetHttpsProxy.Version)),
+		"TargetHttpsProxy", "create", key, targetHttpsProxy.Version, targetHttpsProxy.Description, nil)
 
 	switch targetHttpsProxy.Version {
 	case meta.VersionAlpha:
@@ -6369,10 +6543,17 @@ func CreateTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, targetHttpsProxy
 	}
 }
 
-func DeleteTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func DeleteTargetHttpsProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	return withTransientRetry(ctx, "DeleteTargetHttpsProxy "+key.Name, func() error {
+		return deleteTargetHttpsProxy(ctx, gceCloud, key, version)
+	})
+}
+
+func deleteTargetHttpsProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("TargetHttpsProxy", "delete", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("TargetHttpsProxy", "delete", key.Region, key.Zone, string(version)),
+		"TargetHttpsProxy", "delete", key, version, "", nil)
 
 	switch version {
 	case meta.VersionAlpha:
@@ -6405,8 +6586,8 @@ func DeleteTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, version meta.Ver
 	}
 }
 
-func GetTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*TargetHttpsProxy, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func GetTargetHttpsProxy(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*TargetHttpsProxy, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("TargetHttpsProxy", "get", key.Region, key.Zone, string(version))
 
@@ -6441,8 +6622,8 @@ func GetTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, version meta.Versio
 			gceObj, err = gceCloud.Compute().TargetHttpsProxies().Get(ctx, key)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 	compositeType, err := toTargetHttpsProxy(gceObj)
 	if err != nil {
@@ -6452,8 +6633,14 @@ func GetTargetHttpsProxy(gceCloud *gce.Cloud, key *meta.Key, version meta.Versio
 	return compositeType, nil
 }
 
-func ListTargetHttpsProxies(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*TargetHttpsProxy, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func ListTargetHttpsProxies(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*TargetHttpsProxy, error) {
+	return ListTargetHttpsProxiesWithFilter(ctx, gceCloud, key, version, filter.None)
+}
+
+// ListTargetHttpsProxiesWithFilter lists the TargetHttpsProxys matching fl.
+// The filter is evaluated by the GCE API, see ListFilter.
+func ListTargetHttpsProxiesWithFilter(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, fl *filter.F) ([]*TargetHttpsProxy, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("TargetHttpsProxy", "list", key.Region, key.Zone, string(version))
 
@@ -6464,32 +6651,32 @@ func ListTargetHttpsProxies(gceCloud *gce.Cloud, key *meta.Key, version meta.Ver
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing alpha region TargetHttpsProxy")
-			gceObjs, err = gceCloud.Compute().AlphaRegionTargetHttpsProxies().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaRegionTargetHttpsProxies().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing alpha TargetHttpsProxy")
-			gceObjs, err = gceCloud.Compute().AlphaTargetHttpsProxies().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaTargetHttpsProxies().List(ctx, fl)
 		}
 	case meta.VersionBeta:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing beta region TargetHttpsProxy")
-			gceObjs, err = gceCloud.Compute().BetaRegionTargetHttpsProxies().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaRegionTargetHttpsProxies().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing beta TargetHttpsProxy")
-			gceObjs, err = gceCloud.Compute().BetaTargetHttpsProxies().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaTargetHttpsProxies().List(ctx, fl)
 		}
 	default:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing ga region TargetHttpsProxy")
-			gceObjs, err = gceCloud.Compute().RegionTargetHttpsProxies().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().RegionTargetHttpsProxies().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing ga TargetHttpsProxy")
-			gceObjs, err = gceCloud.Compute().TargetHttpsProxies().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().TargetHttpsProxies().List(ctx, fl)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 
 	compositeObjs, err := toTargetHttpsProxyList(gceObjs)
@@ -6593,10 +6780,17 @@ func (targetHttpsProxy *TargetHttpsProxy) ToGA() (*compute.TargetHttpsProxy, err
 	return ga, nil
 }
 
-func CreateUrlMap(gceCloud *gce.Cloud, key *meta.Key, urlMap *UrlMap) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func CreateUrlMap(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, urlMap *UrlMap) error {
+	return withTransientRetry(ctx, "CreateUrlMap "+key.Name, func() error {
+		return createUrlMap(ctx, gceCloud, key, urlMap)
+	})
+}
+
+func createUrlMap(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, urlMap *UrlMap) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("UrlMap", "create", key.Region, key.Zone, string(urlMap.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("UrlMap", "create", key.Region, key.Zone, string(urlMap.Version)),
+		"UrlMap", "create", key, urlMap.Version, urlMap.Description, nil)
 
 	switch urlMap.Version {
 	case meta.VersionAlpha:
@@ -6644,10 +6838,22 @@ func CreateUrlMap(gceCloud *gce.Cloud, key *meta.Key, urlMap *UrlMap) error {
 	}
 }
 
-func UpdateUrlMap(gceCloud *gce.Cloud, key *meta.Key, urlMap *UrlMap) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func UpdateUrlMap(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, urlMap *UrlMap) error {
+	return withTransientRetry(ctx, "UpdateUrlMap "+key.Name, func() error {
+		return updateUrlMap(ctx, gceCloud, key, urlMap)
+	})
+}
+
+func updateUrlMap(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, urlMap *UrlMap) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("UrlMap", "update", key.Region, key.Zone, string(urlMap.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("UrlMap", "update", key.Region, key.Zone, string(urlMap.Version)),
+		"UrlMap", "update", key, urlMap.Version, urlMap.Description, func() string {
+			return auditDiff(func() (interface{}, error) {
+				existing, err := GetUrlMap(ctx, gceCloud, key, urlMap.Version)
+				return existing, err
+			}, urlMap)
+		})
 	switch urlMap.Version {
 	case meta.VersionAlpha:
 		alpha, err := urlMap.ToAlpha()
@@ -6691,10 +6897,17 @@ func UpdateUrlMap(gceCloud *gce.Cloud, key *meta.Key, urlMap *UrlMap) error {
 	}
 }
 
-func DeleteUrlMap(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func DeleteUrlMap(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	return withTransientRetry(ctx, "DeleteUrlMap "+key.Name, func() error {
+		return deleteUrlMap(ctx, gceCloud, key, version)
+	})
+}
+
+func deleteUrlMap(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("UrlMap", "delete", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("UrlMap", "delete", key.Region, key.Zone, string(version)),
+		"UrlMap", "delete", key, version, "", nil)
 
 	switch version {
 	case meta.VersionAlpha:
@@ -6727,8 +6940,8 @@ func DeleteUrlMap(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) erro
 	}
 }
 
-func GetUrlMap(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*UrlMap, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func GetUrlMap(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*UrlMap, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("UrlMap", "get", key.Region, key.Zone, string(version))
 
@@ -6763,8 +6976,8 @@ func GetUrlMap(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*UrlMa
 			gceObj, err = gceCloud.Compute().UrlMaps().Get(ctx, key)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 	compositeType, err := toUrlMap(gceObj)
 	if err != nil {
@@ -6774,8 +6987,14 @@ func GetUrlMap(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*UrlMa
 	return compositeType, nil
 }
 
-func ListUrlMaps(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*UrlMap, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func ListUrlMaps(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*UrlMap, error) {
+	return ListUrlMapsWithFilter(ctx, gceCloud, key, version, filter.None)
+}
+
+// ListUrlMapsWithFilter lists the UrlMaps matching fl.
+// The filter is evaluated by the GCE API, see ListFilter.
+func ListUrlMapsWithFilter(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, fl *filter.F) ([]*UrlMap, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("UrlMap", "list", key.Region, key.Zone, string(version))
 
@@ -6786,32 +7005,32 @@ func ListUrlMaps(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*U
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing alpha region UrlMap")
-			gceObjs, err = gceCloud.Compute().AlphaRegionUrlMaps().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaRegionUrlMaps().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing alpha UrlMap")
-			gceObjs, err = gceCloud.Compute().AlphaUrlMaps().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().AlphaUrlMaps().List(ctx, fl)
 		}
 	case meta.VersionBeta:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing beta region UrlMap")
-			gceObjs, err = gceCloud.Compute().BetaRegionUrlMaps().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaRegionUrlMaps().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing beta UrlMap")
-			gceObjs, err = gceCloud.Compute().BetaUrlMaps().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().BetaUrlMaps().List(ctx, fl)
 		}
 	default:
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing ga region UrlMap")
-			gceObjs, err = gceCloud.Compute().RegionUrlMaps().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().RegionUrlMaps().List(ctx, key.Region, fl)
 		default:
 			klog.V(3).Infof("Listing ga UrlMap")
-			gceObjs, err = gceCloud.Compute().UrlMaps().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().UrlMaps().List(ctx, fl)
 		}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 
 	compositeObjs, err := toUrlMapList(gceObjs)
@@ -76,6 +76,7 @@ limitations under the License.
 
 package composite
 import (
+	"context"
 	"fmt"
 
 	"k8s.io/klog"
@@ -218,10 +219,17 @@ func genFuncs(wr io.Writer) {
 {{- end}} {{/* IsDefaultZonalService */}}
 	{{if .IsMainService}}
 		{{if .HasCRUD}}
-func Create{{.Name}}(gceCloud *gce.Cloud, key *meta.Key, {{.VarName}} *{{.Name}}) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func Create{{.Name}}(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, {{.VarName}} *{{.Name}}) error {
+	return withTransientRetry(ctx, "Create{{.Name}} "+key.Name, func() error {
+		return create{{.Name}}(ctx, gceCloud, key, {{.VarName}})
+	})
+}
+
+func create{{.Name}}(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, {{.VarName}} *{{.Name}}) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("{{.Name}}", "create", key.Region, key.Zone, string({{.VarName}}.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("{{.Name}}", "create", key.Region, key.Zone, string({{.VarName}}.Version)),
+		"{{.Name}}", "create", key, {{.VarName}}.Version, {{.VarName}}.Description, nil)
 
 	{{- if $onlyZonalKeySupported}}
 	switch key.Type() {
@@ -293,10 +301,22 @@ func Create{{.Name}}(gceCloud *gce.Cloud, key *meta.Key, {{.VarName}} *{{.Name}}
 }
 
 {{if .HasUpdate}}
-func Update{{.Name}}(gceCloud *gce.Cloud, key *meta.Key, {{.VarName}} *{{.Name}}) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func Update{{.Name}}(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, {{.VarName}} *{{.Name}}) error {
+	return withTransientRetry(ctx, "Update{{.Name}} "+key.Name, func() error {
+		return update{{.Name}}(ctx, gceCloud, key, {{.VarName}})
+	})
+}
+
+func update{{.Name}}(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, {{.VarName}} *{{.Name}}) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("{{.Name}}", "update", key.Region, key.Zone, string({{.VarName}}.Version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("{{.Name}}", "update", key.Region, key.Zone, string({{.VarName}}.Version)),
+		"{{.Name}}", "update", key, {{.VarName}}.Version, {{.VarName}}.Description, func() string {
+			return auditDiff(func() (interface{}, error) {
+				existing, err := Get{{.Name}}(ctx, gceCloud, key, {{.VarName}}.Version)
+				return existing, err
+			}, {{.VarName}})
+		})
 
 	{{- if $onlyZonalKeySupported}}
 	switch key.Type() {
@@ -365,10 +385,17 @@ func Update{{.Name}}(gceCloud *gce.Cloud, key *meta.Key, {{.VarName}} *{{.Name}}
 }
 {{- end}} {{/*HasUpdate*/}}
 
-func Delete{{.Name}}(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func Delete{{.Name}}(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	return withTransientRetry(ctx, "Delete{{.Name}} "+key.Name, func() error {
+		return delete{{.Name}}(ctx, gceCloud, key, version)
+	})
+}
+
+func delete{{.Name}}(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("{{.Name}}", "delete", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("{{.Name}}", "delete", key.Region, key.Zone, string(version)),
+		"{{.Name}}", "delete", key, version, "", nil)
 
 	{{- if $onlyZonalKeySupported}}
 	switch key.Type() {
@@ -424,8 +451,8 @@ func Delete{{.Name}}(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) e
 	}
 }
 
-func Get{{.Name}}(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*{{.Name}}, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func Get{{.Name}}(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*{{.Name}}, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("{{.Name}}", "get", key.Region, key.Zone, string(version))
 
@@ -484,8 +511,8 @@ func Get{{.Name}}(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*{{
 		}
 	{{- end}} {{/* $onlyZonalKeySupported*/}}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 	compositeType, err := to{{.Name}}(gceObj)
   	if err != nil {
@@ -503,8 +530,14 @@ func Get{{.Name}}(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) (*{{
   	return compositeType, nil
 }
 
-func List{{.GetCloudProviderName}}(gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*{{.Name}}, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func List{{.GetCloudProviderName}}(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version) ([]*{{.Name}}, error) {
+	return List{{.GetCloudProviderName}}WithFilter(ctx, gceCloud, key, version, filter.None)
+}
+
+// List{{.GetCloudProviderName}}WithFilter lists the {{.Name}}s matching fl.
+// The filter is evaluated by the GCE API, see ListFilter.
+func List{{.GetCloudProviderName}}WithFilter(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, fl *filter.F) ([]*{{.Name}}, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("{{.Name}}", "list", key.Region, key.Zone, string(version))
 
@@ -523,48 +556,48 @@ func List{{.GetCloudProviderName}}(gceCloud *gce.Cloud, key *meta.Key, version m
 	case meta.VersionAlpha:
 	{{- if $onlyZonalKeySupported}}
 		klog.V(3).Infof("Listing alpha zone{{.Name}}")
-		gceObjs, err = gceCloud.Compute().Alpha{{.GetCloudProviderName}}().List(ctx, key.Zone, filter.None)
+		gceObjs, err = gceCloud.Compute().Alpha{{.GetCloudProviderName}}().List(ctx, key.Zone, fl)
 	{{- else}}
 		switch key.Type() {
 		case meta.Regional:
 			klog.V(3).Infof("Listing alpha region {{.Name}}")
-			gceObjs, err = gceCloud.Compute().Alpha{{$regionalKeyFiller}}{{.GetCloudProviderName}}().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().Alpha{{$regionalKeyFiller}}{{.GetCloudProviderName}}().List(ctx, key.Region, fl)
 		default:
 		  	klog.V(3).Infof("Listing alpha {{.Name}}")
-			gceObjs, err = gceCloud.Compute().Alpha{{$globalKeyFiller}}{{.GetCloudProviderName}}().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().Alpha{{$globalKeyFiller}}{{.GetCloudProviderName}}().List(ctx, fl)
 		}
 	{{- end}} {{/* $onlyZonalKeySupported*/}}
 	case meta.VersionBeta:
 	{{- if $onlyZonalKeySupported}}
 		klog.V(3).Infof("Listing beta zone{{.Name}}")
-		gceObjs, err = gceCloud.Compute().Beta{{.GetCloudProviderName}}().List(ctx, key.Zone, filter.None)
+		gceObjs, err = gceCloud.Compute().Beta{{.GetCloudProviderName}}().List(ctx, key.Zone, fl)
 	{{- else}}
 		switch key.Type() {
 		case meta.Regional:
 		  	klog.V(3).Infof("Listing beta region {{.Name}}")
-			gceObjs, err = gceCloud.Compute().Beta{{$regionalKeyFiller}}{{.GetCloudProviderName}}().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().Beta{{$regionalKeyFiller}}{{.GetCloudProviderName}}().List(ctx, key.Region, fl)
 		default:
 		  	klog.V(3).Infof("Listing beta {{.Name}}")
-			gceObjs, err = gceCloud.Compute().Beta{{$globalKeyFiller}}{{.GetCloudProviderName}}().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().Beta{{$globalKeyFiller}}{{.GetCloudProviderName}}().List(ctx, fl)
 		}
 	{{- end}} {{/* $onlyZonalKeySupported*/}}
 	default:
 	{{- if $onlyZonalKeySupported}}
 		klog.V(3).Infof("Listing ga zone{{.Name}}")
-		gceObjs, err = gceCloud.Compute().{{.GetCloudProviderName}}().List(ctx, key.Zone, filter.None)
+		gceObjs, err = gceCloud.Compute().{{.GetCloudProviderName}}().List(ctx, key.Zone, fl)
     {{- else}}
 		switch key.Type() {
 		case meta.Regional:
  			klog.V(3).Infof("Listing ga region {{.Name}}")
-			gceObjs, err = gceCloud.Compute().{{$regionalKeyFiller}}{{.GetCloudProviderName}}().List(ctx, key.Region, filter.None)
+			gceObjs, err = gceCloud.Compute().{{$regionalKeyFiller}}{{.GetCloudProviderName}}().List(ctx, key.Region, fl)
 		default:
  			klog.V(3).Infof("Listing ga {{.Name}}")
-			gceObjs, err = gceCloud.Compute().{{$globalKeyFiller}}{{.GetCloudProviderName}}().List(ctx, filter.None)
+			gceObjs, err = gceCloud.Compute().{{$globalKeyFiller}}{{.GetCloudProviderName}}().List(ctx, fl)
 		}
     {{- end}} {{/* $onlyZonalKeySupported*/}}
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 
 	compositeObjs, err := to{{.Name}}List(gceObjs)
@@ -578,10 +611,11 @@ func List{{.GetCloudProviderName}}(gceCloud *gce.Cloud, key *meta.Key, version m
 }
 
 {{if .IsGroupResourceService}}
-func {{.GetGroupResourceInfo.AttachFuncName}}(gceCloud *gce.Cloud, key *meta.Key, version meta.Version, req *{{.GetGroupResourceInfo.AttachReqName}}) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func {{.GetGroupResourceInfo.AttachFuncName}}(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, req *{{.GetGroupResourceInfo.AttachReqName}}) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("{{.Name}}", "attach", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("{{.Name}}", "attach", key.Region, key.Zone, string(version)),
+		"{{.Name}}", "attach", key, version, "", func() string { return fmt.Sprintf("attach %d endpoints", len(req.NetworkEndpoints)) })
 
 	switch key.Type() {
 	case meta.Zonal:
@@ -614,10 +648,11 @@ func {{.GetGroupResourceInfo.AttachFuncName}}(gceCloud *gce.Cloud, key *meta.Key
 	}
 }
 
-func {{.GetGroupResourceInfo.DetachFuncName}}(gceCloud *gce.Cloud, key *meta.Key, version meta.Version, req *{{.GetGroupResourceInfo.DetachReqName}}) error {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func {{.GetGroupResourceInfo.DetachFuncName}}(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, req *{{.GetGroupResourceInfo.DetachReqName}}) error {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
-	mc := compositemetrics.NewMetricContext("{{.Name}}", "detach", key.Region, key.Zone, string(version))
+	mc := newAuditedCall(compositemetrics.NewMetricContext("{{.Name}}", "detach", key.Region, key.Zone, string(version)),
+		"{{.Name}}", "detach", key, version, "", func() string { return fmt.Sprintf("detach %d endpoints", len(req.NetworkEndpoints)) })
 
 	switch key.Type() {
 	case meta.Zonal:
@@ -650,8 +685,8 @@ func {{.GetGroupResourceInfo.DetachFuncName}}(gceCloud *gce.Cloud, key *meta.Key
 	}
 }
 
-func {{.GetGroupResourceInfo.ListFuncName}}(gceCloud *gce.Cloud, key *meta.Key, version meta.Version, req *{{.GetGroupResourceInfo.ListReqName}}) ([]*{{.GetGroupResourceInfo.ListRespName}}, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func {{.GetGroupResourceInfo.ListFuncName}}(ctx context.Context, gceCloud *gce.Cloud, key *meta.Key, version meta.Version, req *{{.GetGroupResourceInfo.ListReqName}}) ([]*{{.GetGroupResourceInfo.ListRespName}}, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("{{.Name}}", "list", key.Region, key.Zone, string(version))
 
@@ -687,8 +722,8 @@ func {{.GetGroupResourceInfo.ListFuncName}}(gceCloud *gce.Cloud, key *meta.Key,
 		  klog.V(3).Infof("Listing ga zonal {{.Name}} %v", key.Name)
 			gceObjs, err = gceCloud.Compute().{{.GetCloudProviderName}}().{{.GetGroupResourceInfo.ListFuncName}}(ctx, key, gareq, filter.None)
 	}
-	if err != nil {
-		return nil, mc.Observe(err)
+	if err := mc.Observe(err); err != nil {
+		return nil, err
 	}
 
 	compositeObjs, err := to{{.GetGroupResourceInfo.ListRespName}}List(gceObjs)
@@ -701,8 +736,8 @@ func {{.GetGroupResourceInfo.ListFuncName}}(gceCloud *gce.Cloud, key *meta.Key,
 	return compositeObjs, nil
 }
 
-func {{.GetGroupResourceInfo.AggListFuncName}}{{.GetGroupResourceInfo.AggListRespName}}(gceCloud *gce.Cloud, version meta.Version) (map[*meta.Key]*{{.GetGroupResourceInfo.AggListRespName}}, error) {
-	ctx, cancel := cloudprovider.ContextWithCallTimeout()
+func {{.GetGroupResourceInfo.AggListFuncName}}{{.GetGroupResourceInfo.AggListRespName}}(ctx context.Context, gceCloud *gce.Cloud, version meta.Version) (map[*meta.Key]*{{.GetGroupResourceInfo.AggListRespName}}, error) {
+	ctx, cancel := ContextWithCallTimeout(ctx)
 	defer cancel()
 	mc := compositemetrics.NewMetricContext("{{.Name}}", "aggregateList", "", "", string(version))
 
@@ -713,8 +748,8 @@ func {{.GetGroupResourceInfo.AggListFuncName}}{{.GetGroupResourceInfo.AggListRes
 	case meta.VersionAlpha:
 		klog.V(3).Infof("Aggregate List of alpha zonal {{.Name}}")
 		alphaMap, err := gceCloud.Compute().Alpha{{.GetCloudProviderName}}().{{.GetGroupResourceInfo.AggListFuncName}}(ctx, filter.None)
-		if err != nil {
-			return nil, mc.Observe(err)
+		if err := mc.Observe(err); err != nil {
+			return nil, err
 		}
 		// Convert from map to list
 		alphaList := []*computealpha.{{.GetGroupResourceInfo.AggListRespName}}{}
@@ -725,8 +760,8 @@ func {{.GetGroupResourceInfo.AggListFuncName}}{{.GetGroupResourceInfo.AggListRes
 	case meta.VersionBeta:
 		klog.V(3).Infof("Aggregate List of beta zonal {{.Name}}")
 		betaMap, err := gceCloud.Compute().Beta{{.GetCloudProviderName}}().{{.GetGroupResourceInfo.AggListFuncName}}(ctx, filter.None)
-		if err != nil {
-			return nil, mc.Observe(err)
+		if err := mc.Observe(err); err != nil {
+			return nil, err
 		}
 		// Convert from map to list
 		betaList := []*computebeta.{{.GetGroupResourceInfo.AggListRespName}}{}
@@ -737,8 +772,8 @@ func {{.GetGroupResourceInfo.AggListFuncName}}{{.GetGroupResourceInfo.AggListRes
 	default:
 		klog.V(3).Infof("Aggregate List of ga zonal {{.Name}}")
 		gaMap, err := gceCloud.Compute().{{.GetCloudProviderName}}().{{.GetGroupResourceInfo.AggListFuncName}}(ctx, filter.None)
-		if err != nil {
-			return nil, mc.Observe(err)
+		if err := mc.Observe(err); err != nil {
+			return nil, err
 		}
 		// Convert from map to list
 		gaList := []*compute.{{.GetGroupResourceInfo.AggListRespName}}{}
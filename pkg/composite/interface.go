@@ -0,0 +1,278 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/filter"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+// Cloud is the set of composite operations used by the L7 load balancer
+// subsystems. It exists so that those subsystems can be backed either by a
+// real gce.Cloud (see NewCloud) or by an in-memory Fake in unit tests.
+type Cloud interface {
+	// CreateKey returns a key for the given name and scope.
+	// See the package level CreateKey for details.
+	CreateKey(name string, scope meta.KeyType) (*meta.Key, error)
+
+	CreateAddress(ctx context.Context, key *meta.Key, address *Address) error
+	DeleteAddress(ctx context.Context, key *meta.Key, version meta.Version) error
+	GetAddress(ctx context.Context, key *meta.Key, version meta.Version) (*Address, error)
+	ListAddresses(ctx context.Context, key *meta.Key, version meta.Version) ([]*Address, error)
+
+	CreateBackendService(ctx context.Context, key *meta.Key, backendService *BackendService) error
+	UpdateBackendService(ctx context.Context, key *meta.Key, backendService *BackendService) error
+	DeleteBackendService(ctx context.Context, key *meta.Key, version meta.Version) error
+	GetBackendService(ctx context.Context, key *meta.Key, version meta.Version) (*BackendService, error)
+	ListBackendServices(ctx context.Context, key *meta.Key, version meta.Version) ([]*BackendService, error)
+	ListBackendServicesWithFilter(ctx context.Context, key *meta.Key, version meta.Version, fl *filter.F) ([]*BackendService, error)
+	SetSecurityPolicy(ctx context.Context, backendService *BackendService, securityPolicy string) error
+
+	CreateForwardingRule(ctx context.Context, key *meta.Key, forwardingRule *ForwardingRule) error
+	DeleteForwardingRule(ctx context.Context, key *meta.Key, version meta.Version) error
+	GetForwardingRule(ctx context.Context, key *meta.Key, version meta.Version) (*ForwardingRule, error)
+	ListForwardingRules(ctx context.Context, key *meta.Key, version meta.Version) ([]*ForwardingRule, error)
+	SetProxyForForwardingRule(ctx context.Context, key *meta.Key, forwardingRule *ForwardingRule, targetProxyLink string) error
+
+	CreateHealthCheck(ctx context.Context, key *meta.Key, healthCheck *HealthCheck) error
+	UpdateHealthCheck(ctx context.Context, key *meta.Key, healthCheck *HealthCheck) error
+	DeleteHealthCheck(ctx context.Context, key *meta.Key, version meta.Version) error
+	GetHealthCheck(ctx context.Context, key *meta.Key, version meta.Version) (*HealthCheck, error)
+	ListHealthChecks(ctx context.Context, key *meta.Key, version meta.Version) ([]*HealthCheck, error)
+
+	CreateSslCertificate(ctx context.Context, key *meta.Key, sslCertificate *SslCertificate) error
+	DeleteSslCertificate(ctx context.Context, key *meta.Key, version meta.Version) error
+	GetSslCertificate(ctx context.Context, key *meta.Key, version meta.Version) (*SslCertificate, error)
+	ListSslCertificates(ctx context.Context, key *meta.Key, version meta.Version) ([]*SslCertificate, error)
+	// GetSslCertificateOfProject gets the GA SslCertificate of the given
+	// project, which may differ from the project of the Cloud.
+	GetSslCertificateOfProject(ctx context.Context, project string, key *meta.Key) (*SslCertificate, error)
+
+	CreateTargetHttpProxy(ctx context.Context, key *meta.Key, targetHttpProxy *TargetHttpProxy) error
+	DeleteTargetHttpProxy(ctx context.Context, key *meta.Key, version meta.Version) error
+	GetTargetHttpProxy(ctx context.Context, key *meta.Key, version meta.Version) (*TargetHttpProxy, error)
+	ListTargetHttpProxies(ctx context.Context, key *meta.Key, version meta.Version) ([]*TargetHttpProxy, error)
+	SetUrlMapForTargetHttpProxy(ctx context.Context, key *meta.Key, targetHttpProxy *TargetHttpProxy, urlMapLink string) error
+
+	CreateTargetHttpsProxy(ctx context.Context, key *meta.Key, targetHttpsProxy *TargetHttpsProxy) error
+	DeleteTargetHttpsProxy(ctx context.Context, key *meta.Key, version meta.Version) error
+	GetTargetHttpsProxy(ctx context.Context, key *meta.Key, version meta.Version) (*TargetHttpsProxy, error)
+	ListTargetHttpsProxies(ctx context.Context, key *meta.Key, version meta.Version) ([]*TargetHttpsProxy, error)
+	SetUrlMapForTargetHttpsProxy(ctx context.Context, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, urlMapLink string) error
+	SetSslCertificateForTargetHttpsProxy(ctx context.Context, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, sslCertURLs []string) error
+	SetSslPolicyForTargetHttpsProxy(ctx context.Context, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, sslPolicyLink string) error
+
+	CreateUrlMap(ctx context.Context, key *meta.Key, urlMap *UrlMap) error
+	UpdateUrlMap(ctx context.Context, key *meta.Key, urlMap *UrlMap) error
+	DeleteUrlMap(ctx context.Context, key *meta.Key, version meta.Version) error
+	GetUrlMap(ctx context.Context, key *meta.Key, version meta.Version) (*UrlMap, error)
+	ListUrlMaps(ctx context.Context, key *meta.Key, version meta.Version) ([]*UrlMap, error)
+	ListUrlMapsWithFilter(ctx context.Context, key *meta.Key, version meta.Version, fl *filter.F) ([]*UrlMap, error)
+}
+
+// gceCloud implements Cloud by delegating to the package level functions.
+type gceCloud struct {
+	cloud *gce.Cloud
+}
+
+// NewCloud returns a Cloud backed by the given gce.Cloud.
+func NewCloud(cloud *gce.Cloud) Cloud {
+	return &gceCloud{cloud: cloud}
+}
+
+func (g *gceCloud) CreateKey(name string, scope meta.KeyType) (*meta.Key, error) {
+	return CreateKey(g.cloud, name, scope)
+}
+
+func (g *gceCloud) CreateAddress(ctx context.Context, key *meta.Key, address *Address) error {
+	return CreateAddress(ctx, g.cloud, key, address)
+}
+
+func (g *gceCloud) DeleteAddress(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return DeleteAddress(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) GetAddress(ctx context.Context, key *meta.Key, version meta.Version) (*Address, error) {
+	return GetAddress(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) ListAddresses(ctx context.Context, key *meta.Key, version meta.Version) ([]*Address, error) {
+	return ListAddresses(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) CreateBackendService(ctx context.Context, key *meta.Key, backendService *BackendService) error {
+	return CreateBackendService(ctx, g.cloud, key, backendService)
+}
+
+func (g *gceCloud) UpdateBackendService(ctx context.Context, key *meta.Key, backendService *BackendService) error {
+	return UpdateBackendService(ctx, g.cloud, key, backendService)
+}
+
+func (g *gceCloud) DeleteBackendService(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return DeleteBackendService(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) GetBackendService(ctx context.Context, key *meta.Key, version meta.Version) (*BackendService, error) {
+	return GetBackendService(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) ListBackendServices(ctx context.Context, key *meta.Key, version meta.Version) ([]*BackendService, error) {
+	return ListBackendServices(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) ListBackendServicesWithFilter(ctx context.Context, key *meta.Key, version meta.Version, fl *filter.F) ([]*BackendService, error) {
+	return ListBackendServicesWithFilter(ctx, g.cloud, key, version, fl)
+}
+
+func (g *gceCloud) SetSecurityPolicy(ctx context.Context, backendService *BackendService, securityPolicy string) error {
+	return SetSecurityPolicy(ctx, g.cloud, backendService, securityPolicy)
+}
+
+func (g *gceCloud) CreateForwardingRule(ctx context.Context, key *meta.Key, forwardingRule *ForwardingRule) error {
+	return CreateForwardingRule(ctx, g.cloud, key, forwardingRule)
+}
+
+func (g *gceCloud) DeleteForwardingRule(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return DeleteForwardingRule(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) GetForwardingRule(ctx context.Context, key *meta.Key, version meta.Version) (*ForwardingRule, error) {
+	return GetForwardingRule(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) ListForwardingRules(ctx context.Context, key *meta.Key, version meta.Version) ([]*ForwardingRule, error) {
+	return ListForwardingRules(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) SetProxyForForwardingRule(ctx context.Context, key *meta.Key, forwardingRule *ForwardingRule, targetProxyLink string) error {
+	return SetProxyForForwardingRule(ctx, g.cloud, key, forwardingRule, targetProxyLink)
+}
+
+func (g *gceCloud) CreateHealthCheck(ctx context.Context, key *meta.Key, healthCheck *HealthCheck) error {
+	return CreateHealthCheck(ctx, g.cloud, key, healthCheck)
+}
+
+func (g *gceCloud) UpdateHealthCheck(ctx context.Context, key *meta.Key, healthCheck *HealthCheck) error {
+	return UpdateHealthCheck(ctx, g.cloud, key, healthCheck)
+}
+
+func (g *gceCloud) DeleteHealthCheck(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return DeleteHealthCheck(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) GetHealthCheck(ctx context.Context, key *meta.Key, version meta.Version) (*HealthCheck, error) {
+	return GetHealthCheck(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) ListHealthChecks(ctx context.Context, key *meta.Key, version meta.Version) ([]*HealthCheck, error) {
+	return ListHealthChecks(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) CreateSslCertificate(ctx context.Context, key *meta.Key, sslCertificate *SslCertificate) error {
+	return CreateSslCertificate(ctx, g.cloud, key, sslCertificate)
+}
+
+func (g *gceCloud) DeleteSslCertificate(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return DeleteSslCertificate(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) GetSslCertificate(ctx context.Context, key *meta.Key, version meta.Version) (*SslCertificate, error) {
+	return GetSslCertificate(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) ListSslCertificates(ctx context.Context, key *meta.Key, version meta.Version) ([]*SslCertificate, error) {
+	return ListSslCertificates(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) GetSslCertificateOfProject(ctx context.Context, project string, key *meta.Key) (*SslCertificate, error) {
+	return GetSslCertificateOfProject(ctx, g.cloud, project, key)
+}
+
+func (g *gceCloud) CreateTargetHttpProxy(ctx context.Context, key *meta.Key, targetHttpProxy *TargetHttpProxy) error {
+	return CreateTargetHttpProxy(ctx, g.cloud, key, targetHttpProxy)
+}
+
+func (g *gceCloud) DeleteTargetHttpProxy(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return DeleteTargetHttpProxy(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) GetTargetHttpProxy(ctx context.Context, key *meta.Key, version meta.Version) (*TargetHttpProxy, error) {
+	return GetTargetHttpProxy(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) ListTargetHttpProxies(ctx context.Context, key *meta.Key, version meta.Version) ([]*TargetHttpProxy, error) {
+	return ListTargetHttpProxies(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) SetUrlMapForTargetHttpProxy(ctx context.Context, key *meta.Key, targetHttpProxy *TargetHttpProxy, urlMapLink string) error {
+	return SetUrlMapForTargetHttpProxy(ctx, g.cloud, key, targetHttpProxy, urlMapLink)
+}
+
+func (g *gceCloud) CreateTargetHttpsProxy(ctx context.Context, key *meta.Key, targetHttpsProxy *TargetHttpsProxy) error {
+	return CreateTargetHttpsProxy(ctx, g.cloud, key, targetHttpsProxy)
+}
+
+func (g *gceCloud) DeleteTargetHttpsProxy(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return DeleteTargetHttpsProxy(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) GetTargetHttpsProxy(ctx context.Context, key *meta.Key, version meta.Version) (*TargetHttpsProxy, error) {
+	return GetTargetHttpsProxy(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) ListTargetHttpsProxies(ctx context.Context, key *meta.Key, version meta.Version) ([]*TargetHttpsProxy, error) {
+	return ListTargetHttpsProxies(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) SetUrlMapForTargetHttpsProxy(ctx context.Context, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, urlMapLink string) error {
+	return SetUrlMapForTargetHttpsProxy(ctx, g.cloud, key, targetHttpsProxy, urlMapLink)
+}
+
+func (g *gceCloud) SetSslCertificateForTargetHttpsProxy(ctx context.Context, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, sslCertURLs []string) error {
+	return SetSslCertificateForTargetHttpsProxy(ctx, g.cloud, key, targetHttpsProxy, sslCertURLs)
+}
+
+func (g *gceCloud) SetSslPolicyForTargetHttpsProxy(ctx context.Context, key *meta.Key, targetHttpsProxy *TargetHttpsProxy, sslPolicyLink string) error {
+	return SetSslPolicyForTargetHttpsProxy(ctx, g.cloud, key, targetHttpsProxy, sslPolicyLink)
+}
+
+func (g *gceCloud) CreateUrlMap(ctx context.Context, key *meta.Key, urlMap *UrlMap) error {
+	return CreateUrlMap(ctx, g.cloud, key, urlMap)
+}
+
+func (g *gceCloud) UpdateUrlMap(ctx context.Context, key *meta.Key, urlMap *UrlMap) error {
+	return UpdateUrlMap(ctx, g.cloud, key, urlMap)
+}
+
+func (g *gceCloud) DeleteUrlMap(ctx context.Context, key *meta.Key, version meta.Version) error {
+	return DeleteUrlMap(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) GetUrlMap(ctx context.Context, key *meta.Key, version meta.Version) (*UrlMap, error) {
+	return GetUrlMap(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) ListUrlMaps(ctx context.Context, key *meta.Key, version meta.Version) ([]*UrlMap, error) {
+	return ListUrlMaps(ctx, g.cloud, key, version)
+}
+
+func (g *gceCloud) ListUrlMapsWithFilter(ctx context.Context, key *meta.Key, version meta.Version, fl *filter.F) ([]*UrlMap, error) {
+	return ListUrlMapsWithFilter(ctx, g.cloud, key, version, fl)
+}
@@ -84,7 +84,7 @@ func registerAPIMetrics(attributes ...string) *apiCallMetrics {
 	metrics := &apiCallMetrics{
 		latency: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
-				Name: "gce_api_request_duration_seconds", // TODO: (shance) reconcile with cloudprovider
+				Name: latencyMetricName, // TODO: (shance) reconcile with cloudprovider
 				Help: "Latency of a GCE API call",
 			},
 			attributes,
@@ -17,6 +17,8 @@ limitations under the License.
 package metrics
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -26,3 +28,52 @@ func TestVerifyMetricLabelCardinality(t *testing.T) {
 		t.Fatalf("cardinalities of labels and values must match")
 	}
 }
+
+func TestSnapshot(t *testing.T) {
+	before := Snapshot()
+	for i := 0; i < 3; i++ {
+		NewMetricContext("Foo", "get", "", "", "v1").Observe(nil)
+	}
+	NewMetricContext("Foo", "list", "us-central1", "", "beta").Observe(nil)
+	calls := Snapshot().Sub(before)
+
+	if want := (CallCounts{"Foo_get": 3, "Foo_list": 1}); !reflect.DeepEqual(calls, want) {
+		t.Fatalf("Snapshot().Sub() = %v, want %v", calls, want)
+	}
+	for _, tc := range []struct {
+		desc    string
+		budget  Budget
+		wantErr bool
+	}{
+		{desc: "no budget", budget: Budget{}},
+		{desc: "within budget", budget: Budget{"Foo_get": 3, AnyRequest: 4}},
+		{desc: "request over budget", budget: Budget{"Foo_get": 2}, wantErr: true},
+		{desc: "total over budget", budget: Budget{AnyRequest: 3}, wantErr: true},
+	} {
+		if err := calls.CheckBudget(tc.budget); (err != nil) != tc.wantErr {
+			t.Errorf("%s: CheckBudget(%v) = %v, want error %t", tc.desc, tc.budget, err, tc.wantErr)
+		}
+	}
+}
+
+func TestParseCallCounts(t *testing.T) {
+	text := `# HELP gce_api_request_duration_seconds Latency of a GCE API call
+# TYPE gce_api_request_duration_seconds histogram
+gce_api_request_duration_seconds_bucket{region="<n/a>",request="Foo_get",version="ga",zone="<n/a>",le="+Inf"} 3
+gce_api_request_duration_seconds_sum{region="<n/a>",request="Foo_get",version="ga",zone="<n/a>"} 0.3
+gce_api_request_duration_seconds_count{region="<n/a>",request="Foo_get",version="ga",zone="<n/a>"} 3
+gce_api_request_duration_seconds_bucket{region="us-central1",request="Foo_get",version="beta",zone="<n/a>",le="+Inf"} 2
+gce_api_request_duration_seconds_sum{region="us-central1",request="Foo_get",version="beta",zone="<n/a>"} 0.2
+gce_api_request_duration_seconds_count{region="us-central1",request="Foo_get",version="beta",zone="<n/a>"} 2
+# HELP other_total Another metric.
+# TYPE other_total counter
+other_total{request="Foo_list"} 7
+`
+	calls, err := ParseCallCounts(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("ParseCallCounts() = %v", err)
+	}
+	if want := (CallCounts{"Foo_get": 5}); !reflect.DeepEqual(calls, want) {
+		t.Errorf("ParseCallCounts() = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	// AnyRequest is the Budget key which bounds the total number of calls.
+	AnyRequest = "*"
+	// latencyMetricName is the name of the metric whose samples count the
+	// GCE API calls.
+	latencyMetricName = "gce_api_request_duration_seconds"
+)
+
+// CallCounts are the numbers of GCE API calls made through composite by this
+// process, by request (e.g. "BackendService_get"), summed over regions, zones
+// and API versions.
+type CallCounts map[string]uint64
+
+// Budget is the maximum number of GCE API calls by request. AnyRequest bounds
+// the total number of calls.
+type Budget map[string]uint64
+
+// Snapshot returns the number of GCE API calls made so far.
+func Snapshot() CallCounts {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		apiMetrics.latency.Collect(ch)
+		close(ch)
+	}()
+	counts := CallCounts{}
+	for m := range ch {
+		var metric dto.Metric
+		if err := m.Write(&metric); err != nil {
+			continue
+		}
+		counts.add(&metric)
+	}
+	return counts
+}
+
+// ParseCallCounts returns the number of GCE API calls made so far by another
+// process, e.g. the controller, from its metrics in the Prometheus text
+// format.
+func ParseCallCounts(r io.Reader) (CallCounts, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, err
+	}
+	counts := CallCounts{}
+	if family, ok := families[latencyMetricName]; ok {
+		for _, metric := range family.GetMetric() {
+			counts.add(metric)
+		}
+	}
+	return counts, nil
+}
+
+// add adds the calls counted by a sample of the latency metric.
+func (c CallCounts) add(metric *dto.Metric) {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == "request" {
+			c[label.GetValue()] += metric.GetHistogram().GetSampleCount()
+		}
+	}
+}
+
+// Sub returns the calls made since the earlier snapshot.
+func (c CallCounts) Sub(earlier CallCounts) CallCounts {
+	diff := CallCounts{}
+	for request, count := range c {
+		if count > earlier[request] {
+			diff[request] = count - earlier[request]
+		}
+	}
+	return diff
+}
+
+// Total returns the total number of calls.
+func (c CallCounts) Total() uint64 {
+	var total uint64
+	for _, count := range c {
+		total += count
+	}
+	return total
+}
+
+// CheckBudget returns an error listing the requests whose number of calls
+// exceeds the budget. Requests which are not in the budget are only bounded
+// by AnyRequest, if set.
+func (c CallCounts) CheckBudget(budget Budget) error {
+	var exceeded []string
+	for request, count := range c {
+		if max, ok := budget[request]; ok && count > max {
+			exceeded = append(exceeded, fmt.Sprintf("%s: %d calls, budget %d", request, count, max))
+		}
+	}
+	if max, ok := budget[AnyRequest]; ok && c.Total() > max {
+		exceeded = append(exceeded, fmt.Sprintf("total: %d calls, budget %d", c.Total(), max))
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	sort.Strings(exceeded)
+	return fmt.Errorf("GCE API call budget exceeded (%s)", strings.Join(exceeded, "; "))
+}
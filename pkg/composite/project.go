@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"sync"
+
+	cloudprovider "github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	compositemetrics "k8s.io/ingress-gce/pkg/composite/metrics"
+	"k8s.io/klog"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+var (
+	projectRateLimiterLock sync.Mutex
+	// projectRateLimiter rate limits the calls which are not routed
+	// through the gce.Cloud, e.g. to resources of other projects.
+	projectRateLimiter cloudprovider.RateLimiter = &cloudprovider.NopRateLimiter{}
+)
+
+// SetProjectRateLimiter sets the rate limiter of the calls which are not
+// routed through the gce.Cloud, e.g. to resources of other projects or
+// started with Call.Start. It should be the rate limiter of the gce.Cloud,
+// so that those calls share its limits.
+func SetProjectRateLimiter(rl cloudprovider.RateLimiter) {
+	projectRateLimiterLock.Lock()
+	defer projectRateLimiterLock.Unlock()
+	projectRateLimiter = rl
+}
+
+func sharedRateLimiter() cloudprovider.RateLimiter {
+	projectRateLimiterLock.Lock()
+	defer projectRateLimiterLock.Unlock()
+	return projectRateLimiter
+}
+
+// computeForProject returns the compute client of gceCloud with all calls
+// routed to the given project.
+func computeForProject(gceCloud *gce.Cloud, project string) cloudprovider.Cloud {
+	services := gceCloud.ComputeServices()
+	return cloudprovider.NewGCE(&cloudprovider.Service{
+		GA:            services.GA,
+		Alpha:         services.Alpha,
+		Beta:          services.Beta,
+		ProjectRouter: &cloudprovider.SingleProjectRouter{ID: project},
+		RateLimiter:   sharedRateLimiter(),
+	})
+}
+
+// GetSslCertificateOfProject gets the GA SslCertificate of the given key in
+// the given project, which may differ from the project of gceCloud.
+func GetSslCertificateOfProject(ctx context.Context, gceCloud *gce.Cloud, project string, key *meta.Key) (*SslCertificate, error) {
+	if project == gceCloud.ProjectID() {
+		return GetSslCertificate(ctx, gceCloud, key, meta.VersionGA)
+	}
+	ctx, cancel := ContextWithCallTimeout(ctx)
+	defer cancel()
+	mc := compositemetrics.NewMetricContext("SslCertificate", "get", key.Region, key.Zone, string(meta.VersionGA))
+
+	compute := computeForProject(gceCloud, project)
+	var gceObj interface{}
+	var err error
+	switch key.Type() {
+	case meta.Regional:
+		klog.V(3).Infof("Getting ga region SslCertificate %v of project %s", key.Name, project)
+		gceObj, err = compute.RegionSslCertificates().Get(ctx, key)
+	default:
+		klog.V(3).Infof("Getting ga SslCertificate %v of project %s", key.Name, project)
+		gceObj, err = compute.SslCertificates().Get(ctx, key)
+	}
+	if err := mc.Observe(err); err != nil {
+		return nil, err
+	}
+	compositeType, err := toSslCertificate(gceObj)
+	if err != nil {
+		return nil, err
+	}
+	compositeType.Version = meta.VersionGA
+	return compositeType, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+)
+
+var (
+	// transientRetryBackoff is the backoff between the attempts of an
+	// operation which failed with a transient error. The jitter spreads the
+	// retries of workers which conflict on the same resource.
+	transientRetryBackoff = wait.Backoff{
+		Duration: time.Second,
+		Factor:   2,
+		Jitter:   1,
+		Steps:    3,
+	}
+	// retryWait waits for the delay before a retry, or until ctx is done. It
+	// is overridden in tests.
+	retryWait = func(ctx context.Context, delay time.Duration) error {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		}
+	}
+)
+
+// withTransientRetry calls fn until it succeeds, fails with an error which is
+// not transient, or the retries of transientRetryBackoff are exhausted. This
+// spares the callers failing the whole sync and requeueing it because another
+// operation on the resource was still in progress. The retries stop with the
+// error of ctx once it is done, e.g. at the deadline of the sync.
+func withTransientRetry(ctx context.Context, op string, fn func() error) error {
+	backoff := transientRetryBackoff
+	for {
+		err := fn()
+		if err == nil || !isTransientError(err) || backoff.Steps == 0 {
+			return err
+		}
+		delay := backoff.Step()
+		klog.V(2).Infof("Retrying %s in %v after transient error: %v", op, delay, err)
+		if err := retryWait(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// isTransientError returns true if err is a GCE error which is resolved by
+// retrying the operation: the resource is not ready because an operation on it
+// is in progress, or the operation conflicts with one in progress.
+func isTransientError(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, e := range apiErr.Errors {
+		if e.Reason == "resourceNotReady" {
+			return true
+		}
+		if e.Reason == "alreadyExists" {
+			return false
+		}
+	}
+	// The errors of failed operations only carry the error code in the
+	// message.
+	if strings.Contains(apiErr.Message, "RESOURCE_NOT_READY") {
+		return true
+	}
+	return apiErr.Code == http.StatusConflict && !strings.Contains(apiErr.Message, "ALREADY_EXISTS") && !strings.Contains(apiErr.Message, "already exists")
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+func TestIsTransientError(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		err  error
+		want bool
+	}{
+		{"not a GCE error", errors.New("error"), false},
+		{"not found", &googleapi.Error{Code: http.StatusNotFound}, false},
+		{"resource not ready", &googleapi.Error{Code: http.StatusBadRequest, Errors: []googleapi.ErrorItem{{Reason: "resourceNotReady"}}}, true},
+		{"failed operation on a resource not ready", &googleapi.Error{Code: http.StatusBadRequest, Message: "RESOURCE_NOT_READY - The resource is not ready"}, true},
+		{"operation conflict", &googleapi.Error{Code: http.StatusConflict, Message: "The operation conflicts with operation-123"}, true},
+		{"already exists", &googleapi.Error{Code: http.StatusConflict, Errors: []googleapi.ErrorItem{{Reason: "alreadyExists"}}}, false},
+		{"failed operation on a resource which already exists", &googleapi.Error{Code: http.StatusConflict, Message: "ALREADY_EXISTS - The resource already exists"}, false},
+	} {
+		if got := isTransientError(tc.err); got != tc.want {
+			t.Errorf("%s: isTransientError(%v) = %v, want %v", tc.desc, tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestTransientRetry(t *testing.T) {
+	var delays []time.Duration
+	origWait := retryWait
+	retryWait = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	defer func() { retryWait = origWait }()
+
+	notReady := &googleapi.Error{Code: http.StatusBadRequest, Errors: []googleapi.ErrorItem{{Reason: "resourceNotReady"}}}
+	for _, tc := range []struct {
+		desc        string
+		failures    int
+		wantErr     bool
+		wantInserts int
+	}{
+		{desc: "no failure", failures: 0, wantInserts: 1},
+		{desc: "transient failures", failures: 2, wantInserts: 3},
+		{desc: "retries exhausted", failures: 10, wantErr: true, wantInserts: transientRetryBackoff.Steps + 1},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			delays = nil
+			fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+			inserts := 0
+			fakeGCE.Compute().(*cloud.MockGCE).MockBackendServices.InsertHook = func(context.Context, *meta.Key, *compute.BackendService, *cloud.MockBackendServices) (bool, error) {
+				inserts++
+				if inserts <= tc.failures {
+					return true, notReady
+				}
+				return false, nil
+			}
+
+			key := meta.GlobalKey("retry-test")
+			err := CreateBackendService(context.TODO(), fakeGCE, key, &BackendService{Name: key.Name, Version: meta.VersionGA})
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("CreateBackendService() = %v, want error %v", err, tc.wantErr)
+			}
+			if inserts != tc.wantInserts {
+				t.Errorf("Got %d inserts, want %d", inserts, tc.wantInserts)
+			}
+			if len(delays) != tc.wantInserts-1 {
+				t.Fatalf("Got %d retries, want %d", len(delays), tc.wantInserts-1)
+			}
+			// Each delay is jittered up to twice the backoff duration.
+			want := transientRetryBackoff.Duration
+			for i, d := range delays {
+				if d < want || d > 2*want {
+					t.Errorf("Delay of retry %d = %v, want in [%v, %v]", i, d, want, 2*want)
+				}
+				want *= time.Duration(transientRetryBackoff.Factor)
+			}
+		})
+	}
+}
+
+func TestTransientRetryStopsWithContext(t *testing.T) {
+	notReady := &googleapi.Error{Code: http.StatusBadRequest, Errors: []googleapi.ErrorItem{{Reason: "resourceNotReady"}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	inserts := 0
+	// The sync is cancelled while the insert is failing, e.g. at its deadline.
+	fakeGCE.Compute().(*cloud.MockGCE).MockBackendServices.InsertHook = func(context.Context, *meta.Key, *compute.BackendService, *cloud.MockBackendServices) (bool, error) {
+		inserts++
+		cancel()
+		return true, notReady
+	}
+
+	key := meta.GlobalKey("retry-test")
+	start := time.Now()
+	err := CreateBackendService(ctx, fakeGCE, key, &BackendService{Name: key.Name, Version: meta.VersionGA})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("CreateBackendService() = %v, want %v", err, context.Canceled)
+	}
+	if inserts != 1 {
+		t.Errorf("Got %d inserts, want 1", inserts)
+	}
+	if elapsed := time.Since(start); elapsed >= transientRetryBackoff.Duration {
+		t.Errorf("CreateBackendService() returned after %v, want it to stop without waiting for the retry", elapsed)
+	}
+}
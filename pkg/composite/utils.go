@@ -20,8 +20,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"regexp"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/filter"
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
 	"k8s.io/legacy-cloud-providers/gce"
 )
@@ -46,6 +48,28 @@ func CreateKey(gceCloud *gce.Cloud, name string, scope meta.KeyType) (*meta.Key,
 	return nil, fmt.Errorf("invalid resource type: %s", scope)
 }
 
+// ListFilter returns a filter for the List...WithFilter functions which matches
+// resources whose name starts with namePrefix and whose description contains
+// descriptionSubstring. An empty argument matches all resources. The filter is
+// evaluated by the GCE API, so that large projects need not be listed in full.
+// The client library does not quote filter values, hence the arguments must not
+// contain whitespace or quotes.
+func ListFilter(namePrefix, descriptionSubstring string) *filter.F {
+	fl := filter.None
+	if namePrefix != "" {
+		fl = filter.Regexp("name", regexp.QuoteMeta(namePrefix)+".*")
+	}
+	if descriptionSubstring != "" {
+		description := ".*" + regexp.QuoteMeta(descriptionSubstring) + ".*"
+		if fl == filter.None {
+			fl = filter.Regexp("description", description)
+		} else {
+			fl = fl.AndRegexp("description", description)
+		}
+	}
+	return fl
+}
+
 // IsRegionalResource returns true if the resource URL is regional
 func IsRegionalResource(selfLink string) (bool, error) {
 	scope, err := ScopeFromSelfLink(selfLink)
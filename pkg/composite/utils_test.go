@@ -17,7 +17,9 @@ limitations under the License.
 package composite
 
 import (
+	"context"
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
@@ -171,3 +173,74 @@ func TestScopeFromSelfLink(t *testing.T) {
 		})
 	}
 }
+
+func TestListFilter(t *testing.T) {
+	t.Parallel()
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	for _, bs := range []*BackendService{
+		{Name: "k8s-be-30000--uid", Description: `{"kubernetes.io/service-name":"ns/svc"}`},
+		{Name: "k8s1-uid-ns-svc-80-hash", Description: `{"kubernetes.io/service-name":"ns/other"}`},
+		{Name: "other-backend", Description: `{"kubernetes.io/service-name":"ns/svc"}`},
+	} {
+		if err := CreateBackendService(context.TODO(), fakeGCE, meta.GlobalKey(bs.Name), bs); err != nil {
+			t.Fatalf("CreateBackendService(%s) = %v", bs.Name, err)
+		}
+	}
+
+	testCases := []struct {
+		desc                 string
+		namePrefix           string
+		descriptionSubstring string
+		wantFilter           string
+		want                 []string
+	}{
+		{
+			desc: "No filter",
+			want: []string{"k8s-be-30000--uid", "k8s1-uid-ns-svc-80-hash", "other-backend"},
+		},
+		{
+			desc:       "Name prefix",
+			namePrefix: "k8s",
+			wantFilter: "name eq k8s.*",
+			want:       []string{"k8s-be-30000--uid", "k8s1-uid-ns-svc-80-hash"},
+		},
+		{
+			desc:                 "Description substring",
+			descriptionSubstring: "ns/svc",
+			wantFilter:           "description eq .*ns/svc.*",
+			want:                 []string{"k8s-be-30000--uid", "other-backend"},
+		},
+		{
+			desc:                 "Name prefix and description substring",
+			namePrefix:           "k8s-be-",
+			descriptionSubstring: "ns/svc",
+			wantFilter:           "(name eq k8s-be-.*) (description eq .*ns/svc.*)",
+			want:                 []string{"k8s-be-30000--uid"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			fl := ListFilter(tc.namePrefix, tc.descriptionSubstring)
+			// filter.None is nil.
+			gotFilter := ""
+			if fl != nil {
+				gotFilter = fl.String()
+			}
+			if gotFilter != tc.wantFilter {
+				t.Errorf("ListFilter(%q, %q) = %q, want %q", tc.namePrefix, tc.descriptionSubstring, gotFilter, tc.wantFilter)
+			}
+			backends, err := ListBackendServicesWithFilter(context.TODO(), fakeGCE, meta.GlobalKey(""), meta.VersionGA, fl)
+			if err != nil {
+				t.Fatalf("ListBackendServicesWithFilter(%q) = %v", gotFilter, err)
+			}
+			var got []string
+			for _, bs := range backends {
+				got = append(got, bs.Name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ListBackendServicesWithFilter(%q) = %v, want %v", gotFilter, got, tc.want)
+			}
+		})
+	}
+}
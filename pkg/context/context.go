@@ -17,6 +17,7 @@ import (
 	context2 "context"
 	"fmt"
 	"sync"
+	"text/template"
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
@@ -26,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	informerv1 "k8s.io/client-go/informers/core/v1"
@@ -40,12 +42,17 @@ import (
 	backendconfigclient "k8s.io/ingress-gce/pkg/backendconfig/client/clientset/versioned"
 	informerbackendconfig "k8s.io/ingress-gce/pkg/backendconfig/client/informers/externalversions/backendconfig/v1"
 	"k8s.io/ingress-gce/pkg/cmconfig"
+	"k8s.io/ingress-gce/pkg/common/operator"
 	"k8s.io/ingress-gce/pkg/common/typed"
+	"k8s.io/ingress-gce/pkg/eventlog"
+	"k8s.io/ingress-gce/pkg/events"
 	frontendconfigclient "k8s.io/ingress-gce/pkg/frontendconfig/client/clientset/versioned"
 	informerfrontendconfig "k8s.io/ingress-gce/pkg/frontendconfig/client/informers/externalversions/frontendconfig/v1beta1"
 	ingparamsclient "k8s.io/ingress-gce/pkg/ingparams/client/clientset/versioned"
 	informeringparams "k8s.io/ingress-gce/pkg/ingparams/client/informers/externalversions/ingparams/v1beta1"
 	"k8s.io/ingress-gce/pkg/metrics"
+	"k8s.io/ingress-gce/pkg/multiproject"
+	"k8s.io/ingress-gce/pkg/quota"
 	serviceattachmentclient "k8s.io/ingress-gce/pkg/serviceattachment/client/clientset/versioned"
 	informerserviceattachment "k8s.io/ingress-gce/pkg/serviceattachment/client/informers/externalversions/serviceattachment/v1alpha1"
 	svcnegclient "k8s.io/ingress-gce/pkg/svcneg/client/clientset/versioned"
@@ -66,10 +73,14 @@ type ControllerContext struct {
 	KubeConfig            *rest.Config
 	KubeClient            kubernetes.Interface
 	SvcNegClient          svcnegclient.Interface
+	FrontendConfigClient  frontendconfigclient.Interface
 	DestinationRuleClient dynamic.NamespaceableResourceInterface
 	SAClient              serviceattachmentclient.Interface
 
 	Cloud *gce.Cloud
+	// ProjectRouter maps namespaces to the cloud of the project which hosts
+	// their load balancers.
+	ProjectRouter *multiproject.Router
 
 	ClusterNamer  *namer.Namer
 	KubeSystemUID types.UID
@@ -91,6 +102,7 @@ type ControllerContext struct {
 	IngClassInformer        cache.SharedIndexInformer
 	IngParamsInformer       cache.SharedIndexInformer
 	SAInformer              cache.SharedIndexInformer
+	SecretInformer          cache.SharedIndexInformer
 
 	ControllerMetrics *metrics.ControllerMetrics
 
@@ -98,19 +110,50 @@ type ControllerContext struct {
 
 	lock sync.Mutex
 
+	recorderLock sync.Mutex
 	// Map of namespace => record.EventRecorder.
 	recorders map[string]record.EventRecorder
+	// aggregators are the recorders which aggregate events, if enabled.
+	aggregators []*events.AggregatingRecorder
 }
 
 // ControllerContextConfig encapsulates some settings that are tunable via command line flags.
 type ControllerContextConfig struct {
 	Namespace    string
 	ResyncPeriod time.Duration
+	// ResyncSpread is the window over which periodic resyncs are spread.
+	// Zero means all keys are enqueued at once.
+	ResyncSpread time.Duration
 	NumL4Workers int
 	// DefaultBackendSvcPortID is the ServicePort for the system default backend.
 	DefaultBackendSvcPort utils.ServicePort
 	HealthCheckPath       string
 	FrontendConfigEnabled bool
+	// DefaultFrontendConfig is the FrontendConfig applied to Ingresses that
+	// do not reference one, if set.
+	DefaultFrontendConfig *types.NamespacedName
+	// DefaultBackendConfig is the BackendConfig applied to service ports
+	// that do not reference one, if set.
+	DefaultBackendConfig *types.NamespacedName
+	// DefaultBackendTimeout is the timeout of newly created backend services.
+	// Zero means the GCE default.
+	DefaultBackendTimeout time.Duration
+	// NamespaceLimits are the per-namespace limits on provisioned resources.
+	NamespaceLimits quota.Limits
+	// SyncDeadline is the maximum duration of the sync of an Ingress or L4
+	// Service. Zero means no deadline.
+	SyncDeadline time.Duration
+	// StatusHostnameTemplate is the template of the hostname published with
+	// the IP in the status of Ingresses, if set.
+	StatusHostnameTemplate *template.Template
+	// EventAggregationInterval is the interval at which repeated events
+	// of an object are recorded as one. Zero disables aggregation.
+	EventAggregationInterval time.Duration
+	// WatchSecrets enables the Secret informer, so that the Ingresses
+	// referencing a Secret are synced when it changes.
+	WatchSecrets bool
+	// EventLogSink mirrors the recorded Events into Cloud Logging, if set.
+	EventLogSink          *eventlog.Sink
 	EnableASMConfigMap    bool
 	ASMConfigMapNamespace string
 	ASMConfigMapName      string
@@ -136,14 +179,15 @@ func NewControllerContext(
 		SvcNegClient:            svcnegClient,
 		SAClient:                saClient,
 		Cloud:                   cloud,
+		ProjectRouter:           multiproject.NewSingleProjectRouter(cloud),
 		ClusterNamer:            clusterNamer,
 		L4Namer:                 namer.NewL4Namer(string(kubeSystemUID), clusterNamer),
 		KubeSystemUID:           kubeSystemUID,
 		ControllerMetrics:       metrics.NewControllerMetrics(),
 		ControllerContextConfig: config,
-		IngressInformer:         informernetworking.NewIngressInformer(kubeClient, config.Namespace, config.ResyncPeriod, utils.NewNamespaceIndexer()),
-		ServiceInformer:         informerv1.NewServiceInformer(kubeClient, config.Namespace, config.ResyncPeriod, utils.NewNamespaceIndexer()),
-		BackendConfigInformer:   informerbackendconfig.NewBackendConfigInformer(backendConfigClient, config.Namespace, config.ResyncPeriod, utils.NewNamespaceIndexer()),
+		IngressInformer:         informernetworking.NewIngressInformer(kubeClient, config.Namespace, config.ResyncPeriod, mergeIndexers(utils.NewNamespaceIndexer(), operator.IngressIndexers())),
+		ServiceInformer:         informerv1.NewServiceInformer(kubeClient, config.Namespace, config.ResyncPeriod, mergeIndexers(utils.NewNamespaceIndexer(), operator.ServiceIndexers())),
+		BackendConfigInformer:   informerbackendconfig.NewBackendConfigInformer(backendConfigClient, config.Namespace, config.ResyncPeriod, mergeIndexers(utils.NewNamespaceIndexer(), operator.BackendConfigIndexers())),
 		// Do not trigger periodic resync on Endpoints object.
 		// This aims improve NEG controller performance by avoiding unnecessary NEG sync that triggers for each NEG syncer.
 		// As periodic resync may temporary starve NEG API ratelimit quota.
@@ -156,6 +200,7 @@ func NewControllerContext(
 	}
 
 	if config.FrontendConfigEnabled {
+		context.FrontendConfigClient = frontendConfigClient
 		context.FrontendConfigInformer = informerfrontendconfig.NewFrontendConfigInformer(frontendConfigClient, config.Namespace, config.ResyncPeriod, utils.NewNamespaceIndexer())
 	}
 
@@ -168,9 +213,24 @@ func NewControllerContext(
 		context.SAInformer = informerserviceattachment.NewServiceAttachmentInformer(saClient, config.Namespace, config.ResyncPeriod, utils.NewNamespaceIndexer())
 	}
 
+	if config.WatchSecrets {
+		context.SecretInformer = informerv1.NewSecretInformer(kubeClient, config.Namespace, config.ResyncPeriod, utils.NewNamespaceIndexer())
+	}
+
 	return context
 }
 
+// mergeIndexers returns the union of the given indexers.
+func mergeIndexers(indexers ...cache.Indexers) cache.Indexers {
+	ret := cache.Indexers{}
+	for _, idx := range indexers {
+		for name, f := range idx {
+			ret[name] = f
+		}
+	}
+	return ret
+}
+
 // Init inits the Context, so that we can defers some config until the main thread enter actually get the leader lock.
 func (ctx *ControllerContext) Init() {
 	klog.V(2).Infof("Controller Context initializing with %+v", ctx.ControllerContextConfig)
@@ -279,6 +339,10 @@ func (ctx *ControllerContext) HasSynced() bool {
 		funcs = append(funcs, ctx.SAInformer.HasSynced)
 	}
 
+	if ctx.SecretInformer != nil {
+		funcs = append(funcs, ctx.SecretInformer.HasSynced)
+	}
+
 	for _, f := range funcs {
 		if !f() {
 			return false
@@ -289,6 +353,9 @@ func (ctx *ControllerContext) HasSynced() bool {
 
 // Recorder return the event recorder for the given namespace.
 func (ctx *ControllerContext) Recorder(ns string) record.EventRecorder {
+	ctx.recorderLock.Lock()
+	defer ctx.recorderLock.Unlock()
+
 	if rec, ok := ctx.recorders[ns]; ok {
 		return rec
 	}
@@ -298,7 +365,15 @@ func (ctx *ControllerContext) Recorder(ns string) record.EventRecorder {
 	broadcaster.StartRecordingToSink(&corev1.EventSinkImpl{
 		Interface: ctx.KubeClient.CoreV1().Events(ns),
 	})
-	rec := broadcaster.NewRecorder(ctx.generateScheme(), apiv1.EventSource{Component: "loadbalancer-controller"})
+	if ctx.EventLogSink != nil {
+		broadcaster.StartEventWatcher(ctx.EventLogSink.Record)
+	}
+	var rec record.EventRecorder = broadcaster.NewRecorder(ctx.generateScheme(), apiv1.EventSource{Component: "loadbalancer-controller"})
+	if ctx.EventAggregationInterval > 0 {
+		aggregator := events.NewAggregatingRecorder(rec)
+		ctx.aggregators = append(ctx.aggregators, aggregator)
+		rec = aggregator
+	}
 	ctx.recorders[ns] = rec
 
 	return rec
@@ -361,8 +436,25 @@ func (ctx *ControllerContext) Start(stopCh chan struct{}) {
 	if ctx.SAInformer != nil {
 		go ctx.SAInformer.Run(stopCh)
 	}
+	if ctx.SecretInformer != nil {
+		go ctx.SecretInformer.Run(stopCh)
+	}
 	// Export ingress usage metrics.
 	go ctx.ControllerMetrics.Run(stopCh)
+	if ctx.EventAggregationInterval > 0 {
+		go wait.Until(ctx.flushEvents, ctx.EventAggregationInterval, stopCh)
+	}
+}
+
+// flushEvents records the events aggregated by the recorders.
+func (ctx *ControllerContext) flushEvents() {
+	ctx.recorderLock.Lock()
+	aggregators := append([]*events.AggregatingRecorder(nil), ctx.aggregators...)
+	ctx.recorderLock.Unlock()
+
+	for _, aggregator := range aggregators {
+		aggregator.Flush()
+	}
 }
 
 // Ingresses returns the store of Ingresses.
@@ -17,6 +17,8 @@ limitations under the License.
 package controller
 
 import (
+	context2 "context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -27,6 +29,8 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	unversionedcore "k8s.io/client-go/kubernetes/typed/core/v1"
 	listers "k8s.io/client-go/listers/core/v1"
@@ -43,19 +47,27 @@ import (
 	"k8s.io/ingress-gce/pkg/flags"
 	"k8s.io/ingress-gce/pkg/frontendconfig"
 	"k8s.io/ingress-gce/pkg/healthchecks"
+	"k8s.io/ingress-gce/pkg/ingparams"
 	"k8s.io/ingress-gce/pkg/instances"
 	"k8s.io/ingress-gce/pkg/loadbalancers"
 	"k8s.io/ingress-gce/pkg/loadbalancers/features"
 	"k8s.io/ingress-gce/pkg/metrics"
 	negtypes "k8s.io/ingress-gce/pkg/neg/types"
+	"k8s.io/ingress-gce/pkg/quota"
 	ingsync "k8s.io/ingress-gce/pkg/sync"
 	"k8s.io/ingress-gce/pkg/translator"
+	"k8s.io/ingress-gce/pkg/urlmaps"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/ingress-gce/pkg/utils/common"
 	"k8s.io/ingress-gce/pkg/utils/namer"
 	"k8s.io/klog"
 )
 
+// gcKey is the key of the ingress queue which triggers a garbage collection
+// pass. It is not the key of any Ingress, so its sync collects the resources
+// of all deleted Ingresses like the sync of a deleted Ingress.
+const gcKey = "gc"
+
 // LoadBalancerController watches the kubernetes api and adds/removes services
 // from the loadbalancer, via loadBalancerConfig.
 type LoadBalancerController struct {
@@ -95,6 +107,14 @@ type LoadBalancerController struct {
 
 	ingClassLister  cache.Indexer
 	ingParamsLister cache.Indexer
+
+	// warmup probes the VIP of Ingresses before they are marked Ready. It is
+	// nil if ingress warm-up is disabled.
+	warmup *ingressWarmup
+
+	// handoff persists the sync state for the next leader. It is nil if state
+	// handoff is disabled.
+	handoff *stateHandoff
 }
 
 // NewLoadBalancerController creates a controller for gce loadbalancers.
@@ -108,7 +128,7 @@ func NewLoadBalancerController(
 		Interface: ctx.KubeClient.CoreV1().Events(""),
 	})
 
-	healthChecker := healthchecks.NewHealthChecker(ctx.Cloud, ctx.HealthCheckPath, ctx.DefaultBackendSvcPort.ID.Service)
+	healthChecker := healthchecks.NewHealthChecker(ctx.Cloud, ctx.HealthCheckPath, ctx.DefaultBackendSvcPort.ID.Service, ctx)
 	instancePool := instances.NewNodePool(ctx.Cloud, ctx.ClusterNamer, ctx, utils.GetBasePath(ctx.Cloud))
 	backendPool := backends.NewPool(ctx.Cloud, ctx.ClusterNamer)
 
@@ -120,7 +140,7 @@ func NewLoadBalancerController(
 		hasSynced:     ctx.HasSynced,
 		nodes:         NewNodeController(ctx, instancePool),
 		instancePool:  instancePool,
-		l7Pool:        loadbalancers.NewLoadBalancerPool(ctx.Cloud, ctx.ClusterNamer, ctx, namer.NewFrontendNamerFactory(ctx.ClusterNamer, ctx.KubeSystemUID)),
+		l7Pool:        loadbalancers.NewLoadBalancerPool(ctx.ProjectRouter, ctx.ClusterNamer, ctx, namer.NewFrontendNamerFactory(ctx.ClusterNamer, ctx.KubeSystemUID)),
 		backendSyncer: backends.NewBackendSyncer(backendPool, healthChecker, ctx.Cloud),
 		negLinker:     backends.NewNEGLinker(backendPool, negtypes.NewAdapter(ctx.Cloud), ctx.Cloud),
 		igLinker:      backends.NewInstanceGroupLinker(instancePool, backendPool),
@@ -132,9 +152,17 @@ func NewLoadBalancerController(
 		lbc.ingParamsLister = ctx.IngParamsInformer.GetIndexer()
 	}
 
+	if flags.F.EnableIngressWarmup {
+		lbc.warmup = newIngressWarmup()
+	}
+
+	if flags.F.StateHandoffPeriod > 0 {
+		lbc.handoff = newStateHandoff(ctx.KubeClient, flags.F.LeaderElection.LockObjectNamespace, flags.F.LeaderElection.LockObjectName+"-state")
+	}
+
 	lbc.ingSyncer = ingsync.NewIngressSyncer(&lbc)
 
-	lbc.ingQueue = utils.NewPeriodicTaskQueue("ingress", "ingresses", lbc.sync)
+	lbc.ingQueue = utils.NewPeriodicTaskQueue("ingress", "ingresses", utils.WithSyncDeadline("ingresses", ctx.SyncDeadline, lbc.recordedSync, lbc.syncDeadlineExceeded))
 
 	// Ingress event handlers.
 	ctx.IngressInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -183,13 +211,15 @@ func NewLoadBalancerController(
 				}
 				return
 			}
+			var delay time.Duration
 			if reflect.DeepEqual(old, cur) {
-				klog.V(2).Infof("Periodic enqueueing of %s", common.NamespacedName(curIng))
+				delay = utils.ResyncDelay(common.NamespacedName(curIng), lbc.ctx.ResyncSpread)
+				klog.V(2).Infof("Periodic enqueueing of %s in %v", common.NamespacedName(curIng), delay)
 			} else {
 				klog.V(2).Infof("Ingress %s changed, enqueuing", common.NamespacedName(curIng))
 			}
 			lbc.ctx.Recorder(curIng.Namespace).Eventf(curIng, apiv1.EventTypeNormal, events.SyncIngress, "Scheduled for sync")
-			lbc.ingQueue.Enqueue(cur)
+			lbc.ingQueue.EnqueueAfter(cur, delay)
 		},
 	})
 
@@ -197,13 +227,13 @@ func NewLoadBalancerController(
 	ctx.ServiceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			svc := obj.(*apiv1.Service)
-			ings := operator.Ingresses(ctx.Ingresses().List()).ReferencesService(svc).AsList()
+			ings := lbc.ingressesForService(svc)
 			lbc.ingQueue.Enqueue(convert(ings)...)
 		},
 		UpdateFunc: func(old, cur interface{}) {
 			if !reflect.DeepEqual(old, cur) {
 				svc := cur.(*apiv1.Service)
-				ings := operator.Ingresses(ctx.Ingresses().List()).ReferencesService(svc).AsList()
+				ings := lbc.ingressesForService(svc)
 				lbc.ingQueue.Enqueue(convert(ings)...)
 			}
 		},
@@ -215,14 +245,14 @@ func NewLoadBalancerController(
 		AddFunc: func(obj interface{}) {
 			klog.V(3).Infof("obj(type %T) added", obj)
 			beConfig := obj.(*backendconfigv1.BackendConfig)
-			ings := operator.Ingresses(ctx.Ingresses().List()).ReferencesBackendConfig(beConfig, operator.Services(ctx.Services().List())).AsList()
+			ings := lbc.ingressesForBackendConfig(beConfig)
 			lbc.ingQueue.Enqueue(convert(ings)...)
 		},
 		UpdateFunc: func(old, cur interface{}) {
 			if !reflect.DeepEqual(old, cur) {
 				klog.V(3).Infof("obj(type %T) updated", cur)
 				beConfig := cur.(*backendconfigv1.BackendConfig)
-				ings := operator.Ingresses(ctx.Ingresses().List()).ReferencesBackendConfig(beConfig, operator.Services(ctx.Services().List())).AsList()
+				ings := lbc.ingressesForBackendConfig(beConfig)
 				lbc.ingQueue.Enqueue(convert(ings)...)
 			}
 		},
@@ -246,24 +276,46 @@ func NewLoadBalancerController(
 				}
 			}
 
-			ings := operator.Ingresses(ctx.Ingresses().List()).ReferencesBackendConfig(beConfig, operator.Services(ctx.Services().List())).AsList()
+			ings := lbc.ingressesForBackendConfig(beConfig)
 			lbc.ingQueue.Enqueue(convert(ings)...)
 		},
 	})
 
+	// Secret event handlers.
+	if ctx.SecretInformer != nil {
+		ctx.SecretInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				secret := obj.(*apiv1.Secret)
+				ings := lbc.ingressesForSecret(secret.Namespace, secret.Name)
+				lbc.ingQueue.Enqueue(convert(ings)...)
+			},
+			UpdateFunc: func(old, cur interface{}) {
+				if !reflect.DeepEqual(old, cur) {
+					secret := cur.(*apiv1.Secret)
+					ings := lbc.ingressesForSecret(secret.Namespace, secret.Name)
+					lbc.ingQueue.Enqueue(convert(ings)...)
+				}
+			},
+			// Deleted Secrets fail the sync of the Ingresses referencing
+			// them on the next resync.
+		})
+	}
+
 	// FrontendConfig event handlers.
 	if ctx.FrontendConfigEnabled {
 		ctx.FrontendConfigInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
 				feConfig := obj.(*frontendconfigv1beta1.FrontendConfig)
-				ings := operator.Ingresses(ctx.Ingresses().List()).ReferencesFrontendConfig(feConfig).AsList()
+				ings := lbc.ingressesForFrontendConfig(ctx.Ingresses().List(), feConfig)
 				lbc.ingQueue.Enqueue(convert(ings)...)
 
 			},
 			UpdateFunc: func(old, cur interface{}) {
-				if !reflect.DeepEqual(old, cur) {
+				// Status updates are written by this controller and do not
+				// require the Ingresses to be synced again.
+				if !reflect.DeepEqual(old.(*frontendconfigv1beta1.FrontendConfig).Spec, cur.(*frontendconfigv1beta1.FrontendConfig).Spec) {
 					feConfig := cur.(*frontendconfigv1beta1.FrontendConfig)
-					ings := operator.Ingresses(ctx.Ingresses().List()).ReferencesFrontendConfig(feConfig).AsList()
+					ings := lbc.ingressesForFrontendConfig(ctx.Ingresses().List(), feConfig)
 					lbc.ingQueue.Enqueue(convert(ings)...)
 				}
 			},
@@ -286,7 +338,7 @@ func NewLoadBalancerController(
 					}
 				}
 
-				ings := operator.Ingresses(ctx.Ingresses().List()).ReferencesFrontendConfig(feConfig).AsList()
+				ings := lbc.ingressesForFrontendConfig(ctx.Ingresses().List(), feConfig)
 				lbc.ingQueue.Enqueue(convert(ings)...)
 			},
 		})
@@ -294,7 +346,7 @@ func NewLoadBalancerController(
 
 	// Register health check on controller context.
 	ctx.AddHealthCheck("ingress", func() error {
-		_, err := backendPool.Get("k8s-ingress-svc-acct-permission-check-probe", meta.VersionGA, meta.Global)
+		_, err := backendPool.Get(context2.TODO(), "k8s-ingress-svc-acct-permission-check-probe", meta.VersionGA, meta.Global)
 
 		// If this container is scheduled on a node without compute/rw it is
 		// effectively useless, but it is healthy. Reporting it as unhealthy
@@ -316,13 +368,20 @@ func (lbc *LoadBalancerController) Init() {
 	// TODO(rramkumar): Try to get rid of this "Init".
 	lbc.instancePool.Init(lbc.Translator)
 	lbc.backendSyncer.Init(lbc.Translator)
+	lbc.restoreHandoffState()
 }
 
 // Run starts the loadbalancer controller.
 func (lbc *LoadBalancerController) Run() {
 	klog.Infof("Starting loadbalancer controller")
 	go lbc.ingQueue.Run()
-	go lbc.nodes.Run()
+	if lbc.handoff != nil {
+		go wait.Until(lbc.saveHandoffState, flags.F.StateHandoffPeriod, lbc.stopCh)
+	}
+	// Instance groups are not used with restricted node access.
+	if !flags.F.EnableRestrictedNodeAccess {
+		go lbc.nodes.Run()
+	}
 
 	<-lbc.stopCh
 	klog.Infof("Shutting down Loadbalancer Controller")
@@ -348,16 +407,77 @@ func (lbc *LoadBalancerController) Stop(deleteAll bool) error {
 	// TODO(rramkumar): Do we need deleteAll? Can we get rid of its' flag?
 	if deleteAll {
 		klog.Infof("Shutting down cluster manager.")
-		if err := lbc.l7Pool.Shutdown(lbc.ctx.Ingresses().List()); err != nil {
+		if err := lbc.l7Pool.Shutdown(context2.TODO(), lbc.ctx.Ingresses().List()); err != nil {
 			return err
 		}
 
 		// The backend pool will also delete instance groups.
-		return lbc.backendSyncer.Shutdown()
+		return lbc.backendSyncer.Shutdown(context2.TODO())
 	}
 	return nil
 }
 
+// restoreHandoffState resumes from the sync state persisted by the previous
+// leader: backend services it found in sync are not fetched again until their
+// verify period expires, and Ingresses whose sync failed are enqueued.
+func (lbc *LoadBalancerController) restoreHandoffState() {
+	if lbc.handoff == nil {
+		return
+	}
+	state, err := lbc.handoff.load()
+	if err != nil {
+		klog.Errorf("Failed to load handoff state, starting cold: %v", err)
+		return
+	}
+	if state == nil {
+		return
+	}
+	klog.V(2).Infof("Restoring handoff state: %d backend fingerprints, %d pending Ingresses", len(state.Backends), len(state.Pending))
+	lbc.backendSyncer.RestoreFingerprints(state.Backends)
+	for _, key := range state.Pending {
+		lbc.ingQueue.Enqueue(cache.ExplicitKey(key))
+	}
+}
+
+// saveHandoffState persists the sync state for the next leader.
+func (lbc *LoadBalancerController) saveHandoffState() {
+	state := &handoffState{
+		Pending:  lbc.handoff.pendingKeys(),
+		Backends: lbc.backendSyncer.Fingerprints(),
+	}
+	if err := lbc.handoff.save(state); err != nil {
+		klog.Errorf("Failed to save handoff state: %v", err)
+	}
+}
+
+// recordedSync syncs the given key and records its result for the next
+// leader.
+func (lbc *LoadBalancerController) recordedSync(ctx context2.Context, key string) error {
+	err := lbc.syncContext(ctx, key)
+	if lbc.handoff != nil {
+		lbc.handoff.recordSync(key, err)
+	}
+	return err
+}
+
+// Resync enqueues every Ingress managed by the controller for an immediate
+// sync, e.g. after GCE resources were changed or deleted manually.
+func (lbc *LoadBalancerController) Resync() {
+	ings := operator.Ingresses(lbc.ctx.Ingresses().List()).Filter(func(ing *v1.Ingress) bool {
+		return utils.IsGLBCIngress(ing) || common.HasFinalizer(ing.ObjectMeta)
+	}).AsList()
+	klog.V(2).Infof("Resync requested, enqueuing %d ingresses", len(ings))
+	lbc.ingQueue.Enqueue(convert(ings)...)
+}
+
+// TriggerGC enqueues a garbage collection pass of the frontends of deleted
+// Ingresses using the v1 naming scheme and of unused backends. The pass is
+// run by the ingress queue, so it does not race with the sync of an Ingress.
+func (lbc *LoadBalancerController) TriggerGC() {
+	klog.V(2).Infof("Garbage collection requested, enqueuing")
+	lbc.ingQueue.Enqueue(cache.ExplicitKey(gcKey))
+}
+
 // SyncBackends implements Controller.
 func (lbc *LoadBalancerController) SyncBackends(state interface{}) error {
 	// We expect state to be a syncState
@@ -370,15 +490,19 @@ func (lbc *LoadBalancerController) SyncBackends(state interface{}) error {
 	// Only sync instance group when IG is used for this ingress
 	if len(nodePorts(ingSvcPorts)) > 0 {
 		if err := lbc.syncInstanceGroup(syncState.ing, ingSvcPorts); err != nil {
-			klog.Errorf("Failed to sync instance group for ingress %v: %v", syncState.ing, err)
+			klog.Errorf("Failed to sync instance group for ingress %v/%v (sync %s): %v", syncState.ing.Namespace, syncState.ing.Name, syncState.syncID, err)
 			return err
 		}
 	} else {
-		klog.V(2).Infof("Skip syncing instance groups for ingress %v/%v", syncState.ing.Namespace, syncState.ing.Name)
+		klog.V(2).Infof("Skip syncing instance groups for ingress %v/%v (sync %s)", syncState.ing.Namespace, syncState.ing.Name, syncState.syncID)
 	}
 
 	// Sync the backends
-	if err := lbc.backendSyncer.Sync(ingSvcPorts); err != nil {
+	if err := utils.CheckSyncDeadline(syncState.ctx); err != nil {
+		return err
+	}
+	klog.V(3).Infof("Syncing backends of ingress %v/%v (sync %s)", syncState.ing.Namespace, syncState.ing.Name, syncState.syncID)
+	if err := lbc.backendSyncer.Sync(syncState.ctx, ingSvcPorts); err != nil {
 		return err
 	}
 
@@ -395,13 +519,16 @@ func (lbc *LoadBalancerController) SyncBackends(state interface{}) error {
 
 	// Link backends to groups.
 	for _, sp := range ingSvcPorts {
+		if err := utils.CheckSyncDeadline(syncState.ctx); err != nil {
+			return err
+		}
 		var linkErr error
 		if sp.NEGEnabled {
 			// Link backend to NEG's if the backend has NEG enabled.
-			linkErr = lbc.negLinker.Link(sp, groupKeys)
+			linkErr = lbc.negLinker.Link(syncState.ctx, sp, groupKeys)
 		} else {
 			// Otherwise, link backend to IG's.
-			linkErr = lbc.igLinker.Link(sp, groupKeys)
+			linkErr = lbc.igLinker.Link(syncState.ctx, sp, groupKeys)
 		}
 		if linkErr != nil {
 			return linkErr
@@ -450,25 +577,98 @@ func (lbc *LoadBalancerController) syncInstanceGroup(ing *v1.Ingress, ingSvcPort
 }
 
 // GCBackends implements Controller.
-func (lbc *LoadBalancerController) GCBackends(toKeep []*v1.Ingress) error {
+func (lbc *LoadBalancerController) GCBackends(ctx context2.Context, toKeep []*v1.Ingress) error {
 	// Only GCE ingress associated resources are managed by this controller.
 	GCEIngresses := operator.Ingresses(toKeep).Filter(utils.IsGCEIngress).AsList()
 	svcPortsToKeep := lbc.ToSvcPorts(GCEIngresses)
-	if err := lbc.backendSyncer.GC(svcPortsToKeep); err != nil {
+	if err := lbc.backendSyncer.GC(ctx, svcPortsToKeep); err != nil {
 		return err
 	}
 	// TODO(ingress#120): Move this to the backend pool so it mirrors creation
 	// Do not delete instance group if there exists a GLBC ingress.
+	igName := lbc.ctx.ClusterNamer.InstanceGroup()
 	if len(toKeep) == 0 {
-		igName := lbc.ctx.ClusterNamer.InstanceGroup()
 		klog.Infof("Deleting instance group %v", igName)
 		if err := lbc.instancePool.DeleteInstanceGroup(igName); err != err {
 			return err
 		}
+		return nil
+	}
+	igPorts := nodePorts(lbc.ToSvcPorts(instanceGroupIngresses(toKeep)))
+	if flags.F.EnableInstanceGroupGC {
+		return lbc.instancePool.GC(igName, igPorts)
+	}
+	// Only report what would be deleted until GC is enabled.
+	if !klog.V(2) {
+		return nil
+	}
+	decisions, err := lbc.instancePool.ExplainGC(igName, igPorts)
+	if err != nil {
+		klog.Warningf("Failed to explain GC of instance group %v: %v", igName, err)
+		return nil
+	}
+	for _, d := range decisions {
+		if d.Delete {
+			klog.Infof("GC decision (not applied, --enable-instance-group-gc is disabled): %v", d)
+		}
 	}
 	return nil
 }
 
+// instanceGroupIngresses returns the Ingresses whose backends may use the
+// instance groups of the cluster.
+func instanceGroupIngresses(ings []*v1.Ingress) []*v1.Ingress {
+	return operator.Ingresses(ings).Filter(func(ing *v1.Ingress) bool {
+		return utils.IsGCEIngress(ing) || utils.IsGCEMultiClusterIngress(ing)
+	}).AsList()
+}
+
+// ExplainGC returns the decisions garbage collection would currently take for
+// the GCE resources of the cluster, without deleting any. The frontends of
+// Ingresses using the v2 naming scheme are only collected when the Ingress is
+// deleted, they are reported for Ingresses which are being deleted.
+func (lbc *LoadBalancerController) ExplainGC() ([]utils.GCDecision, error) {
+	allIngresses := lbc.ctx.Ingresses().List()
+	toCleanup, toKeep := operator.Ingresses(allIngresses).Partition(utils.NeedsCleanup)
+
+	toKeepV1 := toKeep.Filter(func(ing *v1.Ingress) bool {
+		return namer.FrontendNamingScheme(ing) == namer.V1NamingScheme && utils.IsGCEIngress(ing)
+	})
+	decisions, err := lbc.l7Pool.ExplainGCv1(context2.TODO(), common.ToIngressKeys(toKeepV1.AsList()))
+	if err != nil {
+		return nil, err
+	}
+	namerFactory := namer.NewFrontendNamerFactory(lbc.ctx.ClusterNamer, lbc.ctx.KubeSystemUID)
+	for _, ing := range toCleanup.AsList() {
+		if namer.FrontendNamingScheme(ing) != namer.V2NamingScheme {
+			continue
+		}
+		decisions = append(decisions, utils.GCDecision{
+			Kind:     "LoadBalancer",
+			Name:     string(namerFactory.Namer(ing).LoadBalancer()),
+			Scope:    string(features.ScopeFromIngress(ing)),
+			Evidence: fmt.Sprintf("Ingress %s is being deleted", common.NamespacedName(ing)),
+			Delete:   true,
+		})
+	}
+
+	GCEIngresses := toKeep.Filter(utils.IsGCEIngress).AsList()
+	backendDecisions, err := lbc.backendSyncer.ExplainGC(context2.TODO(), lbc.ToSvcPorts(GCEIngresses))
+	if err != nil {
+		return nil, err
+	}
+	decisions = append(decisions, backendDecisions...)
+	if len(toKeep.AsList()) > 0 {
+		igPorts := nodePorts(lbc.ToSvcPorts(instanceGroupIngresses(toKeep.AsList())))
+		igDecisions, err := lbc.instancePool.ExplainGC(lbc.ctx.ClusterNamer.InstanceGroup(), igPorts)
+		if err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, igDecisions...)
+	}
+	return decisions, nil
+}
+
 // SyncLoadBalancer implements Controller.
 func (lbc *LoadBalancerController) SyncLoadBalancer(state interface{}) error {
 	// We expect state to be a syncState
@@ -481,9 +681,13 @@ func (lbc *LoadBalancerController) SyncLoadBalancer(state interface{}) error {
 	if err != nil {
 		return err
 	}
+	lb.SyncID = syncState.syncID
 
 	// Create higher-level LB resources.
-	l7, err := lbc.l7Pool.Ensure(lb)
+	if err := utils.CheckSyncDeadline(syncState.ctx); err != nil {
+		return err
+	}
+	l7, err := lbc.l7Pool.Ensure(syncState.ctx, lb)
 	if err != nil {
 		return err
 	}
@@ -493,13 +697,13 @@ func (lbc *LoadBalancerController) SyncLoadBalancer(state interface{}) error {
 }
 
 // GCv1LoadBalancers implements Controller.
-func (lbc *LoadBalancerController) GCv1LoadBalancers(toKeep []*v1.Ingress) error {
-	return lbc.l7Pool.GCv1(common.ToIngressKeys(toKeep))
+func (lbc *LoadBalancerController) GCv1LoadBalancers(ctx context2.Context, toKeep []*v1.Ingress) error {
+	return lbc.l7Pool.GCv1(ctx, common.ToIngressKeys(toKeep))
 }
 
 // GCv2LoadBalancer implements Controller.
-func (lbc *LoadBalancerController) GCv2LoadBalancer(ing *v1.Ingress, scope meta.KeyType) error {
-	return lbc.l7Pool.GCv2(ing, scope)
+func (lbc *LoadBalancerController) GCv2LoadBalancer(ctx context2.Context, ing *v1.Ingress, scope meta.KeyType) error {
+	return lbc.l7Pool.GCv2(ctx, ing, scope)
 }
 
 // EnsureDeleteV1Finalizers implements Controller.
@@ -541,16 +745,24 @@ func (lbc *LoadBalancerController) PostProcess(state interface{}) error {
 	}
 
 	// Update the ingress status.
-	return lbc.updateIngressStatus(syncState.l7, syncState.ing)
+	return lbc.updateIngressStatus(syncState.ctx, syncState.l7, syncState.ing)
 }
 
 // sync manages Ingress create/updates/deletes events from queue.
+// sync syncs the Ingress with the given key without a deadline.
 func (lbc *LoadBalancerController) sync(key string) error {
+	return lbc.syncContext(context2.Background(), key)
+}
+
+// syncContext syncs the Ingress with the given key. The GCE calls of the
+// sync are cancelled, and the sync is aborted, once ctx expires.
+func (lbc *LoadBalancerController) syncContext(ctx context2.Context, key string) error {
 	if !lbc.hasSynced() {
 		time.Sleep(context.StoreSyncPollPeriod)
 		return fmt.Errorf("waiting for stores to sync")
 	}
-	klog.V(3).Infof("Syncing %v", key)
+	syncID := utils.NewSyncID()
+	klog.V(3).Infof("Syncing %v (sync %s)", key, syncID)
 
 	ing, ingExists, err := lbc.ctx.Ingresses().GetByKey(key)
 	if err != nil {
@@ -565,55 +777,77 @@ func (lbc *LoadBalancerController) sync(key string) error {
 	if !ingExists || utils.NeedsCleanup(ing) {
 		frontendGCAlgorithm := frontendGCAlgorithm(ingExists, false, ing)
 		// GC will find GCE resources that were used for this ingress and delete them.
-		err := lbc.ingSyncer.GC(allIngresses, ing, frontendGCAlgorithm, scope)
+		err := lbc.ingSyncer.GC(ctx, allIngresses, ing, frontendGCAlgorithm, scope)
 		// Skip emitting an event if ingress does not exist as we cannot retrieve ingress namespace.
 		if err != nil && ingExists {
-			klog.Errorf("Error in GC for %s/%s: %v", ing.Namespace, ing.Name, err)
-			lbc.ctx.Recorder(ing.Namespace).Eventf(ing, apiv1.EventTypeWarning, events.GarbageCollection, "Error: %v", err)
+			klog.Errorf("Error in GC for %s/%s (sync %s): %v", ing.Namespace, ing.Name, syncID, err)
+			events.WithSyncID(lbc.ctx.Recorder(ing.Namespace), syncID).Eventf(ing, apiv1.EventTypeWarning, events.GarbageCollection, "Error: %v", err)
 		}
 		// Delete the ingress state for metrics after GC is successful.
 		if err == nil && ingExists {
 			lbc.metrics.DeleteIngress(key)
 		}
+		if err == nil && lbc.warmup != nil {
+			lbc.warmup.forget(key)
+		}
 		return err
 	}
 
 	// Ensure that a finalizer is attached.
 	if flags.F.FinalizerAdd {
-		if ing, err = lbc.ensureFinalizer(ing); err != nil {
+		if ing, err = lbc.ensureFinalizer(ctx, ing); err != nil {
 			return err
 		}
 	}
 
+	if err := lbc.checkNamespaceLimits(ing, allIngresses); err != nil {
+		if quota.IsExceeded(err) {
+			events.WithSyncID(lbc.ctx.Recorder(ing.Namespace), syncID).Eventf(ing, apiv1.EventTypeWarning, events.QuotaExceeded, "Ingress not synced: %v", err)
+		}
+		return err
+	}
+
 	// Bootstrap state for GCP sync.
 	urlMap, errs := lbc.Translator.TranslateIngress(ing, lbc.ctx.DefaultBackendSvcPort.ID, lbc.ctx.ClusterNamer)
 
 	if errs != nil {
 		msg := fmt.Errorf("invalid ingress spec: %v", utils.JoinErrs(errs))
-		lbc.ctx.Recorder(ing.Namespace).Eventf(ing, apiv1.EventTypeWarning, events.TranslateIngress, "Translation failed: %v", msg)
+		events.WithSyncID(lbc.ctx.Recorder(ing.Namespace), syncID).Eventf(ing, apiv1.EventTypeWarning, events.TranslateIngress, "Translation failed: %v", msg)
+		return msg
+	}
+	if errs := urlmaps.CheckLimits(urlMap); errs != nil {
+		msg := fmt.Errorf("url map exceeds GCE limits: %v", utils.JoinErrs(errs))
+		events.WithSyncID(lbc.ctx.Recorder(ing.Namespace), syncID).Eventf(ing, apiv1.EventTypeWarning, events.LimitExceeded, "Ingress not synced: %v", msg)
 		return msg
 	}
 
 	// Sync GCP resources.
-	syncState := &syncState{urlMap, ing, nil}
+	syncState := &syncState{urlMap: urlMap, ing: ing, syncID: syncID, ctx: ctx}
 	syncErr := lbc.ingSyncer.Sync(syncState)
 	if syncErr != nil {
-		lbc.ctx.Recorder(ing.Namespace).Eventf(ing, apiv1.EventTypeWarning, events.SyncIngress, "Error syncing to GCP: %v", syncErr.Error())
+		klog.Errorf("Error syncing %v (sync %s): %v", key, syncID, syncErr)
+		events.WithSyncID(lbc.ctx.Recorder(ing.Namespace), syncID).Eventf(ing, apiv1.EventTypeWarning, events.SyncIngress, "Error syncing to GCP: %v", syncErr.Error())
 	} else {
 		// Insert/update the ingress state for metrics after successful sync.
 		var fc *frontendconfigv1beta1.FrontendConfig
 		if flags.F.EnableFrontendConfig {
-			fc, err = frontendconfig.FrontendConfigForIngress(lbc.ctx.FrontendConfigs().List(), ing)
+			fc, err = lbc.frontendConfigForIngress(ing)
 			if err != nil {
 				return err
 			}
 		}
 		lbc.metrics.SetIngress(key, metrics.NewIngressState(ing, fc, urlMap.AllServicePorts()))
 	}
+	lbc.updateFrontendConfigStatus(allIngresses, ing, syncErr)
+	// An aborted sync is requeued without garbage collection, which could
+	// block the worker as well.
+	if err := utils.CheckSyncDeadline(ctx); err != nil {
+		return err
+	}
 
 	// Check for scope change GC
 	var oldScope *meta.KeyType
-	oldScope, err = lbc.l7Pool.FrontendScopeChangeGC(ing)
+	oldScope, err = lbc.l7Pool.FrontendScopeChangeGC(ctx, ing)
 	if err != nil {
 		return err
 	}
@@ -625,44 +859,219 @@ func (lbc *LoadBalancerController) sync(key string) error {
 	// it could have been caused by quota issues; therefore, garbage collecting now may
 	// free up enough quota for the next sync to pass.
 	frontendGCAlgorithm := frontendGCAlgorithm(ingExists, oldScope != nil, ing)
-	if gcErr := lbc.ingSyncer.GC(allIngresses, ing, frontendGCAlgorithm, scope); gcErr != nil {
-		lbc.ctx.Recorder(ing.Namespace).Eventf(ing, apiv1.EventTypeWarning, events.GarbageCollection, "Error during garbage collection: %v", gcErr)
+	if gcErr := lbc.ingSyncer.GC(ctx, allIngresses, ing, frontendGCAlgorithm, scope); gcErr != nil {
+		events.WithSyncID(lbc.ctx.Recorder(ing.Namespace), syncID).Eventf(ing, apiv1.EventTypeWarning, events.GarbageCollection, "Error during garbage collection: %v", gcErr)
 		return fmt.Errorf("error during sync %v, error during GC %v", syncErr, gcErr)
 	}
 
 	return syncErr
 }
 
+// ingressesForBackendConfig returns the Ingresses that beConfig applies to.
+// The default BackendConfig may apply to any Ingress.
+func (lbc *LoadBalancerController) ingressesForBackendConfig(beConfig *backendconfigv1.BackendConfig) []*v1.Ingress {
+	if name := lbc.ctx.DefaultBackendConfig; name != nil && name.Namespace == beConfig.Namespace && name.Name == beConfig.Name {
+		return lbc.ctx.Ingresses().List()
+	}
+	ingIndexer := lbc.ctx.IngressInformer.GetIndexer()
+	ings, err := operator.IngressesByIndex(ingIndexer, operator.BackendConfigIndex, beConfig.Namespace, beConfig.Name)
+	if err != nil {
+		klog.Errorf("Failed to look up the Ingresses referencing BackendConfig %s/%s, checking all: %v", beConfig.Namespace, beConfig.Name, err)
+		return operator.Ingresses(lbc.ctx.Ingresses().List()).ReferencesBackendConfig(beConfig, operator.Services(lbc.ctx.Services().List())).AsList()
+	}
+	svcs, err := operator.ServicesByIndex(lbc.ctx.ServiceInformer.GetIndexer(), operator.BackendConfigIndex, beConfig.Namespace, beConfig.Name)
+	if err != nil {
+		klog.Errorf("Failed to look up the Services referencing BackendConfig %s/%s, checking all: %v", beConfig.Namespace, beConfig.Name, err)
+		return operator.Ingresses(lbc.ctx.Ingresses().List()).ReferencesBackendConfig(beConfig, operator.Services(lbc.ctx.Services().List())).AsList()
+	}
+	for _, svc := range svcs {
+		ings = append(ings, lbc.ingressesForService(svc)...)
+	}
+	return uniqueIngresses(ings)
+}
+
+// ingressesForService returns the Ingresses which reference svc.
+func (lbc *LoadBalancerController) ingressesForService(svc *apiv1.Service) []*v1.Ingress {
+	ings, err := operator.IngressesByIndex(lbc.ctx.IngressInformer.GetIndexer(), operator.ServiceIndex, svc.Namespace, svc.Name)
+	if err != nil {
+		klog.Errorf("Failed to look up the Ingresses referencing Service %s/%s, checking all: %v", svc.Namespace, svc.Name, err)
+		return operator.Ingresses(lbc.ctx.Ingresses().List()).ReferencesService(svc).AsList()
+	}
+	return ings
+}
+
+// ingressesForSecret returns the Ingresses which use the Secret with the given
+// namespace and name, either for TLS or through the IAP settings of a
+// BackendConfig.
+func (lbc *LoadBalancerController) ingressesForSecret(namespace, name string) []*v1.Ingress {
+	ings, err := operator.IngressesByIndex(lbc.ctx.IngressInformer.GetIndexer(), operator.SecretIndex, namespace, name)
+	if err != nil {
+		klog.Errorf("Failed to look up the Ingresses referencing Secret %s/%s: %v", namespace, name, err)
+	}
+	beConfigs, err := operator.BackendConfigsByIndex(lbc.ctx.BackendConfigInformer.GetIndexer(), operator.SecretIndex, namespace, name)
+	if err != nil {
+		klog.Errorf("Failed to look up the BackendConfigs referencing Secret %s/%s: %v", namespace, name, err)
+	}
+	for _, beConfig := range beConfigs {
+		ings = append(ings, lbc.ingressesForBackendConfig(beConfig)...)
+	}
+	return uniqueIngresses(ings)
+}
+
+// uniqueIngresses returns ings without duplicates.
+func uniqueIngresses(ings []*v1.Ingress) []*v1.Ingress {
+	seen := map[string]bool{}
+	var ret []*v1.Ingress
+	for _, ing := range ings {
+		key := common.NamespacedName(ing)
+		if !seen[key] {
+			seen[key] = true
+			ret = append(ret, ing)
+		}
+	}
+	return ret
+}
+
+// frontendConfigForIngress returns the FrontendConfig applied to ing, which is
+// either the one it references or the default for its class. No FrontendConfig
+// is applied if the default does not exist.
+func (lbc *LoadBalancerController) frontendConfigForIngress(ing *v1.Ingress) (*frontendconfigv1beta1.FrontendConfig, error) {
+	defaultName := lbc.defaultFrontendConfigName(ing)
+	feConfig, err := frontendconfig.FrontendConfigForIngressWithDefault(lbc.ctx.FrontendConfigs().List(), ing, defaultName)
+	if err == frontendconfig.ErrDefaultFrontendConfigDoesNotExist {
+		lbc.ctx.Recorder(ing.Namespace).Eventf(ing, apiv1.EventTypeWarning, events.DefaultConfigMissing, "Default FrontendConfig %s does not exist, no FrontendConfig is applied", defaultName)
+		return nil, nil
+	}
+	return feConfig, err
+}
+
+// defaultFrontendConfigName returns the name of the FrontendConfig applied to
+// ing if it does not reference one. The default of the parameters of its
+// IngressClass takes precedence over the cluster default.
+func (lbc *LoadBalancerController) defaultFrontendConfigName(ing *v1.Ingress) *types.NamespacedName {
+	params, err := ingparams.ParamsForIngress(ing, lbc.ingClassLister, lbc.ingParamsLister)
+	if err != nil {
+		klog.Warningf("Failed to get IngressClass parameters for %s/%s, using the cluster default FrontendConfig: %v", ing.Namespace, ing.Name, err)
+	}
+	if params != nil && params.Spec.DefaultFrontendConfig != "" {
+		name, err := utils.ToNamespacedName(params.Spec.DefaultFrontendConfig)
+		if err == nil {
+			return &name
+		}
+		klog.Warningf("Invalid default FrontendConfig %q in GCPIngressParams %s, using the cluster default: %v", params.Spec.DefaultFrontendConfig, params.Name, err)
+	}
+	return lbc.ctx.DefaultFrontendConfig
+}
+
+// syncDeadlineExceeded reports that the sync of the Ingress with the given key
+// exceeded the sync deadline.
+func (lbc *LoadBalancerController) syncDeadlineExceeded(key string) {
+	metrics.PublishSyncDeadlineExceeded("ingresses")
+	ing, exists, err := lbc.ctx.Ingresses().GetByKey(key)
+	if err != nil || !exists {
+		return
+	}
+	lbc.ctx.Recorder(ing.Namespace).Eventf(ing, apiv1.EventTypeWarning, events.SyncDeadlineExceeded, "Sync aborted after exceeding deadline of %v, requeued", lbc.ctx.SyncDeadline)
+}
+
+// checkNamespaceLimits returns an error if syncing ing would exceed the
+// limits on resources provisioned in its namespace. The limits of the
+// parameters of its IngressClass take precedence over the cluster limits.
+func (lbc *LoadBalancerController) checkNamespaceLimits(ing *v1.Ingress, allIngresses []*v1.Ingress) error {
+	limits := lbc.ctx.NamespaceLimits
+	params, err := ingparams.ParamsForIngress(ing, lbc.ingClassLister, lbc.ingParamsLister)
+	if err != nil {
+		klog.Warningf("Failed to get IngressClass parameters for %s/%s, using the cluster namespace limits: %v", ing.Namespace, ing.Name, err)
+	}
+	if params != nil && params.Spec.NamespaceLimits != nil {
+		if n := params.Spec.NamespaceLimits.MaxIngresses; n != nil {
+			limits.Ingresses = int(*n)
+		}
+		if n := params.Spec.NamespaceLimits.MaxCertificates; n != nil {
+			limits.Certificates = int(*n)
+		}
+	}
+	ings := operator.Ingresses(allIngresses).Filter(utils.IsGLBCIngress).AsList()
+	return quota.CheckIngress(ing, ings, limits)
+}
+
+// ingressesForFrontendConfig returns the Ingresses in ings that feConfig
+// applies to, either by reference or as their default.
+func (lbc *LoadBalancerController) ingressesForFrontendConfig(ings []*v1.Ingress, feConfig *frontendconfigv1beta1.FrontendConfig) []*v1.Ingress {
+	ret := operator.Ingresses(ings).ReferencesFrontendConfig(feConfig).AsList()
+	for _, ing := range ings {
+		if annotations.FromIngress(ing).FrontendConfig() != "" {
+			continue
+		}
+		if name := lbc.defaultFrontendConfigName(ing); name != nil && name.Namespace == feConfig.Namespace && name.Name == feConfig.Name {
+			ret = append(ret, ing)
+		}
+	}
+	return ret
+}
+
+// updateFrontendConfigStatus updates the status of the FrontendConfig
+// referenced by ing, if any, with the result of its last sync. Failures are
+// logged and do not fail the sync.
+func (lbc *LoadBalancerController) updateFrontendConfigStatus(allIngresses []*v1.Ingress, ing *v1.Ingress, syncErr error) {
+	if !lbc.ctx.FrontendConfigEnabled || lbc.ctx.FrontendConfigClient == nil {
+		return
+	}
+	fc, err := lbc.frontendConfigForIngress(ing)
+	if err != nil || fc == nil {
+		return
+	}
+	ings := lbc.ingressesForFrontendConfig(allIngresses, fc)
+	status := frontendconfig.Status(fc, ings, ing, syncErr)
+	if err := frontendconfig.EnsureStatus(lbc.ctx.FrontendConfigClient, fc, status); err != nil {
+		klog.Errorf("Failed to update status of FrontendConfig %s/%s: %v", fc.Namespace, fc.Name, err)
+	}
+}
+
 // updateIngressStatus updates the IP and annotations of a loadbalancer.
 // The annotations are parsed by kubectl describe.
-func (lbc *LoadBalancerController) updateIngressStatus(l7 *loadbalancers.L7, ing *v1.Ingress) error {
+func (lbc *LoadBalancerController) updateIngressStatus(ctx context2.Context, l7 *loadbalancers.L7, ing *v1.Ingress) error {
 	ingClient := lbc.ctx.KubeClient.NetworkingV1().Ingresses(ing.Namespace)
 
 	// Update IP through update/status endpoint
 	ip := l7.GetIP()
+	hostname := ""
+	if lbc.ctx.StatusHostnameTemplate != nil {
+		var err error
+		if hostname, err = statusHostname(lbc.ctx.StatusHostnameTemplate, ing, lbc.ctx.ClusterNamer.UID()); err != nil {
+			klog.Errorf("Failed to get status hostname of ingress %s/%s: %v", ing.Namespace, ing.Name, err)
+		}
+	}
 	updatedIngStatus := v1.IngressStatus{
 		LoadBalancer: apiv1.LoadBalancerStatus{
 			Ingress: []apiv1.LoadBalancerIngress{
-				{IP: ip},
+				{IP: ip, Hostname: hostname},
 			},
 		},
 	}
 	if ip != "" {
 		lbIPs := ing.Status.LoadBalancer.Ingress
-		if len(lbIPs) == 0 || lbIPs[0].IP != ip {
-			klog.Infof("Updating loadbalancer %v/%v with IP %v", ing.Namespace, ing.Name, ip)
+		// The status only reports the IP of the forwarding rules, stale
+		// entries are dropped when the IP version of the Ingress changes.
+		if len(lbIPs) != 1 || lbIPs[0].IP != ip || lbIPs[0].Hostname != hostname {
+			klog.Infof("Updating loadbalancer %v/%v with IP %v (sync %s)", ing.Namespace, ing.Name, ip, l7.RuntimeInfo().SyncID)
 			if _, err := common.PatchIngressStatus(ingClient, ing, updatedIngStatus); err != nil {
 				klog.Errorf("PatchIngressStatus(%s/%s) failed: %v", ing.Namespace, ing.Name, err)
 				return err
 			}
-			lbc.ctx.Recorder(ing.Namespace).Eventf(ing, apiv1.EventTypeNormal, events.IPChanged, "IP is now %v", ip)
+			events.WithSyncID(lbc.ctx.Recorder(ing.Namespace), l7.RuntimeInfo().SyncID).Eventf(ing, apiv1.EventTypeNormal, events.IPChanged, "IP is now %v", ip)
 		}
 	}
 
-	newAnnotations, err := loadbalancers.GetLBAnnotations(l7, ing.ObjectMeta.DeepCopy().Annotations, lbc.backendSyncer)
+	newAnnotations, err := loadbalancers.GetLBAnnotations(ctx, l7, ing.ObjectMeta.DeepCopy().Annotations, lbc.backendSyncer)
 	if err != nil {
 		return err
 	}
+	if lbc.warmup != nil {
+		if err := lbc.warmUp(l7, ing, newAnnotations); err != nil {
+			return err
+		}
+	}
 
 	if err := updateAnnotations(lbc.ctx.KubeClient, ing, newAnnotations); err != nil {
 		return err
@@ -670,6 +1079,55 @@ func (lbc *LoadBalancerController) updateIngressStatus(l7 *loadbalancers.L7, ing
 	return nil
 }
 
+// warmUp records the Ready condition of the given ingress in ingAnnotations,
+// from the result of the last probe of its VIP. Probes run in the background
+// and the ingress is synced again once they return, after the retry period
+// for failed probes, until the current generation of the ingress is Ready.
+func (lbc *LoadBalancerController) warmUp(l7 *loadbalancers.L7, ing *v1.Ingress, ingAnnotations map[string]string) error {
+	existing := ingAnnotations[annotations.ReadyConditionKey]
+	if readyForGeneration(existing, ing.Generation) {
+		return nil
+	}
+	key := common.IngressKeyFunc(ing)
+	ip := l7.GetIP()
+	probeErr, probed := lbc.warmup.result(key, ing.Generation, ip)
+	if !probed || probeErr != nil {
+		delay := time.Duration(0)
+		if probed {
+			delay = flags.F.IngressWarmupRetryPeriod
+			klog.V(2).Infof("Ingress %s/%s is not Ready yet, probing again in %v: %v", ing.Namespace, ing.Name, delay, probeErr)
+		}
+		https, targets := !l7.ServesHTTP(), warmupTargets(l7.RuntimeInfo().UrlMap)
+		lbc.warmup.start(key, ing.Generation, ip, delay, func() error {
+			if ip == "" {
+				return fmt.Errorf("no IP is assigned yet")
+			}
+			return lbc.warmup.probe(ip, https, targets)
+		}, func() { lbc.ingQueue.Enqueue(ing) })
+	}
+	if !probed {
+		if existing != "" {
+			// Keep the condition until the probe returns.
+			return nil
+		}
+		probeErr = fmt.Errorf("probing the load balancer")
+	}
+	cond, becameReady := readyCondition(ing, existing, probeErr, time.Now())
+	value, err := json.Marshal(cond)
+	if err != nil {
+		return err
+	}
+	ingAnnotations[annotations.ReadyConditionKey] = string(value)
+
+	if probeErr != nil {
+		return nil
+	}
+	if becameReady {
+		events.WithSyncID(lbc.ctx.Recorder(ing.Namespace), l7.RuntimeInfo().SyncID).Eventf(ing, apiv1.EventTypeNormal, events.WarmedUp, "The load balancer serves the hosts and paths of the Ingress")
+	}
+	return nil
+}
+
 // toRuntimeInfo returns L7RuntimeInfo for the given ingress.
 func (lbc *LoadBalancerController) toRuntimeInfo(ing *v1.Ingress, urlMap *utils.GCEURLMap) (*loadbalancers.L7RuntimeInfo, error) {
 	annotations := annotations.FromIngress(ing)
@@ -699,7 +1157,7 @@ func (lbc *LoadBalancerController) toRuntimeInfo(ing *v1.Ingress, urlMap *utils.
 
 	var feConfig *frontendconfigv1beta1.FrontendConfig
 	if lbc.ctx.FrontendConfigEnabled {
-		feConfig, err = frontendconfig.FrontendConfigForIngress(lbc.ctx.FrontendConfigs().List(), ing)
+		feConfig, err = lbc.frontendConfigForIngress(ing)
 		if err != nil {
 			lbc.ctx.Recorder(ing.Namespace).Eventf(ing, apiv1.EventTypeWarning, events.SyncIngress, "Error: %v", err)
 		}
@@ -712,15 +1170,21 @@ func (lbc *LoadBalancerController) toRuntimeInfo(ing *v1.Ingress, urlMap *utils.
 	if err != nil {
 		return nil, err
 	}
+	ipVersion, err := annotations.IPVersion()
+	if err != nil {
+		return nil, err
+	}
 
 	return &loadbalancers.L7RuntimeInfo{
-		TLS:            tls,
-		TLSName:        annotations.UseNamedTLS(),
-		Ingress:        ing,
-		AllowHTTP:      annotations.AllowHTTP(),
-		StaticIPName:   staticIPName,
-		UrlMap:         urlMap,
-		FrontendConfig: feConfig,
+		TLS:                   tls,
+		TLSName:               annotations.UseNamedTLS(),
+		Ingress:               ing,
+		AllowHTTP:             annotations.AllowHTTP(),
+		StaticIPName:          staticIPName,
+		ManagedStaticIPSubnet: annotations.RegionalStaticIPSubnet(),
+		IPVersion:             ipVersion,
+		UrlMap:                urlMap,
+		FrontendConfig:        feConfig,
 	}, nil
 }
 
@@ -751,7 +1215,7 @@ func (lbc *LoadBalancerController) ToSvcPorts(ings []*v1.Ingress) []utils.Servic
 
 // defaultFrontendNamingScheme returns frontend naming scheme for an ingress without finalizer.
 // This is used for adding an appropriate finalizer on the ingress.
-func (lbc *LoadBalancerController) defaultFrontendNamingScheme(ing *v1.Ingress) (namer.Scheme, error) {
+func (lbc *LoadBalancerController) defaultFrontendNamingScheme(ctx context2.Context, ing *v1.Ingress) (namer.Scheme, error) {
 	// Ingress frontend naming scheme is determined based on the following logic,
 	// V2 frontend namer is disabled         : v1 frontend naming scheme
 	// V2 frontend namer is enabled
@@ -765,7 +1229,7 @@ func (lbc *LoadBalancerController) defaultFrontendNamingScheme(ing *v1.Ingress)
 	if !utils.HasVIP(ing) {
 		return namer.V2NamingScheme, nil
 	}
-	urlMapExists, err := lbc.l7Pool.HasUrlMap(ing)
+	urlMapExists, err := lbc.l7Pool.HasUrlMap(ctx, ing)
 	if err != nil {
 		return "", err
 	}
@@ -776,13 +1240,13 @@ func (lbc *LoadBalancerController) defaultFrontendNamingScheme(ing *v1.Ingress)
 }
 
 // ensureFinalizer ensures that a finalizer is attached.
-func (lbc *LoadBalancerController) ensureFinalizer(ing *v1.Ingress) (*v1.Ingress, error) {
+func (lbc *LoadBalancerController) ensureFinalizer(ctx context2.Context, ing *v1.Ingress) (*v1.Ingress, error) {
 	ingKey := common.NamespacedName(ing)
 	if common.HasFinalizer(ing.ObjectMeta) {
 		klog.V(4).Infof("Finalizer exists for ingress %s", ingKey)
 		return ing, nil
 	}
-	namingScheme, err := lbc.defaultFrontendNamingScheme(ing)
+	namingScheme, err := lbc.defaultFrontendNamingScheme(ctx, ing)
 	if err != nil {
 		return nil, err
 	}
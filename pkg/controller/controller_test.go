@@ -18,12 +18,14 @@ package controller
 
 import (
 	context2 "context"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
 	"sort"
 	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
@@ -35,7 +37,10 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/ingress-gce/pkg/annotations"
+	frontendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/frontendconfig/v1beta1"
+	ingparamsv1beta1 "k8s.io/ingress-gce/pkg/apis/ingparams/v1beta1"
 	backendconfigclient "k8s.io/ingress-gce/pkg/backendconfig/client/clientset/versioned/fake"
 	"k8s.io/ingress-gce/pkg/common/operator"
 	"k8s.io/ingress-gce/pkg/context"
@@ -43,6 +48,8 @@ import (
 	"k8s.io/ingress-gce/pkg/flags"
 	"k8s.io/ingress-gce/pkg/instances"
 	"k8s.io/ingress-gce/pkg/loadbalancers"
+	"k8s.io/ingress-gce/pkg/multiproject"
+	"k8s.io/ingress-gce/pkg/quota"
 	"k8s.io/ingress-gce/pkg/test"
 	"k8s.io/ingress-gce/pkg/translator"
 	"k8s.io/ingress-gce/pkg/utils"
@@ -77,7 +84,7 @@ func newLoadBalancerController() *LoadBalancerController {
 	lbc := NewLoadBalancerController(ctx, stopCh)
 	// TODO(rramkumar): Fix this so we don't have to override with our fake
 	lbc.instancePool = instances.NewNodePool(instances.NewFakeInstanceGroups(sets.NewString(), namer), namer, &test.FakeRecorderSource{}, utils.GetBasePath(fakeGCE))
-	lbc.l7Pool = loadbalancers.NewLoadBalancerPool(fakeGCE, namer, events.RecorderProducerMock{}, namer_util.NewFrontendNamerFactory(namer, ""))
+	lbc.l7Pool = loadbalancers.NewLoadBalancerPool(multiproject.NewSingleProjectRouter(fakeGCE), namer, events.RecorderProducerMock{}, namer_util.NewFrontendNamerFactory(namer, ""))
 	lbc.instancePool.Init(&instances.FakeZoneLister{Zones: []string{fakeZone}})
 
 	lbc.hasSynced = func() bool { return true }
@@ -179,6 +186,40 @@ func TestIngressSyncError(t *testing.T) {
 	}
 }
 
+// TestIngressSyncDeadline asserts that a sync whose deadline has passed is
+// aborted with an error, so that the Ingress is requeued, and creates no
+// load balancer.
+func TestIngressSyncDeadline(t *testing.T) {
+	lbc := newLoadBalancerController()
+
+	svc := test.NewService(types.NamespacedName{Name: "my-service", Namespace: "default"}, api_v1.ServiceSpec{
+		Type:  api_v1.ServiceTypeNodePort,
+		Ports: []api_v1.ServicePort{{Port: 80}},
+	})
+	addService(lbc, svc)
+	someBackend := backend("my-service", networkingv1.ServiceBackendPort{Number: 80})
+	ing := test.NewIngress(types.NamespacedName{Name: "my-ingress", Namespace: "default"},
+		networkingv1.IngressSpec{
+			DefaultBackend: &someBackend,
+		})
+	addIngress(lbc, ing)
+
+	ctx, cancel := context2.WithDeadline(context2.Background(), time.Now())
+	defer cancel()
+	ingStoreKey := getKey(ing, t)
+	if err := lbc.syncContext(ctx, ingStoreKey); !errors.Is(err, context2.DeadlineExceeded) {
+		t.Fatalf("lbc.syncContext(%v) = %v, want %v", ingStoreKey, err, context2.DeadlineExceeded)
+	}
+
+	urlMaps, err := lbc.ctx.Cloud.ListURLMaps()
+	if err != nil {
+		t.Fatalf("lbc.ctx.Cloud.ListURLMaps() = %v", err)
+	}
+	if len(urlMaps) != 0 {
+		t.Errorf("lbc.ctx.Cloud.ListURLMaps() = %v, want none", urlMaps)
+	}
+}
+
 // TestNEGOnlyIngress asserts that `sync` will not create IG when there is only NEG backends for the ingress
 func TestNEGOnlyIngress(t *testing.T) {
 	lbc := newLoadBalancerController()
@@ -211,6 +252,57 @@ func TestNEGOnlyIngress(t *testing.T) {
 	}
 }
 
+// TestIngressStatusHostname asserts that the hostname rendered from the
+// status hostname template is published with the IP of the Ingress.
+func TestIngressStatusHostname(t *testing.T) {
+	for _, tc := range []struct {
+		desc         string
+		template     string
+		wantHostname string
+	}{
+		{
+			desc: "no template",
+		},
+		{
+			desc:         "template",
+			template:     "{{.Name}}.{{.Namespace}}.{{.ClusterUID}}.lb.example.com",
+			wantHostname: "my-ingress.default.aaaaa.lb.example.com",
+		},
+		{
+			desc:     "invalid hostname",
+			template: "{{.Name}}_{{.Namespace}}",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			lbc := newLoadBalancerController()
+			if tc.template != "" {
+				lbc.ctx.StatusHostnameTemplate = template.Must(template.New("hostname").Parse(tc.template))
+			}
+			svc := test.NewService(types.NamespacedName{Name: "my-service", Namespace: "default"}, api_v1.ServiceSpec{
+				Type:  api_v1.ServiceTypeNodePort,
+				Ports: []api_v1.ServicePort{{Port: 80}},
+			})
+			addService(lbc, svc)
+			defaultBackend := backend("my-service", networkingv1.ServiceBackendPort{Number: 80})
+			ing := test.NewIngress(types.NamespacedName{Name: "my-ingress", Namespace: "default"},
+				networkingv1.IngressSpec{
+					DefaultBackend: &defaultBackend,
+				})
+			addIngress(lbc, ing)
+
+			ingStoreKey := getKey(ing, t)
+			if err := lbc.sync(ingStoreKey); err != nil {
+				t.Fatalf("lbc.sync(%v) = %v, want nil", ingStoreKey, err)
+			}
+			updatedIng, _ := lbc.ctx.KubeClient.NetworkingV1().Ingresses(ing.Namespace).Get(context2.TODO(), ing.Name, meta_v1.GetOptions{})
+			lbIngress := updatedIng.Status.LoadBalancer.Ingress
+			if len(lbIngress) != 1 || lbIngress[0].IP == "" || lbIngress[0].Hostname != tc.wantHostname {
+				t.Errorf("Get(%q) = status %+v, want IP and hostname %q", updatedIng.Name, lbIngress, tc.wantHostname)
+			}
+		})
+	}
+}
+
 // TestIngressCreateDeleteFinalizer asserts that `sync` will will not return an
 // error for a good ingress config. It also tests garbage collection for
 // Ingresses that need to be deleted, and keep the ones that don't, depending
@@ -1026,3 +1118,113 @@ func getUpdatedIngress(t *testing.T, lbc *LoadBalancerController, ing *networkin
 	}
 	return updatedIng
 }
+
+func TestFrontendConfigForIngressWithDefault(t *testing.T) {
+	lbc := newLoadBalancerController()
+	lbc.ingClassLister = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	lbc.ingParamsLister = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	lbc.ctx.FrontendConfigInformer = cache.NewSharedIndexInformer(&cache.ListWatch{}, &frontendconfigv1beta1.FrontendConfig{}, 0, cache.Indexers{})
+	lbc.ctx.DefaultFrontendConfig = &types.NamespacedName{Namespace: "kube-system", Name: "cluster-default"}
+	ing := test.NewIngress(types.NamespacedName{Name: "ing", Namespace: "default"}, networkingv1.IngressSpec{})
+
+	// A missing default FrontendConfig does not fail the sync.
+	fc, err := lbc.frontendConfigForIngress(ing)
+	if fc != nil || err != nil {
+		t.Errorf("frontendConfigForIngress() = %v, %v, want nil, nil if the default does not exist", fc, err)
+	}
+
+	lbc.ctx.FrontendConfigInformer.GetStore().Add(&frontendconfigv1beta1.FrontendConfig{
+		ObjectMeta: meta_v1.ObjectMeta{Namespace: "kube-system", Name: "cluster-default"},
+	})
+	fc, err = lbc.frontendConfigForIngress(ing)
+	if err != nil || fc == nil || fc.Name != "cluster-default" {
+		t.Errorf("frontendConfigForIngress() = %v, %v, want the cluster default", fc, err)
+	}
+}
+
+func TestDefaultFrontendConfigName(t *testing.T) {
+	lbc := newLoadBalancerController()
+	lbc.ingClassLister = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	lbc.ingParamsLister = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	clusterDefault := &types.NamespacedName{Namespace: "kube-system", Name: "cluster-default"}
+	lbc.ctx.DefaultFrontendConfig = clusterDefault
+
+	apiGroup := "networking.gke.io"
+	for _, class := range []*networkingv1.IngressClass{
+		{
+			ObjectMeta: meta_v1.ObjectMeta{Name: "with-default"},
+			Spec: networkingv1.IngressClassSpec{
+				Parameters: &api_v1.TypedLocalObjectReference{APIGroup: &apiGroup, Kind: "GCPIngressParams", Name: "with-default"},
+			},
+		},
+		{
+			ObjectMeta: meta_v1.ObjectMeta{Name: "without-default"},
+			Spec: networkingv1.IngressClassSpec{
+				Parameters: &api_v1.TypedLocalObjectReference{APIGroup: &apiGroup, Kind: "GCPIngressParams", Name: "without-default"},
+			},
+		},
+		{
+			ObjectMeta: meta_v1.ObjectMeta{Name: "missing-params"},
+			Spec: networkingv1.IngressClassSpec{
+				Parameters: &api_v1.TypedLocalObjectReference{APIGroup: &apiGroup, Kind: "GCPIngressParams", Name: "missing"},
+			},
+		},
+	} {
+		lbc.ingClassLister.Add(class)
+	}
+	lbc.ingParamsLister.Add(&ingparamsv1beta1.GCPIngressParams{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "with-default"},
+		Spec:       ingparamsv1beta1.GCPIngressParamsSpec{DefaultFrontendConfig: "team/class-default"},
+	})
+	lbc.ingParamsLister.Add(&ingparamsv1beta1.GCPIngressParams{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "without-default"},
+	})
+
+	for _, tc := range []struct {
+		desc  string
+		class string
+		want  *types.NamespacedName
+	}{
+		{desc: "no ingress class", want: clusterDefault},
+		{desc: "class params with default", class: "with-default", want: &types.NamespacedName{Namespace: "team", Name: "class-default"}},
+		{desc: "class params without default", class: "without-default", want: clusterDefault},
+		{desc: "class params missing", class: "missing-params", want: clusterDefault},
+		{desc: "unknown class", class: "unknown", want: clusterDefault},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ing := test.NewIngress(types.NamespacedName{Name: "ing", Namespace: "default"}, networkingv1.IngressSpec{})
+			if tc.class != "" {
+				ing.Spec.IngressClassName = &tc.class
+			}
+			if got := lbc.defaultFrontendConfigName(ing); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("defaultFrontendConfigName() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSyncNamespaceIngressLimit(t *testing.T) {
+	lbc := newLoadBalancerController()
+	lbc.ctx.NamespaceLimits = quota.Limits{Ingresses: 1}
+	svc := test.NewService(types.NamespacedName{Name: "my-service", Namespace: "default"}, api_v1.ServiceSpec{
+		Type:  api_v1.ServiceTypeNodePort,
+		Ports: []api_v1.ServicePort{{Port: 80}},
+	})
+	addService(lbc, svc)
+	defaultBackend := backend("my-service", networkingv1.ServiceBackendPort{Number: 80})
+
+	var keys []string
+	for i, name := range []string{"first", "second"} {
+		ing := test.NewIngress(types.NamespacedName{Name: name, Namespace: "default"}, networkingv1.IngressSpec{DefaultBackend: &defaultBackend})
+		ing.CreationTimestamp = meta_v1.NewTime(time.Unix(int64(i), 0))
+		addIngress(lbc, ing)
+		keys = append(keys, getKey(ing, t))
+	}
+
+	if err := lbc.sync(keys[0]); err != nil {
+		t.Fatalf("lbc.sync(%v) = %v, want nil", keys[0], err)
+	}
+	if err := lbc.sync(keys[1]); !quota.IsExceeded(err) {
+		t.Errorf("lbc.sync(%v) = %v, want quota exceeded error", keys[1], err)
+	}
+}
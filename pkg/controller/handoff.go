@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/ingress-gce/pkg/backends"
+)
+
+// handoffStateKey is the key of the ConfigMap data which holds the state.
+const handoffStateKey = "state"
+
+// handoffState is the minimal sync state which the leader persists, so that
+// a newly elected leader resumes reconciliation without first fetching every
+// GCE resource again.
+type handoffState struct {
+	// Pending are the keys of the Ingresses whose last sync failed.
+	Pending []string `json:"pending,omitempty"`
+	// Backends are the fingerprints of the backend services which were found
+	// in sync.
+	Backends []backends.Fingerprint `json:"backends,omitempty"`
+}
+
+// stateHandoff persists the handoff state in a ConfigMap and tracks the
+// Ingresses whose last sync failed.
+type stateHandoff struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+
+	lock    sync.Mutex
+	pending sets.String
+}
+
+func newStateHandoff(client kubernetes.Interface, namespace, name string) *stateHandoff {
+	return &stateHandoff{
+		client:    client,
+		namespace: namespace,
+		name:      name,
+		pending:   sets.NewString(),
+	}
+}
+
+// recordSync records the result of the sync of the given key.
+func (h *stateHandoff) recordSync(key string, err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if err != nil {
+		h.pending.Insert(key)
+	} else {
+		h.pending.Delete(key)
+	}
+}
+
+// pendingKeys returns the keys of the Ingresses whose last sync failed.
+func (h *stateHandoff) pendingKeys() []string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.pending.List()
+}
+
+// load returns the state persisted by the previous leader, or nil if there
+// is none.
+func (h *stateHandoff) load() (*handoffState, error) {
+	cm, err := h.client.CoreV1().ConfigMaps(h.namespace).Get(context.TODO(), h.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, ok := cm.Data[handoffStateKey]
+	if !ok {
+		return nil, nil
+	}
+	var state handoffState
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return nil, fmt.Errorf("failed to parse handoff state of ConfigMap %s/%s: %v", h.namespace, h.name, err)
+	}
+	return &state, nil
+}
+
+// save persists the given state, creating the ConfigMap if needed.
+func (h *stateHandoff) save(state *handoffState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	configMaps := h.client.CoreV1().ConfigMaps(h.namespace)
+	cm, err := configMaps.Get(context.TODO(), h.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		cm = &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: h.namespace, Name: h.name},
+			Data:       map[string]string{handoffStateKey: string(data)},
+		}
+		_, err = configMaps.Create(context.TODO(), cm, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if cm.Data[handoffStateKey] == string(data) {
+		return nil
+	}
+	cm = cm.DeepCopy()
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[handoffStateKey] = string(data)
+	_, err = configMaps.Update(context.TODO(), cm, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/ingress-gce/pkg/annotations"
+	"k8s.io/ingress-gce/pkg/backends"
+)
+
+func TestStateHandoff(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	leader := newStateHandoff(client, "kube-system", "ingress-gce-lock-state")
+
+	if state, err := leader.load(); state != nil || err != nil {
+		t.Fatalf("load() = %+v, %v, want nil, nil before any state is saved", state, err)
+	}
+
+	leader.recordSync("ns/ing-1", fmt.Errorf("quota exceeded"))
+	leader.recordSync("ns/ing-2", fmt.Errorf("quota exceeded"))
+	leader.recordSync("ns/ing-2", nil)
+	state := &handoffState{
+		Pending: leader.pendingKeys(),
+		Backends: []backends.Fingerprint{{
+			Name:        "k8s1-uid-ns-svc-80-hash",
+			Protocol:    annotations.ProtocolHTTP,
+			Scope:       meta.Global,
+			Fingerprint: "abcd",
+			Verified:    time.Unix(1600000000, 0).UTC(),
+		}},
+	}
+	if want := []string{"ns/ing-1"}; !reflect.DeepEqual(state.Pending, want) {
+		t.Errorf("pendingKeys() = %v, want %v", state.Pending, want)
+	}
+	// The first save creates the ConfigMap and later ones update it.
+	for i := 0; i < 2; i++ {
+		if err := leader.save(state); err != nil {
+			t.Fatalf("save() = %v", err)
+		}
+		state.Pending = append(state.Pending, fmt.Sprintf("ns/ing-%d", i+3))
+	}
+	state.Pending = state.Pending[:len(state.Pending)-1]
+
+	next := newStateHandoff(client, "kube-system", "ingress-gce-lock-state")
+	got, err := next.load()
+	if err != nil {
+		t.Fatalf("load() = %v", err)
+	}
+	if !reflect.DeepEqual(got, state) {
+		t.Errorf("load() = %+v, want %+v", got, state)
+	}
+}
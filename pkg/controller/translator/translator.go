@@ -17,7 +17,7 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
-	"strings"
+	"time"
 
 	"k8s.io/ingress-gce/pkg/flags"
 
@@ -28,6 +28,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	listers "k8s.io/client-go/listers/core/v1"
@@ -36,25 +38,25 @@ import (
 	"k8s.io/ingress-gce/pkg/annotations"
 	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
 	"k8s.io/ingress-gce/pkg/backendconfig"
+	"k8s.io/ingress-gce/pkg/backends/features"
 	"k8s.io/ingress-gce/pkg/context"
 	"k8s.io/ingress-gce/pkg/controller/errors"
+	"k8s.io/ingress-gce/pkg/events"
+	"k8s.io/ingress-gce/pkg/ingparams"
+	"k8s.io/ingress-gce/pkg/urlmaps"
 	"k8s.io/ingress-gce/pkg/utils"
 	namer_util "k8s.io/ingress-gce/pkg/utils/namer"
 )
 
-const (
-	// DefaultHost is the host used if none is specified. It is a valid value
-	// for the "Host" field recognized by GCE.
-	DefaultHost = "*"
-
-	// DefaultPath is the path used if none is specified. It is a valid path
-	// recognized by GCE.
-	DefaultPath = "/*"
-)
-
 // getServicePortParams allows for passing parameters to getServicePort()
 type getServicePortParams struct {
 	isL7ILB bool
+	// isSSLProxy is true if the service port is the backend of a target SSL
+	// proxy rather than a URL map.
+	isSSLProxy bool
+	// defaultTimeoutSec is the timeout of backend services created for the
+	// service port.
+	defaultTimeoutSec int64
 }
 
 // NewTranslator returns a new Translator.
@@ -98,7 +100,7 @@ func maybeEnableNEG(sp *utils.ServicePort, svc *api_v1.Service) error {
 		sp.NEGEnabled = negAnnotation.NEGEnabledForIngress()
 	}
 
-	if !sp.NEGEnabled && svc.Spec.Type != api_v1.ServiceTypeNodePort &&
+	if !sp.NEGEnabled && !flags.F.EnableRestrictedNodeAccess && svc.Spec.Type != api_v1.ServiceTypeNodePort &&
 		svc.Spec.Type != api_v1.ServiceTypeLoadBalancer {
 		// This is a fatal error.
 		return errors.ErrBadSvcType{Service: sp.ID.Service, ServiceType: svc.Spec.Type}
@@ -109,6 +111,11 @@ func maybeEnableNEG(sp *utils.ServicePort, svc *api_v1.Service) error {
 		sp.NEGEnabled = true
 	}
 
+	if flags.F.EnableRestrictedNodeAccess {
+		// Instance groups require access to the nodes.
+		sp.NEGEnabled = true
+	}
+
 	return nil
 }
 
@@ -128,10 +135,43 @@ func setAppProtocol(sp *utils.ServicePort, svc *api_v1.Service, port *api_v1.Ser
 	return nil
 }
 
+// setSSLProxyProtocol translates the app protocol of a service port into the
+// protocol of a backend service behind a target SSL proxy. Backends which
+// expect TLS use SSL, all others receive the decrypted stream over TCP.
+func setSSLProxyProtocol(sp *utils.ServicePort) {
+	if sp.Protocol == annotations.ProtocolHTTPS {
+		sp.Protocol = annotations.ProtocolSSL
+		return
+	}
+	sp.Protocol = annotations.ProtocolTCP
+}
+
+// validateSSLProxyIngress checks that an Ingress of the SSL proxy class only
+// uses a default backend, target SSL proxies do not support routing rules.
+func validateSSLProxyIngress(ing *v1.Ingress) error {
+	if ing.Spec.DefaultBackend == nil {
+		return fmt.Errorf("%s Ingress %s/%s must specify a default backend", annotations.GceSSLProxyIngressClass, ing.Namespace, ing.Name)
+	}
+	if len(ing.Spec.Rules) > 0 {
+		return fmt.Errorf("%s Ingress %s/%s does not support rules, only the default backend is used", annotations.GceSSLProxyIngressClass, ing.Namespace, ing.Name)
+	}
+	return nil
+}
+
 // maybeEnableBackendConfig sets the backendConfig for the service port if necessary
 func (t *Translator) maybeEnableBackendConfig(sp *utils.ServicePort, svc *api_v1.Service, port *api_v1.ServicePort) error {
 	var beConfig *backendconfigv1.BackendConfig
-	beConfig, err := backendconfig.GetBackendConfigForServicePort(t.ctx.BackendConfigInformer.GetIndexer(), svc, port)
+	var err error
+	if sp.ID.BackendConfig != "" {
+		// The BackendConfig of the Ingress path overrides the one of the
+		// Service port.
+		beConfig, err = backendconfig.GetBackendConfig(t.ctx.BackendConfigInformer.GetIndexer(), types.NamespacedName{Namespace: svc.Namespace, Name: sp.ID.BackendConfig})
+		if err != nil {
+			return errors.ErrSvcBackendConfig{ServicePortID: sp.ID, Err: err}
+		}
+	} else {
+		beConfig, err = backendconfig.GetBackendConfigForServicePort(t.ctx.BackendConfigInformer.GetIndexer(), svc, port)
+	}
 	if err != nil {
 		// If we could not find a backend config name for the current
 		// service port, then do not return an error. Removing a reference
@@ -141,6 +181,18 @@ func (t *Translator) maybeEnableBackendConfig(sp *utils.ServicePort, svc *api_v1
 			return errors.ErrSvcBackendConfig{ServicePortID: sp.ID, Err: err}
 		}
 	}
+	if beConfig == nil && t.ctx.DefaultBackendConfig != nil {
+		beConfig, err = backendconfig.GetBackendConfig(t.ctx.BackendConfigInformer.GetIndexer(), *t.ctx.DefaultBackendConfig)
+		if err == backendconfig.ErrBackendConfigDoesNotExist {
+			// A missing default must not break every Ingress, so no
+			// BackendConfig is applied until it is created.
+			t.ctx.Recorder(svc.Namespace).Eventf(svc, api_v1.EventTypeWarning, events.DefaultConfigMissing, "Default BackendConfig %s does not exist, no BackendConfig is applied to port %v", t.ctx.DefaultBackendConfig, port.Port)
+			return nil
+		}
+		if err != nil {
+			return errors.ErrSvcBackendConfig{ServicePortID: sp.ID, Err: err}
+		}
+	}
 	// Object in cache could be changed in-flight. Deepcopy to
 	// reduce race conditions.
 	beConfig = beConfig.DeepCopy()
@@ -148,10 +200,42 @@ func (t *Translator) maybeEnableBackendConfig(sp *utils.ServicePort, svc *api_v1
 		return errors.ErrBackendConfigValidation{BackendConfig: *beConfig, Err: err}
 	}
 
-	sp.BackendConfig = beConfig
+	// Settings which cannot be materialized for this backend are rejected,
+	// the rest of the BackendConfig is applied.
+	sp.BackendConfig = beConfig.DeepCopy()
+	var errs []error
+	if err := features.ValidateCDN(*sp); err != nil {
+		sp.BackendConfig.Spec.Cdn = nil
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errors.ErrBackendConfigValidation{BackendConfig: *beConfig, Err: utilerrors.NewAggregate(errs)}
+	}
 	return nil
 }
 
+// defaultBackendTimeoutSec returns the timeout of backend services created
+// for ing. The default of the parameters of its IngressClass takes precedence
+// over the controller default, unless it is not a timeout accepted by GCE.
+func (t *Translator) defaultBackendTimeoutSec(ing *v1.Ingress) int64 {
+	var classLister, paramsLister cache.Indexer
+	if t.ctx.IngClassInformer != nil {
+		classLister, paramsLister = t.ctx.IngClassInformer.GetIndexer(), t.ctx.IngParamsInformer.GetIndexer()
+	}
+	params, err := ingparams.ParamsForIngress(ing, classLister, paramsLister)
+	if err != nil {
+		klog.Warningf("Failed to get IngressClass parameters for %s/%s, using the default backend timeout: %v", ing.Namespace, ing.Name, err)
+	}
+	if params != nil && params.Spec.DefaultBackendTimeoutSec != nil {
+		if err := backendconfig.ValidateTimeoutSec(*params.Spec.DefaultBackendTimeoutSec); err != nil {
+			klog.Warningf("Invalid DefaultBackendTimeoutSec in GCPIngressParams %q for %s/%s, using the default backend timeout: %v", params.Name, ing.Namespace, ing.Name, err)
+		} else {
+			return *params.Spec.DefaultBackendTimeoutSec
+		}
+	}
+	return int64(t.ctx.DefaultBackendTimeout / time.Second)
+}
+
 // getServicePort looks in the svc store for a matching service:port,
 // and returns the nodeport.
 func (t *Translator) getServicePort(id utils.ServicePortID, params *getServicePortParams, namer namer_util.BackendNamer) (*utils.ServicePort, error) {
@@ -169,12 +253,13 @@ func (t *Translator) getServicePort(id utils.ServicePortID, params *getServicePo
 	// We periodically add information to the ServicePort to ensure that we
 	// always return as much as possible, rather than nil, if there was a non-fatal error.
 	svcPort := &utils.ServicePort{
-		ID:           id,
-		NodePort:     int64(port.NodePort),
-		Port:         int32(port.Port),
-		TargetPort:   port.TargetPort.String(),
-		L7ILBEnabled: params.isL7ILB,
-		BackendNamer: namer,
+		ID:                id,
+		NodePort:          int64(port.NodePort),
+		Port:              int32(port.Port),
+		TargetPort:        port.TargetPort.String(),
+		L7ILBEnabled:      params.isL7ILB,
+		DefaultTimeoutSec: params.defaultTimeoutSec,
+		BackendNamer:      namer,
 	}
 
 	if err := maybeEnableNEG(svcPort, svc); err != nil {
@@ -184,6 +269,9 @@ func (t *Translator) getServicePort(id utils.ServicePortID, params *getServicePo
 	if err := setAppProtocol(svcPort, svc, port); err != nil {
 		return svcPort, err
 	}
+	if params.isSSLProxy {
+		setSSLProxyProtocol(svcPort)
+	}
 
 	if err := t.maybeEnableBackendConfig(svcPort, svc, port); err != nil {
 		return svcPort, err
@@ -195,22 +283,40 @@ func (t *Translator) getServicePort(id utils.ServicePortID, params *getServicePo
 // TranslateIngress converts an Ingress into our internal UrlMap representation.
 func (t *Translator) TranslateIngress(ing *v1.Ingress, systemDefaultBackend utils.ServicePortID, namer namer_util.BackendNamer) (*utils.GCEURLMap, []error) {
 	var errs []error
-	urlMap := utils.NewGCEURLMap()
+	svcPorts := urlmaps.ServicePorts{}
 
 	params := &getServicePortParams{}
 	params.isL7ILB = utils.IsGCEL7ILBIngress(ing)
+	params.isSSLProxy = utils.IsGCESSLProxyIngress(ing)
+	params.defaultTimeoutSec = t.defaultBackendTimeoutSec(ing)
+	if params.isSSLProxy {
+		// The system default backend serves HTTP and must never be used
+		// behind a target SSL proxy.
+		if err := validateSSLProxyIngress(ing); err != nil {
+			return utils.NewGCEURLMap(), []error{err}
+		}
+	}
+
+	policy := urlmaps.Policy{
+		SystemDefaultBackend: systemDefaultBackend,
+		EnableGAPathTypes:    flags.F.EnableIngressGAFields,
+	}
+	pathBackendConfigs, err := annotations.FromIngress(ing).PathBackendConfigs()
+	if err != nil {
+		// Paths keep the BackendConfig of their Service port.
+		errs = append(errs, err)
+	}
+	policy.PathBackendConfigs = pathBackendConfigs
 
 	for _, rule := range ing.Spec.Rules {
 		if rule.HTTP == nil {
 			continue
 		}
 
-		pathRules := []utils.PathRule{}
 		for _, p := range rule.HTTP.Paths {
-			svcPortID, err := utils.BackendToServicePortID(p.Backend, ing.Namespace)
+			svcPortID, err := urlmaps.PathServicePortID(p, ing.Namespace, policy)
 			if err != nil {
-				// Only error possible is Backend is not a Service Backend, so move to next path
-				errs = append(errs, err)
+				// Reported by urlmaps.TranslateIngress.
 				continue
 			}
 			svcPort, err := t.getServicePort(svcPortID, params, namer)
@@ -218,121 +324,32 @@ func (t *Translator) TranslateIngress(ing *v1.Ingress, systemDefaultBackend util
 				errs = append(errs, err)
 			}
 			if svcPort != nil {
-				// The Ingress spec defines empty path as catch-all, so if a user
-				// asks for a single host and multiple empty paths, all traffic is
-				// sent to one of the last backend in the rules list.
-
-				paths, err := validateAndGetPaths(p)
-				if err != nil {
-					errs = append(errs, err)
-					continue
-				}
-				for _, path := range paths {
-					if path == "" {
-						path = DefaultPath
-					}
-					pathRules = append(pathRules, utils.PathRule{Path: path, Backend: *svcPort})
-				}
+				svcPorts[svcPortID] = *svcPort
 			}
 		}
-
-		host := rule.Host
-		if host == "" {
-			host = DefaultHost
-		}
-		urlMap.PutPathRulesForHost(host, pathRules)
 	}
 
 	if ing.Spec.DefaultBackend != nil {
-		svcPortID, err := utils.BackendToServicePortID(*ing.Spec.DefaultBackend, ing.Namespace)
-		if err != nil {
-			errs = append(errs, err)
-			return urlMap, errs
+		if svcPortID, err := utils.BackendToServicePortID(*ing.Spec.DefaultBackend, ing.Namespace); err == nil {
+			svcPort, err := t.getServicePort(svcPortID, params, namer)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			if svcPort != nil {
+				svcPorts[svcPortID] = *svcPort
+			}
 		}
-		svcPort, err := t.getServicePort(svcPortID, params, namer)
+	} else {
+		svcPort, err := t.getServicePort(systemDefaultBackend, params, namer)
 		if err == nil {
-			urlMap.DefaultBackend = svcPort
-			return urlMap, errs
-		}
-
-		errs = append(errs, err)
-		return urlMap, errs
-	}
-
-	svcPort, err := t.getServicePort(systemDefaultBackend, params, namer)
-	if err == nil {
-		urlMap.DefaultBackend = svcPort
-		return urlMap, errs
-	}
-
-	errs = append(errs, fmt.Errorf("failed to retrieve the system default backend service %q with port %q: %v", systemDefaultBackend.Service.String(), systemDefaultBackend.Port.String(), err))
-	return urlMap, errs
-}
-
-// validateAndGetPaths will validate the path based on the specifed path type and will return the
-// the path rules that should be used. If no path type is provided, the path type will be assumed
-// to be ImplementationSpecific. If a non existent path type is provided, an error will be returned.
-func validateAndGetPaths(path v1.HTTPIngressPath) ([]string, error) {
-	pathType := v1.PathTypeImplementationSpecific
-
-	if path.PathType != nil {
-		if !flags.F.EnableIngressGAFields && *path.PathType != v1.PathTypeImplementationSpecific {
-			return nil, fmt.Errorf("only \"ImplementationSpecific\" path type is supported")
+			svcPorts[systemDefaultBackend] = *svcPort
+		} else {
+			errs = append(errs, fmt.Errorf("failed to retrieve the system default backend service %q with port %q: %v", systemDefaultBackend.Service.String(), systemDefaultBackend.Port.String(), err))
 		}
-		pathType = *path.PathType
-	}
-
-	switch pathType {
-	case v1.PathTypeImplementationSpecific:
-		// ImplementationSpecific will have no validation to continue backwards compatibility
-		return []string{path.Path}, nil
-	case v1.PathTypeExact:
-		return validateExactPathType(path)
-	case v1.PathTypePrefix:
-		return validateAndModifyPrefixPathType(path)
-	default:
-		return nil, fmt.Errorf("unsupported path type: %s", pathType)
-	}
-}
-
-// validateExactPathType will validate the path provided does not have any wildcards and will
-// return the path unmodified. If the path is in valid, an empty list and error is returned.
-func validateExactPathType(path v1.HTTPIngressPath) ([]string, error) {
-	if path.Path == "" {
-		return nil, fmt.Errorf("failed to validate exact path type due to empty path")
 	}
 
-	if strings.Contains(path.Path, "*") {
-		return nil, fmt.Errorf("failed to validate exact path %s due to invalid wildcard", path.Path)
-	}
-	return []string{path.Path}, nil
-}
-
-// validateAndModifyPrefixPathType will validate the path provided does not have any wildcards
-// and will return the path unmodified. If the path is in valid, an empty list and error is
-// returned.
-func validateAndModifyPrefixPathType(path v1.HTTPIngressPath) ([]string, error) {
-	if path.Path == "" {
-		return nil, fmt.Errorf("failed to validate prefix path type due to empty path")
-	}
-
-	// The Ingress spec defines Prefx path "/" as matching all paths
-	if path.Path == "/" {
-		return []string{"/*"}, nil
-	}
-
-	if strings.Contains(path.Path, "*") {
-		return nil, fmt.Errorf("failed to validate prefix path %s due to invalid wildcard", path.Path)
-	}
-
-	// Prefix path `/foo` or `/foo/` should support requests for `/foo`, `/foo/` and `/foo/bar`. URLMap requires two
-	// path rules 1) `/foo` & 2) `/foo/*` to support all three requests.
-	// Therefore each prefix path should result in two paths for the URLMap, one without a
-	// trailing '/' and one that ends with '/*'
-	if path.Path[len(path.Path)-1] == '/' {
-		return []string{path.Path[0 : len(path.Path)-1], path.Path + "*"}, nil
-	}
-	return []string{path.Path, path.Path + "/*"}, nil
+	urlMap, translateErrs := urlmaps.TranslateIngress(ing, svcPorts, policy)
+	return urlMap, append(errs, translateErrs...)
 }
 
 func getZone(n *api_v1.Node) string {
@@ -462,8 +479,11 @@ func isSimpleHTTPProbe(probe *api_v1.Probe) bool {
 // getProbeScheme returns the Kubernetes API URL scheme corresponding to the
 // protocol.
 func getProbeScheme(protocol annotations.AppProtocol) api_v1.URIScheme {
-	if protocol == annotations.ProtocolHTTP2 {
+	switch protocol {
+	case annotations.ProtocolHTTP2, annotations.ProtocolSSL:
 		return api_v1.URISchemeHTTPS
+	case annotations.ProtocolTCP:
+		return api_v1.URISchemeHTTP
 	}
 	return api_v1.URIScheme(string(protocol))
 }
@@ -32,9 +32,12 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/ingress-gce/pkg/annotations"
 	backendconfig "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
+	ingparamsv1beta1 "k8s.io/ingress-gce/pkg/apis/ingparams/v1beta1"
 	backendconfigclient "k8s.io/ingress-gce/pkg/backendconfig/client/clientset/versioned/fake"
 	"k8s.io/ingress-gce/pkg/context"
+	"k8s.io/ingress-gce/pkg/controller/errors"
 	"k8s.io/ingress-gce/pkg/flags"
+	ingparamsclient "k8s.io/ingress-gce/pkg/ingparams/client/clientset/versioned/fake"
 	"k8s.io/ingress-gce/pkg/test"
 	"k8s.io/ingress-gce/pkg/utils"
 	namer_util "k8s.io/ingress-gce/pkg/utils/namer"
@@ -211,6 +214,8 @@ func TestGetServicePort(t *testing.T) {
 		wantErr     bool
 		wantPort    bool
 		params      getServicePortParams
+		// wantProtocol is only checked if set.
+		wantProtocol annotations.AppProtocol
 	}{
 		{
 			desc: "clusterIP service",
@@ -245,6 +250,31 @@ func TestGetServicePort(t *testing.T) {
 			wantErr:  true,
 			wantPort: true,
 		},
+		{
+			desc: "ssl proxy backend",
+			spec: apiv1.ServiceSpec{
+				Type:  apiv1.ServiceTypeNodePort,
+				Ports: []apiv1.ServicePort{{Name: "mqtt", Port: 1883}},
+			},
+			id:           utils.ServicePortID{Port: v1.ServiceBackendPort{Name: "mqtt"}},
+			wantPort:     true,
+			params:       getServicePortParams{isSSLProxy: true},
+			wantProtocol: annotations.ProtocolTCP,
+		},
+		{
+			desc: "ssl proxy backend with https app protocol",
+			spec: apiv1.ServiceSpec{
+				Type:  apiv1.ServiceTypeNodePort,
+				Ports: []apiv1.ServicePort{{Name: "amqps", Port: 5671}},
+			},
+			annotations: map[string]string{
+				"service.alpha.kubernetes.io/app-protocols": `{"amqps":"HTTPS"}`,
+			},
+			id:           utils.ServicePortID{Port: v1.ServiceBackendPort{Name: "amqps"}},
+			wantPort:     true,
+			params:       getServicePortParams{isSSLProxy: true},
+			wantProtocol: annotations.ProtocolSSL,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -264,10 +294,37 @@ func TestGetServicePort(t *testing.T) {
 			if (port != nil) != tc.wantPort {
 				t.Errorf("translator.getServicePort(%+v) = %v, want port? %v", tc.id, port, tc.wantPort)
 			}
+			if tc.wantProtocol != "" && port != nil && port.Protocol != tc.wantProtocol {
+				t.Errorf("translator.getServicePort(%+v).Protocol = %v, want %v", tc.id, port.Protocol, tc.wantProtocol)
+			}
 		})
 	}
 }
 
+func TestGetServicePortRestrictedNodeAccess(t *testing.T) {
+	defer func(v bool) { flags.F.EnableRestrictedNodeAccess = v }(flags.F.EnableRestrictedNodeAccess)
+	flags.F.EnableRestrictedNodeAccess = true
+
+	translator := fakeTranslator()
+	svcName := types.NamespacedName{Name: "foo", Namespace: "default"}
+	// Without access to the nodes, even a ClusterIP service without the NEG
+	// annotation is a valid backend.
+	svc := test.NewService(svcName, apiv1.ServiceSpec{
+		Type:  apiv1.ServiceTypeClusterIP,
+		Ports: []apiv1.ServicePort{{Name: "http", Port: 80}},
+	})
+	translator.ctx.ServiceInformer.GetIndexer().Add(svc)
+
+	id := utils.ServicePortID{Service: svcName, Port: v1.ServiceBackendPort{Name: "http"}}
+	port, err := translator.getServicePort(id, &getServicePortParams{}, defaultNamer)
+	if err != nil {
+		t.Fatalf("translator.getServicePort(%+v) = _, %v, want nil", id, err)
+	}
+	if !port.NEGEnabled {
+		t.Errorf("translator.getServicePort(%+v).NEGEnabled = false, want true", id)
+	}
+}
+
 func TestGetServicePortWithBackendConfigEnabled(t *testing.T) {
 	backendConfig := test.NewBackendConfig(types.NamespacedName{Name: "config-http", Namespace: "default"}, backendconfig.BackendConfigSpec{
 		Cdn: &backendconfig.CDNConfig{
@@ -345,6 +402,296 @@ func TestGetServicePortWithBackendConfigEnabled(t *testing.T) {
 	}
 }
 
+// TestGetServicePortRejectsInvalidCDN asserts that CDN settings which cannot
+// be applied to the backend are rejected with an error, while the rest of the
+// BackendConfig is applied.
+func TestTranslateIngressKeepsDefaultBackendWithInvalidBackendConfig(t *testing.T) {
+	backendConfig := test.NewBackendConfig(types.NamespacedName{Name: "config-http", Namespace: "default"}, backendconfig.BackendConfigSpec{
+		Cdn: &backendconfig.CDNConfig{Enabled: true},
+	})
+	translator := fakeTranslator()
+	svcName := types.NamespacedName{Name: "first-service", Namespace: "default"}
+	svc := test.NewService(svcName, apiv1.ServiceSpec{
+		Type:  apiv1.ServiceTypeNodePort,
+		Ports: []apiv1.ServicePort{{Port: 80}},
+	})
+	svc.Annotations = map[string]string{annotations.BackendConfigKey: `{"default":"config-http"}`}
+	translator.ctx.ServiceInformer.GetIndexer().Add(svc)
+	translator.ctx.BackendConfigInformer.GetIndexer().Add(backendConfig)
+
+	// CDN is rejected for the backends of internal Ingresses, the rest of the
+	// default backend is still served.
+	ing := test.NewIngress(types.NamespacedName{Name: "my-ingress", Namespace: "default"}, v1.IngressSpec{
+		DefaultBackend: test.Backend("first-service", port80),
+	})
+	ing.Annotations = map[string]string{annotations.IngressClassKey: annotations.GceL7ILBIngressClass}
+	urlMap, errs := translator.TranslateIngress(ing, defaultBackend.ID, defaultNamer)
+	if len(errs) != 1 {
+		t.Errorf("TranslateIngress() = _, %v, want 1 error", errs)
+	}
+	want := &utils.GCEURLMap{DefaultBackend: &utils.ServicePort{ID: utils.ServicePortID{Service: svcName, Port: port80}}}
+	if !utils.EqualMapping(urlMap, want) {
+		t.Fatalf("TranslateIngress() = %+v, want %+v", urlMap.String(), want.String())
+	}
+	if bc := urlMap.DefaultBackend.BackendConfig; bc == nil || bc.Spec.Cdn != nil {
+		t.Errorf("DefaultBackend.BackendConfig = %+v, want the BackendConfig without CDN", bc)
+	}
+}
+
+func TestGetServicePortRejectsInvalidCDN(t *testing.T) {
+	timeoutSec := int64(42)
+	backendConfig := test.NewBackendConfig(types.NamespacedName{Name: "config-http", Namespace: "default"}, backendconfig.BackendConfigSpec{
+		Cdn:        &backendconfig.CDNConfig{Enabled: true},
+		TimeoutSec: &timeoutSec,
+	})
+	translator := fakeTranslator()
+	svcName := types.NamespacedName{Name: "foo", Namespace: "default"}
+	svc := test.NewService(svcName, apiv1.ServiceSpec{
+		Type:  apiv1.ServiceTypeNodePort,
+		Ports: []apiv1.ServicePort{{Name: "http", Port: 80}},
+	})
+	svc.Annotations = map[string]string{annotations.BackendConfigKey: `{"ports":{"http":"config-http"}}`}
+	translator.ctx.ServiceInformer.GetIndexer().Add(svc)
+	translator.ctx.BackendConfigInformer.GetIndexer().Add(backendConfig)
+
+	id := utils.ServicePortID{Service: svcName, Port: v1.ServiceBackendPort{Name: "http"}}
+	port, err := translator.getServicePort(id, &getServicePortParams{isL7ILB: true}, defaultNamer)
+	if _, ok := err.(errors.ErrBackendConfigValidation); !ok {
+		t.Errorf("translator.getServicePort(%+v) = _, %v, want %T", id, err, errors.ErrBackendConfigValidation{})
+	}
+	if port == nil || port.BackendConfig == nil {
+		t.Fatalf("translator.getServicePort(%+v) = %+v, _, want a port with a BackendConfig", id, port)
+	}
+	if port.BackendConfig.Spec.Cdn != nil {
+		t.Errorf("port.BackendConfig.Spec.Cdn = %+v, want nil", port.BackendConfig.Spec.Cdn)
+	}
+	if got := port.BackendConfig.Spec.TimeoutSec; got == nil || *got != timeoutSec {
+		t.Errorf("port.BackendConfig.Spec.TimeoutSec = %v, want %d", got, timeoutSec)
+	}
+	if backendConfig.Spec.Cdn == nil {
+		t.Errorf("The cached BackendConfig was modified")
+	}
+}
+
+func TestGetServicePortWithDefaultBackendConfig(t *testing.T) {
+	defaultName := types.NamespacedName{Name: "config-default", Namespace: "kube-system"}
+	defaultConfig := test.NewBackendConfig(defaultName, backendconfig.BackendConfigSpec{})
+	httpConfig := test.NewBackendConfig(types.NamespacedName{Name: "config-http", Namespace: "default"}, backendconfig.BackendConfigSpec{})
+
+	testCases := []struct {
+		desc        string
+		annotations map[string]string
+		missing     bool
+		wantConfig  string
+		wantErr     bool
+	}{
+		{
+			desc:       "no backend config annotation",
+			wantConfig: "config-default",
+		},
+		{
+			desc: "no backend config name for port",
+			annotations: map[string]string{
+				annotations.BackendConfigKey: `{"ports":{"https":"config-https"}}`,
+			},
+			wantConfig: "config-default",
+		},
+		{
+			desc: "backend config referenced by the service",
+			annotations: map[string]string{
+				annotations.BackendConfigKey: `{"ports":{"http":"config-http"}}`,
+			},
+			wantConfig: "config-http",
+		},
+		{
+			desc:    "default backend config does not exist",
+			missing: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			translator := fakeTranslator()
+			translator.ctx.DefaultBackendConfig = &defaultName
+			svcLister := translator.ctx.ServiceInformer.GetIndexer()
+			backendConfigLister := translator.ctx.BackendConfigInformer.GetIndexer()
+			svcName := types.NamespacedName{Name: "foo", Namespace: "default"}
+			svc := test.NewService(svcName, apiv1.ServiceSpec{
+				Type:  apiv1.ServiceTypeNodePort,
+				Ports: []apiv1.ServicePort{{Name: "http", Port: 80}, {Name: "https", Port: 443}},
+			})
+			svc.Annotations = tc.annotations
+			svcLister.Add(svc)
+			backendConfigLister.Add(httpConfig)
+			if !tc.missing {
+				backendConfigLister.Add(defaultConfig)
+			}
+
+			id := utils.ServicePortID{Service: svcName, Port: v1.ServiceBackendPort{Name: "http"}}
+			port, err := translator.getServicePort(id, &getServicePortParams{}, defaultNamer)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("translator.getServicePort(%+v) = _, %v, want err? %v", id, err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if tc.wantConfig == "" {
+				if port.BackendConfig != nil {
+					t.Errorf("translator.getServicePort(%+v).BackendConfig = %v, want nil", id, port.BackendConfig)
+				}
+				return
+			}
+			if port.BackendConfig == nil || port.BackendConfig.Name != tc.wantConfig {
+				t.Errorf("translator.getServicePort(%+v).BackendConfig = %v, want %q", id, port.BackendConfig, tc.wantConfig)
+			}
+		})
+	}
+}
+
+func TestTranslateIngressWithPathBackendConfig(t *testing.T) {
+	translator := fakeTranslator()
+	svcLister := translator.ctx.ServiceInformer.GetIndexer()
+	backendConfigLister := translator.ctx.BackendConfigInformer.GetIndexer()
+	svcLister.Add(test.NewService(types.NamespacedName{Name: "default-http-backend", Namespace: "kube-system"}, apiv1.ServiceSpec{
+		Type:  apiv1.ServiceTypeNodePort,
+		Ports: []apiv1.ServicePort{{Name: "http", Port: 80}},
+	}))
+	svc := test.NewService(types.NamespacedName{Name: "foo", Namespace: "default"}, apiv1.ServiceSpec{
+		Type:  apiv1.ServiceTypeNodePort,
+		Ports: []apiv1.ServicePort{{Name: "http", Port: 80, NodePort: 30001}},
+	})
+	svc.Annotations = map[string]string{annotations.BackendConfigKey: `{"default":"config-http"}`}
+	svcLister.Add(svc)
+	backendConfigLister.Add(test.NewBackendConfig(types.NamespacedName{Name: "config-http", Namespace: "default"}, backendconfig.BackendConfigSpec{}))
+	backendConfigLister.Add(test.NewBackendConfig(types.NamespacedName{Name: "config-static", Namespace: "default"}, backendconfig.BackendConfigSpec{}))
+
+	backend := v1.IngressBackend{Service: &v1.IngressServiceBackend{Name: "foo", Port: v1.ServiceBackendPort{Name: "http"}}}
+	ing := test.NewIngress(types.NamespacedName{Name: "my-ingress", Namespace: "default"}, v1.IngressSpec{
+		Rules: []v1.IngressRule{{
+			IngressRuleValue: v1.IngressRuleValue{HTTP: &v1.HTTPIngressRuleValue{
+				Paths: []v1.HTTPIngressPath{{Path: "/static/*", Backend: backend}, {Path: "/api/*", Backend: backend}},
+			}},
+		}},
+	})
+
+	for _, tc := range []struct {
+		desc         string
+		annotation   string
+		wantErrCount int
+		wantConfigs  map[string]string
+	}{
+		{
+			desc:        "no override",
+			wantConfigs: map[string]string{"/static/*": "config-http", "/api/*": "config-http"},
+		},
+		{
+			desc:        "override of one path",
+			annotation:  `{"/static/*": "config-static"}`,
+			wantConfigs: map[string]string{"/static/*": "config-static", "/api/*": "config-http"},
+		},
+		{
+			desc:         "invalid annotation",
+			annotation:   `config-static`,
+			wantErrCount: 1,
+			wantConfigs:  map[string]string{"/static/*": "config-http", "/api/*": "config-http"},
+		},
+		{
+			desc:         "missing BackendConfig",
+			annotation:   `{"/static/*": "config-missing"}`,
+			wantErrCount: 1,
+			wantConfigs:  map[string]string{"/static/*": "", "/api/*": "config-http"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ing := ing.DeepCopy()
+			if tc.annotation != "" {
+				ing.Annotations = map[string]string{annotations.PathBackendConfigsKey: tc.annotation}
+			}
+			urlMap, errs := translator.TranslateIngress(ing, defaultBackend.ID, defaultNamer)
+			if len(errs) != tc.wantErrCount {
+				t.Errorf("TranslateIngress() = _, %v, want %d errs", errs, tc.wantErrCount)
+			}
+			backendNames := sets.NewString()
+			for _, rule := range urlMap.HostRules[0].Paths {
+				var gotConfig string
+				if rule.Backend.BackendConfig != nil {
+					gotConfig = rule.Backend.BackendConfig.Name
+				}
+				if gotConfig != tc.wantConfigs[rule.Path] {
+					t.Errorf("BackendConfig of path %q = %q, want %q", rule.Path, gotConfig, tc.wantConfigs[rule.Path])
+				}
+				backendNames.Insert(rule.Backend.BackendName())
+			}
+			// Paths with different BackendConfigs use different backend services.
+			wantBackends := sets.NewString()
+			for _, config := range tc.wantConfigs {
+				wantBackends.Insert(config)
+			}
+			if backendNames.Len() != wantBackends.Len() {
+				t.Errorf("Got backends %v, want %d backends", backendNames.List(), wantBackends.Len())
+			}
+			if got := len(urlMap.AllServicePorts()); got != wantBackends.Len()+1 {
+				t.Errorf("len(AllServicePorts()) = %d, want %d", got, wantBackends.Len()+1)
+			}
+		})
+	}
+}
+
+func TestDefaultBackendTimeoutSec(t *testing.T) {
+	ctxConfig := context.ControllerContextConfig{
+		Namespace:             apiv1.NamespaceAll,
+		ResyncPeriod:          1 * time.Second,
+		DefaultBackendSvcPort: defaultBackend,
+		HealthCheckPath:       "/",
+		DefaultBackendTimeout: 2 * time.Minute,
+	}
+	ctx := context.NewControllerContext(nil, fake.NewSimpleClientset(), backendconfigclient.NewSimpleClientset(), nil, nil, ingparamsclient.NewSimpleClientset(), nil, nil, defaultNamer, "" /*kubeSystemUID*/, ctxConfig)
+	translator := &Translator{ctx: ctx}
+
+	apiGroup := "networking.gke.io"
+	timeout := int64(3600)
+	ctx.IngClassInformer.GetIndexer().Add(&v1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "streaming"},
+		Spec: v1.IngressClassSpec{
+			Parameters: &apiv1.TypedLocalObjectReference{APIGroup: &apiGroup, Kind: "GCPIngressParams", Name: "streaming"},
+		},
+	})
+	ctx.IngParamsInformer.GetIndexer().Add(&ingparamsv1beta1.GCPIngressParams{
+		ObjectMeta: metav1.ObjectMeta{Name: "streaming"},
+		Spec:       ingparamsv1beta1.GCPIngressParamsSpec{DefaultBackendTimeoutSec: &timeout},
+	})
+	invalidTimeout := int64(-1)
+	ctx.IngClassInformer.GetIndexer().Add(&v1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid"},
+		Spec: v1.IngressClassSpec{
+			Parameters: &apiv1.TypedLocalObjectReference{APIGroup: &apiGroup, Kind: "GCPIngressParams", Name: "invalid"},
+		},
+	})
+	ctx.IngParamsInformer.GetIndexer().Add(&ingparamsv1beta1.GCPIngressParams{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid"},
+		Spec:       ingparamsv1beta1.GCPIngressParamsSpec{DefaultBackendTimeoutSec: &invalidTimeout},
+	})
+
+	for _, tc := range []struct {
+		desc  string
+		class string
+		want  int64
+	}{
+		{desc: "controller default", want: 120},
+		{desc: "class default", class: "streaming", want: 3600},
+		{desc: "invalid class default", class: "invalid", want: 120},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ing := test.NewIngress(types.NamespacedName{Name: "ing", Namespace: "default"}, v1.IngressSpec{})
+			if tc.class != "" {
+				ing.Spec.IngressClassName = &tc.class
+			}
+			if got := translator.defaultBackendTimeoutSec(ing); got != tc.want {
+				t.Errorf("defaultBackendTimeoutSec() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestGetProbe(t *testing.T) {
 	translator := fakeTranslator()
 	nodePortToHealthCheck := map[utils.ServicePort]string{
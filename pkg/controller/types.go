@@ -17,6 +17,8 @@ limitations under the License.
 package controller
 
 import (
+	"context"
+
 	v1 "k8s.io/api/networking/v1"
 	"k8s.io/ingress-gce/pkg/loadbalancers"
 	"k8s.io/ingress-gce/pkg/utils"
@@ -27,4 +29,8 @@ type syncState struct {
 	urlMap *utils.GCEURLMap
 	ing    *v1.Ingress
 	l7     *loadbalancers.L7
+	// syncID identifies the sync in logs, events and resource descriptions.
+	syncID string
+	// ctx expires at the sync deadline.
+	ctx context.Context
 }
@@ -17,12 +17,16 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"text/template"
 
 	compute "google.golang.org/api/compute/v1"
 	api_v1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/ingress-gce/pkg/annotations"
 	"k8s.io/ingress-gce/pkg/utils"
 )
@@ -86,3 +90,22 @@ func nodePorts(svcPorts []utils.ServicePort) []int64 {
 	}
 	return ports
 }
+
+// statusHostname returns the hostname published in the status of the given
+// Ingress, rendered from the template tmpl.
+func statusHostname(tmpl *template.Template, ing *v1.Ingress, clusterUID string) (string, error) {
+	var buf bytes.Buffer
+	data := struct {
+		Name       string
+		Namespace  string
+		ClusterUID string
+	}{ing.Name, ing.Namespace, clusterUID}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	hostname := strings.ToLower(buf.String())
+	if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+		return "", fmt.Errorf("invalid hostname %q: %s", hostname, strings.Join(errs, ", "))
+	}
+	return hostname, nil
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+const (
+	// readyConditionType is the type of the condition recorded in the
+	// ready annotation of an Ingress.
+	readyConditionType = "Ready"
+	// Reasons of the Ready condition.
+	readyReasonWarmedUp  = "WarmedUp"
+	readyReasonWarmingUp = "WarmingUp"
+
+	// maxWarmupTargets is the maximum number of hosts and paths probed per
+	// Ingress.
+	maxWarmupTargets = 10
+	// warmupProbeTimeout is the timeout of a single probe.
+	warmupProbeTimeout = 5 * time.Second
+)
+
+// warmupTarget is a host and path of an Ingress probed on its VIP.
+type warmupTarget struct {
+	host string
+	path string
+}
+
+func (t warmupTarget) String() string {
+	return t.host + t.path
+}
+
+// warmupTargets returns the hosts and paths of the URL map which are probed
+// before the Ingress is Ready. Wildcard hosts are skipped and wildcard paths
+// are probed at their prefix. The default backend is probed without host.
+func warmupTargets(urlMap *utils.GCEURLMap) []warmupTarget {
+	var targets []warmupTarget
+	seen := map[warmupTarget]bool{}
+	add := func(t warmupTarget) {
+		if seen[t] || len(targets) >= maxWarmupTargets {
+			return
+		}
+		seen[t] = true
+		targets = append(targets, t)
+	}
+	if urlMap == nil {
+		return nil
+	}
+	for _, hostRule := range urlMap.HostRules {
+		if strings.Contains(hostRule.Hostname, "*") {
+			continue
+		}
+		for _, pathRule := range hostRule.Paths {
+			path := strings.TrimSuffix(pathRule.Path, "*")
+			if path == "" {
+				path = "/"
+			}
+			add(warmupTarget{host: hostRule.Hostname, path: path})
+		}
+	}
+	if urlMap.DefaultBackend != nil {
+		add(warmupTarget{path: "/"})
+	}
+	return targets
+}
+
+// ingressWarmup probes the VIP of Ingresses for their hosts and paths. Probes
+// run in the background, so that they do not block the sync of Ingresses, and
+// their results are picked up by the next sync.
+type ingressWarmup struct {
+	// httpPort and httpsPort are the ports probed on the VIP.
+	httpPort  string
+	httpsPort string
+
+	lock sync.Mutex
+	// pending are the keys of the Ingresses for which a probe is scheduled or
+	// running.
+	pending sets.String
+	// results are the results of the last probes, by Ingress key.
+	results map[string]warmupResult
+}
+
+// warmupResult is the result of a probe of the VIP of an Ingress.
+type warmupResult struct {
+	// generation and ip are the generation of the Ingress and the VIP
+	// probed.
+	generation int64
+	ip         string
+	err        error
+}
+
+func newIngressWarmup() *ingressWarmup {
+	return &ingressWarmup{httpPort: "80", httpsPort: "443", pending: sets.NewString(), results: map[string]warmupResult{}}
+}
+
+// start calls probe after delay in the background and records its result
+// for the given generation and ip of the Ingress with the given key, then
+// calls done. It does nothing if a probe of the Ingress is already pending.
+func (w *ingressWarmup) start(key string, generation int64, ip string, delay time.Duration, probe func() error, done func()) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.pending.Has(key) {
+		return
+	}
+	w.pending.Insert(key)
+	time.AfterFunc(delay, func() {
+		err := probe()
+		w.lock.Lock()
+		w.pending.Delete(key)
+		w.results[key] = warmupResult{generation: generation, ip: ip, err: err}
+		w.lock.Unlock()
+		done()
+	})
+}
+
+// result returns the result of the last probe of the Ingress with the given
+// key and whether it is known. Results of probes of another generation or ip
+// are discarded.
+func (w *ingressWarmup) result(key string, generation int64, ip string) (error, bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	res, ok := w.results[key]
+	if !ok {
+		return nil, false
+	}
+	delete(w.results, key)
+	if res.generation != generation || res.ip != ip {
+		return nil, false
+	}
+	return res.err, true
+}
+
+// forget discards the result of the last probe of the Ingress with the given
+// key, e.g. once the Ingress is deleted.
+func (w *ingressWarmup) forget(key string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	delete(w.results, key)
+}
+
+// probe sends a request for every target to the VIP ip. It returns an error
+// unless every request is answered without a server error. Certificates are
+// not verified, since managed certificates might not be provisioned yet.
+func (w *ingressWarmup) probe(ip string, https bool, targets []warmupTarget) error {
+	if len(targets) == 0 {
+		return fmt.Errorf("no hosts or paths to probe")
+	}
+	scheme, port := "http", w.httpPort
+	if https {
+		scheme, port = "https", w.httpsPort
+	}
+	for _, target := range targets {
+		client := &http.Client{
+			Timeout: warmupProbeTimeout,
+			// Each target needs its own server name, so the transport is
+			// not shared and must not keep idle connections around.
+			Transport: &http.Transport{
+				TLSClientConfig:   &tls.Config{ServerName: target.host, InsecureSkipVerify: true},
+				DisableKeepAlives: true,
+			},
+			// Redirects, e.g. to HTTPS, are answered by the load balancer
+			// and are not followed.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(ip, port), target.path), nil)
+		if err != nil {
+			return err
+		}
+		if target.host != "" {
+			req.Host = target.host
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("probe of %s failed: %v", target, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("probe of %s returned %s", target, resp.Status)
+		}
+	}
+	return nil
+}
+
+// readyForGeneration returns true if the Ready condition recorded in existing
+// is true for the given generation of the Ingress, in which case the VIP is
+// not probed again.
+func readyForGeneration(existing string, generation int64) bool {
+	var cond metav1.Condition
+	if existing == "" || json.Unmarshal([]byte(existing), &cond) != nil {
+		return false
+	}
+	return cond.Status == metav1.ConditionTrue && cond.ObservedGeneration == generation
+}
+
+// readyCondition returns the Ready condition of the Ingress given the result
+// of the probe and whether the Ingress became Ready. The last transition time
+// of the condition recorded in existing is kept if the status is unchanged.
+func readyCondition(ing *v1.Ingress, existing string, probeErr error, now time.Time) (metav1.Condition, bool) {
+	cond := metav1.Condition{
+		Type:               readyConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: ing.Generation,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             readyReasonWarmedUp,
+		Message:            "The load balancer serves the hosts and paths of the Ingress",
+	}
+	if probeErr != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = readyReasonWarmingUp
+		cond.Message = probeErr.Error()
+	}
+	var prev metav1.Condition
+	if existing != "" && json.Unmarshal([]byte(existing), &prev) == nil && prev.Status == cond.Status {
+		cond.LastTransitionTime = prev.LastTransitionTime
+		return cond, false
+	}
+	return cond, cond.Status == metav1.ConditionTrue
+}
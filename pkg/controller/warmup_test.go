@@ -0,0 +1,244 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+func TestWarmupTargets(t *testing.T) {
+	urlMap := utils.NewGCEURLMap()
+	urlMap.DefaultBackend = &utils.ServicePort{}
+	urlMap.PutPathRulesForHost("foo.com", []utils.PathRule{{Path: "/*"}, {Path: "/api/*"}, {Path: "/api"}})
+	urlMap.PutPathRulesForHost("*.bar.com", []utils.PathRule{{Path: "/*"}})
+
+	want := []warmupTarget{
+		{host: "foo.com", path: "/"},
+		{host: "foo.com", path: "/api/"},
+		{host: "foo.com", path: "/api"},
+		{path: "/"},
+	}
+	if got := warmupTargets(urlMap); !reflect.DeepEqual(got, want) {
+		t.Errorf("warmupTargets() = %v, want %v", got, want)
+	}
+}
+
+func TestIngressWarmupProbe(t *testing.T) {
+	var gotHosts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHosts = append(gotHosts, r.Host)
+		switch r.URL.Path {
+		case "/unavailable":
+			w.WriteHeader(http.StatusBadGateway)
+		case "/redirect":
+			http.Redirect(w, r, "/unavailable", http.StatusMovedPermanently)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ip, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newIngressWarmup()
+	w.httpPort = port
+
+	for _, tc := range []struct {
+		desc    string
+		targets []warmupTarget
+		wantErr bool
+	}{
+		{
+			desc:    "served",
+			targets: []warmupTarget{{host: "foo.com", path: "/"}, {path: "/"}},
+		},
+		{
+			desc:    "redirect is not followed",
+			targets: []warmupTarget{{host: "foo.com", path: "/redirect"}},
+		},
+		{
+			desc:    "server error",
+			targets: []warmupTarget{{host: "foo.com", path: "/"}, {host: "foo.com", path: "/unavailable"}},
+			wantErr: true,
+		},
+		{
+			desc:    "no targets",
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			gotHosts = nil
+			err := w.probe(ip, false, tc.targets)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("probe() = %v, want error %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if gotHosts[0] != "foo.com" {
+				t.Errorf("probe() sent host %q, want %q", gotHosts[0], "foo.com")
+			}
+		})
+	}
+}
+
+func TestReadyCondition(t *testing.T) {
+	ing := &v1.Ingress{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	before := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := before.Add(time.Hour)
+	existing := func(status metav1.ConditionStatus) string {
+		b, err := json.Marshal(metav1.Condition{Type: readyConditionType, Status: status, LastTransitionTime: metav1.NewTime(before)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	for _, tc := range []struct {
+		desc           string
+		existing       string
+		probeErr       error
+		wantStatus     metav1.ConditionStatus
+		wantTransition time.Time
+		wantReady      bool
+	}{
+		{
+			desc:           "not ready yet",
+			probeErr:       fmt.Errorf("probe failed"),
+			wantStatus:     metav1.ConditionFalse,
+			wantTransition: now,
+		},
+		{
+			desc:           "becomes ready",
+			existing:       existing(metav1.ConditionFalse),
+			wantStatus:     metav1.ConditionTrue,
+			wantTransition: now,
+			wantReady:      true,
+		},
+		{
+			desc:           "stays ready",
+			existing:       existing(metav1.ConditionTrue),
+			wantStatus:     metav1.ConditionTrue,
+			wantTransition: before,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			cond, ready := readyCondition(ing, tc.existing, tc.probeErr, now)
+			if cond.Status != tc.wantStatus || !cond.LastTransitionTime.Time.Equal(tc.wantTransition) || cond.ObservedGeneration != 2 {
+				t.Errorf("readyCondition() = %+v, want status %v, last transition %v and observed generation 2", cond, tc.wantStatus, tc.wantTransition)
+			}
+			if ready != tc.wantReady {
+				t.Errorf("readyCondition() became ready = %v, want %v", ready, tc.wantReady)
+			}
+		})
+	}
+}
+
+func TestIngressWarmupStart(t *testing.T) {
+	w := newIngressWarmup()
+	done := make(chan struct{})
+	release := make(chan struct{})
+	probes := 0
+	probe := func() error {
+		probes++
+		<-release
+		return fmt.Errorf("probe failed")
+	}
+
+	w.start("ns/ing", 1, "1.2.3.4", 0, probe, func() { close(done) })
+	// A probe of the same Ingress is already pending.
+	w.start("ns/ing", 1, "1.2.3.4", 0, probe, func() { t.Error("unexpected second probe") })
+	if _, ok := w.result("ns/ing", 1, "1.2.3.4"); ok {
+		t.Errorf("result() returned a result before the probe returned")
+	}
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("probe did not return")
+	}
+	if probes != 1 {
+		t.Errorf("got %d probes, want 1", probes)
+	}
+
+	if err, ok := w.result("ns/ing", 1, "1.2.3.4"); !ok || err == nil {
+		t.Errorf("result() = %v, %v, want the probe error", err, ok)
+	}
+	// Results are consumed.
+	if _, ok := w.result("ns/ing", 1, "1.2.3.4"); ok {
+		t.Errorf("result() returned a consumed result")
+	}
+}
+
+func TestIngressWarmupResultMismatch(t *testing.T) {
+	for _, tc := range []struct {
+		desc       string
+		generation int64
+		ip         string
+	}{
+		{desc: "other generation", generation: 2, ip: "1.2.3.4"},
+		{desc: "other ip", generation: 1, ip: "5.6.7.8"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			w := newIngressWarmup()
+			w.results["ns/ing"] = warmupResult{generation: 1, ip: "1.2.3.4"}
+			if _, ok := w.result("ns/ing", tc.generation, tc.ip); ok {
+				t.Errorf("result(%d, %q) returned the result of generation 1 and ip 1.2.3.4", tc.generation, tc.ip)
+			}
+		})
+	}
+}
+
+func TestReadyForGeneration(t *testing.T) {
+	cond := func(status metav1.ConditionStatus, generation int64) string {
+		b, err := json.Marshal(metav1.Condition{Type: readyConditionType, Status: status, ObservedGeneration: generation})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		existing string
+		want     bool
+	}{
+		{desc: "no condition"},
+		{desc: "invalid condition", existing: "{"},
+		{desc: "not ready", existing: cond(metav1.ConditionFalse, 2)},
+		{desc: "ready for previous generation", existing: cond(metav1.ConditionTrue, 1)},
+		{desc: "ready", existing: cond(metav1.ConditionTrue, 2), want: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := readyForGeneration(tc.existing, 2); got != tc.want {
+				t.Errorf("readyForGeneration() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -138,6 +138,11 @@ func crd(meta *CRDMeta, namespacedScoped bool) *apiextensionsv1.CustomResourceDe
 		if i == 0 {
 			version.Storage = true
 		}
+		if meta.statusSubresource {
+			version.Subresources = &apiextensionsv1.CustomResourceSubresources{
+				Status: &apiextensionsv1.CustomResourceSubresourceStatus{},
+			}
+		}
 		versions = append(versions, version)
 	}
 	crd.Spec.Versions = versions
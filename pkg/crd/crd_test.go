@@ -132,3 +132,17 @@ func TestCreateOrUpdateCRD(t *testing.T) {
 		}
 	}
 }
+
+func TestCRDStatusSubresource(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		meta := *crdMeta
+		if enabled {
+			meta.WithStatusSubresource()
+		}
+		for _, v := range crd(&meta, true).Spec.Versions {
+			if got := v.Subresources != nil && v.Subresources.Status != nil; got != enabled {
+				t.Errorf("status subresource enabled for version %s = %v, want %v", v.Name, got, enabled)
+			}
+		}
+	}
+}
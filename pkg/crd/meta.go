@@ -33,6 +33,8 @@ type CRDMeta struct {
 	shortNames []string
 	typeSource string
 	fn         common.GetOpenAPIDefinitions
+	// statusSubresource enables the status subresource for all versions.
+	statusSubresource bool
 }
 
 // NewCRDMeta creates a CRDMeta type which can be passed to a CRDHandler in
@@ -49,6 +51,12 @@ func NewCRDMeta(groupName, kind, listKind, singular, plural string, versions []*
 	}
 }
 
+// WithStatusSubresource enables the status subresource of the CRD.
+func (m *CRDMeta) WithStatusSubresource() *CRDMeta {
+	m.statusSubresource = true
+	return m
+}
+
 // Version specifies the API version and meta information that is needed to
 // generate OpenAPI schema based CRD validation.
 type Version struct {
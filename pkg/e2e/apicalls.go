@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	compositemetrics "k8s.io/ingress-gce/pkg/composite/metrics"
+)
+
+// ControllerAPICalls returns the number of GCE API calls the controller made
+// so far, read from its metrics endpoint.
+func (f *Framework) ControllerAPICalls() (compositemetrics.CallCounts, error) {
+	resp, err := http.Get(f.controllerMetricsURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %q: %d, want 200", f.controllerMetricsURL, resp.StatusCode)
+	}
+	return compositemetrics.ParseCallCounts(resp.Body)
+}
+
+// ExpectControllerAPICallBudget runs the scenario and fails the test if the
+// GCE API calls the controller made meanwhile exceed the budget. It returns
+// the calls made during the scenario. The test is skipped if the metrics
+// endpoint of the controller is not configured. The controller syncs all
+// Ingresses of the cluster, so the test must not run in parallel with other
+// tests.
+func (f *Framework) ExpectControllerAPICallBudget(t *testing.T, budget compositemetrics.Budget, scenario func()) compositemetrics.CallCounts {
+	t.Helper()
+
+	if f.controllerMetricsURL == "" {
+		t.Skip("The metrics URL of the controller is not set, skipping the GCE API call budget check")
+	}
+	before, err := f.ControllerAPICalls()
+	if err != nil {
+		t.Fatalf("error getting the GCE API calls of the controller: %v", err)
+	}
+	scenario()
+	after, err := f.ControllerAPICalls()
+	if err != nil {
+		t.Fatalf("error getting the GCE API calls of the controller: %v", err)
+	}
+	calls := after.Sub(before)
+	if err := calls.CheckBudget(budget); err != nil {
+		t.Errorf("%v, calls: %v", err, calls)
+	}
+	return calls
+}
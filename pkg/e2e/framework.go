@@ -43,6 +43,7 @@ import (
 	frontendconfigclient "k8s.io/ingress-gce/pkg/frontendconfig/client/clientset/versioned"
 	serviceattachment "k8s.io/ingress-gce/pkg/serviceattachment/client/clientset/versioned"
 	svcnegclient "k8s.io/ingress-gce/pkg/svcneg/client/clientset/versioned"
+	"k8s.io/ingress-gce/pkg/utils/common"
 	"k8s.io/klog"
 )
 
@@ -53,8 +54,16 @@ type Options struct {
 	Network             string
 	Seed                int64
 	DestroySandboxes    bool
+	AdoptSandboxes      bool
 	GceEndpointOverride string
 	CreateILBSubnet     bool
+	// WaitConfigs override the polling configuration of the wait helpers
+	// by kind. Zero fields keep the defaults.
+	WaitConfigs map[WaitKind]WaitConfig
+	// ControllerMetricsURL is the URL of the metrics endpoint of the
+	// controller, used to check its GCE API call budget. If empty, budget
+	// checks are skipped.
+	ControllerMetricsURL string
 }
 
 const (
@@ -91,6 +100,7 @@ func NewFramework(config *rest.Config, options Options) *Framework {
 		klog.Fatalf("Failed to create Service Attachment client: %v", err)
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	f := &Framework{
 		RestConfig:           config,
 		Clientset:            kubernetes.NewForConfigOrDie(config),
@@ -105,7 +115,12 @@ func NewFramework(config *rest.Config, options Options) *Framework {
 		Cloud:                theCloud,
 		Rand:                 rand.New(rand.NewSource(options.Seed)),
 		destroySandboxes:     options.DestroySandboxes,
+		adoptSandboxes:       options.AdoptSandboxes,
 		CreateILBSubnet:      options.CreateILBSubnet,
+		waitConfigs:          options.WaitConfigs,
+		controllerMetricsURL: options.ControllerMetricsURL,
+		ctx:                  ctx,
+		cancel:               cancel,
 	}
 	f.statusManager = NewStatusManager(f)
 
@@ -151,12 +166,38 @@ type Framework struct {
 	statusManager         *StatusManager
 
 	destroySandboxes bool
-	CreateILBSubnet  bool
+	// adoptSandboxes keeps the sandboxes of RunWithSandboxFixed after the
+	// tests, so that the next run adopts them and their GCE resources.
+	adoptSandboxes  bool
+	CreateILBSubnet bool
+
+	// waitConfigs override the polling configuration of the wait helpers.
+	waitConfigs map[WaitKind]WaitConfig
+	// controllerMetricsURL is the metrics endpoint of the controller.
+	controllerMetricsURL string
+	// ctx is done once the framework is aborted, which stops all waits.
+	ctx    context.Context
+	cancel context.CancelFunc
 
 	lock      sync.Mutex
 	sandboxes []*Sandbox
 }
 
+// Context returns the context of the framework, which is done once the
+// framework is aborted.
+func (f *Framework) Context() context.Context {
+	return f.ctx
+}
+
+// Abort stops the waits of all tests, which then fail with the error of the
+// context of the framework. It is called on fatal errors, such as a broken
+// cluster in which sandboxes cannot be created, to fail fast instead of
+// waiting for every timeout.
+func (f *Framework) Abort(reason error) {
+	klog.Errorf("Aborting e2e framework: %v", reason)
+	f.cancel()
+}
+
 // SanityCheck the test environment before proceeding.
 func (f *Framework) SanityCheck() error {
 	klog.V(2).Info("Checking connectivity with Kubernetes API")
@@ -185,7 +226,7 @@ func (f *Framework) SanityCheck() error {
 		return err
 	}
 	klog.V(2).Info("Waiting for BackendConfig CRD to be established")
-	if err := waitForBackendConfigCRDEstablish(f.crdClient); err != nil {
+	if err := f.waitForBackendConfigCRDEstablish(); err != nil {
 		klog.Errorf("Error waiting for BackendConfig CRD to be established: %v", err)
 		return err
 	}
@@ -206,6 +247,9 @@ func (f *Framework) CatchSIGINT() {
 }
 func (f *Framework) sigintHandler() {
 	if !f.destroySandboxes {
+		// Keep the sandboxes, but stop the waits so that the tests fail
+		// fast.
+		f.Abort(fmt.Errorf("SIGINT received"))
 		return
 	}
 	klog.Warningf("SIGINT received, shutting down (disable with -handleSIGINT=false)")
@@ -218,6 +262,9 @@ func (f *Framework) shutdown(exitCode int) {
 
 	klog.V(2).Infof("Cleaning up sandboxes...")
 	for _, s := range f.sandboxes {
+		if s.adopt {
+			continue
+		}
 		s.Destroy()
 	}
 	f.statusManager.shutdown()
@@ -241,6 +288,7 @@ func (f *Framework) WithSandbox(testFunc func(*Sandbox) error) error {
 	klog.V(2).Infof("Using namespace %q for test sandbox", sandbox.Namespace)
 	if err := sandbox.Create(); err != nil {
 		f.lock.Unlock()
+		f.Abort(fmt.Errorf("error creating sandbox %s: %v", sandbox.Namespace, err))
 		return err
 	}
 
@@ -275,6 +323,7 @@ func (f *Framework) RunWithSandbox(name string, t *testing.T, testFunc func(*tes
 		klog.V(2).Infof("Using namespace %q for test sandbox", sandbox.Namespace)
 		if err := sandbox.Create(); err != nil {
 			f.lock.Unlock()
+			f.Abort(fmt.Errorf("error creating sandbox %s: %v", sandbox.Namespace, err))
 			t.Fatalf("error creating sandbox: %v", err)
 		}
 
@@ -290,6 +339,48 @@ func (f *Framework) RunWithSandbox(name string, t *testing.T, testFunc func(*tes
 	})
 }
 
+// RunWithSandboxFixed runs the testFunc with a Sandbox whose namespace is
+// derived from the name of the test, so that it is the same across runs, if
+// the framework adopts sandboxes. The sandbox and the resources created in it
+// are then not torn down after the test, and the next run reuses them instead
+// of creating them again. Otherwise this is the same as RunWithSandbox, so that
+// concurrent runs do not share namespaces. Tests run with this should create their resources with
+// the Ensure helpers and skip deleting them if Sandbox.Adopt() is true. This
+// exercises the idempotent Ensure paths of the controller and cuts the time
+// to iterate on a test. This indirectly calls testing.T.Run().
+func (f *Framework) RunWithSandboxFixed(name string, t *testing.T, testFunc func(*testing.T, *Sandbox)) {
+	if !f.adoptSandboxes {
+		f.RunWithSandbox(name, t, testFunc)
+		return
+	}
+	t.Run(name, func(t *testing.T) {
+		f.lock.Lock()
+		sandbox := &Sandbox{
+			Namespace: fmt.Sprintf("test-sandbox-fixed-%s", common.ContentHash(t.Name(), 16)),
+			f:         f,
+			adopt:     true,
+		}
+		for _, s := range f.sandboxes {
+			if s.Namespace == sandbox.Namespace {
+				f.lock.Unlock()
+				t.Fatalf("Sandbox %s was created previously by the framework.", s.Namespace)
+			}
+		}
+		klog.V(2).Infof("Using namespace %q for fixed test sandbox of %s", sandbox.Namespace, t.Name())
+		if err := sandbox.Create(); err != nil {
+			f.lock.Unlock()
+			f.Abort(fmt.Errorf("error creating sandbox %s: %v", sandbox.Namespace, err))
+			t.Fatalf("error creating sandbox: %v", err)
+		}
+
+		f.sandboxes = append(f.sandboxes, sandbox)
+		f.lock.Unlock()
+
+		defer sandbox.DumpSandboxInfo(t)
+		testFunc(t, sandbox)
+	})
+}
+
 // NewCloud creates a new cloud for the given project.
 func NewCloud(project, GceEndpointOverride string) (cloud.Cloud, error) {
 	const computeScope = "https://www.googleapis.com/auth/compute"
@@ -34,7 +34,6 @@ import (
 	v1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
-	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -49,8 +48,9 @@ import (
 	negv1beta1 "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1"
 	"k8s.io/ingress-gce/pkg/e2e/adapter"
 	"k8s.io/ingress-gce/pkg/fuzz"
-	"k8s.io/ingress-gce/pkg/fuzz/features"
-	"k8s.io/ingress-gce/pkg/fuzz/whitebox"
+	// Register the built-in features and whitebox tests.
+	_ "k8s.io/ingress-gce/pkg/fuzz/features"
+	_ "k8s.io/ingress-gce/pkg/fuzz/whitebox"
 	negtypes "k8s.io/ingress-gce/pkg/neg/types"
 	"k8s.io/ingress-gce/pkg/psc"
 	"k8s.io/ingress-gce/pkg/utils"
@@ -128,8 +128,8 @@ func IsRfc1918Addr(addr string) bool {
 
 // UpgradeTestWaitForIngress waits for ingress to stabilize and set sandbox status to stable.
 // Note that this is used only for upgrade tests.
-func UpgradeTestWaitForIngress(s *Sandbox, ing *networkingv1.Ingress, options *WaitForIngressOptions) (*networkingv1.Ingress, error) {
-	ing, err := WaitForIngress(s, ing, nil, options)
+func UpgradeTestWaitForIngress(s *Sandbox, ing *networkingv1.Ingress, options *WaitForIngressOptions, opts ...WaitOption) (*networkingv1.Ingress, error) {
+	ing, err := WaitForIngress(s, ing, nil, options, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -140,8 +140,8 @@ func UpgradeTestWaitForIngress(s *Sandbox, ing *networkingv1.Ingress, options *W
 // WaitForIngress to stabilize.
 // We expect the ingress to be unreachable at first as LB is
 // still programming itself (i.e 404's / 502's)
-func WaitForIngress(s *Sandbox, ing *networkingv1.Ingress, fc *frontendconfigv1beta1.FrontendConfig, options *WaitForIngressOptions) (*networkingv1.Ingress, error) {
-	err := wait.Poll(ingressPollInterval, ingressPollTimeout, func() (bool, error) {
+func WaitForIngress(s *Sandbox, ing *networkingv1.Ingress, fc *frontendconfigv1beta1.FrontendConfig, options *WaitForIngressOptions, opts ...WaitOption) (*networkingv1.Ingress, error) {
+	err := s.f.poll(WaitIngress, func() (bool, error) {
 		var err error
 		crud := adapter.IngressCRUD{C: s.f.Clientset}
 		ing, err = crud.Get(s.Namespace, ing.Name)
@@ -150,7 +150,7 @@ func WaitForIngress(s *Sandbox, ing *networkingv1.Ingress, fc *frontendconfigv1b
 		}
 		attrs := fuzz.DefaultAttributes()
 		attrs.Region = s.f.Region
-		validator, err := fuzz.NewIngressValidator(s.ValidatorEnv, ing, fc, []fuzz.WhiteboxTest{}, attrs, features.All)
+		validator, err := fuzz.NewIngressValidator(s.ValidatorEnv, ing, fc, []fuzz.WhiteboxTest{}, attrs, fuzz.RegisteredFeatures())
 		if err != nil {
 			return true, err
 		}
@@ -162,7 +162,7 @@ func WaitForIngress(s *Sandbox, ing *networkingv1.Ingress, fc *frontendconfigv1b
 			return false, nil
 		}
 		return true, fmt.Errorf("unexpected error from validation: %v", result.Err)
-	})
+	}, opts...)
 	return ing, err
 }
 
@@ -174,11 +174,11 @@ func WaitForIngress(s *Sandbox, ing *networkingv1.Ingress, fc *frontendconfigv1b
 // validator thinks that http load balancer is configured when https only
 // configuration exists.
 // TODO(smatti): Remove this when the above issue is fixed.
-func WaitForHTTPResourceAnnotations(s *Sandbox, ing *networkingv1.Ingress) (*networkingv1.Ingress, error) {
+func WaitForHTTPResourceAnnotations(s *Sandbox, ing *networkingv1.Ingress, opts ...WaitOption) (*networkingv1.Ingress, error) {
 	ingKey := fmt.Sprintf("%s/%s", s.Namespace, ing.Name)
 	klog.Infof("Waiting for HTTP annotations to be added on Ingress %s", ingKey)
 	var err error
-	if waitErr := wait.Poll(updateIngressPollInterval, updateIngressPollTimeout, func() (bool, error) {
+	if waitErr := s.f.poll(WaitIngressUpdate, func() (bool, error) {
 		crud := adapter.IngressCRUD{C: s.f.Clientset}
 		if ing, err = crud.Get(s.Namespace, ing.Name); err != nil {
 			return true, err
@@ -189,7 +189,7 @@ func WaitForHTTPResourceAnnotations(s *Sandbox, ing *networkingv1.Ingress) (*net
 		}
 		klog.Infof("HTTP forwarding rule annotation found on ingress %s", ingKey)
 		return true, nil
-	}); waitErr != nil {
+	}, opts...); waitErr != nil {
 		if waitErr == wait.ErrWaitTimeout {
 			return nil, fmt.Errorf("error time out, last seem error: %v", err)
 		}
@@ -200,10 +200,10 @@ func WaitForHTTPResourceAnnotations(s *Sandbox, ing *networkingv1.Ingress) (*net
 
 // WaitForFinalizer waits for Finalizer to be added.
 // Note that this is used only for upgrade tests.
-func WaitForFinalizer(s *Sandbox, ing *networkingv1.Ingress) (*networkingv1.Ingress, error) {
+func WaitForFinalizer(s *Sandbox, ing *networkingv1.Ingress, opts ...WaitOption) (*networkingv1.Ingress, error) {
 	ingKey := fmt.Sprintf("%s/%s", s.Namespace, ing.Name)
 	klog.Infof("Waiting for Finalizer to be added for Ingress %s", ingKey)
-	err := wait.Poll(k8sApiPoolInterval, k8sApiPollTimeout, func() (bool, error) {
+	err := s.f.poll(WaitK8sAPI, func() (bool, error) {
 		var err error
 		crud := adapter.IngressCRUD{C: s.f.Clientset}
 		if ing, err = crud.Get(s.Namespace, ing.Name); err != nil {
@@ -218,7 +218,7 @@ func WaitForFinalizer(s *Sandbox, ing *networkingv1.Ingress) (*networkingv1.Ingr
 			return false, nil
 		}
 		return true, nil
-	})
+	}, opts...)
 	if err == nil {
 		// Set status back to stable.
 		s.PutStatus(Stable)
@@ -234,10 +234,10 @@ func WhiteboxTest(ing *networkingv1.Ingress, fc *frontendconfigv1beta1.FrontendC
 
 	vip := ing.Status.LoadBalancer.Ingress[0].IP
 	klog.Infof("Ingress %s/%s VIP = %s", s.Namespace, ing.Name, vip)
-	params := &fuzz.GCLBForVIPParams{
-		VIP:        vip,
-		Region:     region,
-		Validators: fuzz.FeatureValidators(features.All),
+	params := fuzz.GCLBForVIPParamsForIngress(s.ValidatorEnv, ing, vip, fuzz.FeatureValidators(fuzz.RegisteredFeatures()))
+	if region != "" {
+		params.Region = region
+		params.Network = s.ValidatorEnv.Network()
 	}
 	gclb, err := fuzz.GCLBForVIP(context.Background(), cloud, params)
 	if err != nil {
@@ -252,7 +252,7 @@ func WhiteboxTest(ing *networkingv1.Ingress, fc *frontendconfigv1beta1.FrontendC
 
 // performWhiteboxTests runs the whitebox tests against the Ingress.
 func performWhiteboxTests(s *Sandbox, ing *networkingv1.Ingress, fc *frontendconfigv1beta1.FrontendConfig, gclb *fuzz.GCLB) error {
-	validator, err := fuzz.NewIngressValidator(s.ValidatorEnv, ing, fc, whitebox.AllTests, nil, []fuzz.Feature{})
+	validator, err := fuzz.NewIngressValidator(s.ValidatorEnv, ing, fc, fuzz.RegisteredWhiteboxTests(), nil, []fuzz.Feature{})
 	if err != nil {
 		return err
 	}
@@ -264,13 +264,13 @@ func performWhiteboxTests(s *Sandbox, ing *networkingv1.Ingress, fc *frontendcon
 
 // WaitForIngressDeletion deletes the given ingress and waits for the
 // resources associated with it to be deleted.
-func WaitForIngressDeletion(ctx context.Context, g *fuzz.GCLB, s *Sandbox, ing *networkingv1.Ingress, options *fuzz.GCLBDeleteOptions) error {
+func WaitForIngressDeletion(ctx context.Context, g *fuzz.GCLB, s *Sandbox, ing *networkingv1.Ingress, options *fuzz.GCLBDeleteOptions, opts ...WaitOption) error {
 	crud := adapter.IngressCRUD{C: s.f.Clientset}
 	if err := crud.Delete(ing.Namespace, ing.Name); err != nil {
 		return fmt.Errorf("delete(%q) = %v, want nil", ing.Name, err)
 	}
 	klog.Infof("Waiting for GCLB resources to be deleted (%s/%s), IngressDeletionOptions=%+v", s.Namespace, ing.Name, options)
-	if err := WaitForGCLBDeletion(ctx, s.f.Cloud, g, options); err != nil {
+	if err := WaitForGCLBDeletion(ctx, s.f.Cloud, s, g, options, opts...); err != nil {
 		return fmt.Errorf("WaitForGCLBDeletion(...) = %v, want nil", err)
 	}
 	klog.Infof("GCLB resources deleted (%s/%s)", s.Namespace, ing.Name)
@@ -279,16 +279,16 @@ func WaitForIngressDeletion(ctx context.Context, g *fuzz.GCLB, s *Sandbox, ing *
 
 // WaitForFinalizerDeletion waits for gclb resources to be deleted and
 // the finalizer attached to the Ingress resource to be removed.
-func WaitForFinalizerDeletion(ctx context.Context, g *fuzz.GCLB, s *Sandbox, ingName string, options *fuzz.GCLBDeleteOptions) error {
+func WaitForFinalizerDeletion(ctx context.Context, g *fuzz.GCLB, s *Sandbox, ingName string, options *fuzz.GCLBDeleteOptions, opts ...WaitOption) error {
 	klog.Infof("Waiting for GCLB resources to be deleted (%s/%s), IngressDeletionOptions=%+v", s.Namespace, ingName, options)
-	if err := WaitForGCLBDeletion(ctx, s.f.Cloud, g, options); err != nil {
+	if err := WaitForGCLBDeletion(ctx, s.f.Cloud, s, g, options, opts...); err != nil {
 		return fmt.Errorf("WaitForGCLBDeletion(...) = %v, want nil", err)
 	}
 	klog.Infof("GCLB resources deleted (%s/%s)", s.Namespace, ingName)
 
 	crud := adapter.IngressCRUD{C: s.f.Clientset}
 	klog.Infof("Waiting for Finalizer to be removed for Ingress %s/%s", s.Namespace, ingName)
-	return wait.Poll(k8sApiPoolInterval, k8sApiPollTimeout, func() (bool, error) {
+	return s.f.poll(WaitK8sAPI, func() (bool, error) {
 		ing, err := crud.Get(s.Namespace, ingName)
 		if err != nil {
 			klog.Infof("WaitForFinalizerDeletion(%s/%s) = Error retrieving Ingress: %v", s.Namespace, ingName, err)
@@ -299,25 +299,25 @@ func WaitForFinalizerDeletion(ctx context.Context, g *fuzz.GCLB, s *Sandbox, ing
 			return false, nil
 		}
 		return true, nil
-	})
+	}, append([]WaitOption{WithContext(ctx)}, opts...)...)
 }
 
 // WaitForGCLBDeletion waits for the resources associated with the GLBC to be
 // deleted.
-func WaitForGCLBDeletion(ctx context.Context, c cloud.Cloud, g *fuzz.GCLB, options *fuzz.GCLBDeleteOptions) error {
-	return wait.Poll(gclbDeletionInterval, gclbDeletionTimeout, func() (bool, error) {
+func WaitForGCLBDeletion(ctx context.Context, c cloud.Cloud, s *Sandbox, g *fuzz.GCLB, options *fuzz.GCLBDeleteOptions, opts ...WaitOption) error {
+	return s.f.poll(WaitGCLBDeletion, func() (bool, error) {
 		if err := g.CheckResourceDeletion(ctx, c, options); err != nil {
 			klog.Infof("WaitForGCLBDeletion(%q) = %v", g.VIP, err)
 			return false, nil
 		}
 		return true, nil
-	})
+	}, append([]WaitOption{WithContext(ctx)}, opts...)...)
 }
 
 // WaitForFrontendResourceDeletion waits for frontend resources associated with the GLBC to be
 // deleted for given protocol.
-func WaitForFrontendResourceDeletion(ctx context.Context, c cloud.Cloud, g *fuzz.GCLB, options *fuzz.GCLBDeleteOptions) error {
-	return wait.Poll(gclbDeletionInterval, gclbDeletionTimeout, func() (bool, error) {
+func WaitForFrontendResourceDeletion(ctx context.Context, c cloud.Cloud, s *Sandbox, g *fuzz.GCLB, options *fuzz.GCLBDeleteOptions, opts ...WaitOption) error {
+	return s.f.poll(WaitGCLBDeletion, func() (bool, error) {
 		if options.CheckHttpFrontendResources {
 			if err := g.CheckResourceDeletionByProtocol(ctx, c, options, fuzz.HttpProtocol); err != nil {
 				klog.Infof("WaitForGCLBDeletionByProtocol(..., %q, %q) = %v", g.VIP, fuzz.HttpProtocol, err)
@@ -331,33 +331,35 @@ func WaitForFrontendResourceDeletion(ctx context.Context, c cloud.Cloud, g *fuzz
 			}
 		}
 		return true, nil
-	})
+	}, append([]WaitOption{WithContext(ctx)}, opts...)...)
 }
 
 // WaitForNEGDeletion waits for all NEGs associated with a GCLB to be deleted via GC
-func WaitForNEGDeletion(ctx context.Context, c cloud.Cloud, g *fuzz.GCLB, options *fuzz.GCLBDeleteOptions) error {
-	return wait.Poll(negPollInterval, gclbDeletionTimeout, func() (bool, error) {
+func WaitForNEGDeletion(ctx context.Context, c cloud.Cloud, s *Sandbox, g *fuzz.GCLB, options *fuzz.GCLBDeleteOptions, opts ...WaitOption) error {
+	return s.f.poll(WaitNEGStatus, func() (bool, error) {
 		if err := g.CheckNEGDeletion(ctx, c, options); err != nil {
 			klog.Infof("WaitForNegDeletion(%q) = %v", g.VIP, err)
 			return false, nil
 		}
 		return true, nil
-	})
+	}, append([]WaitOption{WithContext(ctx)}, opts...)...)
 }
 
-func WaitForRedirectURLMapDeletion(ctx context.Context, c cloud.Cloud, g *fuzz.GCLB) error {
-	return wait.Poll(gclbDeletionInterval, redirectURLMapPollTimeout, func() (bool, error) {
+// WaitForRedirectURLMapDeletion waits for the redirect url map of the GCLB to
+// be deleted.
+func WaitForRedirectURLMapDeletion(ctx context.Context, c cloud.Cloud, s *Sandbox, g *fuzz.GCLB, opts ...WaitOption) error {
+	return s.f.poll(WaitRedirectURLMapDeletion, func() (bool, error) {
 		if err := g.CheckRedirectUrlMapDeletion(ctx, c); err != nil {
 			klog.Infof("WaitForRedirectURLMapDeletion(%q) = %v", g.VIP, err)
 			return false, nil
 		}
 		return true, nil
-	})
+	}, append([]WaitOption{WithContext(ctx)}, opts...)...)
 }
 
 // WaitForEchoDeploymentStable waits until the deployment's readyReplicas, availableReplicas and updatedReplicas are equal to replicas.
-func WaitForEchoDeploymentStable(s *Sandbox, name string) error {
-	return wait.Poll(k8sApiPoolInterval, k8sApiPollTimeout, func() (bool, error) {
+func WaitForEchoDeploymentStable(s *Sandbox, name string, opts ...WaitOption) error {
+	return s.f.poll(WaitK8sAPI, func() (bool, error) {
 		deployment, err := s.f.Clientset.AppsV1().Deployments(s.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
 		if deployment == nil || err != nil {
 			return false, fmt.Errorf("failed to get deployment %s/%s: %v", s.Namespace, name, err)
@@ -367,19 +369,18 @@ func WaitForEchoDeploymentStable(s *Sandbox, name string) error {
 			return false, nil
 		}
 		return true, nil
-	})
+	}, opts...)
 }
 
 // WaitForNegStatus waits util the neg status on the service got to expected state.
 // if noPresentTest set to true, WaitForNegStatus makes sure no NEG annotation is added until timeout(5 mins).
-func WaitForNegStatus(s *Sandbox, name string, expectSvcPorts []string, noPresentTest bool) (*annotations.NegStatus, error) {
+func WaitForNegStatus(s *Sandbox, name string, expectSvcPorts []string, noPresentTest bool, opts ...WaitOption) (*annotations.NegStatus, error) {
 	var ret annotations.NegStatus
 	var err error
-	timeout := gclbDeletionTimeout
 	if noPresentTest {
-		timeout = 2 * time.Minute
+		opts = append([]WaitOption{WithTimeout(2 * time.Minute)}, opts...)
 	}
-	err = wait.Poll(negPollInterval, timeout, func() (bool, error) {
+	err = s.f.poll(WaitNEGStatus, func() (bool, error) {
 		svc, err := s.f.Clientset.CoreV1().Services(s.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
 		if svc == nil || err != nil {
 			return false, fmt.Errorf("failed to get service %s/%s: %v", s.Namespace, name, err)
@@ -390,7 +391,7 @@ func WaitForNegStatus(s *Sandbox, name string, expectSvcPorts []string, noPresen
 			return false, nil
 		}
 		return true, nil
-	})
+	}, opts...)
 	if noPresentTest && err == wait.ErrWaitTimeout {
 		return nil, nil
 	}
@@ -398,8 +399,8 @@ func WaitForNegStatus(s *Sandbox, name string, expectSvcPorts []string, noPresen
 }
 
 // WaitForNegs waits until the input NEG got into the expect states.
-func WaitForNegs(ctx context.Context, c cloud.Cloud, negName string, zones []string, expectHealthy bool, expectCount int) error {
-	return wait.Poll(negPollInterval, negPollTimeout, func() (bool, error) {
+func WaitForNegs(ctx context.Context, c cloud.Cloud, s *Sandbox, negName string, zones []string, expectHealthy bool, expectCount int, opts ...WaitOption) error {
+	return s.f.poll(WaitNEG, func() (bool, error) {
 		negs, err := fuzz.NetworkEndpointsInNegs(ctx, c, negName, zones)
 		if err != nil {
 			klog.Infof("WaitForNegs(%q, %v, %v, %v) failed to retrieve NEGs: %v", negName, zones, expectHealthy, expectCount, err)
@@ -411,18 +412,18 @@ func WaitForNegs(ctx context.Context, c cloud.Cloud, negName string, zones []str
 			return false, nil
 		}
 		return true, nil
-	})
+	}, append([]WaitOption{WithContext(ctx)}, opts...)...)
 }
 
 // WaitForDistinctHosts waits util
-func WaitForDistinctHosts(ctx context.Context, vip string, expectDistinctHosts int, tolerateTransientError bool) error {
-	return wait.Poll(negPollInterval, negPollTimeout, func() (bool, error) {
+func WaitForDistinctHosts(ctx context.Context, s *Sandbox, vip string, expectDistinctHosts int, tolerateTransientError bool, opts ...WaitOption) error {
+	return s.f.poll(WaitNEG, func() (bool, error) {
 		if err := CheckDistinctResponseHost(vip, expectDistinctHosts, tolerateTransientError); err != nil {
 			klog.Infof("WaitForDistinctHosts(%q, %v, %v) = %v", vip, expectDistinctHosts, tolerateTransientError, err)
 			return false, nil
 		}
 		return true, nil
-	})
+	}, append([]WaitOption{WithContext(ctx)}, opts...)...)
 }
 
 // CheckSvcEvents checks to see if the service has an event with the provided msgType and message
@@ -673,9 +674,10 @@ func CheckV2Finalizer(ing *networkingv1.Ingress) error {
 }
 
 // WaitDestinationRuleAnnotation waits until the DestinationRule NEG annotation count equal to negCount.
-func WaitDestinationRuleAnnotation(s *Sandbox, namespace, name string, negCount int, timeout time.Duration) (*annotations.DestinationRuleNEGStatus, error) {
+func WaitDestinationRuleAnnotation(s *Sandbox, namespace, name string, negCount int, timeout time.Duration, opts ...WaitOption) (*annotations.DestinationRuleNEGStatus, error) {
 	var rsl annotations.DestinationRuleNEGStatus
-	if err := wait.Poll(5*time.Second, timeout, func() (bool, error) {
+	opts = append([]WaitOption{WithInterval(5 * time.Second), WithTimeout(timeout)}, opts...)
+	if err := s.f.poll(WaitK8sAPI, func() (bool, error) {
 		unsDr, err := s.f.DestinationRuleClient.Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 		if err != nil {
 			return false, err
@@ -694,14 +696,14 @@ func WaitDestinationRuleAnnotation(s *Sandbox, namespace, name string, negCount
 			}
 		}
 		return false, nil
-	}); err != nil {
+	}, opts...); err != nil {
 		return nil, err
 	}
 	return &rsl, nil
 }
 
 // WaitConfigMapEvents waits the msgs messages present for namespace:name ConfigMap until timeout.
-func WaitConfigMapEvents(s *Sandbox, namespace, name string, msgs []string, timeout time.Duration) error {
+func WaitConfigMapEvents(s *Sandbox, namespace, name string, msgs []string, timeout time.Duration, opts ...WaitOption) error {
 	cm, err := s.f.Clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	if err != nil {
 		return err
@@ -709,7 +711,8 @@ func WaitConfigMapEvents(s *Sandbox, namespace, name string, msgs []string, time
 	if cm == nil {
 		return fmt.Errorf("Cannot find ConfigMap: %s/%s", namespace, name)
 	}
-	return wait.Poll(5*time.Second, timeout, func() (bool, error) {
+	opts = append([]WaitOption{WithInterval(5 * time.Second), WithTimeout(timeout)}, opts...)
+	return s.f.poll(WaitK8sAPI, func() (bool, error) {
 		eventList, err := s.f.Clientset.CoreV1().Events(namespace).Search(Scheme, cm)
 		if err != nil {
 			return false, err
@@ -733,14 +736,14 @@ func WaitConfigMapEvents(s *Sandbox, namespace, name string, msgs []string, time
 			}
 		}
 		return true, nil
-	})
+	}, opts...)
 }
 
 // waitForBackendConfigCRDEstablish waits for backendconfig CRD to be ensured
 // by the ingress controller.
-func waitForBackendConfigCRDEstablish(crdClient *apiextensionsclient.Clientset) error {
+func (f *Framework) waitForBackendConfigCRDEstablish() error {
 	condition := func() (bool, error) {
-		bcCRD, err := crdClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), backendConfigCRDName, metav1.GetOptions{})
+		bcCRD, err := f.crdClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), backendConfigCRDName, metav1.GetOptions{})
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				klog.V(3).Infof("CRD %s is not found, retrying", backendConfigCRDName)
@@ -756,7 +759,7 @@ func waitForBackendConfigCRDEstablish(crdClient *apiextensionsclient.Clientset)
 		klog.V(3).Infof("CRD %s is not established, retrying", backendConfigCRDName)
 		return false, nil
 	}
-	if err := wait.Poll(backendConfigEnsurePollInterval, backendConfigEnsurePollTimeout, condition); err != nil {
+	if err := f.poll(WaitBackendConfigCRD, condition); err != nil {
 		return fmt.Errorf("error waiting for CRD established: %v", err)
 	}
 	return nil
@@ -764,10 +767,10 @@ func waitForBackendConfigCRDEstablish(crdClient *apiextensionsclient.Clientset)
 
 // WaitForNegCRs waits up to the gclbDeletionTimeout for  neg crs that have the configurations in expectedNegs, and are owned by the given service name,
 // otherwise returns an error. The parameter expectedNegs maps a port to an expected neg name or an empty string for a generated name.
-func WaitForNegCRs(s *Sandbox, serviceName string, expectedNegs map[string]string) (annotations.NegStatus, error) {
+func WaitForNegCRs(s *Sandbox, serviceName string, expectedNegs map[string]string, opts ...WaitOption) (annotations.NegStatus, error) {
 	var svc *v1.Service
 
-	err := wait.Poll(negPollInterval, negPollTimeout, func() (bool, error) {
+	err := s.f.poll(WaitNEG, func() (bool, error) {
 		var err error
 		svc, err = s.f.Clientset.CoreV1().Services(s.Namespace).Get(context.TODO(), serviceName, metav1.GetOptions{})
 		if svc == nil || err != nil {
@@ -786,7 +789,7 @@ func WaitForNegCRs(s *Sandbox, serviceName string, expectedNegs map[string]strin
 		}
 
 		return true, nil
-	})
+	}, opts...)
 
 	if err != nil {
 		return annotations.NegStatus{}, err
@@ -834,9 +837,9 @@ func CheckNegCRs(svc *v1.Service, svcNegs *negv1beta1.ServiceNetworkEndpointGrou
 }
 
 // WaitForStandaloneNegDeletion waits for standalone NEGs and corresponding CR are deleted via GC.
-func WaitForStandaloneNegDeletion(ctx context.Context, c cloud.Cloud, s *Sandbox, port string, negStatus annotations.NegStatus) error {
+func WaitForStandaloneNegDeletion(ctx context.Context, c cloud.Cloud, s *Sandbox, port string, negStatus annotations.NegStatus, opts ...WaitOption) error {
 	negName := negStatus.NetworkEndpointGroups[port]
-	return wait.Poll(negPollInterval, negGCPollTimeout, func() (bool, error) {
+	return s.f.poll(WaitNEGGC, func() (bool, error) {
 		if crDeleted, err := CheckDeletedNegCRs(s, negName, port); !crDeleted {
 			return false, err
 		}
@@ -848,7 +851,7 @@ func WaitForStandaloneNegDeletion(ctx context.Context, c cloud.Cloud, s *Sandbox
 			return false, nil
 		}
 		return true, nil
-	})
+	}, append([]WaitOption{WithContext(ctx)}, opts...)...)
 }
 
 // CheckDeletedNegCRs verifies that the provided neg list does not have negs that are associated with the provided neg atrributes
@@ -902,13 +905,14 @@ func CheckNegFinalizer(svcNeg negv1beta1.ServiceNetworkEndpointGroup) error {
 
 // WaitForSvcNegErrorEvents waits for at least one of the possibles messages to be emitted on the
 // namespace:svcName serice until timeout
-func WaitForSvcNegErrorEvents(s *Sandbox, svcName string, possibleMessages []string) error {
+func WaitForSvcNegErrorEvents(s *Sandbox, svcName string, possibleMessages []string, opts ...WaitOption) error {
 	svc, err := s.f.Clientset.CoreV1().Services(s.Namespace).Get(context.TODO(), svcName, metav1.GetOptions{})
 	if svc == nil || err != nil {
 		return fmt.Errorf("failed to get service %s/%s: %v", s.Namespace, svcName, err)
 	}
 
-	return wait.Poll(5*time.Second, negPollTimeout, func() (bool, error) {
+	opts = append([]WaitOption{WithInterval(5 * time.Second)}, opts...)
+	return s.f.poll(WaitNEG, func() (bool, error) {
 		eventList, err := s.f.Clientset.CoreV1().Events(s.Namespace).Search(Scheme, svc)
 		if err != nil {
 			return false, err
@@ -934,7 +938,7 @@ func WaitForSvcNegErrorEvents(s *Sandbox, svcName string, possibleMessages []str
 		}
 
 		return true, nil
-	})
+	}, opts...)
 }
 
 // CreateNegCR creates a neg cr with the provided neg name and service port. The neg cr created will not be a valid one
@@ -960,9 +964,9 @@ func DeleteNegCR(s *Sandbox, negName string) error {
 
 // WaitForServiceAttachment waits until the gce service attachment corresponding to the provided CR name is
 // created and properly configured
-func WaitForServiceAttachment(s *Sandbox, saName string) (string, error) {
+func WaitForServiceAttachment(s *Sandbox, saName string, opts ...WaitOption) (string, error) {
 	var gceSAURL string
-	err := wait.Poll(negPollInterval, negPollTimeout, func() (bool, error) {
+	err := s.f.poll(WaitNEG, func() (bool, error) {
 		saCR, err := s.f.SAClient.NetworkingV1alpha1().ServiceAttachments(s.Namespace).Get(context.TODO(), saName, metav1.GetOptions{})
 		if saCR == nil || err != nil {
 			return false, fmt.Errorf("failed to get service attachment %s/%s: %v", s.Namespace, saName, err)
@@ -985,13 +989,13 @@ func WaitForServiceAttachment(s *Sandbox, saName string) (string, error) {
 		}
 		klog.Infof("WaitForServiceAttachment(), found ServiceAttachment %s", gceSAURL)
 		return true, nil
-	})
+	}, opts...)
 	return gceSAURL, err
 }
 
 // WaitForServiceAttachmentDeletion waits until the Service Attachment CR and resource in GCE has been deleted.
-func WaitForServiceAttachmentDeletion(s *Sandbox, saName, gceSAURL string) error {
-	return wait.Poll(negPollInterval, negGCPollTimeout, func() (bool, error) {
+func WaitForServiceAttachmentDeletion(s *Sandbox, saName, gceSAURL string, opts ...WaitOption) error {
+	return s.f.poll(WaitNEGGC, func() (bool, error) {
 		if !CheckServiceAttachmentCRDeletion(s, saName) {
 			return false, nil
 		}
@@ -1004,7 +1008,7 @@ func WaitForServiceAttachmentDeletion(s *Sandbox, saName, gceSAURL string) error
 			return deleted, nil
 		}
 		return true, nil
-	})
+	}, opts...)
 }
 
 // CheckServiceAttachmentCRDeletion verifes that the CR does not exist
@@ -26,6 +26,7 @@ import (
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/ingress-gce/pkg/fuzz"
 )
@@ -43,6 +44,9 @@ type Sandbox struct {
 	destroyed bool
 	//Rand int that is used to generate the Namespace name
 	RandInt int64
+	// adopt is true if the sandbox is kept after the test and adopted,
+	// together with its resources, by the next run.
+	adopt bool
 }
 
 // Create the sandbox.
@@ -53,12 +57,15 @@ func (s *Sandbox) Create() error {
 		},
 	}
 	if _, err := s.f.Clientset.CoreV1().Namespaces().Create(context.TODO(), ns, metav1.CreateOptions{}); err != nil {
-		klog.Errorf("Error creating namespace %q: %v", s.Namespace, err)
-		return err
+		if !s.adopt || !errors.IsAlreadyExists(err) {
+			klog.Errorf("Error creating namespace %q: %v", s.Namespace, err)
+			return err
+		}
+		klog.V(2).Infof("Adopting namespace %q of a previous run", s.Namespace)
 	}
 
 	var err error
-	s.ValidatorEnv, err = fuzz.NewDefaultValidatorEnv(s.f.RestConfig, s.Namespace, s.f.Cloud)
+	s.ValidatorEnv, err = fuzz.NewDefaultValidatorEnv(s.f.RestConfig, s.Namespace, s.f.Cloud, s.f.Region, s.f.Network)
 	if err != nil {
 		klog.Errorf("Error creating validator env for namespace %q: %v", s.Namespace, err)
 		return err
@@ -67,6 +74,12 @@ func (s *Sandbox) Create() error {
 	return nil
 }
 
+// Adopt returns true if the sandbox and its resources are kept after the test
+// to be adopted by the next run. Tests should not delete their resources then.
+func (s *Sandbox) Adopt() bool {
+	return s.adopt
+}
+
 // IstioEnabled returns true if Istio is enabled for target cluster.
 func (s *Sandbox) IstioEnabled() bool {
 	return s.f.DestinationRuleClient != nil
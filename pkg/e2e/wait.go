@@ -0,0 +1,213 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// WaitKind names a group of wait helpers which share the same polling
+// configuration.
+type WaitKind string
+
+const (
+	// WaitIngress is used by WaitForIngress.
+	WaitIngress WaitKind = "Ingress"
+	// WaitIngressUpdate is used by WaitForHTTPResourceAnnotations.
+	WaitIngressUpdate WaitKind = "IngressUpdate"
+	// WaitK8sAPI is used by the helpers which wait for Kubernetes objects.
+	WaitK8sAPI WaitKind = "K8sAPI"
+	// WaitGCLBDeletion is used by the helpers which wait for the deletion of
+	// GCE load balancer resources.
+	WaitGCLBDeletion WaitKind = "GCLBDeletion"
+	// WaitRedirectURLMapDeletion is used by WaitForRedirectURLMapDeletion.
+	WaitRedirectURLMapDeletion WaitKind = "RedirectURLMapDeletion"
+	// WaitNEG is used by the helpers which wait for NEGs, their endpoints and
+	// service attachments.
+	WaitNEG WaitKind = "NEG"
+	// WaitNEGStatus is used by WaitForNegStatus and WaitForNEGDeletion.
+	WaitNEGStatus WaitKind = "NEGStatus"
+	// WaitNEGGC is used by the helpers which wait for the garbage collection
+	// of NEGs and service attachments.
+	WaitNEGGC WaitKind = "NEGGC"
+	// WaitBackendConfigCRD is used to wait for the BackendConfig CRD to be
+	// established.
+	WaitBackendConfigCRD WaitKind = "BackendConfigCRD"
+)
+
+// WaitConfig configures how a wait helper polls for its condition.
+type WaitConfig struct {
+	// Interval is the time before the first check of the condition and
+	// between two checks.
+	Interval time.Duration
+	// Timeout is the time after which the wait fails with
+	// wait.ErrWaitTimeout.
+	Timeout time.Duration
+	// Backoff multiplies the interval after every check which did not meet
+	// the condition. Values up to 1 keep the interval constant.
+	Backoff float64
+	// MaxInterval caps the interval grown by Backoff. Zero leaves it
+	// uncapped.
+	MaxInterval time.Duration
+}
+
+// merge returns c with the non-zero fields of o.
+func (c WaitConfig) merge(o WaitConfig) WaitConfig {
+	if o.Interval != 0 {
+		c.Interval = o.Interval
+	}
+	if o.Timeout != 0 {
+		c.Timeout = o.Timeout
+	}
+	if o.Backoff != 0 {
+		c.Backoff = o.Backoff
+	}
+	if o.MaxInterval != 0 {
+		c.MaxInterval = o.MaxInterval
+	}
+	return c
+}
+
+// defaultWaitConfigs are the polling configurations used unless they are
+// overridden by Options.WaitConfigs or the options of a call.
+var defaultWaitConfigs = map[WaitKind]WaitConfig{
+	WaitIngress:                {Interval: ingressPollInterval, Timeout: ingressPollTimeout},
+	WaitIngressUpdate:          {Interval: updateIngressPollInterval, Timeout: updateIngressPollTimeout},
+	WaitK8sAPI:                 {Interval: k8sApiPoolInterval, Timeout: k8sApiPollTimeout},
+	WaitGCLBDeletion:           {Interval: gclbDeletionInterval, Timeout: gclbDeletionTimeout},
+	WaitRedirectURLMapDeletion: {Interval: gclbDeletionInterval, Timeout: redirectURLMapPollTimeout},
+	WaitNEG:                    {Interval: negPollInterval, Timeout: negPollTimeout},
+	WaitNEGStatus:              {Interval: negPollInterval, Timeout: gclbDeletionTimeout},
+	WaitNEGGC:                  {Interval: negPollInterval, Timeout: negGCPollTimeout},
+	WaitBackendConfigCRD:       {Interval: backendConfigEnsurePollInterval, Timeout: backendConfigEnsurePollTimeout},
+}
+
+// ScaledWaitConfigs returns the default polling configurations with their
+// timeouts multiplied by scale, for clusters or projects in which the load
+// balancers take more or less time to be programmed.
+func ScaledWaitConfigs(scale float64) map[WaitKind]WaitConfig {
+	configs := map[WaitKind]WaitConfig{}
+	for kind, config := range defaultWaitConfigs {
+		config.Timeout = time.Duration(float64(config.Timeout) * scale)
+		configs[kind] = config
+	}
+	return configs
+}
+
+// waitParams are the parameters of a single wait.
+type waitParams struct {
+	WaitConfig
+	ctx context.Context
+}
+
+// WaitOption overrides the polling configuration of a single call of a wait
+// helper.
+type WaitOption func(*waitParams)
+
+// WithInterval sets the polling interval.
+func WithInterval(interval time.Duration) WaitOption {
+	return func(p *waitParams) { p.Interval = interval }
+}
+
+// WithTimeout sets the timeout of the wait.
+func WithTimeout(timeout time.Duration) WaitOption {
+	return func(p *waitParams) { p.Timeout = timeout }
+}
+
+// WithBackoff grows the polling interval by factor after every check, up to
+// maxInterval.
+func WithBackoff(factor float64, maxInterval time.Duration) WaitOption {
+	return func(p *waitParams) {
+		p.Backoff = factor
+		p.MaxInterval = maxInterval
+	}
+}
+
+// WithContext stops the wait with the error of ctx once it is done, in
+// addition to the context of the framework.
+func WithContext(ctx context.Context) WaitOption {
+	return func(p *waitParams) { p.ctx = ctx }
+}
+
+// waitConfig returns the polling configuration of the given kind, including
+// the overrides of the framework.
+func (f *Framework) waitConfig(kind WaitKind) WaitConfig {
+	return defaultWaitConfigs[kind].merge(f.waitConfigs[kind])
+}
+
+// poll checks condition with the polling configuration of the given kind
+// until it is met, it returns an error, the wait times out or either the
+// context of the framework or the one passed with WithContext is done.
+func (f *Framework) poll(kind WaitKind, condition wait.ConditionFunc, opts ...WaitOption) error {
+	params := waitParams{WaitConfig: f.waitConfig(kind), ctx: context.Background()}
+	for _, opt := range opts {
+		opt(&params)
+	}
+	ctx, cancel := mergeContexts(params.ctx, f.ctx)
+	defer cancel()
+	return pollWithContext(ctx, params.WaitConfig, condition)
+}
+
+// mergeContexts returns a context which is done when either a or b is.
+func mergeContexts(a, b context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(a)
+	go func() {
+		select {
+		case <-b.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// pollWithContext checks condition after every interval of config until it
+// is met or returns an error. It returns wait.ErrWaitTimeout once the timeout
+// expires and the error of ctx once it is done.
+func pollWithContext(ctx context.Context, config WaitConfig, condition wait.ConditionFunc) error {
+	deadline := time.NewTimer(config.Timeout)
+	defer deadline.Stop()
+	interval := config.Interval
+	for {
+		tick := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			tick.Stop()
+			return ctx.Err()
+		case <-deadline.C:
+			tick.Stop()
+			return wait.ErrWaitTimeout
+		case <-tick.C:
+		}
+		done, err := condition()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if config.Backoff > 1 {
+			interval = time.Duration(float64(interval) * config.Backoff)
+			if config.MaxInterval > 0 && interval > config.MaxInterval {
+				interval = config.MaxInterval
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestPollWithContext(t *testing.T) {
+	errFatal := errors.New("fatal")
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tc := range []struct {
+		desc      string
+		ctx       context.Context
+		config    WaitConfig
+		doneAfter int
+		condErr   error
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			desc:      "condition met",
+			ctx:       context.Background(),
+			config:    WaitConfig{Interval: time.Millisecond, Timeout: time.Minute},
+			doneAfter: 3,
+			wantCalls: 3,
+		},
+		{
+			desc:      "condition error",
+			ctx:       context.Background(),
+			config:    WaitConfig{Interval: time.Millisecond, Timeout: time.Minute},
+			condErr:   errFatal,
+			wantErr:   errFatal,
+			wantCalls: 1,
+		},
+		{
+			desc:    "timeout",
+			ctx:     context.Background(),
+			config:  WaitConfig{Interval: time.Millisecond, Timeout: 20 * time.Millisecond, Backoff: 2, MaxInterval: 4 * time.Millisecond},
+			wantErr: wait.ErrWaitTimeout,
+		},
+		{
+			desc:    "context done",
+			ctx:     cancelled,
+			config:  WaitConfig{Interval: time.Minute, Timeout: time.Hour},
+			wantErr: context.Canceled,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			calls := 0
+			err := pollWithContext(tc.ctx, tc.config, func() (bool, error) {
+				calls++
+				if tc.condErr != nil {
+					return false, tc.condErr
+				}
+				return tc.doneAfter > 0 && calls >= tc.doneAfter, nil
+			})
+			if err != tc.wantErr {
+				t.Errorf("pollWithContext() = %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantCalls > 0 && calls != tc.wantCalls {
+				t.Errorf("pollWithContext() checked the condition %d times, want %d", calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestFrameworkPoll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &Framework{
+		waitConfigs: map[WaitKind]WaitConfig{WaitNEG: {Interval: time.Millisecond}},
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	if got := f.waitConfig(WaitNEG); got.Interval != time.Millisecond || got.Timeout != negPollTimeout {
+		t.Errorf("waitConfig(%q) = %+v, want the interval of the framework and the default timeout", WaitNEG, got)
+	}
+
+	err := f.poll(WaitNEG, func() (bool, error) { return false, nil }, WithTimeout(10*time.Millisecond))
+	if err != wait.ErrWaitTimeout {
+		t.Errorf("poll() = %v, want %v", err, wait.ErrWaitTimeout)
+	}
+
+	f.Abort(errors.New("broken cluster"))
+	err = f.poll(WaitNEG, func() (bool, error) { return false, nil })
+	if err != context.Canceled {
+		t.Errorf("poll() after Abort() = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package eventlog mirrors the Events recorded by the controllers into Cloud
+// Logging as structured entries.
+package eventlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// Scope is the OAuth scope required to write entries.
+	Scope = "https://www.googleapis.com/auth/logging.write"
+
+	defaultEndpoint = "https://logging.googleapis.com/v2/entries:write"
+	// queueSize is the number of events buffered while they are written.
+	// Events are dropped when the buffer is full.
+	queueSize = 1000
+	// maxBatchSize is the maximum number of entries written per request.
+	maxBatchSize = 100
+	// flushPeriod is the maximum time an event is buffered before it is
+	// written.
+	flushPeriod = 5 * time.Second
+)
+
+// Sink writes Events as structured entries to a Cloud Logging log. Entries
+// are written in batches in the background by Run.
+type Sink struct {
+	client      *http.Client
+	endpoint    string
+	projectID   string
+	logName     string
+	location    string
+	clusterName string
+
+	queue chan *apiv1.Event
+}
+
+// NewSink returns a Sink which writes to the log logID of the given project
+// with the authenticated client. Entries are attributed to the cluster with
+// the given name and location.
+func NewSink(client *http.Client, projectID, logID, location, clusterName string) *Sink {
+	return &Sink{
+		client:      client,
+		endpoint:    defaultEndpoint,
+		projectID:   projectID,
+		logName:     fmt.Sprintf("projects/%s/logs/%s", projectID, url.PathEscape(logID)),
+		location:    location,
+		clusterName: clusterName,
+		queue:       make(chan *apiv1.Event, queueSize),
+	}
+}
+
+// Record queues the event to be written. It never blocks, the event is
+// dropped if the queue is full. It can be passed to
+// EventBroadcaster.StartEventWatcher.
+func (s *Sink) Record(event *apiv1.Event) {
+	select {
+	case s.queue <- event:
+	default:
+		klog.Warningf("Event log queue is full, dropping event %s/%s", event.Namespace, event.Name)
+	}
+}
+
+// Run writes the queued events until stopCh is closed.
+func (s *Sink) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(flushPeriod)
+	defer ticker.Stop()
+	var batch []*apiv1.Event
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.write(batch); err != nil {
+			klog.Errorf("Failed to write %d events to log %s: %v", len(batch), s.logName, err)
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case event := <-s.queue:
+			batch = append(batch, event)
+			if len(batch) >= maxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-stopCh:
+			flush()
+			return
+		}
+	}
+}
+
+// logEntry is a LogEntry of the Cloud Logging API.
+type logEntry struct {
+	Severity    string            `json:"severity"`
+	Timestamp   string            `json:"timestamp,omitempty"`
+	Labels      map[string]string `json:"labels"`
+	JSONPayload *eventPayload     `json:"jsonPayload"`
+}
+
+// eventPayload is the structured payload of the entry of an event.
+type eventPayload struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Count   int32  `json:"count,omitempty"`
+	// Object is the kind, namespace and name of the object of the event.
+	Object apiv1.ObjectReference `json:"involvedObject"`
+	Source string                `json:"source,omitempty"`
+}
+
+type monitoredResource struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels"`
+}
+
+type writeRequest struct {
+	LogName  string            `json:"logName"`
+	Resource monitoredResource `json:"resource"`
+	Entries  []*logEntry       `json:"entries"`
+}
+
+// write writes the events in a single request.
+func (s *Sink) write(events []*apiv1.Event) error {
+	req := writeRequest{
+		LogName: s.logName,
+		Resource: monitoredResource{
+			Type: "k8s_cluster",
+			Labels: map[string]string{
+				"project_id":   s.projectID,
+				"location":     s.location,
+				"cluster_name": s.clusterName,
+			},
+		},
+	}
+	for _, event := range events {
+		req.Entries = append(req.Entries, s.toEntry(event))
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, msg)
+	}
+	return nil
+}
+
+// toEntry returns the log entry of the event. The entry is labeled with the
+// cluster and the object of the event, e.g. the Ingress.
+func (s *Sink) toEntry(event *apiv1.Event) *logEntry {
+	severity := "INFO"
+	if event.Type == apiv1.EventTypeWarning {
+		severity = "WARNING"
+	}
+	timestamp := event.LastTimestamp.Time
+	if timestamp.IsZero() {
+		timestamp = event.EventTime.Time
+	}
+	entry := &logEntry{
+		Severity: severity,
+		Labels: map[string]string{
+			"cluster_name": s.clusterName,
+			"namespace":    event.InvolvedObject.Namespace,
+			"kind":         event.InvolvedObject.Kind,
+			"name":         event.InvolvedObject.Name,
+		},
+		JSONPayload: &eventPayload{
+			Reason:  event.Reason,
+			Message: event.Message,
+			Type:    event.Type,
+			Count:   event.Count,
+			Object:  event.InvolvedObject,
+			Source:  event.Source.Component,
+		},
+	}
+	if !timestamp.IsZero() {
+		entry.Timestamp = timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	switch event.InvolvedObject.Kind {
+	case "Ingress":
+		entry.Labels["ingress"] = event.InvolvedObject.Namespace + "/" + event.InvolvedObject.Name
+	case "Service":
+		entry.Labels["service"] = event.InvolvedObject.Namespace + "/" + event.InvolvedObject.Name
+	}
+	return entry
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventlog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSinkRun(t *testing.T) {
+	requests := make(chan writeRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req writeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Decode() = %v", err)
+		}
+		requests <- req
+	}))
+	defer server.Close()
+
+	sink := NewSink(server.Client(), "test-project", "ingress-events", "us-central1", "test-cluster")
+	sink.endpoint = server.URL
+	sink.Record(&apiv1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: "ing.1"},
+		InvolvedObject: apiv1.ObjectReference{Kind: "Ingress", Namespace: "default", Name: "ing"},
+		Reason:         "Sync",
+		Message:        "Scheduled for sync",
+		Type:           apiv1.EventTypeWarning,
+		LastTimestamp:  metav1.NewTime(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		sink.Run(stopCh)
+		close(done)
+	}()
+	// Wait for the event to be dequeued, so that it is flushed on stop.
+	for len(sink.queue) > 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(stopCh)
+	<-done
+
+	var req writeRequest
+	select {
+	case req = <-requests:
+	default:
+		t.Fatalf("Run() did not write the event")
+	}
+	if want := "projects/test-project/logs/ingress-events"; req.LogName != want {
+		t.Errorf("logName = %q, want %q", req.LogName, want)
+	}
+	if got := req.Resource.Labels["cluster_name"]; got != "test-cluster" {
+		t.Errorf("resource cluster_name = %q, want %q", got, "test-cluster")
+	}
+	if len(req.Entries) != 1 {
+		t.Fatalf("len(entries) = %d, want 1", len(req.Entries))
+	}
+	entry := req.Entries[0]
+	if entry.Severity != "WARNING" {
+		t.Errorf("severity = %q, want WARNING", entry.Severity)
+	}
+	if want := "2021-01-02T03:04:05Z"; entry.Timestamp != want {
+		t.Errorf("timestamp = %q, want %q", entry.Timestamp, want)
+	}
+	if got := entry.Labels["ingress"]; got != "default/ing" {
+		t.Errorf("ingress label = %q, want %q", got, "default/ing")
+	}
+	if entry.JSONPayload == nil || entry.JSONPayload.Reason != "Sync" {
+		t.Errorf("jsonPayload = %+v, want reason Sync", entry.JSONPayload)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+)
+
+// AggregatingRecorder limits the events recorded for every object, event type
+// and reason to two per flush: the first event is recorded right away, and the
+// events repeated until the next flush are recorded as a single event with
+// their count and the time of the first and the last one. This prevents
+// flooding the API server with events when many objects fail to sync
+// repeatedly, e.g. during a GCE outage. Events with annotations are not
+// aggregated.
+type AggregatingRecorder struct {
+	record.EventRecorder
+	clock clock.Clock
+
+	lock    sync.Mutex
+	pending map[aggregateKey]*aggregate
+}
+
+// aggregateKey identifies the events which are aggregated.
+type aggregateKey struct {
+	object    string
+	eventtype string
+	reason    string
+}
+
+// aggregate holds the events repeated since the first one.
+type aggregate struct {
+	object runtime.Object
+	// message is the message of the last repeated event.
+	message string
+	// count is the number of repeated events, the first of which was
+	// recorded at first and the last at last.
+	count       int
+	first, last time.Time
+}
+
+// NewAggregatingRecorder returns a recorder which aggregates the events
+// recorded to r. Flush must be called periodically.
+func NewAggregatingRecorder(r record.EventRecorder) *AggregatingRecorder {
+	return &AggregatingRecorder{
+		EventRecorder: r,
+		clock:         clock.RealClock{},
+		pending:       map[aggregateKey]*aggregate{},
+	}
+}
+
+// Event implements record.EventRecorder.
+func (r *AggregatingRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	key := aggregateKey{object: objectKey(object), eventtype: eventtype, reason: reason}
+	now := r.clock.Now()
+
+	r.lock.Lock()
+	a, ok := r.pending[key]
+	if !ok {
+		r.pending[key] = &aggregate{object: object}
+	} else {
+		if a.count == 0 {
+			a.first = now
+		}
+		a.object, a.message, a.last = object, message, now
+		a.count++
+	}
+	r.lock.Unlock()
+
+	if !ok {
+		r.EventRecorder.Event(object, eventtype, reason, message)
+	}
+}
+
+// Eventf implements record.EventRecorder.
+func (r *AggregatingRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// Flush records the aggregated events and starts a new aggregation period.
+func (r *AggregatingRecorder) Flush() {
+	r.lock.Lock()
+	pending := r.pending
+	r.pending = map[aggregateKey]*aggregate{}
+	r.lock.Unlock()
+
+	for key, a := range pending {
+		if a.count == 0 {
+			continue
+		}
+		r.EventRecorder.Eventf(a.object, key.eventtype, key.reason, "%s (repeated %d times between %s and %s)",
+			a.message, a.count, a.first.Format(time.RFC3339), a.last.Format(time.RFC3339))
+	}
+}
+
+// objectKey returns a key identifying the object of an event.
+func objectKey(object runtime.Object) string {
+	if ref, ok := object.(*v1.ObjectReference); ok {
+		return fmt.Sprintf("%s/%s/%s/%s", ref.Kind, ref.Namespace, ref.Name, ref.UID)
+	}
+	if m, err := meta.Accessor(object); err == nil {
+		return fmt.Sprintf("%T/%s/%s/%s", object, m.GetNamespace(), m.GetName(), m.GetUID())
+	}
+	return fmt.Sprintf("%T/%p", object, object)
+}
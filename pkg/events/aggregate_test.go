@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestAggregatingRecorder(t *testing.T) {
+	fakeRecorder := record.NewFakeRecorder(10)
+	start := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(start)
+	r := NewAggregatingRecorder(fakeRecorder)
+	r.clock = fakeClock
+
+	svc1 := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "svc1", UID: "uid1"}}
+	svc2 := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "svc2", UID: "uid2"}}
+
+	// The first event of every object and reason is recorded right away.
+	r.Eventf(svc1, v1.EventTypeWarning, "SyncLoadBalancerFailed", "error %d", 1)
+	r.Eventf(svc2, v1.EventTypeWarning, "SyncLoadBalancerFailed", "error %d", 1)
+	r.Event(svc1, v1.EventTypeNormal, "SyncLoadBalancerSuccessful", "synced")
+	// The repeated ones are aggregated.
+	fakeClock.Step(time.Minute)
+	r.Eventf(svc1, v1.EventTypeWarning, "SyncLoadBalancerFailed", "error %d", 2)
+	fakeClock.Step(time.Minute)
+	r.Eventf(svc1, v1.EventTypeWarning, "SyncLoadBalancerFailed", "error %d", 3)
+	assertEvents(t, fakeRecorder, []string{
+		"Warning SyncLoadBalancerFailed error 1",
+		"Warning SyncLoadBalancerFailed error 1",
+		"Normal SyncLoadBalancerSuccessful synced",
+	})
+
+	r.Flush()
+	assertEvents(t, fakeRecorder, []string{
+		"Warning SyncLoadBalancerFailed error 3 (repeated 2 times between 2021-06-01T10:01:00Z and 2021-06-01T10:02:00Z)",
+	})
+
+	// A flush starts a new aggregation period.
+	r.Eventf(svc1, v1.EventTypeWarning, "SyncLoadBalancerFailed", "error %d", 4)
+	assertEvents(t, fakeRecorder, []string{"Warning SyncLoadBalancerFailed error 4"})
+	r.Flush()
+	assertEvents(t, fakeRecorder, nil)
+}
+
+// assertEvents checks that the events recorded to fakeRecorder are want, in
+// order.
+func assertEvents(t *testing.T, fakeRecorder *record.FakeRecorder, want []string) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-fakeRecorder.Events:
+			if got != w {
+				t.Errorf("Recorded event %q, want %q", got, w)
+			}
+		default:
+			t.Errorf("No event recorded, want %q", w)
+		}
+	}
+	select {
+	case got := <-fakeRecorder.Events:
+		t.Errorf("Recorded unexpected event %q", got)
+	default:
+	}
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package events
 
 import (
+	"fmt"
+
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 )
 
@@ -29,6 +32,22 @@ const (
 	TranslateIngress  = "Translate"
 	IPChanged         = "IPChanged"
 	GarbageCollection = "GarbageCollection"
+	QuotaExceeded     = "QuotaExceeded"
+	// LimitExceeded is used when the GCE resources of an Ingress would
+	// exceed a GCE limit.
+	LimitExceeded = "LimitExceeded"
+	// SyncDeadlineExceeded is used when a sync was aborted and requeued
+	// after exceeding the sync deadline.
+	SyncDeadlineExceeded = "SyncDeadlineExceeded"
+	// WarmedUp is used when the load balancer of an Ingress serves its
+	// hosts and paths and the Ingress is marked Ready.
+	WarmedUp = "WarmedUp"
+	// HealthCheckConfigured is used when the health check of a service port
+	// is created or updated. The message lists the source of every setting.
+	HealthCheckConfigured = "HealthCheckConfigured"
+	// DefaultConfigMissing is used when the default BackendConfig or
+	// FrontendConfig does not exist and no config is applied instead.
+	DefaultConfigMissing = "DefaultConfigMissing"
 
 	SyncService = "Sync"
 )
@@ -44,6 +63,37 @@ func (r RecorderProducerMock) Recorder(ns string) record.EventRecorder {
 	return &record.FakeRecorder{}
 }
 
+// WithSyncID returns a recorder which appends the ID of the controller sync to
+// the message of the events it records. The recorder is returned unchanged if
+// the ID is empty.
+func WithSyncID(r record.EventRecorder, syncID string) record.EventRecorder {
+	if syncID == "" {
+		return r
+	}
+	return &syncIDRecorder{EventRecorder: r, syncID: syncID}
+}
+
+type syncIDRecorder struct {
+	record.EventRecorder
+	syncID string
+}
+
+func (r *syncIDRecorder) suffix(message string) string {
+	return fmt.Sprintf("%s (sync %s)", message, r.syncID)
+}
+
+func (r *syncIDRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.EventRecorder.Event(object, eventtype, reason, r.suffix(message))
+}
+
+func (r *syncIDRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.EventRecorder.Event(object, eventtype, reason, r.suffix(fmt.Sprintf(messageFmt, args...)))
+}
+
+func (r *syncIDRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, "%s", r.suffix(fmt.Sprintf(messageFmt, args...)))
+}
+
 // GloablEventf records a Cluster level event not attached to a given object.
 func GlobalEventf(r record.EventRecorder, eventtype, reason, messageFmt string, args ...interface{}) {
 	// Using an empty ObjectReference to indicate no associated
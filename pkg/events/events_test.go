@@ -3,6 +3,9 @@ package events
 import (
 	"fmt"
 	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 )
 
 func TestTruncatedStringList(t *testing.T) {
@@ -32,3 +35,24 @@ func TestTruncatedStringList(t *testing.T) {
 		})
 	}
 }
+
+func TestWithSyncID(t *testing.T) {
+	fakeRecorder := record.NewFakeRecorder(3)
+	if got := WithSyncID(fakeRecorder, ""); got != fakeRecorder {
+		t.Errorf("WithSyncID(r, \"\") = %v, want r", got)
+	}
+
+	r := WithSyncID(fakeRecorder, "abc123")
+	r.Event(&v1.Pod{}, v1.EventTypeNormal, SyncIngress, "created")
+	r.Eventf(&v1.Pod{}, v1.EventTypeNormal, SyncIngress, "UrlMap %q created", "um")
+	r.AnnotatedEventf(&v1.Pod{}, nil, v1.EventTypeWarning, SyncIngress, "error: %v", "quota")
+	for _, want := range []string{
+		"Normal Sync created (sync abc123)",
+		`Normal Sync UrlMap "um" created (sync abc123)`,
+		"Warning Sync error: quota (sync abc123)",
+	} {
+		if got := <-fakeRecorder.Events; got != want {
+			t.Errorf("Recorded event %q, want %q", got, want)
+		}
+	}
+}
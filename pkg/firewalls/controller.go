@@ -46,6 +46,11 @@ var (
 	}
 
 	ErrNoILBIngress = errors.New("no ILB Ingress found")
+
+	// l7IPv6SrcRanges are the IPv6 ranges of the Google Front Ends and health
+	// checkers. They are allowed while IPv6 Ingresses exist, so that dual-stack
+	// nodes can be reached over IPv6.
+	l7IPv6SrcRanges = []string{"2600:2d00:1:b029::/64", "2600:2d00:1:1::/64"}
 )
 
 // FirewallController synchronizes the firewall rule for all ingresses.
@@ -62,12 +67,17 @@ type FirewallController struct {
 func NewFirewallController(
 	ctx *context.ControllerContext,
 	portRanges []string) *FirewallController {
-	firewallPool := NewFirewallPool(ctx.Cloud, ctx.ClusterNamer, gce.L7LoadBalancerSrcRanges(), portRanges)
+	tr := translator.NewTranslator(ctx)
+	cloud := newGCEFirewall(ctx.Cloud)
+	if flags.F.NodeTagsRefreshPeriod > 0 {
+		cloud = newRefreshingNodeTags(cloud, ctx.Cloud, tr.GetZoneForNode, flags.F.NodeTagsRefreshPeriod)
+	}
+	firewallPool := NewFirewallPool(cloud, ctx.ClusterNamer, gce.L7LoadBalancerSrcRanges(), portRanges)
 
 	fwc := &FirewallController{
 		ctx:          ctx,
 		firewallPool: firewallPool,
-		translator:   translator.NewTranslator(ctx),
+		translator:   tr,
 		nodeLister:   ctx.NodeInformer.GetIndexer(),
 		hasSynced:    ctx.HasSynced,
 	}
@@ -190,6 +200,10 @@ func (fwc *FirewallController) sync(key string) error {
 		additionalRanges = append(additionalRanges, ilbRange)
 	}
 
+	if hasIPv6Ingress(gceIngresses) {
+		additionalRanges = append(additionalRanges, l7IPv6SrcRanges...)
+	}
+
 	var additionalPorts []string
 	if flags.F.EnableBackendConfigHealthCheck {
 		hcPorts := fwc.getCustomHealthCheckPorts(gceSvcPorts)
@@ -211,6 +225,12 @@ func (fwc *FirewallController) sync(key string) error {
 			return err
 		}
 	}
+	// Raise an event on each ingress for the reverted changes.
+	for _, conflict := range fwc.firewallPool.Conflicts() {
+		for _, ing := range gceIngresses {
+			fwc.ctx.Recorder(ing.Namespace).Eventf(ing, apiv1.EventTypeWarning, "FirewallConflict", conflict)
+		}
+	}
 	return nil
 }
 
@@ -234,6 +254,17 @@ func (fwc *FirewallController) ilbFirewallSrcRange(gceIngresses []*v1.Ingress) (
 	return "", ErrNoILBIngress
 }
 
+// hasIPv6Ingress returns true if any of the given Ingresses is exposed on
+// IPv6.
+func hasIPv6Ingress(gceIngresses []*v1.Ingress) bool {
+	for _, ing := range gceIngresses {
+		if version, err := annotations.FromIngress(ing).IPVersion(); err == nil && version == annotations.IPv6Version {
+			return true
+		}
+	}
+	return false
+}
+
 func (fwc *FirewallController) getCustomHealthCheckPorts(svcPorts []utils.ServicePort) []string {
 	var result []string
 
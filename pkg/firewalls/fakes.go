@@ -114,6 +114,23 @@ func (ff *fakeFirewallsProvider) UpdateFirewall(f *compute.Firewall) error {
 	return ff.doUpdateFirewall(f)
 }
 
+func (ff *fakeFirewallsProvider) PatchFirewall(f *compute.Firewall) error {
+	if ff.fwReadOnly {
+		return utils.FakeGoogleAPIForbiddenErr()
+	}
+
+	existing, exists := ff.fw[f.Name]
+	if !exists {
+		return utils.FakeGoogleAPINotFoundErr()
+	}
+	cf, err := copyFirewall(applyFirewallPatch(existing, f))
+	if err != nil {
+		return err
+	}
+	ff.fw[f.Name] = cf
+	return nil
+}
+
 func (ff *fakeFirewallsProvider) NetworkProjectID() string {
 	return ff.networkProjectID
 }
@@ -20,9 +20,11 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 
 	"google.golang.org/api/compute/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/ingress-gce/pkg/flags"
 	"k8s.io/ingress-gce/pkg/utils"
 	namer_util "k8s.io/ingress-gce/pkg/utils/namer"
 	"k8s.io/klog"
@@ -44,6 +46,14 @@ type FirewallRules struct {
 	// TODO(rramkumar): Eliminate this variable. We should just pass in
 	// all the port ranges to open with each call to Sync()
 	nodePortRanges []string
+
+	lock sync.Mutex
+	// applied are the firewall rules as last applied by the controller,
+	// keyed by name. They detect changes made outside of the controller.
+	applied map[string]*compute.Firewall
+	// conflicts are the changes made outside of the controller which were
+	// reverted by the last Sync.
+	conflicts []string
 }
 
 // NewFirewallPool creates a new firewall rule manager.
@@ -59,14 +69,15 @@ func NewFirewallPool(cloud Firewall, namer *namer_util.Namer, l7SrcRanges []stri
 		namer:          namer,
 		srcRanges:      l7SrcRanges,
 		nodePortRanges: nodePortRanges,
+		applied:        map[string]*compute.Firewall{},
 	}
 }
 
 // Sync firewall rules with the cloud.
+// GCE firewall rules can not mix IPv4 and IPv6 source ranges, so IPv6 ranges
+// are allowed by a separate rule which only exists while IPv6 ranges do.
 func (fr *FirewallRules) Sync(nodeNames, additionalPorts, additionalRanges []string, allowNodePort bool) error {
 	klog.V(4).Infof("Sync(%v)", nodeNames)
-	name := fr.namer.FirewallRule()
-	existingFirewall, _ := fr.cloud.GetFirewall(name)
 
 	// Retrieve list of target tags from node names. This may be configured in
 	// gce.conf or computed by the GCE cloudprovider package.
@@ -86,16 +97,69 @@ func (fr *FirewallRules) Sync(nodeNames, additionalPorts, additionalRanges []str
 	// De-dupe srcRanges
 	ranges := sets.NewString(fr.srcRanges...)
 	ranges.Insert(additionalRanges...)
+	ipv4Ranges, ipv6Ranges := splitRangesByFamily(ranges.List())
+
+	var conflicts []string
+	defer func() {
+		fr.lock.Lock()
+		defer fr.lock.Unlock()
+		fr.conflicts = conflicts
+	}()
+	conflict, err := fr.syncFirewall(fr.namer.FirewallRule(), "GCE L7 firewall rule", ipv4Ranges, ports.List(), targetTags)
+	if err != nil {
+		return err
+	}
+	conflicts = appendConflict(conflicts, conflict)
+
+	ipv6Name := ipv6FirewallRule(fr.namer)
+	if len(ipv6Ranges) == 0 {
+		if existing, _ := fr.cloud.GetFirewall(ipv6Name); existing != nil {
+			klog.V(3).Infof("Deleting unused firewall rule %q", ipv6Name)
+			if err := fr.deleteFirewall(ipv6Name); err != nil {
+				return err
+			}
+		}
+	} else {
+		conflict, err = fr.syncFirewall(ipv6Name, "GCE L7 firewall rule for IPv6 ranges", ipv6Ranges, ports.List(), targetTags)
+		if err != nil {
+			return err
+		}
+		conflicts = appendConflict(conflicts, conflict)
+	}
+	return nil
+}
+
+// Conflicts returns the changes made outside of the controller which were
+// reverted by the last Sync.
+func (fr *FirewallRules) Conflicts() []string {
+	fr.lock.Lock()
+	defer fr.lock.Unlock()
+	return fr.conflicts
+}
+
+// appendConflict appends conflict to conflicts unless it is empty.
+func appendConflict(conflicts []string, conflict string) []string {
+	if conflict == "" {
+		return conflicts
+	}
+	return append(conflicts, conflict)
+}
+
+// syncFirewall creates or updates the firewall rule with the given name. It
+// returns a description of the changes made outside of the controller which
+// were reverted, if any.
+func (fr *FirewallRules) syncFirewall(name, description string, ranges, ports, targetTags []string) (string, error) {
+	existingFirewall, _ := fr.cloud.GetFirewall(name)
 
 	expectedFirewall := &compute.Firewall{
 		Name:         name,
-		Description:  "GCE L7 firewall rule",
-		SourceRanges: ranges.UnsortedList(),
+		Description:  description,
+		SourceRanges: ranges,
 		Network:      fr.cloud.NetworkURL(),
 		Allowed: []*compute.FirewallAllowed{
 			{
 				IPProtocol: "tcp",
-				Ports:      ports.List(),
+				Ports:      ports,
 			},
 		},
 		TargetTags: targetTags,
@@ -103,24 +167,87 @@ func (fr *FirewallRules) Sync(nodeNames, additionalPorts, additionalRanges []str
 
 	if existingFirewall == nil {
 		klog.V(3).Infof("Creating firewall rule %q", name)
-		return fr.createFirewall(expectedFirewall)
+		if err := fr.createFirewall(expectedFirewall); err != nil {
+			return "", err
+		}
+		fr.setApplied(expectedFirewall)
+		return "", nil
 	}
 
+	conflict := fr.conflict(existingFirewall)
+	patch, changed := firewallPatch(expectedFirewall, existingFirewall)
 	// Early return if an update is not required.
-	if equal(expectedFirewall, existingFirewall) {
+	if patch == nil {
 		klog.V(4).Info("Firewall does not need update of ports or source ranges")
-		return nil
+		fr.setApplied(expectedFirewall)
+		return "", nil
+	}
+
+	// Only the changed fields are sent, so that concurrent changes to the
+	// other fields are not reverted.
+	klog.V(3).Infof("Patching %v of firewall rule %q", changed, name)
+	if err := fr.patchFirewall(patch, expectedFirewall); err != nil {
+		return "", err
+	}
+	fr.setApplied(expectedFirewall)
+	return conflict, nil
+}
+
+// conflict returns a description of the changes made outside of the
+// controller to the fields it owns of the firewall rule existing, or "" if
+// there are none. These changes are reverted.
+func (fr *FirewallRules) conflict(existing *compute.Firewall) string {
+	fr.lock.Lock()
+	applied, ok := fr.applied[existing.Name]
+	fr.lock.Unlock()
+	if !ok {
+		return ""
+	}
+	changed := changedFields(applied, existing)
+	if len(changed) == 0 {
+		return ""
 	}
+	klog.Warningf("Firewall rule %q was modified outside of the controller, reverting %v", existing.Name, changed)
+	return fmt.Sprintf("firewall rule %q was modified outside of the controller, reverted %v: the rule is owned by the controller, use a separate firewall rule instead", existing.Name, changed)
+}
 
-	klog.V(3).Infof("Updating firewall rule %q", name)
-	return fr.updateFirewall(expectedFirewall)
+// setApplied records f as the firewall rule last applied by the controller.
+func (fr *FirewallRules) setApplied(f *compute.Firewall) {
+	fr.lock.Lock()
+	defer fr.lock.Unlock()
+	fr.applied[f.Name] = f
 }
 
-// GC deletes the firewall rule.
+// GC deletes the firewall rules.
 func (fr *FirewallRules) GC() error {
 	name := fr.namer.FirewallRule()
 	klog.V(3).Infof("Deleting firewall %q", name)
-	return fr.deleteFirewall(name)
+	if err := fr.deleteFirewall(name); err != nil {
+		return err
+	}
+	ipv6Name := ipv6FirewallRule(fr.namer)
+	if existing, _ := fr.cloud.GetFirewall(ipv6Name); existing != nil {
+		klog.V(3).Infof("Deleting firewall %q", ipv6Name)
+		return fr.deleteFirewall(ipv6Name)
+	}
+	return nil
+}
+
+// ipv6FirewallRule returns the name of the firewall rule for IPv6 ranges.
+func ipv6FirewallRule(namer *namer_util.Namer) string {
+	return namer.FirewallRule() + "-ipv6"
+}
+
+// splitRangesByFamily splits the given CIDRs into IPv4 and IPv6 ranges.
+func splitRangesByFamily(ranges []string) (ipv4Ranges, ipv6Ranges []string) {
+	for _, r := range ranges {
+		if netset.IsIPv6CIDRString(r) {
+			ipv6Ranges = append(ipv6Ranges, r)
+		} else {
+			ipv4Ranges = append(ipv4Ranges, r)
+		}
+	}
+	return ipv4Ranges, ipv6Ranges
 }
 
 // GetFirewall just returns the firewall object corresponding to the given name.
@@ -132,7 +259,7 @@ func (fr *FirewallRules) GetFirewall(name string) (*compute.Firewall, error) {
 
 func (fr *FirewallRules) createFirewall(f *compute.Firewall) error {
 	err := fr.cloud.CreateFirewall(f)
-	if utils.IsForbiddenError(err) && fr.cloud.OnXPN() {
+	if utils.IsForbiddenError(err) && raiseForbiddenChange(fr.cloud) {
 		gcloudCmd := gce.FirewallToGCloudCreateCmd(f, fr.cloud.NetworkProjectID())
 		klog.V(3).Infof("Could not create L7 firewall on XPN cluster: %v. Raising event for cmd: %q", err, gcloudCmd)
 		return newFirewallXPNError(err, gcloudCmd)
@@ -140,9 +267,11 @@ func (fr *FirewallRules) createFirewall(f *compute.Firewall) error {
 	return err
 }
 
-func (fr *FirewallRules) updateFirewall(f *compute.Firewall) error {
-	err := fr.cloud.UpdateFirewall(f)
-	if utils.IsForbiddenError(err) && fr.cloud.OnXPN() {
+// patchFirewall applies patch, which carries the changes needed for the
+// firewall rule f.
+func (fr *FirewallRules) patchFirewall(patch, f *compute.Firewall) error {
+	err := fr.cloud.PatchFirewall(patch)
+	if utils.IsForbiddenError(err) && raiseForbiddenChange(fr.cloud) {
 		gcloudCmd := gce.FirewallToGCloudUpdateCmd(f, fr.cloud.NetworkProjectID())
 		klog.V(3).Infof("Could not update L7 firewall on XPN cluster: %v. Raising event for cmd: %q", err, gcloudCmd)
 		return newFirewallXPNError(err, gcloudCmd)
@@ -151,11 +280,15 @@ func (fr *FirewallRules) updateFirewall(f *compute.Firewall) error {
 }
 
 func (fr *FirewallRules) deleteFirewall(name string) error {
+	fr.lock.Lock()
+	delete(fr.applied, name)
+	fr.lock.Unlock()
+
 	err := fr.cloud.DeleteFirewall(name)
 	if utils.IsNotFoundError(err) {
 		klog.Infof("Firewall with name %v didn't exist when attempting delete.", name)
 		return nil
-	} else if utils.IsForbiddenError(err) && fr.cloud.OnXPN() {
+	} else if utils.IsForbiddenError(err) && raiseForbiddenChange(fr.cloud) {
 		gcloudCmd := gce.FirewallToGCloudDeleteCmd(name, fr.cloud.NetworkProjectID())
 		klog.V(3).Infof("Could not attempt delete of L7 firewall on XPN cluster: %v. %q needs to be ran.", err, gcloudCmd)
 		return newFirewallXPNError(err, gcloudCmd)
@@ -163,6 +296,13 @@ func (fr *FirewallRules) deleteFirewall(name string) error {
 	return err
 }
 
+// raiseForbiddenChange returns true if a firewall change which the controller
+// is forbidden to make is raised as an event with the gcloud command making
+// it. This is the case on XPN clusters and with restricted node access.
+func raiseForbiddenChange(cloud interface{ OnXPN() bool }) bool {
+	return cloud.OnXPN() || flags.F.EnableRestrictedNodeAccess
+}
+
 func newFirewallXPNError(internal error, cmd string) *FirewallXPNError {
 	return &FirewallXPNError{
 		Internal: internal,
@@ -179,28 +319,6 @@ func (f *FirewallXPNError) Error() string {
 	return f.Message
 }
 
-func equal(expected *compute.Firewall, existing *compute.Firewall) bool {
-	if !sets.NewString(expected.TargetTags...).Equal(sets.NewString(existing.TargetTags...)) {
-		klog.V(5).Infof("Expected target tags %v, actually %v", expected.TargetTags, existing.TargetTags)
-		return false
-	}
-
-	expectedAllowed := allowedToStrings(expected.Allowed)
-	existingAllowed := allowedToStrings(existing.Allowed)
-	if !sets.NewString(expectedAllowed...).Equal(sets.NewString(existingAllowed...)) {
-		klog.V(5).Infof("Expected allowed rules %v, actually %v", expectedAllowed, existingAllowed)
-		return false
-	}
-
-	if !sets.NewString(expected.SourceRanges...).Equal(sets.NewString(existing.SourceRanges...)) {
-		klog.V(5).Infof("Expected source ranges %v, actually %v", expected.SourceRanges, existing.SourceRanges)
-		return false
-	}
-
-	// Ignore other firewall properties as the controller does not set them.
-	return true
-}
-
 func allowedToStrings(allowed []*compute.FirewallAllowed) []string {
 	var allowedStrs []string
 	for _, v := range allowed {
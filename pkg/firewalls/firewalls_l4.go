@@ -27,6 +27,18 @@ import (
 )
 
 func EnsureL4InternalFirewallRule(cloud *gce.Cloud, fwName, lbIP, nsName string, sourceRanges, portRanges, nodeNames []string, proto string, sharedRule bool) error {
+	allowed := []*compute.FirewallAllowed{
+		{
+			IPProtocol: strings.ToLower(proto),
+			Ports:      portRanges,
+		},
+	}
+	return EnsureL4InternalFirewallRuleAllowed(cloud, fwName, lbIP, nsName, sourceRanges, allowed, nodeNames, sharedRule)
+}
+
+// EnsureL4InternalFirewallRuleAllowed ensures the L4 firewall rule with the
+// given name allows the given protocols and ports.
+func EnsureL4InternalFirewallRuleAllowed(cloud *gce.Cloud, fwName, lbIP, nsName string, sourceRanges []string, allowed []*compute.FirewallAllowed, nodeNames []string, sharedRule bool) error {
 	existingFw, err := cloud.GetFirewall(fwName)
 	if err != nil && !utils.IsNotFoundError(err) {
 		return err
@@ -47,17 +59,12 @@ func EnsureL4InternalFirewallRule(cloud *gce.Cloud, fwName, lbIP, nsName string,
 		Network:      cloud.NetworkURL(),
 		SourceRanges: sourceRanges,
 		TargetTags:   nodeTags,
-		Allowed: []*compute.FirewallAllowed{
-			{
-				IPProtocol: strings.ToLower(proto),
-				Ports:      portRanges,
-			},
-		},
+		Allowed:      allowed,
 	}
 	if existingFw == nil {
 		klog.V(2).Infof("EnsureL4InternalFirewallRule(%v): creating firewall", fwName)
 		err = cloud.CreateFirewall(expectedFw)
-		if utils.IsForbiddenError(err) && cloud.OnXPN() {
+		if utils.IsForbiddenError(err) && raiseForbiddenChange(cloud) {
 			gcloudCmd := gce.FirewallToGCloudCreateCmd(expectedFw, cloud.NetworkProjectID())
 
 			klog.V(3).Infof("EnsureL4InternalFirewallRule(%v): Could not create L4 firewall on XPN cluster: %v. Raising event for cmd: %q", fwName, err, gcloudCmd)
@@ -70,7 +77,7 @@ func EnsureL4InternalFirewallRule(cloud *gce.Cloud, fwName, lbIP, nsName string,
 	}
 	klog.V(2).Infof("EnsureL4InternalFirewallRule(%v): updating firewall", fwName)
 	err = cloud.UpdateFirewall(expectedFw)
-	if utils.IsForbiddenError(err) && cloud.OnXPN() {
+	if utils.IsForbiddenError(err) && raiseForbiddenChange(cloud) {
 		gcloudCmd := gce.FirewallToGCloudUpdateCmd(expectedFw, cloud.NetworkProjectID())
 		klog.V(3).Infof("EnsureL4InternalFirewallRule(%v): Could not update L4 firewall on XPN cluster: %v. Raising event for cmd: %q", fwName, err, gcloudCmd)
 		return newFirewallXPNError(err, gcloudCmd)
@@ -80,7 +87,7 @@ func EnsureL4InternalFirewallRule(cloud *gce.Cloud, fwName, lbIP, nsName string,
 
 func EnsureL4InternalFirewallRuleDeleted(cloud *gce.Cloud, fwName string) error {
 	if err := utils.IgnoreHTTPNotFound(cloud.DeleteFirewall(fwName)); err != nil {
-		if utils.IsForbiddenError(err) && cloud.OnXPN() {
+		if utils.IsForbiddenError(err) && raiseForbiddenChange(cloud) {
 			gcloudCmd := gce.FirewallToGCloudDeleteCmd(fwName, cloud.NetworkProjectID())
 			klog.V(3).Infof("EnsureL4InternalFirewallRuleDeleted(%v): could not delete traffic firewall on XPN cluster. Raising event.", fwName)
 			return newFirewallXPNError(err, gcloudCmd)
@@ -90,11 +97,21 @@ func EnsureL4InternalFirewallRuleDeleted(cloud *gce.Cloud, fwName string) error
 	return nil
 }
 
+// l4FirewallFields are the fields of an L4 firewall rule compared by
+// firewallRuleEqual.
+var l4FirewallFields = []utils.FieldSpec{
+	{Path: "Allowed", Elem: []utils.FieldSpec{
+		{Path: "IPProtocol"},
+		{Path: "Ports", Unordered: true},
+	}},
+	{Path: "Description"},
+	{Path: "SourceRanges", Unordered: true},
+	{Path: "TargetTags", Unordered: true},
+}
+
 func firewallRuleEqual(a, b *compute.Firewall) bool {
-	return a.Description == b.Description &&
-		len(a.Allowed) == 1 && len(a.Allowed) == len(b.Allowed) &&
-		a.Allowed[0].IPProtocol == b.Allowed[0].IPProtocol &&
-		utils.EqualStringSets(a.Allowed[0].Ports, b.Allowed[0].Ports) &&
-		utils.EqualStringSets(a.SourceRanges, b.SourceRanges) &&
-		utils.EqualStringSets(a.TargetTags, b.TargetTags)
+	if len(a.Allowed) == 0 {
+		return false
+	}
+	return len(utils.DiffFields(a, b, l4FirewallFields)) == 0
 }
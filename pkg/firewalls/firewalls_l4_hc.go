@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package firewalls
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/compute/v1"
+	"k8s.io/ingress-gce/pkg/utils"
+	"k8s.io/klog"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+// L4HealthCheckFirewall manages a single firewall rule per cluster which
+// allows the health check source ranges to reach the nodes on the health
+// check ports of all L4 ILB services. Every service references the rule with
+// the port of its health check. The rule allows the union of the referenced
+// ports and is deleted once it is no longer referenced.
+type L4HealthCheckFirewall struct {
+	cloud *gce.Cloud
+	name  string
+	// listRefs returns the health check port of every service which
+	// references the rule, keyed by service. It seeds the references after
+	// a restart of the controller.
+	listRefs func() (map[string]int32, error)
+
+	lock sync.Mutex
+	// refs maps the services which reference the rule to their health check
+	// port. It is nil until it is seeded.
+	refs map[string]int32
+}
+
+// NewL4HealthCheckFirewall returns a L4HealthCheckFirewall for the rule with
+// the given name.
+func NewL4HealthCheckFirewall(cloud *gce.Cloud, name string, listRefs func() (map[string]int32, error)) *L4HealthCheckFirewall {
+	return &L4HealthCheckFirewall{cloud: cloud, name: name, listRefs: listRefs}
+}
+
+// Name returns the name of the firewall rule.
+func (f *L4HealthCheckFirewall) Name() string {
+	return f.name
+}
+
+// Ensure adds a reference from the service svcKey with the given health check
+// port and ensures that the rule allows the referenced ports to the nodes.
+func (f *L4HealthCheckFirewall) Ensure(svcKey string, port int32, nodeNames []string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.seed(); err != nil {
+		return err
+	}
+	f.refs[svcKey] = port
+
+	existingFw, err := f.cloud.GetFirewall(f.name)
+	if err != nil && !utils.IsNotFoundError(err) {
+		return err
+	}
+	nodeTags, err := f.cloud.GetNodeTags(nodeNames)
+	if err != nil {
+		return err
+	}
+	expectedFw := f.expectedFirewall(gce.L4LoadBalancerSrcRanges(), nodeTags)
+	if existingFw == nil {
+		klog.V(2).Infof("L4HealthCheckFirewall(%v): creating firewall", f.name)
+		err = f.cloud.CreateFirewall(expectedFw)
+		if utils.IsForbiddenError(err) && raiseForbiddenChange(f.cloud) {
+			gcloudCmd := gce.FirewallToGCloudCreateCmd(expectedFw, f.cloud.NetworkProjectID())
+			klog.V(3).Infof("L4HealthCheckFirewall(%v): Could not create L4 firewall on XPN cluster: %v. Raising event for cmd: %q", f.name, err, gcloudCmd)
+			return newFirewallXPNError(err, gcloudCmd)
+		}
+		return err
+	}
+	return f.patch(expectedFw, existingFw)
+}
+
+// Release removes the reference from the service svcKey. The rule is narrowed
+// to the remaining ports, or deleted if it is no longer referenced.
+func (f *L4HealthCheckFirewall) Release(svcKey string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.seed(); err != nil {
+		return err
+	}
+	delete(f.refs, svcKey)
+	if len(f.refs) == 0 {
+		klog.V(2).Infof("L4HealthCheckFirewall(%v): deleting firewall, no longer referenced", f.name)
+		return EnsureL4InternalFirewallRuleDeleted(f.cloud, f.name)
+	}
+
+	existingFw, err := f.cloud.GetFirewall(f.name)
+	if err != nil {
+		return utils.IgnoreHTTPNotFound(err)
+	}
+	return f.patch(f.expectedFirewall(existingFw.SourceRanges, existingFw.TargetTags), existingFw)
+}
+
+// expectedFirewall returns the rule which allows the referenced ports from the
+// given source ranges to the nodes with the given tags.
+func (f *L4HealthCheckFirewall) expectedFirewall(sourceRanges, targetTags []string) *compute.Firewall {
+	fwDesc, err := utils.MakeL4ILBServiceDescription("", "", meta.VersionGA, true)
+	if err != nil {
+		klog.Warningf("L4HealthCheckFirewall(%v): Failed to generate description, err: %v", f.name, err)
+	}
+	return &compute.Firewall{
+		Name:         f.name,
+		Description:  fwDesc,
+		Network:      f.cloud.NetworkURL(),
+		SourceRanges: sourceRanges,
+		TargetTags:   targetTags,
+		Allowed: []*compute.FirewallAllowed{
+			{
+				IPProtocol: "tcp",
+				Ports:      f.ports(),
+			},
+		},
+	}
+}
+
+// patch updates the fields of the existing rule which differ from expectedFw.
+// Only these fields are sent, so that concurrent changes to the other fields
+// of the rule are not reverted.
+func (f *L4HealthCheckFirewall) patch(expectedFw, existingFw *compute.Firewall) error {
+	patch, changed := firewallPatch(expectedFw, existingFw)
+	if patch == nil {
+		return nil
+	}
+	klog.V(2).Infof("L4HealthCheckFirewall(%v): patching %v of firewall, allowed ports %v", f.name, changed, expectedFw.Allowed[0].Ports)
+	err := newGCEFirewall(f.cloud).PatchFirewall(patch)
+	if utils.IsForbiddenError(err) && raiseForbiddenChange(f.cloud) {
+		gcloudCmd := gce.FirewallToGCloudUpdateCmd(expectedFw, f.cloud.NetworkProjectID())
+		klog.V(3).Infof("L4HealthCheckFirewall(%v): Could not update L4 firewall on XPN cluster: %v. Raising event for cmd: %q", f.name, err, gcloudCmd)
+		return newFirewallXPNError(err, gcloudCmd)
+	}
+	return err
+}
+
+// seed initializes the references from listRefs if they are not known yet.
+func (f *L4HealthCheckFirewall) seed() error {
+	if f.refs != nil {
+		return nil
+	}
+	refs, err := f.listRefs()
+	if err != nil {
+		return err
+	}
+	klog.V(3).Infof("L4HealthCheckFirewall(%v): seeded %d references", f.name, len(refs))
+	f.refs = refs
+	if f.refs == nil {
+		f.refs = map[string]int32{}
+	}
+	return nil
+}
+
+// ports returns the sorted distinct ports referenced by the services.
+func (f *L4HealthCheckFirewall) ports() []string {
+	seen := map[int32]bool{}
+	var ports []int
+	for _, port := range f.refs {
+		if seen[port] {
+			continue
+		}
+		seen[port] = true
+		ports = append(ports, int(port))
+	}
+	sort.Ints(ports)
+	var result []string
+	for _, port := range ports {
+		result = append(result, strconv.Itoa(port))
+	}
+	return result
+}
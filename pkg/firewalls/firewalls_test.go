@@ -94,6 +94,17 @@ func TestFirewallPoolSyncSrcRanges(t *testing.T) {
 		t.Errorf("unexpected err when syncing firewall, err: %v", err)
 	}
 	verifyFirewallRule(fwp, ruleName, nodes, srcRanges, portRanges(), t)
+	if conflicts := fp.Conflicts(); len(conflicts) != 1 {
+		t.Errorf("Conflicts() = %v, want 1 conflict", conflicts)
+	}
+
+	// The conflict is only reported by the sync which reverted it.
+	if err := fp.Sync(nodes, nil, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if conflicts := fp.Conflicts(); len(conflicts) != 0 {
+		t.Errorf("Conflicts() = %v, want none", conflicts)
+	}
 }
 
 func TestFirewallPoolSyncPorts(t *testing.T) {
@@ -171,6 +182,42 @@ func TestFirewallPoolSyncRanges(t *testing.T) {
 	}
 }
 
+func TestFirewallPoolSyncIPv6Ranges(t *testing.T) {
+	t.Parallel()
+	fwp := NewFakeFirewallsProvider(false, false)
+	fp := NewFirewallPool(fwp, defaultNamer, srcRanges, portRanges())
+	nodes := []string{"node-a", "node-b", "node-c"}
+	ipv6Ranges := []string{"2600:2d00:1:b029::/64", "2600:2d00:1:1::/64"}
+	ipv6RuleName := ipv6FirewallRule(defaultNamer)
+
+	if err := fp.Sync(nodes, nil, ipv6Ranges, true); err != nil {
+		t.Fatalf("fp.Sync(%v, nil, %v) = %v; want nil", nodes, ipv6Ranges, err)
+	}
+	verifyFirewallRule(fwp, ruleName, nodes, srcRanges, portRanges(), t)
+	verifyFirewallRule(fwp, ipv6RuleName, nodes, ipv6Ranges, portRanges(), t)
+
+	// The IPv6 rule is deleted once no IPv6 ranges are needed.
+	if err := fp.Sync(nodes, nil, nil, true); err != nil {
+		t.Fatalf("fp.Sync(%v, nil, nil) = %v; want nil", nodes, err)
+	}
+	verifyFirewallRule(fwp, ruleName, nodes, srcRanges, portRanges(), t)
+	if f, err := fwp.GetFirewall(ipv6RuleName); err == nil || f != nil {
+		t.Errorf("GetFirewall(%q) = %v, %v, expected nil, (error)", ipv6RuleName, f, err)
+	}
+
+	if err := fp.Sync(nodes, nil, ipv6Ranges, true); err != nil {
+		t.Fatalf("fp.Sync(%v, nil, %v) = %v; want nil", nodes, ipv6Ranges, err)
+	}
+	if err := fp.GC(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{ruleName, ipv6RuleName} {
+		if f, err := fwp.GetFirewall(name); err == nil || f != nil {
+			t.Errorf("GetFirewall(%q) = %v, %v, expected nil, (error)", name, f, err)
+		}
+	}
+}
+
 func TestFirewallPoolGC(t *testing.T) {
 	fwp := NewFakeFirewallsProvider(false, false)
 	fp := NewFirewallPool(fwp, defaultNamer, srcRanges, portRanges())
@@ -191,6 +238,106 @@ func TestFirewallPoolGC(t *testing.T) {
 	}
 }
 
+// TestFirewallPoolSyncPatch tests that only the changed fields of the rule are
+// updated, keeping changes made to the other fields outside of the controller.
+func TestFirewallPoolSyncPatch(t *testing.T) {
+	fwp := NewFakeFirewallsProvider(false, false)
+	fp := NewFirewallPool(fwp, defaultNamer, srcRanges, portRanges())
+	nodes := []string{"node-a", "node-b", "node-c"}
+
+	if err := fp.Sync(nodes, nil, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Manually modify fields which are not owned by the controller.
+	f, _ := fwp.GetFirewall(ruleName)
+	f.Priority = 900
+	f.Description = "edited"
+	if err := fwp.UpdateFirewall(f); err != nil {
+		t.Fatal(err)
+	}
+
+	negTargetports := []string{"80", "443"}
+	if err := fp.Sync(nodes, negTargetports, nil, true); err != nil {
+		t.Errorf("unexpected err when syncing firewall, err: %v", err)
+	}
+	verifyFirewallRule(fwp, ruleName, nodes, srcRanges, append(portRanges(), negTargetports...), t)
+	f, _ = fwp.GetFirewall(ruleName)
+	if f.Priority != 900 || f.Description != "edited" {
+		t.Errorf("GetFirewall(%q) = {Priority: %d, Description: %q}, want {Priority: 900, Description: \"edited\"}", ruleName, f.Priority, f.Description)
+	}
+}
+
+func TestFirewallPatch(t *testing.T) {
+	existing := &compute.Firewall{
+		Name:         ruleName,
+		Description:  "edited",
+		SourceRanges: []string{"10.0.0.0/8", "11.0.0.0/8"},
+		TargetTags:   []string{"node-a"},
+		Allowed:      []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"80", "443"}}},
+	}
+	for _, tc := range []struct {
+		desc        string
+		expected    *compute.Firewall
+		wantChanged []string
+	}{
+		{
+			desc: "no changes",
+			expected: &compute.Firewall{
+				Name:         ruleName,
+				SourceRanges: []string{"11.0.0.0/8", "10.0.0.0/8"},
+				TargetTags:   []string{"node-a"},
+				Allowed:      []*compute.FirewallAllowed{{IPProtocol: "TCP", Ports: []string{"443", "80"}}},
+			},
+		},
+		{
+			desc: "ports changed",
+			expected: &compute.Firewall{
+				Name:         ruleName,
+				SourceRanges: []string{"10.0.0.0/8", "11.0.0.0/8"},
+				TargetTags:   []string{"node-a"},
+				Allowed:      []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"80"}}},
+			},
+			wantChanged: []string{fieldAllowed},
+		},
+		{
+			desc: "source ranges and target tags changed",
+			expected: &compute.Firewall{
+				Name:         ruleName,
+				SourceRanges: []string{"10.0.0.0/8"},
+				TargetTags:   []string{"node-a", "node-b"},
+				Allowed:      []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"80", "443"}}},
+			},
+			wantChanged: []string{fieldSourceRanges, fieldTargetTags},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			patch, changed := firewallPatch(tc.expected, existing)
+			if !sets.NewString(changed...).Equal(sets.NewString(tc.wantChanged...)) {
+				t.Fatalf("firewallPatch() changed %v, want %v", changed, tc.wantChanged)
+			}
+			if len(tc.wantChanged) == 0 {
+				if patch != nil {
+					t.Errorf("firewallPatch() = %+v, want nil", patch)
+				}
+				return
+			}
+			if patch.Name != ruleName || patch.Description != "" {
+				t.Errorf("firewallPatch() = {Name: %q, Description: %q}, want {Name: %q, Description: \"\"}", patch.Name, patch.Description, ruleName)
+			}
+			if got := patch.Allowed != nil; got != slice.ContainsString(changed, fieldAllowed, nil) {
+				t.Errorf("firewallPatch().Allowed = %v, want set: %t", patch.Allowed, !got)
+			}
+			if got := patch.SourceRanges != nil; got != slice.ContainsString(changed, fieldSourceRanges, nil) {
+				t.Errorf("firewallPatch().SourceRanges = %v, want set: %t", patch.SourceRanges, !got)
+			}
+			if got := patch.TargetTags != nil; got != slice.ContainsString(changed, fieldTargetTags, nil) {
+				t.Errorf("firewallPatch().TargetTags = %v, want set: %t", patch.TargetTags, !got)
+			}
+		})
+	}
+}
+
 // TestSyncOnXPNWithPermission tests that firewall sync continues to work when OnXPN=true
 func TestSyncOnXPNWithPermission(t *testing.T) {
 	// Fake XPN cluster with permission
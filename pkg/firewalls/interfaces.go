@@ -25,6 +25,9 @@ type SingleFirewallPool interface {
 	// Sync syncs firewall rules with the cloud
 	Sync(nodeNames, additionalPorts, additionalRanges []string, allowNodePort bool) error
 	GC() error
+	// Conflicts returns the changes made outside of the controller which
+	// were reverted by the last Sync.
+	Conflicts() []string
 }
 
 // Firewall interfaces with the GCE firewall api.
@@ -35,6 +38,9 @@ type Firewall interface {
 	GetFirewall(name string) (*compute.Firewall, error)
 	DeleteFirewall(name string) error
 	UpdateFirewall(f *compute.Firewall) error
+	// PatchFirewall only updates the fields of the firewall rule which are
+	// set or listed in ForceSendFields.
+	PatchFirewall(f *compute.Firewall) error
 	GetNodeTags(nodeNames []string) ([]string, error)
 	NetworkProjectID() string
 	NetworkURL() string
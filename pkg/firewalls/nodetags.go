@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package firewalls
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/filter"
+	"google.golang.org/api/compute/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+// refreshingNodeTags is a Firewall which re-resolves the network tags of the
+// nodes from their GCE instances once the tags are older than the refresh
+// period. The GCE cloud provider either uses the tags configured in gce.conf
+// or computes them once per set of node names, so it misses tags that change
+// later, e.g. when node pools with different tags are added.
+type refreshingNodeTags struct {
+	Firewall
+	cloud *gce.Cloud
+	// zoneForNode returns the zone of the named node.
+	zoneForNode func(name string) (string, error)
+	period      time.Duration
+	now         func() time.Time
+
+	lock      sync.Mutex
+	nodeNames sets.String
+	tags      []string
+	resolved  time.Time
+}
+
+// newRefreshingNodeTags returns a Firewall which re-resolves the node tags of
+// fw every period.
+func newRefreshingNodeTags(fw Firewall, cloud *gce.Cloud, zoneForNode func(string) (string, error), period time.Duration) Firewall {
+	return &refreshingNodeTags{
+		Firewall:    fw,
+		cloud:       cloud,
+		zoneForNode: zoneForNode,
+		period:      period,
+		now:         time.Now,
+	}
+}
+
+// GetNodeTags implements Firewall.
+func (r *refreshingNodeTags) GetNodeTags(nodeNames []string) ([]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	names := sets.NewString(nodeNames...)
+	if names.Equal(r.nodeNames) && r.now().Sub(r.resolved) < r.period {
+		return r.tags, nil
+	}
+	tags, err := r.resolve(nodeNames)
+	if err != nil {
+		return nil, err
+	}
+	if r.tags != nil && !sets.NewString(tags...).Equal(sets.NewString(r.tags...)) {
+		klog.V(2).Infof("Node tags changed from %v to %v", r.tags, tags)
+	}
+	r.nodeNames = names
+	r.tags = tags
+	r.resolved = r.now()
+	return tags, nil
+}
+
+// resolve returns the tags of the instances of the given nodes. The tags
+// returned by the cloud provider are kept for instances which carry them.
+// Otherwise the tag of an instance is the longest of its tags which is a
+// prefix of its name, as computed by the cloud provider.
+func (r *refreshingNodeTags) resolve(nodeNames []string) ([]string, error) {
+	baseTags, err := r.Firewall.GetNodeTags(nodeNames)
+	if err != nil {
+		return nil, err
+	}
+	base := sets.NewString(baseTags...)
+
+	namesByZone := map[string]sets.String{}
+	for _, name := range nodeNames {
+		zone, err := r.zoneForNode(name)
+		if err != nil {
+			klog.Warningf("Failed to get the zone of node %s, keeping tags %v for it: %v", name, baseTags, err)
+			continue
+		}
+		if namesByZone[zone] == nil {
+			namesByZone[zone] = sets.NewString()
+		}
+		namesByZone[zone].Insert(name)
+	}
+
+	tags := sets.NewString()
+	found := sets.NewString()
+	for zone, names := range namesByZone {
+		instances, err := r.listInstances(zone)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range instances {
+			if !names.Has(instance.Name) {
+				continue
+			}
+			found.Insert(instance.Name)
+			if tag := instanceTags(instance, base); len(tag) > 0 {
+				tags.Insert(tag...)
+			} else {
+				klog.Warningf("Instance %s has no tag which is a prefix of its name or one of %v, firewall rules do not target it", instance.Name, baseTags)
+			}
+		}
+	}
+	// Nodes whose instances are unknown keep the tags of the cloud provider.
+	if found.Len() < len(nodeNames) {
+		tags.Insert(baseTags...)
+	}
+	return tags.List(), nil
+}
+
+func (r *refreshingNodeTags) listInstances(zone string) ([]*compute.Instance, error) {
+	ctx, cancel := cloud.ContextWithCallTimeout()
+	defer cancel()
+	return r.cloud.Compute().Instances().List(ctx, zone, filter.None)
+}
+
+// instanceTags returns the tags of instance which are in base, or else the
+// longest tag which is a prefix of its name.
+func instanceTags(instance *compute.Instance, base sets.String) []string {
+	if instance.Tags == nil {
+		return nil
+	}
+	var tags []string
+	longestTag := ""
+	for _, tag := range instance.Tags.Items {
+		if base.Has(tag) {
+			tags = append(tags, tag)
+		}
+		if strings.HasPrefix(instance.Name, tag) && len(tag) > len(longestTag) {
+			longestTag = tag
+		}
+	}
+	if len(tags) == 0 && longestTag != "" {
+		tags = append(tags, longestTag)
+	}
+	return tags
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package firewalls
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/compute/v1"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+// configuredNodeTags is a Firewall with node tags configured in gce.conf.
+type configuredNodeTags struct {
+	Firewall
+	tags []string
+}
+
+func (c *configuredNodeTags) GetNodeTags([]string) ([]string, error) {
+	return c.tags, nil
+}
+
+func TestRefreshingNodeTags(t *testing.T) {
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	zones := map[string]string{"node-a": "zone-a", "pool2-node-b": "zone-b"}
+	setInstance := func(name string, tags ...string) {
+		key := meta.ZonalKey(name, zones[name])
+		fakeGCE.Compute().Instances().Delete(context.Background(), key)
+		if err := fakeGCE.Compute().Instances().Insert(context.Background(), key, &compute.Instance{Name: name, Tags: &compute.Tags{Items: tags}}); err != nil {
+			t.Fatalf("Insert(%v) = %v", key, err)
+		}
+	}
+	zoneForNode := func(name string) (string, error) {
+		if zone, ok := zones[name]; ok {
+			return zone, nil
+		}
+		return "", fmt.Errorf("node %s not found", name)
+	}
+
+	now := time.Now()
+	fw := newRefreshingNodeTags(&configuredNodeTags{NewFakeFirewallsProvider(false, false), []string{"cluster-node"}}, fakeGCE, zoneForNode, time.Minute).(*refreshingNodeTags)
+	fw.now = func() time.Time { return now }
+
+	setInstance("node-a", "cluster-node", "http-server")
+	setInstance("pool2-node-b", "pool2", "http-server")
+
+	for _, tc := range []struct {
+		desc      string
+		nodeNames []string
+		update    func()
+		advance   time.Duration
+		want      []string
+	}{
+		{
+			desc:      "configured tags",
+			nodeNames: []string{"node-a"},
+			want:      []string{"cluster-node"},
+		},
+		{
+			desc:      "node pool with other tags added",
+			nodeNames: []string{"node-a", "pool2-node-b"},
+			want:      []string{"cluster-node", "pool2"},
+		},
+		{
+			desc:      "tags changed within refresh period",
+			nodeNames: []string{"node-a", "pool2-node-b"},
+			update:    func() { setInstance("pool2-node-b", "pool2-node", "http-server") },
+			want:      []string{"cluster-node", "pool2"},
+		},
+		{
+			desc:      "tags changed after refresh period",
+			nodeNames: []string{"node-a", "pool2-node-b"},
+			advance:   time.Minute,
+			want:      []string{"cluster-node", "pool2-node"},
+		},
+		{
+			desc:      "unknown node keeps configured tags",
+			nodeNames: []string{"pool2-node-b", "node-c"},
+			want:      []string{"cluster-node", "pool2-node"},
+		},
+	} {
+		if tc.update != nil {
+			tc.update()
+		}
+		now = now.Add(tc.advance)
+		got, err := fw.GetNodeTags(tc.nodeNames)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: GetNodeTags(%v) = %v, %v, want %v, nil", tc.desc, tc.nodeNames, got, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package firewalls
+
+import (
+	"google.golang.org/api/compute/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+// Fields of a firewall rule which are owned by the controller.
+const (
+	fieldAllowed      = "Allowed"
+	fieldSourceRanges = "SourceRanges"
+	fieldTargetTags   = "TargetTags"
+)
+
+// gceFirewall adds PATCH semantics to the firewall methods of the GCE cloud
+// provider, which only replaces firewall rules as a whole.
+type gceFirewall struct {
+	*gce.Cloud
+}
+
+// newGCEFirewall returns the Firewall of the given cloud.
+func newGCEFirewall(c *gce.Cloud) Firewall {
+	return &gceFirewall{Cloud: c}
+}
+
+// PatchFirewall implements Firewall. The vendored GCE client does not support
+// PATCH for firewall rules, so the patch is applied to the current rule which
+// is then updated. Both calls go through the rate limiter and API metrics of
+// the cloud provider.
+func (g *gceFirewall) PatchFirewall(f *compute.Firewall) error {
+	existing, err := g.GetFirewall(f.Name)
+	if err != nil {
+		return err
+	}
+	return g.UpdateFirewall(applyFirewallPatch(existing, f))
+}
+
+// applyFirewallPatch returns a copy of the firewall rule existing in which the
+// fields owned by the controller are replaced by those of patch which are set
+// or listed in its ForceSendFields.
+func applyFirewallPatch(existing, patch *compute.Firewall) *compute.Firewall {
+	patched := *existing
+	forced := sets.NewString(patch.ForceSendFields...)
+	if len(patch.Allowed) > 0 || forced.Has(fieldAllowed) {
+		patched.Allowed = patch.Allowed
+	}
+	if len(patch.SourceRanges) > 0 || forced.Has(fieldSourceRanges) {
+		patched.SourceRanges = patch.SourceRanges
+	}
+	if len(patch.TargetTags) > 0 || forced.Has(fieldTargetTags) {
+		patched.TargetTags = patch.TargetTags
+	}
+	return &patched
+}
+
+// firewallPatch returns a firewall rule which only carries the fields owned
+// by the controller in which existing differs from expected, along with the
+// names of these fields. The patch is nil if no field differs. Fields which
+// are not sent are left untouched by GCE, so that concurrent changes to them
+// are not reverted.
+func firewallPatch(expected, existing *compute.Firewall) (*compute.Firewall, []string) {
+	changed := changedFields(expected, existing)
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	patch := &compute.Firewall{Name: expected.Name}
+	for _, field := range changed {
+		switch field {
+		case fieldAllowed:
+			patch.Allowed = expected.Allowed
+		case fieldSourceRanges:
+			patch.SourceRanges = expected.SourceRanges
+		case fieldTargetTags:
+			patch.TargetTags = expected.TargetTags
+		}
+	}
+	// Lists which became empty are only cleared if they are sent explicitly.
+	patch.ForceSendFields = changed
+	return patch, changed
+}
+
+// changedFields returns the fields owned by the controller in which the
+// firewall rules a and b differ.
+func changedFields(a, b *compute.Firewall) []string {
+	var changed []string
+	if !sets.NewString(allowedToStrings(a.Allowed)...).Equal(sets.NewString(allowedToStrings(b.Allowed)...)) {
+		changed = append(changed, fieldAllowed)
+	}
+	if !sets.NewString(a.SourceRanges...).Equal(sets.NewString(b.SourceRanges...)) {
+		changed = append(changed, fieldSourceRanges)
+	}
+	if !sets.NewString(a.TargetTags...).Equal(sets.NewString(b.TargetTags...)) {
+		changed = append(changed, fieldTargetTags)
+	}
+	return changed
+}
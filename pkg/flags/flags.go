@@ -61,29 +61,51 @@ var (
 	// F are global flags for the controller.
 	F = struct {
 		APIServerHost                    string
+		AuditLog                         string
 		ASMConfigMapBasedConfigCMName    string
 		ASMConfigMapBasedConfigNamespace string
+		BackendServiceCacheVerifyPeriod  time.Duration
 		ClusterName                      string
+		ComputeAPIEndpoint               string
 		ConfigFilePath                   string
+		DefaultBackendConfig             string
+		DefaultBackendTimeout            time.Duration
+		DefaultFrontendConfig            string
 		DefaultSvc                       string
 		DefaultSvcHealthCheckPath        string
+		DebugTokenFile                   string
 		DefaultSvcPortName               string
 		DeleteAllOnQuit                  bool
+		EventAggregationInterval         time.Duration
+		EventsLogName                    string
 		GCEOperationPollInterval         time.Duration
 		GCERateLimit                     RateLimitSpecs
 		HealthCheckPath                  string
 		HealthzPort                      int
 		InCluster                        bool
 		IngressClass                     string
+		IngressStatusHostnameTemplate    string
+		IngressWarmupRetryPeriod         time.Duration
 		KubeConfigFile                   string
+		L4NetLBHealthCheckPolicy         string
+		MaxCertificatesPerNamespace      int
+		MaxIngressesPerNamespace         int
+		MaxNEGServicesPerNamespace       int
+		NamespaceProjectConfigPath       string
 		NegGCPeriod                      time.Duration
+		NegLabelPropagationAllowList     string
 		NodePortRanges                   PortRanges
+		NodeTagsRefreshPeriod            time.Duration
 		ResyncPeriod                     time.Duration
+		ResyncSpread                     time.Duration
+		StateHandoffPeriod               time.Duration
+		SyncDeadline                     time.Duration
 		NumL4Workers                     int
 		RunIngressController             bool
 		RunL4Controller                  bool
 		Version                          bool
 		WatchNamespace                   string
+		WatchSecrets                     bool
 		LeaderElection                   LeaderElectionConfiguration
 
 		// Feature flags should be named Enablexxx.
@@ -91,13 +113,19 @@ var (
 		EnableBackendConfigHealthCheck bool
 		EnableDeleteUnusedFrontends    bool
 		EnableFrontendConfig           bool
+		EnableIngressWarmup            bool
+		EnableInstanceGroupGC          bool
 		EnableNonGCPMode               bool
 		EnableReadinessReflector       bool
+		EnableRestrictedNodeAccess     bool
 		EnableV2FrontendNamer          bool
 		FinalizerAdd                   bool // Should have been named Enablexxx.
 		FinalizerRemove                bool // Should have been named Enablexxx.
 		EnablePSC                      bool
 		EnableIngressGAFields          bool
+		EnableSharedHealthChecks       bool
+		EnableL4ILBMixedProtocol       bool
+		EnableProbeHealthCheckOverride bool
 	}{}
 )
 
@@ -140,15 +168,36 @@ func Register() {
 protocol://address:port, e.g., http://localhost:8080. If not specified, the
 assumption is that the binary runs inside a Kubernetes cluster and local
 discovery is attempted.`)
+	flag.StringVar(&F.AuditLog, "audit-log", "",
+		`Optional, path of a file to which a line of JSON is appended for every mutating call to the GCE API,
+recording the resource, a summary of the change, the sync and the outcome. Use "-" for stdout.`)
 	flag.StringVar(&F.ClusterName, "cluster-uid", DefaultClusterUID,
 		`Optional, used to tag cluster wide, shared loadbalancer resources such
 as instance groups. Use this flag if you'd like to continue using the same
 resources across a pod restart. Note that this does not need to  match the name
 of you Kubernetes cluster, it's just an arbitrary name used to tag/lookup cloud
 resources.`)
+	flag.StringVar(&F.ComputeAPIEndpoint, "compute-api-endpoint", "",
+		`Optional, base URL of the GA compute API, e.g.
+"https://restricted.googleapis.com/compute/v1/" for environments restricted by
+VPC Service Controls or the URL of an API emulator. Overrides the api-endpoint
+of the gce config files. The URLs of the beta and alpha APIs are derived by
+replacing "v1".`)
 	flag.StringVar(&F.ConfigFilePath, "config-file-path", "",
 		`Path to a file containing the gce config. If left unspecified this
 controller only works with default zones.`)
+	flag.StringVar(&F.DefaultBackendConfig, "default-backend-config", "",
+		`Optional, BackendConfig applied to service ports that do not reference one.
+Takes the form namespace/name. While it does not exist, an event is recorded on
+the service and no BackendConfig is applied.`)
+	flag.DurationVar(&F.DefaultBackendTimeout, "default-backend-timeout", 0,
+		`Optional, timeout of newly created backend services. A BackendConfig timeout
+takes precedence. Zero means the GCE default of 30s.`)
+	flag.StringVar(&F.DefaultFrontendConfig, "default-frontend-config", "",
+		`Optional, FrontendConfig applied to Ingresses that do not reference one and
+whose IngressClass parameters do not specify a default. Takes the form namespace/name.
+While it does not exist, an event is recorded on the Ingress and no FrontendConfig
+is applied.`)
 	flag.StringVar(&F.DefaultSvcHealthCheckPath, "default-backend-health-check-path", "/healthz",
 		`Path used to health-check the default backend service. This path must serve a 200 page.
 Flags default-backend-service and default-backend-service-port should never be empty - default
@@ -164,6 +213,16 @@ only the port's name - not its number.`)
 external cloud resources as it's shutting down. Mostly used for testing. In
 normal environments the controller should only delete a loadbalancer if the
 associated Ingress is deleted.`)
+	flag.DurationVar(&F.EventAggregationInterval, "event-aggregation-interval", 0,
+		`Optional, interval at which the Events repeated for the same object and
+reason are recorded. The first Event is recorded right away, the repeated ones
+are recorded once per interval as a single Event with their count, so that
+widespread sync failures do not flood the API server. Zero disables
+aggregation.`)
+	flag.StringVar(&F.EventsLogName, "events-log-name", "",
+		`Optional, name of a Cloud Logging log of the project of the cluster. If set,
+the Events recorded by the controllers are mirrored into the log as structured
+entries labeled with the cluster and the Ingress or Service they are about.`)
 	flag.BoolVar(&F.EnableFrontendConfig, "enable-frontend-config", false,
 		`Optional, whether or not to enable FrontendConfig.`)
 	flag.Var(&F.GCERateLimit, "gce-ratelimit",
@@ -180,6 +239,12 @@ values.`)
 	flag.StringVar(&F.HealthCheckPath, "health-check-path", "/",
 		`Path used to health-check a backend service. All Services must serve a
 200 page on this path. Currently this is only configurable globally.`)
+	flag.StringVar(&F.DebugTokenFile, "debug-token-file", "",
+		`Optional, path to a file holding a bearer token. If set, POST requests to
+/debug/resync and /debug/gc on the healthz port which carry the token in their
+Authorization header trigger an immediate resync of all Ingresses or a garbage
+collection pass. GET requests to /debug/gc carrying the token list the garbage
+collection decisions.`)
 	flag.IntVar(&F.HealthzPort, "healthz-port", 8081,
 		`Port to run healthz server. Must match the health check port in yaml.`)
 	flag.BoolVar(&F.InCluster, "running-in-cluster", true,
@@ -187,12 +252,64 @@ values.`)
 the pod secrets for creating a Kubernetes client.`)
 	flag.StringVar(&F.KubeConfigFile, "kubeconfig", "",
 		`Path to kubeconfig file with authorization and master location information.`)
+	flag.StringVar(&F.L4NetLBHealthCheckPolicy, "l4-netlb-health-check-policy", "shared",
+		`Optional, healthchecks of external LoadBalancer services with the Cluster
+traffic policy which are migrated from target pools to regional backend
+services. Either "shared", for a single healthcheck per cluster, or
+"per-service". Migrated LoadBalancers are moved to the healthcheck of the policy
+when their migration is run again and healthchecks are deleted once no longer
+referenced. Services with the Local traffic policy always have their own
+healthcheck.`)
+	flag.IntVar(&F.MaxCertificatesPerNamespace, "max-certificates-per-namespace", 0,
+		`Optional, maximum number of certificates, TLS secrets and pre-shared
+certificates, used by the Ingresses of a namespace. Ingresses exceeding the
+limit are not synced. Zero means unlimited.`)
+	flag.IntVar(&F.MaxIngressesPerNamespace, "max-ingresses-per-namespace", 0,
+		`Optional, maximum number of Ingresses provisioned per namespace. The
+oldest Ingresses are provisioned first. Zero means unlimited.`)
+	flag.IntVar(&F.MaxNEGServicesPerNamespace, "max-neg-services-per-namespace", 0,
+		`Optional, maximum number of Services with NEGs enabled per namespace.
+NEGs are not created for Services exceeding the limit. Zero means unlimited.`)
+	flag.StringVar(&F.IngressStatusHostnameTemplate, "ingress-status-hostname-template", "",
+		`Optional, Go template of a hostname published with the IP in the status of
+Ingresses, e.g. "{{.Name}}.{{.Namespace}}.lb.example.com", so that external-dns can
+create records by hostname. The template is executed with the Name and Namespace of
+the Ingress and the ClusterUID.`)
+	flag.BoolVar(&F.EnableIngressWarmup, "enable-ingress-warmup", false,
+		`Optional, probe the VIP of an Ingress for its hosts and paths once it is
+provisioned and record a Ready condition in the ingress.kubernetes.io/ready
+annotation only after the load balancer serves them, e.g. before DNS is cut over
+to the VIP. Probes run in the background and stop once the current generation of
+the Ingress is Ready.`)
+	flag.DurationVar(&F.IngressWarmupRetryPeriod, "ingress-warmup-retry-period", 30*time.Second,
+		`Optional, the period after which the VIP of an Ingress which is not Ready
+yet is probed again. Only used with -enable-ingress-warmup.`)
+	flag.StringVar(&F.NamespaceProjectConfigPath, "namespace-project-config-path", "",
+		`Optional, path to a JSON file mapping namespaces to GCP projects. The load
+balancer frontends of Ingresses in a mapped namespace are created in the mapped
+project, using the gce config file given for it, which is required for every
+project other than the cluster project. Backends stay in the cluster project
+and are referenced across projects.`)
 	flag.DurationVar(&F.ResyncPeriod, "sync-period", 30*time.Second,
 		`Relist and confirm cloud resources this often.`)
+	flag.DurationVar(&F.ResyncSpread, "resync-spread", 0,
+		`Optional, window over which the periodic resyncs of Ingresses and L4
+Services are spread, instead of enqueueing all of them at once. Each key is
+delayed by a stable offset within the window, so that it is still synced once
+per sync-period. Should not exceed sync-period. Zero disables spreading.`)
+	flag.DurationVar(&F.SyncDeadline, "sync-deadline", 0,
+		`Optional, maximum duration of the sync of an Ingress or L4 Service. A sync
+exceeding it is aborted before its next GCE operation and requeued, and is
+reported with an event and a metric, so that a hung GCE operation does not
+block a worker. Zero means no deadline.`)
 	flag.IntVar(&F.NumL4Workers, "num-l4-workers", 5,
 		`Number of parallel L4 Service worker goroutines.`)
 	flag.StringVar(&F.WatchNamespace, "watch-namespace", v1.NamespaceAll,
 		`Namespace to watch for Ingress/Services/Endpoints.`)
+	flag.BoolVar(&F.WatchSecrets, "watch-secrets", false,
+		`Optional, if enabled, Ingresses are synced as soon as a Secret they use,
+for TLS or through the IAP settings of a BackendConfig, changes. All Secrets
+of the watched namespaces are then cached by the controller.`)
 	flag.BoolVar(&F.Version, "version", false,
 		`Print the version of the controller and exit`)
 	flag.StringVar(&F.IngressClass, "ingress-class", "",
@@ -203,9 +320,22 @@ L7 load balancing. CSV values accepted. Example: -node-port-ranges=80,8080,400-5
 	leaderelectionconfig.BindLeaderElectionFlags(&F.LeaderElection.LeaderElectionConfiguration, flag.CommandLine)
 	flag.StringVar(&F.LeaderElection.LockObjectNamespace, "lock-object-namespace", F.LeaderElection.LockObjectNamespace, "Define the namespace of the lock object.")
 	flag.StringVar(&F.LeaderElection.LockObjectName, "lock-object-name", F.LeaderElection.LockObjectName, "Define the name of the lock object.")
+	flag.DurationVar(&F.NodeTagsRefreshPeriod, "node-tags-refresh-period", 0,
+		`Optional, re-resolve the network tags targeted by the L7 firewall rule from the GCE instances of the nodes this often.
+Tags of instances which carry none of the tags configured in gce.conf are then added. Zero resolves the tags only when the nodes change.`)
 	flag.DurationVar(&F.NegGCPeriod, "neg-gc-period", 120*time.Second,
 		`Relist and garbage collect NEGs this often.`)
+	flag.StringVar(&F.NegLabelPropagationAllowList, "neg-label-propagation-allowlist", "",
+		`Comma separated list of Service label keys that are copied onto the annotations of the Service's NEGs.
+NEGs cannot be modified in place, so a NEG whose annotations are out of date is recreated when it is not in use.`)
 	flag.BoolVar(&F.EnableReadinessReflector, "enable-readiness-reflector", true, "Enable NEG Readiness Reflector")
+	flag.BoolVar(&F.EnableRestrictedNodeAccess, "enable-restricted-node-access", false,
+		`Optional, run the Ingress controller in environments where it cannot enumerate or modify node-level
+resources, e.g. GKE Autopilot. Ingress backends always use NEGs and no instance groups are managed,
+and firewall changes the controller is forbidden to make are raised as events with the gcloud command
+making them; there is no fallback creating the rules otherwise. Requires --enable-readiness-reflector,
+as the health status of NEGs replaces the health checks of the nodes, and cannot be combined with
+--run-l4-controller, which uses instance groups.`)
 	flag.BoolVar(&F.FinalizerAdd, "enable-finalizer-add",
 		F.FinalizerAdd, "Enable adding Finalizer to Ingress.")
 	flag.BoolVar(&F.FinalizerRemove, "enable-finalizer-remove",
@@ -221,6 +351,12 @@ L7 load balancing. CSV values accepted. Example: -node-port-ranges=80,8080,400-5
 	flag.BoolVar(&F.EnableBackendConfigHealthCheck, "enable-backendconfig-healthcheck", false, "Enable configuration of HealthChecks from the BackendConfig")
 	flag.BoolVar(&F.EnablePSC, "enable-psc", false, "Enable PSC controller")
 	flag.BoolVar(&F.EnableIngressGAFields, "enable-ingress-ga-fields", false, "Enable using Ingress Class GA features")
+	flag.BoolVar(&F.EnableInstanceGroupGC, "enable-instance-group-gc", false, "Delete the instance groups of the cluster once all Ingress backends use NEGs, and remove their named ports for node ports no longer used by Ingress backends. If disabled, these deletions are only logged and reported with the other GC decisions.")
+	flag.BoolVar(&F.EnableSharedHealthChecks, "enable-shared-health-checks", false, "Share a single health check between NEG backend services with identical health checks, instead of creating one health check per backend service.")
+	flag.BoolVar(&F.EnableProbeHealthCheckOverride, "enable-readiness-probe-health-check-override", false, "Apply changes of the readiness probe (path, host, period and timeout) to existing health checks, overriding these settings if they were changed outside of the controller. If disabled, the readiness probe only applies when the health check is created.")
+	flag.BoolVar(&F.EnableL4ILBMixedProtocol, "enable-l4ilb-mixed-protocol", false, "Support L4 ILB services with both TCP and UDP ports, by creating one forwarding rule per protocol which share the IP address and the backend service.")
+	flag.DurationVar(&F.BackendServiceCacheVerifyPeriod, "backend-service-cache-verify-period", 0, "If set, backend services which are in sync are cached and only fetched again from GCE once their cached copy is older than this period. Zero disables the cache.")
+	flag.DurationVar(&F.StateHandoffPeriod, "state-handoff-period", 0, "If set, the leader persists its sync state every period in a ConfigMap named after the lock object, so that a newly elected leader does not fetch backend services cached as in sync again and retries failed syncs first. Zero disables the handoff.")
 }
 
 type RateLimitSpecs struct {
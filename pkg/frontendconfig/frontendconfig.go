@@ -17,18 +17,28 @@ limitations under the License.
 package frontendconfig
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"sort"
 
 	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/ingress-gce/pkg/annotations"
 	apisfrontendconfig "k8s.io/ingress-gce/pkg/apis/frontendconfig"
 	frontendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/frontendconfig/v1beta1"
 	"k8s.io/ingress-gce/pkg/common/operator"
 	"k8s.io/ingress-gce/pkg/crd"
+	frontendconfigclient "k8s.io/ingress-gce/pkg/frontendconfig/client/clientset/versioned"
+	"k8s.io/ingress-gce/pkg/utils/patch"
 )
 
 var (
-	ErrFrontendConfigDoesNotExist = errors.New("no FrontendConfig for Ingress exists.")
+	ErrFrontendConfigDoesNotExist        = errors.New("no FrontendConfig for Ingress exists.")
+	ErrDefaultFrontendConfigDoesNotExist = errors.New("default FrontendConfig for Ingress does not exist.")
 )
 
 func CRDMeta() *crd.CRDMeta {
@@ -42,7 +52,7 @@ func CRDMeta() *crd.CRDMeta {
 			crd.NewVersion("v1beta1", "k8s.io/ingress-gce/pkg/apis/frontendconfig/v1beta1.FrontendConfig", frontendconfigv1beta1.GetOpenAPIDefinitions),
 		},
 	)
-	return meta
+	return meta.WithStatusSubresource()
 }
 
 // FrontendConfigForIngress returns the corresponding FrontendConfig for the given Ingress if one was specified.
@@ -63,3 +73,73 @@ func FrontendConfigForIngress(feConfigs []*frontendconfigv1beta1.FrontendConfig,
 	// would mean we have a bug somewhere in the operator or annotation processing.
 	return matches[0], nil
 }
+
+// FrontendConfigForIngressWithDefault returns the FrontendConfig for the given
+// Ingress like FrontendConfigForIngress, falling back to the FrontendConfig
+// named defaultName if the Ingress does not specify one. A nil defaultName
+// means there is no default.
+func FrontendConfigForIngressWithDefault(feConfigs []*frontendconfigv1beta1.FrontendConfig, ing *v1.Ingress, defaultName *types.NamespacedName) (*frontendconfigv1beta1.FrontendConfig, error) {
+	if defaultName == nil || annotations.FromIngress(ing).FrontendConfig() != "" {
+		return FrontendConfigForIngress(feConfigs, ing)
+	}
+	for _, feConfig := range feConfigs {
+		if feConfig.Namespace == defaultName.Namespace && feConfig.Name == defaultName.Name {
+			return feConfig, nil
+		}
+	}
+	return nil, ErrDefaultFrontendConfigDoesNotExist
+}
+
+// Status returns the status of feConfig given the Ingresses that reference it
+// and the result of the last sync of ing, one of them. Target proxies and SSL
+// policies are read from the status annotations of the Ingresses, and the
+// errors of the other Ingresses are kept from the current status.
+func Status(feConfig *frontendconfigv1beta1.FrontendConfig, ings []*v1.Ingress, ing *v1.Ingress, syncErr error) frontendconfigv1beta1.FrontendConfigStatus {
+	lastErrors := map[string]string{}
+	for _, ingStatus := range feConfig.Status.Ingresses {
+		lastErrors[ingStatus.Name] = ingStatus.LastError
+	}
+
+	var status frontendconfigv1beta1.FrontendConfigStatus
+	proxies := sets.NewString()
+	for _, i := range ings {
+		for _, key := range []string{annotations.TargetHttpProxyKey, annotations.TargetHttpsProxyKey} {
+			if name := i.Annotations[key]; name != "" {
+				proxies.Insert(name)
+			}
+		}
+		name := types.NamespacedName{Namespace: i.Namespace, Name: i.Name}.String()
+		ingStatus := frontendconfigv1beta1.FrontendConfigIngressStatus{
+			Name:      name,
+			SslPolicy: i.Annotations[annotations.SslPolicyKey],
+			LastError: lastErrors[name],
+		}
+		if i.Namespace == ing.Namespace && i.Name == ing.Name {
+			ingStatus.LastError = ""
+			if syncErr != nil {
+				ingStatus.LastError = syncErr.Error()
+			}
+		}
+		status.Ingresses = append(status.Ingresses, ingStatus)
+	}
+	if proxies.Len() > 0 {
+		status.TargetProxies = proxies.List()
+	}
+	sort.Slice(status.Ingresses, func(i, j int) bool {
+		return status.Ingresses[i].Name < status.Ingresses[j].Name
+	})
+	return status
+}
+
+// EnsureStatus patches the status of feConfig if it differs from status.
+func EnsureStatus(client frontendconfigclient.Interface, feConfig *frontendconfigv1beta1.FrontendConfig, status frontendconfigv1beta1.FrontendConfigStatus) error {
+	if reflect.DeepEqual(feConfig.Status, status) {
+		return nil
+	}
+	patchBytes, err := patch.MergePatchBytes(frontendconfigv1beta1.FrontendConfig{Status: feConfig.Status}, frontendconfigv1beta1.FrontendConfig{Status: status})
+	if err != nil {
+		return fmt.Errorf("failed to prepare patch bytes: %w", err)
+	}
+	_, err = client.NetworkingV1beta1().FrontendConfigs(feConfig.Namespace).Patch(context.TODO(), feConfig.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status")
+	return err
+}
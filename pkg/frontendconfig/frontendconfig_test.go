@@ -1,10 +1,17 @@
 package frontendconfig
 
 import (
+	"context"
+	"fmt"
+	"reflect"
 	"testing"
 
 	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/ingress-gce/pkg/annotations"
 	frontendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/frontendconfig/v1beta1"
+	"k8s.io/ingress-gce/pkg/frontendconfig/client/clientset/versioned/fake"
 	"k8s.io/ingress-gce/pkg/test"
 )
 
@@ -49,3 +56,160 @@ func TestFrontendConfigForIngress(t *testing.T) {
 		})
 	}
 }
+
+func TestFrontendConfigForIngressWithDefault(t *testing.T) {
+	t.Parallel()
+
+	defaultConfig := &frontendconfigv1beta1.FrontendConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "kube-system"},
+	}
+	feConfigs := []*frontendconfigv1beta1.FrontendConfig{test.FrontendConfig, defaultConfig}
+	defaultName := &types.NamespacedName{Name: "default", Namespace: "kube-system"}
+
+	testCases := []struct {
+		desc        string
+		ing         *v1.Ingress
+		defaultName *types.NamespacedName
+		expected    *frontendconfigv1beta1.FrontendConfig
+		err         error
+	}{
+		{
+			desc:     "ingress without frontend config and no default",
+			ing:      test.IngressWithoutFrontendConfig,
+			expected: nil,
+		},
+		{
+			desc:        "ingress without frontend config uses the default",
+			ing:         test.IngressWithoutFrontendConfig,
+			defaultName: defaultName,
+			expected:    defaultConfig,
+		},
+		{
+			desc:        "ingress frontend config takes precedence over the default",
+			ing:         test.IngressWithFrontendConfig,
+			defaultName: defaultName,
+			expected:    test.FrontendConfig,
+		},
+		{
+			desc:        "missing ingress frontend config does not fall back to the default",
+			ing:         test.IngressWithOtherFrontendConfig,
+			defaultName: defaultName,
+			err:         ErrFrontendConfigDoesNotExist,
+		},
+		{
+			desc:        "default frontend config missing",
+			ing:         test.IngressWithoutFrontendConfig,
+			defaultName: &types.NamespacedName{Name: "missing", Namespace: "kube-system"},
+			err:         ErrDefaultFrontendConfigDoesNotExist,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			result, err := FrontendConfigForIngressWithDefault(feConfigs, tc.ing, tc.defaultName)
+			if result != tc.expected {
+				t.Errorf("Expected result to be %v, got %v", tc.expected, result)
+			}
+			if err != tc.err {
+				t.Errorf("Expected err to be %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	feConfig := test.FrontendConfig.DeepCopy()
+	feConfig.Status.Ingresses = []frontendconfigv1beta1.FrontendConfigIngressStatus{
+		{Name: "default/ing-a", LastError: "quota exceeded"},
+		{Name: "default/ing-b", LastError: "invalid certificate"},
+	}
+	ings := []*v1.Ingress{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ing-b", Annotations: map[string]string{
+			annotations.TargetHttpProxyKey:  "http-b",
+			annotations.TargetHttpsProxyKey: "https-b",
+			annotations.SslPolicyKey:        "global/sslPolicies/policy",
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ing-a", Annotations: map[string]string{
+			annotations.TargetHttpProxyKey: "http-a",
+		}}},
+		// Ingresses that have not been synced yet have no proxies.
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ing-c"}},
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		ing     *v1.Ingress
+		syncErr error
+		want    []frontendconfigv1beta1.FrontendConfigIngressStatus
+	}{
+		{
+			desc: "successful sync clears the error of the Ingress only",
+			ing:  ings[1],
+			want: []frontendconfigv1beta1.FrontendConfigIngressStatus{
+				{Name: "default/ing-a"},
+				{Name: "default/ing-b", SslPolicy: "global/sslPolicies/policy", LastError: "invalid certificate"},
+				{Name: "default/ing-c"},
+			},
+		},
+		{
+			desc:    "failed sync sets the error of the Ingress only",
+			ing:     ings[2],
+			syncErr: fmt.Errorf("backend not found"),
+			want: []frontendconfigv1beta1.FrontendConfigIngressStatus{
+				{Name: "default/ing-a", LastError: "quota exceeded"},
+				{Name: "default/ing-b", SslPolicy: "global/sslPolicies/policy", LastError: "invalid certificate"},
+				{Name: "default/ing-c", LastError: "backend not found"},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			status := Status(feConfig, ings, tc.ing, tc.syncErr)
+			want := frontendconfigv1beta1.FrontendConfigStatus{
+				TargetProxies: []string{"http-a", "http-b", "https-b"},
+				Ingresses:     tc.want,
+			}
+			if !reflect.DeepEqual(status, want) {
+				t.Errorf("Status() = %+v, want %+v", status, want)
+			}
+		})
+	}
+
+	// Ingresses which no longer reference the FrontendConfig are dropped.
+	if status := Status(feConfig, nil, ings[0], nil); !reflect.DeepEqual(status, frontendconfigv1beta1.FrontendConfigStatus{}) {
+		t.Errorf("Status() = %+v, want empty status", status)
+	}
+}
+
+func TestEnsureStatus(t *testing.T) {
+	t.Parallel()
+
+	feConfig := test.FrontendConfig.DeepCopy()
+	feConfig.Status.Ingresses = []frontendconfigv1beta1.FrontendConfigIngressStatus{{Name: "default/ing", LastError: "quota exceeded"}}
+	client := fake.NewSimpleClientset(feConfig)
+	status := frontendconfigv1beta1.FrontendConfigStatus{TargetProxies: []string{"proxy"}}
+
+	if err := EnsureStatus(client, feConfig, status); err != nil {
+		t.Fatalf("EnsureStatus() = %v", err)
+	}
+	got, err := client.NetworkingV1beta1().FrontendConfigs(feConfig.Namespace).Get(context.TODO(), feConfig.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if !reflect.DeepEqual(got.Status, status) {
+		t.Errorf("Status = %+v, want %+v", got.Status, status)
+	}
+
+	// An unchanged status is not patched.
+	client.ClearActions()
+	if err := EnsureStatus(client, got, status); err != nil {
+		t.Fatalf("EnsureStatus() = %v", err)
+	}
+	if actions := client.Actions(); len(actions) != 0 {
+		t.Errorf("EnsureStatus() with unchanged status made %d calls, want 0", len(actions))
+	}
+}
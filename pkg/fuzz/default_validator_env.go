@@ -41,14 +41,19 @@ type DefaultValidatorEnv struct {
 	fc    *fcclient.Clientset
 	gce   cloud.Cloud
 	namer *namer.Namer
+	// region and network of the cluster, used to resolve regional
+	// resources.
+	region  string
+	network string
 	// feNamerFactory is frontend namer factory that creates frontend naming policy
 	// for given ingress/ load-balancer.
 	feNamerFactory namer.IngressFrontendNamerFactory
 }
 
-// NewDefaultValidatorEnv returns a new ValidatorEnv.
-func NewDefaultValidatorEnv(config *rest.Config, ns string, gce cloud.Cloud) (ValidatorEnv, error) {
-	ret := &DefaultValidatorEnv{ns: ns, gce: gce}
+// NewDefaultValidatorEnv returns a new ValidatorEnv. region and network are
+// only required for validating regional Ingress classes.
+func NewDefaultValidatorEnv(config *rest.Config, ns string, gce cloud.Cloud, region, network string) (ValidatorEnv, error) {
+	ret := &DefaultValidatorEnv{ns: ns, gce: gce, region: region, network: network}
 	var err error
 	if ret.k8s, err = kubernetes.NewForConfig(config); err != nil {
 		return nil, err
@@ -124,3 +129,13 @@ func (e *DefaultValidatorEnv) BackendNamer() namer.BackendNamer {
 func (e *DefaultValidatorEnv) FrontendNamerFactory() namer.IngressFrontendNamerFactory {
 	return e.feNamerFactory
 }
+
+// Region implements ValidatorEnv.
+func (e *DefaultValidatorEnv) Region() string {
+	return e.region
+}
+
+// Network implements ValidatorEnv.
+func (e *DefaultValidatorEnv) Network() string {
+	return e.network
+}
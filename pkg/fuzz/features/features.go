@@ -21,7 +21,8 @@ package features
 
 import "k8s.io/ingress-gce/pkg/fuzz"
 
-// All is the set of all features.
+// All is the set of all built-in features. They are registered with
+// fuzz.RegisterFeature when this package is imported.
 var All = []fuzz.Feature{
 	AllowHTTP,
 	PresharedCert,
@@ -33,4 +34,12 @@ var All = []fuzz.Feature{
 	AppProtocol,
 	ILB,
 	HTTPSRedirects,
+	IPv6,
+	WebSocket,
+}
+
+func init() {
+	for _, f := range All {
+		fuzz.RegisterFeature(f)
+	}
 }
@@ -17,11 +17,15 @@ limitations under the License.
 package features
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
+	"net/url"
 
+	"cloud.google.com/go/compute/metadata"
 	v1 "k8s.io/api/networking/v1"
 	"k8s.io/ingress-gce/pkg/annotations"
+	backendconfig "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
 	"k8s.io/ingress-gce/pkg/fuzz"
 	"k8s.io/klog"
 )
@@ -29,12 +33,38 @@ import (
 // IAP is a feature in BackendConfig that supports using GCP Identity-Aware Proxy (IAP).
 var IAP = &IAPFeature{}
 
+// iapSignInHost is the host which IAP redirects unauthenticated requests to.
+const iapSignInHost = "accounts.google.com"
+
 // IAPFeature implements the associated feature.
-type IAPFeature struct{}
+type IAPFeature struct {
+	// IDToken returns an OpenID Connect ID token, for the given audience, of
+	// a service account which is allowed by IAP. The audience is the OAuth
+	// client ID of the backend. If set, the validator checks that requests
+	// carrying the token pass IAP.
+	IDToken func(audience string) (string, error)
+}
 
 // NewValidator implements fuzz.Feature.
-func (IAPFeature) NewValidator() fuzz.FeatureValidator {
-	return &iapValidator{}
+func (f IAPFeature) NewValidator() fuzz.FeatureValidator {
+	return &iapValidator{
+		idToken: f.IDToken,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// MetadataIDToken returns an ID token for the audience of the service account
+// of the GCE instance or GKE workload it runs on, from the metadata server. It
+// can be used as IAPFeature.IDToken.
+func MetadataIDToken(audience string) (string, error) {
+	return metadata.Get("instance/service-accounts/default/identity?format=full&audience=" + url.QueryEscape(audience))
 }
 
 // Name implements fuzz.Feature.
@@ -46,8 +76,10 @@ func (*IAPFeature) Name() string {
 type iapValidator struct {
 	fuzz.NullValidator
 
-	env fuzz.ValidatorEnv
-	ing *v1.Ingress
+	env     fuzz.ValidatorEnv
+	ing     *v1.Ingress
+	idToken func(audience string) (string, error)
+	client  *http.Client
 }
 
 // Name implements fuzz.FeatureValidator.
@@ -79,7 +111,7 @@ func (v *iapValidator) CheckResponse(host, path string, resp *http.Response, bod
 		iapEnabled = true
 	}
 	// If IAP is turned on, verify response header contains "x-goog-iap-generated-response" key
-	// and that the response code was a 302.
+	// and that the response code was a 302 to the Google sign-in page.
 	if iapEnabled {
 		if resp.StatusCode != http.StatusFound {
 			klog.V(2).Infof("The response was %v", resp)
@@ -88,6 +120,18 @@ func (v *iapValidator) CheckResponse(host, path string, resp *http.Response, bod
 		if resp.Header.Get("x-goog-iap-generated-response") == "" {
 			return fuzz.CheckResponseContinue, fmt.Errorf("IAP is turned on but response w/ header %v did not contain IAP header", resp.Header)
 		}
+		location, err := resp.Location()
+		if err != nil {
+			return fuzz.CheckResponseContinue, fmt.Errorf("IAP is turned on but response w/ header %v did not redirect: %v", resp.Header, err)
+		}
+		if location.Host != iapSignInHost {
+			return fuzz.CheckResponseContinue, fmt.Errorf("IAP is turned on but response redirected to %q, want host %q", location, iapSignInHost)
+		}
+		if v.idToken != nil {
+			if err := v.checkAllowed(resp.Request, backendConfig.Spec.Iap.OAuthClientCredentials); err != nil {
+				return fuzz.CheckResponseContinue, err
+			}
+		}
 		return fuzz.CheckResponseSkip, nil
 	}
 	// If IAP is turned off, verify that the response code was not 302.
@@ -96,3 +140,26 @@ func (v *iapValidator) CheckResponse(host, path string, resp *http.Response, bod
 	}
 	return fuzz.CheckResponseContinue, nil
 }
+
+// checkAllowed sends the request again with the ID token of the allowed
+// service account and checks that it passes IAP.
+func (v *iapValidator) checkAllowed(req *http.Request, creds *backendconfig.OAuthClientCredentials) error {
+	if creds == nil || creds.ClientID == "" {
+		return fmt.Errorf("IAP is turned on but the BackendConfig does not set the OAuth client ID, which is the audience of the ID token")
+	}
+	token, err := v.idToken(creds.ClientID)
+	if err != nil {
+		return fmt.Errorf("error getting ID token for audience %q: %v", creds.ClientID, err)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("x-goog-iap-generated-response") != "" || resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("IAP is turned on but request w/ ID token of allowed service account returned %d w/ header %v, want 200 from the backend", resp.StatusCode, resp.Header)
+	}
+	return nil
+}
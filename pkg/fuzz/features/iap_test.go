@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/ingress-gce/pkg/annotations"
+	backendconfig "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
+	"k8s.io/ingress-gce/pkg/fuzz"
+)
+
+// iapServer returns a server which, like IAP, redirects the requests without
+// the given ID token to the Google sign-in page.
+func iapServer(redirectHost, allowedToken string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if allowedToken != "" && r.Header.Get("Authorization") == "Bearer "+allowedToken {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("x-goog-iap-generated-response", "true")
+		w.Header().Set("Location", "https://"+redirectHost+"/o/oauth2/v2/auth")
+		w.WriteHeader(http.StatusFound)
+	}))
+}
+
+func TestIAPCheckResponse(t *testing.T) {
+	t.Parallel()
+
+	const clientID = "client-id"
+	for _, tc := range []struct {
+		desc         string
+		redirectHost string
+		allowedToken string
+		idToken      func(audience string) (string, error)
+		wantErr      bool
+	}{
+		{
+			desc:         "redirect to sign-in",
+			redirectHost: iapSignInHost,
+		},
+		{
+			desc:         "redirect elsewhere",
+			redirectHost: "example.com",
+			wantErr:      true,
+		},
+		{
+			desc:         "allowed service account passes",
+			redirectHost: iapSignInHost,
+			allowedToken: "token-" + clientID,
+			idToken:      func(audience string) (string, error) { return "token-" + audience, nil },
+		},
+		{
+			desc:         "service account not allowed",
+			redirectHost: iapSignInHost,
+			allowedToken: "other-token",
+			idToken:      func(audience string) (string, error) { return "token-" + audience, nil },
+			wantErr:      true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			server := iapServer(tc.redirectHost, tc.allowedToken)
+			defer server.Close()
+
+			svc := fuzz.NewService("svc1", "ns1", 80)
+			svc.Annotations = map[string]string{annotations.BackendConfigKey: `{"default":"bc1"}`}
+			bc := fuzz.NewBackendConfigBuilder("ns1", "bc1").SetIAPConfig(true, "").Build()
+			bc.Spec.Iap.OAuthClientCredentials.ClientID = clientID
+			env := &fuzz.MockValidatorEnv{
+				ServicesMap:       map[string]*v1.Service{"svc1": svc},
+				BackendConfigsMap: map[string]*backendconfig.BackendConfig{"bc1": bc},
+			}
+			ing := fuzz.NewIngressBuilder("ns1", "ing1", "").
+				AddPath("test.com", "/", "svc1", networkingv1.ServiceBackendPort{Number: 80}).
+				Build()
+
+			v := IAPFeature{IDToken: tc.idToken}.NewValidator()
+			if err := v.ConfigureAttributes(env, ing, &fuzz.IngressValidatorAttributes{}); err != nil {
+				t.Fatalf("ConfigureAttributes() = %v", err)
+			}
+			req, err := http.NewRequest("GET", server.URL+"/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := v.(*iapValidator).client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			action, err := v.CheckResponse("test.com", "/", resp, nil)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("CheckResponse() = %v, want error %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && action != fuzz.CheckResponseSkip {
+				t.Errorf("CheckResponse() = %v, want CheckResponseSkip", action)
+			}
+		})
+	}
+}
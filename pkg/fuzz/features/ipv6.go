@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/ingress-gce/pkg/annotations"
+	"k8s.io/ingress-gce/pkg/fuzz"
+)
+
+// IPv6 is the "kubernetes.io/ingress.ip-version" annotation.
+var IPv6 = &IPv6Feature{}
+
+// IPv6Feature implements the associated feature.
+type IPv6Feature struct{}
+
+// NewValidator implements fuzz.Feature.
+func (*IPv6Feature) NewValidator() fuzz.FeatureValidator {
+	return &ipv6Validator{}
+}
+
+// Name implements fuzz.Feature.
+func (*IPv6Feature) Name() string {
+	return "IPv6"
+}
+
+// ipv6Validator checks that an IPv6 Ingress only reports IPv6 addresses.
+type ipv6Validator struct {
+	fuzz.NullValidator
+
+	ing *v1.Ingress
+}
+
+// Name implements fuzz.FeatureValidator.
+func (*ipv6Validator) Name() string {
+	return "IPv6"
+}
+
+// ConfigureAttributes implements fuzz.FeatureValidator.
+func (v *ipv6Validator) ConfigureAttributes(env fuzz.ValidatorEnv, ing *v1.Ingress, a *fuzz.IngressValidatorAttributes) error {
+	// Capture the Ingress for use later in CheckResponse.
+	v.ing = ing
+	return nil
+}
+
+// CheckResponse implements fuzz.FeatureValidator.
+func (v *ipv6Validator) CheckResponse(host, path string, resp *http.Response, body []byte) (fuzz.CheckResponseAction, error) {
+	version, err := annotations.FromIngress(v.ing).IPVersion()
+	if err != nil {
+		return fuzz.CheckResponseContinue, err
+	}
+	if version != annotations.IPv6Version {
+		return fuzz.CheckResponseContinue, nil
+	}
+	for _, status := range v.ing.Status.LoadBalancer.Ingress {
+		if ip := net.ParseIP(status.IP); ip == nil || ip.To4() != nil {
+			return fuzz.CheckResponseContinue, fmt.Errorf("got IP %q in the status of an IPv6 Ingress, want only IPv6 addresses", status.IP)
+		}
+	}
+	return fuzz.CheckResponseContinue, nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/ingress-gce/pkg/annotations"
+	"k8s.io/ingress-gce/pkg/fuzz"
+)
+
+func TestIPv6Feature(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		desc      string
+		ipVersion string
+		ips       []string
+		wantErr   bool
+	}{
+		{desc: "IPv4 Ingress", ips: []string{"1.2.3.4"}},
+		{desc: "IPv6 Ingress", ipVersion: annotations.IPv6Version, ips: []string{"2600:1901:0:1234::"}},
+		{desc: "IPv6 Ingress with IPv4 status", ipVersion: annotations.IPv6Version, ips: []string{"2600:1901:0:1234::", "1.2.3.4"}, wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ing := fuzz.NewIngressBuilder("ns1", "ing1", "").Build()
+			ing.Annotations = map[string]string{}
+			if tc.ipVersion != "" {
+				ing.Annotations[annotations.IPVersionKey] = tc.ipVersion
+			}
+			for _, ip := range tc.ips {
+				ing.Status.LoadBalancer.Ingress = append(ing.Status.LoadBalancer.Ingress, apiv1.LoadBalancerIngress{IP: ip})
+			}
+
+			v := IPv6.NewValidator()
+			if err := v.ConfigureAttributes(&fuzz.MockValidatorEnv{}, ing, &fuzz.IngressValidatorAttributes{}); err != nil {
+				t.Fatalf("v.ConfigureAttributes(%+v) = %v, want nil", ing, err)
+			}
+			if _, err := v.CheckResponse("", "/", nil, nil); (err != nil) != tc.wantErr {
+				t.Errorf("v.CheckResponse() = %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/ingress-gce/pkg/annotations"
+	"k8s.io/ingress-gce/pkg/fuzz"
+	"k8s.io/klog"
+)
+
+const (
+	// webSocketGUID is used to compute Sec-WebSocket-Accept (RFC 6455).
+	webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// maxWebSocketTimeout is the longest backend timeout that is verified.
+	// Longer timeouts would make the check impractically slow.
+	maxWebSocketTimeout = 5 * time.Minute
+	// webSocketTimeoutSlack is the tolerance around the backend timeout
+	// within which the load balancer is expected to close the connection.
+	webSocketTimeoutSlack = 10 * time.Second
+	webSocketDialTimeout  = 30 * time.Second
+)
+
+// WebSocket checks that WebSocket connections through the load balancer are
+// closed when the timeout of the backend service expires, and not before.
+var WebSocket = &WebSocketFeature{}
+
+// WebSocketFeature implements the associated feature.
+type WebSocketFeature struct{}
+
+// NewValidator implements fuzz.Feature.
+func (*WebSocketFeature) NewValidator() fuzz.FeatureValidator {
+	return &webSocketValidator{checked: map[string]bool{}}
+}
+
+// Name implements fuzz.Feature.
+func (*WebSocketFeature) Name() string {
+	return "WebSocket"
+}
+
+// webSocketValidator is a validator for the WebSocket feature.
+type webSocketValidator struct {
+	fuzz.NullValidator
+
+	env fuzz.ValidatorEnv
+	ing *v1.Ingress
+
+	// checked records the host and paths for which the WebSocket lifetime
+	// was verified, as each check holds a connection for the whole timeout.
+	lock    sync.Mutex
+	checked map[string]bool
+}
+
+// Name implements fuzz.FeatureValidator.
+func (*webSocketValidator) Name() string {
+	return "WebSocket"
+}
+
+// ConfigureAttributes implements fuzz.FeatureValidator.
+func (v *webSocketValidator) ConfigureAttributes(env fuzz.ValidatorEnv, ing *v1.Ingress, a *fuzz.IngressValidatorAttributes) error {
+	v.env = env
+	v.ing = ing
+	return nil
+}
+
+// CheckResponse implements fuzz.FeatureValidator.
+func (v *webSocketValidator) CheckResponse(host, path string, resp *http.Response, body []byte) (fuzz.CheckResponseAction, error) {
+	// Only check backends which are serving.
+	if resp.StatusCode != http.StatusOK || resp.Request == nil {
+		return fuzz.CheckResponseContinue, nil
+	}
+	backendConfig, err := fuzz.BackendConfigForPath(host, path, v.ing, v.env)
+	if err != nil {
+		if err == annotations.ErrBackendConfigAnnotationMissing {
+			return fuzz.CheckResponseContinue, nil
+		}
+		return fuzz.CheckResponseContinue, err
+	}
+	if backendConfig.Spec.TimeoutSec == nil {
+		return fuzz.CheckResponseContinue, nil
+	}
+	timeout := time.Duration(*backendConfig.Spec.TimeoutSec) * time.Second
+	if timeout > maxWebSocketTimeout {
+		klog.V(2).Infof("Skipping WebSocket check of %s%s, timeout %v is longer than %v", host, path, timeout, maxWebSocketTimeout)
+		return fuzz.CheckResponseContinue, nil
+	}
+
+	key := resp.Request.URL.Scheme + "://" + host + path
+	v.lock.Lock()
+	done := v.checked[key]
+	v.lock.Unlock()
+	if done {
+		return fuzz.CheckResponseContinue, nil
+	}
+	if err := checkWebSocketLifetime(resp.Request.URL, host, timeout, webSocketTimeoutSlack); err != nil {
+		return fuzz.CheckResponseContinue, err
+	}
+	v.lock.Lock()
+	v.checked[key] = true
+	v.lock.Unlock()
+	return fuzz.CheckResponseContinue, nil
+}
+
+// checkWebSocketLifetime opens a WebSocket to u and verifies that it stays
+// open for timeout-slack and is closed within timeout+slack.
+func checkWebSocketLifetime(u *url.URL, host string, timeout, slack time.Duration) error {
+	start := time.Now()
+	conn, br, err := dialWebSocket(u, host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	closed := func(deadline time.Time) (bool, error) {
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			return false, err
+		}
+		// Frames sent by the server, e.g. a close frame, are ignored. Only
+		// the connection being closed matters.
+		for {
+			if _, err := br.ReadByte(); err != nil {
+				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+					return false, nil
+				}
+				return true, nil
+			}
+		}
+	}
+
+	if c, err := closed(start.Add(timeout - slack)); err != nil {
+		return err
+	} else if c {
+		return fmt.Errorf("WebSocket to %s was closed after %v, before the backend timeout of %v", u, time.Since(start), timeout)
+	}
+	if c, err := closed(start.Add(timeout + slack)); err != nil {
+		return err
+	} else if !c {
+		return fmt.Errorf("WebSocket to %s was still open %v after the backend timeout of %v", u, time.Since(start)-timeout, timeout)
+	}
+	klog.V(2).Infof("WebSocket to %s closed after %v, backend timeout %v", u, time.Since(start), timeout)
+	return nil
+}
+
+// dialWebSocket opens a WebSocket to u with the given Host header. The
+// returned reader must be used to read from the connection.
+func dialWebSocket(u *url.URL, host string) (net.Conn, *bufio.Reader, error) {
+	addr := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+	dialer := &net.Dialer{Timeout: webSocketDialTimeout}
+	var conn net.Conn
+	var err error
+	if u.Scheme == "https" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{InsecureSkipVerify: true, ServerName: host})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %v", addr, err)
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if host != "" {
+		req.Host = host
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	conn.SetDeadline(time.Now().Add(webSocketDialTimeout))
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to send WebSocket handshake to %s: %v", u, err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to read WebSocket handshake from %s: %v", u, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, nil, fmt.Errorf("WebSocket handshake to %s: got status %d, want %d", u, resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+	if got, want := resp.Header.Get("Sec-WebSocket-Accept"), webSocketAccept(key); got != want {
+		conn.Close()
+		return nil, nil, fmt.Errorf("WebSocket handshake to %s: got Sec-WebSocket-Accept %q, want %q", u, got, want)
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, br, nil
+}
+
+// webSocketAccept returns the Sec-WebSocket-Accept value for key.
+func webSocketAccept(key string) string {
+	h := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
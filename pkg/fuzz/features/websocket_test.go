@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestWebSocketAccept(t *testing.T) {
+	t.Parallel()
+	// Example from RFC 6455.
+	if got, want := webSocketAccept("dGhlIHNhbXBsZSBub25jZQ=="), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Errorf("webSocketAccept() = %q, want %q", got, want)
+	}
+}
+
+// webSocketServer returns a server which accepts WebSockets and closes them
+// after closeAfter, like a load balancer enforcing a backend timeout.
+func webSocketServer(closeAfter time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", webSocketAccept(r.Header.Get("Sec-WebSocket-Key")))
+		rw.Flush()
+		time.Sleep(closeAfter)
+	}))
+}
+
+func TestCheckWebSocketLifetime(t *testing.T) {
+	t.Parallel()
+	const (
+		timeout = 1 * time.Second
+		slack   = 400 * time.Millisecond
+	)
+	for _, tc := range []struct {
+		desc       string
+		closeAfter time.Duration
+		wantErr    bool
+	}{
+		{desc: "closed at the timeout", closeAfter: timeout},
+		{desc: "closed before the timeout", closeAfter: 200 * time.Millisecond, wantErr: true},
+		{desc: "open after the timeout", closeAfter: 3 * time.Second, wantErr: true},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			server := webSocketServer(tc.closeAfter)
+			defer server.Close()
+			u, err := url.Parse(server.URL + "/ws")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = checkWebSocketLifetime(u, "example.com", timeout, slack)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("checkWebSocketLifetime() = %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
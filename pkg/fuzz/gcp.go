@@ -27,6 +27,7 @@ import (
 	computebeta "google.golang.org/api/compute/v0.beta"
 	compute "google.golang.org/api/compute/v1"
 	"google.golang.org/api/googleapi"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/klog"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
@@ -312,7 +313,12 @@ func (g *GCLB) CheckResourceDeletionByProtocol(ctx context.Context, c cloud.Clou
 	switch protocol {
 	case HttpProtocol:
 		for k := range g.TargetHTTPProxy {
-			_, err := c.TargetHttpProxies().Get(ctx, &k)
+			var err error
+			if k.Region != "" {
+				_, err = c.BetaRegionTargetHttpProxies().Get(ctx, &k)
+			} else {
+				_, err = c.TargetHttpProxies().Get(ctx, &k)
+			}
 			if err != nil {
 				if err.(*googleapi.Error) == nil || err.(*googleapi.Error).Code != http.StatusNotFound {
 					return fmt.Errorf("TargetHTTPProxy %s is not deleted/error to get: %s", k.Name, err)
@@ -323,7 +329,12 @@ func (g *GCLB) CheckResourceDeletionByProtocol(ctx context.Context, c cloud.Clou
 		}
 	case HttpsProtocol:
 		for k := range g.TargetHTTPSProxy {
-			_, err := c.TargetHttpsProxies().Get(ctx, &k)
+			var err error
+			if k.Region != "" {
+				_, err = c.BetaRegionTargetHttpsProxies().Get(ctx, &k)
+			} else {
+				_, err = c.TargetHttpsProxies().Get(ctx, &k)
+			}
 			if err != nil {
 				if err.(*googleapi.Error) == nil || err.(*googleapi.Error).Code != http.StatusNotFound {
 					return fmt.Errorf("TargetHTTPSProxy %s is not deleted/error to get: %s", k.Name, err)
@@ -394,7 +405,12 @@ func (g *GCLB) CheckNEGDeletion(ctx context.Context, c cloud.Cloud, options *GCL
 func (g *GCLB) CheckRedirectUrlMapDeletion(ctx context.Context, c cloud.Cloud) error {
 	for k := range g.URLMap {
 		if strings.Contains(k.Name, "-rm") {
-			_, err := c.UrlMaps().Get(ctx, &k)
+			var err error
+			if k.Region != "" {
+				_, err = c.BetaRegionUrlMaps().Get(ctx, &k)
+			} else {
+				_, err = c.UrlMaps().Get(ctx, &k)
+			}
 			if err != nil {
 				if err.(*googleapi.Error) == nil || err.(*googleapi.Error).Code != http.StatusNotFound {
 					return err
@@ -432,6 +448,18 @@ type GCLBForVIPParams struct {
 	Validators []FeatureValidator
 }
 
+// GCLBForVIPParamsForIngress returns the parameters for retrieving the GCLB
+// of the given Ingress. Region and Network are filled from the environment
+// if the Ingress is backed by regional resources.
+func GCLBForVIPParamsForIngress(env ValidatorEnv, ing *networkingv1.Ingress, vip string, validators []FeatureValidator) *GCLBForVIPParams {
+	params := &GCLBForVIPParams{VIP: vip, Validators: validators}
+	if utils.IsRegionalIngress(ing) {
+		params.Region = env.Region()
+		params.Network = env.Network()
+	}
+	return params
+}
+
 // GCLBForVIP retrieves all of the resources associated with the GCLB for a given VIP.
 func GCLBForVIP(ctx context.Context, c cloud.Cloud, params *GCLBForVIPParams) (*GCLB, error) {
 	gclb := NewGCLB(params.VIP)
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fuzz
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	registryLock            sync.Mutex
+	registeredFeatures      []Feature
+	registeredWhiteboxTests []WhiteboxTest
+)
+
+// RegisterFeature adds the feature to the set of features validated by
+// default. It is intended to be called from the init() of the package
+// implementing the feature, so that test suites can validate their own
+// annotations and CRDs without modifying this package. RegisterFeature panics
+// if a feature with the same name is already registered.
+func RegisterFeature(f Feature) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	for _, existing := range registeredFeatures {
+		if existing.Name() == f.Name() {
+			panic(fmt.Sprintf("fuzz: feature %q registered twice", f.Name()))
+		}
+	}
+	registeredFeatures = append(registeredFeatures, f)
+}
+
+// RegisteredFeatures returns the registered features, in the order in which
+// they were registered.
+func RegisteredFeatures() []Feature {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	return append([]Feature(nil), registeredFeatures...)
+}
+
+// RegisterWhiteboxTest adds the test to the set of whitebox tests run by
+// default. RegisterWhiteboxTest panics if a test with the same name is already
+// registered.
+func RegisterWhiteboxTest(t WhiteboxTest) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	for _, existing := range registeredWhiteboxTests {
+		if existing.Name() == t.Name() {
+			panic(fmt.Sprintf("fuzz: whitebox test %q registered twice", t.Name()))
+		}
+	}
+	registeredWhiteboxTests = append(registeredWhiteboxTests, t)
+}
+
+// RegisteredWhiteboxTests returns the registered whitebox tests, in the order
+// in which they were registered.
+func RegisteredWhiteboxTests() []WhiteboxTest {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	return append([]WhiteboxTest(nil), registeredWhiteboxTests...)
+}
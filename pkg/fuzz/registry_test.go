@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fuzz
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/networking/v1"
+	frontendconfig "k8s.io/ingress-gce/pkg/apis/frontendconfig/v1beta1"
+)
+
+type namedFeature string
+
+func (f namedFeature) Name() string { return string(f) }
+
+func (f namedFeature) NewValidator() FeatureValidator { return nil }
+
+type namedWhiteboxTest string
+
+func (t namedWhiteboxTest) Name() string { return string(t) }
+
+func (t namedWhiteboxTest) Test(*v1.Ingress, *frontendconfig.FrontendConfig, *GCLB) error { return nil }
+
+func TestRegistry(t *testing.T) {
+	defer func(fs []Feature, ws []WhiteboxTest) {
+		registeredFeatures, registeredWhiteboxTests = fs, ws
+	}(registeredFeatures, registeredWhiteboxTests)
+	registeredFeatures, registeredWhiteboxTests = nil, nil
+
+	RegisterFeature(namedFeature("a"))
+	RegisterFeature(namedFeature("b"))
+	RegisterWhiteboxTest(namedWhiteboxTest("c"))
+
+	var gotFeatures []string
+	for _, f := range RegisteredFeatures() {
+		gotFeatures = append(gotFeatures, f.Name())
+	}
+	if len(gotFeatures) != 2 || gotFeatures[0] != "a" || gotFeatures[1] != "b" {
+		t.Errorf("RegisteredFeatures() = %v, want [a b]", gotFeatures)
+	}
+	if got := RegisteredWhiteboxTests(); len(got) != 1 || got[0].Name() != "c" {
+		t.Errorf("RegisteredWhiteboxTests() = %v, want [c]", got)
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		register func()
+	}{
+		{desc: "feature", register: func() { RegisterFeature(namedFeature("a")) }},
+		{desc: "whitebox test", register: func() { RegisterWhiteboxTest(namedWhiteboxTest("c")) }},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("registering a duplicate %s did not panic", tc.desc)
+				}
+			}()
+			tc.register()
+		})
+	}
+}
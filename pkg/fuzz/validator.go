@@ -21,6 +21,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
@@ -50,6 +51,12 @@ type ValidatorEnv interface {
 	Cloud() cloud.Cloud
 	BackendNamer() namer.BackendNamer
 	FrontendNamerFactory() namer.IngressFrontendNamerFactory
+	// Region of the cluster. Used to resolve resources for regional
+	// Ingress classes.
+	Region() string
+	// Network of the cluster. Used to resolve resources for regional
+	// Ingress classes.
+	Network() string
 }
 
 // MockValidatorEnv is an environment that is used for mock testing.
@@ -59,6 +66,8 @@ type MockValidatorEnv struct {
 	ServicesMap          map[string]*v1.Service
 	MockCloud            *cloud.MockGCE
 	IngressNamer         *namer.Namer
+	MockRegion           string
+	MockNetwork          string
 	frontendNamerFactory namer.IngressFrontendNamerFactory
 }
 
@@ -92,6 +101,16 @@ func (e *MockValidatorEnv) FrontendNamerFactory() namer.IngressFrontendNamerFact
 	return e.frontendNamerFactory
 }
 
+// Region implements ValidatorEnv.
+func (e *MockValidatorEnv) Region() string {
+	return e.MockRegion
+}
+
+// Network implements ValidatorEnv.
+func (e *MockValidatorEnv) Network() string {
+	return e.MockNetwork
+}
+
 // IngressValidatorAttributes are derived attributes governing how the Ingress
 // is validated. Features will use this structure to express changes to the
 // standard checks by modifying this struct.
@@ -369,7 +388,7 @@ func (v *IngressValidator) checkPath(ctx context.Context, scheme, host, path str
 	}
 	vip := *v.Vip()
 
-	url := fmt.Sprintf("%s://%s%s%s", scheme, vip, portStr(v.attribs, scheme), path)
+	url := fmt.Sprintf("%s://%s%s%s", scheme, urlHost(vip), portStr(v.attribs, scheme), path)
 	klog.V(3).Infof("Checking Ingress %s/%s url=%q", v.ing.Namespace, v.ing.Name, url)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -440,3 +459,12 @@ func portStr(a *IngressValidatorAttributes, scheme string) string {
 	}
 	return ""
 }
+
+// urlHost returns the vip in the form used as the host of a URL. IPv6
+// addresses are enclosed in brackets.
+func urlHost(vip string) string {
+	if ip := net.ParseIP(vip); ip != nil && ip.To4() == nil {
+		return "[" + vip + "]"
+	}
+	return vip
+}
@@ -453,3 +453,20 @@ func TestPortStr(t *testing.T) {
 		})
 	}
 }
+
+func TestURLHost(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		vip  string
+		want string
+	}{
+		{vip: "1.2.3.4", want: "1.2.3.4"},
+		{vip: "2600:1901:0:1234::", want: "[2600:1901:0:1234::]"},
+		{vip: "my-host", want: "my-host"},
+	} {
+		if got := urlHost(tc.vip); got != tc.want {
+			t.Errorf("urlHost(%q) = %q, want %q", tc.vip, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package whitebox
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	v1 "k8s.io/api/networking/v1"
+	frontendconfig "k8s.io/ingress-gce/pkg/apis/frontendconfig/v1beta1"
+	"k8s.io/ingress-gce/pkg/fuzz"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+// Implements a whitebox test to check that the frontend and backend resources
+// of the GCLB are all global, or all in the same region for regional Ingress
+// classes.
+type resourceScopeTest struct {
+}
+
+// Name implements WhiteboxTest.
+func (t *resourceScopeTest) Name() string {
+	return "ResourceScopeTest"
+}
+
+// Test implements WhiteboxTest.
+func (t *resourceScopeTest) Test(ing *v1.Ingress, fc *frontendconfig.FrontendConfig, gclb *fuzz.GCLB) error {
+	var keys []meta.Key
+	for k := range gclb.ForwardingRule {
+		keys = append(keys, k)
+	}
+	for k := range gclb.TargetHTTPProxy {
+		keys = append(keys, k)
+	}
+	for k := range gclb.TargetHTTPSProxy {
+		keys = append(keys, k)
+	}
+	for k := range gclb.URLMap {
+		keys = append(keys, k)
+	}
+	for k := range gclb.BackendService {
+		keys = append(keys, k)
+	}
+
+	regional := utils.IsRegionalIngress(ing)
+	region := ""
+	for _, k := range keys {
+		if !regional {
+			if k.Type() != meta.Global {
+				return fmt.Errorf("expected %s to be global for Ingress %s/%s", k.String(), ing.Namespace, ing.Name)
+			}
+			continue
+		}
+		if k.Type() != meta.Regional {
+			return fmt.Errorf("expected %s to be regional for Ingress %s/%s", k.String(), ing.Namespace, ing.Name)
+		}
+		if region == "" {
+			region = k.Region
+		}
+		if k.Region != region {
+			return fmt.Errorf("expected %s to be in region %q", k.String(), region)
+		}
+	}
+	return nil
+}
@@ -18,10 +18,18 @@ package whitebox
 
 import "k8s.io/ingress-gce/pkg/fuzz"
 
-// AllTests is the set of all whitebox tests.
+// AllTests is the set of all built-in whitebox tests. They are registered with
+// fuzz.RegisterWhiteboxTest when this package is imported.
 var AllTests = []fuzz.WhiteboxTest{
 	&numBackendServicesTest{},
 	&numForwardingRulesTest{},
 	&numTargetProxiesTest{},
 	&redirectURLMapTest{},
+	&resourceScopeTest{},
+}
+
+func init() {
+	for _, t := range AllTests {
+		fuzz.RegisterWhiteboxTest(t)
+	}
 }
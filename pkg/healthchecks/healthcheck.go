@@ -18,13 +18,21 @@ package healthchecks
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
 	"k8s.io/ingress-gce/pkg/translator"
+	"k8s.io/ingress-gce/pkg/utils"
 )
 
+// healthCheckFields are the fields of a health check which always follow the
+// service port. The other fields are preserved if they were changed outside
+// of the controller, unless they are set by the BackendConfig.
+var healthCheckFields = []utils.FieldSpec{
+	{Path: "Type"},
+	{Path: "PortSpecification"},
+}
+
 // fieldDiffs encapsulate which fields are different between health checks.
 type fieldDiffs struct {
 	f []string
@@ -36,15 +44,16 @@ func (c *fieldDiffs) add(field, oldv, newv string) {
 func (c *fieldDiffs) String() string { return strings.Join(c.f, ", ") }
 func (c *fieldDiffs) hasDiff() bool  { return len(c.f) > 0 }
 
+// addFields adds the fields in mask which differ between old and new.
+func (c *fieldDiffs) addFields(old, new *translator.HealthCheck, mask []utils.FieldSpec) {
+	for _, path := range utils.DiffFields(new, old, mask) {
+		c.add(path, fmt.Sprint(utils.FieldValue(old, path)), fmt.Sprint(utils.FieldValue(new, path)))
+	}
+}
+
 func calculateDiff(old, new *translator.HealthCheck, c *backendconfigv1.HealthCheckConfig) *fieldDiffs {
 	var changes fieldDiffs
-
-	if old.Protocol() != new.Protocol() {
-		changes.add("Protocol", string(old.Protocol()), string(new.Protocol()))
-	}
-	if old.PortSpecification != new.PortSpecification {
-		changes.add("PortSpecification", old.PortSpecification, new.PortSpecification)
-	}
+	changes.addFields(old, new, healthCheckFields)
 
 	// TODO(bowei): why don't we check Port, timeout etc.
 
@@ -53,25 +62,25 @@ func calculateDiff(old, new *translator.HealthCheck, c *backendconfigv1.HealthCh
 	}
 
 	// This code assumes that the changes wrt to `c` has been applied to `new`.
-	if c.CheckIntervalSec != nil && old.CheckIntervalSec != new.CheckIntervalSec {
-		changes.add("CheckIntervalSec", strconv.FormatInt(old.CheckIntervalSec, 10), strconv.FormatInt(new.CheckIntervalSec, 10))
-	}
-	if c.TimeoutSec != nil && old.TimeoutSec != new.TimeoutSec {
-		changes.add("TimeoutSec", strconv.FormatInt(old.TimeoutSec, 10), strconv.FormatInt(new.TimeoutSec, 10))
-	}
-	if c.HealthyThreshold != nil && old.HealthyThreshold != new.HealthyThreshold {
-		changes.add("HeathyThreshold", strconv.FormatInt(old.HealthyThreshold, 10), strconv.FormatInt(new.HealthyThreshold, 10))
-	}
-	if c.UnhealthyThreshold != nil && old.UnhealthyThreshold != new.UnhealthyThreshold {
-		changes.add("UnhealthyThreshold", strconv.FormatInt(old.UnhealthyThreshold, 10), strconv.FormatInt(new.UnhealthyThreshold, 10))
-	}
-	// c.Type is handled by Protocol above.
-	if c.RequestPath != nil && old.RequestPath != new.RequestPath {
-		changes.add("RequestPath", old.RequestPath, new.RequestPath)
-	}
-	if c.Port != nil && old.Port != new.Port {
-		changes.add("Port", strconv.FormatInt(old.Port, 10), strconv.FormatInt(new.Port, 10))
+	// c.Type and c.PortSpecification are handled by healthCheckFields.
+	var mask []utils.FieldSpec
+	for _, f := range []struct {
+		path string
+		set  bool
+	}{
+		{"CheckIntervalSec", c.CheckIntervalSec != nil},
+		{"TimeoutSec", c.TimeoutSec != nil},
+		{"HealthyThreshold", c.HealthyThreshold != nil},
+		{"UnhealthyThreshold", c.UnhealthyThreshold != nil},
+		{"RequestPath", c.RequestPath != nil},
+		{"Port", c.Port != nil},
+		{"ProxyHeader", c.ProxyHeader != nil},
+	} {
+		if f.set {
+			mask = append(mask, utils.FieldSpec{Path: f.path})
+		}
 	}
+	changes.addFields(old, new, mask)
 
 	// TODO(bowei): Host seems to be missing.
 
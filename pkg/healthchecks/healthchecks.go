@@ -17,6 +17,7 @@ limitations under the License.
 package healthchecks
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -27,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
 	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/events"
 	"k8s.io/ingress-gce/pkg/flags"
 	"k8s.io/ingress-gce/pkg/loadbalancers/features"
 	"k8s.io/ingress-gce/pkg/translator"
@@ -43,35 +45,55 @@ type HealthChecks struct {
 	// This is a workaround which allows us to not have to maintain
 	// a separate health checker for the default backend.
 	defaultBackendSvc types.NamespacedName
+	// recorders records the events of the services whose health checks
+	// are synced.
+	recorders events.RecorderProducer
 }
 
 // NewHealthChecker creates a new health checker.
 // cloud: the cloud object implementing SingleHealthCheck.
 // defaultHealthCheckPath: is the HTTP path to use for health checks.
-func NewHealthChecker(cloud HealthCheckProvider, healthCheckPath string, defaultBackendSvc types.NamespacedName) *HealthChecks {
-	return &HealthChecks{cloud, healthCheckPath, defaultBackendSvc}
+func NewHealthChecker(cloud HealthCheckProvider, healthCheckPath string, defaultBackendSvc types.NamespacedName, recorders events.RecorderProducer) *HealthChecks {
+	return &HealthChecks{
+		cloud:             cloud,
+		path:              healthCheckPath,
+		defaultBackendSvc: defaultBackendSvc,
+		recorders:         recorders,
+	}
 }
 
 // new returns a *HealthCheck with default settings and specified port/protocol
 func (h *HealthChecks) new(sp utils.ServicePort) *translator.HealthCheck {
 	var hc *translator.HealthCheck
+	protocol := sp.Protocol
 	if sp.NEGEnabled && !sp.L7ILBEnabled {
-		hc = translator.DefaultNEGHealthCheck(sp.Protocol)
+		hc = translator.DefaultNEGHealthCheck(protocol)
 	} else if sp.L7ILBEnabled {
-		hc = translator.DefaultILBHealthCheck(sp.Protocol)
+		hc = translator.DefaultILBHealthCheck(protocol)
 	} else {
-		hc = translator.DefaultHealthCheck(sp.NodePort, sp.Protocol)
+		hc = translator.DefaultHealthCheck(sp.NodePort, protocol)
 	}
 	// port is the key for retrieving existing health-check
 	// TODO: rename backend-service and health-check to not use port as key
 	hc.Name = sp.BackendName()
 	hc.Port = sp.NodePort
-	hc.RequestPath = h.pathFromSvcPort(sp)
+	if hc.IsHTTP() {
+		hc.RequestPath = h.pathFromSvcPort(sp)
+	}
 	return hc
 }
 
 // SyncServicePort implements HealthChecker.
-func (h *HealthChecks) SyncServicePort(sp *utils.ServicePort, probe *v1.Probe) (string, error) {
+//
+// The settings of the health check have a strict precedence: settings of the
+// BackendConfig override settings inferred from the readiness probe, which
+// override settings changed on the existing health check outside of the
+// controller, which override the defaults. Unless the readiness probe
+// override is enabled, the readiness probe only applies when the health check
+// is created, and settings of the existing health check take precedence over
+// it. An event listing the source of every setting is recorded on the service
+// when the health check is created or updated.
+func (h *HealthChecks) SyncServicePort(ctx context.Context, sp *utils.ServicePort, probe *v1.Probe) (string, error) {
 	hc := h.new(*sp)
 	if probe != nil {
 		klog.V(2).Infof("Applying httpGet settings of readinessProbe to health check on port %+v", sp)
@@ -85,12 +107,15 @@ func (h *HealthChecks) SyncServicePort(sp *utils.ServicePort, probe *v1.Probe) (
 	if bchcc != nil {
 		klog.V(2).Infof("ServicePort %v has BackendConfig healthcheck override", sp.ID)
 	}
-	return h.sync(hc, bchcc)
+	if flags.F.EnableSharedHealthChecks && shareable(hc, bchcc != nil) {
+		return h.syncShared(ctx, sp, hc)
+	}
+	return h.sync(ctx, sp.ID.Service, hc, probe, bchcc)
 }
 
 // sync retrieves a health check based on port, checks type and settings and updates/creates if necessary.
 // sync is only called by the backends.Add func - it's not a pool like other resources.
-func (h *HealthChecks) sync(hc *translator.HealthCheck, bchcc *backendconfigv1.HealthCheckConfig) (string, error) {
+func (h *HealthChecks) sync(ctx context.Context, svc types.NamespacedName, hc *translator.HealthCheck, probe *v1.Probe, bchcc *backendconfigv1.HealthCheckConfig) (string, error) {
 	var scope meta.KeyType
 	// TODO(shance): find a way to remove this
 	if hc.ForILB {
@@ -99,16 +124,17 @@ func (h *HealthChecks) sync(hc *translator.HealthCheck, bchcc *backendconfigv1.H
 		scope = meta.Global
 	}
 
-	existingHC, err := h.Get(hc.Name, hc.Version(), scope)
+	existingHC, err := h.Get(ctx, hc.Name, hc.Version(), scope)
 	if utils.IsHTTPErrorCode(err, http.StatusNotFound) {
 		klog.V(2).Infof("Health check %q does not exist, creating (hc=%+v, bchcc=%+v)", hc.Name, hc, bchcc)
-		if err = h.create(hc, bchcc); err != nil {
+		if err = h.create(ctx, hc, bchcc); err != nil {
 			klog.Errorf("Health check %q creation error: %v", hc.Name, err)
 			return "", err
 		}
+		h.recordSources(svc, hc.Name, newFieldSources(probe, bchcc, false))
 		// TODO(bowei) -- we don't need to fetch the self-link here as it is
 		// returned as part of the GCE call.
-		selfLink, err := h.getHealthCheckLink(hc.Name, hc.Version(), scope)
+		selfLink, err := h.getHealthCheckLink(ctx, hc.Name, hc.Version(), scope)
 		klog.V(2).Infof("Health check %q selflink = %q", hc.Name, selfLink)
 		return selfLink, err
 	}
@@ -125,6 +151,14 @@ func (h *HealthChecks) sync(hc *translator.HealthCheck, bchcc *backendconfigv1.H
 	klog.V(3).Infof("HC before merge = %+v", premergeHC)
 	klog.V(3).Infof("Resulting HC = %+v", hc)
 
+	// Then, if enabled, the readiness probe overrides the settings it
+	// specifies.
+	if !flags.F.EnableProbeHealthCheckOverride || probe == nil || probe.Handler.HTTPGet == nil {
+		probe = nil
+	}
+	if probe != nil {
+		translator.ApplyProbeSettingsToHC(probe, hc)
+	}
 	// Then, BackendConfig will override any fields that are explicitly set.
 	if bchcc != nil {
 		// BackendConfig healthcheck settings always take precedence.
@@ -132,11 +166,16 @@ func (h *HealthChecks) sync(hc *translator.HealthCheck, bchcc *backendconfigv1.H
 	}
 
 	changes := calculateDiff(existingHC, hc, bchcc)
+	if probe != nil {
+		calculateProbeDiff(existingHC, hc, changes)
+	}
 	if changes.hasDiff() {
 		klog.V(2).Infof("Health check %q needs update (%s)", existingHC.Name, changes)
-		err := h.update(hc)
+		err := h.update(ctx, hc)
 		if err != nil {
 			klog.Errorf("Health check %q update error: %v", existingHC.Name, err)
+		} else {
+			h.recordSources(svc, hc.Name, newFieldSources(probe, bchcc, true))
 		}
 		return existingHC.SelfLink, err
 	}
@@ -145,8 +184,19 @@ func (h *HealthChecks) sync(hc *translator.HealthCheck, bchcc *backendconfigv1.H
 	return existingHC.SelfLink, nil
 }
 
+// recordSources records an event on the service listing the source of every
+// setting of its health check.
+func (h *HealthChecks) recordSources(svc types.NamespacedName, hcName string, sources fieldSources) {
+	klog.V(2).Infof("Health check %q of service %s uses settings from %s", hcName, svc, sources)
+	if h.recorders == nil || svc.Name == "" {
+		return
+	}
+	ref := &v1.ObjectReference{APIVersion: "v1", Kind: "Service", Namespace: svc.Namespace, Name: svc.Name}
+	h.recorders.Recorder(svc.Namespace).Eventf(ref, v1.EventTypeNormal, events.HealthCheckConfigured, "Health check %s uses settings from %s", hcName, sources)
+}
+
 // TODO(shance): merge with existing hc code
-func (h *HealthChecks) createILB(hc *translator.HealthCheck) error {
+func (h *HealthChecks) createILB(ctx context.Context, hc *translator.HealthCheck) error {
 	compositeType, err := composite.AlphaToHealthCheck(hc.ToAlphaComputeHealthCheck())
 	if err != nil {
 		return fmt.Errorf("Error converting hc to composite: %w", err)
@@ -160,7 +210,7 @@ func (h *HealthChecks) createILB(hc *translator.HealthCheck) error {
 
 	compositeType.Version = features.L7ILBVersions().HealthCheck
 	compositeType.Region = key.Region
-	err = composite.CreateHealthCheck(cloud, key, compositeType)
+	err = composite.CreateHealthCheck(ctx, cloud, key, compositeType)
 	if err != nil {
 		return fmt.Errorf("Error creating health check %v: %w", compositeType, err)
 	}
@@ -168,14 +218,14 @@ func (h *HealthChecks) createILB(hc *translator.HealthCheck) error {
 	return nil
 }
 
-func (h *HealthChecks) create(hc *translator.HealthCheck, bchcc *backendconfigv1.HealthCheckConfig) error {
+func (h *HealthChecks) create(ctx context.Context, hc *translator.HealthCheck, bchcc *backendconfigv1.HealthCheckConfig) error {
 	if bchcc != nil {
 		// BackendConfig healthcheck settings always take precedence.
 		hc.UpdateFromBackendConfig(bchcc)
 	}
 	// special case ILB to avoid mucking with stable HC code
 	if hc.ForILB {
-		return h.createILB(hc)
+		return h.createILB(ctx, hc)
 	}
 
 	switch hc.Version() {
@@ -202,7 +252,7 @@ func (h *HealthChecks) create(hc *translator.HealthCheck, bchcc *backendconfigv1
 }
 
 // TODO(shance): merge with existing hc code
-func (h *HealthChecks) updateILB(hc *translator.HealthCheck) error {
+func (h *HealthChecks) updateILB(ctx context.Context, hc *translator.HealthCheck) error {
 	// special case ILB to avoid mucking with stable HC code
 	compositeType, err := composite.AlphaToHealthCheck(hc.ToAlphaComputeHealthCheck())
 	if err != nil {
@@ -215,12 +265,12 @@ func (h *HealthChecks) updateILB(hc *translator.HealthCheck) error {
 	compositeType.Version = features.L7ILBVersions().HealthCheck
 	compositeType.Region = key.Region
 
-	return composite.UpdateHealthCheck(cloud, key, compositeType)
+	return composite.UpdateHealthCheck(ctx, cloud, key, compositeType)
 }
 
-func (h *HealthChecks) update(hc *translator.HealthCheck) error {
+func (h *HealthChecks) update(ctx context.Context, hc *translator.HealthCheck) error {
 	if hc.ForILB {
-		return h.updateILB(hc)
+		return h.updateILB(ctx, hc)
 	}
 	switch hc.Version() {
 	case meta.VersionAlpha:
@@ -245,8 +295,8 @@ func (h *HealthChecks) update(hc *translator.HealthCheck) error {
 	}
 }
 
-func (h *HealthChecks) getHealthCheckLink(name string, version meta.Version, scope meta.KeyType) (string, error) {
-	hc, err := h.Get(name, version, scope)
+func (h *HealthChecks) getHealthCheckLink(ctx context.Context, name string, version meta.Version, scope meta.KeyType) (string, error) {
+	hc, err := h.Get(ctx, name, version, scope)
 	if err != nil {
 		return "", err
 	}
@@ -254,7 +304,7 @@ func (h *HealthChecks) getHealthCheckLink(name string, version meta.Version, sco
 }
 
 // Delete deletes the health check by port.
-func (h *HealthChecks) Delete(name string, scope meta.KeyType) error {
+func (h *HealthChecks) Delete(ctx context.Context, name string, scope meta.KeyType) error {
 	if scope == meta.Regional {
 		cloud := h.cloud.(*gce.Cloud)
 		key, err := composite.CreateKey(cloud, name, meta.Regional)
@@ -263,7 +313,7 @@ func (h *HealthChecks) Delete(name string, scope meta.KeyType) error {
 		}
 		klog.V(2).Infof("Deleting regional health check %v", name)
 		// L7-ILB is the only use of regional right now
-		if err = composite.DeleteHealthCheck(cloud, key, features.L7ILBVersions().HealthCheck); err != nil {
+		if err = composite.DeleteHealthCheck(ctx, cloud, key, features.L7ILBVersions().HealthCheck); err != nil {
 			// Ignore error if the deletion candidate is being used by another resource.
 			// In most of the cases, this is the associated backend resource itself.
 			if utils.IsHTTPErrorCode(err, http.StatusNotFound) || utils.IsInUsedByError(err) {
@@ -290,7 +340,7 @@ func (h *HealthChecks) Delete(name string, scope meta.KeyType) error {
 }
 
 // TODO(shance): merge with existing hc code
-func (h *HealthChecks) getILB(name string) (*translator.HealthCheck, error) {
+func (h *HealthChecks) getILB(ctx context.Context, name string) (*translator.HealthCheck, error) {
 	klog.V(3).Infof("Getting ILB Health Check, name: %s", name)
 	cloud := h.cloud.(*gce.Cloud)
 	key, err := composite.CreateKey(cloud, name, meta.Regional)
@@ -298,7 +348,7 @@ func (h *HealthChecks) getILB(name string) (*translator.HealthCheck, error) {
 		return nil, err
 	}
 	// L7-ILB is the only use of regional right now
-	hc, err := composite.GetHealthCheck(cloud, key, features.L7ILBVersions().HealthCheck)
+	hc, err := composite.GetHealthCheck(ctx, cloud, key, features.L7ILBVersions().HealthCheck)
 	if err != nil {
 		return nil, err
 	}
@@ -320,12 +370,12 @@ func (h *HealthChecks) getILB(name string) (*translator.HealthCheck, error) {
 }
 
 // Get returns the health check by port
-func (h *HealthChecks) Get(name string, version meta.Version, scope meta.KeyType) (*translator.HealthCheck, error) {
+func (h *HealthChecks) Get(ctx context.Context, name string, version meta.Version, scope meta.KeyType) (*translator.HealthCheck, error) {
 	klog.V(3).Infof("Getting Health Check, name: %s, version: %v, scope: %v", name, version, scope)
 
 	// L7-ILB is the only use of regional right now
 	if scope == meta.Regional {
-		return h.getILB(name)
+		return h.getILB(ctx, name)
 	}
 
 	var hc *computealpha.HealthCheck
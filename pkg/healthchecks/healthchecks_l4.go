@@ -17,6 +17,7 @@ limitations under the License.
 package healthchecks
 
 import (
+	"context"
 	"fmt"
 
 	cloudprovider "github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
@@ -41,13 +42,20 @@ const (
 
 // EnsureL4HealthCheck creates a new HTTP health check for an L4 LoadBalancer service, based on the parameters provided.
 // If the healthcheck already exists, it is updated as needed.
-func EnsureL4HealthCheck(cloud *gce.Cloud, name string, svcName types.NamespacedName, shared bool, path string, port int32) (*composite.HealthCheck, string, error) {
+func EnsureL4HealthCheck(ctx context.Context, cloud *gce.Cloud, name string, svcName types.NamespacedName, shared bool, path string, port int32) (*composite.HealthCheck, string, error) {
+	return EnsureL4HealthCheckWithScope(ctx, cloud, name, svcName, shared, path, port, meta.Global)
+}
+
+// EnsureL4HealthCheckWithScope is like EnsureL4HealthCheck but creates the
+// healthcheck in the given scope. Backend service based external L4
+// LoadBalancers require regional healthchecks.
+func EnsureL4HealthCheckWithScope(ctx context.Context, cloud *gce.Cloud, name string, svcName types.NamespacedName, shared bool, path string, port int32, scope meta.KeyType) (*composite.HealthCheck, string, error) {
 	selfLink := ""
-	key, err := composite.CreateKey(cloud, name, meta.Global)
+	key, err := composite.CreateKey(cloud, name, scope)
 	if err != nil {
 		return nil, selfLink, fmt.Errorf("Failed to create composite key for healthcheck %s - %w", name, err)
 	}
-	hc, err := composite.GetHealthCheck(cloud, key, meta.VersionGA)
+	hc, err := composite.GetHealthCheck(ctx, cloud, key, meta.VersionGA)
 	if err != nil {
 		if !utils.IsNotFoundError(err) {
 			return nil, selfLink, err
@@ -57,7 +65,7 @@ func EnsureL4HealthCheck(cloud *gce.Cloud, name string, svcName types.Namespaced
 	if hc == nil {
 		// Create the healthcheck
 		klog.V(2).Infof("Creating healthcheck %s for service %s, shared = %v", name, svcName, shared)
-		err = composite.CreateHealthCheck(cloud, key, expectedHC)
+		err = composite.CreateHealthCheck(ctx, cloud, key, expectedHC)
 		if err != nil {
 			return nil, selfLink, err
 		}
@@ -71,19 +79,19 @@ func EnsureL4HealthCheck(cloud *gce.Cloud, name string, svcName types.Namespaced
 	}
 	mergeHealthChecks(hc, expectedHC)
 	klog.V(2).Infof("Updating healthcheck %s for service %s", name, svcName)
-	err = composite.UpdateHealthCheck(cloud, key, expectedHC)
+	err = composite.UpdateHealthCheck(ctx, cloud, key, expectedHC)
 	if err != nil {
 		return nil, selfLink, err
 	}
 	return expectedHC, selfLink, err
 }
 
-func DeleteHealthCheck(cloud *gce.Cloud, name string) error {
+func DeleteHealthCheck(ctx context.Context, cloud *gce.Cloud, name string) error {
 	key, err := composite.CreateKey(cloud, name, meta.Global)
 	if err != nil {
 		return fmt.Errorf("Failed to create composite key for healthcheck %s - %w", name, err)
 	}
-	return composite.DeleteHealthCheck(cloud, key, meta.VersionGA)
+	return composite.DeleteHealthCheck(ctx, cloud, key, meta.VersionGA)
 }
 
 func NewL4HealthCheck(name string, svcName types.NamespacedName, shared bool, path string, port int32) *composite.HealthCheck {
@@ -130,15 +138,22 @@ func mergeHealthChecks(hc, newHC *composite.HealthCheck) {
 	}
 }
 
+// l4HealthCheckFields are the fields of an L4 health check owned by the
+// controller. The intervals and thresholds are minimums, which may be raised
+// outside of the controller.
+var l4HealthCheckFields = []utils.FieldSpec{
+	{Path: "HttpHealthCheck.Port"},
+	{Path: "HttpHealthCheck.RequestPath"},
+	{Path: "Description"},
+	{Path: "CheckIntervalSec", Minimum: true},
+	{Path: "TimeoutSec", Minimum: true},
+	{Path: "UnhealthyThreshold", Minimum: true},
+	{Path: "HealthyThreshold", Minimum: true},
+}
+
 // needToUpdateHealthChecks checks whether the healthcheck needs to be updated.
 func needToUpdateHealthChecks(hc, newHC *composite.HealthCheck) bool {
 	return hc.HttpHealthCheck == nil ||
 		newHC.HttpHealthCheck == nil ||
-		hc.HttpHealthCheck.Port != newHC.HttpHealthCheck.Port ||
-		hc.HttpHealthCheck.RequestPath != newHC.HttpHealthCheck.RequestPath ||
-		hc.Description != newHC.Description ||
-		hc.CheckIntervalSec < newHC.CheckIntervalSec ||
-		hc.TimeoutSec < newHC.TimeoutSec ||
-		hc.UnhealthyThreshold < newHC.UnhealthyThreshold ||
-		hc.HealthyThreshold < newHC.HealthyThreshold
+		len(utils.DiffFields(newHC, hc, l4HealthCheckFields)) > 0
 }
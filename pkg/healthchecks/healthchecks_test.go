@@ -37,6 +37,7 @@ import (
 	"k8s.io/ingress-gce/pkg/annotations"
 	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
 	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/events"
 	"k8s.io/ingress-gce/pkg/flags"
 	"k8s.io/ingress-gce/pkg/loadbalancers/features"
 	"k8s.io/ingress-gce/pkg/translator"
@@ -111,10 +112,10 @@ func init() {
 
 func TestHealthCheckAdd(t *testing.T) {
 	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
-	healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc)
+	healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc, events.RecorderProducerMock{})
 
 	sp := &utils.ServicePort{NodePort: 80, Protocol: annotations.ProtocolHTTP, NEGEnabled: false, BackendNamer: testNamer}
-	_, err := healthChecks.SyncServicePort(sp, nil)
+	_, err := healthChecks.SyncServicePort(context.TODO(), sp, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -125,7 +126,7 @@ func TestHealthCheckAdd(t *testing.T) {
 	}
 
 	sp = &utils.ServicePort{NodePort: 443, Protocol: annotations.ProtocolHTTPS, NEGEnabled: false, BackendNamer: testNamer}
-	_, err = healthChecks.SyncServicePort(sp, nil)
+	_, err = healthChecks.SyncServicePort(context.TODO(), sp, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -136,7 +137,7 @@ func TestHealthCheckAdd(t *testing.T) {
 	}
 
 	sp = &utils.ServicePort{NodePort: 3000, Protocol: annotations.ProtocolHTTP2, NEGEnabled: false, BackendNamer: testNamer}
-	_, err = healthChecks.SyncServicePort(sp, nil)
+	_, err = healthChecks.SyncServicePort(context.TODO(), sp, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -147,9 +148,68 @@ func TestHealthCheckAdd(t *testing.T) {
 	}
 }
 
+func TestHealthCheckAddTargetSSLProxyBackends(t *testing.T) {
+	for _, tc := range []struct {
+		protocol annotations.AppProtocol
+		nodePort int64
+	}{
+		{protocol: annotations.ProtocolTCP, nodePort: 4000},
+		{protocol: annotations.ProtocolSSL, nodePort: 4001},
+	} {
+		t.Run(string(tc.protocol), func(t *testing.T) {
+			fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+			healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc, events.RecorderProducerMock{})
+			updates := 0
+			mock := fakeGCE.Compute().(*cloud.MockGCE)
+			mock.MockHealthChecks.UpdateHook = func(context.Context, *meta.Key, *compute.HealthCheck, *cloud.MockHealthChecks) error {
+				updates++
+				return nil
+			}
+
+			sp := &utils.ServicePort{NodePort: tc.nodePort, Protocol: tc.protocol, BackendNamer: testNamer}
+			probe := &v1.Probe{Handler: v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz"}}, TimeoutSeconds: 5, PeriodSeconds: 10}
+			for i := 0; i < 2; i++ {
+				if _, err := healthChecks.SyncServicePort(context.TODO(), sp, probe); err != nil {
+					t.Fatalf("SyncServicePort() = %v", err)
+				}
+			}
+			hc, err := fakeGCE.GetHealthCheck(testNamer.IGBackend(tc.nodePort))
+			if err != nil {
+				t.Fatalf("GetHealthCheck() = %v", err)
+			}
+			if hc.Type != string(tc.protocol) {
+				t.Errorf("got health check type %q, want %q", hc.Type, tc.protocol)
+			}
+			if hc.HttpHealthCheck != nil || hc.HttpsHealthCheck != nil {
+				t.Errorf("got HTTP settings on a %s health check: %+v", tc.protocol, hc)
+			}
+			var port int64
+			switch tc.protocol {
+			case annotations.ProtocolTCP:
+				if hc.TcpHealthCheck != nil {
+					port = hc.TcpHealthCheck.Port
+				}
+			case annotations.ProtocolSSL:
+				if hc.SslHealthCheck != nil {
+					port = hc.SslHealthCheck.Port
+				}
+			}
+			if port != tc.nodePort {
+				t.Errorf("got %s health check port %d, want %d", tc.protocol, port, tc.nodePort)
+			}
+			if hc.TimeoutSec != 5 {
+				t.Errorf("got health check timeout %d, want the probe timeout 5", hc.TimeoutSec)
+			}
+			if updates != 0 {
+				t.Errorf("got %d health check updates on resync, want 0", updates)
+			}
+		})
+	}
+}
+
 func TestHealthCheckAddExisting(t *testing.T) {
 	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
-	healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc)
+	healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc, events.RecorderProducerMock{})
 
 	// HTTP
 	// Manually insert a health check
@@ -164,7 +224,7 @@ func TestHealthCheckAddExisting(t *testing.T) {
 
 	sp := &utils.ServicePort{NodePort: 3000, Protocol: annotations.ProtocolHTTP, NEGEnabled: false, BackendNamer: testNamer}
 	// Should not fail adding the same type of health check
-	_, err = healthChecks.SyncServicePort(sp, nil)
+	_, err = healthChecks.SyncServicePort(context.TODO(), sp, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -186,7 +246,7 @@ func TestHealthCheckAddExisting(t *testing.T) {
 	fakeGCE.CreateHealthCheck(v1hc)
 
 	sp = &utils.ServicePort{NodePort: 4000, Protocol: annotations.ProtocolHTTPS, NEGEnabled: false, BackendNamer: testNamer}
-	_, err = healthChecks.SyncServicePort(sp, nil)
+	_, err = healthChecks.SyncServicePort(context.TODO(), sp, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -208,7 +268,7 @@ func TestHealthCheckAddExisting(t *testing.T) {
 	fakeGCE.CreateHealthCheck(v1hc)
 
 	sp = &utils.ServicePort{NodePort: 5000, Protocol: annotations.ProtocolHTTPS, NEGEnabled: false, BackendNamer: testNamer}
-	_, err = healthChecks.SyncServicePort(sp, nil)
+	_, err = healthChecks.SyncServicePort(context.TODO(), sp, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -221,7 +281,7 @@ func TestHealthCheckAddExisting(t *testing.T) {
 
 func TestHealthCheckDelete(t *testing.T) {
 	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
-	healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc)
+	healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc, events.RecorderProducerMock{})
 
 	// Create HTTP HC for 1234
 	hc := translator.DefaultHealthCheck(1234, annotations.ProtocolHTTP)
@@ -237,7 +297,7 @@ func TestHealthCheckDelete(t *testing.T) {
 	fakeGCE.CreateHealthCheck(v1hc)
 
 	// Delete only HTTP 1234
-	err = healthChecks.Delete(testNamer.IGBackend(1234), meta.Global)
+	err = healthChecks.Delete(context.TODO(), testNamer.IGBackend(1234), meta.Global)
 	if err != nil {
 		t.Errorf("unexpected error when deleting health check, err: %v", err)
 	}
@@ -251,7 +311,7 @@ func TestHealthCheckDelete(t *testing.T) {
 
 func TestHTTP2HealthCheckDelete(t *testing.T) {
 	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
-	healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc)
+	healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc, events.RecorderProducerMock{})
 
 	// Create HTTP2 HC for 1234
 	hc := translator.DefaultHealthCheck(1234, annotations.ProtocolHTTP2)
@@ -261,7 +321,7 @@ func TestHTTP2HealthCheckDelete(t *testing.T) {
 	fakeGCE.CreateAlphaHealthCheck(alphahc)
 
 	// Delete only HTTP2 1234
-	err := healthChecks.Delete(testNamer.IGBackend(1234), meta.Global)
+	err := healthChecks.Delete(context.TODO(), testNamer.IGBackend(1234), meta.Global)
 	if err != nil {
 		t.Errorf("unexpected error when deleting health check, err: %v", err)
 	}
@@ -275,7 +335,7 @@ func TestHTTP2HealthCheckDelete(t *testing.T) {
 
 func TestRegionalHealthCheckDelete(t *testing.T) {
 	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
-	healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc)
+	healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc, events.RecorderProducerMock{})
 
 	hc := healthChecks.new(
 		utils.ServicePort{
@@ -294,7 +354,7 @@ func TestRegionalHealthCheckDelete(t *testing.T) {
 	)
 	hcName := testNamer.NEG("ns2", "svc2", 80)
 
-	if err := healthChecks.create(hc, nil); err != nil {
+	if err := healthChecks.create(context.TODO(), hc, nil); err != nil {
 		t.Fatalf("healthchecks.Create(%q) = %v, want nil", hc.Name, err)
 	}
 
@@ -304,17 +364,17 @@ func TestRegionalHealthCheckDelete(t *testing.T) {
 	}
 
 	// Verify that Health-check exists.
-	if existingHC, err := composite.GetHealthCheck(fakeGCE, key, features.L7ILBVersions().HealthCheck); err != nil || existingHC == nil {
+	if existingHC, err := composite.GetHealthCheck(context.TODO(), fakeGCE, key, features.L7ILBVersions().HealthCheck); err != nil || existingHC == nil {
 		t.Fatalf("GetHealthCheck(%q) = %v, %v, want nil", hc.Name, existingHC, err)
 	}
 
 	// Delete HTTP health-check.
-	if err = healthChecks.Delete(hcName, meta.Regional); err != nil {
+	if err = healthChecks.Delete(context.TODO(), hcName, meta.Regional); err != nil {
 		t.Errorf("healthchecks.Delete(%q, %q) = %v, want nil", hcName, meta.Regional, err)
 	}
 
 	// Validate health-check is deleted.
-	if _, err = composite.GetHealthCheck(fakeGCE, key, features.L7ILBVersions().HealthCheck); !utils.IsHTTPErrorCode(err, http.StatusNotFound) {
+	if _, err = composite.GetHealthCheck(context.TODO(), fakeGCE, key, features.L7ILBVersions().HealthCheck); !utils.IsHTTPErrorCode(err, http.StatusNotFound) {
 		t.Errorf("Expected not-found error, actual: %v", err)
 	}
 }
@@ -327,7 +387,7 @@ func TestHealthCheckUpdate(t *testing.T) {
 	(fakeGCE.Compute().(*cloud.MockGCE)).MockAlphaHealthChecks.UpdateHook = mock.UpdateAlphaHealthCheckHook
 	(fakeGCE.Compute().(*cloud.MockGCE)).MockBetaHealthChecks.UpdateHook = mock.UpdateBetaHealthCheckHook
 
-	healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc)
+	healthChecks := NewHealthChecker(fakeGCE, "/", defaultBackendSvc, events.RecorderProducerMock{})
 
 	// HTTP
 	// Manually insert a health check
@@ -341,20 +401,20 @@ func TestHealthCheckUpdate(t *testing.T) {
 	fakeGCE.CreateHealthCheck(v1hc)
 
 	// Verify the health check exists
-	_, err = healthChecks.Get(hc.Name, meta.VersionGA, meta.Global)
+	_, err = healthChecks.Get(context.TODO(), hc.Name, meta.VersionGA, meta.Global)
 	if err != nil {
 		t.Fatalf("expected the health check to exist, err: %v", err)
 	}
 
 	// Change to HTTPS
 	hc.Type = string(annotations.ProtocolHTTPS)
-	_, err = healthChecks.sync(hc, nil)
+	_, err = healthChecks.sync(context.TODO(), types.NamespacedName{}, hc, nil, nil)
 	if err != nil {
 		t.Fatalf("unexpected err while syncing healthcheck, err %v", err)
 	}
 
 	// Verify the health check exists
-	_, err = healthChecks.Get(hc.Name, meta.VersionGA, meta.Global)
+	_, err = healthChecks.Get(context.TODO(), hc.Name, meta.VersionGA, meta.Global)
 	if err != nil {
 		t.Fatalf("expected the health check to exist, err: %v", err)
 	}
@@ -366,13 +426,13 @@ func TestHealthCheckUpdate(t *testing.T) {
 
 	// Change to HTTP2
 	hc.Type = string(annotations.ProtocolHTTP2)
-	_, err = healthChecks.sync(hc, nil)
+	_, err = healthChecks.sync(context.TODO(), types.NamespacedName{}, hc, nil, nil)
 	if err != nil {
 		t.Fatalf("unexpected err while syncing healthcheck, err %v", err)
 	}
 
 	// Verify the health check exists. HTTP2 is alpha-only.
-	_, err = healthChecks.Get(hc.Name, meta.VersionAlpha, meta.Global)
+	_, err = healthChecks.Get(context.TODO(), hc.Name, meta.VersionAlpha, meta.Global)
 	if err != nil {
 		t.Fatalf("expected the health check to exist, err: %v", err)
 	}
@@ -385,14 +445,14 @@ func TestHealthCheckUpdate(t *testing.T) {
 	// Change to NEG Health Check
 	hc.ForNEG = true
 	hc.PortSpecification = "USE_SERVING_PORT"
-	_, err = healthChecks.sync(hc, nil)
+	_, err = healthChecks.sync(context.TODO(), types.NamespacedName{}, hc, nil, nil)
 
 	if err != nil {
 		t.Fatalf("unexpected err while syncing healthcheck, err %v", err)
 	}
 
 	// Verify the health check exists.
-	hc, err = healthChecks.Get(hc.Name, meta.VersionAlpha, meta.Global)
+	hc, err = healthChecks.Get(context.TODO(), hc.Name, meta.VersionAlpha, meta.Global)
 	if err != nil {
 		t.Fatalf("expected the health check to exist, err: %v", err)
 	}
@@ -406,13 +466,13 @@ func TestHealthCheckUpdate(t *testing.T) {
 	hc.Port = 3000
 	hc.PortSpecification = ""
 
-	_, err = healthChecks.sync(hc, nil)
+	_, err = healthChecks.sync(context.TODO(), types.NamespacedName{}, hc, nil, nil)
 	if err != nil {
 		t.Fatalf("unexpected err while syncing healthcheck, err %v", err)
 	}
 
 	// Verify the health check exists.
-	hc, err = healthChecks.Get(hc.Name, meta.VersionAlpha, meta.Global)
+	hc, err = healthChecks.Get(context.TODO(), hc.Name, meta.VersionAlpha, meta.Global)
 	if err != nil {
 		t.Fatalf("expected the health check to exist, err: %v", err)
 	}
@@ -735,6 +795,26 @@ func TestCalculateDiff(t *testing.T) {
 		hasDiff: true,
 	})
 
+	newHC = translator.DefaultHealthCheck(8080, annotations.ProtocolHTTP)
+	newHC.ProxyHeader = "PROXY_V1"
+	cases = append(cases, tc{
+		desc:    "Backendconfig ProxyHeader",
+		old:     translator.DefaultHealthCheck(8080, annotations.ProtocolHTTP),
+		new:     newHC,
+		c:       &backendconfigv1.HealthCheckConfig{ProxyHeader: s("PROXY_V1")},
+		hasDiff: true,
+	})
+
+	newHC = translator.DefaultHealthCheck(8080, annotations.ProtocolHTTP)
+	newHC.PortSpecification = "USE_SERVING_PORT"
+	cases = append(cases, tc{
+		desc:    "Backendconfig PortSpecification",
+		old:     translator.DefaultHealthCheck(8080, annotations.ProtocolHTTP),
+		new:     newHC,
+		c:       &backendconfigv1.HealthCheckConfig{PortSpecification: s("USE_SERVING_PORT")},
+		hasDiff: true,
+	})
+
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
 			diffs := calculateDiff(tc.old, tc.new, tc.c)
@@ -870,6 +950,8 @@ func TestSyncServicePort(t *testing.T) {
 		sp       *utils.ServicePort
 		probe    *v1.Probe
 		regional bool
+		// probeOverride enables the readiness probe override.
+		probeOverride bool
 
 		wantSelfLink  string
 		wantErr       bool
@@ -975,6 +1057,23 @@ func TestSyncServicePort(t *testing.T) {
 	}
 	cases = append(cases, &tc{desc: "create backendconfig port", sp: &sp, wantComputeHC: chc})
 
+	// BackendConfig serving port and proxy header
+	chc = fixture.hc()
+	chc.HttpHealthCheck.Port = 0
+	chc.HttpHealthCheck.PortSpecification = "USE_SERVING_PORT"
+	chc.HttpHealthCheck.ProxyHeader = "PROXY_V1"
+	servingPort, proxyV1 := "USE_SERVING_PORT", "PROXY_V1"
+	servingPortSP := utils.ServicePort{
+		NodePort:     80,
+		Protocol:     annotations.ProtocolHTTP,
+		BackendNamer: testNamer,
+		BackendConfig: &backendconfigv1.BackendConfig{Spec: backendconfigv1.BackendConfigSpec{HealthCheck: &backendconfigv1.HealthCheckConfig{
+			PortSpecification: &servingPort,
+			ProxyHeader:       &proxyV1,
+		}}},
+	}
+	cases = append(cases, &tc{desc: "create backendconfig serving port and proxy header", sp: &servingPortSP, wantComputeHC: chc})
+
 	// BackendConfig neg
 	chc = fixture.neg()
 	chc.HttpHealthCheck.RequestPath = "/foo"
@@ -1172,13 +1271,12 @@ func TestSyncServicePort(t *testing.T) {
 		wantComputeHC: wantCHC,
 	})
 
-	// BUG: changing probe settings has not effect on the healthcheck
-	// update.
-	// TODO(bowei): document this.
+	// Without the override, changing probe settings has no effect on the
+	// existing healthcheck.
 	chc = fixture.hc()
 	chc.HttpHealthCheck.RequestPath = "/user-path"
 	cases = append(cases, &tc{
-		desc:  "update probe has no effect (bug)",
+		desc:  "update probe has no effect without override",
 		setup: fixture.setupExistingHCFunc(chc),
 		sp:    testSPs["HTTP-80-reg-nil"],
 		probe: &v1.Probe{
@@ -1191,6 +1289,57 @@ func TestSyncServicePort(t *testing.T) {
 		wantComputeHC: chc,
 	})
 
+	// With the override, probe settings override the settings of the
+	// existing healthcheck.
+	chc = fixture.hc()
+	chc.HttpHealthCheck.RequestPath = "/user-path"
+	chc.HealthyThreshold = 1234
+	wantCHC = fixture.hc()
+	wantCHC.HttpHealthCheck.RequestPath = "/foo" // from probe
+	wantCHC.HttpHealthCheck.Host = "foo.com"     // from probe
+	wantCHC.CheckIntervalSec = 61                // from probe
+	wantCHC.TimeoutSec = 1234                    // from probe
+	wantCHC.HealthyThreshold = 1234              // preserved
+	cases = append(cases, &tc{
+		desc:  "update probe overrides existing settings",
+		setup: fixture.setupExistingHCFunc(chc),
+		sp:    testSPs["HTTP-80-reg-nil"],
+		probe: &v1.Probe{
+			Handler: v1.Handler{
+				HTTPGet: &v1.HTTPGetAction{Path: "/foo", Host: "foo.com"},
+			},
+			PeriodSeconds:  1,
+			TimeoutSeconds: 1234,
+		},
+		probeOverride: true,
+		wantComputeHC: wantCHC,
+	})
+
+	// BackendConfig settings override probe settings, which override the
+	// settings of the existing healthcheck.
+	chc = fixture.hc()
+	chc.HttpHealthCheck.RequestPath = "/user-path"
+	chc.HttpHealthCheck.Host = "user.com"
+	wantCHC = fixture.hc()
+	wantCHC.HttpHealthCheck.RequestPath = "/foo" // from bc
+	wantCHC.HttpHealthCheck.Host = "probe.com"   // from probe
+	wantCHC.CheckIntervalSec = 61                // from probe
+	wantCHC.TimeoutSec = 1234                    // from probe
+	cases = append(cases, &tc{
+		desc:  "update probe and backendconfig precedence",
+		setup: fixture.setupExistingHCFunc(chc),
+		sp:    testSPs["HTTP-80-reg-bc"],
+		probe: &v1.Probe{
+			Handler: v1.Handler{
+				HTTPGet: &v1.HTTPGetAction{Path: "/probe", Host: "probe.com"},
+			},
+			PeriodSeconds:  1,
+			TimeoutSeconds: 1234,
+		},
+		probeOverride: true,
+		wantComputeHC: wantCHC,
+	})
+
 	// BUG: Enable NEG, leaks old healthcheck, does not preserve old
 	// settings.
 
@@ -1208,9 +1357,13 @@ func TestSyncServicePort(t *testing.T) {
 				tc.setup(mock)
 			}
 
-			hcs := NewHealthChecker(fakeGCE, "/", defaultBackendSvc)
+			oldOverride := flags.F.EnableProbeHealthCheckOverride
+			flags.F.EnableProbeHealthCheckOverride = tc.probeOverride
+			defer func() { flags.F.EnableProbeHealthCheckOverride = oldOverride }()
+
+			hcs := NewHealthChecker(fakeGCE, "/", defaultBackendSvc, events.RecorderProducerMock{})
 
-			gotSelfLink, err := hcs.SyncServicePort(tc.sp, tc.probe)
+			gotSelfLink, err := hcs.SyncServicePort(context.TODO(), tc.sp, tc.probe)
 			if gotErr := err != nil; gotErr != tc.wantErr {
 				t.Errorf("hcs.SyncServicePort(tc.sp, tc.probe) = _, %v; gotErr = %t, want %t\nsp = %s\nprobe = %s", err, gotErr, tc.wantErr, pretty.Sprint(tc.sp), pretty.Sprint(tc.probe))
 			}
@@ -1254,7 +1407,7 @@ func TestSyncServicePort(t *testing.T) {
 				return nil
 			}
 
-			gotSelfLink, err = hcs.SyncServicePort(tc.sp, tc.probe)
+			gotSelfLink, err = hcs.SyncServicePort(context.TODO(), tc.sp, tc.probe)
 			if gotErr := err != nil; gotErr != tc.wantErr {
 				t.Errorf("hcs.SyncServicePort(tc.sp, tc.probe) = %v; gotErr = %t, want %t\nsp = %s\nprobe = %s", err, gotErr, tc.wantErr, pretty.Sprint(tc.sp), pretty.Sprint(tc.probe))
 			}
@@ -1268,3 +1421,28 @@ func TestSyncServicePort(t *testing.T) {
 
 // TODO(bowei): test regional delete
 // TODO(bowei): test errors from GCE
+
+func TestSharedHealthCheckHash(t *testing.T) {
+	hash := func(hc *translator.HealthCheck) string {
+		t.Helper()
+		h, err := sharedHealthCheckHash(hc)
+		if err != nil {
+			t.Fatalf("sharedHealthCheckHash() = %v", err)
+		}
+		return h
+	}
+
+	hc1 := translator.DefaultNEGHealthCheck(annotations.ProtocolHTTP)
+	hc1.Name = "hc1"
+	hc2 := translator.DefaultNEGHealthCheck(annotations.ProtocolHTTP)
+	hc2.Name = "hc2"
+	hc2.Description = "other description"
+	if hash(hc1) != hash(hc2) {
+		t.Errorf("Health checks with identical settings have different hashes")
+	}
+
+	hc2.RequestPath = "/healthz"
+	if hash(hc1) == hash(hc2) {
+		t.Errorf("Health checks with different request paths have the same hash")
+	}
+}
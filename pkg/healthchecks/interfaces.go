@@ -17,6 +17,8 @@ limitations under the License.
 package healthchecks
 
 import (
+	"context"
+
 	computealpha "google.golang.org/api/compute/v0.alpha"
 	computebeta "google.golang.org/api/compute/v0.beta"
 	compute "google.golang.org/api/compute/v1"
@@ -53,7 +55,7 @@ type HealthChecker interface {
 	// ServicePort and Pod Probe definition.
 	//
 	// `probe` can be nil if no probe exists.
-	SyncServicePort(sp *utils.ServicePort, probe *v1.Probe) (string, error)
-	Delete(name string, scope meta.KeyType) error
-	Get(name string, version meta.Version, scope meta.KeyType) (*translator.HealthCheck, error)
+	SyncServicePort(ctx context.Context, sp *utils.ServicePort, probe *v1.Probe) (string, error)
+	Delete(ctx context.Context, name string, scope meta.KeyType) error
+	Get(ctx context.Context, name string, version meta.Version, scope meta.KeyType) (*translator.HealthCheck, error)
 }
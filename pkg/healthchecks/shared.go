@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthchecks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"k8s.io/ingress-gce/pkg/translator"
+	"k8s.io/ingress-gce/pkg/utils"
+	"k8s.io/klog"
+)
+
+const sharedHealthCheckDescription = "Kubernetes L7 health check shared by backend services with identical health checks."
+
+// shareable returns true if the health check may be shared with other
+// backend services. Only global NEG health checks are shared, as they do not
+// depend on the port of the service, and only if the BackendConfig of the
+// service does not customize the health check.
+func shareable(hc *translator.HealthCheck, hasBackendConfigHC bool) bool {
+	return hc.ForNEG && !hc.ForILB && !hasBackendConfigHC
+}
+
+// sharedHealthCheckHash returns a hash of the settings of the health check.
+// Health checks with identical settings have the same hash.
+func sharedHealthCheckHash(hc *translator.HealthCheck) (string, error) {
+	settings := *hc.ToAlphaComputeHealthCheck()
+	settings.Name = ""
+	settings.Description = ""
+	settings.SelfLink = ""
+	settings.CreationTimestamp = ""
+	settings.Id = 0
+	settings.Kind = ""
+	settings.Region = ""
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b))[:16], nil
+}
+
+// syncShared ensures the shared health check for the backend service of the
+// given ServicePort. The health check is named after the hash of its settings,
+// so it is never updated in place: backend services move to another shared
+// health check when their settings change. Shared health checks are not
+// reference counted here, the syncer deletes them once no backend service
+// of the cluster uses them.
+func (h *HealthChecks) syncShared(ctx context.Context, sp *utils.ServicePort, hc *translator.HealthCheck) (string, error) {
+	hash, err := sharedHealthCheckHash(hc)
+	if err != nil {
+		return "", err
+	}
+	hc.Name = sp.BackendNamer.SharedHealthCheck(hash)
+	hc.Description = sharedHealthCheckDescription
+
+	selfLink, err := h.getHealthCheckLink(ctx, hc.Name, hc.Version(), meta.Global)
+	if utils.IsHTTPErrorCode(err, http.StatusNotFound) {
+		klog.V(2).Infof("Shared health check %q does not exist, creating (hc=%+v)", hc.Name, hc)
+		if err = h.create(ctx, hc, nil); err != nil {
+			klog.Errorf("Shared health check %q creation error: %v", hc.Name, err)
+			return "", err
+		}
+		selfLink, err = h.getHealthCheckLink(ctx, hc.Name, hc.Version(), meta.Global)
+	}
+	if err != nil {
+		return "", err
+	}
+	return selfLink, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthchecks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
+	"k8s.io/ingress-gce/pkg/translator"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+// Sources of the settings of a health check, from the lowest to the highest
+// precedence.
+const (
+	sourceDefault       = "default"
+	sourceExisting      = "existing health check"
+	sourceProbe         = "readinessProbe"
+	sourceBackendConfig = "BackendConfig"
+)
+
+// probeFields are the fields of a health check taken from the readiness probe.
+var probeFields = []utils.FieldSpec{
+	{Path: "RequestPath"},
+	{Path: "Host"},
+	{Path: "CheckIntervalSec"},
+	{Path: "TimeoutSec"},
+}
+
+// fieldSources maps the settings of a health check to their source.
+type fieldSources map[string]string
+
+// newFieldSources returns the sources of the settings of a health check
+// built from the given readiness probe and BackendConfig, either of which may
+// be nil. existing is true if the settings of an existing health check are
+// preserved.
+//
+// The sources have a strict precedence: BackendConfig settings override
+// readinessProbe settings, which override the settings of the existing health
+// check, which override the defaults.
+func newFieldSources(probe *v1.Probe, bchcc *backendconfigv1.HealthCheckConfig, existing bool) fieldSources {
+	base := sourceDefault
+	if existing {
+		base = sourceExisting
+	}
+	s := fieldSources{}
+	for _, field := range []string{"checkIntervalSec", "timeoutSec", "healthyThreshold", "unhealthyThreshold", "requestPath", "host", "proxyHeader"} {
+		s[field] = base
+	}
+	// The type and port of the health check follow the service port, unless
+	// set by the BackendConfig.
+	s["type"] = sourceDefault
+	s["port"] = sourceDefault
+	if probe != nil && probe.Handler.HTTPGet != nil {
+		for _, field := range []string{"checkIntervalSec", "timeoutSec", "requestPath", "host"} {
+			s[field] = sourceProbe
+		}
+	}
+	if bchcc != nil {
+		set := map[string]bool{
+			"checkIntervalSec":   bchcc.CheckIntervalSec != nil,
+			"timeoutSec":         bchcc.TimeoutSec != nil,
+			"healthyThreshold":   bchcc.HealthyThreshold != nil,
+			"unhealthyThreshold": bchcc.UnhealthyThreshold != nil,
+			"type":               bchcc.Type != nil,
+			"requestPath":        bchcc.RequestPath != nil,
+			"port":               bchcc.Port != nil || bchcc.PortSpecification != nil,
+			"proxyHeader":        bchcc.ProxyHeader != nil,
+		}
+		for field, ok := range set {
+			if ok {
+				s[field] = sourceBackendConfig
+			}
+		}
+	}
+	return s
+}
+
+// String returns the settings grouped by source, e.g.
+// "readinessProbe: host, requestPath; default: port".
+func (s fieldSources) String() string {
+	bySource := map[string][]string{}
+	for field, source := range s {
+		bySource[source] = append(bySource[source], field)
+	}
+	var parts []string
+	for _, source := range []string{sourceBackendConfig, sourceProbe, sourceExisting, sourceDefault} {
+		fields := bySource[source]
+		if len(fields) == 0 {
+			continue
+		}
+		sort.Strings(fields)
+		parts = append(parts, fmt.Sprintf("%s: %s", source, strings.Join(fields, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// calculateProbeDiff adds the differences in the settings taken from the
+// readiness probe to changes, so that changes of the probe are applied to
+// existing health checks.
+func calculateProbeDiff(old, new *translator.HealthCheck, changes *fieldDiffs) {
+	changes.addFields(old, new, probeFields)
+}
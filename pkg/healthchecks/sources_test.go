@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthchecks
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
+	"k8s.io/ingress-gce/pkg/events"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+type fakeRecorders struct {
+	recorder *record.FakeRecorder
+}
+
+func (f fakeRecorders) Recorder(string) record.EventRecorder {
+	return f.recorder
+}
+
+func TestFieldSources(t *testing.T) {
+	path := "/bc"
+	probe := &v1.Probe{Handler: v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: "/probe"}}}
+	bchcc := &backendconfigv1.HealthCheckConfig{RequestPath: &path}
+
+	for _, tc := range []struct {
+		desc     string
+		probe    *v1.Probe
+		bchcc    *backendconfigv1.HealthCheckConfig
+		existing bool
+		want     string
+	}{
+		{
+			desc: "defaults",
+			want: "default: checkIntervalSec, healthyThreshold, host, port, proxyHeader, requestPath, timeoutSec, type, unhealthyThreshold",
+		},
+		{
+			desc:     "existing",
+			existing: true,
+			want:     "existing health check: checkIntervalSec, healthyThreshold, host, proxyHeader, requestPath, timeoutSec, unhealthyThreshold; default: port, type",
+		},
+		{
+			desc:     "probe and backendconfig",
+			probe:    probe,
+			bchcc:    bchcc,
+			existing: true,
+			want:     "BackendConfig: requestPath; readinessProbe: checkIntervalSec, host, timeoutSec; existing health check: healthyThreshold, proxyHeader, unhealthyThreshold; default: port, type",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := newFieldSources(tc.probe, tc.bchcc, tc.existing).String(); got != tc.want {
+				t.Errorf("newFieldSources().String() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSyncServicePortRecordsSources(t *testing.T) {
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	recorder := record.NewFakeRecorder(10)
+	hcs := NewHealthChecker(fakeGCE, "/", defaultBackendSvc, fakeRecorders{recorder})
+	probe := &v1.Probe{Handler: v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: "/probe"}}}
+	sp := *testSPs["HTTP-80-reg-nil"]
+	sp.ID.Service = types.NamespacedName{Namespace: "default", Name: "svc"}
+
+	if _, err := hcs.SyncServicePort(context.TODO(), &sp, probe); err != nil {
+		t.Fatalf("SyncServicePort() = %v", err)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, events.HealthCheckConfigured) || !strings.Contains(event, "readinessProbe: checkIntervalSec, host, requestPath, timeoutSec") {
+			t.Errorf("Got event %q, want %s event listing the readinessProbe settings", event, events.HealthCheckConfigured)
+		}
+	default:
+		t.Fatalf("SyncServicePort() recorded no event")
+	}
+
+	// A resync without changes records no event.
+	if _, err := hcs.SyncServicePort(context.TODO(), &sp, probe); err != nil {
+		t.Fatalf("SyncServicePort() = %v", err)
+	}
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("Got event %q on resync, want none", event)
+	default:
+	}
+}
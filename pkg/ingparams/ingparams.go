@@ -16,6 +16,10 @@ limitations under the License.
 package ingparams
 
 import (
+	"fmt"
+
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/client-go/tools/cache"
 	apisingparams "k8s.io/ingress-gce/pkg/apis/ingparams"
 	ingparamsv1beta1 "k8s.io/ingress-gce/pkg/apis/ingparams/v1beta1"
 	"k8s.io/ingress-gce/pkg/crd"
@@ -35,3 +39,31 @@ func CRDMeta() *crd.CRDMeta {
 	)
 	return meta
 }
+
+// ParamsForIngress returns the GCPIngressParams referenced by the IngressClass
+// of ing. It returns nil if ing has no class or the class does not reference
+// GCPIngressParams.
+func ParamsForIngress(ing *v1.Ingress, ingClassLister, ingParamsLister cache.Indexer) (*ingparamsv1beta1.GCPIngressParams, error) {
+	if ing.Spec.IngressClassName == nil || ingClassLister == nil || ingParamsLister == nil {
+		return nil, nil
+	}
+	obj, exists, err := ingClassLister.GetByKey(*ing.Spec.IngressClassName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IngressClass %q: %w", *ing.Spec.IngressClassName, err)
+	}
+	if !exists {
+		return nil, nil
+	}
+	params := obj.(*v1.IngressClass).Spec.Parameters
+	if params == nil || params.APIGroup == nil || *params.APIGroup != apisingparams.GroupName || params.Kind != "GCPIngressParams" {
+		return nil, nil
+	}
+	obj, exists, err = ingParamsLister.GetByKey(params.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GCPIngressParams %q: %w", params.Name, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("GCPIngressParams %q referenced by IngressClass %q does not exist", params.Name, *ing.Spec.IngressClassName)
+	}
+	return obj.(*ingparamsv1beta1.GCPIngressParams), nil
+}
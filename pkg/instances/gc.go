@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instances
+
+import (
+	"fmt"
+
+	"google.golang.org/api/compute/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog"
+
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+// ExplainGC returns the decisions garbage collection of the instance groups
+// with the given name would take, given the node ports still used by the
+// backends of Ingresses. If no node port is used, all backends were migrated
+// to NEGs and the instance groups are deleted. Otherwise the named ports which
+// were set by the controller for unused node ports are removed.
+func (i *Instances) ExplainGC(name string, ports []int64) ([]utils.GCDecision, error) {
+	zones, igs, err := i.instanceGroupsByZone(name)
+	if err != nil {
+		return nil, err
+	}
+	var decisions []utils.GCDecision
+	for _, zone := range zones {
+		igDecision, portDecisions, _ := i.gcDecisions(igs[zone], zone, ports)
+		decisions = append(decisions, igDecision)
+		decisions = append(decisions, portDecisions...)
+	}
+	return decisions, nil
+}
+
+// GC deletes the instance groups with the given name, or their unused named
+// ports, as explained by ExplainGC.
+func (i *Instances) GC(name string, ports []int64) error {
+	zones, igs, err := i.instanceGroupsByZone(name)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, zone := range zones {
+		igDecision, portDecisions, keep := i.gcDecisions(igs[zone], zone, ports)
+		if igDecision.Delete {
+			klog.V(2).Infof("GC decision: %v", igDecision)
+			if err := i.cloud.DeleteInstanceGroup(name, zone); err != nil {
+				if utils.IsInUsedByError(err) {
+					klog.V(3).Infof("Could not delete instance group %v in zone %v because it's still in use. Ignoring: %v", name, zone, err)
+				} else if !utils.IsNotFoundError(err) {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+		removed := false
+		for _, d := range portDecisions {
+			if d.Delete {
+				klog.V(2).Infof("GC decision: %v", d)
+				removed = true
+			}
+		}
+		if !removed {
+			continue
+		}
+		if err := i.cloud.SetNamedPortsOfInstanceGroup(name, zone, keep); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove unused named ports of instance group %v in zone %v: %v", name, zone, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// instanceGroupsByZone returns the zones in which an instance group with the
+// given name exists, and the instance groups by zone.
+func (i *Instances) instanceGroupsByZone(name string) ([]string, map[string]*compute.InstanceGroup, error) {
+	allZones, err := i.ListZones()
+	if err != nil {
+		return nil, nil, err
+	}
+	var zones []string
+	igs := map[string]*compute.InstanceGroup{}
+	for _, zone := range allZones {
+		ig, err := i.Get(name, zone)
+		if utils.IsNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		zones = append(zones, zone)
+		igs[zone] = ig
+	}
+	return zones, igs, nil
+}
+
+// gcDecisions returns the decisions for the given instance group and its named
+// ports, and the named ports to keep.
+func (i *Instances) gcDecisions(ig *compute.InstanceGroup, zone string, ports []int64) (utils.GCDecision, []utils.GCDecision, []*compute.NamedPort) {
+	igDecision := utils.GCDecision{Kind: "InstanceGroup", Name: ig.Name, Scope: zone}
+	if len(ports) == 0 {
+		igDecision.Evidence = "no Ingress backend uses instance groups, all use NEGs"
+		igDecision.Delete = true
+		return igDecision, nil, nil
+	}
+	igDecision.Evidence = fmt.Sprintf("node ports %v are used by Ingress backends", ports)
+
+	used := map[int64]bool{}
+	for _, port := range ports {
+		used[port] = true
+	}
+	var decisions []utils.GCDecision
+	var keep []*compute.NamedPort
+	for _, np := range ig.NamedPorts {
+		d := utils.GCDecision{Kind: "NamedPort", Name: fmt.Sprintf("%s/%s:%d", ig.Name, np.Name, np.Port), Scope: zone}
+		switch {
+		case np.Name != i.namer.NamedPort(np.Port):
+			d.Evidence = "not set by the controller"
+		case used[np.Port]:
+			d.Evidence = "node port of an Ingress backend"
+		default:
+			d.Evidence = "node port not used by any Ingress backend"
+			d.Delete = true
+		}
+		if !d.Delete {
+			keep = append(keep, np)
+		}
+		decisions = append(decisions, d)
+	}
+	return igDecision, decisions, keep
+}
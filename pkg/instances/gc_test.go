@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instances
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestInstanceGroupGC(t *testing.T) {
+	igName := defaultNamer.InstanceGroup()
+	userPort := &compute.NamedPort{Name: "http", Port: 8080}
+
+	for _, tc := range []struct {
+		desc          string
+		ports         []int64
+		wantDeletes   []string
+		wantIG        bool
+		wantPortNames []string
+	}{
+		{
+			desc:          "all node ports used",
+			ports:         []int64{30001, 30002},
+			wantIG:        true,
+			wantPortNames: []string{"port30001", "port30002", "http"},
+		},
+		{
+			desc:          "unused node port",
+			ports:         []int64{30001},
+			wantDeletes:   []string{"NamedPort " + igName + "/port30002:30002"},
+			wantIG:        true,
+			wantPortNames: []string{"port30001", "http"},
+		},
+		{
+			desc:        "all backends use NEGs",
+			wantDeletes: []string{"InstanceGroup " + igName},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := NewFakeInstanceGroups(sets.NewString("n1"), defaultNamer)
+			pool := newNodePool(f, defaultZone)
+			if _, err := pool.EnsureInstanceGroupsAndPorts(igName, []int64{30001, 30002}); err != nil {
+				t.Fatalf("EnsureInstanceGroupsAndPorts() = %v", err)
+			}
+			ig, _ := f.GetInstanceGroup(igName, defaultZone)
+			ig.NamedPorts = append(ig.NamedPorts, userPort)
+
+			decisions, err := pool.ExplainGC(igName, tc.ports)
+			if err != nil {
+				t.Fatalf("ExplainGC() = %v", err)
+			}
+			var gotDeletes []string
+			for _, d := range decisions {
+				if d.Delete {
+					gotDeletes = append(gotDeletes, d.Kind+" "+d.Name)
+				}
+			}
+			if !reflect.DeepEqual(gotDeletes, tc.wantDeletes) {
+				t.Errorf("ExplainGC() deletes %v, want %v", gotDeletes, tc.wantDeletes)
+			}
+
+			if err := pool.GC(igName, tc.ports); err != nil {
+				t.Fatalf("GC() = %v", err)
+			}
+			ig, err = f.GetInstanceGroup(igName, defaultZone)
+			if gotIG := err == nil; gotIG != tc.wantIG {
+				t.Fatalf("Instance group exists = %v, want %v", gotIG, tc.wantIG)
+			}
+			if !tc.wantIG {
+				return
+			}
+			var gotPortNames []string
+			for _, np := range ig.NamedPorts {
+				gotPortNames = append(gotPortNames, np.Name)
+			}
+			if !reflect.DeepEqual(gotPortNames, tc.wantPortNames) {
+				t.Errorf("Named ports = %v, want %v", gotPortNames, tc.wantPortNames)
+			}
+		})
+	}
+}
@@ -18,6 +18,8 @@ package instances
 
 import (
 	compute "google.golang.org/api/compute/v1"
+
+	"k8s.io/ingress-gce/pkg/utils"
 )
 
 // ZoneLister manages lookups for GCE instance groups/instances to zones.
@@ -34,6 +36,12 @@ type NodePool interface {
 	// The following 2 methods operate on instance groups.
 	EnsureInstanceGroupsAndPorts(name string, ports []int64) ([]*compute.InstanceGroup, error)
 	DeleteInstanceGroup(name string) error
+	// ExplainGC returns the decisions GC would take for the instance groups
+	// with the given name, given the node ports used by Ingress backends.
+	ExplainGC(name string, ports []int64) ([]utils.GCDecision, error)
+	// GC deletes the instance groups with the given name if no node port is
+	// used by Ingress backends, or their unused named ports otherwise.
+	GC(name string, ports []int64) error
 
 	// TODO: Refactor for modularity
 	Add(groupName string, nodeNames []string) error
@@ -17,6 +17,7 @@ limitations under the License.
 package l4
 
 import (
+	context2 "context"
 	"fmt"
 	"reflect"
 	"sync"
@@ -31,8 +32,11 @@ import (
 	"k8s.io/ingress-gce/pkg/backends"
 	"k8s.io/ingress-gce/pkg/context"
 	"k8s.io/ingress-gce/pkg/controller/translator"
+	"k8s.io/ingress-gce/pkg/events"
+	"k8s.io/ingress-gce/pkg/firewalls"
 	l4metrics "k8s.io/ingress-gce/pkg/l4/metrics"
 	"k8s.io/ingress-gce/pkg/loadbalancers"
+	usage "k8s.io/ingress-gce/pkg/metrics"
 	negtypes "k8s.io/ingress-gce/pkg/neg/types"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/ingress-gce/pkg/utils/common"
@@ -69,6 +73,8 @@ type L4Controller struct {
 	// syncTracker tracks the latest time an enqueued service was synced
 	syncTracker         utils.TimeTracker
 	sharedResourcesLock sync.Mutex
+	// hcFirewall is the firewall rule for health checks shared by all ILB services.
+	hcFirewall *firewalls.L4HealthCheckFirewall
 }
 
 // NewController creates a new instance of the L4 ILB controller.
@@ -89,8 +95,10 @@ func NewController(ctx *context.ControllerContext, stopCh chan struct{}) *L4Cont
 	l4c.translator = translator.NewTranslator(ctx)
 	l4c.backendPool = backends.NewPool(ctx.Cloud, l4c.namer)
 	l4c.NegLinker = backends.NewNEGLinker(l4c.backendPool, negtypes.NewAdapter(ctx.Cloud), ctx.Cloud)
+	_, hcFwName := l4c.namer.L4HealthCheck("", "", true)
+	l4c.hcFirewall = firewalls.NewL4HealthCheckFirewall(ctx.Cloud, hcFwName, l4c.healthCheckPorts)
 
-	l4c.svcQueue = utils.NewPeriodicTaskQueueWithMultipleWorkers("l4", "services", l4c.numWorkers, l4c.sync)
+	l4c.svcQueue = utils.NewPeriodicTaskQueueWithMultipleWorkers("l4", "services", l4c.numWorkers, utils.WithSyncDeadline("services", ctx.SyncDeadline, l4c.syncContext, l4c.syncDeadlineExceeded))
 
 	ctx.ServiceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
@@ -125,8 +133,9 @@ func NewController(ctx *context.ControllerContext, stopCh chan struct{}) *L4Cont
 			if needsILB && reflect.DeepEqual(old, cur) {
 				// this will happen when informers run a resync on all the existing services even when the object is
 				// not modified.
-				klog.V(3).Infof("Periodic enqueueing of %v", svcKey)
-				l4c.svcQueue.Enqueue(curSvc)
+				delay := utils.ResyncDelay(svcKey, l4c.ctx.ResyncSpread)
+				klog.V(3).Infof("Periodic enqueueing of %v in %v", svcKey, delay)
+				l4c.svcQueue.EnqueueAfter(curSvc, delay)
 				l4c.enqueueTracker.Track()
 			}
 		},
@@ -166,7 +175,7 @@ func (l4c *L4Controller) shutdown() {
 
 // processServiceCreateOrUpdate ensures load balancer resources for the given service, as needed.
 // Returns an error if processing the service update failed.
-func (l4c *L4Controller) processServiceCreateOrUpdate(key string, service *v1.Service) *loadbalancers.SyncResult {
+func (l4c *L4Controller) processServiceCreateOrUpdate(ctx context2.Context, key string, service *v1.Service) *loadbalancers.SyncResult {
 	// skip services that are being handled by the legacy service controller.
 	if utils.IsLegacyL4ILBService(service) {
 		klog.Warningf("Ignoring update for service %s:%s managed by service controller", service.Namespace, service.Name)
@@ -180,13 +189,17 @@ func (l4c *L4Controller) processServiceCreateOrUpdate(key string, service *v1.Se
 		return &loadbalancers.SyncResult{Error: fmt.Errorf("Failed to attach finalizer to service %s/%s, err %w", service.Namespace, service.Name, err)}
 	}
 	l4 := loadbalancers.NewL4Handler(service, l4c.ctx.Cloud, meta.Regional, l4c.namer, l4c.ctx.Recorder(service.Namespace), &l4c.sharedResourcesLock)
+	l4.HealthCheckFirewall = l4c.hcFirewall
 	nodeNames, err := utils.GetReadyNodeNames(l4c.nodeLister)
 	if err != nil {
 		return &loadbalancers.SyncResult{Error: err}
 	}
+	if err := utils.CheckSyncDeadline(ctx); err != nil {
+		return &loadbalancers.SyncResult{Error: err}
+	}
 	// Use the same function for both create and updates. If controller crashes and restarts,
 	// all existing services will show up as Service Adds.
-	syncResult := l4.EnsureInternalLoadBalancer(nodeNames, service)
+	syncResult := l4.EnsureInternalLoadBalancer(ctx, nodeNames, service)
 	// syncResult will not be nil
 	if syncResult.Error != nil {
 		l4c.ctx.Recorder(service.Namespace).Eventf(service, v1.EventTypeWarning, "SyncLoadBalancerFailed",
@@ -200,7 +213,11 @@ func (l4c *L4Controller) processServiceCreateOrUpdate(key string, service *v1.Se
 			l4.NamespacedName.String())
 		return syncResult
 	}
-	if err = l4c.linkNEG(l4); err != nil {
+	if err = utils.CheckSyncDeadline(ctx); err != nil {
+		syncResult.Error = err
+		return syncResult
+	}
+	if err = l4c.linkNEG(ctx, l4); err != nil {
 		l4c.ctx.Recorder(service.Namespace).Eventf(service, v1.EventTypeWarning, "SyncLoadBalancerFailed",
 			"Failed to link NEG with Backend Service for load balancer, err: %v", err)
 		syncResult.Error = err
@@ -224,10 +241,11 @@ func (l4c *L4Controller) processServiceCreateOrUpdate(key string, service *v1.Se
 	return syncResult
 }
 
-func (l4c *L4Controller) processServiceDeletion(key string, svc *v1.Service) *loadbalancers.SyncResult {
+func (l4c *L4Controller) processServiceDeletion(ctx context2.Context, key string, svc *v1.Service) *loadbalancers.SyncResult {
 	l4 := loadbalancers.NewL4Handler(svc, l4c.ctx.Cloud, meta.Regional, l4c.namer, l4c.ctx.Recorder(svc.Namespace), &l4c.sharedResourcesLock)
+	l4.HealthCheckFirewall = l4c.hcFirewall
 	l4c.ctx.Recorder(svc.Namespace).Eventf(svc, v1.EventTypeNormal, "DeletingLoadBalancer", "Deleting load balancer for %s", key)
-	result := l4.EnsureInternalLoadBalancerDeleted(svc)
+	result := l4.EnsureInternalLoadBalancerDeleted(ctx, svc)
 	if result.Error != nil {
 		l4c.ctx.Recorder(svc.Namespace).Eventf(svc, v1.EventTypeWarning, "DeleteLoadBalancerFailed", "Error deleting load balancer: %v", result.Error)
 		return result
@@ -259,7 +277,7 @@ func (l4c *L4Controller) processServiceDeletion(key string, svc *v1.Service) *lo
 }
 
 // linkNEG associates the NEG to the backendService for the given L4 ILB service.
-func (l4c *L4Controller) linkNEG(l4 *loadbalancers.L4) error {
+func (l4c *L4Controller) linkNEG(ctx context2.Context, l4 *loadbalancers.L4) error {
 	// link neg to backend service
 	zones, err := l4c.translator.ListZones()
 	if err != nil {
@@ -269,10 +287,28 @@ func (l4c *L4Controller) linkNEG(l4 *loadbalancers.L4) error {
 	for _, zone := range zones {
 		groupKeys = append(groupKeys, backends.GroupKey{Zone: zone})
 	}
-	return l4c.NegLinker.Link(l4.ServicePort, groupKeys)
+	return l4c.NegLinker.Link(ctx, l4.ServicePort, groupKeys)
 }
 
+// syncDeadlineExceeded reports that the sync of the service with the given key
+// exceeded the sync deadline.
+func (l4c *L4Controller) syncDeadlineExceeded(key string) {
+	usage.PublishSyncDeadlineExceeded("l4ilb")
+	svc, exists, err := l4c.ctx.Services().GetByKey(key)
+	if err != nil || !exists || svc == nil {
+		return
+	}
+	l4c.ctx.Recorder(svc.Namespace).Eventf(svc, v1.EventTypeWarning, events.SyncDeadlineExceeded, "Sync aborted after exceeding deadline of %v, requeued", l4c.ctx.SyncDeadline)
+}
+
+// sync syncs the service with the given key without a deadline.
 func (l4c *L4Controller) sync(key string) error {
+	return l4c.syncContext(context2.Background(), key)
+}
+
+// syncContext syncs the service with the given key. The GCE calls of the
+// sync are cancelled, and the sync is aborted, once ctx expires.
+func (l4c *L4Controller) syncContext(ctx context2.Context, key string) error {
 	l4c.syncTracker.Track()
 	svc, exists, err := l4c.ctx.Services().GetByKey(key)
 	if err != nil {
@@ -288,7 +324,7 @@ func (l4c *L4Controller) sync(key string) error {
 	var result *loadbalancers.SyncResult
 	if needsDeletion(svc) {
 		klog.V(2).Infof("Deleting ILB resources for service %s managed by L4 controller", key)
-		result = l4c.processServiceDeletion(key, svc)
+		result = l4c.processServiceDeletion(ctx, key, svc)
 		if result == nil {
 			return nil
 		}
@@ -299,7 +335,7 @@ func (l4c *L4Controller) sync(key string) error {
 	// longer needing an ILB.
 	if wantsILB, _ := annotations.WantsL4ILB(svc); wantsILB {
 		klog.V(2).Infof("Ensuring ILB resources for service %s managed by L4 controller", key)
-		result = l4c.processServiceCreateOrUpdate(key, svc)
+		result = l4c.processServiceCreateOrUpdate(ctx, key, svc)
 		if result == nil {
 			// result will be nil if the service was ignored(due to presence of service controller finalizer).
 			return nil
@@ -367,6 +403,20 @@ func mergeAnnotations(existing, ilbAnnotations map[string]string) map[string]str
 	return existing
 }
 
+// healthCheckPorts returns the health check port of every ILB service managed
+// by the controller, keyed by service.
+func (l4c *L4Controller) healthCheckPorts() (map[string]int32, error) {
+	ports := map[string]int32{}
+	for _, obj := range l4c.serviceLister.List() {
+		svc := obj.(*v1.Service)
+		if !common.HasGivenFinalizer(svc.ObjectMeta, common.ILBFinalizerV2) {
+			continue
+		}
+		ports[types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}.String()] = loadbalancers.HealthCheckPort(svc)
+	}
+	return ports, nil
+}
+
 func needsDeletion(svc *v1.Service) bool {
 	if !common.HasGivenFinalizer(svc.ObjectMeta, common.ILBFinalizerV2) {
 		return false
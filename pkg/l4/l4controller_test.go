@@ -18,6 +18,7 @@ package l4
 
 import (
 	context2 "context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -105,7 +106,7 @@ func addNEG(l4c *L4Controller, svc *api_v1.Service) {
 	negName, _ := l4c.namer.VMIPNEG(svc.Namespace, svc.Name)
 	neg := &composite.NetworkEndpointGroup{Name: negName}
 	key := meta.ZonalKey(negName, testGCEZone)
-	composite.CreateNetworkEndpointGroup(l4c.ctx.Cloud, key, neg)
+	composite.CreateNetworkEndpointGroup(context2.TODO(), l4c.ctx.Cloud, key, neg)
 }
 
 func getKeyForSvc(svc *api_v1.Service, t *testing.T) string {
@@ -409,3 +410,24 @@ func TestProcessServiceOnError(t *testing.T) {
 		ByErrorType:   map[string]uint64{http.StatusText(http.StatusInternalServerError): 1}}
 	prevMetrics.ValidateDiff(test.GetL4ILBErrorMetric(t), expectMetrics, t)
 }
+
+// TestProcessServiceSyncDeadline asserts that a sync which exceeded its
+// deadline is aborted before changing GCE resources.
+func TestProcessServiceSyncDeadline(t *testing.T) {
+	l4c := newServiceController(t, newFakeGCE())
+	newSvc := test.NewL4ILBService(false, 8080)
+	addILBService(l4c, newSvc)
+	addNEG(l4c, newSvc)
+	ctx, cancel := context2.WithDeadline(context2.Background(), time.Now())
+	defer cancel()
+	if err := l4c.syncContext(ctx, getKeyForSvc(newSvc, t)); !errors.Is(err, context2.DeadlineExceeded) {
+		t.Fatalf("syncContext(%s) = %v, want %v", newSvc.Name, err, context2.DeadlineExceeded)
+	}
+	frs, err := l4c.ctx.Cloud.ListRegionForwardingRules(l4c.ctx.Cloud.Region())
+	if err != nil {
+		t.Fatalf("ListRegionForwardingRules() = %v", err)
+	}
+	if len(frs) != 0 {
+		t.Errorf("Forwarding rules = %v, want none", frs)
+	}
+}
@@ -39,6 +39,8 @@ type addressManager struct {
 	region      string
 	subnetURL   string
 	tryRelease  bool
+	// purpose of the reserved address, if any.
+	purpose string
 }
 
 func newAddressManager(svc gce.CloudAddressService, serviceName, region, subnetURL, name, targetIP string, addressType cloud.LbScheme) *addressManager {
@@ -128,6 +130,7 @@ func (am *addressManager) ensureAddressReservation() (string, error) {
 		Address:     am.targetIP,
 		AddressType: string(am.addressType),
 		Subnetwork:  am.subnetURL,
+		Purpose:     am.purpose,
 	}
 
 	reserveErr := am.svc.ReserveRegionAddress(newAddr, am.region)
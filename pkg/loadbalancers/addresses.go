@@ -17,12 +17,16 @@ limitations under the License.
 package loadbalancers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/ingress-gce/pkg/annotations"
 	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/events"
 	"k8s.io/ingress-gce/pkg/flags"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/ingress-gce/pkg/utils/namer"
@@ -30,13 +34,13 @@ import (
 )
 
 // checkStaticIP reserves a regional or global static IP allocated to the Forwarding Rule.
-func (l *L7) checkStaticIP() (err error) {
-	if l.fw == nil || l.fw.IPAddress == "" {
+func (l *L7) checkStaticIP(ctx context.Context) (err error) {
+	if l.GetIP() == "" {
 		return fmt.Errorf("will not create static IP without a forwarding rule")
 	}
 	managedStaticIPName := l.namer.ForwardingRule(namer.HTTPProtocol)
 	// Don't manage staticIPs if the user has specified an IP.
-	address, manageStaticIP, err := l.getEffectiveIP()
+	address, manageStaticIP, err := l.getEffectiveIP(ctx)
 	if err != nil {
 		return err
 	}
@@ -45,7 +49,7 @@ func (l *L7) checkStaticIP() (err error) {
 		if flags.F.EnableDeleteUnusedFrontends {
 			// Delete ingress controller managed static ip if exists.
 			if ip, ok := l.ingress.Annotations[annotations.StaticIPKey]; ok && ip == managedStaticIPName {
-				return l.deleteStaticIP()
+				return l.deleteStaticIP(ctx)
 			}
 		}
 		return nil
@@ -56,22 +60,33 @@ func (l *L7) checkStaticIP() (err error) {
 		return err
 	}
 
-	ip, _ := composite.GetAddress(l.cloud, key, meta.VersionGA)
+	ip, _ := l.compositeCloud.GetAddress(ctx, key, meta.VersionGA)
+	if ip != nil && !sameIPVersion(ip.IpVersion, l.runtimeInfo.IPVersion) {
+		// The IP version of the Ingress changed. The static IP can only be
+		// replaced once no forwarding rule uses it anymore, until then the
+		// forwarding rules use ephemeral IPs of the new version.
+		klog.V(2).Infof("Static ip %v(%v) has the wrong IP version, recreating it", ip.Name, ip.Address)
+		if err := l.deleteStaticIP(ctx); err != nil {
+			klog.V(2).Infof("Failed to delete static ip %v, will retry once it is unused: %v", ip.Name, err)
+			return nil
+		}
+		ip = nil
+	}
 	if ip == nil {
 		klog.V(3).Infof("Creating static ip %v", managedStaticIPName)
 		address := l.newStaticAddress(managedStaticIPName)
 
-		err = composite.CreateAddress(l.cloud, key, address)
+		err = l.compositeCloud.CreateAddress(ctx, key, address)
 		if err != nil {
 			if utils.IsHTTPErrorCode(err, http.StatusConflict) ||
 				utils.IsHTTPErrorCode(err, http.StatusBadRequest) {
 				klog.V(3).Infof("IP %v(%v) is already reserved, assuming it is OK to use.",
-					l.fw.IPAddress, managedStaticIPName)
+					l.GetIP(), managedStaticIPName)
 				return nil
 			}
 			return err
 		}
-		ip, err = composite.GetAddress(l.cloud, key, meta.VersionGA)
+		ip, err = l.compositeCloud.GetAddress(ctx, key, meta.VersionGA)
 		if err != nil {
 			return err
 		}
@@ -80,9 +95,86 @@ func (l *L7) checkStaticIP() (err error) {
 	return nil
 }
 
+// ensureInternalStaticIP reserves the regional internal static IP of an L7-ILB
+// Ingress in the subnet given by ManagedStaticIPSubnet. The IP of an existing
+// forwarding rule in that subnet is kept. The subnet of a reserved IP is not
+// changed, as that requires all forwarding rules using it to be deleted.
+func (l *L7) ensureInternalStaticIP(ctx context.Context) error {
+	name := l.namer.ForwardingRule(namer.HTTPProtocol)
+	key, err := l.CreateKey(name)
+	if err != nil {
+		return err
+	}
+	subnetKey := meta.RegionalKey(l.runtimeInfo.ManagedStaticIPSubnet, l.cloud.Region())
+	subnetURL := cloud.SelfLink(meta.VersionGA, l.cloud.ProjectID(), "subnetworks", subnetKey)
+
+	ip, err := l.compositeCloud.GetAddress(ctx, key, meta.VersionGA)
+	if utils.IgnoreHTTPNotFound(err) != nil {
+		return err
+	}
+	if ip == nil {
+		address := &composite.Address{
+			Name:        name,
+			AddressType: "INTERNAL",
+			Subnetwork:  subnetURL,
+			Version:     meta.VersionGA,
+		}
+		for _, protocol := range []namer.NamerProtocol{namer.HTTPProtocol, namer.HTTPSProtocol} {
+			frKey, err := l.CreateKey(l.namer.ForwardingRule(protocol))
+			if err != nil {
+				return err
+			}
+			fr, _ := l.compositeCloud.GetForwardingRule(ctx, frKey, l.Versions().ForwardingRule)
+			if fr != nil && fr.IPAddress != "" && utils.EqualResourceIDs(fr.Subnetwork, subnetURL) {
+				address.Address = fr.IPAddress
+				break
+			}
+		}
+		klog.V(3).Infof("Creating internal static ip %v in subnet %v", name, l.runtimeInfo.ManagedStaticIPSubnet)
+		if err := l.compositeCloud.CreateAddress(ctx, key, address); err != nil {
+			return fmt.Errorf("failed to reserve static IP %s in subnet %s: %w", name, l.runtimeInfo.ManagedStaticIPSubnet, err)
+		}
+		if ip, err = l.compositeCloud.GetAddress(ctx, key, meta.VersionGA); err != nil {
+			return err
+		}
+		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "Static IP %q(%s) reserved in subnet %q", name, ip.Address, l.runtimeInfo.ManagedStaticIPSubnet)
+	} else if !utils.EqualResourceIDs(ip.Subnetwork, subnetURL) {
+		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeWarning, events.SyncIngress,
+			"Static IP %q(%s) is reserved in subnet %q, the Ingress must be recreated to move it to subnet %q", name, ip.Address, ip.Subnetwork, l.runtimeInfo.ManagedStaticIPSubnet)
+	}
+	l.ip = ip
+	l.runtimeInfo.StaticIPSubnet = ip.Subnetwork
+	return nil
+}
+
+// promoteHttpsIP reserves the IP of an existing HTTPS forwarding rule as the
+// static IP if there is no HTTP forwarding rule yet. An Ingress which switches
+// from HTTPS-only to serving both protocols thereby keeps its IP, instead of
+// creating the HTTP forwarding rule with an ephemeral IP which the HTTPS
+// forwarding rule is then moved to.
+func (l *L7) promoteHttpsIP(ctx context.Context) error {
+	if l.ip != nil {
+		return nil
+	}
+	fw, err := l.getForwardingRule(ctx, namer.HTTPProtocol)
+	if err != nil || fw != nil {
+		return err
+	}
+	fws, err := l.getForwardingRule(ctx, namer.HTTPSProtocol)
+	if err != nil || fws == nil || fws.IPAddress == "" {
+		return err
+	}
+	klog.V(3).Infof("Promoting ip %v of https forwarding rule %v before creating the http forwarding rule", fws.IPAddress, fws.Name)
+	l.fws = fws
+	return l.checkStaticIP(ctx)
+}
+
 func (l *L7) newStaticAddress(name string) *composite.Address {
 	isInternal := utils.IsGCEL7ILBIngress(&l.ingress)
-	address := &composite.Address{Name: name, Address: l.fw.IPAddress, Version: meta.VersionGA}
+	address := &composite.Address{Name: name, Address: l.GetIP(), Version: meta.VersionGA}
+	if l.isIPv6() {
+		address.IpVersion = annotations.IPv6Version
+	}
 	if isInternal {
 		// Used for L7 ILB
 		address.AddressType = "INTERNAL"
@@ -90,3 +182,15 @@ func (l *L7) newStaticAddress(name string) *composite.Address {
 
 	return address
 }
+
+// sameIPVersion returns true if the given IP versions of addresses or
+// forwarding rules are equal. An empty version is IPv4.
+func sameIPVersion(a, b string) bool {
+	if a == "" {
+		a = annotations.IPv4Version
+	}
+	if b == "" {
+		b = annotations.IPv4Version
+	}
+	return a == b
+}
@@ -32,6 +32,7 @@ func TestNewStaticAddress(t *testing.T) {
 		ip         string
 		name       string
 		isInternal bool
+		ipVersion  string
 		expected   *composite.Address
 	}{
 		{
@@ -48,13 +49,21 @@ func TestNewStaticAddress(t *testing.T) {
 			isInternal: true,
 			expected:   &composite.Address{Name: "internal-addr", Version: meta.VersionGA, Address: "10.2.3.4", AddressType: "INTERNAL"},
 		},
+		{
+			desc:      "external ipv6 static address",
+			ip:        "2600:1901:0:1234::",
+			name:      "external-addr",
+			ipVersion: annotations.IPv6Version,
+			expected:  &composite.Address{Name: "external-addr", Version: meta.VersionGA, Address: "2600:1901:0:1234::", IpVersion: annotations.IPv6Version},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
 			l7 := &L7{
-				ingress: v1.Ingress{Spec: v1.IngressSpec{}},
-				fw:      &composite.ForwardingRule{IPAddress: tc.ip},
+				ingress:     v1.Ingress{Spec: v1.IngressSpec{}},
+				fw:          &composite.ForwardingRule{IPAddress: tc.ip},
+				runtimeInfo: &L7RuntimeInfo{IPVersion: tc.ipVersion},
 			}
 
 			if tc.isInternal {
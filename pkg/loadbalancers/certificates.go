@@ -17,6 +17,7 @@ limitations under the License.
 package loadbalancers
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -28,18 +29,24 @@ import (
 
 const SslCertificateMissing = "SslCertificateMissing"
 
-func (l *L7) checkSSLCert() error {
+func (l *L7) checkSSLCert(ctx context.Context) error {
 	isL7ILB := utils.IsGCEL7ILBIngress(l.runtimeInfo.Ingress)
 	tr := translator.NewTranslator(isL7ILB, l.namer)
 	env := &translator.Env{Region: l.cloud.Region(), Project: l.cloud.ProjectID()}
-	translatorCerts := tr.ToCompositeSSLCertificates(env, l.runtimeInfo.TLSName, l.runtimeInfo.TLS, l.Versions().SslCertificate)
+	translatorCerts, err := tr.ToCompositeSSLCertificates(env, l.runtimeInfo.TLSName, l.runtimeInfo.TLS, l.Versions().SslCertificate)
 
 	// Use both pre-shared and secret-based certs if available,
 	// combining encountered errors.
 	errs := []error{}
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if err := l.checkSharedSslCerts(ctx, translatorCerts); err != nil {
+		errs = append(errs, err)
+	}
 
 	// Get updated value of certificate for comparison
-	existingSecretsSslCerts, err := l.getIngressManagedSslCerts()
+	existingSecretsSslCerts, err := l.getIngressManagedSslCerts(ctx)
 	if err != nil {
 		errs = append(errs, err)
 		// Do not continue if getIngressManagedSslCerts() failed.
@@ -47,7 +54,7 @@ func (l *L7) checkSSLCert() error {
 	}
 
 	l.oldSSLCerts = existingSecretsSslCerts
-	sslCerts, err := l.createSslCertificates(existingSecretsSslCerts, translatorCerts)
+	sslCerts, err := l.createSslCertificates(ctx, existingSecretsSslCerts, translatorCerts)
 	if err != nil {
 		errs = append(errs, err)
 	}
@@ -59,7 +66,7 @@ func (l *L7) checkSSLCert() error {
 }
 
 // createSslCertificates creates SslCertificates based on kubernetes secrets in Ingress configuration.
-func (l *L7) createSslCertificates(existingCerts, translatorCerts []*composite.SslCertificate) ([]*composite.SslCertificate, error) {
+func (l *L7) createSslCertificates(ctx context.Context, existingCerts, translatorCerts []*composite.SslCertificate) ([]*composite.SslCertificate, error) {
 	var result []*composite.SslCertificate
 
 	existingCertsMap := getMapfromCertList(existingCerts)
@@ -102,7 +109,7 @@ func (l *L7) createSslCertificates(existingCerts, translatorCerts []*composite.S
 			klog.Errorf("l.CreateKey(%s) = %v", translatorCert.Name, err)
 			return nil, err
 		}
-		err = composite.CreateSslCertificate(l.cloud, key, translatorCert)
+		err = l.compositeCloud.CreateSslCertificate(ctx, key, translatorCert)
 		if err != nil {
 			klog.Errorf("Failed to create new sslCertificate %q for %q - %v", translatorCert.Name, l, err)
 			failedCerts = append(failedCerts, translatorCert.Name+" Error:"+err.Error())
@@ -111,7 +118,7 @@ func (l *L7) createSslCertificates(existingCerts, translatorCerts []*composite.S
 		visitedCertMap[translatorCert.Name] = fmt.Sprintf("secret cert:%q", translatorCert.Certificate)
 
 		// Get SSLCert
-		cert, err := composite.GetSslCertificate(l.cloud, key, translatorCert.Version)
+		cert, err := l.compositeCloud.GetSslCertificate(ctx, key, translatorCert.Version)
 		if err != nil {
 			klog.Errorf("GetSslCertificate(_, %v, %v) = %v", key, translatorCert.Version, err)
 			return nil, err
@@ -140,7 +147,7 @@ func getMapfromCertList(certs []*composite.SslCertificate) map[string]*composite
 // getIngressManagedSslCerts fetches SslCertificate resources created and managed by this load balancer
 // instance. These SslCertificate resources were created based on kubernetes secrets in Ingress
 // configuration.
-func (l *L7) getIngressManagedSslCerts() ([]*composite.SslCertificate, error) {
+func (l *L7) getIngressManagedSslCerts(ctx context.Context) ([]*composite.SslCertificate, error) {
 	var result []*composite.SslCertificate
 
 	// Currently we list all certs available in gcloud and filter the ones managed by this loadbalancer instance. This is
@@ -153,7 +160,7 @@ func (l *L7) getIngressManagedSslCerts() ([]*composite.SslCertificate, error) {
 		return nil, err
 	}
 	version := l.Versions().SslCertificate
-	certs, err := composite.ListSslCertificates(l.cloud, key, version)
+	certs, err := l.compositeCloud.ListSslCertificates(ctx, key, version)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +173,7 @@ func (l *L7) getIngressManagedSslCerts() ([]*composite.SslCertificate, error) {
 	if len(result) == 0 {
 		// Check for legacy cert since that follows a different naming convention
 		klog.V(4).Infof("Looking for legacy ssl certs")
-		expectedCertLinks, err := l.getSslCertLinkInUse()
+		expectedCertLinks, err := l.getSslCertLinkInUse(ctx)
 		if err != nil {
 			// Return nil if target proxy doesn't exist.
 			return nil, utils.IgnoreHTTPNotFound(err)
@@ -186,7 +193,7 @@ func (l *L7) getIngressManagedSslCerts() ([]*composite.SslCertificate, error) {
 			if err != nil {
 				return nil, err
 			}
-			cert, _ := composite.GetSslCertificate(l.cloud, key, version)
+			cert, _ := l.compositeCloud.GetSslCertificate(ctx, key, version)
 			if cert != nil {
 				klog.V(4).Infof("Populating legacy ssl cert %s for l7 %s", cert.Name, l)
 				result = append(result, cert)
@@ -196,7 +203,7 @@ func (l *L7) getIngressManagedSslCerts() ([]*composite.SslCertificate, error) {
 	return result, nil
 }
 
-func (l *L7) deleteOldSSLCerts() {
+func (l *L7) deleteOldSSLCerts(ctx context.Context) {
 	if len(l.oldSSLCerts) == 0 {
 		return
 	}
@@ -212,7 +219,7 @@ func (l *L7) deleteOldSSLCerts() {
 		}
 		klog.V(3).Infof("Cleaning up old SSL Certificate %s", cert.Name)
 		key, _ := l.CreateKey(cert.Name)
-		if certErr := utils.IgnoreHTTPNotFound(composite.DeleteSslCertificate(l.cloud, key, l.Versions().SslCertificate)); certErr != nil {
+		if certErr := utils.IgnoreHTTPNotFound(l.compositeCloud.DeleteSslCertificate(ctx, key, l.Versions().SslCertificate)); certErr != nil {
 			klog.Errorf("Old cert %s delete failed - %v", cert.Name, certErr)
 		}
 	}
@@ -19,9 +19,14 @@ package loadbalancers
 import (
 	"context"
 	"fmt"
+	"sync"
+
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
 	"google.golang.org/api/compute/v1"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/utils"
+	"k8s.io/legacy-cloud-providers/gce"
 )
 
 const FakeCertQuota = 15
@@ -50,3 +55,126 @@ func InsertForwardingRuleHook(ctx context.Context, key *meta.Key, obj *compute.F
 	}
 	return false, nil
 }
+
+// FakeTargetSslProxies is an in-memory TargetSslProxies.
+type FakeTargetSslProxies struct {
+	lock    sync.Mutex
+	Proxies map[string]*compute.TargetSslProxy
+}
+
+// NewFakeTargetSslProxies creates a fake for target ssl proxies.
+func NewFakeTargetSslProxies() *FakeTargetSslProxies {
+	return &FakeTargetSslProxies{Proxies: make(map[string]*compute.TargetSslProxy)}
+}
+
+func (f *FakeTargetSslProxies) Get(ctx context.Context, name string) (*compute.TargetSslProxy, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	proxy, ok := f.Proxies[name]
+	if !ok {
+		return nil, utils.FakeGoogleAPINotFoundErr()
+	}
+	// Return a copy, callers must update the proxy through the setters.
+	copy := *proxy
+	return &copy, nil
+}
+
+func (f *FakeTargetSslProxies) Insert(ctx context.Context, proxy *compute.TargetSslProxy) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.Proxies[proxy.Name]; ok {
+		return fmt.Errorf("target ssl proxy %s already exists", proxy.Name)
+	}
+	proxy.SelfLink = cloud.SelfLink(meta.VersionGA, "mock-project", "targetSslProxies", meta.GlobalKey(proxy.Name))
+	f.Proxies[proxy.Name] = proxy
+	return nil
+}
+
+func (f *FakeTargetSslProxies) SetBackendService(ctx context.Context, name, backendServiceLink string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	proxy, ok := f.Proxies[name]
+	if !ok {
+		return utils.FakeGoogleAPINotFoundErr()
+	}
+	proxy.Service = backendServiceLink
+	return nil
+}
+
+func (f *FakeTargetSslProxies) SetSslCertificates(ctx context.Context, name string, certLinks []string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	proxy, ok := f.Proxies[name]
+	if !ok {
+		return utils.FakeGoogleAPINotFoundErr()
+	}
+	proxy.SslCertificates = certLinks
+	return nil
+}
+
+func (f *FakeTargetSslProxies) Delete(ctx context.Context, name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.Proxies[name]; !ok {
+		return utils.FakeGoogleAPINotFoundErr()
+	}
+	delete(f.Proxies, name)
+	return nil
+}
+
+// FakeUrlMapValidator is an UrlMapValidator which fails the validation of the
+// url maps with errors in LoadErrors.
+type FakeUrlMapValidator struct {
+	lock sync.Mutex
+	// LoadErrors are the validation errors of url maps by name.
+	LoadErrors map[string][]string
+	// Validated are the names of the validated url maps.
+	Validated []string
+	// Projects are the projects of the validated url maps.
+	Projects []string
+}
+
+// NewFakeUrlMapValidator creates a fake url map validator.
+func NewFakeUrlMapValidator() *FakeUrlMapValidator {
+	return &FakeUrlMapValidator{LoadErrors: make(map[string][]string)}
+}
+
+func (f *FakeUrlMapValidator) Validate(ctx context.Context, cloud *gce.Cloud, name string, um *compute.UrlMap) (*compute.UrlMapValidationResult, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.Validated = append(f.Validated, name)
+	f.Projects = append(f.Projects, cloud.ProjectID())
+	loadErrors := f.LoadErrors[name]
+	return &compute.UrlMapValidationResult{LoadSucceeded: len(loadErrors) == 0, LoadErrors: loadErrors}, nil
+}
+
+// FakeSharedSslCertificates is a SharedSslCertificates which serves the
+// certificates in Certs and denies access to the projects in Forbidden.
+type FakeSharedSslCertificates struct {
+	lock sync.Mutex
+	// Certs are the certificates by relative resource name.
+	Certs map[string]*composite.SslCertificate
+	// Forbidden are the projects the controller is not allowed to read.
+	Forbidden map[string]bool
+}
+
+// NewFakeSharedSslCertificates creates a fake for shared certificates.
+func NewFakeSharedSslCertificates() *FakeSharedSslCertificates {
+	return &FakeSharedSslCertificates{
+		Certs:     make(map[string]*composite.SslCertificate),
+		Forbidden: make(map[string]bool),
+	}
+}
+
+func (f *FakeSharedSslCertificates) Get(ctx context.Context, project string, key *meta.Key) (*composite.SslCertificate, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.Forbidden[project] {
+		return nil, utils.FakeGoogleAPIForbiddenErr()
+	}
+	cert, ok := f.Certs[cloud.RelativeResourceName(project, "sslCertificates", key)]
+	if !ok {
+		return nil, utils.FakeGoogleAPINotFoundErr()
+	}
+	return cert, nil
+}
@@ -18,6 +18,8 @@ limitations under the License.
 package features
 
 import (
+	"sort"
+
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
 	v1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -46,9 +48,17 @@ type ResourceVersions struct {
 }
 
 var (
-	// versionToFeatures stores the mapping from the resource to the required API
-	// version to feature names.  This is necessary since a feature could
-	// require using different versions for each resource.
+	// featureGates stores for each feature whether it is used by an Ingress.
+	featureGates = map[string]func(ing *v1.Ingress) bool{
+		FeatureL7ILB: utils.IsGCEL7ILBIngress,
+	}
+
+	// featureToVersions stores the mapping from the feature names to the API
+	// versions they require for each resource. A feature only sets the
+	// resources which need a pre-GA API, e.g. only TargetHttpsProxy, and all
+	// other resources stay on GA. The versions of the features used by an
+	// Ingress are resolved per resource, so enabling one pre-GA feature does
+	// not move every resource of the Ingress to the alpha or beta API.
 	// must not be nil
 	featureToVersions = map[string]*ResourceVersions{
 		FeatureL7ILB: &l7IlbVersions,
@@ -62,9 +72,8 @@ var (
 		meta.Regional: {FeatureL7ILB},
 	}
 
-	// All of these fields must be filled in to allow L7ILBVersions() to work
-	// TODO(shance) Remove this entirely
-	l7IlbVersions = *NewResourceVersions()
+	// All L7-ILB resources are GA.
+	l7IlbVersions = ResourceVersions{}
 )
 
 func NewResourceVersions() *ResourceVersions {
@@ -91,22 +100,29 @@ func (r *ResourceVersions) merge(other *ResourceVersions) *ResourceVersions {
 	}
 }
 
-// featuresFromIngress returns the features enabled by an ingress
+// featuresFromIngress returns the features enabled by an ingress, sorted by
+// name.
 func featuresFromIngress(ing *v1.Ingress) []string {
 	var result []string
-	if utils.IsGCEL7ILBIngress(ing) {
-		result = append(result, FeatureL7ILB)
+	for feature, enabled := range featureGates {
+		if enabled(ing) {
+			result = append(result, feature)
+		}
 	}
+	sort.Strings(result)
 	return result
 }
 
-// versionFromFeatures returns the meta.Version required for a list of features
+// versionsFromFeatures returns the meta.Version required for each resource by
+// a list of features. A resource which is not set by any of the features uses
+// meta.VersionGA.
 func versionsFromFeatures(features []string) *ResourceVersions {
 	result := NewResourceVersions()
 
 	for _, feature := range features {
-		versions := featureToVersions[feature]
-		result = result.merge(versions)
+		if versions, ok := featureToVersions[feature]; ok {
+			result = result.merge(versions)
+		}
 	}
 
 	return result
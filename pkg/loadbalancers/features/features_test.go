@@ -29,8 +29,9 @@ const (
 	fakeGaFeature    = "fakegafeature"
 	fakeAlphaFeature = "fakealphafeature"
 	fakeBetaFeature  = "fakebetafeature"
-	// This fake feature is used to test per-resource versions
-	fakeAlphaFeatureUrlMapOnly = "fakealphafeatureurlmaponly"
+	// These fake features are used to test per-resource versions
+	fakeAlphaFeatureUrlMapOnly          = "fakealphafeatureurlmaponly"
+	fakeBetaFeatureTargetHttpsProxyOnly = "fakebetafeaturetargethttpsproxyonly"
 
 	fakeGlobalFeature   = "fakeglobalfeature"
 	fakeRegionalFeature = "fakeRegionalFeature"
@@ -62,6 +63,9 @@ var (
 	fakeAlphaFeatureUrlMapOnlyVersions = ResourceVersions{
 		UrlMap: meta.VersionAlpha,
 	}
+	fakeBetaFeatureTargetHttpsProxyOnlyVersions = ResourceVersions{
+		TargetHttpsProxy: meta.VersionBeta,
+	}
 
 	emptyIng = networkingv1.Ingress{
 		ObjectMeta: v1.ObjectMeta{
@@ -76,10 +80,11 @@ var (
 	}
 
 	fakeFeatureToVersions = map[string]*ResourceVersions{
-		fakeGaFeature:              GAResourceVersions,
-		fakeAlphaFeature:           &fakeAlphaFeatureVersions,
-		fakeBetaFeature:            &fakeBetaFeatureVersions,
-		fakeAlphaFeatureUrlMapOnly: &fakeAlphaFeatureUrlMapOnlyVersions,
+		fakeGaFeature:                       GAResourceVersions,
+		fakeAlphaFeature:                    &fakeAlphaFeatureVersions,
+		fakeBetaFeature:                     &fakeBetaFeatureVersions,
+		fakeAlphaFeatureUrlMapOnly:          &fakeAlphaFeatureUrlMapOnlyVersions,
+		fakeBetaFeatureTargetHttpsProxyOnly: &fakeBetaFeatureTargetHttpsProxyOnlyVersions,
 	}
 )
 
@@ -214,6 +219,24 @@ func TestVersionsFromFeatures(t *testing.T) {
 			features: []string{fakeGaFeature, fakeAlphaFeatureUrlMapOnly},
 			expected: NewResourceVersions().merge(&ResourceVersions{UrlMap: meta.VersionAlpha}),
 		},
+		{
+			desc:     "Pre-GA features of different resources",
+			features: []string{fakeAlphaFeatureUrlMapOnly, fakeBetaFeatureTargetHttpsProxyOnly},
+			expected: &ResourceVersions{
+				UrlMap:           meta.VersionAlpha,
+				ForwardingRule:   meta.VersionGA,
+				TargetHttpProxy:  meta.VersionGA,
+				TargetHttpsProxy: meta.VersionBeta,
+				SslCertificate:   meta.VersionGA,
+				BackendService:   meta.VersionGA,
+				HealthCheck:      meta.VersionGA,
+			},
+		},
+		{
+			desc:     "Unknown feature",
+			features: []string{"unknown"},
+			expected: GAResourceVersions,
+		},
 		{
 			desc: "Many features",
 			features: []string{
@@ -247,6 +270,49 @@ func TestVersionsFromFeatures(t *testing.T) {
 	}
 }
 
+func TestVersionsFromIngress(t *testing.T) {
+	defer func(gates map[string]func(*networkingv1.Ingress) bool, versions map[string]*ResourceVersions) {
+		featureGates, featureToVersions = gates, versions
+	}(featureGates, featureToVersions)
+	featureGates = map[string]func(*networkingv1.Ingress) bool{
+		fakeAlphaFeatureUrlMapOnly: func(ing *networkingv1.Ingress) bool {
+			return ing.Annotations["urlmap"] == "true"
+		},
+		fakeBetaFeatureTargetHttpsProxyOnly: func(ing *networkingv1.Ingress) bool {
+			return ing.Annotations["https-proxy"] == "true"
+		},
+	}
+	featureToVersions = fakeFeatureToVersions
+
+	for _, tc := range []struct {
+		desc        string
+		annotations map[string]string
+		expected    *ResourceVersions
+	}{
+		{
+			desc:     "No features",
+			expected: GAResourceVersions,
+		},
+		{
+			desc:        "Only the target https proxy is beta",
+			annotations: map[string]string{"https-proxy": "true"},
+			expected:    NewResourceVersions().merge(&ResourceVersions{TargetHttpsProxy: meta.VersionBeta}),
+		},
+		{
+			desc:        "Both features",
+			annotations: map[string]string{"https-proxy": "true", "urlmap": "true"},
+			expected:    NewResourceVersions().merge(&ResourceVersions{UrlMap: meta.VersionAlpha, TargetHttpsProxy: meta.VersionBeta}),
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ing := &networkingv1.Ingress{ObjectMeta: v1.ObjectMeta{Annotations: tc.annotations}}
+			if result := VersionsFromIngress(ing); !reflect.DeepEqual(result, tc.expected) {
+				t.Fatalf("want %+v, got %+v", tc.expected, result)
+			}
+		})
+	}
+}
+
 func TestScopeFromFeatures(t *testing.T) {
 	testCases := []struct {
 		desc     string
@@ -17,7 +17,9 @@ limitations under the License.
 package loadbalancers
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
@@ -38,16 +40,16 @@ import (
 // maxL4ILBPorts is the maximum number of ports that can be specified in an L4 ILB Forwarding Rule
 const maxL4ILBPorts = 5
 
-func (l *L7) checkHttpForwardingRule() (err error) {
+func (l *L7) checkHttpForwardingRule(ctx context.Context) (err error) {
 	if l.tp == nil {
 		return fmt.Errorf("cannot create forwarding rule without proxy")
 	}
 	name := l.namer.ForwardingRule(namer.HTTPProtocol)
-	address, _, err := l.getEffectiveIP()
+	address, _, err := l.getEffectiveIP(ctx)
 	if err != nil {
 		return err
 	}
-	fw, err := l.checkForwardingRule(namer.HTTPProtocol, name, l.tp.SelfLink, address)
+	fw, err := l.checkForwardingRule(ctx, namer.HTTPProtocol, name, l.tp.SelfLink, address)
 	if err != nil {
 		return err
 	}
@@ -55,17 +57,17 @@ func (l *L7) checkHttpForwardingRule() (err error) {
 	return nil
 }
 
-func (l *L7) checkHttpsForwardingRule() (err error) {
+func (l *L7) checkHttpsForwardingRule(ctx context.Context) (err error) {
 	if l.tps == nil {
 		klog.V(3).Infof("No https target proxy for %v, not created https forwarding rule", l)
 		return nil
 	}
 	name := l.namer.ForwardingRule(namer.HTTPSProtocol)
-	address, _, err := l.getEffectiveIP()
+	address, _, err := l.getEffectiveIP(ctx)
 	if err != nil {
 		return err
 	}
-	fws, err := l.checkForwardingRule(namer.HTTPSProtocol, name, l.tps.SelfLink, address)
+	fws, err := l.checkForwardingRule(ctx, namer.HTTPSProtocol, name, l.tps.SelfLink, address)
 	if err != nil {
 		return err
 	}
@@ -73,7 +75,21 @@ func (l *L7) checkHttpsForwardingRule() (err error) {
 	return nil
 }
 
-func (l *L7) checkForwardingRule(protocol namer.NamerProtocol, name, proxyLink, ip string) (existing *composite.ForwardingRule, err error) {
+// getForwardingRule returns the forwarding rule of the given protocol, or nil
+// if it does not exist.
+func (l *L7) getForwardingRule(ctx context.Context, protocol namer.NamerProtocol) (*composite.ForwardingRule, error) {
+	key, err := l.CreateKey(l.namer.ForwardingRule(protocol))
+	if err != nil {
+		return nil, err
+	}
+	fr, err := l.compositeCloud.GetForwardingRule(ctx, key, l.Versions().ForwardingRule)
+	if err != nil {
+		return nil, utils.IgnoreHTTPNotFound(err)
+	}
+	return fr, nil
+}
+
+func (l *L7) checkForwardingRule(ctx context.Context, protocol namer.NamerProtocol, name, proxyLink, ip string) (existing *composite.ForwardingRule, err error) {
 	key, err := l.CreateKey(name)
 	if err != nil {
 		return nil, err
@@ -86,18 +102,17 @@ func (l *L7) checkForwardingRule(protocol namer.NamerProtocol, name, proxyLink,
 
 	isL7ILB := utils.IsGCEL7ILBIngress(l.runtimeInfo.Ingress)
 	tr := translator.NewTranslator(isL7ILB, l.namer)
-	env := &translator.Env{VIP: ip, Network: l.cloud.NetworkURL(), Subnetwork: l.cloud.SubnetworkURL()}
+	env := &translator.Env{VIP: ip, Network: l.cloud.NetworkURL(), Subnetwork: l.cloud.SubnetworkURL(), IPVersion: l.runtimeInfo.IPVersion}
 	fr := tr.ToCompositeForwardingRule(env, protocol, version, proxyLink, description, l.runtimeInfo.StaticIPSubnet)
 
-	existing, _ = composite.GetForwardingRule(l.cloud, key, version)
-	if existing != nil && (fr.IPAddress != "" && existing.IPAddress != fr.IPAddress || existing.PortRange != fr.PortRange) {
-		klog.Warningf("Recreating forwarding rule %v(%v), so it has %v(%v)",
-			existing.IPAddress, existing.PortRange, fr.IPAddress, fr.PortRange)
-		if err = utils.IgnoreHTTPNotFound(composite.DeleteForwardingRule(l.cloud, key, version)); err != nil {
-			return nil, err
+	existing, _ = l.compositeCloud.GetForwardingRule(ctx, key, version)
+	if existing != nil {
+		if fields := immutableForwardingRuleDiff(existing, fr); len(fields) > 0 {
+			if err := l.recreateForwardingRule(ctx, key, existing, fr, fields); err != nil {
+				return nil, err
+			}
+			existing = nil
 		}
-		existing = nil
-		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "ForwardingRule %q deleted", key.Name)
 	}
 	if existing == nil {
 		// This is a special case where exactly one of http or https forwarding rule
@@ -110,7 +125,7 @@ func (l *L7) checkForwardingRule(protocol namer.NamerProtocol, name, proxyLink,
 			// Note that this Static IP annotation is applied by ingress controller.
 			if currentIPName, exists := l.ingress.Annotations[annotations.StaticIPKey]; exists && currentIPName == managedStaticIPName {
 				currentIP, _ := l.cloud.GetGlobalAddress(managedStaticIPName)
-				if currentIP != nil {
+				if currentIP != nil && sameIPVersion(currentIP.IpVersion, fr.IpVersion) {
 					klog.V(3).Infof("Ingress managed static IP %s(%s) exists, using it to create forwarding rule %s", currentIPName, currentIP.Address, name)
 					fr.IPAddress = currentIP.Address
 				}
@@ -118,7 +133,7 @@ func (l *L7) checkForwardingRule(protocol namer.NamerProtocol, name, proxyLink,
 		}
 		klog.V(3).Infof("Creating forwarding rule for proxy %q and ip %v:%v", proxyLink, ip, protocol)
 
-		if err = composite.CreateForwardingRule(l.cloud, key, fr); err != nil {
+		if err = l.compositeCloud.CreateForwardingRule(ctx, key, fr); err != nil {
 			return nil, err
 		}
 		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "ForwardingRule %q created", key.Name)
@@ -127,12 +142,11 @@ func (l *L7) checkForwardingRule(protocol namer.NamerProtocol, name, proxyLink,
 		if err != nil {
 			return nil, err
 		}
-		existing, err = composite.GetForwardingRule(l.cloud, key, version)
+		existing, err = l.compositeCloud.GetForwardingRule(ctx, key, version)
 		if err != nil {
 			return nil, err
 		}
 	}
-	// TODO: If the port range and protocol don't match, recreate the rule
 	if utils.EqualResourceIDs(existing.Target, proxyLink) {
 		klog.V(4).Infof("Forwarding rule %v already exists", existing.Name)
 	} else {
@@ -142,17 +156,108 @@ func (l *L7) checkForwardingRule(protocol namer.NamerProtocol, name, proxyLink,
 		if err != nil {
 			return nil, err
 		}
-		if err := composite.SetProxyForForwardingRule(l.cloud, key, existing, proxyLink); err != nil {
+		if err := l.compositeCloud.SetProxyForForwardingRule(ctx, key, existing, proxyLink); err != nil {
 			return nil, err
 		}
 	}
 	return existing, nil
 }
 
+// immutableForwardingRuleFields are the fields of a forwarding rule set by the
+// translator which GCE does not update. The translator leaves the network
+// tier unset: global forwarding rules only support the premium tier, and
+// internal ones have none.
+var immutableForwardingRuleFields = []utils.FieldSpec{
+	{Path: "IPAddress", Optional: true},
+	{Path: "PortRange"},
+	{Path: "IPProtocol", Optional: true},
+	{Path: "IpVersion", ServerDefault: annotations.IPv4Version},
+	{Path: "Subnetwork", Optional: true, ResourceID: true},
+}
+
+// immutableForwardingRuleDiff returns the fields which GCE does not update in
+// which the existing forwarding rule differs from the expected one. Changing
+// them requires the forwarding rule to be deleted and created again.
+func immutableForwardingRuleDiff(existing, expected *composite.ForwardingRule) []string {
+	return utils.DiffFields(expected, existing, immutableForwardingRuleFields)
+}
+
+// recreateForwardingRule deletes the existing forwarding rule, so that it is
+// created again with the given immutable fields changed. The target proxy is
+// kept, so traffic only stops until the forwarding rule is created again.
+// The VIP is kept if the forwarding rule uses an ephemeral IP and neither its
+// IP version nor its subnetwork change: the IP is reserved as the static IP
+// managed for the Ingress before the forwarding rule is deleted, as done when
+// an Ingress starts to serve both HTTP and HTTPS. Otherwise the VIP changes,
+// which is reported by a warning event.
+func (l *L7) recreateForwardingRule(ctx context.Context, key *meta.Key, existing, expected *composite.ForwardingRule, fields []string) error {
+	keepIP := expected.IPAddress == "" && existing.IPAddress != "" &&
+		sameIPVersion(existing.IpVersion, expected.IpVersion) &&
+		(expected.Subnetwork == "" || utils.EqualResourceIDs(existing.Subnetwork, expected.Subnetwork))
+	if keepIP {
+		if err := l.reserveForwardingRuleIP(ctx, existing); err != nil {
+			klog.Warningf("Failed to reserve IP %v of forwarding rule %v before recreating it, the IP will change: %v", existing.IPAddress, existing.Name, err)
+			keepIP = false
+		} else {
+			expected.IPAddress = existing.IPAddress
+		}
+	}
+
+	if keepIP || expected.IPAddress == existing.IPAddress {
+		klog.V(2).Infof("Recreating forwarding rule %v to change %v, keeping IP %v", existing.Name, fields, existing.IPAddress)
+		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress,
+			"ForwardingRule %q is recreated to change %s, its IP %s is kept", key.Name, strings.Join(fields, ", "), existing.IPAddress)
+	} else {
+		klog.Warningf("Recreating forwarding rule %v to change %v, its IP %v changes", existing.Name, fields, existing.IPAddress)
+		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeWarning, events.SyncIngress,
+			"ForwardingRule %q is recreated to change %s, its IP %s changes", key.Name, strings.Join(fields, ", "), existing.IPAddress)
+	}
+	if err := utils.IgnoreHTTPNotFound(l.compositeCloud.DeleteForwardingRule(ctx, key, existing.Version)); err != nil {
+		return err
+	}
+	l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "ForwardingRule %q deleted", key.Name)
+	return nil
+}
+
+// reserveForwardingRuleIP reserves the ephemeral IP of the forwarding rule as
+// the static IP managed for the Ingress, which is released with the Ingress.
+func (l *L7) reserveForwardingRuleIP(ctx context.Context, fr *composite.ForwardingRule) error {
+	name := l.namer.ForwardingRule(namer.HTTPProtocol)
+	key, err := l.CreateKey(name)
+	if err != nil {
+		return err
+	}
+	ip, err := l.compositeCloud.GetAddress(ctx, key, meta.VersionGA)
+	if utils.IgnoreHTTPNotFound(err) != nil {
+		return err
+	}
+	if ip != nil {
+		if ip.Address != fr.IPAddress {
+			return fmt.Errorf("static IP %v is reserved with IP %v", name, ip.Address)
+		}
+		l.ip = ip
+		return nil
+	}
+	address := &composite.Address{Name: name, Address: fr.IPAddress, IpVersion: fr.IpVersion, Version: meta.VersionGA}
+	if l.Regional() {
+		address.AddressType = "INTERNAL"
+		address.Subnetwork = fr.Subnetwork
+	}
+	klog.V(3).Infof("Reserving IP %v of forwarding rule %v as static IP %v", fr.IPAddress, fr.Name, name)
+	if err := l.compositeCloud.CreateAddress(ctx, key, address); err != nil {
+		return err
+	}
+	if l.ip, err = l.compositeCloud.GetAddress(ctx, key, meta.VersionGA); err != nil {
+		return err
+	}
+	l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "Static IP %q(%s) reserved", name, fr.IPAddress)
+	return nil
+}
+
 // getEffectiveIP returns a string with the IP to use in the HTTP and HTTPS
 // forwarding rules, a boolean indicating if this is an IP the controller
 // should manage or not and an error if the specified IP was not found.
-func (l *L7) getEffectiveIP() (string, bool, error) {
+func (l *L7) getEffectiveIP(ctx context.Context) (string, bool, error) {
 
 	// A note on IP management:
 	// User specifies a different IP on startup:
@@ -179,9 +284,12 @@ func (l *L7) getEffectiveIP() (string, bool, error) {
 		// Existing static IPs allocated to forwarding rules will get orphaned
 		// till the Ingress is torn down.
 		// TODO(shance): Replace version
-		if ip, err := composite.GetAddress(l.cloud, key, meta.VersionGA); err != nil || ip == nil {
+		if ip, err := l.compositeCloud.GetAddress(ctx, key, meta.VersionGA); err != nil || ip == nil {
 			return "", false, fmt.Errorf("the given static IP name %v doesn't translate to an existing static IP.",
 				l.runtimeInfo.StaticIPName)
+		} else if !sameIPVersion(ip.IpVersion, l.runtimeInfo.IPVersion) {
+			return "", false, fmt.Errorf("the given static IP %v does not match the IP version of the Ingress (%s annotation)",
+				l.runtimeInfo.StaticIPName, annotations.IPVersionKey)
 		} else {
 			l.runtimeInfo.StaticIPSubnet = ip.Subnetwork
 			return ip.Address, false, nil
@@ -193,9 +301,11 @@ func (l *L7) getEffectiveIP() (string, bool, error) {
 	return "", true, nil
 }
 
-// ensureForwardingRule creates a forwarding rule with the given name, if it does not exist. It updates the existing
-// forwarding rule if needed.
-func (l *L4) ensureForwardingRule(loadBalancerName, bsLink string, options gce.ILBOptions, existingFwdRule *composite.ForwardingRule) (*composite.ForwardingRule, error) {
+// ensureForwardingRule creates a forwarding rule with the given name, protocol and ports, if it does not exist. It
+// updates the existing forwarding rule if needed. The IP of ipFwdRule is reused, which is usually the existing
+// forwarding rule. If sharedAddressName is set, the IP of the forwarding rule is held by the address with that name,
+// which is kept so that the forwarding rules of the other protocols use the same IP.
+func (l *L4) ensureForwardingRule(ctx context.Context, loadBalancerName, bsLink string, options gce.ILBOptions, existingFwdRule, ipFwdRule *composite.ForwardingRule, protocol v1.Protocol, ports []string, sharedAddressName string) (*composite.ForwardingRule, error) {
 	key, err := l.CreateKey(loadBalancerName)
 	if err != nil {
 		return nil, err
@@ -219,20 +329,31 @@ func (l *L4) ensureForwardingRule(loadBalancerName, bsLink string, options gce.I
 	}
 	// Determine IP which will be used for this LB. If no forwarding rule has been established
 	// or specified in the Service spec, then requestedIP = "".
-	ipToUse := ilbIPToUse(l.Service, existingFwdRule, subnetworkURL)
+	ipToUse := ilbIPToUse(l.Service, ipFwdRule, subnetworkURL)
 	klog.V(2).Infof("ensureForwardingRule(%v): Using subnet %s for LoadBalancer IP %s", loadBalancerName, options.SubnetName, ipToUse)
 
 	var addrMgr *addressManager
 	// If the network is not a legacy network, use the address manager
 	if !l.cloud.IsLegacyNetwork() {
 		nm := types.NamespacedName{Namespace: l.Service.Namespace, Name: l.Service.Name}.String()
-		addrMgr = newAddressManager(l.cloud, nm, l.cloud.Region(), subnetworkURL, loadBalancerName, ipToUse, cloud.SchemeInternal)
+		addrName := loadBalancerName
+		if sharedAddressName != "" {
+			addrName = sharedAddressName
+		}
+		addrMgr = newAddressManager(l.cloud, nm, l.cloud.Region(), subnetworkURL, addrName, ipToUse, cloud.SchemeInternal)
+		if sharedAddressName != "" {
+			addrMgr.purpose = sharedVIPPurpose
+		}
 		ipToUse, err = addrMgr.HoldAddress()
 		if err != nil {
 			return nil, err
 		}
 		klog.V(2).Infof("ensureForwardingRule(%v): reserved IP %q for the forwarding rule", loadBalancerName, ipToUse)
 		defer func() {
+			if sharedAddressName != "" {
+				// The shared address is deleted with the load balancer.
+				return
+			}
 			// Release the address that was reserved, in all cases. If the forwarding rule was successfully created,
 			// the ephemeral IP is not needed anymore. If it was not created, the address should be released to prevent leaks.
 			if err := addrMgr.ReleaseAddress(); err != nil {
@@ -241,7 +362,6 @@ func (l *L4) ensureForwardingRule(loadBalancerName, bsLink string, options gce.I
 		}()
 	}
 
-	ports, _, protocol := utils.GetPortsAndProtocol(l.Service.Spec.Ports)
 	// Create the forwarding rule
 	frDesc, err := utils.MakeL4ILBServiceDescription(utils.ServiceKeyFunc(l.Service.Namespace, l.Service.Name), ipToUse,
 		version, false)
@@ -282,25 +402,25 @@ func (l *L4) ensureForwardingRule(loadBalancerName, bsLink string, options gce.I
 		// If the forwarding rule pointed to a backend service which does not match the controller naming scheme,
 		// that resouce could be leaked. It is not being deleted here because that is a user-managed resource.
 		klog.V(2).Infof("ensureForwardingRule: forwarding rule changed - Existing - %+v\n, New - %+v\n, Diff(-existing, +new) - %s\n. Deleting existing forwarding rule.", existingFwdRule, fr, frDiff)
-		if err = utils.IgnoreHTTPNotFound(composite.DeleteForwardingRule(l.cloud, key, version)); err != nil {
+		if err = utils.IgnoreHTTPNotFound(composite.DeleteForwardingRule(ctx, l.cloud, key, version)); err != nil {
 			return nil, err
 		}
 		l.recorder.Eventf(l.Service, corev1.EventTypeNormal, events.SyncIngress, "ForwardingRule %q deleted", key.Name)
 	}
 	klog.V(2).Infof("ensureForwardingRule: Creating/Recreating forwarding rule - %s", fr.Name)
-	if err = composite.CreateForwardingRule(l.cloud, key, fr); err != nil {
+	if err = composite.CreateForwardingRule(ctx, l.cloud, key, fr); err != nil {
 		return nil, err
 	}
-	return composite.GetForwardingRule(l.cloud, key, fr.Version)
+	return composite.GetForwardingRule(ctx, l.cloud, key, fr.Version)
 }
 
-func (l *L4) getForwardingRule(name string, version meta.Version) *composite.ForwardingRule {
+func (l *L4) getForwardingRule(ctx context.Context, name string, version meta.Version) *composite.ForwardingRule {
 	key, err := l.CreateKey(name)
 	if err != nil {
 		klog.Errorf("Failed to create key for fetching existing forwarding rule %s, err: %v", name, err)
 		return nil
 	}
-	fr, err := composite.GetForwardingRule(l.cloud, key, version)
+	fr, err := composite.GetForwardingRule(ctx, l.cloud, key, version)
 	if utils.IgnoreHTTPNotFound(err) != nil {
 		klog.Errorf("Failed to lookup existing forwarding rule %s, err: %v", name, err)
 		return nil
@@ -308,34 +428,38 @@ func (l *L4) getForwardingRule(name string, version meta.Version) *composite.For
 	return fr
 }
 
-func (l *L4) deleteForwardingRule(name string, version meta.Version) {
+func (l *L4) deleteForwardingRule(ctx context.Context, name string, version meta.Version) {
 	key, err := l.CreateKey(name)
 	if err != nil {
 		klog.Errorf("Failed to create key for deleting forwarding rule %s, err: %v", name, err)
 		return
 	}
-	if err := utils.IgnoreHTTPNotFound(composite.DeleteForwardingRule(l.cloud, key, version)); err != nil {
+	if err := utils.IgnoreHTTPNotFound(composite.DeleteForwardingRule(ctx, l.cloud, key, version)); err != nil {
 		klog.Errorf("Failed to delete forwarding rule %s, err: %v", name, err)
 	}
 }
 
+// l4ForwardingRuleFields are the fields of an L4 forwarding rule compared by
+// Equal.
+var l4ForwardingRuleFields = []utils.FieldSpec{
+	{Path: "IPAddress"},
+	{Path: "IPProtocol"},
+	{Path: "LoadBalancingScheme"},
+	{Path: "Ports", Unordered: true},
+	{Path: "BackendService", ResourceID: true},
+	{Path: "AllowGlobalAccess"},
+	{Path: "AllPorts"},
+	{Path: "Subnetwork"},
+}
+
 func Equal(fr1, fr2 *composite.ForwardingRule) (bool, error) {
-	id1, err := cloud.ParseResourceURL(fr1.BackendService)
-	if err != nil {
+	if _, err := cloud.ParseResourceURL(fr1.BackendService); err != nil {
 		return false, fmt.Errorf("forwardingRulesEqual(): failed to parse backend resource URL from FR, err - %w", err)
 	}
-	id2, err := cloud.ParseResourceURL(fr2.BackendService)
-	if err != nil {
+	if _, err := cloud.ParseResourceURL(fr2.BackendService); err != nil {
 		return false, fmt.Errorf("forwardingRulesEqual(): failed to parse resource URL from FR, err - %w", err)
 	}
-	return fr1.IPAddress == fr2.IPAddress &&
-		fr1.IPProtocol == fr2.IPProtocol &&
-		fr1.LoadBalancingScheme == fr2.LoadBalancingScheme &&
-		utils.EqualStringSets(fr1.Ports, fr2.Ports) &&
-		id1.Equal(id2) &&
-		fr1.AllowGlobalAccess == fr2.AllowGlobalAccess &&
-		fr1.AllPorts == fr2.AllPorts &&
-		fr1.Subnetwork == fr2.Subnetwork, nil
+	return len(utils.DiffFields(fr1, fr2, l4ForwardingRuleFields)) == 0, nil
 }
 
 // ilbIPToUse determines which IP address needs to be used in the ForwardingRule. If an IP has been
@@ -1,6 +1,8 @@
 package loadbalancers
 
 import (
+	"context"
+	"reflect"
 	"testing"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
@@ -49,11 +51,12 @@ func TestGetEffectiveIP(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+			vals := gce.DefaultTestClusterValues()
+			fakeCloud := composite.NewFake(vals.ProjectID, vals.Region)
 			l7 := L7{
-				cloud:       fakeGCE,
-				scope:       tc.scope,
-				runtimeInfo: &L7RuntimeInfo{StaticIPName: ""},
+				compositeCloud: fakeCloud,
+				scope:          tc.scope,
+				runtimeInfo:    &L7RuntimeInfo{StaticIPName: ""},
 			}
 
 			// Create Address if specified
@@ -62,14 +65,14 @@ func TestGetEffectiveIP(t *testing.T) {
 				if err != nil {
 					t.Fatal(err)
 				}
-				err = composite.CreateAddress(fakeGCE, key, tc.address)
+				err = fakeCloud.CreateAddress(context.TODO(), key, tc.address)
 				if err != nil {
 					t.Fatal(err)
 				}
 				l7.runtimeInfo.StaticIPName = tc.address.Name
 			}
 
-			ip, managed, err := l7.getEffectiveIP()
+			ip, managed, err := l7.getEffectiveIP(context.TODO())
 			if (err != nil) != tc.wantErr {
 				t.Errorf("getEffectiveIP() error = %v, wantErr %v", err, tc.wantErr)
 				return
@@ -201,3 +204,45 @@ func TestForwardingRulesEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestImmutableForwardingRuleDiff(t *testing.T) {
+	existing := &composite.ForwardingRule{
+		IPAddress:  "1.2.3.4",
+		PortRange:  "80-80",
+		IPProtocol: "TCP",
+		Subnetwork: "https://www.googleapis.com/compute/v1/projects/p/regions/r/subnetworks/a",
+	}
+	for _, tc := range []struct {
+		desc     string
+		expected *composite.ForwardingRule
+		want     []string
+	}{
+		{
+			desc:     "ephemeral IP",
+			expected: &composite.ForwardingRule{PortRange: "80-80", IPProtocol: "TCP"},
+		},
+		{
+			desc:     "same subnetwork path",
+			expected: &composite.ForwardingRule{PortRange: "80-80", Subnetwork: "projects/p/regions/r/subnetworks/a"},
+		},
+		{
+			desc:     "new IP and port range",
+			expected: &composite.ForwardingRule{IPAddress: "5.6.7.8", PortRange: "443-443"},
+			want:     []string{"IPAddress", "PortRange"},
+		},
+		{
+			desc:     "IPv6",
+			expected: &composite.ForwardingRule{PortRange: "80-80", IpVersion: "IPV6"},
+			want:     []string{"IpVersion"},
+		},
+		{
+			desc:     "subnetwork",
+			expected: &composite.ForwardingRule{PortRange: "80-80", Subnetwork: "projects/p/regions/r/subnetworks/b"},
+			want:     []string{"Subnetwork"},
+		},
+	} {
+		if got := immutableForwardingRuleDiff(existing, tc.expected); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: immutableForwardingRuleDiff() = %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
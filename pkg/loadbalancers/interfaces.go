@@ -17,23 +17,29 @@ limitations under the License.
 package loadbalancers
 
 import (
+	"context"
+
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
 	v1 "k8s.io/api/networking/v1"
+	"k8s.io/ingress-gce/pkg/utils"
 )
 
 // LoadBalancerPool is an interface to manage the cloud resources associated
 // with a gce loadbalancer.
 type LoadBalancerPool interface {
 	// Ensure ensures a loadbalancer and its resources given the RuntimeInfo.
-	Ensure(ri *L7RuntimeInfo) (*L7, error)
+	Ensure(ctx context.Context, ri *L7RuntimeInfo) (*L7, error)
 	// GCv2 garbage collects loadbalancer associated with given ingress using v2 naming scheme.
-	GCv2(ing *v1.Ingress, scope meta.KeyType) error
+	GCv2(ctx context.Context, ing *v1.Ingress, scope meta.KeyType) error
 	// GCv1 garbage collects loadbalancers not in the input list using v1 naming scheme.
-	GCv1(names []string) error
+	GCv1(ctx context.Context, names []string) error
+	// ExplainGCv1 returns the decisions GCv1 would take for the loadbalancers
+	// using the v1 naming scheme, without deleting any.
+	ExplainGCv1(ctx context.Context, names []string) ([]utils.GCDecision, error)
 	// FrontendScopeChangeGC checks if GC is needed for an ingress that has changed scopes
-	FrontendScopeChangeGC(ing *v1.Ingress) (*meta.KeyType, error)
+	FrontendScopeChangeGC(ctx context.Context, ing *v1.Ingress) (*meta.KeyType, error)
 	// Shutdown deletes all loadbalancers for given list of ingresses.
-	Shutdown(ings []*v1.Ingress) error
+	Shutdown(ctx context.Context, ings []*v1.Ingress) error
 	// HasUrlMap returns true if an URL map exists in GCE for given ingress.
-	HasUrlMap(ing *v1.Ingress) (bool, error)
+	HasUrlMap(ctx context.Context, ing *v1.Ingress) (bool, error)
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package loadbalancers
 
 import (
+	"context"
 	"strconv"
 	"strings"
 	"sync"
@@ -24,6 +25,7 @@ import (
 	"fmt"
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/compute/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -60,6 +62,10 @@ type L4 struct {
 	ServicePort         utils.ServicePort
 	NamespacedName      types.NamespacedName
 	sharedResourcesLock *sync.Mutex
+	// HealthCheckFirewall is the firewall rule for health checks shared by
+	// all L4 ILB services of the cluster. If nil, every service with the
+	// Local traffic policy has a firewall rule for its health check.
+	HealthCheckFirewall *firewalls.L4HealthCheckFirewall
 }
 
 // SyncResult contains information about the outcome of an L4 ILB sync. It stores the list of resource name annotations,
@@ -104,7 +110,7 @@ func getILBOptions(svc *corev1.Service) gce.ILBOptions {
 }
 
 // EnsureInternalLoadBalancerDeleted performs a cleanup of all GCE resources for the given loadbalancer service.
-func (l *L4) EnsureInternalLoadBalancerDeleted(svc *corev1.Service) *SyncResult {
+func (l *L4) EnsureInternalLoadBalancerDeleted(ctx context.Context, svc *corev1.Service) *SyncResult {
 	klog.V(2).Infof("EnsureInternalLoadBalancerDeleted(%s): attempting delete of load balancer resources", l.NamespacedName.String())
 	sharedHC := !helpers.RequestsOnlyLocalTraffic(svc)
 	result := &SyncResult{SyncType: SyncTypeDelete, StartTime: time.Now()}
@@ -114,20 +120,29 @@ func (l *L4) EnsureInternalLoadBalancerDeleted(svc *corev1.Service) *SyncResult
 		result.Error = fmt.Errorf("Namer does not support L4 VMIPNEGs")
 		return result
 	}
-	frName := l.GetFRName()
-	key, err := l.CreateKey(frName)
-	if err != nil {
-		klog.Errorf("Failed to create key for LoadBalancer resources with name %s for service %s, err %v", frName, l.NamespacedName.String(), err)
-		result.Error = err
-		return result
+	// A mixed protocol service has a forwarding rule per protocol. All of
+	// them are deleted, since mixed protocol support might have been disabled
+	// after they were created.
+	var frNames []string
+	for _, protocol := range l4ILBProtocols {
+		frNames = append(frNames, l.getFRNameWithProtocol(string(protocol)))
 	}
-	// If any resource deletion fails, log the error and continue cleanup.
-	if err = utils.IgnoreHTTPNotFound(composite.DeleteForwardingRule(l.cloud, key, meta.VersionGA)); err != nil {
-		klog.Errorf("Failed to delete forwarding rule for internal loadbalancer service %s, err %v", l.NamespacedName.String(), err)
-		result.Error = err
-		result.GCEResourceInError = annotations.ForwardingRuleResource
+	for _, frName := range frNames {
+		key, err := l.CreateKey(frName)
+		if err != nil {
+			klog.Errorf("Failed to create key for LoadBalancer resources with name %s for service %s, err %v", frName, l.NamespacedName.String(), err)
+			result.Error = err
+			return result
+		}
+		// If any resource deletion fails, log the error and continue cleanup.
+		if err = utils.IgnoreHTTPNotFound(composite.DeleteForwardingRule(ctx, l.cloud, key, meta.VersionGA)); err != nil {
+			klog.Errorf("Failed to delete forwarding rule %s for internal loadbalancer service %s, err %v", frName, l.NamespacedName.String(), err)
+			result.Error = err
+			result.GCEResourceInError = annotations.ForwardingRuleResource
+		}
 	}
-	if err = ensureAddressDeleted(l.cloud, name, l.cloud.Region()); err != nil {
+	err := ensureAddressDeleted(l.cloud, name, l.cloud.Region())
+	if err != nil {
 		klog.Errorf("Failed to delete address for internal loadbalancer service %s, err %v", l.NamespacedName.String(), err)
 		result.Error = err
 		result.GCEResourceInError = annotations.AddressResource
@@ -135,15 +150,7 @@ func (l *L4) EnsureInternalLoadBalancerDeleted(svc *corev1.Service) *SyncResult
 	hcName, hcFwName := l.namer.L4HealthCheck(svc.Namespace, svc.Name, sharedHC)
 	// delete fw rules
 	deleteFunc := func(name string) error {
-		err := firewalls.EnsureL4InternalFirewallRuleDeleted(l.cloud, name)
-		if err != nil {
-			if fwErr, ok := err.(*firewalls.FirewallXPNError); ok {
-				l.recorder.Eventf(l.Service, corev1.EventTypeNormal, "XPN", fwErr.Message)
-				return nil
-			}
-			return err
-		}
-		return nil
+		return l.ignoreFirewallXPNError(firewalls.EnsureL4InternalFirewallRuleDeleted(l.cloud, name))
 	}
 	// delete firewall rule allowing load balancer source ranges
 	err = deleteFunc(name)
@@ -154,14 +161,23 @@ func (l *L4) EnsureInternalLoadBalancerDeleted(svc *corev1.Service) *SyncResult
 	}
 
 	// delete firewall rule allowing healthcheck source ranges
-	err = deleteFunc(hcFwName)
+	if l.HealthCheckFirewall != nil {
+		// The shared rule is only deleted once no other service references it. A service with the Local
+		// traffic policy may still have its own rule from before the shared rule.
+		err = l.ignoreFirewallXPNError(l.HealthCheckFirewall.Release(l.NamespacedName.String()))
+		if err == nil && !sharedHC {
+			err = deleteFunc(hcFwName)
+		}
+	} else {
+		err = deleteFunc(hcFwName)
+	}
 	if err != nil {
 		klog.Errorf("Failed to delete firewall rule %s for internal loadbalancer service %s, err %v", hcFwName, l.NamespacedName.String(), err)
 		result.GCEResourceInError = annotations.FirewallForHealthcheckResource
 		result.Error = err
 	}
 	// delete backend service
-	err = utils.IgnoreHTTPNotFound(l.backendPool.Delete(name, meta.VersionGA, meta.Regional))
+	err = utils.IgnoreHTTPNotFound(l.backendPool.Delete(ctx, name, meta.VersionGA, meta.Regional))
 	if err != nil {
 		klog.Errorf("Failed to delete backends for internal loadbalancer service %s, err  %v", l.NamespacedName.String(), err)
 		result.GCEResourceInError = annotations.BackendServiceResource
@@ -173,7 +189,7 @@ func (l *L4) EnsureInternalLoadBalancerDeleted(svc *corev1.Service) *SyncResult
 		l.sharedResourcesLock.Lock()
 		defer l.sharedResourcesLock.Unlock()
 	}
-	err = utils.IgnoreHTTPNotFound(healthchecks.DeleteHealthCheck(l.cloud, hcName))
+	err = utils.IgnoreHTTPNotFound(healthchecks.DeleteHealthCheck(ctx, l.cloud, hcName))
 	if err != nil {
 		if !utils.IsInUsedByError(err) {
 			klog.Errorf("Failed to delete healthcheck for internal loadbalancer service %s, err %v", l.NamespacedName.String(), err)
@@ -188,6 +204,26 @@ func (l *L4) EnsureInternalLoadBalancerDeleted(svc *corev1.Service) *SyncResult
 	return result
 }
 
+// ignoreFirewallXPNError raises an event for a FirewallXPNError, which
+// requires the user to act on the firewall rule, and ignores it.
+func (l *L4) ignoreFirewallXPNError(err error) error {
+	if fwErr, ok := err.(*firewalls.FirewallXPNError); ok {
+		l.recorder.Eventf(l.Service, corev1.EventTypeNormal, "XPN", fwErr.Message)
+		return nil
+	}
+	return err
+}
+
+// HealthCheckPort returns the node port checked by the health check of the
+// given ILB service.
+func HealthCheckPort(svc *corev1.Service) int32 {
+	if helpers.RequestsOnlyLocalTraffic(svc) {
+		_, port := helpers.GetServiceHealthCheckPathPort(svc)
+		return port
+	}
+	return gce.GetNodesHealthCheckPort()
+}
+
 // GetFRName returns the name of the forwarding rule for the given ILB service.
 // This appends the protocol to the forwarding rule name, which will help supporting multiple protocols in the same ILB
 // service.
@@ -200,9 +236,36 @@ func (l *L4) getFRNameWithProtocol(protocol string) string {
 	return l.namer.L4ForwardingRule(l.Service.Namespace, l.Service.Name, strings.ToLower(protocol))
 }
 
+// getForwardingRules returns the existing forwarding rules of the service by protocol. These are the forwarding
+// rules for the given ports, and for the protocol of the existing backend service.
+func (l *L4) getForwardingRules(ctx context.Context, pps []protocolPorts, existingBS *composite.BackendService) map[corev1.Protocol]*composite.ForwardingRule {
+	var protocols []corev1.Protocol
+	for _, pp := range pps {
+		protocols = append(protocols, pp.protocol)
+	}
+	if existingBS != nil {
+		if existingBS.Protocol == mixedProtocolBackendServiceProtocol {
+			protocols = append(protocols, l4ILBProtocols...)
+		} else {
+			protocols = append(protocols, corev1.Protocol(existingBS.Protocol))
+		}
+	}
+	frs := map[corev1.Protocol]*composite.ForwardingRule{}
+	for _, protocol := range protocols {
+		if _, ok := frs[protocol]; ok {
+			continue
+		}
+		frs[protocol] = l.getForwardingRule(ctx, l.getFRNameWithProtocol(string(protocol)), meta.VersionGA)
+		if frs[protocol] == nil {
+			delete(frs, protocol)
+		}
+	}
+	return frs
+}
+
 // EnsureInternalLoadBalancer ensures that all GCE resources for the given loadbalancer service have
 // been created. It returns a LoadBalancerStatus with the updated ForwardingRule IP address.
-func (l *L4) EnsureInternalLoadBalancer(nodeNames []string, svc *corev1.Service) *SyncResult {
+func (l *L4) EnsureInternalLoadBalancer(ctx context.Context, nodeNames []string, svc *corev1.Service) *SyncResult {
 	result := &SyncResult{
 		Annotations: make(map[string]string),
 		StartTime:   time.Now(),
@@ -234,7 +297,7 @@ func (l *L4) EnsureInternalLoadBalancer(nodeNames []string, svc *corev1.Service)
 		// Take the lock when creating the shared healthcheck
 		l.sharedResourcesLock.Lock()
 	}
-	_, hcLink, err := healthchecks.EnsureL4HealthCheck(l.cloud, hcName, l.NamespacedName, sharedHC, hcPath, hcPort)
+	_, hcLink, err := healthchecks.EnsureL4HealthCheck(ctx, l.cloud, hcName, l.NamespacedName, sharedHC, hcPath, hcPort)
 	if sharedHC {
 		// unlock here so rest of the resource creation API can be called without unnecessarily holding the lock.
 		l.sharedResourcesLock.Unlock()
@@ -246,7 +309,9 @@ func (l *L4) EnsureInternalLoadBalancer(nodeNames []string, svc *corev1.Service)
 	}
 	result.Annotations[annotations.HealthcheckKey] = hcName
 
-	_, portRanges, protocol := utils.GetPortsAndProtocol(l.Service.Spec.Ports)
+	pps := l.servicePortsByProtocol()
+	mixedProtocol := len(pps) > 1
+	bsProtocol := backendServiceProtocol(pps)
 
 	// ensure firewalls
 	sourceRanges, err := helpers.GetLoadBalancerSourceRanges(l.Service)
@@ -255,24 +320,16 @@ func (l *L4) EnsureInternalLoadBalancer(nodeNames []string, svc *corev1.Service)
 		return result
 	}
 	hcSourceRanges := gce.L4LoadBalancerSrcRanges()
-	ensureFunc := func(name, IP string, sourceRanges, portRanges []string, proto string, shared bool) error {
+	ensureFunc := func(name, IP string, sourceRanges []string, allowed []*compute.FirewallAllowed, shared bool) error {
 		if shared {
 			l.sharedResourcesLock.Lock()
 			defer l.sharedResourcesLock.Unlock()
 		}
 		nsName := utils.ServiceKeyFunc(l.Service.Namespace, l.Service.Name)
-		err := firewalls.EnsureL4InternalFirewallRule(l.cloud, name, IP, nsName, sourceRanges, portRanges, nodeNames, proto, shared)
-		if err != nil {
-			if fwErr, ok := err.(*firewalls.FirewallXPNError); ok {
-				l.recorder.Eventf(l.Service, corev1.EventTypeNormal, "XPN", fwErr.Message)
-				return nil
-			}
-			return err
-		}
-		return nil
+		return l.ignoreFirewallXPNError(firewalls.EnsureL4InternalFirewallRuleAllowed(l.cloud, name, IP, nsName, sourceRanges, allowed, nodeNames, shared))
 	}
 	// Add firewall rule for ILB traffic to nodes
-	err = ensureFunc(name, "", sourceRanges.StringSlice(), portRanges, string(protocol), false)
+	err = ensureFunc(name, "", sourceRanges.StringSlice(), firewallAllowed(pps), false)
 	if err != nil {
 		result.GCEResourceInError = annotations.FirewallRuleResource
 		result.Error = err
@@ -281,7 +338,19 @@ func (l *L4) EnsureInternalLoadBalancer(nodeNames []string, svc *corev1.Service)
 	result.Annotations[annotations.FirewallRuleKey] = name
 
 	// Add firewall rule for healthchecks to nodes
-	err = ensureFunc(hcFwName, "", hcSourceRanges, []string{strconv.Itoa(int(hcPort))}, string(corev1.ProtocolTCP), sharedHC)
+	if l.HealthCheckFirewall != nil {
+		_, svcHcFwName := l.namer.L4HealthCheck(svc.Namespace, svc.Name, false)
+		hcFwName = l.HealthCheckFirewall.Name()
+		err = l.ignoreFirewallXPNError(l.HealthCheckFirewall.Ensure(l.NamespacedName.String(), hcPort, nodeNames))
+		// Migrate from the rule of the service once the shared rule allows its health check port.
+		if err == nil && svc.Annotations[annotations.FirewallRuleForHealthcheckKey] == svcHcFwName {
+			klog.V(2).Infof("Deleting firewall rule %s for service %s, replaced by shared rule %s", svcHcFwName, l.NamespacedName.String(), hcFwName)
+			err = l.ignoreFirewallXPNError(firewalls.EnsureL4InternalFirewallRuleDeleted(l.cloud, svcHcFwName))
+		}
+	} else {
+		hcAllowed := []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{strconv.Itoa(int(hcPort))}}}
+		err = ensureFunc(hcFwName, "", hcSourceRanges, hcAllowed, sharedHC)
+	}
 	if err != nil {
 		result.GCEResourceInError = annotations.FirewallForHealthcheckResource
 		result.Error = err
@@ -289,23 +358,32 @@ func (l *L4) EnsureInternalLoadBalancer(nodeNames []string, svc *corev1.Service)
 	}
 	result.Annotations[annotations.FirewallRuleForHealthcheckKey] = hcFwName
 
-	// Check if protocol has changed for this service. In this case, forwarding rule should be deleted before
+	// Check if protocol has changed for this service. In this case, forwarding rules should be deleted before
 	// the backend service can be updated.
-	existingBS, err := l.backendPool.Get(name, meta.VersionGA, l.scope)
+	existingBS, err := l.backendPool.Get(ctx, name, meta.VersionGA, l.scope)
 	err = utils.IgnoreHTTPNotFound(err)
 	if err != nil {
 		klog.Errorf("Failed to lookup existing backend service, ignoring err: %v", err)
 	}
-	existingFR := l.getForwardingRule(l.GetFRName(), meta.VersionGA)
-	if existingBS != nil && existingBS.Protocol != string(protocol) {
-		klog.Infof("Protocol changed from %q to %q for service %s", existingBS.Protocol, string(protocol), l.NamespacedName)
-		// Delete forwarding rule if it exists
-		existingFR = l.getForwardingRule(l.getFRNameWithProtocol(existingBS.Protocol), meta.VersionGA)
-		l.deleteForwardingRule(l.getFRNameWithProtocol(existingBS.Protocol), meta.VersionGA)
+	existingFRs := l.getForwardingRules(ctx, pps, existingBS)
+	// ipFR is the forwarding rule whose IP is used by the forwarding rules which do not exist yet.
+	var ipFR *composite.ForwardingRule
+	for _, p := range l4ILBProtocols {
+		if ipFR = existingFRs[p]; ipFR != nil {
+			break
+		}
+	}
+	if existingBS != nil && existingBS.Protocol != bsProtocol {
+		klog.Infof("Protocol changed from %q to %q for service %s", existingBS.Protocol, bsProtocol, l.NamespacedName)
+		// Delete forwarding rules if they exist
+		for _, fr := range existingFRs {
+			l.deleteForwardingRule(ctx, fr.Name, meta.VersionGA)
+		}
+		existingFRs = nil
 	}
 
 	// ensure backend service
-	bs, err := l.backendPool.EnsureL4BackendService(name, hcLink, string(protocol), string(l.Service.Spec.SessionAffinity),
+	bs, err := l.backendPool.EnsureL4BackendService(ctx, name, hcLink, bsProtocol, string(l.Service.Spec.SessionAffinity),
 		string(cloud.SchemeInternal), l.NamespacedName, meta.VersionGA)
 	if err != nil {
 		result.GCEResourceInError = annotations.BackendServiceResource
@@ -313,19 +391,47 @@ func (l *L4) EnsureInternalLoadBalancer(nodeNames []string, svc *corev1.Service)
 		return result
 	}
 	result.Annotations[annotations.BackendServiceKey] = name
-	// create fr rule
-	frName := l.GetFRName()
-	fr, err := l.ensureForwardingRule(frName, bs.SelfLink, options, existingFR)
-	if err != nil {
-		klog.Errorf("EnsureInternalLoadBalancer: Failed to create forwarding rule - %v", err)
-		result.GCEResourceInError = annotations.ForwardingRuleResource
-		result.Error = err
-		return result
+	// create fr rules, one per protocol. The forwarding rules of a mixed protocol service share the IP, which is
+	// held by an address with the name of the backend service.
+	sharedAddressName := ""
+	if mixedProtocol {
+		sharedAddressName = name
 	}
-	if fr.IPProtocol == string(corev1.ProtocolTCP) {
-		result.Annotations[annotations.TCPForwardingRuleKey] = frName
-	} else {
-		result.Annotations[annotations.UDPForwardingRuleKey] = frName
+	var fr *composite.ForwardingRule
+	for _, pp := range pps {
+		frName := l.getFRNameWithProtocol(string(pp.protocol))
+		existingFR := existingFRs[pp.protocol]
+		ipFwdRule := existingFR
+		if ipFwdRule == nil {
+			ipFwdRule = ipFR
+		}
+		protocolFR, err := l.ensureForwardingRule(ctx, frName, bs.SelfLink, options, existingFR, ipFwdRule, pp.protocol, pp.ports, sharedAddressName)
+		if err != nil {
+			klog.Errorf("EnsureInternalLoadBalancer: Failed to create forwarding rule - %v", err)
+			result.GCEResourceInError = annotations.ForwardingRuleResource
+			result.Error = err
+			return result
+		}
+		if fr != nil && protocolFR.IPAddress != fr.IPAddress {
+			result.GCEResourceInError = annotations.ForwardingRuleResource
+			result.Error = fmt.Errorf("forwarding rules %s and %s of service %s have different IPs %s and %s",
+				fr.Name, protocolFR.Name, l.NamespacedName, fr.IPAddress, protocolFR.IPAddress)
+			return result
+		}
+		if protocolFR.IPProtocol == string(corev1.ProtocolTCP) {
+			result.Annotations[annotations.TCPForwardingRuleKey] = frName
+		} else {
+			result.Annotations[annotations.UDPForwardingRuleKey] = frName
+		}
+		if fr == nil {
+			fr = protocolFR
+		}
+	}
+	if !mixedProtocol && existingBS != nil && existingBS.Protocol == mixedProtocolBackendServiceProtocol {
+		// The IP is no longer shared by several forwarding rules.
+		if err := ensureAddressDeleted(l.cloud, name, l.cloud.Region()); err != nil {
+			klog.Errorf("Failed to delete shared address %s of service %s, err: %v", name, l.NamespacedName, err)
+		}
 	}
 
 	result.MetricsState.InSuccess = true
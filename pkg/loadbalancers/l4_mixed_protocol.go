@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancers
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/compute/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/ingress-gce/pkg/flags"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+const (
+	// mixedProtocolBackendServiceProtocol is the protocol of the backend
+	// service shared by the TCP and UDP forwarding rules of a mixed protocol
+	// service.
+	mixedProtocolBackendServiceProtocol = "UNSPECIFIED"
+	// sharedVIPPurpose is the purpose of an internal address which is used by
+	// several forwarding rules.
+	sharedVIPPurpose = "SHARED_LOADBALANCER_VIP"
+)
+
+// l4ILBProtocols are the protocols for which an ILB service may have a
+// forwarding rule.
+var l4ILBProtocols = []corev1.Protocol{corev1.ProtocolTCP, corev1.ProtocolUDP}
+
+// protocolPorts are the ports of a service which use the same protocol, and
+// are served by the same forwarding rule.
+type protocolPorts struct {
+	protocol   corev1.Protocol
+	ports      []string
+	portRanges []string
+}
+
+// servicePortsByProtocol returns the ports of the service grouped by
+// protocol, sorted by protocol. Unless mixed protocol services are enabled,
+// all ports are served with the protocol of the first port.
+func (l *L4) servicePortsByProtocol() []protocolPorts {
+	if !flags.F.EnableL4ILBMixedProtocol {
+		ports, portRanges, protocol := utils.GetPortsAndProtocol(l.Service.Spec.Ports)
+		return []protocolPorts{{protocol: protocol, ports: ports, portRanges: portRanges}}
+	}
+	if len(l.Service.Spec.Ports) == 0 {
+		return []protocolPorts{{protocol: corev1.ProtocolTCP, ports: []string{}, portRanges: []string{}}}
+	}
+	portInts := map[corev1.Protocol][]int{}
+	for _, p := range l.Service.Spec.Ports {
+		portInts[p.Protocol] = append(portInts[p.Protocol], int(p.Port))
+	}
+	var ret []protocolPorts
+	for protocol, ints := range portInts {
+		pp := protocolPorts{protocol: protocol, portRanges: utils.GetPortRanges(ints)}
+		for _, port := range ints {
+			pp.ports = append(pp.ports, strconv.Itoa(port))
+		}
+		ret = append(ret, pp)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].protocol < ret[j].protocol })
+	return ret
+}
+
+// backendServiceProtocol returns the protocol of the backend service which
+// serves the given ports.
+func backendServiceProtocol(pps []protocolPorts) string {
+	if len(pps) > 1 {
+		return mixedProtocolBackendServiceProtocol
+	}
+	return string(pps[0].protocol)
+}
+
+// firewallAllowed returns the protocols and ports allowed by the firewall rule
+// of the service.
+func firewallAllowed(pps []protocolPorts) []*compute.FirewallAllowed {
+	var allowed []*compute.FirewallAllowed
+	for _, pp := range pps {
+		allowed = append(allowed, &compute.FirewallAllowed{
+			IPProtocol: strings.ToLower(string(pp.protocol)),
+			Ports:      pp.portRanges,
+		})
+	}
+	return allowed
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cloud-provider/service/helpers"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/firewalls"
+	"k8s.io/ingress-gce/pkg/flags"
+	"k8s.io/ingress-gce/pkg/healthchecks"
+	"k8s.io/ingress-gce/pkg/utils"
+	"k8s.io/klog"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+const (
+	// NetLBHealthCheckPolicyShared is the policy under which all external
+	// LoadBalancer services with the Cluster traffic policy share a single
+	// regional healthcheck.
+	NetLBHealthCheckPolicyShared = "shared"
+	// NetLBHealthCheckPolicyPerService is the policy under which every
+	// external LoadBalancer service has its own regional healthcheck.
+	NetLBHealthCheckPolicyPerService = "per-service"
+)
+
+// ValidateNetLBHealthCheckPolicy returns an error if policy is not a known
+// healthcheck policy of external LoadBalancers.
+func ValidateNetLBHealthCheckPolicy(policy string) error {
+	switch policy {
+	case NetLBHealthCheckPolicyShared, NetLBHealthCheckPolicyPerService:
+		return nil
+	}
+	return fmt.Errorf("unknown healthcheck policy %q, must be %q or %q", policy, NetLBHealthCheckPolicyShared, NetLBHealthCheckPolicyPerService)
+}
+
+// netLBSharedHealthCheck returns true if the external LoadBalancer of the
+// service uses the healthcheck shared by the cluster. Services with the
+// Local traffic policy always have their own healthcheck, since they are
+// checked on their own healthcheck node port.
+func (l *L4) netLBSharedHealthCheck() bool {
+	return !helpers.RequestsOnlyLocalTraffic(l.Service) && flags.F.L4NetLBHealthCheckPolicy != NetLBHealthCheckPolicyPerService
+}
+
+// netLBHealthCheckPathPort returns the path and port checked by the
+// healthcheck of the external LoadBalancer of the service. Services with the
+// Local traffic policy are checked on the healthcheck node port served by
+// kube-proxy, which fails on nodes without local endpoints, so that only those
+// nodes receive traffic.
+func (l *L4) netLBHealthCheckPathPort() (string, int32) {
+	if helpers.RequestsOnlyLocalTraffic(l.Service) {
+		return helpers.GetServiceHealthCheckPathPort(l.Service)
+	}
+	return gce.GetNodesHealthCheckPath(), gce.GetNodesHealthCheckPort()
+}
+
+// ensureNetLBHealthCheckFirewall ensures the firewall rule which allows the
+// healthcheck probes of the external LoadBalancer of the service to reach
+// the healthcheck port on the given nodes. It returns the name of the rule.
+func (l *L4) ensureNetLBHealthCheckFirewall(nodeNames []string) (string, error) {
+	shared := l.netLBSharedHealthCheck()
+	_, hcFwName := l.namer.L4HealthCheck(l.Service.Namespace, l.Service.Name, shared)
+	_, hcPort := l.netLBHealthCheckPathPort()
+	if l.HealthCheckFirewall != nil {
+		hcFwName = l.HealthCheckFirewall.Name()
+		err := l.ignoreFirewallXPNError(l.HealthCheckFirewall.Ensure(l.NamespacedName.String(), hcPort, nodeNames))
+		return hcFwName, err
+	}
+	if shared {
+		l.sharedResourcesLock.Lock()
+		defer l.sharedResourcesLock.Unlock()
+	}
+	nsName := utils.ServiceKeyFunc(l.Service.Namespace, l.Service.Name)
+	err := l.ignoreFirewallXPNError(firewalls.EnsureL4InternalFirewallRule(l.cloud, hcFwName, "", nsName, gce.L4LoadBalancerSrcRanges(), []string{strconv.Itoa(int(hcPort))}, nodeNames, string(corev1.ProtocolTCP), shared))
+	if err != nil {
+		return "", fmt.Errorf("failed to ensure healthcheck firewall rule %s: %w", hcFwName, err)
+	}
+	return hcFwName, nil
+}
+
+// ensureNetLBHealthCheck ensures the regional healthcheck of the external
+// LoadBalancer of the service, as chosen by the healthcheck policy. It returns
+// the link of the healthcheck and whether it is shared.
+func (l *L4) ensureNetLBHealthCheck(ctx context.Context) (string, bool, error) {
+	shared := l.netLBSharedHealthCheck()
+	hcName, _ := l.namer.L4HealthCheck(l.Service.Namespace, l.Service.Name, shared)
+	hcPath, hcPort := l.netLBHealthCheckPathPort()
+	if shared {
+		l.sharedResourcesLock.Lock()
+		defer l.sharedResourcesLock.Unlock()
+	}
+	_, hcLink, err := healthchecks.EnsureL4HealthCheckWithScope(ctx, l.cloud, hcName, l.NamespacedName, shared, hcPath, hcPort, meta.Regional)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to ensure healthcheck %s: %w", hcName, err)
+	}
+	return hcLink, shared, nil
+}
+
+// convertNetLBHealthCheck moves the backend service of the external
+// LoadBalancer of the service to the healthcheck chosen by the healthcheck
+// policy, e.g. after the policy was changed. The healthchecks no longer used
+// by the backend service are released.
+func (l *L4) convertNetLBHealthCheck(ctx context.Context) error {
+	bsName, ok := l.namer.VMIPNEG(l.Service.Namespace, l.Service.Name)
+	if !ok {
+		return fmt.Errorf("Namer does not support L4 VMIPNEGs")
+	}
+	bs, err := l.backendPool.Get(ctx, bsName, meta.VersionGA, meta.Regional)
+	if err != nil {
+		return utils.IgnoreHTTPNotFound(err)
+	}
+	hcLink, shared, err := l.ensureNetLBHealthCheck(ctx)
+	if err != nil {
+		return err
+	}
+	hcName, err := utils.KeyName(hcLink)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for _, link := range bs.HealthChecks {
+		name, err := utils.KeyName(link)
+		if err != nil {
+			return err
+		}
+		if name != hcName {
+			stale = append(stale, name)
+		}
+	}
+	if len(stale) == 0 && len(bs.HealthChecks) == 1 {
+		return nil
+	}
+	klog.V(2).Infof("Moving backend service %s of service %s to healthcheck %s, shared = %v", bsName, l.NamespacedName, hcName, shared)
+	bs.HealthChecks = []string{hcLink}
+	if err := composite.UpdateBackendService(ctx, l.cloud, meta.RegionalKey(bsName, l.cloud.Region()), bs); err != nil {
+		return fmt.Errorf("failed to update healthcheck of backend service %s: %w", bsName, err)
+	}
+	for _, name := range stale {
+		if err := l.releaseNetLBHealthCheck(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseNetLBHealthCheck deletes the regional healthcheck with the given
+// name once no backend service references it. Healthchecks of a single
+// service are deleted right away, along with their firewall rule. The firewall
+// rule of the shared healthcheck is kept, since internal LoadBalancers use it
+// too.
+func (l *L4) releaseNetLBHealthCheck(ctx context.Context, name string) error {
+	sharedName, _ := l.namer.L4HealthCheck(l.Service.Namespace, l.Service.Name, true)
+	if svcName, svcFwName := l.namer.L4HealthCheck(l.Service.Namespace, l.Service.Name, false); name == svcName && l.HealthCheckFirewall == nil {
+		klog.V(2).Infof("Deleting firewall rule %s of healthcheck %s", svcFwName, name)
+		if err := l.ignoreFirewallXPNError(firewalls.EnsureL4InternalFirewallRuleDeleted(l.cloud, svcFwName)); err != nil {
+			return fmt.Errorf("failed to delete healthcheck firewall rule %s: %w", svcFwName, err)
+		}
+	}
+	if name == sharedName {
+		l.sharedResourcesLock.Lock()
+		defer l.sharedResourcesLock.Unlock()
+		refs, err := l.netLBHealthCheckRefs(ctx, name)
+		if err != nil {
+			return err
+		}
+		if refs > 0 {
+			klog.V(3).Infof("Keeping healthcheck %s, referenced by %d backend services", name, refs)
+			return nil
+		}
+	}
+	klog.V(2).Infof("Deleting healthcheck %s, no longer referenced", name)
+	err := composite.DeleteHealthCheck(ctx, l.cloud, meta.RegionalKey(name, l.cloud.Region()), meta.VersionGA)
+	if err := utils.IgnoreHTTPNotFound(err); err != nil {
+		return fmt.Errorf("failed to delete healthcheck %s: %w", name, err)
+	}
+	return nil
+}
+
+// netLBHealthCheckRefs returns the number of regional backend services which
+// reference the regional healthcheck with the given name.
+func (l *L4) netLBHealthCheckRefs(ctx context.Context, name string) (int, error) {
+	bss, err := composite.ListBackendServices(ctx, l.cloud, meta.RegionalKey("", l.cloud.Region()), meta.VersionGA)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list backend services: %w", err)
+	}
+	// Backend services of internal LoadBalancers reference the global
+	// healthcheck with the same name.
+	suffix := fmt.Sprintf("/regions/%s/healthChecks/%s", l.cloud.Region(), name)
+	refs := 0
+	for _, bs := range bss {
+		for _, link := range bs.HealthChecks {
+			if strings.HasSuffix(link, suffix) {
+				refs++
+				break
+			}
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/flags"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+func TestConvertNetLBHealthCheck(t *testing.T) {
+	oldPolicy := flags.F.L4NetLBHealthCheckPolicy
+	defer func() { flags.F.L4NetLBHealthCheckPolicy = oldPolicy }()
+
+	l, _ := newMigrationHandler(t)
+	l.Service.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+	region := l.cloud.Region()
+	igLink := "https://www.googleapis.com/compute/v1/projects/test-project/zones/us-central1-b/instanceGroups/k8s-ig"
+	bsName, _ := l.namer.VMIPNEG(l.Service.Namespace, l.Service.Name)
+	sharedName, _ := l.namer.L4HealthCheck(l.Service.Namespace, l.Service.Name, true)
+
+	// checkHealthCheck verifies that the backend service uses the healthcheck
+	// want and whether the healthcheck gone exists.
+	checkHealthCheck := func(want, gone string, wantGone bool) {
+		t.Helper()
+		bs, err := l.backendPool.Get(context.TODO(), bsName, meta.VersionGA, meta.Regional)
+		if err != nil {
+			t.Fatalf("Get(%s) = %v", bsName, err)
+		}
+		if len(bs.HealthChecks) != 1 {
+			t.Fatalf("Backend service healthchecks = %v, want %s", bs.HealthChecks, want)
+		}
+		if name, _ := utils.KeyName(bs.HealthChecks[0]); name != want {
+			t.Errorf("Backend service healthchecks = %v, want %s", bs.HealthChecks, want)
+		}
+		_, err = composite.GetHealthCheck(context.TODO(), l.cloud, meta.RegionalKey(gone, region), meta.VersionGA)
+		if gotGone := utils.IsNotFoundError(err); gotGone != wantGone {
+			t.Errorf("GetHealthCheck(%s) = %v, want deleted %v", gone, err, wantGone)
+		}
+	}
+
+	flags.F.L4NetLBHealthCheckPolicy = NetLBHealthCheckPolicyShared
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), []string{igLink}, []string{"test-node-1"}); err != nil {
+		t.Fatalf("MigrateTargetPoolToRBS() = %v", err)
+	}
+	checkHealthCheck(sharedName, bsName, true)
+
+	// The shared healthcheck is deleted once no longer referenced.
+	flags.F.L4NetLBHealthCheckPolicy = NetLBHealthCheckPolicyPerService
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), []string{igLink}, []string{"test-node-1"}); err != nil {
+		t.Fatalf("MigrateTargetPoolToRBS() after policy change = %v", err)
+	}
+	checkHealthCheck(bsName, sharedName, true)
+
+	// The shared healthcheck is kept while another backend service
+	// references it.
+	flags.F.L4NetLBHealthCheckPolicy = NetLBHealthCheckPolicyShared
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), []string{igLink}, []string{"test-node-1"}); err != nil {
+		t.Fatalf("MigrateTargetPoolToRBS() after policy change = %v", err)
+	}
+	checkHealthCheck(sharedName, bsName, true)
+	sharedHC, err := composite.GetHealthCheck(context.TODO(), l.cloud, meta.RegionalKey(sharedName, region), meta.VersionGA)
+	if err != nil {
+		t.Fatalf("GetHealthCheck(%s) = %v", sharedName, err)
+	}
+	otherBS := &composite.BackendService{Name: "other", Version: meta.VersionGA, HealthChecks: []string{sharedHC.SelfLink}}
+	if err := composite.CreateBackendService(context.TODO(), l.cloud, meta.RegionalKey(otherBS.Name, region), otherBS); err != nil {
+		t.Fatalf("CreateBackendService(%s) = %v", otherBS.Name, err)
+	}
+	flags.F.L4NetLBHealthCheckPolicy = NetLBHealthCheckPolicyPerService
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), []string{igLink}, []string{"test-node-1"}); err != nil {
+		t.Fatalf("MigrateTargetPoolToRBS() after policy change = %v", err)
+	}
+	checkHealthCheck(bsName, sharedName, false)
+}
+
+func TestNetLBLocalTrafficHealthCheck(t *testing.T) {
+	l, _ := newMigrationHandler(t)
+	l.Service.Spec.HealthCheckNodePort = 31234
+	region := l.cloud.Region()
+	igLink := "https://www.googleapis.com/compute/v1/projects/test-project/zones/us-central1-b/instanceGroups/k8s-ig"
+	nodeNames := []string{"test-node-1"}
+	hcName, hcFwName := l.namer.L4HealthCheck(l.Service.Namespace, l.Service.Name, false)
+
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), []string{igLink}, nodeNames); err != nil {
+		t.Fatalf("MigrateTargetPoolToRBS() = %v", err)
+	}
+	// Services with the Local traffic policy are checked on the healthcheck
+	// node port of kube-proxy.
+	hc, err := composite.GetHealthCheck(context.TODO(), l.cloud, meta.RegionalKey(hcName, region), meta.VersionGA)
+	if err != nil {
+		t.Fatalf("GetHealthCheck(%s) = %v", hcName, err)
+	}
+	if hc.HttpHealthCheck == nil || hc.HttpHealthCheck.Port != 31234 || hc.HttpHealthCheck.RequestPath != "/healthz" {
+		t.Errorf("Healthcheck = %+v, want HTTP check of /healthz on port 31234", hc.HttpHealthCheck)
+	}
+	fw, err := l.cloud.GetFirewall(hcFwName)
+	if err != nil {
+		t.Fatalf("GetFirewall(%s) = %v", hcFwName, err)
+	}
+	if len(fw.Allowed) != 1 || len(fw.Allowed[0].Ports) != 1 || fw.Allowed[0].Ports[0] != "31234" {
+		t.Errorf("Firewall allowed = %+v, want port 31234", fw.Allowed)
+	}
+
+	// The healthcheck and firewall rule of the service are deleted once it
+	// uses the shared healthcheck.
+	l.Service.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+	l.Service.Spec.HealthCheckNodePort = 0
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), []string{igLink}, nodeNames); err != nil {
+		t.Fatalf("MigrateTargetPoolToRBS() after traffic policy change = %v", err)
+	}
+	if _, err := l.cloud.GetFirewall(hcFwName); !utils.IsNotFoundError(err) {
+		t.Errorf("GetFirewall(%s) = %v, want deleted", hcFwName, err)
+	}
+	_, sharedFwName := l.namer.L4HealthCheck(l.Service.Namespace, l.Service.Name, true)
+	if _, err := l.cloud.GetFirewall(sharedFwName); err != nil {
+		t.Errorf("GetFirewall(%s) = %v, want shared healthcheck firewall rule", sharedFwName, err)
+	}
+}
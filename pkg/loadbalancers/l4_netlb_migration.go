@@ -0,0 +1,445 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/cloud-provider/service/helpers"
+	"k8s.io/ingress-gce/pkg/backends"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/firewalls"
+	"k8s.io/ingress-gce/pkg/utils"
+	"k8s.io/klog"
+)
+
+const (
+	// TargetPoolMigrated is the event reason used when an external
+	// LoadBalancer service has been migrated to a regional backend service.
+	TargetPoolMigrated = "TargetPoolMigrated"
+	// TargetPoolMigrationFailed is the event reason used when the migration
+	// of an external LoadBalancer service failed.
+	TargetPoolMigrationFailed = "TargetPoolMigrationFailed"
+)
+
+// MigrateTargetPoolToRBS migrates the external LoadBalancer of the service
+// from the target pool created by the legacy service controller to a regional
+// backend service with the given instance groups as backends. The healthcheck
+// probes are allowed to reach the given nodes.
+//
+// The new data path is created first. The legacy forwarding rule is then
+// replaced by one with the same IP address that points at the backend
+// service. If that fails, the legacy forwarding rule and the data path
+// created by this call are restored. If the legacy forwarding rule cannot be
+// restored either, its IP address stays reserved and is used by the
+// forwarding rule created by the next call. Once traffic has been switched,
+// the target pool and its per-service legacy healthcheck are deleted.
+//
+// A service without a legacy forwarding rule and without a forwarding rule
+// pointing at a backend service, e.g. one which opted in on creation, has
+// nothing to migrate. Its healthcheck, firewall rules, backend service and
+// forwarding rule are created directly.
+//
+// Migration is idempotent, an error while cleaning up the legacy resources is
+// retried by calling it again. Calling it again after the migration moves the
+// backend service to the healthcheck chosen by the healthcheck policy, sets
+// its backends to the given instance groups and recreates the forwarding rule
+// if the requested port range changed.
+func (l *L4) MigrateTargetPoolToRBS(ctx context.Context, instanceGroupLinks, nodeNames []string) error {
+	legacyName := cloudprovider.DefaultLoadBalancerName(l.Service)
+	region := l.cloud.Region()
+
+	legacyFR, err := l.cloud.GetRegionForwardingRule(legacyName, region)
+	if utils.IgnoreHTTPNotFound(err) != nil {
+		return err
+	}
+	if err := l.ensureNetLBFirewall(nodeNames); err != nil {
+		return err
+	}
+	if legacyFR != nil {
+		if legacyFR.Target == "" {
+			return fmt.Errorf("forwarding rule %s of service %s does not point at a target pool", legacyName, l.NamespacedName)
+		}
+		if err := l.switchToBackendService(ctx, legacyFR, instanceGroupLinks, nodeNames); err != nil {
+			l.recorder.Eventf(l.Service, corev1.EventTypeWarning, TargetPoolMigrationFailed, "Failed to migrate to a regional backend service: %v", err)
+			return err
+		}
+		l.recorder.Eventf(l.Service, corev1.EventTypeNormal, TargetPoolMigrated, "Migrated forwarding rule %s to a regional backend service", legacyName)
+		return l.deleteLegacyTargetPool(legacyName, region)
+	}
+
+	fr := l.getForwardingRule(ctx, l.GetFRName(), meta.VersionGA)
+	switch {
+	case fr == nil:
+		if err := l.createNetLB(ctx, instanceGroupLinks, nodeNames); err != nil {
+			return err
+		}
+	case fr.BackendService == "":
+		return fmt.Errorf("forwarding rule %s of service %s does not point at a backend service", fr.Name, l.NamespacedName)
+	default:
+		if _, err := l.ensureNetLBHealthCheckFirewall(nodeNames); err != nil {
+			return err
+		}
+		if err := l.convertNetLBHealthCheck(ctx); err != nil {
+			return err
+		}
+		if err := l.ensureNetLBBackends(ctx, instanceGroupLinks); err != nil {
+			return err
+		}
+		if err := l.ensureNetLBPortRange(); err != nil {
+			return err
+		}
+	}
+	return l.deleteLegacyTargetPool(legacyName, region)
+}
+
+// createNetLB creates the backend service data path and a forwarding rule
+// pointing at it for a service which has no external LoadBalancer yet. The
+// forwarding rule uses the requested LoadBalancer IP of the service, the IP
+// address held by a failed migration, or an ephemeral one. Resources left
+// over by a failed call are reused by the next.
+func (l *L4) createNetLB(ctx context.Context, instanceGroupLinks, nodeNames []string) error {
+	region := l.cloud.Region()
+	portRange, err := netLBPortRange(l.Service)
+	if err != nil {
+		return err
+	}
+	ipAddress := l.Service.Spec.LoadBalancerIP
+	heldAddr, err := l.heldMigrationAddress(region)
+	if err != nil {
+		return err
+	}
+	if ipAddress == "" && heldAddr != nil {
+		ipAddress = heldAddr.Address
+	}
+	bs, _, err := l.ensureNetLBDataPath(ctx, instanceGroupLinks, nodeNames)
+	if err != nil {
+		return err
+	}
+	_, _, protocol := utils.GetPortsAndProtocol(l.Service.Spec.Ports)
+	frName := l.GetFRName()
+	fr := &compute.ForwardingRule{
+		Name:                frName,
+		IPAddress:           ipAddress,
+		IPProtocol:          string(protocol),
+		PortRange:           portRange,
+		LoadBalancingScheme: string(cloud.SchemeExternal),
+		BackendService:      bs.SelfLink,
+	}
+	if heldAddr != nil && heldAddr.Address == ipAddress {
+		fr.NetworkTier = heldAddr.NetworkTier
+	}
+	if err := l.cloud.CreateRegionForwardingRule(fr, region); err != nil {
+		return fmt.Errorf("failed to create forwarding rule %s: %w", frName, err)
+	}
+	klog.V(2).Infof("Created forwarding rule %s of service %s pointing at backend service %s", frName, l.NamespacedName, bs.Name)
+	if heldAddr != nil {
+		if err := utils.IgnoreHTTPNotFound(l.cloud.DeleteRegionAddress(heldAddr.Name, region)); err != nil {
+			return fmt.Errorf("failed to release address %s held by the migration: %w", heldAddr.Name, err)
+		}
+	}
+	return nil
+}
+
+// heldMigrationAddress returns the address reserved by a migration of the
+// service which failed to restore its legacy forwarding rule, or nil if
+// there is none.
+func (l *L4) heldMigrationAddress(region string) (*compute.Address, error) {
+	name := cloudprovider.DefaultLoadBalancerName(l.Service)
+	addr, err := l.cloud.GetRegionAddress(name, region)
+	if err != nil {
+		return nil, utils.IgnoreHTTPNotFound(err)
+	}
+	if addr.Description != l.migrationAddressDescription() {
+		return nil, nil
+	}
+	return addr, nil
+}
+
+// migrationAddressDescription returns the description of the address
+// reserved while the IP address of the service is not used by a forwarding
+// rule during the migration.
+func (l *L4) migrationAddressDescription() string {
+	return fmt.Sprintf("IP address of service %s held during target pool migration", l.NamespacedName)
+}
+
+// ensureNetLBFirewall ensures the firewall rule allowing the traffic of the
+// service from its LoadBalancer source ranges to the given nodes. The rule
+// of the legacy service controller is no longer updated once the service
+// opted in, so this rule takes over.
+func (l *L4) ensureNetLBFirewall(nodeNames []string) error {
+	name, ok := l.namer.VMIPNEG(l.Service.Namespace, l.Service.Name)
+	if !ok {
+		return fmt.Errorf("Namer does not support L4 VMIPNEGs")
+	}
+	sourceRanges, err := helpers.GetLoadBalancerSourceRanges(l.Service)
+	if err != nil {
+		return err
+	}
+	nsName := utils.ServiceKeyFunc(l.Service.Namespace, l.Service.Name)
+	err = l.ignoreFirewallXPNError(firewalls.EnsureL4InternalFirewallRuleAllowed(l.cloud, name, "", nsName, sourceRanges.StringSlice(), firewallAllowed(l.servicePortsByProtocol()), nodeNames, false))
+	if err != nil {
+		return fmt.Errorf("failed to ensure firewall rule %s: %w", name, err)
+	}
+	return nil
+}
+
+// switchToBackendService creates the backend service data path and replaces
+// legacyFR by a forwarding rule pointing at it. All changes are rolled back
+// on failure.
+//
+// An ephemeral IP address of legacyFR is reserved while no forwarding rule
+// uses it. If neither the new forwarding rule nor the restored legacy one
+// exists, the reservation is kept and picked up by the next call.
+func (l *L4) switchToBackendService(ctx context.Context, legacyFR *compute.ForwardingRule, instanceGroupLinks, nodeNames []string) (err error) {
+	region := l.cloud.Region()
+	// rollbacks undo the changes made so far on failure. releaseIP, if set,
+	// runs last and only if a forwarding rule uses the IP address, as
+	// tracked by ipInUse.
+	var rollbacks []func() error
+	var releaseIP func()
+	ipInUse := true
+	defer func() {
+		if err != nil {
+			for i := len(rollbacks) - 1; i >= 0; i-- {
+				if rbErr := rollbacks[i](); rbErr != nil {
+					klog.Errorf("Failed to roll back target pool migration of service %s: %v", l.NamespacedName, rbErr)
+				}
+			}
+		}
+		if releaseIP == nil {
+			return
+		}
+		if !ipInUse {
+			err = fmt.Errorf("%w, IP address %s is kept reserved as address %s until a forwarding rule uses it", err, legacyFR.IPAddress, legacyFR.Name)
+			return
+		}
+		releaseIP()
+	}()
+
+	portRange, err := netLBPortRange(l.Service)
+	if err != nil {
+		return err
+	}
+	bs, dataPathRollbacks, err := l.ensureNetLBDataPath(ctx, instanceGroupLinks, nodeNames)
+	rollbacks = append(rollbacks, dataPathRollbacks...)
+	if err != nil {
+		return err
+	}
+
+	// Reserve the IP address so that it is kept while no forwarding rule
+	// uses it. The reservation is released once the new forwarding rule
+	// exists or the legacy one has been restored.
+	ipReserved, err := l.reserveForwardingRuleIP(legacyFR, region)
+	if err != nil {
+		return err
+	}
+	if ipReserved {
+		releaseIP = func() {
+			if relErr := utils.IgnoreHTTPNotFound(l.cloud.DeleteRegionAddress(legacyFR.Name, region)); relErr != nil {
+				klog.Errorf("Failed to release address %s reserved for the migration of service %s: %v", legacyFR.Name, l.NamespacedName, relErr)
+			}
+		}
+	}
+
+	if err = utils.IgnoreHTTPNotFound(l.cloud.DeleteRegionForwardingRule(legacyFR.Name, region)); err != nil {
+		return fmt.Errorf("failed to delete forwarding rule %s: %w", legacyFR.Name, err)
+	}
+	ipInUse = false
+	rollbacks = append(rollbacks, func() error {
+		if err := l.cloud.CreateRegionForwardingRule(restorableForwardingRule(legacyFR), region); err != nil {
+			return fmt.Errorf("failed to restore forwarding rule %s: %w", legacyFR.Name, err)
+		}
+		ipInUse = true
+		return nil
+	})
+
+	frName := l.GetFRName()
+	fr := &compute.ForwardingRule{
+		Name:                frName,
+		Description:         legacyFR.Description,
+		IPAddress:           legacyFR.IPAddress,
+		IPProtocol:          legacyFR.IPProtocol,
+		PortRange:           portRange,
+		LoadBalancingScheme: string(cloud.SchemeExternal),
+		BackendService:      bs.SelfLink,
+		NetworkTier:         legacyFR.NetworkTier,
+	}
+	if err = l.cloud.CreateRegionForwardingRule(fr, region); err != nil {
+		return fmt.Errorf("failed to create forwarding rule %s: %w", frName, err)
+	}
+	ipInUse = true
+	klog.V(2).Infof("Migrated forwarding rule %s of service %s to backend service %s", legacyFR.Name, l.NamespacedName, bs.Name)
+	return nil
+}
+
+// ensureNetLBDataPath ensures the healthcheck, its firewall rule and the
+// backend service of the external LoadBalancer of the service, with the given
+// instance groups as backends. It returns the backend service along with the
+// functions undoing the changes made, also when an error is returned.
+func (l *L4) ensureNetLBDataPath(ctx context.Context, instanceGroupLinks, nodeNames []string) (*composite.BackendService, []func() error, error) {
+	var rollbacks []func() error
+	region := l.cloud.Region()
+	name, ok := l.namer.VMIPNEG(l.Service.Namespace, l.Service.Name)
+	if !ok {
+		return nil, nil, fmt.Errorf("Namer does not support L4 VMIPNEGs")
+	}
+
+	// Backend service based external LoadBalancers require regional
+	// healthchecks.
+	hcLink, sharedHC, err := l.ensureNetLBHealthCheck(ctx)
+	if err != nil {
+		return nil, rollbacks, err
+	}
+	if !sharedHC {
+		rollbacks = append(rollbacks, func() error {
+			return utils.IgnoreHTTPNotFound(composite.DeleteHealthCheck(ctx, l.cloud, meta.RegionalKey(name, region), meta.VersionGA))
+		})
+	}
+	hcFwName, err := l.ensureNetLBHealthCheckFirewall(nodeNames)
+	if err != nil {
+		return nil, rollbacks, err
+	}
+	if !sharedHC && l.HealthCheckFirewall == nil {
+		rollbacks = append(rollbacks, func() error {
+			return firewalls.EnsureL4InternalFirewallRuleDeleted(l.cloud, hcFwName)
+		})
+	}
+
+	_, _, protocol := utils.GetPortsAndProtocol(l.Service.Spec.Ports)
+	bs, err := l.backendPool.EnsureL4BackendService(ctx, name, hcLink, string(protocol), string(l.Service.Spec.SessionAffinity),
+		string(cloud.SchemeExternal), l.NamespacedName, meta.VersionGA)
+	if err != nil {
+		return nil, rollbacks, fmt.Errorf("failed to ensure backend service %s: %w", name, err)
+	}
+	rollbacks = append(rollbacks, func() error {
+		return utils.IgnoreHTTPNotFound(l.backendPool.Delete(ctx, name, meta.VersionGA, meta.Regional))
+	})
+	bs.Backends = netLBBackends(instanceGroupLinks)
+	if err := composite.UpdateBackendService(ctx, l.cloud, meta.RegionalKey(name, region), bs); err != nil {
+		return nil, rollbacks, fmt.Errorf("failed to add instance groups to backend service %s: %w", name, err)
+	}
+	return bs, rollbacks, nil
+}
+
+// ensureNetLBBackends sets the backends of the backend service of the
+// external LoadBalancer of the service to the given instance groups, e.g.
+// after nodes were added in a new zone.
+func (l *L4) ensureNetLBBackends(ctx context.Context, instanceGroupLinks []string) error {
+	name, ok := l.namer.VMIPNEG(l.Service.Namespace, l.Service.Name)
+	if !ok {
+		return fmt.Errorf("Namer does not support L4 VMIPNEGs")
+	}
+	bs, err := l.backendPool.Get(ctx, name, meta.VersionGA, meta.Regional)
+	if err != nil {
+		return fmt.Errorf("failed to get backend service %s: %w", name, err)
+	}
+	have := sets.NewString()
+	for _, be := range bs.Backends {
+		have.Insert(be.Group)
+	}
+	if have.Equal(sets.NewString(instanceGroupLinks...)) {
+		return nil
+	}
+	klog.V(2).Infof("Updating backends of backend service %s of service %s to %v", name, l.NamespacedName, instanceGroupLinks)
+	bs.Backends = netLBBackends(instanceGroupLinks)
+	if err := composite.UpdateBackendService(ctx, l.cloud, meta.RegionalKey(name, l.cloud.Region()), bs); err != nil {
+		return fmt.Errorf("failed to update backends of backend service %s: %w", name, err)
+	}
+	return nil
+}
+
+// netLBBackends returns the backends of an external LoadBalancer backend
+// service for the given instance groups.
+func netLBBackends(instanceGroupLinks []string) []*composite.Backend {
+	var ret []*composite.Backend
+	for _, link := range instanceGroupLinks {
+		ret = append(ret, &composite.Backend{Group: link, BalancingMode: string(backends.Connections)})
+	}
+	return ret
+}
+
+// reserveForwardingRuleIP reserves the IP address of fr if it is ephemeral.
+// It returns true if an address was reserved.
+func (l *L4) reserveForwardingRuleIP(fr *compute.ForwardingRule, region string) (bool, error) {
+	addr, err := l.cloud.GetRegionAddressByIP(region, fr.IPAddress)
+	if utils.IgnoreHTTPNotFound(err) != nil {
+		return false, fmt.Errorf("failed to look up address %s: %w", fr.IPAddress, err)
+	}
+	if addr != nil {
+		return false, nil
+	}
+	err = l.cloud.ReserveRegionAddress(&compute.Address{
+		Name:        fr.Name,
+		Address:     fr.IPAddress,
+		NetworkTier: fr.NetworkTier,
+		Description: l.migrationAddressDescription(),
+	}, region)
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve address %s: %w", fr.IPAddress, err)
+	}
+	return true, nil
+}
+
+// deleteLegacyTargetPool deletes the target pool created by the legacy
+// service controller along with its per-service healthcheck. Healthchecks
+// shared with other target pools are left in place.
+func (l *L4) deleteLegacyTargetPool(name, region string) error {
+	tp, err := l.cloud.GetTargetPool(name, region)
+	if err != nil {
+		return utils.IgnoreHTTPNotFound(err)
+	}
+	if err := utils.IgnoreHTTPNotFound(l.cloud.DeleteTargetPool(name, region)); err != nil {
+		return fmt.Errorf("failed to delete target pool %s: %w", name, err)
+	}
+	for _, hcLink := range tp.HealthChecks {
+		hcName, err := utils.KeyName(hcLink)
+		if err != nil {
+			return err
+		}
+		// The legacy service controller names per-service healthchecks
+		// after the LoadBalancer.
+		if hcName != name {
+			continue
+		}
+		if err := utils.IgnoreHTTPNotFound(l.cloud.DeleteHTTPHealthCheck(hcName)); err != nil {
+			return fmt.Errorf("failed to delete healthcheck %s: %w", hcName, err)
+		}
+	}
+	return nil
+}
+
+// restorableForwardingRule returns a copy of fr without the fields set by GCE,
+// so that it can be created again.
+func restorableForwardingRule(fr *compute.ForwardingRule) *compute.ForwardingRule {
+	ret := *fr
+	ret.Id = 0
+	ret.SelfLink = ""
+	ret.CreationTimestamp = ""
+	ret.Fingerprint = ""
+	ret.Region = ""
+	ret.ServerResponse = googleapi.ServerResponse{}
+	return &ret
+}
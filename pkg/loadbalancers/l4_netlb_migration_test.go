@@ -0,0 +1,298 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/mock"
+	"google.golang.org/api/compute/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/ingress-gce/pkg/annotations"
+	"k8s.io/ingress-gce/pkg/test"
+	"k8s.io/ingress-gce/pkg/utils"
+	namer_util "k8s.io/ingress-gce/pkg/utils/namer"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+const legacyLBIP = "35.1.2.3"
+
+// newLegacyNetLB creates the resources of a target pool based external
+// LoadBalancer as created by the legacy service controller.
+func newLegacyNetLB(t *testing.T, fakeGCE *gce.Cloud, name string) {
+	t.Helper()
+	region := fakeGCE.Region()
+	if err := fakeGCE.CreateHTTPHealthCheck(&compute.HttpHealthCheck{Name: name, Port: 10256, RequestPath: "/healthz"}); err != nil {
+		t.Fatalf("CreateHTTPHealthCheck(%s) = %v", name, err)
+	}
+	hc, err := fakeGCE.GetHTTPHealthCheck(name)
+	if err != nil {
+		t.Fatalf("GetHTTPHealthCheck(%s) = %v", name, err)
+	}
+	if err := fakeGCE.CreateTargetPool(&compute.TargetPool{Name: name, HealthChecks: []string{hc.SelfLink}}, region); err != nil {
+		t.Fatalf("CreateTargetPool(%s) = %v", name, err)
+	}
+	tp, err := fakeGCE.GetTargetPool(name, region)
+	if err != nil {
+		t.Fatalf("GetTargetPool(%s) = %v", name, err)
+	}
+	fr := &compute.ForwardingRule{
+		Name:                name,
+		IPAddress:           legacyLBIP,
+		IPProtocol:          "TCP",
+		PortRange:           "8080-8080",
+		LoadBalancingScheme: string(cloud.SchemeExternal),
+		Target:              tp.SelfLink,
+	}
+	if err := fakeGCE.CreateRegionForwardingRule(fr, region); err != nil {
+		t.Fatalf("CreateRegionForwardingRule(%s) = %v", name, err)
+	}
+}
+
+func newMigrationHandler(t *testing.T) (*L4, string) {
+	t.Helper()
+	vals := gce.DefaultTestClusterValues()
+	fakeGCE := getFakeGCECloud(vals)
+	if _, err := test.CreateAndInsertNodes(fakeGCE, []string{"test-node-1"}, vals.ZoneName); err != nil {
+		t.Fatalf("CreateAndInsertNodes() = %v", err)
+	}
+	svc := test.NewL4ILBService(true, 8080)
+	svc.UID = types.UID("0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0")
+	delete(svc.Annotations, gce.ServiceAnnotationLoadBalancerType)
+	namer := namer_util.NewL4Namer(kubeSystemUID, nil)
+	l := NewL4Handler(svc, fakeGCE, meta.Regional, namer, record.NewFakeRecorder(100), &sync.Mutex{})
+	legacyName := cloudprovider.DefaultLoadBalancerName(svc)
+	newLegacyNetLB(t, fakeGCE, legacyName)
+	return l, legacyName
+}
+
+func TestMigrateTargetPoolToRBS(t *testing.T) {
+	t.Parallel()
+	l, legacyName := newMigrationHandler(t)
+	region := l.cloud.Region()
+	igLink := "https://www.googleapis.com/compute/v1/projects/test-project/zones/us-central1-b/instanceGroups/k8s-ig"
+
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), []string{igLink}, []string{"test-node-1"}); err != nil {
+		t.Fatalf("MigrateTargetPoolToRBS() = %v", err)
+	}
+
+	if _, err := l.cloud.GetRegionForwardingRule(legacyName, region); !utils.IsNotFoundError(err) {
+		t.Errorf("GetRegionForwardingRule(%s) = %v, want legacy forwarding rule deleted", legacyName, err)
+	}
+	fr, err := l.cloud.GetRegionForwardingRule(l.GetFRName(), region)
+	if err != nil {
+		t.Fatalf("GetRegionForwardingRule(%s) = %v", l.GetFRName(), err)
+	}
+	if fr.IPAddress != legacyLBIP || fr.BackendService == "" || fr.Target != "" {
+		t.Errorf("Forwarding rule = %+v, want IP %s pointing at a backend service", fr, legacyLBIP)
+	}
+	bsName, _ := l.namer.VMIPNEG(l.Service.Namespace, l.Service.Name)
+	bs, err := l.backendPool.Get(context.TODO(), bsName, meta.VersionGA, meta.Regional)
+	if err != nil {
+		t.Fatalf("Get(%s) = %v", bsName, err)
+	}
+	if bs.LoadBalancingScheme != string(cloud.SchemeExternal) || len(bs.Backends) != 1 || bs.Backends[0].Group != igLink {
+		t.Errorf("Backend service = %+v, want external scheme with instance group %s", bs, igLink)
+	}
+	if _, err := l.cloud.GetTargetPool(legacyName, region); !utils.IsNotFoundError(err) {
+		t.Errorf("GetTargetPool(%s) = %v, want target pool deleted", legacyName, err)
+	}
+	if _, err := l.cloud.GetHTTPHealthCheck(legacyName); !utils.IsNotFoundError(err) {
+		t.Errorf("GetHTTPHealthCheck(%s) = %v, want legacy healthcheck deleted", legacyName, err)
+	}
+	if _, err := l.cloud.GetRegionAddress(legacyName, region); !utils.IsNotFoundError(err) {
+		t.Errorf("GetRegionAddress(%s) = %v, want address released", legacyName, err)
+	}
+
+	// Migrating again is a no-op.
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), []string{igLink}, []string{"test-node-1"}); err != nil {
+		t.Errorf("MigrateTargetPoolToRBS() after migration = %v", err)
+	}
+}
+
+func TestMigrateTargetPoolToRBSRollback(t *testing.T) {
+	t.Parallel()
+	l, legacyName := newMigrationHandler(t)
+	region := l.cloud.Region()
+	mockGCE := l.cloud.Compute().(*cloud.MockGCE)
+	mockGCE.MockForwardingRules.InsertHook = func(ctx context.Context, key *meta.Key, obj *compute.ForwardingRule, m *cloud.MockForwardingRules) (bool, error) {
+		if obj.BackendService != "" {
+			return true, fmt.Errorf("injected error")
+		}
+		return mock.InsertFwdRuleHook(ctx, key, obj, m)
+	}
+
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), nil, []string{"test-node-1"}); err == nil {
+		t.Fatalf("MigrateTargetPoolToRBS() = nil, want error")
+	}
+
+	fr, err := l.cloud.GetRegionForwardingRule(legacyName, region)
+	if err != nil {
+		t.Fatalf("GetRegionForwardingRule(%s) = %v, want legacy forwarding rule restored", legacyName, err)
+	}
+	if fr.IPAddress != legacyLBIP || fr.Target == "" {
+		t.Errorf("Forwarding rule = %+v, want IP %s pointing at the target pool", fr, legacyLBIP)
+	}
+	bsName, _ := l.namer.VMIPNEG(l.Service.Namespace, l.Service.Name)
+	if _, err := l.backendPool.Get(context.TODO(), bsName, meta.VersionGA, meta.Regional); !utils.IsNotFoundError(err) {
+		t.Errorf("Get(%s) = %v, want backend service deleted", bsName, err)
+	}
+	if _, err := l.cloud.GetTargetPool(legacyName, region); err != nil {
+		t.Errorf("GetTargetPool(%s) = %v, want target pool kept", legacyName, err)
+	}
+	if _, err := l.cloud.GetRegionAddress(legacyName, region); !utils.IsNotFoundError(err) {
+		t.Errorf("GetRegionAddress(%s) = %v, want address released", legacyName, err)
+	}
+}
+
+func TestMigrateTargetPoolToRBSFailedRollback(t *testing.T) {
+	t.Parallel()
+	l, legacyName := newMigrationHandler(t)
+	region := l.cloud.Region()
+	mockGCE := l.cloud.Compute().(*cloud.MockGCE)
+	mockGCE.MockForwardingRules.InsertHook = func(ctx context.Context, key *meta.Key, obj *compute.ForwardingRule, m *cloud.MockForwardingRules) (bool, error) {
+		return true, fmt.Errorf("injected error")
+	}
+
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), nil, []string{"test-node-1"}); err == nil {
+		t.Fatalf("MigrateTargetPoolToRBS() = nil, want error")
+	}
+	if _, err := l.cloud.GetRegionForwardingRule(legacyName, region); !utils.IsNotFoundError(err) {
+		t.Fatalf("GetRegionForwardingRule(%s) = %v, want legacy forwarding rule not restored", legacyName, err)
+	}
+	// The IP address stays reserved while no forwarding rule uses it.
+	addr, err := l.cloud.GetRegionAddress(legacyName, region)
+	if err != nil {
+		t.Fatalf("GetRegionAddress(%s) = %v, want address kept", legacyName, err)
+	}
+	if addr.Address != legacyLBIP {
+		t.Errorf("Address = %+v, want IP %s", addr, legacyLBIP)
+	}
+
+	// The next call creates the forwarding rule with the reserved IP address.
+	mockGCE.MockForwardingRules.InsertHook = mock.InsertFwdRuleHook
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), nil, []string{"test-node-1"}); err != nil {
+		t.Fatalf("MigrateTargetPoolToRBS() after failed rollback = %v", err)
+	}
+	fr, err := l.cloud.GetRegionForwardingRule(l.GetFRName(), region)
+	if err != nil {
+		t.Fatalf("GetRegionForwardingRule(%s) = %v", l.GetFRName(), err)
+	}
+	if fr.IPAddress != legacyLBIP || fr.BackendService == "" {
+		t.Errorf("Forwarding rule = %+v, want IP %s pointing at a backend service", fr, legacyLBIP)
+	}
+	if _, err := l.cloud.GetRegionAddress(legacyName, region); !utils.IsNotFoundError(err) {
+		t.Errorf("GetRegionAddress(%s) = %v, want address released", legacyName, err)
+	}
+	if _, err := l.cloud.GetTargetPool(legacyName, region); !utils.IsNotFoundError(err) {
+		t.Errorf("GetTargetPool(%s) = %v, want target pool deleted", legacyName, err)
+	}
+}
+
+func TestMigrateTargetPoolToRBSPortRange(t *testing.T) {
+	t.Parallel()
+	l, _ := newMigrationHandler(t)
+	region := l.cloud.Region()
+	l.Service.Annotations[annotations.PortRangeKey] = "8000-9000"
+
+	checkPortRange := func(want string) {
+		t.Helper()
+		fr, err := l.cloud.GetRegionForwardingRule(l.GetFRName(), region)
+		if err != nil {
+			t.Fatalf("GetRegionForwardingRule(%s) = %v", l.GetFRName(), err)
+		}
+		if fr.PortRange != want || fr.IPAddress != legacyLBIP || fr.BackendService == "" {
+			t.Errorf("Forwarding rule = %+v, want port range %s with IP %s pointing at a backend service", fr, want, legacyLBIP)
+		}
+	}
+
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), nil, []string{"test-node-1"}); err != nil {
+		t.Fatalf("MigrateTargetPoolToRBS() = %v", err)
+	}
+	checkPortRange("8000-9000")
+
+	// The forwarding rule is recreated when the port range changes.
+	l.Service.Annotations[annotations.PortRangeKey] = "8080-10000"
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), nil, []string{"test-node-1"}); err != nil {
+		t.Fatalf("MigrateTargetPoolToRBS() after port range change = %v", err)
+	}
+	checkPortRange("8080-10000")
+
+	// A range which does not contain the ports of the service is rejected.
+	l.Service.Annotations[annotations.PortRangeKey] = "9000-10000"
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), nil, []string{"test-node-1"}); err == nil {
+		t.Errorf("MigrateTargetPoolToRBS() with port range excluding the service port = nil, want error")
+	}
+	checkPortRange("8080-10000")
+
+	// Without annotation the ports of the service are forwarded.
+	delete(l.Service.Annotations, annotations.PortRangeKey)
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), nil, []string{"test-node-1"}); err != nil {
+		t.Fatalf("MigrateTargetPoolToRBS() after port range removal = %v", err)
+	}
+	checkPortRange("8080-8080")
+}
+
+func TestMigrateTargetPoolToRBSNewService(t *testing.T) {
+	t.Parallel()
+	vals := gce.DefaultTestClusterValues()
+	fakeGCE := getFakeGCECloud(vals)
+	if _, err := test.CreateAndInsertNodes(fakeGCE, []string{"test-node-1"}, vals.ZoneName); err != nil {
+		t.Fatalf("CreateAndInsertNodes() = %v", err)
+	}
+	svc := test.NewL4ILBService(true, 8080)
+	svc.UID = types.UID("0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0")
+	delete(svc.Annotations, gce.ServiceAnnotationLoadBalancerType)
+	namer := namer_util.NewL4Namer(kubeSystemUID, nil)
+	l := NewL4Handler(svc, fakeGCE, meta.Regional, namer, record.NewFakeRecorder(100), &sync.Mutex{})
+	region := l.cloud.Region()
+	igLink := "https://www.googleapis.com/compute/v1/projects/test-project/zones/us-central1-b/instanceGroups/k8s-ig"
+
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), []string{igLink}, []string{"test-node-1"}); err != nil {
+		t.Fatalf("MigrateTargetPoolToRBS() = %v", err)
+	}
+	fr, err := l.cloud.GetRegionForwardingRule(l.GetFRName(), region)
+	if err != nil {
+		t.Fatalf("GetRegionForwardingRule(%s) = %v", l.GetFRName(), err)
+	}
+	if fr.BackendService == "" || fr.PortRange != "8080-8080" || fr.LoadBalancingScheme != string(cloud.SchemeExternal) {
+		t.Errorf("Forwarding rule = %+v, want external port range 8080-8080 pointing at a backend service", fr)
+	}
+	bsName, _ := l.namer.VMIPNEG(l.Service.Namespace, l.Service.Name)
+	bs, err := l.backendPool.Get(context.TODO(), bsName, meta.VersionGA, meta.Regional)
+	if err != nil {
+		t.Fatalf("Get(%s) = %v", bsName, err)
+	}
+	if len(bs.HealthChecks) != 1 || len(bs.Backends) != 1 || bs.Backends[0].Group != igLink {
+		t.Errorf("Backend service = %+v, want a healthcheck and instance group %s", bs, igLink)
+	}
+	if _, err := l.cloud.GetFirewall(bsName); err != nil {
+		t.Errorf("GetFirewall(%s) = %v, want traffic firewall rule", bsName, err)
+	}
+
+	// Migrating again keeps the LoadBalancer.
+	if err := l.MigrateTargetPoolToRBS(context.TODO(), []string{igLink}, []string{"test-node-1"}); err != nil {
+		t.Errorf("MigrateTargetPoolToRBS() after creation = %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/ingress-gce/pkg/annotations"
+	"k8s.io/ingress-gce/pkg/utils"
+	"k8s.io/klog"
+)
+
+// PortRangeChanged is the event reason used when the external forwarding
+// rule of a LoadBalancer service was recreated with a new port range.
+const PortRangeChanged = "PortRangeChanged"
+
+// netLBPortRange returns the port range of the external forwarding rule of
+// the service. It is the range of the port range annotation, or else the
+// range spanned by the ports of the service, like the legacy service
+// controller.
+func netLBPortRange(svc *corev1.Service) (string, error) {
+	first, last, ok, err := annotations.FromService(svc).PortRange()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		for i, port := range svc.Spec.Ports {
+			if i == 0 || port.Port < first {
+				first = port.Port
+			}
+			if i == 0 || port.Port > last {
+				last = port.Port
+			}
+		}
+		return fmt.Sprintf("%d-%d", first, last), nil
+	}
+	for _, port := range svc.Spec.Ports {
+		if port.Port < first || port.Port > last {
+			return "", fmt.Errorf("port %d of service %s/%s is not in port range %d-%d", port.Port, svc.Namespace, svc.Name, first, last)
+		}
+	}
+	return fmt.Sprintf("%d-%d", first, last), nil
+}
+
+// ensureNetLBPortRange recreates the external forwarding rule of the service
+// if its port range differs from the one requested, since the ports of a
+// forwarding rule cannot be updated. The IP address of the forwarding rule is
+// kept. The previous forwarding rule is restored on failure.
+func (l *L4) ensureNetLBPortRange() error {
+	portRange, err := netLBPortRange(l.Service)
+	if err != nil {
+		return err
+	}
+	region := l.cloud.Region()
+	fr, err := l.cloud.GetRegionForwardingRule(l.GetFRName(), region)
+	if err != nil {
+		return utils.IgnoreHTTPNotFound(err)
+	}
+	if fr.PortRange == portRange {
+		return nil
+	}
+	klog.V(2).Infof("Recreating forwarding rule %s of service %s to change its port range from %s to %s", fr.Name, l.NamespacedName, fr.PortRange, portRange)
+
+	ipReserved, err := l.reserveForwardingRuleIP(fr, region)
+	if err != nil {
+		return err
+	}
+	if ipReserved {
+		defer func() {
+			if relErr := utils.IgnoreHTTPNotFound(l.cloud.DeleteRegionAddress(fr.Name, region)); relErr != nil {
+				klog.Errorf("Failed to release address %s reserved for the port range change of service %s: %v", fr.Name, l.NamespacedName, relErr)
+			}
+		}()
+	}
+
+	if err := utils.IgnoreHTTPNotFound(l.cloud.DeleteRegionForwardingRule(fr.Name, region)); err != nil {
+		return fmt.Errorf("failed to delete forwarding rule %s: %w", fr.Name, err)
+	}
+	newFR := restorableForwardingRule(fr)
+	newFR.PortRange = portRange
+	if err := l.cloud.CreateRegionForwardingRule(newFR, region); err != nil {
+		if rbErr := l.cloud.CreateRegionForwardingRule(restorableForwardingRule(fr), region); rbErr != nil {
+			klog.Errorf("Failed to restore forwarding rule %s of service %s: %v", fr.Name, l.NamespacedName, rbErr)
+		}
+		return fmt.Errorf("failed to create forwarding rule %s with port range %s: %w", fr.Name, portRange, err)
+	}
+	l.recorder.Eventf(l.Service, corev1.EventTypeNormal, PortRangeChanged, "Forwarding rule %s now forwards ports %s", fr.Name, portRange)
+	return nil
+}
@@ -27,6 +27,7 @@ import (
 	"google.golang.org/api/compute/v1"
 	"k8s.io/ingress-gce/pkg/backends"
 	"k8s.io/ingress-gce/pkg/firewalls"
+	"k8s.io/ingress-gce/pkg/flags"
 	"k8s.io/ingress-gce/pkg/utils"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
@@ -69,18 +70,18 @@ func TestEnsureInternalBackendServiceUpdates(t *testing.T) {
 	namer := namer_util.NewL4Namer(kubeSystemUID, nil)
 	l := NewL4Handler(svc, fakeGCE, meta.Regional, namer, record.NewFakeRecorder(100), &sync.Mutex{})
 	bsName, _ := l.namer.VMIPNEG(l.Service.Namespace, l.Service.Name)
-	_, err := l.backendPool.EnsureL4BackendService(bsName, "", "TCP", string(svc.Spec.SessionAffinity), string(cloud.SchemeInternal), l.NamespacedName, meta.VersionGA)
+	_, err := l.backendPool.EnsureL4BackendService(context.TODO(), bsName, "", "TCP", string(svc.Spec.SessionAffinity), string(cloud.SchemeInternal), l.NamespacedName, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to ensure backend service  %s - err %v", bsName, err)
 	}
 
 	// Update the Internal Backend Service with a new ServiceAffinity
-	_, err = l.backendPool.EnsureL4BackendService(bsName, "", "TCP", string(v1.ServiceAffinityNone), string(cloud.SchemeInternal), l.NamespacedName, meta.VersionGA)
+	_, err = l.backendPool.EnsureL4BackendService(context.TODO(), bsName, "", "TCP", string(v1.ServiceAffinityNone), string(cloud.SchemeInternal), l.NamespacedName, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to ensure backend service  %s - err %v", bsName, err)
 	}
 	key := meta.RegionalKey(bsName, l.cloud.Region())
-	bs, err := composite.GetBackendService(l.cloud, key, meta.VersionGA)
+	bs, err := composite.GetBackendService(context.TODO(), l.cloud, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to get backend service  %s - err %v", bsName, err)
 	}
@@ -92,11 +93,11 @@ func TestEnsureInternalBackendServiceUpdates(t *testing.T) {
 	newTimeout := int64(backends.DefaultConnectionDrainingTimeoutSeconds * 2)
 	bs.ConnectionDraining.DrainingTimeoutSec = newTimeout
 	bs.SessionAffinity = strings.ToUpper(string(v1.ServiceAffinityClientIP))
-	err = composite.UpdateBackendService(l.cloud, key, bs)
+	err = composite.UpdateBackendService(context.TODO(), l.cloud, key, bs)
 	if err != nil {
 		t.Errorf("Failed to update backend service with new connection draining timeout - err %v", err)
 	}
-	bs, err = l.backendPool.EnsureL4BackendService(bsName, "", "TCP", string(v1.ServiceAffinityNone), string(cloud.SchemeInternal), l.NamespacedName, meta.VersionGA)
+	bs, err = l.backendPool.EnsureL4BackendService(context.TODO(), bsName, "", "TCP", string(v1.ServiceAffinityNone), string(cloud.SchemeInternal), l.NamespacedName, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to ensure backend service  %s - err %v", bsName, err)
 	}
@@ -121,7 +122,7 @@ func TestEnsureInternalLoadBalancer(t *testing.T) {
 		t.Errorf("Unexpected error when adding nodes %v", err)
 	}
 
-	result := l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -132,7 +133,7 @@ func TestEnsureInternalLoadBalancer(t *testing.T) {
 
 	backendServiceName, _ := l.namer.VMIPNEG(l.Service.Namespace, l.Service.Name)
 	key := meta.RegionalKey(backendServiceName, l.cloud.Region())
-	bs, err := composite.GetBackendService(l.cloud, key, meta.VersionGA)
+	bs, err := composite.GetBackendService(context.TODO(), l.cloud, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to lookup backend service, err %v", err)
 	}
@@ -142,11 +143,11 @@ func TestEnsureInternalLoadBalancer(t *testing.T) {
 	}
 	// Add a backend list to simulate NEG linker populating the backends.
 	bs.Backends = []*composite.Backend{{Group: "test"}}
-	if err := composite.UpdateBackendService(l.cloud, key, bs); err != nil {
+	if err := composite.UpdateBackendService(context.TODO(), l.cloud, key, bs); err != nil {
 		t.Errorf("Failed updating backend service, err %v", err)
 	}
 	// Simulate a periodic sync. The backends list should not be reconciled.
-	result = l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result = l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -154,7 +155,7 @@ func TestEnsureInternalLoadBalancer(t *testing.T) {
 		t.Errorf("Got empty loadBalancer status using handler %v", l)
 	}
 	assertInternalLbResources(t, svc, l, nodeNames, result.Annotations)
-	bs, err = composite.GetBackendService(l.cloud, meta.RegionalKey(backendServiceName, l.cloud.Region()), meta.VersionGA)
+	bs, err = composite.GetBackendService(context.TODO(), l.cloud, meta.RegionalKey(backendServiceName, l.cloud.Region()), meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to lookup backend service, err %v", err)
 	}
@@ -175,7 +176,7 @@ func TestEnsureInternalLoadBalancerTypeChange(t *testing.T) {
 	if _, err := test.CreateAndInsertNodes(l.cloud, nodeNames, vals.ZoneName); err != nil {
 		t.Errorf("Unexpected error when adding nodes %v", err)
 	}
-	result := l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Unexpected error %v", result.Error)
 	}
@@ -187,7 +188,7 @@ func TestEnsureInternalLoadBalancerTypeChange(t *testing.T) {
 	// Now add the latest annotation and change scheme to external
 	svc.Annotations[gce.ServiceAnnotationLoadBalancerType] = ""
 	// This will be invoked by service_controller
-	if result = l.EnsureInternalLoadBalancerDeleted(svc); result.Error != nil {
+	if result = l.EnsureInternalLoadBalancerDeleted(context.TODO(), svc); result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
 	assertInternalLbResourcesDeleted(t, svc, true, l)
@@ -213,16 +214,16 @@ func TestEnsureInternalLoadBalancerWithExistingResources(t *testing.T) {
 	sharedHC := !servicehelper.RequestsOnlyLocalTraffic(svc)
 	hcName, _ := l.namer.L4HealthCheck(svc.Namespace, svc.Name, sharedHC)
 	hcPath, hcPort := gce.GetNodesHealthCheckPath(), gce.GetNodesHealthCheckPort()
-	_, hcLink, err := healthchecks.EnsureL4HealthCheck(l.cloud, hcName, l.NamespacedName, sharedHC, hcPath, hcPort)
+	_, hcLink, err := healthchecks.EnsureL4HealthCheck(context.TODO(), l.cloud, hcName, l.NamespacedName, sharedHC, hcPath, hcPort)
 	if err != nil {
 		t.Errorf("Failed to create healthcheck, err %v", err)
 	}
-	_, err = l.backendPool.EnsureL4BackendService(lbName, hcLink, "TCP", string(l.Service.Spec.SessionAffinity),
+	_, err = l.backendPool.EnsureL4BackendService(context.TODO(), lbName, hcLink, "TCP", string(l.Service.Spec.SessionAffinity),
 		string(cloud.SchemeInternal), l.NamespacedName, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to create backendservice, err %v", err)
 	}
-	result := l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -264,7 +265,7 @@ func TestEnsureInternalLoadBalancerClearPreviousResources(t *testing.T) {
 		IPProtocol:          "TCP",
 		LoadBalancingScheme: string(cloud.SchemeInternal),
 	}
-	if err = composite.CreateForwardingRule(l.cloud, key, existingFwdRule); err != nil {
+	if err = composite.CreateForwardingRule(context.TODO(), l.cloud, key, existingFwdRule); err != nil {
 		t.Errorf("Failed to create fake forwarding rule %s, err %v", lbName, err)
 	}
 	key.Name = lbName
@@ -287,7 +288,7 @@ func TestEnsureInternalLoadBalancerClearPreviousResources(t *testing.T) {
 	// Create a healthcheck with an incomplete fields
 	existingHC := &composite.HealthCheck{Name: hcName}
 	// hcName will be same as lbName since this service uses trafficPolicy Local. So the same key can be used.
-	if err = composite.CreateHealthCheck(fakeGCE, key, existingHC); err != nil {
+	if err = composite.CreateHealthCheck(context.TODO(), fakeGCE, key, existingHC); err != nil {
 		t.Errorf("Failed to create fake healthcheck %s, err %v", hcName, err)
 	}
 
@@ -298,24 +299,24 @@ func TestEnsureInternalLoadBalancerClearPreviousResources(t *testing.T) {
 		LoadBalancingScheme: string(cloud.SchemeInternal),
 	}
 
-	if err = composite.CreateBackendService(fakeGCE, key, existingBS); err != nil {
+	if err = composite.CreateBackendService(context.TODO(), fakeGCE, key, existingBS); err != nil {
 		t.Errorf("Failed to create fake backend service %s, err %v", lbName, err)
 	}
 	key.Name = frName
 	// Set the backend service link correctly, so that forwarding rule comparison works correctly
 	existingFwdRule.BackendService = cloud.SelfLink(meta.VersionGA, vals.ProjectID, "backendServices", meta.RegionalKey(existingBS.Name, vals.Region))
-	if err = composite.DeleteForwardingRule(fakeGCE, key, meta.VersionGA); err != nil {
+	if err = composite.DeleteForwardingRule(context.TODO(), fakeGCE, key, meta.VersionGA); err != nil {
 		t.Errorf("Failed to delete forwarding rule, err %v", err)
 	}
-	if err = composite.CreateForwardingRule(fakeGCE, key, existingFwdRule); err != nil {
+	if err = composite.CreateForwardingRule(context.TODO(), fakeGCE, key, existingFwdRule); err != nil {
 		t.Errorf("Failed to update forwarding rule with new BS link, err %v", err)
 	}
-	if result := l.EnsureInternalLoadBalancer(nodeNames, svc); result.Error != nil {
+	if result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc); result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer %s, err %v", lbName, result.Error)
 	}
 	key.Name = frName
 	// Expect new resources with the correct attributes to be created
-	newFwdRule, err := composite.GetForwardingRule(fakeGCE, key, meta.VersionGA)
+	newFwdRule, err := composite.GetForwardingRule(context.TODO(), fakeGCE, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to lookup forwarding rule %s, err %v", lbName, err)
 	}
@@ -332,7 +333,7 @@ func TestEnsureInternalLoadBalancerClearPreviousResources(t *testing.T) {
 	}
 
 	key.Name = lbName
-	newHC, err := composite.GetHealthCheck(fakeGCE, key, meta.VersionGA)
+	newHC, err := composite.GetHealthCheck(context.TODO(), fakeGCE, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to lookup healthcheck %s, err %v", lbName, err)
 	}
@@ -340,7 +341,7 @@ func TestEnsureInternalLoadBalancerClearPreviousResources(t *testing.T) {
 		t.Errorf("Expected incomplete healthcheck to be updated")
 	}
 
-	newBS, err := composite.GetBackendService(fakeGCE, key, meta.VersionGA)
+	newBS, err := composite.GetBackendService(context.TODO(), fakeGCE, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to lookup backend service %s, err %v", lbName, err)
 	}
@@ -373,13 +374,13 @@ func TestUpdateResourceLinks(t *testing.T) {
 	}
 	key.Name = "hc1"
 	hc1 := &composite.HealthCheck{Name: "hc1"}
-	if err = composite.CreateHealthCheck(fakeGCE, key, hc1); err != nil {
+	if err = composite.CreateHealthCheck(context.TODO(), fakeGCE, key, hc1); err != nil {
 		t.Errorf("Failed to create fake healthcheck hc1 , err %v", err)
 	}
 
 	key.Name = "hc2"
 	hc2 := &composite.HealthCheck{Name: "hc2"}
-	if err = composite.CreateHealthCheck(fakeGCE, key, hc2); err != nil {
+	if err = composite.CreateHealthCheck(context.TODO(), fakeGCE, key, hc2); err != nil {
 		t.Errorf("Failed to create fake healthcheck hc2, err %v", err)
 	}
 
@@ -392,17 +393,17 @@ func TestUpdateResourceLinks(t *testing.T) {
 		HealthChecks:        []string{"hc1", "hc2"},
 	}
 
-	if err = composite.CreateBackendService(fakeGCE, key, existingBS); err != nil {
+	if err = composite.CreateBackendService(context.TODO(), fakeGCE, key, existingBS); err != nil {
 		t.Errorf("Failed to create fake backend service %s, err %v", lbName, err)
 	}
-	bs, err := composite.GetBackendService(fakeGCE, key, meta.VersionGA)
+	bs, err := composite.GetBackendService(context.TODO(), fakeGCE, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to lookup backend service")
 	}
 	if !reflect.DeepEqual(bs.HealthChecks, []string{"hc1", "hc2"}) {
 		t.Errorf("Unexpected healthchecks in backend service - %v", bs.HealthChecks)
 	}
-	result := l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer %s, err %v", lbName, result.Error)
 	}
@@ -411,14 +412,14 @@ func TestUpdateResourceLinks(t *testing.T) {
 
 	// ensure that the other healthchecks still exist.
 	key.Name = "hc1"
-	if hc1, err = composite.GetHealthCheck(fakeGCE, key, meta.VersionGA); err != nil {
+	if hc1, err = composite.GetHealthCheck(context.TODO(), fakeGCE, key, meta.VersionGA); err != nil {
 		t.Errorf("Failed to lookup healthcheck - hc1")
 	}
 	if hc1 == nil {
 		t.Errorf("Got nil healthcheck")
 	}
 	key.Name = "hc2"
-	if hc2, err = composite.GetHealthCheck(fakeGCE, key, meta.VersionGA); err != nil {
+	if hc2, err = composite.GetHealthCheck(context.TODO(), fakeGCE, key, meta.VersionGA); err != nil {
 		t.Errorf("Failed to lookup healthcheck - hc1")
 	}
 	if hc2 == nil {
@@ -450,15 +451,15 @@ func TestEnsureInternalLoadBalancerHealthCheckConfigurable(t *testing.T) {
 
 	// Create a healthcheck with an incorrect threshold, default value is 8s.
 	existingHC := &composite.HealthCheck{Name: hcName, CheckIntervalSec: 6000}
-	if err = composite.CreateHealthCheck(fakeGCE, key, existingHC); err != nil {
+	if err = composite.CreateHealthCheck(context.TODO(), fakeGCE, key, existingHC); err != nil {
 		t.Errorf("Failed to create fake healthcheck %s, err %v", hcName, err)
 	}
 
-	if result := l.EnsureInternalLoadBalancer(nodeNames, svc); result.Error != nil {
+	if result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc); result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer %s, err %v", lbName, result.Error)
 	}
 
-	newHC, err := composite.GetHealthCheck(fakeGCE, key, meta.VersionGA)
+	newHC, err := composite.GetHealthCheck(context.TODO(), fakeGCE, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to lookup healthcheck %s, err %v", lbName, err)
 	}
@@ -479,7 +480,7 @@ func TestEnsureInternalLoadBalancerDeleted(t *testing.T) {
 	if _, err := test.CreateAndInsertNodes(l.cloud, nodeNames, vals.ZoneName); err != nil {
 		t.Errorf("Unexpected error when adding nodes %v", err)
 	}
-	result := l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -489,7 +490,7 @@ func TestEnsureInternalLoadBalancerDeleted(t *testing.T) {
 	assertInternalLbResources(t, svc, l, nodeNames, result.Annotations)
 
 	// Delete the loadbalancer
-	result = l.EnsureInternalLoadBalancerDeleted(svc)
+	result = l.EnsureInternalLoadBalancerDeleted(context.TODO(), svc)
 	if result.Error != nil {
 		t.Errorf("Unexpected error %v", result.Error)
 	}
@@ -508,7 +509,7 @@ func TestEnsureInternalLoadBalancerDeletedTwiceDoesNotError(t *testing.T) {
 	if _, err := test.CreateAndInsertNodes(l.cloud, nodeNames, vals.ZoneName); err != nil {
 		t.Errorf("Unexpected error when adding nodes %v", err)
 	}
-	result := l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -518,20 +519,124 @@ func TestEnsureInternalLoadBalancerDeletedTwiceDoesNotError(t *testing.T) {
 	assertInternalLbResources(t, svc, l, nodeNames, result.Annotations)
 
 	// Delete the loadbalancer
-	result = l.EnsureInternalLoadBalancerDeleted(svc)
+	result = l.EnsureInternalLoadBalancerDeleted(context.TODO(), svc)
 	if result.Error != nil {
 		t.Errorf("Unexpected error %v", result.Error)
 	}
 	assertInternalLbResourcesDeleted(t, svc, true, l)
 
 	// Deleting the loadbalancer and resources again should not cause an error.
-	result = l.EnsureInternalLoadBalancerDeleted(svc)
+	result = l.EnsureInternalLoadBalancerDeleted(context.TODO(), svc)
 	if result.Error != nil {
 		t.Errorf("Unexpected error %v", result.Error)
 	}
 	assertInternalLbResourcesDeleted(t, svc, true, l)
 }
 
+func TestEnsureInternalLoadBalancerSharedHealthCheckFirewall(t *testing.T) {
+	t.Parallel()
+
+	vals := gce.DefaultTestClusterValues()
+	fakeGCE := getFakeGCECloud(vals)
+	nodeNames := []string{"test-node-1"}
+	if _, err := test.CreateAndInsertNodes(fakeGCE, nodeNames, vals.ZoneName); err != nil {
+		t.Errorf("Unexpected error when adding nodes %v", err)
+	}
+	namer := namer_util.NewL4Namer(kubeSystemUID, nil)
+	_, hcFwName := namer.L4HealthCheck("", "", true)
+	// A service synced before a restart of the controller references the rule.
+	hcFirewall := firewalls.NewL4HealthCheckFirewall(fakeGCE, hcFwName, func() (map[string]int32, error) {
+		return map[string]int32{"other/svc": 30001}, nil
+	})
+	newHandler := func(svc *v1.Service) *L4 {
+		l := NewL4Handler(svc, fakeGCE, meta.Regional, namer, record.NewFakeRecorder(100), &sync.Mutex{})
+		l.HealthCheckFirewall = hcFirewall
+		return l
+	}
+	assertPorts := func(want ...string) {
+		t.Helper()
+		fw, err := fakeGCE.GetFirewall(hcFwName)
+		if err != nil {
+			t.Fatalf("GetFirewall(%s) = %v", hcFwName, err)
+		}
+		if got := fw.Allowed[0].Ports; !reflect.DeepEqual(got, want) {
+			t.Errorf("Ports of firewall rule %s = %v, want %v", hcFwName, got, want)
+		}
+	}
+
+	// The Local service has a firewall rule for its health check from before the shared rule.
+	localSvc := test.NewL4ILBService(true, 8080)
+	localSvc.Name = "local"
+	localSvc.Spec.HealthCheckNodePort = 30002
+	_, localHcFwName := namer.L4HealthCheck(localSvc.Namespace, localSvc.Name, false)
+	if err := firewalls.EnsureL4InternalFirewallRule(fakeGCE, localHcFwName, "", utils.ServiceKeyFunc(localSvc.Namespace, localSvc.Name), gce.L4LoadBalancerSrcRanges(), []string{"30002"}, nodeNames, "tcp", false); err != nil {
+		t.Fatalf("EnsureL4InternalFirewallRule(%s) = %v", localHcFwName, err)
+	}
+	localSvc.Annotations[annotations.FirewallRuleForHealthcheckKey] = localHcFwName
+	clusterSvc := test.NewL4ILBService(false, 8081)
+	clusterSvc.Name = "cluster"
+
+	for _, svc := range []*v1.Service{localSvc, clusterSvc} {
+		result := newHandler(svc).EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
+		if result.Error != nil {
+			t.Fatalf("Failed to ensure loadBalancer for %s, err %v", svc.Name, result.Error)
+		}
+		if got := result.Annotations[annotations.FirewallRuleForHealthcheckKey]; got != hcFwName {
+			t.Errorf("Firewall rule for health check of %s = %q, want %q", svc.Name, got, hcFwName)
+		}
+	}
+	assertPorts("10256", "30001", "30002")
+	if _, err := fakeGCE.GetFirewall(localHcFwName); !utils.IsNotFoundError(err) {
+		t.Errorf("GetFirewall(%s) = %v, want not found after migration", localHcFwName, err)
+	}
+
+	// Changes made outside of the controller to fields it does not own are
+	// kept when the rule is widened or narrowed.
+	fw, err := fakeGCE.GetFirewall(hcFwName)
+	if err != nil {
+		t.Fatalf("GetFirewall(%s) = %v", hcFwName, err)
+	}
+	fw.Priority = 900
+	if err := fakeGCE.UpdateFirewall(fw); err != nil {
+		t.Fatalf("UpdateFirewall(%s) = %v", hcFwName, err)
+	}
+	assertPriorityKept := func() {
+		t.Helper()
+		if fw, err := fakeGCE.GetFirewall(hcFwName); err != nil || fw.Priority != 900 {
+			t.Errorf("GetFirewall(%s) = %+v, %v, want Priority 900 to be kept", hcFwName, fw, err)
+		}
+	}
+
+	// The rule is widened as services reference new ports.
+	if err := hcFirewall.Ensure("other/new", 30003, nodeNames); err != nil {
+		t.Fatalf("Ensure(other/new) = %v", err)
+	}
+	assertPorts("10256", "30001", "30002", "30003")
+	assertPriorityKept()
+	if err := hcFirewall.Release("other/new"); err != nil {
+		t.Fatalf("Release(other/new) = %v", err)
+	}
+
+	// The rule is narrowed as services are deleted.
+	if result := newHandler(localSvc).EnsureInternalLoadBalancerDeleted(context.TODO(), localSvc); result.Error != nil {
+		t.Fatalf("Failed to delete loadBalancer for %s, err %v", localSvc.Name, result.Error)
+	}
+	assertPorts("10256", "30001")
+	assertPriorityKept()
+	if result := newHandler(clusterSvc).EnsureInternalLoadBalancerDeleted(context.TODO(), clusterSvc); result.Error != nil {
+		t.Fatalf("Failed to delete loadBalancer for %s, err %v", clusterSvc.Name, result.Error)
+	}
+	assertPorts("30001")
+
+	// The rule is deleted once it is no longer referenced.
+	if err := hcFirewall.Release("other/svc"); err != nil {
+		t.Fatalf("Release(other/svc) = %v", err)
+	}
+	if _, err := fakeGCE.GetFirewall(hcFwName); !utils.IsNotFoundError(err) {
+		t.Errorf("GetFirewall(%s) = %v, want not found", hcFwName, err)
+	}
+}
+
 func TestEnsureInternalLoadBalancerWithSpecialHealthCheck(t *testing.T) {
 	vals := gce.DefaultTestClusterValues()
 	fakeGCE := getFakeGCECloud(vals)
@@ -548,7 +653,7 @@ func TestEnsureInternalLoadBalancerWithSpecialHealthCheck(t *testing.T) {
 	svc.Spec.Type = v1.ServiceTypeLoadBalancer
 	svc.Spec.ExternalTrafficPolicy = v1.ServiceExternalTrafficPolicyTypeLocal
 
-	result := l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -562,7 +667,7 @@ func TestEnsureInternalLoadBalancerWithSpecialHealthCheck(t *testing.T) {
 	if err != nil {
 		t.Errorf("Unexpected error when creating key - %v", err)
 	}
-	hc, err := composite.GetHealthCheck(l.cloud, key, meta.VersionGA)
+	hc, err := composite.GetHealthCheck(context.TODO(), l.cloud, key, meta.VersionGA)
 	if err != nil || hc == nil {
 		t.Errorf("Failed to get healthcheck, err %v", err)
 	}
@@ -654,14 +759,14 @@ func TestEnsureInternalLoadBalancerErrors(t *testing.T) {
 				t.Errorf("Unexpected error when adding nodes %v", err)
 			}
 			// Create a dummy forwarding rule in order to trigger a delete in the EnsureInternalLoadBalancer function.
-			if err = composite.CreateForwardingRule(l.cloud, key, &composite.ForwardingRule{Name: frName}); err != nil {
+			if err = composite.CreateForwardingRule(context.TODO(), l.cloud, key, &composite.ForwardingRule{Name: frName}); err != nil {
 				t.Errorf("Failed to create fake forwarding rule %s, err %v", frName, err)
 			}
 			// Inject error hooks after creating the forwarding rule
 			if tc.injectMock != nil {
 				tc.injectMock(fakeGCE.Compute().(*cloud.MockGCE))
 			}
-			result := l.EnsureInternalLoadBalancer(nodeNames, params.service)
+			result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, params.service)
 			if result.Error == nil {
 				t.Errorf("Expected error when %s", desc)
 			}
@@ -728,7 +833,7 @@ func TestEnsureInternalLoadBalancerEnableGlobalAccess(t *testing.T) {
 		t.Errorf("Unexpected error when adding nodes %v", err)
 	}
 	frName := l.GetFRName()
-	result := l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -739,7 +844,7 @@ func TestEnsureInternalLoadBalancerEnableGlobalAccess(t *testing.T) {
 
 	// Change service to include the global access annotation
 	svc.Annotations[gce.ServiceAnnotationILBAllowGlobalAccess] = "true"
-	result = l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result = l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -755,7 +860,7 @@ func TestEnsureInternalLoadBalancerEnableGlobalAccess(t *testing.T) {
 	if err != nil {
 		t.Errorf("Unexpected error when creating key - %v", err)
 	}
-	fwdRule, err := composite.GetForwardingRule(l.cloud, key, meta.VersionGA)
+	fwdRule, err := composite.GetForwardingRule(context.TODO(), l.cloud, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Unexpected error when looking up forwarding rule - %v", err)
 	}
@@ -767,7 +872,7 @@ func TestEnsureInternalLoadBalancerEnableGlobalAccess(t *testing.T) {
 	}
 	// remove the annotation and disable global access.
 	delete(svc.Annotations, gce.ServiceAnnotationILBAllowGlobalAccess)
-	result = l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result = l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -775,7 +880,7 @@ func TestEnsureInternalLoadBalancerEnableGlobalAccess(t *testing.T) {
 		t.Errorf("Got empty loadBalancer status using handler %v", l)
 	}
 	// make sure GlobalAccess field is off.
-	fwdRule, err = composite.GetForwardingRule(l.cloud, key, meta.VersionGA)
+	fwdRule, err = composite.GetForwardingRule(context.TODO(), l.cloud, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Unexpected error when looking up forwarding rule - %v", err)
 	}
@@ -788,7 +893,7 @@ func TestEnsureInternalLoadBalancerEnableGlobalAccess(t *testing.T) {
 	}
 	assertInternalLbResources(t, svc, l, nodeNames, result.Annotations)
 	// Delete the service
-	result = l.EnsureInternalLoadBalancerDeleted(svc)
+	result = l.EnsureInternalLoadBalancerDeleted(context.TODO(), svc)
 	if result.Error != nil {
 		t.Errorf("Unexpected error %v", err)
 	}
@@ -807,7 +912,7 @@ func TestEnsureInternalLoadBalancerCustomSubnet(t *testing.T) {
 	if _, err := test.CreateAndInsertNodes(l.cloud, nodeNames, vals.ZoneName); err != nil {
 		t.Errorf("Unexpected error when adding nodes %v", err)
 	}
-	result := l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -817,7 +922,7 @@ func TestEnsureInternalLoadBalancerCustomSubnet(t *testing.T) {
 	assertInternalLbResources(t, svc, l, nodeNames, result.Annotations)
 
 	frName := l.GetFRName()
-	fwdRule, err := composite.GetForwardingRule(l.cloud, meta.RegionalKey(frName, l.cloud.Region()), meta.VersionGA)
+	fwdRule, err := composite.GetForwardingRule(context.TODO(), l.cloud, meta.RegionalKey(frName, l.cloud.Region()), meta.VersionGA)
 	if err != nil || fwdRule == nil {
 		t.Errorf("Unexpected error looking up forwarding rule - err %v", err)
 	}
@@ -829,7 +934,7 @@ func TestEnsureInternalLoadBalancerCustomSubnet(t *testing.T) {
 	requestedIP := "4.5.6.7"
 	svc.Annotations[gce.ServiceAnnotationILBSubnet] = "test-subnet"
 	svc.Spec.LoadBalancerIP = requestedIP
-	result = l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result = l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if err != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", err)
 	}
@@ -840,7 +945,7 @@ func TestEnsureInternalLoadBalancerCustomSubnet(t *testing.T) {
 	if result.Status.Ingress[0].IP != requestedIP {
 		t.Fatalf("Reserved IP %s not propagated, Got '%s'", requestedIP, result.Status.Ingress[0].IP)
 	}
-	fwdRule, err = composite.GetForwardingRule(l.cloud, meta.RegionalKey(frName, l.cloud.Region()), meta.VersionGA)
+	fwdRule, err = composite.GetForwardingRule(context.TODO(), l.cloud, meta.RegionalKey(frName, l.cloud.Region()), meta.VersionGA)
 	if err != nil || fwdRule == nil {
 		t.Errorf("Unexpected error looking up forwarding rule - err %v", err)
 	}
@@ -850,7 +955,7 @@ func TestEnsureInternalLoadBalancerCustomSubnet(t *testing.T) {
 
 	// Change to a different subnet
 	svc.Annotations[gce.ServiceAnnotationILBSubnet] = "another-subnet"
-	result = l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result = l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -861,7 +966,7 @@ func TestEnsureInternalLoadBalancerCustomSubnet(t *testing.T) {
 	if result.Status.Ingress[0].IP != requestedIP {
 		t.Errorf("Reserved IP %s not propagated, Got %s", requestedIP, result.Status.Ingress[0].IP)
 	}
-	fwdRule, err = composite.GetForwardingRule(l.cloud, meta.RegionalKey(frName, l.cloud.Region()), meta.VersionGA)
+	fwdRule, err = composite.GetForwardingRule(context.TODO(), l.cloud, meta.RegionalKey(frName, l.cloud.Region()), meta.VersionGA)
 	if err != nil || fwdRule == nil {
 		t.Errorf("Unexpected error looking up forwarding rule - err %v", err)
 	}
@@ -870,7 +975,7 @@ func TestEnsureInternalLoadBalancerCustomSubnet(t *testing.T) {
 	}
 	// remove the annotation - ILB should revert to default subnet.
 	delete(svc.Annotations, gce.ServiceAnnotationILBSubnet)
-	result = l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result = l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -878,7 +983,7 @@ func TestEnsureInternalLoadBalancerCustomSubnet(t *testing.T) {
 	if len(result.Status.Ingress) == 0 {
 		t.Errorf("Got empty loadBalancer status using handler %v", l)
 	}
-	fwdRule, err = composite.GetForwardingRule(l.cloud, meta.RegionalKey(frName, l.cloud.Region()), meta.VersionGA)
+	fwdRule, err = composite.GetForwardingRule(context.TODO(), l.cloud, meta.RegionalKey(frName, l.cloud.Region()), meta.VersionGA)
 	if err != nil || fwdRule == nil {
 		t.Errorf("Unexpected error %v", err)
 	}
@@ -886,7 +991,7 @@ func TestEnsureInternalLoadBalancerCustomSubnet(t *testing.T) {
 		t.Errorf("Unexpected subnet value '%s' in ILB ForwardingRule.", fwdRule.Subnetwork)
 	}
 	// Delete the loadbalancer
-	result = l.EnsureInternalLoadBalancerDeleted(svc)
+	result = l.EnsureInternalLoadBalancerDeleted(context.TODO(), svc)
 	if result.Error != nil {
 		t.Errorf("Unexpected error deleting loadbalancer - err %v", result.Error)
 	}
@@ -978,7 +1083,7 @@ func TestEnsureInternalLoadBalancerModifyProtocol(t *testing.T) {
 	}
 
 	frName := l.getFRNameWithProtocol("TCP")
-	result := l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -990,7 +1095,7 @@ func TestEnsureInternalLoadBalancerModifyProtocol(t *testing.T) {
 	if err != nil {
 		t.Errorf("Unexpected error when creating key - %v", err)
 	}
-	fwdRule, err := composite.GetForwardingRule(l.cloud, key, meta.VersionGA)
+	fwdRule, err := composite.GetForwardingRule(context.TODO(), l.cloud, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Unexpected error when looking up forwarding rule - %v", err)
 	}
@@ -999,7 +1104,7 @@ func TestEnsureInternalLoadBalancerModifyProtocol(t *testing.T) {
 	}
 	// change the protocol to UDP
 	svc.Spec.Ports[0].Protocol = v1.ProtocolUDP
-	result = l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result = l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -1008,7 +1113,7 @@ func TestEnsureInternalLoadBalancerModifyProtocol(t *testing.T) {
 	}
 	assertInternalLbResources(t, svc, l, nodeNames, result.Annotations)
 	// Make sure the old forwarding rule is deleted
-	fwdRule, err = composite.GetForwardingRule(l.cloud, key, meta.VersionGA)
+	fwdRule, err = composite.GetForwardingRule(context.TODO(), l.cloud, key, meta.VersionGA)
 	if !utils.IsNotFoundError(err) {
 		t.Errorf("Failed to delete ForwardingRule %s", frName)
 	}
@@ -1016,7 +1121,7 @@ func TestEnsureInternalLoadBalancerModifyProtocol(t *testing.T) {
 	if key, err = composite.CreateKey(l.cloud, frName, meta.Regional); err != nil {
 		t.Errorf("Unexpected error when creating key - %v", err)
 	}
-	if fwdRule, err = composite.GetForwardingRule(l.cloud, key, meta.VersionGA); err != nil {
+	if fwdRule, err = composite.GetForwardingRule(context.TODO(), l.cloud, key, meta.VersionGA); err != nil {
 		t.Errorf("Unexpected error when looking up forwarding rule - %v", err)
 	}
 	if fwdRule.IPProtocol != "UDP" {
@@ -1024,13 +1129,128 @@ func TestEnsureInternalLoadBalancerModifyProtocol(t *testing.T) {
 	}
 
 	// Delete the service
-	result = l.EnsureInternalLoadBalancerDeleted(svc)
+	result = l.EnsureInternalLoadBalancerDeleted(context.TODO(), svc)
 	if err != nil {
 		t.Errorf("Unexpected error %v", err)
 	}
 	assertInternalLbResourcesDeleted(t, svc, true, l)
 }
 
+func TestEnsureInternalLoadBalancerMixedProtocol(t *testing.T) {
+	defer func(enabled bool) { flags.F.EnableL4ILBMixedProtocol = enabled }(flags.F.EnableL4ILBMixedProtocol)
+	flags.F.EnableL4ILBMixedProtocol = true
+
+	vals := gce.DefaultTestClusterValues()
+	fakeGCE := getFakeGCECloud(vals)
+	nodeNames := []string{"test-node-1"}
+	svc := test.NewL4ILBService(false, 8080)
+	svc.Spec.Ports = append(svc.Spec.Ports, v1.ServicePort{Name: "dns", Port: 53, Protocol: v1.ProtocolUDP})
+	namer := namer_util.NewL4Namer(kubeSystemUID, nil)
+	l := NewL4Handler(svc, fakeGCE, meta.Regional, namer, record.NewFakeRecorder(100), &sync.Mutex{})
+	if _, err := test.CreateAndInsertNodes(l.cloud, nodeNames, vals.ZoneName); err != nil {
+		t.Errorf("Unexpected error when adding nodes %v", err)
+	}
+	name, _ := l.namer.VMIPNEG(svc.Namespace, svc.Name)
+	tcpFRName, udpFRName := l.getFRNameWithProtocol("TCP"), l.getFRNameWithProtocol("UDP")
+	getFR := func(name string) *composite.ForwardingRule {
+		key, err := composite.CreateKey(l.cloud, name, meta.Regional)
+		if err != nil {
+			t.Fatalf("Unexpected error when creating key - %v", err)
+		}
+		fr, err := composite.GetForwardingRule(context.TODO(), l.cloud, key, meta.VersionGA)
+		if utils.IgnoreHTTPNotFound(err) != nil {
+			t.Fatalf("Unexpected error when looking up forwarding rule %s - %v", name, err)
+		}
+		return fr
+	}
+
+	result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
+	if result.Error != nil {
+		t.Fatalf("Failed to ensure loadBalancer, err %v", result.Error)
+	}
+	tcpFR, udpFR := getFR(tcpFRName), getFR(udpFRName)
+	if tcpFR == nil || udpFR == nil {
+		t.Fatalf("Got forwarding rules %v and %v, want both TCP and UDP forwarding rules", tcpFR, udpFR)
+	}
+	if tcpFR.IPProtocol != "TCP" || !reflect.DeepEqual(tcpFR.Ports, []string{"8080"}) {
+		t.Errorf("Got TCP forwarding rule with protocol %s and ports %v, want TCP and [8080]", tcpFR.IPProtocol, tcpFR.Ports)
+	}
+	if udpFR.IPProtocol != "UDP" || !reflect.DeepEqual(udpFR.Ports, []string{"53"}) {
+		t.Errorf("Got UDP forwarding rule with protocol %s and ports %v, want UDP and [53]", udpFR.IPProtocol, udpFR.Ports)
+	}
+	if tcpFR.IPAddress == "" || tcpFR.IPAddress != udpFR.IPAddress {
+		t.Errorf("Got forwarding rule IPs %q and %q, want a shared IP", tcpFR.IPAddress, udpFR.IPAddress)
+	}
+	if tcpFR.BackendService != udpFR.BackendService {
+		t.Errorf("Got forwarding rule backend services %q and %q, want a shared backend service", tcpFR.BackendService, udpFR.BackendService)
+	}
+	if got := result.Status.Ingress; len(got) != 1 || got[0].IP != tcpFR.IPAddress {
+		t.Errorf("Got status %v, want IP %s", got, tcpFR.IPAddress)
+	}
+	if result.Annotations[annotations.TCPForwardingRuleKey] != tcpFRName || result.Annotations[annotations.UDPForwardingRuleKey] != udpFRName {
+		t.Errorf("Got annotations %v, want forwarding rules %s and %s", result.Annotations, tcpFRName, udpFRName)
+	}
+	bs, err := l.backendPool.Get(context.TODO(), name, meta.VersionGA, meta.Regional)
+	if err != nil {
+		t.Fatalf("Unexpected error when looking up backend service - %v", err)
+	}
+	if bs.Protocol != mixedProtocolBackendServiceProtocol {
+		t.Errorf("Got backend service protocol %s, want %s", bs.Protocol, mixedProtocolBackendServiceProtocol)
+	}
+	addr, err := l.cloud.GetRegionAddress(name, l.cloud.Region())
+	if err != nil {
+		t.Fatalf("Unexpected error when looking up shared address - %v", err)
+	}
+	if addr.Address != tcpFR.IPAddress || addr.Purpose != sharedVIPPurpose {
+		t.Errorf("Got shared address with IP %s and purpose %s, want %s and %s", addr.Address, addr.Purpose, tcpFR.IPAddress, sharedVIPPurpose)
+	}
+	fw, err := l.cloud.GetFirewall(name)
+	if err != nil {
+		t.Fatalf("Unexpected error when looking up firewall rule - %v", err)
+	}
+	var gotAllowed []string
+	for _, allowed := range fw.Allowed {
+		gotAllowed = append(gotAllowed, allowed.IPProtocol+":"+strings.Join(allowed.Ports, ","))
+	}
+	if want := []string{"tcp:8080", "udp:53"}; !reflect.DeepEqual(gotAllowed, want) {
+		t.Errorf("Got firewall rule allowing %v, want %v", gotAllowed, want)
+	}
+
+	// Remove the UDP port, the UDP forwarding rule and the shared address are deleted.
+	svc.Spec.Ports = svc.Spec.Ports[:1]
+	result = l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
+	if result.Error != nil {
+		t.Fatalf("Failed to ensure loadBalancer, err %v", result.Error)
+	}
+	assertInternalLbResources(t, svc, l, nodeNames, result.Annotations)
+	if fr := getFR(udpFRName); fr != nil {
+		t.Errorf("Forwarding rule %s was not deleted", udpFRName)
+	}
+	if fr := getFR(tcpFRName); fr == nil || fr.IPAddress != tcpFR.IPAddress {
+		t.Errorf("Got TCP forwarding rule %v, want IP %s", fr, tcpFR.IPAddress)
+	}
+	if _, err := l.cloud.GetRegionAddress(name, l.cloud.Region()); !utils.IsNotFoundError(err) {
+		t.Errorf("Shared address %s was not deleted, err %v", name, err)
+	}
+
+	// Add the UDP port back and delete the service. Both forwarding rules are
+	// deleted even once mixed protocol support was disabled.
+	svc.Spec.Ports = append(svc.Spec.Ports, v1.ServicePort{Name: "dns", Port: 53, Protocol: v1.ProtocolUDP})
+	if result = l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc); result.Error != nil {
+		t.Fatalf("Failed to ensure loadBalancer, err %v", result.Error)
+	}
+	flags.F.EnableL4ILBMixedProtocol = false
+	if result = l.EnsureInternalLoadBalancerDeleted(context.TODO(), svc); result.Error != nil {
+		t.Fatalf("Failed to delete loadBalancer, err %v", result.Error)
+	}
+	assertInternalLbResourcesDeleted(t, svc, true, l)
+	for _, frName := range []string{tcpFRName, udpFRName} {
+		if fr := getFR(frName); fr != nil {
+			t.Errorf("Forwarding rule %s was not deleted", frName)
+		}
+	}
+}
+
 func TestEnsureInternalLoadBalancerAllPorts(t *testing.T) {
 	t.Parallel()
 
@@ -1043,7 +1263,7 @@ func TestEnsureInternalLoadBalancerAllPorts(t *testing.T) {
 	if _, err := test.CreateAndInsertNodes(l.cloud, nodeNames, vals.ZoneName); err != nil {
 		t.Errorf("Unexpected error when adding nodes %v", err)
 	}
-	result := l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result := l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -1056,7 +1276,7 @@ func TestEnsureInternalLoadBalancerAllPorts(t *testing.T) {
 	if err != nil {
 		t.Errorf("Unexpected error when creating key - %v", err)
 	}
-	fwdRule, err := composite.GetForwardingRule(l.cloud, key, meta.VersionGA)
+	fwdRule, err := composite.GetForwardingRule(context.TODO(), l.cloud, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Unexpected error when looking up forwarding rule - %v", err)
 	}
@@ -1072,7 +1292,7 @@ func TestEnsureInternalLoadBalancerAllPorts(t *testing.T) {
 		{Name: "testport", Port: int32(8300), Protocol: "TCP"},
 		{Name: "testport", Port: int32(8400), Protocol: "TCP"},
 	}
-	result = l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result = l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -1080,7 +1300,7 @@ func TestEnsureInternalLoadBalancerAllPorts(t *testing.T) {
 		t.Errorf("Got empty loadBalancer status using handler %v", l)
 	}
 	assertInternalLbResources(t, svc, l, nodeNames, result.Annotations)
-	fwdRule, err = composite.GetForwardingRule(l.cloud, key, meta.VersionGA)
+	fwdRule, err = composite.GetForwardingRule(context.TODO(), l.cloud, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Unexpected error when looking up forwarding rule - %v", err)
 	}
@@ -1098,7 +1318,7 @@ func TestEnsureInternalLoadBalancerAllPorts(t *testing.T) {
 		{Name: "testport", Port: int32(8400), Protocol: "TCP"},
 	}
 	expectPorts := []string{"8090", "8100", "8300", "8400"}
-	result = l.EnsureInternalLoadBalancer(nodeNames, svc)
+	result = l.EnsureInternalLoadBalancer(context.TODO(), nodeNames, svc)
 	if result.Error != nil {
 		t.Errorf("Failed to ensure loadBalancer, err %v", result.Error)
 	}
@@ -1106,7 +1326,7 @@ func TestEnsureInternalLoadBalancerAllPorts(t *testing.T) {
 		t.Errorf("Got empty loadBalancer status using handler %v", l)
 	}
 	assertInternalLbResources(t, svc, l, nodeNames, result.Annotations)
-	fwdRule, err = composite.GetForwardingRule(l.cloud, key, meta.VersionGA)
+	fwdRule, err = composite.GetForwardingRule(context.TODO(), l.cloud, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Unexpected error when looking up forwarding rule - %v", err)
 	}
@@ -1117,7 +1337,7 @@ func TestEnsureInternalLoadBalancerAllPorts(t *testing.T) {
 		t.Errorf("Expected AllPorts field to be unset in forwarding rule - %+v", fwdRule)
 	}
 	// Delete the service
-	result = l.EnsureInternalLoadBalancerDeleted(svc)
+	result = l.EnsureInternalLoadBalancerDeleted(context.TODO(), svc)
 	if result.Error != nil {
 		t.Errorf("Unexpected error %v", result.Error)
 	}
@@ -1174,7 +1394,7 @@ func assertInternalLbResources(t *testing.T, apiService *v1.Service, l *L4, node
 	}
 
 	// Check that HealthCheck is created
-	healthcheck, err := composite.GetHealthCheck(l.cloud, meta.GlobalKey(hcName), meta.VersionGA)
+	healthcheck, err := composite.GetHealthCheck(context.TODO(), l.cloud, meta.GlobalKey(hcName), meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to fetch healthcheck %s - err %v", hcName, err)
 	}
@@ -1191,7 +1411,7 @@ func assertInternalLbResources(t *testing.T, apiService *v1.Service, l *L4, node
 	backendServiceName := resourceName
 	key := meta.RegionalKey(backendServiceName, l.cloud.Region())
 	backendServiceLink := cloud.SelfLink(meta.VersionGA, l.cloud.ProjectID(), "backendServices", key)
-	bs, err := composite.GetBackendService(l.cloud, key, meta.VersionGA)
+	bs, err := composite.GetBackendService(context.TODO(), l.cloud, key, meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to fetch backend service %s - err %v", backendServiceName, err)
 	}
@@ -1211,7 +1431,7 @@ func assertInternalLbResources(t *testing.T, apiService *v1.Service, l *L4, node
 	expectedAnnotations[annotations.BackendServiceKey] = backendServiceName
 	// Check that ForwardingRule is created
 	frName := l.GetFRName()
-	fwdRule, err := composite.GetForwardingRule(l.cloud, meta.RegionalKey(frName, l.cloud.Region()), meta.VersionGA)
+	fwdRule, err := composite.GetForwardingRule(context.TODO(), l.cloud, meta.RegionalKey(frName, l.cloud.Region()), meta.VersionGA)
 	if err != nil {
 		t.Errorf("Failed to fetch forwarding rule %s - err %v", frName, err)
 
@@ -17,6 +17,7 @@ limitations under the License.
 package loadbalancers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -25,6 +26,7 @@ import (
 	"k8s.io/ingress-gce/pkg/translator"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/compute/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -63,10 +65,20 @@ type L7RuntimeInfo struct {
 	StaticIPName string
 	// The name of the static IP subnet, this is only used for L7-ILB Ingress static IPs
 	StaticIPSubnet string
+	// ManagedStaticIPSubnet is the name of the subnet in which the internal
+	// static IP of an L7-ILB Ingress is reserved. It is only used if
+	// StaticIPName is empty.
+	ManagedStaticIPSubnet string
+	// IPVersion is the IP version of the forwarding rules, either IPV4 or
+	// IPV6. IPv4 is used if empty.
+	IPVersion string
 	// UrlMap is our internal representation of a url map.
 	UrlMap *utils.GCEURLMap
 	// FrontendConfig is the type which encapsulates features for the load balancer.
 	FrontendConfig *frontendconfigv1beta1.FrontendConfig
+	// SyncID identifies the controller sync which ensures the load balancer.
+	// It is recorded in the logs, events and resource descriptions of the sync.
+	SyncID string
 }
 
 // L7 represents a single L7 loadbalancer.
@@ -77,6 +89,13 @@ type L7 struct {
 	ingress v1.Ingress
 	// cloud is an interface to manage loadbalancers in the GCE cloud.
 	cloud *gce.Cloud
+	// compositeCloud manages the composite resources of the loadbalancer in
+	// cloud.
+	compositeCloud composite.Cloud
+	// backendProjectID is the project of the backend services. It differs
+	// from the project of cloud if the Ingress namespace is mapped to another
+	// project, the backend services are then referenced across projects.
+	backendProjectID string
 	// um is the UrlMap associated with this L7.
 	um *composite.UrlMap
 	// rum is the Http Redirect only UrlMap associated with this L7.
@@ -85,9 +104,19 @@ type L7 struct {
 	tp *composite.TargetHttpProxy
 	// tps is the TargetHTTPSProxy associated with this L7.
 	tps *composite.TargetHttpsProxy
+	// sslProxy is the TargetSslProxy associated with this L7. It is only set
+	// for gce-ssl-proxy Ingresses.
+	sslProxy *compute.TargetSslProxy
+	// sslProxies manages target ssl proxies.
+	sslProxies TargetSslProxies
+	// urlMapValidator validates url maps before they are swapped.
+	urlMapValidator UrlMapValidator
+	// sharedSslCerts gets the pre-shared certificates of other projects.
+	sharedSslCerts SharedSslCertificates
 	// fw is the GlobalForwardingRule that points to the TargetHTTPProxy.
 	fw *composite.ForwardingRule
-	// fws is the GlobalForwardingRule that points to the TargetHTTPSProxy.
+	// fws is the GlobalForwardingRule that points to the TargetHTTPSProxy,
+	// or to the TargetSslProxy for gce-ssl-proxy Ingresses.
 	fws *composite.ForwardingRule
 	// ip is the static-ip associated with both ForwardingRules.
 	ip *composite.Address
@@ -106,10 +135,14 @@ type L7 struct {
 	scope meta.KeyType
 }
 
-// String returns the name of the loadbalancer.
+// String returns the name of the loadbalancer, and the ID of the sync which
+// ensures it if any.
 // Warning: This should be used only for logging and should not be used to
 // retrieve/ delete gce resource names.
 func (l *L7) String() string {
+	if l.runtimeInfo != nil && l.runtimeInfo.SyncID != "" {
+		return fmt.Sprintf("%s (sync %s)", l.namer.LoadBalancer(), l.runtimeInfo.SyncID)
+	}
 	return l.namer.LoadBalancer().String()
 }
 
@@ -120,7 +153,20 @@ func (l *L7) Versions() *features.ResourceVersions {
 
 // CreateKey creates a meta.Key for use with composite types
 func (l *L7) CreateKey(name string) (*meta.Key, error) {
-	return composite.CreateKey(l.cloud, name, l.scope)
+	return l.compositeCloud.CreateKey(name, l.scope)
+}
+
+// backendProject returns the project of the backend services.
+func (l *L7) backendProject() string {
+	if l.backendProjectID == "" {
+		return l.cloud.ProjectID()
+	}
+	return l.backendProjectID
+}
+
+// isIPv6 returns true if the frontend of the l7 is IPv6 only.
+func (l *L7) isIPv6() bool {
+	return l.runtimeInfo != nil && l.runtimeInfo.IPVersion == annotations.IPv6Version
 }
 
 // Regional returns true if the l7 scope is regional
@@ -138,7 +184,14 @@ func (l *L7) UrlMap() *composite.UrlMap {
 	return l.um
 }
 
-func (l *L7) edgeHop() error {
+func (l *L7) edgeHop(ctx context.Context) error {
+	if l.isIPv6() && l.Regional() {
+		return fmt.Errorf("IPv6 is only supported by global external Ingresses")
+	}
+	if utils.IsGCESSLProxyIngress(l.runtimeInfo.Ingress) {
+		return l.edgeHopSslProxy(ctx)
+	}
+
 	sslConfigured := l.runtimeInfo.TLS != nil || l.runtimeInfo.TLSName != ""
 	// Return an error if user configuration species that both HTTP & HTTPS are not to be configured.
 	if !l.runtimeInfo.AllowHTTP && !sslConfigured {
@@ -151,67 +204,93 @@ func (l *L7) edgeHop() error {
 		return fmt.Errorf("error invalid internal ingress https config")
 	}
 
-	if err := l.ensureComputeURLMap(); err != nil {
+	if l.Regional() && l.runtimeInfo.StaticIPName == "" && l.runtimeInfo.ManagedStaticIPSubnet != "" {
+		if err := l.ensureInternalStaticIP(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := l.ensureComputeURLMap(ctx); err != nil {
 		return err
 	}
 
 	if flags.F.EnableFrontendConfig {
-		if err := l.ensureRedirectURLMap(); err != nil {
+		if err := l.ensureRedirectURLMap(ctx); err != nil {
 			return fmt.Errorf("ensureRedirectUrlMap() = %v", err)
 		}
 	}
 
 	if l.runtimeInfo.AllowHTTP {
-		if err := l.edgeHopHttp(); err != nil {
+		if sslConfigured {
+			if err := l.promoteHttpsIP(ctx); err != nil {
+				return err
+			}
+		}
+		if err := l.edgeHopHttp(ctx); err != nil {
 			return err
 		}
-	} else if flags.F.EnableDeleteUnusedFrontends && requireDeleteFrontend(l.ingress, namer.HTTPProtocol) {
-		if err := l.deleteHttp(features.VersionsFromIngress(&l.ingress)); err != nil {
+	} else if flags.F.EnableDeleteUnusedFrontends {
+		// Unused HTTP frontend resources are not kept for an HTTPS-only
+		// Ingress, as they would keep serving port 80.
+		deleteHttp, err := l.requireDeleteHttp(ctx)
+		if err != nil {
 			return err
 		}
-		klog.V(2).Infof("Successfully deleted unused HTTP frontend resources for load-balancer %s", l)
+		if deleteHttp {
+			if err := l.deleteHttp(ctx, features.VersionsFromIngress(&l.ingress)); err != nil {
+				return err
+			}
+			klog.V(2).Infof("Successfully deleted unused HTTP frontend resources for load-balancer %s", l)
+		}
 	}
 	// Defer promoting an ephemeral to a static IP until it's really needed.
 	if l.runtimeInfo.AllowHTTP && sslConfigured {
 		klog.V(3).Infof("checking static ip for %v", l)
-		if err := l.checkStaticIP(); err != nil {
+		if err := l.checkStaticIP(ctx); err != nil {
 			return err
 		}
 	}
 	if sslConfigured {
 		klog.V(3).Infof("validating https for %v", l)
-		if err := l.edgeHopHttps(); err != nil {
+		if err := l.edgeHopHttps(ctx); err != nil {
 			return err
 		}
+		// The Ingress might have switched from the gce-ssl-proxy class.
+		if flags.F.EnableDeleteUnusedFrontends {
+			if err := l.deleteTargetSslProxy(ctx); err != nil {
+				return err
+			}
+		}
 	} else if flags.F.EnableDeleteUnusedFrontends && requireDeleteFrontend(l.ingress, namer.HTTPSProtocol) {
-		if err := l.deleteHttps(features.VersionsFromIngress(&l.ingress)); err != nil {
+		if err := l.deleteHttps(ctx, features.VersionsFromIngress(&l.ingress)); err != nil {
 			return err
 		}
 		klog.V(2).Infof("Successfully deleted unused HTTPS frontend resources for load-balancer %s", l)
 	}
-	return nil
+	// The target proxies no longer use the url map replaced by a swap.
+	return l.deleteSwappedURLMap(ctx)
 }
 
-func (l *L7) edgeHopHttp() error {
-	if err := l.checkProxy(); err != nil {
+func (l *L7) edgeHopHttp(ctx context.Context) error {
+	if err := l.checkProxy(ctx); err != nil {
 		return err
 	}
-	if err := l.checkHttpForwardingRule(); err != nil {
+	if err := l.checkHttpForwardingRule(ctx); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (l *L7) edgeHopHttps() error {
-	defer l.deleteOldSSLCerts()
-	if err := l.checkSSLCert(); err != nil {
+func (l *L7) edgeHopHttps(ctx context.Context) error {
+	defer l.deleteOldSSLCerts(ctx)
+	if err := l.checkSSLCert(ctx); err != nil {
 		return err
 	}
 
-	if err := l.checkHttpsProxy(); err != nil {
+	if err := l.checkHttpsProxy(ctx); err != nil {
 		return err
 	}
-	return l.checkHttpsForwardingRule()
+	return l.checkHttpsForwardingRule(ctx)
 }
 
 // requireDeleteFrontend returns true if gce loadbalancer resources needs to deleted for given protocol.
@@ -240,6 +319,17 @@ func requireDeleteFrontend(ing v1.Ingress, protocol namer.NamerProtocol) bool {
 	return false
 }
 
+// requireDeleteHttp returns true if HTTP frontend resources exist according to
+// the Ingress annotations or GCE, so that they are deleted even if the
+// annotations are stale.
+func (l *L7) requireDeleteHttp(ctx context.Context) (bool, error) {
+	if requireDeleteFrontend(l.ingress, namer.HTTPProtocol) {
+		return true, nil
+	}
+	fw, err := l.getForwardingRule(ctx, namer.HTTPProtocol)
+	return fw != nil, err
+}
+
 // GetIP returns the ip associated with the forwarding rule for this l7.
 func (l *L7) GetIP() string {
 	if l.fw != nil {
@@ -251,22 +341,28 @@ func (l *L7) GetIP() string {
 	return ""
 }
 
+// ServesHTTP returns true if the l7 has a HTTP forwarding rule, i.e. if the
+// IP returned by GetIP serves HTTP rather than HTTPS.
+func (l *L7) ServesHTTP() bool {
+	return l.fw != nil
+}
+
 // deleteForwardingRule deletes forwarding rule for given protocol.
-func (l *L7) deleteForwardingRule(versions *features.ResourceVersions, protocol namer.NamerProtocol) error {
+func (l *L7) deleteForwardingRule(ctx context.Context, versions *features.ResourceVersions, protocol namer.NamerProtocol) error {
 	frName := l.namer.ForwardingRule(protocol)
 	klog.V(2).Infof("Deleting forwarding rule %v", frName)
 	key, err := l.CreateKey(frName)
 	if err != nil {
 		return err
 	}
-	if err := utils.IgnoreHTTPNotFound(composite.DeleteForwardingRule(l.cloud, key, versions.ForwardingRule)); err != nil {
+	if err := utils.IgnoreHTTPNotFound(l.compositeCloud.DeleteForwardingRule(ctx, key, versions.ForwardingRule)); err != nil {
 		return err
 	}
 	return nil
 }
 
 // deleteTargetProxy deletes target proxy for given protocol.
-func (l *L7) deleteTargetProxy(versions *features.ResourceVersions, protocol namer.NamerProtocol) error {
+func (l *L7) deleteTargetProxy(ctx context.Context, versions *features.ResourceVersions, protocol namer.NamerProtocol) error {
 	tpName := l.namer.TargetProxy(protocol)
 	klog.V(2).Infof("Deleting target %v proxy %v", protocol, tpName)
 	key, err := l.CreateKey(tpName)
@@ -275,11 +371,11 @@ func (l *L7) deleteTargetProxy(versions *features.ResourceVersions, protocol nam
 	}
 	switch protocol {
 	case namer.HTTPProtocol:
-		if err := utils.IgnoreHTTPNotFound(composite.DeleteTargetHttpProxy(l.cloud, key, versions.TargetHttpProxy)); err != nil {
+		if err := utils.IgnoreHTTPNotFound(l.compositeCloud.DeleteTargetHttpProxy(ctx, key, versions.TargetHttpProxy)); err != nil {
 			return err
 		}
 	case namer.HTTPSProtocol:
-		if err := utils.IgnoreHTTPNotFound(composite.DeleteTargetHttpsProxy(l.cloud, key, versions.TargetHttpsProxy)); err != nil {
+		if err := utils.IgnoreHTTPNotFound(l.compositeCloud.DeleteTargetHttpsProxy(ctx, key, versions.TargetHttpsProxy)); err != nil {
 			return err
 		}
 	default:
@@ -289,40 +385,44 @@ func (l *L7) deleteTargetProxy(versions *features.ResourceVersions, protocol nam
 }
 
 // deleteHttp deletes http forwarding rule and target http proxy.
-func (l *L7) deleteHttp(versions *features.ResourceVersions) error {
+func (l *L7) deleteHttp(ctx context.Context, versions *features.ResourceVersions) error {
 	// Delete http forwarding rule.
-	if err := l.deleteForwardingRule(versions, namer.HTTPProtocol); err != nil {
+	if err := l.deleteForwardingRule(ctx, versions, namer.HTTPProtocol); err != nil {
 		return err
 	}
 	// Delete target http proxy.
-	return l.deleteTargetProxy(versions, namer.HTTPProtocol)
+	return l.deleteTargetProxy(ctx, versions, namer.HTTPProtocol)
 }
 
 // deleteHttps deletes https forwarding rule, target https proxy and ingress controller
 // managed ssl certificates.
-func (l *L7) deleteHttps(versions *features.ResourceVersions) error {
+func (l *L7) deleteHttps(ctx context.Context, versions *features.ResourceVersions) error {
 	// Delete https forwarding rule.
-	if err := l.deleteForwardingRule(versions, namer.HTTPSProtocol); err != nil {
+	if err := l.deleteForwardingRule(ctx, versions, namer.HTTPSProtocol); err != nil {
 		return err
 	}
 	// Get list of ssl certificates owned by this load-balancer that needs to be deleted.
 	// We are using https target proxy to list legacy certs, so this list needs to be
 	// populated before deleting https target proxy.
-	secretsSslCerts, err := l.getIngressManagedSslCerts()
+	secretsSslCerts, err := l.getIngressManagedSslCerts(ctx)
 	if err != nil {
 		return err
 	}
 	// Delete target https proxy.
-	if err := l.deleteTargetProxy(versions, namer.HTTPSProtocol); err != nil {
+	if err := l.deleteTargetProxy(ctx, versions, namer.HTTPSProtocol); err != nil {
+		return err
+	}
+	// Delete target ssl proxy, which shares the ssl certificates.
+	if err := l.deleteTargetSslProxy(ctx); err != nil {
 		return err
 	}
 	// Delete ingress managed ssl certificates those created from a secret,
 	// not referencing a pre-created GCE cert or managed certificates.
-	return l.deleteSSLCertificates(secretsSslCerts, versions)
+	return l.deleteSSLCertificates(ctx, secretsSslCerts, versions)
 }
 
 // deleteSSLCertificates deletes given ssl certificates.
-func (l *L7) deleteSSLCertificates(sslCertificates []*composite.SslCertificate, versions *features.ResourceVersions) error {
+func (l *L7) deleteSSLCertificates(ctx context.Context, sslCertificates []*composite.SslCertificate, versions *features.ResourceVersions) error {
 	if len(sslCertificates) == 0 {
 		return nil
 	}
@@ -333,7 +433,7 @@ func (l *L7) deleteSSLCertificates(sslCertificates []*composite.SslCertificate,
 		if err != nil {
 			return err
 		}
-		if err := utils.IgnoreHTTPNotFound(composite.DeleteSslCertificate(l.cloud, key, versions.SslCertificate)); err != nil {
+		if err := utils.IgnoreHTTPNotFound(l.compositeCloud.DeleteSslCertificate(ctx, key, versions.SslCertificate)); err != nil {
 			klog.Errorf("Old cert delete failed - %v", err)
 			certErr = err
 		}
@@ -343,12 +443,16 @@ func (l *L7) deleteSSLCertificates(sslCertificates []*composite.SslCertificate,
 }
 
 // deleteStaticIP deletes ingress managed static ip.
-func (l *L7) deleteStaticIP() error {
+func (l *L7) deleteStaticIP(ctx context.Context) error {
 	frName := l.namer.ForwardingRule(namer.HTTPProtocol)
-	ip, err := l.cloud.GetGlobalAddress(frName)
+	key, err := l.CreateKey(frName)
+	if err != nil {
+		return err
+	}
+	ip, err := l.compositeCloud.GetAddress(ctx, key, meta.VersionGA)
 	if ip != nil && utils.IgnoreHTTPNotFound(err) == nil {
 		klog.V(2).Infof("Deleting static IP %v(%v)", ip.Name, ip.Address)
-		if err := utils.IgnoreHTTPNotFound(l.cloud.DeleteGlobalAddress(ip.Name)); err != nil {
+		if err := utils.IgnoreHTTPNotFound(l.compositeCloud.DeleteAddress(ctx, key, meta.VersionGA)); err != nil {
 			return err
 		}
 	}
@@ -358,18 +462,18 @@ func (l *L7) deleteStaticIP() error {
 // Cleanup deletes resources specific to this l7 in the right order.
 // forwarding rule -> target proxy -> url map
 // This leaves backends and health checks, which are shared across loadbalancers.
-func (l *L7) Cleanup(versions *features.ResourceVersions) error {
+func (l *L7) Cleanup(ctx context.Context, versions *features.ResourceVersions) error {
 	var err error
 	// Delete http frontend resources.
-	if err := l.deleteHttp(versions); err != nil {
+	if err := l.deleteHttp(ctx, versions); err != nil {
 		return err
 	}
-	// Delete static ip.
-	if err := l.deleteStaticIP(); err != nil {
+	// Delete https frontend resources.
+	if err := l.deleteHttps(ctx, versions); err != nil {
 		return err
 	}
-	// Delete https frontend resources.
-	if err := l.deleteHttps(versions); err != nil {
+	// Delete static ip once no forwarding rule uses it.
+	if err := l.deleteStaticIP(ctx); err != nil {
 		return err
 	}
 	// Delete URL map.
@@ -379,9 +483,20 @@ func (l *L7) Cleanup(versions *features.ResourceVersions) error {
 	if err != nil {
 		return err
 	}
-	if err := utils.IgnoreHTTPNotFound(composite.DeleteUrlMap(l.cloud, key, versions.UrlMap)); err != nil {
+	if err := utils.IgnoreHTTPNotFound(l.compositeCloud.DeleteUrlMap(ctx, key, versions.UrlMap)); err != nil {
 		return err
 	}
+	// Delete the swapped URL map if exists.
+	if umName, supported := l.namer.SwapUrlMap(); supported {
+		klog.V(2).Infof("Deleting Swap URL Map %v", umName)
+		key, err := l.CreateKey(umName)
+		if err != nil {
+			return err
+		}
+		if err := utils.IgnoreHTTPNotFound(l.compositeCloud.DeleteUrlMap(ctx, key, versions.UrlMap)); err != nil {
+			return err
+		}
+	}
 
 	// Delete RedirectUrlMap if exists
 	if flags.F.EnableFrontendConfig {
@@ -395,7 +510,7 @@ func (l *L7) Cleanup(versions *features.ResourceVersions) error {
 		if err != nil {
 			return err
 		}
-		if err := utils.IgnoreHTTPNotFound(composite.DeleteUrlMap(l.cloud, key, versions.UrlMap)); err != nil {
+		if err := utils.IgnoreHTTPNotFound(l.compositeCloud.DeleteUrlMap(ctx, key, versions.UrlMap)); err != nil {
 			return err
 		}
 	}
@@ -412,7 +527,12 @@ func (l *L7) getFrontendAnnotations(existing map[string]string) map[string]strin
 		certs = append(certs, cert.Name)
 	}
 
-	existing[annotations.UrlMapKey] = l.um.Name
+	// Ingresses of the gce-ssl-proxy class do not have a url map.
+	if l.um != nil {
+		existing[annotations.UrlMapKey] = l.um.Name
+	} else {
+		delete(existing, annotations.UrlMapKey)
+	}
 	// Forwarding rule and target proxy might not exist if allowHTTP == false
 	if l.fw != nil {
 		existing[annotations.HttpForwardingRuleKey] = l.fw.Name
@@ -435,6 +555,18 @@ func (l *L7) getFrontendAnnotations(existing map[string]string) map[string]strin
 	} else {
 		delete(existing, annotations.TargetHttpsProxyKey)
 	}
+	// The SSL policy is the one applied to the target https proxy.
+	delete(existing, annotations.SslPolicyKey)
+	if l.tps != nil && l.tps.SslPolicy != "" {
+		if policy, err := utils.ResourcePath(l.tps.SslPolicy); err == nil {
+			existing[annotations.SslPolicyKey] = policy
+		}
+	}
+	if l.sslProxy != nil {
+		existing[annotations.TargetSslProxyKey] = l.sslProxy.Name
+	} else {
+		delete(existing, annotations.TargetSslProxyKey)
+	}
 
 	// Handle Https Redirect Map
 	if flags.F.EnableFrontendConfig {
@@ -460,15 +592,21 @@ func (l *L7) getFrontendAnnotations(existing map[string]string) map[string]strin
 }
 
 // GetLBAnnotations returns the annotations of an l7. This includes it's current status.
-func GetLBAnnotations(l7 *L7, existing map[string]string, backendSyncer backends.Syncer) (map[string]string, error) {
-	backends, err := getBackendNames(l7.um)
+func GetLBAnnotations(ctx context.Context, l7 *L7, existing map[string]string, backendSyncer backends.Syncer) (map[string]string, error) {
+	var backends []string
+	var err error
+	if l7.sslProxy != nil {
+		backends, err = getSslProxyBackendNames(l7.sslProxy)
+	} else {
+		backends, err = getBackendNames(l7.um)
+	}
 	if err != nil {
 		return nil, err
 	}
 	backendState := map[string]string{}
 	for _, beName := range backends {
 		version := l7.Versions().BackendService
-		state, err := backendSyncer.Status(beName, version, l7.scope)
+		state, err := backendSyncer.Status(ctx, beName, version, l7.scope)
 		// Don't return error here since we want to keep syncing
 		if err != nil {
 			klog.Errorf("Error syncing backend status for %s - %s - %s: %v", beName, version, l7.scope, err)
@@ -497,7 +635,9 @@ func GCEResourceName(ingAnnotations map[string]string, resourceName string) stri
 	return resourceName
 }
 
-// description gets a description for the ingress GCP resources.
+// description gets a description for the ingress GCP resources. It records
+// the sync which created or updated the resource. Backend services are shared
+// between Ingresses and do not record the sync.
 func (l *L7) description() (string, error) {
 	if l.runtimeInfo.Ingress == nil {
 		return "", fmt.Errorf("missing Ingress object to construct description for %s", l)
@@ -507,5 +647,8 @@ func (l *L7) description() (string, error) {
 	ingressName := l.runtimeInfo.Ingress.ObjectMeta.Name
 	namespacedName := types.NamespacedName{Name: ingressName, Namespace: namespace}
 
+	if l.runtimeInfo.SyncID != "" {
+		return fmt.Sprintf(`{"kubernetes.io/ingress-name": %q, "kubernetes.io/sync-id": %q}`, namespacedName.String(), l.runtimeInfo.SyncID), nil
+	}
 	return fmt.Sprintf(`{"kubernetes.io/ingress-name": %q}`, namespacedName.String()), nil
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package loadbalancers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -26,6 +27,7 @@ import (
 	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/events"
 	"k8s.io/ingress-gce/pkg/loadbalancers/features"
+	"k8s.io/ingress-gce/pkg/multiproject"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/ingress-gce/pkg/utils/common"
 	namer_util "k8s.io/ingress-gce/pkg/utils/namer"
@@ -35,35 +37,101 @@ import (
 
 // L7s implements LoadBalancerPool.
 type L7s struct {
+	// cloud is the cloud of the cluster project, which hosts the backends.
 	cloud *gce.Cloud
+	// compositeCloud is the composite client of cloud.
+	compositeCloud composite.Cloud
+	// projects routes the frontend of an Ingress to the project mapped to
+	// its namespace.
+	projects *multiproject.Router
 	// v1NamerHelper is an interface for helper functions for v1 frontend naming scheme.
 	v1NamerHelper    namer_util.V1FrontendNamer
 	recorderProducer events.RecorderProducer
 	// namerFactory creates frontend naming policy for ingress/ load balancer.
 	namerFactory namer_util.IngressFrontendNamerFactory
+	// sslProxies manages the target ssl proxies of gce-ssl-proxy Ingresses.
+	sslProxies TargetSslProxies
+	// urlMapValidator validates the url maps of Ingresses which swap url maps.
+	urlMapValidator UrlMapValidator
+	// sharedSslCerts gets the pre-shared certificates of other projects.
+	sharedSslCerts SharedSslCertificates
 }
 
 // NewLoadBalancerPool returns a new loadbalancer pool.
-// - cloud: implements LoadBalancers. Used to sync L7 loadbalancer resources
-//	 with the cloud.
-func NewLoadBalancerPool(cloud *gce.Cloud, v1NamerHelper namer_util.V1FrontendNamer, recorderProducer events.RecorderProducer, namerFactory namer_util.IngressFrontendNamerFactory) LoadBalancerPool {
+//   - projects: routes Ingresses to the cloud used to sync their L7
+//     loadbalancer resources.
+func NewLoadBalancerPool(projects *multiproject.Router, v1NamerHelper namer_util.V1FrontendNamer, recorderProducer events.RecorderProducer, namerFactory namer_util.IngressFrontendNamerFactory) LoadBalancerPool {
+	cloud := projects.Default()
+	compositeCloud := composite.NewCloud(cloud)
 	return &L7s{
 		cloud:            cloud,
+		compositeCloud:   compositeCloud,
+		projects:         projects,
 		v1NamerHelper:    v1NamerHelper,
 		recorderProducer: recorderProducer,
 		namerFactory:     namerFactory,
+		sslProxies:       NewTargetSslProxies(cloud),
+		urlMapValidator:  NewUrlMapValidator(),
+		sharedSslCerts:   NewSharedSslCertificates(compositeCloud),
 	}
 }
 
+// cloudForIngress returns the cloud which hosts the frontend of the Ingress.
+// A nil Ingress maps to the cluster project.
+func (l *L7s) cloudForIngress(ing *v1.Ingress) *gce.Cloud {
+	if l.projects == nil {
+		return l.cloud
+	}
+	return l.projects.CloudForIngress(ing)
+}
+
+// compositeForCloud returns the composite client of the given cloud.
+func (l *L7s) compositeForCloud(cloud *gce.Cloud) composite.Cloud {
+	if cloud == l.cloud {
+		return l.compositeCloud
+	}
+	return composite.NewCloud(cloud)
+}
+
+// sslProxiesForCloud returns the target ssl proxy client for the given cloud.
+func (l *L7s) sslProxiesForCloud(cloud *gce.Cloud) TargetSslProxies {
+	if cloud == l.cloud {
+		return l.sslProxies
+	}
+	return NewTargetSslProxies(cloud)
+}
+
+// urlMapNames returns the names of the url maps of a load balancer. The url
+// map alternates between these names if the Ingress swaps url maps.
+func urlMapNames(namer namer_util.IngressFrontendNamer) []string {
+	names := []string{namer.UrlMap()}
+	if swapName, supported := namer.SwapUrlMap(); supported {
+		names = append(names, swapName)
+	}
+	return names
+}
+
 // Ensure implements LoadBalancerPool.
-func (l *L7s) Ensure(ri *L7RuntimeInfo) (*L7, error) {
+func (l *L7s) Ensure(ctx context.Context, ri *L7RuntimeInfo) (*L7, error) {
+	cloud := l.cloudForIngress(ri.Ingress)
 	lb := &L7{
-		runtimeInfo: ri,
-		cloud:       l.cloud,
-		namer:       l.namerFactory.Namer(ri.Ingress),
-		recorder:    l.recorderProducer.Recorder(ri.Ingress.Namespace),
-		scope:       features.ScopeFromIngress(ri.Ingress),
-		ingress:     *ri.Ingress,
+		runtimeInfo:      ri,
+		cloud:            cloud,
+		compositeCloud:   l.compositeForCloud(cloud),
+		backendProjectID: l.cloud.ProjectID(),
+		namer:            l.namerFactory.Namer(ri.Ingress),
+		recorder:         events.WithSyncID(l.recorderProducer.Recorder(ri.Ingress.Namespace), ri.SyncID),
+		scope:            features.ScopeFromIngress(ri.Ingress),
+		ingress:          *ri.Ingress,
+		sslProxies:       l.sslProxiesForCloud(cloud),
+		urlMapValidator:  l.urlMapValidator,
+		sharedSslCerts:   l.sharedSslCerts,
+	}
+
+	// Load balancers with the v1 naming scheme are garbage collected by
+	// listing the url maps of the cluster project.
+	if cloud != l.cloud && namer_util.FrontendNamingScheme(ri.Ingress) != namer_util.V2NamingScheme {
+		return nil, fmt.Errorf("Ingress %s/%s is mapped to project %q and requires the %s frontend naming scheme", ri.Ingress.Namespace, ri.Ingress.Name, cloud.ProjectID(), namer_util.V2NamingScheme)
 	}
 
 	if !lb.namer.IsValidLoadBalancer() {
@@ -72,37 +140,45 @@ func (l *L7s) Ensure(ri *L7RuntimeInfo) (*L7, error) {
 		return nil, err
 	}
 
-	if err := lb.edgeHop(); err != nil {
+	if err := lb.edgeHop(ctx); err != nil {
 		return nil, fmt.Errorf("loadbalancer %v does not exist: %v", lb.String(), err)
 	}
 	return lb, nil
 }
 
-// delete deletes a loadbalancer by frontend namer.
-func (l *L7s) delete(namer namer_util.IngressFrontendNamer, versions *features.ResourceVersions, scope meta.KeyType) error {
+// delete deletes a loadbalancer by frontend namer. The ingress is optional,
+// it is used to find frontend resources which are not named by the namer
+// alone, such as the target ssl proxy of gce-ssl-proxy Ingresses.
+func (l *L7s) delete(ctx context.Context, namer namer_util.IngressFrontendNamer, versions *features.ResourceVersions, scope meta.KeyType, ing *v1.Ingress) error {
 	if !namer.IsValidLoadBalancer() {
 		klog.V(2).Infof("Loadbalancer name %s invalid, skipping GC", namer.LoadBalancer())
 		return nil
 	}
+	cloud := l.cloudForIngress(ing)
 	lb := &L7{
-		runtimeInfo: &L7RuntimeInfo{},
-		cloud:       l.cloud,
-		namer:       namer,
-		scope:       scope,
+		runtimeInfo:    &L7RuntimeInfo{},
+		cloud:          cloud,
+		compositeCloud: l.compositeForCloud(cloud),
+		namer:          namer,
+		scope:          scope,
+		sslProxies:     l.sslProxiesForCloud(cloud),
+	}
+	if ing != nil {
+		lb.ingress = *ing
 	}
 
 	klog.V(2).Infof("Deleting loadbalancer %s", lb.String())
 
-	if err := lb.Cleanup(versions); err != nil {
+	if err := lb.Cleanup(ctx, versions); err != nil {
 		return err
 	}
 	return nil
 }
 
 // list returns a list of urlMaps (the top level LB resource) that belong to the cluster.
-func (l *L7s) list(key *meta.Key, version meta.Version) ([]*composite.UrlMap, error) {
+func (l *L7s) list(ctx context.Context, key *meta.Key, version meta.Version) ([]*composite.UrlMap, error) {
 	var result []*composite.UrlMap
-	urlMaps, err := composite.ListUrlMaps(l.cloud, key, version)
+	urlMaps, err := l.compositeCloud.ListUrlMapsWithFilter(ctx, key, version, composite.ListFilter(l.v1NamerHelper.NamePrefix(), ""))
 	if err != nil {
 		return nil, err
 	}
@@ -117,10 +193,10 @@ func (l *L7s) list(key *meta.Key, version meta.Version) ([]*composite.UrlMap, er
 }
 
 // GCv2 implements LoadBalancerPool.
-func (l *L7s) GCv2(ing *v1.Ingress, scope meta.KeyType) error {
+func (l *L7s) GCv2(ctx context.Context, ing *v1.Ingress, scope meta.KeyType) error {
 	ingKey := common.NamespacedName(ing)
 	klog.V(2).Infof("GCv2(%v)", ingKey)
-	if err := l.delete(l.namerFactory.Namer(ing), features.VersionsFromIngress(ing), scope); err != nil {
+	if err := l.delete(ctx, l.namerFactory.Namer(ing), features.VersionsFromIngress(ing), scope, ing); err != nil {
 		return err
 	}
 	klog.V(2).Infof("GCv2(%v) ok", ingKey)
@@ -131,30 +207,32 @@ func (l *L7s) GCv2(ing *v1.Ingress, scope meta.KeyType) error {
 // (e.g. when a user migrates from ILB to ELB on the same ingress or vice versa.)
 // This only applies to the V2 Naming Scheme
 // TODO(shance): Refactor to avoid calling GCE every sync loop
-func (l *L7s) FrontendScopeChangeGC(ing *v1.Ingress) (*meta.KeyType, error) {
+func (l *L7s) FrontendScopeChangeGC(ctx context.Context, ing *v1.Ingress) (*meta.KeyType, error) {
 	if ing == nil {
 		return nil, nil
 	}
 
 	namer := l.namerFactory.Namer(ing)
-	urlMapName := namer.UrlMap()
 	currentScope := features.ScopeFromIngress(ing)
+	cloud := l.compositeForCloud(l.cloudForIngress(ing))
 
 	for _, scope := range []meta.KeyType{meta.Global, meta.Regional} {
 		if scope != currentScope {
-			key, err := composite.CreateKey(l.cloud, urlMapName, scope)
-			if err != nil {
-				return nil, err
-			}
-
-			// Look for existing LBs with the same name but of a different scope
-			_, err = composite.GetUrlMap(l.cloud, key, features.VersionsFromIngress(ing).UrlMap)
-			if err == nil {
-				klog.V(2).Infof("GC'ing ing %v for scope %q", ing, scope)
-				return &scope, nil
-			}
-			if !utils.IsHTTPErrorCode(err, http.StatusNotFound) {
-				return nil, err
+			for _, urlMapName := range urlMapNames(namer) {
+				key, err := cloud.CreateKey(urlMapName, scope)
+				if err != nil {
+					return nil, err
+				}
+
+				// Look for existing LBs with the same name but of a different scope
+				_, err = cloud.GetUrlMap(ctx, key, features.VersionsFromIngress(ing).UrlMap)
+				if err == nil {
+					klog.V(2).Infof("GC'ing ing %v for scope %q", ing, scope)
+					return &scope, nil
+				}
+				if !utils.IsHTTPErrorCode(err, http.StatusNotFound) {
+					return nil, err
+				}
 			}
 		}
 	}
@@ -163,62 +241,114 @@ func (l *L7s) FrontendScopeChangeGC(ing *v1.Ingress) (*meta.KeyType, error) {
 
 // GCv1 implements LoadBalancerPool.
 // TODO(shance): Update to handle regional and global LB with same name
-func (l *L7s) GCv1(names []string) error {
+func (l *L7s) GCv1(ctx context.Context, names []string) error {
 	klog.V(2).Infof("GCv1(%v)", names)
 
-	knownLoadBalancers := make(map[namer_util.LoadBalancerName]bool)
-	for _, n := range names {
-		knownLoadBalancers[l.v1NamerHelper.LoadBalancer(n)] = true
-	}
+	knownLoadBalancers := l.knownLoadBalancers(names)
 
 	// GC L7-ILB LBs if enabled
-	key, err := composite.CreateKey(l.cloud, "", meta.Regional)
+	key, err := l.compositeCloud.CreateKey("", meta.Regional)
 	if err != nil {
 		return fmt.Errorf("error getting regional key: %v", err)
 	}
-	urlMaps, err := l.list(key, features.L7ILBVersions().UrlMap)
+	urlMaps, err := l.list(ctx, key, features.L7ILBVersions().UrlMap)
 	if err != nil {
 		return fmt.Errorf("error listing regional LBs: %v", err)
 	}
 
-	if err := l.gc(urlMaps, knownLoadBalancers, features.L7ILBVersions()); err != nil {
+	if err := l.gc(ctx, urlMaps, knownLoadBalancers, features.L7ILBVersions()); err != nil {
 		return fmt.Errorf("error gc-ing regional LBs: %v", err)
 	}
 
 	// TODO(shance): fix list taking a key
-	urlMaps, err = l.list(meta.GlobalKey(""), meta.VersionGA)
+	urlMaps, err = l.list(ctx, meta.GlobalKey(""), meta.VersionGA)
 	if err != nil {
 		return fmt.Errorf("error listing global LBs: %v", err)
 	}
 
-	if errors := l.gc(urlMaps, knownLoadBalancers, features.GAResourceVersions); errors != nil {
+	if errors := l.gc(ctx, urlMaps, knownLoadBalancers, features.GAResourceVersions); errors != nil {
 		return fmt.Errorf("error gcing global LBs: %v", errors)
 	}
 
 	return nil
 }
 
+// ExplainGCv1 implements LoadBalancerPool.
+func (l *L7s) ExplainGCv1(ctx context.Context, names []string) ([]utils.GCDecision, error) {
+	knownLoadBalancers := l.knownLoadBalancers(names)
+	key, err := l.compositeCloud.CreateKey("", meta.Regional)
+	if err != nil {
+		return nil, fmt.Errorf("error getting regional key: %v", err)
+	}
+	urlMaps, err := l.list(ctx, key, features.L7ILBVersions().UrlMap)
+	if err != nil {
+		return nil, fmt.Errorf("error listing regional LBs: %v", err)
+	}
+	globalUrlMaps, err := l.list(ctx, meta.GlobalKey(""), meta.VersionGA)
+	if err != nil {
+		return nil, fmt.Errorf("error listing global LBs: %v", err)
+	}
+
+	var decisions []utils.GCDecision
+	for _, um := range append(urlMaps, globalUrlMaps...) {
+		decision, _, err := l.gcDecision(um, knownLoadBalancers)
+		if err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, nil
+}
+
+// knownLoadBalancers maps the v1 load balancer names of the Ingresses with the
+// given keys to the keys.
+func (l *L7s) knownLoadBalancers(names []string) map[namer_util.LoadBalancerName]string {
+	knownLoadBalancers := make(map[namer_util.LoadBalancerName]string)
+	for _, n := range names {
+		knownLoadBalancers[l.v1NamerHelper.LoadBalancer(n)] = n
+	}
+	return knownLoadBalancers
+}
+
+// gcDecision decides whether the load balancer of um is garbage collected
+// given the known load balancers, and returns its scope.
+func (l *L7s) gcDecision(um *composite.UrlMap, knownLoadBalancers map[namer_util.LoadBalancerName]string) (utils.GCDecision, meta.KeyType, error) {
+	l7Name := l.v1NamerHelper.LoadBalancerForURLMap(um.Name)
+	decision := utils.GCDecision{Kind: "LoadBalancer", Name: string(l7Name)}
+	scope, err := composite.ScopeFromSelfLink(um.SelfLink)
+	if err != nil {
+		return decision, scope, fmt.Errorf("error getting scope from self link for urlMap %v: %v", um, err)
+	}
+	decision.Scope = string(scope)
+	if ingKey, ok := knownLoadBalancers[l7Name]; ok {
+		decision.Evidence = fmt.Sprintf("url map %s is the load balancer of Ingress %s", um.Name, ingKey)
+		return decision, scope, nil
+	}
+	decision.Evidence = fmt.Sprintf("url map %s belongs to the cluster but to no Ingress using the v1 naming scheme", um.Name)
+	decision.Delete = true
+	return decision, scope, nil
+}
+
 // gc is a helper for GCv1.
 // TODO(shance): get versions from description
-func (l *L7s) gc(urlMaps []*composite.UrlMap, knownLoadBalancers map[namer_util.LoadBalancerName]bool, versions *features.ResourceVersions) []error {
+func (l *L7s) gc(ctx context.Context, urlMaps []*composite.UrlMap, knownLoadBalancers map[namer_util.LoadBalancerName]string, versions *features.ResourceVersions) []error {
 	var errors []error
 
 	// Delete unknown loadbalancers
 	for _, um := range urlMaps {
-		l7Name := l.v1NamerHelper.LoadBalancerForURLMap(um.Name)
-
-		if knownLoadBalancers[l7Name] {
-			klog.V(3).Infof("Load balancer %v is still valid, not GC'ing", l7Name)
+		decision, scope, err := l.gcDecision(um, knownLoadBalancers)
+		if err != nil {
+			errors = append(errors, err)
 			continue
 		}
-
-		scope, err := composite.ScopeFromSelfLink(um.SelfLink)
-		if err != nil {
-			errors = append(errors, fmt.Errorf("error getting scope from self link for urlMap %v: %v", um, err))
+		if !decision.Delete {
+			klog.V(3).Infof("Load balancer %v is still valid, not GC'ing", decision.Name)
 			continue
 		}
+		klog.V(4).Infof("GC decision: %v", decision)
 
-		if err := l.delete(l.namerFactory.NamerForLoadBalancer(l7Name), versions, scope); err != nil {
+		l7Name := namer_util.LoadBalancerName(decision.Name)
+		if err := l.delete(ctx, l.namerFactory.NamerForLoadBalancer(l7Name), versions, scope, nil); err != nil {
 			errors = append(errors, fmt.Errorf("error deleting loadbalancer %q: %v", l7Name, err))
 		}
 	}
@@ -226,9 +356,9 @@ func (l *L7s) gc(urlMaps []*composite.UrlMap, knownLoadBalancers map[namer_util.
 }
 
 // Shutdown implements LoadBalancerPool.
-func (l *L7s) Shutdown(ings []*v1.Ingress) error {
+func (l *L7s) Shutdown(ctx context.Context, ings []*v1.Ingress) error {
 	// Delete ingresses that use v1 naming scheme.
-	if err := l.GCv1([]string{}); err != nil {
+	if err := l.GCv1(ctx, []string{}); err != nil {
 		return fmt.Errorf("error deleting load-balancers for v1 naming policy: %v", err)
 	}
 	// Delete ingresses that use v2 naming policy.
@@ -237,7 +367,7 @@ func (l *L7s) Shutdown(ings []*v1.Ingress) error {
 		return namer_util.FrontendNamingScheme(ing) == namer_util.V2NamingScheme
 	}).AsList()
 	for _, ing := range v2Ings {
-		if err := l.GCv2(ing, features.ScopeFromIngress(ing)); err != nil {
+		if err := l.GCv2(ctx, ing, features.ScopeFromIngress(ing)); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -249,17 +379,22 @@ func (l *L7s) Shutdown(ings []*v1.Ingress) error {
 }
 
 // HasUrlMap implements LoadBalancerPool.
-func (l *L7s) HasUrlMap(ing *v1.Ingress) (bool, error) {
+func (l *L7s) HasUrlMap(ctx context.Context, ing *v1.Ingress) (bool, error) {
 	namer := l.namerFactory.Namer(ing)
-	key, err := composite.CreateKey(l.cloud, namer.UrlMap(), features.ScopeFromIngress(ing))
-	if err != nil {
-		return false, err
-	}
-	if _, err := composite.GetUrlMap(l.cloud, key, features.VersionsFromIngress(ing).UrlMap); err != nil {
-		if utils.IsHTTPErrorCode(err, http.StatusNotFound) {
-			return false, nil
+	cloud := l.compositeForCloud(l.cloudForIngress(ing))
+	// The url map might only exist under the swap name.
+	for _, urlMapName := range urlMapNames(namer) {
+		key, err := cloud.CreateKey(urlMapName, features.ScopeFromIngress(ing))
+		if err != nil {
+			return false, err
+		}
+		_, err = cloud.GetUrlMap(ctx, key, features.VersionsFromIngress(ing).UrlMap)
+		if err == nil {
+			return true, nil
+		}
+		if !utils.IsHTTPErrorCode(err, http.StatusNotFound) {
+			return false, err
 		}
-		return false, err
 	}
-	return true, nil
+	return false, nil
 }
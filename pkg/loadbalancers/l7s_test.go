@@ -17,6 +17,7 @@ limitations under the License.
 package loadbalancers
 
 import (
+	context2 "context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -31,6 +32,7 @@ import (
 	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/context"
 	"k8s.io/ingress-gce/pkg/loadbalancers/features"
+	"k8s.io/ingress-gce/pkg/multiproject"
 	"k8s.io/ingress-gce/pkg/utils/common"
 	namer_util "k8s.io/ingress-gce/pkg/utils/namer"
 	"k8s.io/legacy-cloud-providers/gce"
@@ -189,7 +191,7 @@ func TestGC(t *testing.T) {
 			createFakeLoadbalancer(cloud, namer, versions, defaultScope)
 		}
 
-		err := l7sPool.GCv1(tc.ingressLBs)
+		err := l7sPool.GCv1(context2.TODO(), tc.ingressLBs)
 		if err != nil {
 			t.Errorf("For case %q, do not expect err: %v", tc.desc, err)
 		}
@@ -251,7 +253,7 @@ func TestDoNotGCWantedLB(t *testing.T) {
 	for _, tc := range testCases {
 		namer := l7sPool.namerFactory.NamerForLoadBalancer(l7sPool.v1NamerHelper.LoadBalancer(tc.key))
 		createFakeLoadbalancer(l7sPool.cloud, namer, versions, defaultScope)
-		err := l7sPool.GCv1([]string{tc.key})
+		err := l7sPool.GCv1(context2.TODO(), []string{tc.key})
 		if err != nil {
 			t.Errorf("For case %q, do not expect err: %v", tc.desc, err)
 		}
@@ -287,7 +289,7 @@ func TestGCToLeakLB(t *testing.T) {
 	for _, tc := range testCases {
 		namer := l7sPool.namerFactory.NamerForLoadBalancer(l7sPool.v1NamerHelper.LoadBalancer(tc.key))
 		createFakeLoadbalancer(l7sPool.cloud, namer, versions, defaultScope)
-		err := l7sPool.GCv1([]string{})
+		err := l7sPool.GCv1(context2.TODO(), []string{})
 		if err != nil {
 			t.Errorf("For case %q, do not expect err: %v", tc.desc, err)
 		}
@@ -525,7 +527,7 @@ func TestV2GC(t *testing.T) {
 				createFakeLoadbalancer(cloud, feNamerFactory.Namer(ing), versions, defaultScope)
 			}
 
-			err := l7sPool.GCv2(tc.ingressToDelete, features.ScopeFromIngress(tc.ingressToDelete))
+			err := l7sPool.GCv2(context2.TODO(), tc.ingressToDelete, features.ScopeFromIngress(tc.ingressToDelete))
 			if err != nil {
 				t.Errorf("l7sPool.GC(%q) = %v, want nil for case %q", common.NamespacedName(tc.ingressToDelete), err, tc.desc)
 			}
@@ -604,7 +606,7 @@ func TestDoNotLeakV2LB(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			feNamer := feNamerFactory.Namer(tc.ing)
 			createFakeLoadbalancer(l7sPool.cloud, feNamer, versions, defaultScope)
-			err := l7sPool.GCv2(tc.ing, features.ScopeFromIngress(tc.ing))
+			err := l7sPool.GCv2(context2.TODO(), tc.ing, features.ScopeFromIngress(tc.ing))
 			if err != nil {
 				t.Errorf("l7sPool.GC(%q) = %v, want nil for case %q", common.NamespacedName(tc.ing), err, tc.desc)
 			}
@@ -625,20 +627,20 @@ func newTestLoadBalancerPool() LoadBalancerPool {
 	namer := namer_util.NewNamer(testClusterName, "fw1")
 	fakeGCECloud := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
 	ctx := &context.ControllerContext{}
-	return NewLoadBalancerPool(fakeGCECloud, namer, ctx, namer_util.NewFrontendNamerFactory(namer, kubeSystemUID))
+	return NewLoadBalancerPool(multiproject.NewSingleProjectRouter(fakeGCECloud), namer, ctx, namer_util.NewFrontendNamerFactory(namer, kubeSystemUID))
 }
 
 func createFakeLoadbalancer(cloud *gce.Cloud, namer namer_util.IngressFrontendNamer, versions *features.ResourceVersions, scope meta.KeyType) {
 	key, _ := composite.CreateKey(cloud, "", scope)
 
 	key.Name = namer.ForwardingRule(namer_util.HTTPProtocol)
-	composite.CreateForwardingRule(cloud, key, &composite.ForwardingRule{Name: key.Name, Version: versions.ForwardingRule})
+	composite.CreateForwardingRule(context2.TODO(), cloud, key, &composite.ForwardingRule{Name: key.Name, Version: versions.ForwardingRule})
 
 	key.Name = namer.TargetProxy(namer_util.HTTPProtocol)
-	composite.CreateTargetHttpProxy(cloud, key, &composite.TargetHttpProxy{Name: key.Name, Version: versions.TargetHttpProxy})
+	composite.CreateTargetHttpProxy(context2.TODO(), cloud, key, &composite.TargetHttpProxy{Name: key.Name, Version: versions.TargetHttpProxy})
 
 	key.Name = namer.UrlMap()
-	composite.CreateUrlMap(cloud, key, &composite.UrlMap{Name: key.Name, Version: versions.UrlMap})
+	composite.CreateUrlMap(context2.TODO(), cloud, key, &composite.UrlMap{Name: key.Name, Version: versions.UrlMap})
 
 	cloud.ReserveGlobalAddress(&compute.Address{Name: namer.ForwardingRule(namer_util.HTTPProtocol)})
 
@@ -647,13 +649,13 @@ func createFakeLoadbalancer(cloud *gce.Cloud, namer namer_util.IngressFrontendNa
 func removeFakeLoadBalancer(cloud *gce.Cloud, namer namer_util.IngressFrontendNamer, versions *features.ResourceVersions, scope meta.KeyType) {
 	key, _ := composite.CreateKey(cloud, "", scope)
 	key.Name = namer.ForwardingRule(namer_util.HTTPProtocol)
-	composite.DeleteForwardingRule(cloud, key, versions.ForwardingRule)
+	composite.DeleteForwardingRule(context2.TODO(), cloud, key, versions.ForwardingRule)
 
 	key.Name = namer.TargetProxy(namer_util.HTTPProtocol)
-	composite.DeleteTargetHttpProxy(cloud, key, versions.TargetHttpProxy)
+	composite.DeleteTargetHttpProxy(context2.TODO(), cloud, key, versions.TargetHttpProxy)
 
 	key.Name = namer.UrlMap()
-	composite.DeleteUrlMap(cloud, key, versions.UrlMap)
+	composite.DeleteUrlMap(context2.TODO(), cloud, key, versions.UrlMap)
 
 	cloud.DeleteGlobalAddress(namer.ForwardingRule(namer_util.HTTPProtocol))
 }
@@ -663,7 +665,7 @@ func checkFakeLoadBalancer(cloud *gce.Cloud, namer namer_util.IngressFrontendNam
 		return err
 	}
 	key, _ := composite.CreateKey(cloud, namer.UrlMap(), scope)
-	_, err := composite.GetUrlMap(cloud, key, versions.UrlMap)
+	_, err := composite.GetUrlMap(context2.TODO(), cloud, key, versions.UrlMap)
 	if expectPresent && err != nil {
 		return err
 	}
@@ -684,7 +686,7 @@ func checkFakeLoadBalancerWithProtocol(cloud *gce.Cloud, namer namer_util.Ingres
 	var err error
 	key, _ := composite.CreateKey(cloud, namer.ForwardingRule(protocol), scope)
 
-	_, err = composite.GetForwardingRule(cloud, key, versions.ForwardingRule)
+	_, err = composite.GetForwardingRule(context2.TODO(), cloud, key, versions.ForwardingRule)
 	if expectPresent && err != nil {
 		return err
 	}
@@ -694,9 +696,9 @@ func checkFakeLoadBalancerWithProtocol(cloud *gce.Cloud, namer namer_util.Ingres
 
 	key.Name = namer.TargetProxy(protocol)
 	if protocol == namer_util.HTTPProtocol {
-		_, err = composite.GetTargetHttpProxy(cloud, key, versions.TargetHttpProxy)
+		_, err = composite.GetTargetHttpProxy(context2.TODO(), cloud, key, versions.TargetHttpProxy)
 	} else {
-		_, err = composite.GetTargetHttpsProxy(cloud, key, versions.TargetHttpsProxy)
+		_, err = composite.GetTargetHttpsProxy(context2.TODO(), cloud, key, versions.TargetHttpsProxy)
 	}
 	if expectPresent && err != nil {
 		return err
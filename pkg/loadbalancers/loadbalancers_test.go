@@ -40,8 +40,10 @@ import (
 	"k8s.io/ingress-gce/pkg/flags"
 	"k8s.io/ingress-gce/pkg/instances"
 	"k8s.io/ingress-gce/pkg/loadbalancers/features"
+	"k8s.io/ingress-gce/pkg/multiproject"
 	"k8s.io/ingress-gce/pkg/test"
 	"k8s.io/ingress-gce/pkg/translator"
+	"k8s.io/ingress-gce/pkg/urlmaps"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/ingress-gce/pkg/utils/common"
 	namer_util "k8s.io/ingress-gce/pkg/utils/namer"
@@ -103,7 +105,7 @@ func newTestJig(t *testing.T) *testJig {
 			if err != nil {
 				return err
 			}
-			_, err = composite.GetSslCertificate(fakeGCE, resID.Key, defaultVersion)
+			_, err = composite.GetSslCertificate(context.TODO(), fakeGCE, resID.Key, defaultVersion)
 			if err != nil {
 				return err
 			}
@@ -159,7 +161,7 @@ func newFakeLoadBalancerPool(cloud *gce.Cloud, t *testing.T, namer *namer_util.N
 	nodePool := instances.NewNodePool(fakeIGs, namer, &test.FakeRecorderSource{}, utils.GetBasePath(cloud))
 	nodePool.Init(&instances.FakeZoneLister{Zones: []string{defaultZone}})
 
-	return L7s{cloud, namer, events.RecorderProducerMock{}, namer_util.NewFrontendNamerFactory(namer, "")}
+	return L7s{cloud, composite.NewCloud(cloud), multiproject.NewSingleProjectRouter(cloud), namer, events.RecorderProducerMock{}, namer_util.NewFrontendNamerFactory(namer, ""), NewFakeTargetSslProxies(), NewFakeUrlMapValidator(), NewFakeSharedSslCertificates()}
 }
 
 func newILBIngress() *networkingv1.Ingress {
@@ -186,13 +188,108 @@ func TestCreateHTTPLoadBalancer(t *testing.T) {
 		Ingress:   newIngress(),
 	}
 
-	l7, err := j.pool.Ensure(lbInfo)
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil || l7 == nil {
 		t.Fatalf("Expected l7 not created, err: %v", err)
 	}
 	verifyHTTPForwardingRuleAndProxyLinks(t, j, l7, "")
 }
 
+func TestSyncIDInDescriptions(t *testing.T) {
+	j := newTestJig(t)
+
+	gceUrlMap := utils.NewGCEURLMap()
+	gceUrlMap.DefaultBackend = &utils.ServicePort{NodePort: 31234, BackendNamer: j.namer}
+	lbInfo := &L7RuntimeInfo{AllowHTTP: true, UrlMap: gceUrlMap, Ingress: newIngress(), SyncID: "abc123"}
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
+	if err != nil {
+		t.Fatalf("j.pool.Ensure() = %v", err)
+	}
+
+	want := fmt.Sprintf(`{"kubernetes.io/ingress-name": "%s/%s", "kubernetes.io/sync-id": "abc123"}`, namespace, ingressName)
+	key, err := composite.CreateKey(j.fakeGCE, l7.um.Name, defaultScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	um, err := composite.GetUrlMap(context.TODO(), j.fakeGCE, key, defaultVersion)
+	if err != nil {
+		t.Fatalf("composite.GetUrlMap(%q) = %v", key.Name, err)
+	}
+	if um.Description != want {
+		t.Errorf("um.Description = %q, want %q", um.Description, want)
+	}
+	if l7.tp.Description != want {
+		t.Errorf("l7.tp.Description = %q, want %q", l7.tp.Description, want)
+	}
+	if l7.fw.Description != want {
+		t.Errorf("l7.fw.Description = %q, want %q", l7.fw.Description, want)
+	}
+	if got := l7.String(); !strings.HasSuffix(got, "(sync abc123)") {
+		t.Errorf("l7.String() = %q, want sync ID", got)
+	}
+}
+
+func TestCreateLoadBalancerInMappedProject(t *testing.T) {
+	j := newTestJig(t)
+	vals := gce.DefaultTestClusterValues()
+	vals.ProjectID = "tenant-project"
+	tenantGCE := gce.NewFakeGCECloud(vals)
+	config := &multiproject.Config{Projects: []multiproject.ProjectConfig{{ProjectID: vals.ProjectID, Namespaces: []string{namespace}}}}
+	router, err := multiproject.NewRouter(j.fakeGCE, config, func(multiproject.ProjectConfig) (*gce.Cloud, error) { return tenantGCE, nil })
+	if err != nil {
+		t.Fatalf("multiproject.NewRouter() = %v", err)
+	}
+	j.pool.projects = router
+
+	gceUrlMap := utils.NewGCEURLMap()
+	gceUrlMap.DefaultBackend = &utils.ServicePort{NodePort: 31234, BackendNamer: j.namer}
+	gceUrlMap.PutPathRulesForHost("bar.example.com", []utils.PathRule{{Path: "/bar", Backend: utils.ServicePort{NodePort: 30000, BackendNamer: j.namer}}})
+
+	// The v1 naming scheme is rejected for mapped namespaces.
+	lbInfo := &L7RuntimeInfo{AllowHTTP: true, UrlMap: gceUrlMap, Ingress: newIngress()}
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err == nil {
+		t.Errorf("j.pool.Ensure() = nil, want error for v1 naming scheme")
+	}
+
+	ing := newIngress()
+	ing.Finalizers = []string{common.FinalizerKeyV2}
+	lbInfo.Ingress = ing
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
+	if err != nil {
+		t.Fatalf("j.pool.Ensure() = %v", err)
+	}
+
+	key, err := composite.CreateKey(tenantGCE, l7.namer.UrlMap(), l7.scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	um, err := composite.GetUrlMap(context.TODO(), tenantGCE, key, l7.Versions().UrlMap)
+	if err != nil {
+		t.Fatalf("composite.GetUrlMap(%q) in tenant project = %v", key.Name, err)
+	}
+	wantPrefix := fmt.Sprintf("projects/%s/global/backendServices/", j.fakeGCE.ProjectID())
+	if !strings.HasPrefix(um.DefaultService, wantPrefix) {
+		t.Errorf("um.DefaultService = %q, want prefix %q", um.DefaultService, wantPrefix)
+	}
+	for _, pm := range um.PathMatchers {
+		for _, rule := range pm.PathRules {
+			if !strings.HasPrefix(rule.Service, wantPrefix) {
+				t.Errorf("rule.Service = %q, want prefix %q", rule.Service, wantPrefix)
+			}
+		}
+	}
+	if _, err := composite.GetUrlMap(context.TODO(), j.fakeGCE, key, l7.Versions().UrlMap); !utils.IsNotFoundError(err) {
+		t.Errorf("composite.GetUrlMap(%q) in cluster project = %v, want not found", key.Name, err)
+	}
+
+	if err := j.pool.GCv2(context.TODO(), ing, l7.scope); err != nil {
+		t.Fatalf("j.pool.GCv2() = %v", err)
+	}
+	if _, err := composite.GetUrlMap(context.TODO(), tenantGCE, key, l7.Versions().UrlMap); !utils.IsNotFoundError(err) {
+		t.Errorf("composite.GetUrlMap(%q) in tenant project = %v, want not found", key.Name, err)
+	}
+}
+
 func TestCreateHTTPILBLoadBalancer(t *testing.T) {
 	// This should NOT create the forwarding rule and target proxy
 	// associated with the HTTPS branch of this loadbalancer.
@@ -207,7 +304,7 @@ func TestCreateHTTPILBLoadBalancer(t *testing.T) {
 		Ingress:   newILBIngress(),
 	}
 
-	l7, err := j.pool.Ensure(lbInfo)
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil || l7 == nil {
 		t.Fatalf("Expected l7 not created, err: %v", err)
 	}
@@ -225,7 +322,7 @@ func TestCreateHTTPILBLoadBalancerStaticIp(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = composite.CreateAddress(j.fakeGCE, key, &composite.Address{Name: ipName, Version: meta.VersionGA, Address: ip})
+	err = composite.CreateAddress(context.TODO(), j.fakeGCE, key, &composite.Address{Name: ipName, Version: meta.VersionGA, Address: ip})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -240,7 +337,7 @@ func TestCreateHTTPILBLoadBalancerStaticIp(t *testing.T) {
 		StaticIPName: ipName,
 	}
 
-	l7, err := j.pool.Ensure(lbInfo)
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil || l7 == nil {
 		t.Fatalf("Expected l7 not created, err: %v", err)
 	}
@@ -248,6 +345,61 @@ func TestCreateHTTPILBLoadBalancerStaticIp(t *testing.T) {
 	verifyHTTPForwardingRuleAndProxyLinks(t, j, l7, ip)
 }
 
+func TestCreateHTTPILBLoadBalancerManagedStaticIP(t *testing.T) {
+	j := newTestJig(t)
+	j.mock.MockAddresses.InsertHook = mock.InsertAddressHook
+	j.mock.MockAddresses.X = mock.AddressAttributes{}
+
+	gceUrlMap := utils.NewGCEURLMap()
+	gceUrlMap.DefaultBackend = &utils.ServicePort{NodePort: 31234, BackendNamer: j.namer}
+	lbInfo := &L7RuntimeInfo{
+		AllowHTTP:             true,
+		UrlMap:                gceUrlMap,
+		Ingress:               newILBIngress(),
+		ManagedStaticIPSubnet: "ilb-subnet",
+	}
+
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
+	if err != nil || l7 == nil {
+		t.Fatalf("j.pool.Ensure(%v) = %v, want nil", lbInfo, err)
+	}
+	key, err := composite.CreateKey(j.fakeGCE, l7.namer.ForwardingRule(namer_util.HTTPProtocol), features.L7ILBScope())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ip, err := composite.GetAddress(context.TODO(), j.fakeGCE, key, meta.VersionGA)
+	if err != nil {
+		t.Fatalf("composite.GetAddress(%q) = %v, want nil", key.Name, err)
+	}
+	if ip.Address == "" || ip.AddressType != "INTERNAL" || !strings.HasSuffix(ip.Subnetwork, "/subnetworks/ilb-subnet") {
+		t.Errorf("Static IP = %+v, want an internal address in subnet ilb-subnet", ip)
+	}
+	if got := l7.getFrontendAnnotations(nil)[annotations.StaticIPKey]; got != ip.Name {
+		t.Errorf("Static IP annotation = %q, want %q", got, ip.Name)
+	}
+	verifyHTTPForwardingRuleAndProxyLinks(t, j, l7, ip.Address)
+	fr, err := composite.GetForwardingRule(context.TODO(), j.fakeGCE, key, l7.Versions().ForwardingRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fr.Subnetwork != ip.Subnetwork {
+		t.Errorf("Forwarding rule subnetwork = %q, want %q", fr.Subnetwork, ip.Subnetwork)
+	}
+
+	// The static IP is kept across syncs and released on cleanup.
+	l7, err = j.pool.Ensure(context.TODO(), lbInfo)
+	if err != nil {
+		t.Fatalf("j.pool.Ensure(%v) = %v, want nil", lbInfo, err)
+	}
+	verifyHTTPForwardingRuleAndProxyLinks(t, j, l7, ip.Address)
+	if err := l7.Cleanup(context.TODO(), l7.Versions()); err != nil {
+		t.Fatalf("l7.Cleanup() = %v", err)
+	}
+	if _, err := composite.GetAddress(context.TODO(), j.fakeGCE, key, meta.VersionGA); !utils.IsNotFoundError(err) {
+		t.Errorf("composite.GetAddress(%q) = %v, want not found", key.Name, err)
+	}
+}
+
 func TestCreateHTTPSILBLoadBalancer(t *testing.T) {
 	// This should NOT create the forwarding rule and target proxy
 	// associated with the HTTP branch of this loadbalancer.
@@ -263,7 +415,7 @@ func TestCreateHTTPSILBLoadBalancer(t *testing.T) {
 		Ingress:   newILBIngress(),
 	}
 
-	l7, err := j.pool.Ensure(lbInfo)
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil || l7 == nil {
 		t.Fatalf("Expected l7 not created")
 	}
@@ -285,7 +437,7 @@ func TestCreateHTTPSILBLoadBalancerAllowHTTP(t *testing.T) {
 		Ingress:   newILBIngress(),
 	}
 
-	if _, err := j.pool.Ensure(lbInfo); err == nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err == nil {
 		t.Fatalf("j.pool.Ensure(%v) = nil, want err", lbInfo)
 	}
 }
@@ -305,7 +457,7 @@ func TestCreateHTTPSLoadBalancer(t *testing.T) {
 		Ingress:   newIngress(),
 	}
 
-	l7, err := j.pool.Ensure(lbInfo)
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil || l7 == nil {
 		t.Fatalf("Expected l7 not created")
 	}
@@ -320,11 +472,11 @@ func verifyHTTPSForwardingRuleAndProxyLinks(t *testing.T, j *testJig, l7 *L7) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	um, err := composite.GetUrlMap(j.fakeGCE, key, versions.UrlMap)
+	um, err := composite.GetUrlMap(context.TODO(), j.fakeGCE, key, versions.UrlMap)
 
 	tpsName := l7.namer.TargetProxy(namer_util.HTTPSProtocol)
 	key.Name = tpsName
-	tps, err := composite.GetTargetHttpsProxy(j.fakeGCE, key, versions.TargetHttpsProxy)
+	tps, err := composite.GetTargetHttpsProxy(context.TODO(), j.fakeGCE, key, versions.TargetHttpsProxy)
 	if err != nil {
 		t.Fatalf("j.fakeGCE.GetTargetHTTPSProxy(%q) = _, %v; want nil", tpsName, err)
 	}
@@ -334,7 +486,7 @@ func verifyHTTPSForwardingRuleAndProxyLinks(t *testing.T, j *testJig, l7 *L7) {
 
 	fwsName := l7.namer.ForwardingRule(namer_util.HTTPSProtocol)
 	key.Name = fwsName
-	fws, err := composite.GetForwardingRule(j.fakeGCE, key, versions.ForwardingRule)
+	fws, err := composite.GetForwardingRule(context.TODO(), j.fakeGCE, key, versions.ForwardingRule)
 	if err != nil {
 		t.Fatalf("j.fakeGCE.GetGlobalForwardingRule(%q) = _, %v, want nil", fwsName, err)
 	}
@@ -354,10 +506,10 @@ func verifyHTTPForwardingRuleAndProxyLinks(t *testing.T, j *testJig, l7 *L7, ip
 	if err != nil {
 		t.Fatal(err)
 	}
-	um, err := composite.GetUrlMap(j.fakeGCE, key, versions.UrlMap)
+	um, err := composite.GetUrlMap(context.TODO(), j.fakeGCE, key, versions.UrlMap)
 	tpName := l7.namer.TargetProxy(namer_util.HTTPProtocol)
 	key.Name = tpName
-	tps, err := composite.GetTargetHttpProxy(j.fakeGCE, key, versions.TargetHttpProxy)
+	tps, err := composite.GetTargetHttpProxy(context.TODO(), j.fakeGCE, key, versions.TargetHttpProxy)
 	if err != nil {
 		t.Fatalf("j.fakeGCE.GetTargetHTTPProxy(%q) = _, %v; want nil", tpName, err)
 	}
@@ -366,7 +518,7 @@ func verifyHTTPForwardingRuleAndProxyLinks(t *testing.T, j *testJig, l7 *L7, ip
 	}
 	fwName := l7.namer.ForwardingRule(namer_util.HTTPProtocol)
 	key.Name = fwName
-	fws, err := composite.GetForwardingRule(j.fakeGCE, key, versions.ForwardingRule)
+	fws, err := composite.GetForwardingRule(context.TODO(), j.fakeGCE, key, versions.ForwardingRule)
 	if err != nil {
 		t.Fatalf("j.fakeGCE.GetGlobalForwardingRule(%q) = _, %v, want nil", fwName, err)
 	}
@@ -404,7 +556,7 @@ func TestCertUpdate(t *testing.T) {
 	}
 
 	// Sync first cert
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 
@@ -415,7 +567,7 @@ func TestCertUpdate(t *testing.T) {
 
 	// Sync with different cert
 	lbInfo.TLS = []*translator.TLSCerts{createCert("key2", "cert2", "name")}
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 	expectCerts = map[string]string{certName2: lbInfo.TLS[0].Cert}
@@ -443,7 +595,7 @@ func TestMultipleSecretsWithSameCert(t *testing.T) {
 	}
 
 	// Sync first cert
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 	certName := feNamer.SSLCertName(translator.GetCertHash("cert"))
@@ -477,14 +629,14 @@ func TestCertCreationWithCollision(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name:        certName1,
 		Certificate: "cert",
 		SelfLink:    "existing",
 	})
 
 	// Sync first cert
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 
@@ -494,7 +646,7 @@ func TestCertCreationWithCollision(t *testing.T) {
 	// Create another cert where the name matches that of another cert, but contents are different - xyz != cert2.
 	// Simulates a hash collision
 	key.Name = certName2
-	composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name:        certName2,
 		Certificate: "xyz",
 		SelfLink:    "existing",
@@ -502,7 +654,7 @@ func TestCertCreationWithCollision(t *testing.T) {
 
 	// Sync with different cert
 	lbInfo.TLS = []*translator.TLSCerts{createCert("key2", "cert2", "name")}
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 	expectCerts = map[string]string{certName2: "xyz"}
@@ -537,29 +689,29 @@ func TestMultipleCertRetentionAfterRestart(t *testing.T) {
 
 	firstPool := j.pool
 
-	firstPool.Ensure(lbInfo)
+	firstPool.Ensure(context.TODO(), lbInfo)
 	verifyCertAndProxyLink(expectCerts, expectCerts, j, t)
 	// Update config to use 2 certs
 	lbInfo.TLS = []*translator.TLSCerts{cert1, cert2}
 	expectCerts[certName2] = cert2.Cert
-	firstPool.Ensure(lbInfo)
+	firstPool.Ensure(context.TODO(), lbInfo)
 	verifyCertAndProxyLink(expectCerts, expectCerts, j, t)
 
 	// Restart of controller represented by a new pool
 	secondPool := newFakeLoadBalancerPool(j.fakeGCE, t, j.namer)
-	secondPool.Ensure(lbInfo)
+	secondPool.Ensure(context.TODO(), lbInfo)
 	// Verify both certs are still present
 	verifyCertAndProxyLink(expectCerts, expectCerts, j, t)
 
 	// Replace the 2 certs with a different, single cert
 	lbInfo.TLS = []*translator.TLSCerts{cert3}
 	expectCerts = map[string]string{certName3: cert3.Cert}
-	secondPool.Ensure(lbInfo)
+	secondPool.Ensure(context.TODO(), lbInfo)
 	// Only the new cert should be present
 	verifyCertAndProxyLink(expectCerts, expectCerts, j, t)
 }
 
-//TestUpgradeToNewCertNames verifies that certs uploaded using the old naming convention
+// TestUpgradeToNewCertNames verifies that certs uploaded using the old naming convention
 // are picked up and deleted when upgrading to the new scheme.
 func TestUpgradeToNewCertNames(t *testing.T) {
 	j := newTestJig(t)
@@ -585,8 +737,8 @@ func TestUpgradeToNewCertNames(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	composite.CreateSslCertificate(j.fakeGCE, key, sslCert)
-	sslCert, _ = composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, sslCert)
+	sslCert, _ = composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 	tpName := feNamer.TargetProxy(namer_util.HTTPSProtocol)
 	newProxy := &composite.TargetHttpsProxy{
 		Name:            tpName,
@@ -595,11 +747,11 @@ func TestUpgradeToNewCertNames(t *testing.T) {
 		Version:         defaultVersion,
 	}
 	key.Name = tpName
-	err = composite.CreateTargetHttpsProxy(j.fakeGCE, key, newProxy)
+	err = composite.CreateTargetHttpsProxy(context.TODO(), j.fakeGCE, key, newProxy)
 	if err != nil {
 		t.Fatalf("Failed to create Target proxy %v - %v", newProxy, err)
 	}
-	proxyCerts, err := composite.ListSslCertificates(j.fakeGCE, key, defaultVersion)
+	proxyCerts, err := composite.ListSslCertificates(context.TODO(), j.fakeGCE, key, defaultVersion)
 	if err != nil {
 		t.Fatalf("Failed to list certs for load balancer %v - %v", j, err)
 	}
@@ -611,7 +763,7 @@ func TestUpgradeToNewCertNames(t *testing.T) {
 		t.Fatalf("Expected cert with name %s, Got %s", oldCertName, proxyCerts[0].Name)
 	}
 	// Sync should replace this oldCert with one following the new naming scheme
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 	// We expect to see only the new cert linked to the proxy and available in the load balancer.
@@ -646,14 +798,14 @@ func TestMaxCertsUpload(t *testing.T) {
 		Ingress:   ing,
 	}
 
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 
 	verifyCertAndProxyLink(expectCerts, expectCerts, j, t)
 	failCert := createCert("key100", "cert100", "name100")
 	lbInfo.TLS = append(lbInfo.TLS, failCert)
-	_, err := j.pool.Ensure(lbInfo)
+	_, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err == nil {
 		t.Fatalf("Creating more than %d certs should have errored out", FakeCertQuota)
 	}
@@ -663,7 +815,7 @@ func TestMaxCertsUpload(t *testing.T) {
 	for _, cert := range lbInfo.TLS {
 		expectCertsExtra[feNamer.SSLCertName(cert.CertHash)] = cert.Cert
 	}
-	_, err = j.pool.Ensure(lbInfo)
+	_, err = j.pool.Ensure(context.TODO(), lbInfo)
 	if err == nil {
 		t.Fatalf("Assigning more than %d certs should have errored out", TargetProxyCertLimit)
 	}
@@ -671,7 +823,7 @@ func TestMaxCertsUpload(t *testing.T) {
 	verifyCertAndProxyLink(expectCertsExtra, expectCerts, j, t)
 	// Removing the extra cert from ingress spec should delete it
 	lbInfo.TLS = lbInfo.TLS[:TargetProxyCertLimit]
-	_, err = j.pool.Ensure(lbInfo)
+	_, err = j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil {
 		t.Fatalf("Unexpected error %s", err)
 	}
@@ -713,13 +865,13 @@ func TestIdenticalHostnameCerts(t *testing.T) {
 
 	// Sync multiple times to make sure ordering is preserved
 	for i := 0; i < 10; i++ {
-		if _, err := j.pool.Ensure(lbInfo); err != nil {
+		if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 			t.Fatalf("pool.Ensure() = err %v", err)
 		}
 		verifyCertAndProxyLink(expectCerts, expectCerts, j, t)
 		// Fetch the target proxy certs and go through in order
 		verifyProxyCertsInOrder(" foo.com", j, t)
-		j.pool.delete(feNamer, features.GAResourceVersions, defaultScope)
+		j.pool.delete(context.TODO(), feNamer, features.GAResourceVersions, defaultScope, nil)
 	}
 }
 
@@ -739,28 +891,28 @@ func TestIdenticalHostnameCertsPreShared(t *testing.T) {
 		t.Fatal(err)
 	}
 	// Prepare pre-shared certs.
-	composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name:        "test-pre-shared-cert",
 		Certificate: "cert-0 foo.com",
 		SelfLink:    "existing",
 	})
-	preSharedCert1, _ := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+	preSharedCert1, _ := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 
 	key.Name = "test-pre-shared-cert1"
-	composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name:        "test-pre-shared-cert1",
 		Certificate: "cert-1 foo.com",
 		SelfLink:    "existing",
 	})
-	preSharedCert2, _ := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+	preSharedCert2, _ := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 
 	key.Name = "test-pre-shared-cert2"
-	composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name:        "test-pre-shared-cert2",
 		Certificate: "cert2",
 		SelfLink:    "existing",
 	})
-	preSharedCert3, _ := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+	preSharedCert3, _ := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 
 	expectCerts := map[string]string{preSharedCert1.Name: preSharedCert1.Certificate,
 		preSharedCert2.Name: preSharedCert2.Certificate, preSharedCert3.Name: preSharedCert3.Certificate}
@@ -768,14 +920,14 @@ func TestIdenticalHostnameCertsPreShared(t *testing.T) {
 	lbInfo.TLSName = preSharedCert1.Name + "," + preSharedCert2.Name + "," + preSharedCert3.Name
 	// Sync multiple times to make sure ordering is preserved
 	for i := 0; i < 10; i++ {
-		if _, err := j.pool.Ensure(lbInfo); err != nil {
+		if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 			t.Fatalf("pool.Ensure() = err %v", err)
 		}
 		verifyCertAndProxyLink(expectCerts, expectCerts, j, t)
 		// Fetch the target proxy certs and go through in order
 		verifyProxyCertsInOrder(" foo.com", j, t)
 		feNamer := namer_util.NewFrontendNamerFactory(j.namer, "").Namer(lbInfo.Ingress)
-		j.pool.delete(feNamer, features.GAResourceVersions, defaultScope)
+		j.pool.delete(context.TODO(), feNamer, features.GAResourceVersions, defaultScope, nil)
 	}
 }
 
@@ -803,27 +955,27 @@ func TestPreSharedToSecretBasedCertUpdate(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name:        "test-pre-shared-cert",
 		Certificate: "abc",
 		SelfLink:    "existing",
 	})
-	preSharedCert1, _ := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+	preSharedCert1, _ := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 
 	// Prepare pre-shared certs.
 	key.Name = "test-pre-shared-cert2"
-	composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name:        "test-pre-shared-cert2",
 		Certificate: "xyz",
 		SelfLink:    "existing",
 		Version:     defaultVersion,
 	})
-	preSharedCert2, _ := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+	preSharedCert2, _ := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 
 	lbInfo.TLSName = preSharedCert1.Name + "," + preSharedCert2.Name
 
 	// Sync pre-shared certs.
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 	expectCerts := map[string]string{preSharedCert1.Name: preSharedCert1.Certificate,
@@ -833,7 +985,7 @@ func TestPreSharedToSecretBasedCertUpdate(t *testing.T) {
 	// Updates from pre-shared cert to secret based cert.
 	lbInfo.TLS = []*translator.TLSCerts{createCert("key", "cert", "name")}
 	lbInfo.TLSName = ""
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 	expectCerts[certName1] = lbInfo.TLS[0].Cert
@@ -843,7 +995,7 @@ func TestPreSharedToSecretBasedCertUpdate(t *testing.T) {
 
 	// Sync a different cert.
 	lbInfo.TLS = []*translator.TLSCerts{createCert("key2", "cert2", "name")}
-	j.pool.Ensure(lbInfo)
+	j.pool.Ensure(context.TODO(), lbInfo)
 	delete(expectCerts, certName1)
 	expectCerts[certName2] = lbInfo.TLS[0].Cert
 	expectCertsProxy = map[string]string{certName2: lbInfo.TLS[0].Cert}
@@ -851,11 +1003,11 @@ func TestPreSharedToSecretBasedCertUpdate(t *testing.T) {
 
 	// Check if pre-shared certs are retained.
 	key.Name = preSharedCert1.Name
-	if cert, err := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion); err != nil || cert == nil {
+	if cert, err := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion); err != nil || cert == nil {
 		t.Fatalf("Want pre-shared certificate %v to exist, got none, err: %v", preSharedCert1.Name, err)
 	}
 	key.Name = preSharedCert2.Name
-	if cert, err := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion); err != nil || cert == nil {
+	if cert, err := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion); err != nil || cert == nil {
 		t.Fatalf("Want pre-shared certificate %v to exist, got none, err: %v", preSharedCert2.Name, err)
 	}
 }
@@ -869,7 +1021,7 @@ func verifyProxyCertsInOrder(hostname string, j *testJig, t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	tps, err := composite.GetTargetHttpsProxy(j.fakeGCE, key, defaultVersion)
+	tps, err := composite.GetTargetHttpsProxy(context.TODO(), j.fakeGCE, key, defaultVersion)
 	if err != nil {
 		t.Fatalf("expected https proxy to exist: %v, err: %v", TPName, err)
 	}
@@ -879,7 +1031,7 @@ func verifyProxyCertsInOrder(hostname string, j *testJig, t *testing.T) {
 	for _, link := range tps.SslCertificates {
 		certName, _ := utils.KeyName(link)
 		key.Name = certName
-		cert, err := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+		cert, err := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 		if err != nil {
 			t.Fatalf("Failed to fetch certificate from link %s - %v", link, err)
 		}
@@ -908,7 +1060,7 @@ func verifyCertAndProxyLink(expectCerts map[string]string, expectCertsProxy map[
 	if err != nil {
 		t.Fatal(err)
 	}
-	allCerts, err := composite.ListSslCertificates(j.fakeGCE, key, defaultVersion)
+	allCerts, err := composite.ListSslCertificates(context.TODO(), j.fakeGCE, key, defaultVersion)
 	if err != nil {
 		t.Fatalf("Failed to list certificates for %v - %v", j, err)
 	}
@@ -918,7 +1070,7 @@ func verifyCertAndProxyLink(expectCerts map[string]string, expectCertsProxy map[
 	}
 	for certName, certValue := range expectCerts {
 		key.Name = certName
-		cert, err := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+		cert, err := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 		if err != nil {
 			t.Fatalf("expected ssl certificate to exist: %v, err: %v, all certs: %v", certName, err, toCertNames(allCerts))
 		}
@@ -933,7 +1085,7 @@ func verifyCertAndProxyLink(expectCerts map[string]string, expectCertsProxy map[
 	if err != nil {
 		t.Fatal(err)
 	}
-	tps, err := composite.GetTargetHttpsProxy(j.fakeGCE, key, defaultVersion)
+	tps, err := composite.GetTargetHttpsProxy(context.TODO(), j.fakeGCE, key, defaultVersion)
 	if err != nil {
 		// Return immediately if expected certs is an empty map.
 		if len(expectCertsProxy) == 0 && err.(*googleapi.Error).Code == http.StatusNotFound {
@@ -980,19 +1132,202 @@ func TestCreateHTTPSLoadBalancerAnnotationCert(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name: tlsName,
 	})
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
-	l7, err := j.pool.Ensure(lbInfo)
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil || l7 == nil {
 		t.Fatalf("Expected l7 not created")
 	}
 	verifyHTTPSForwardingRuleAndProxyLinks(t, j, l7)
 }
 
+func TestCreateSSLProxyLoadBalancer(t *testing.T) {
+	j := newTestJig(t)
+	fakeSslProxies := j.pool.sslProxies.(*FakeTargetSslProxies)
+
+	gceUrlMap := utils.NewGCEURLMap()
+	gceUrlMap.DefaultBackend = &utils.ServicePort{NodePort: 31234, BackendNamer: j.namer}
+	ing := newIngress()
+	ing.Annotations = map[string]string{annotations.IngressClassKey: annotations.GceSSLProxyIngressClass}
+	lbInfo := &L7RuntimeInfo{
+		AllowHTTP: true,
+		TLS:       []*translator.TLSCerts{createCert("key", "cert", "name")},
+		UrlMap:    gceUrlMap,
+		Ingress:   ing,
+	}
+
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
+	if err != nil || l7 == nil {
+		t.Fatalf("j.pool.Ensure(%v) = %v, %v; want l7, nil", lbInfo, l7, err)
+	}
+	versions := l7.Versions()
+
+	// No url map or http frontend is created even though HTTP is allowed.
+	key, err := composite.CreateKey(j.fakeGCE, l7.namer.UrlMap(), l7.scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := composite.GetUrlMap(context.TODO(), j.fakeGCE, key, versions.UrlMap); !utils.IsNotFoundError(err) {
+		t.Errorf("composite.GetUrlMap(%q) = %v, want not found", key.Name, err)
+	}
+	key.Name = l7.namer.TargetProxy(namer_util.HTTPProtocol)
+	if _, err := composite.GetTargetHttpProxy(context.TODO(), j.fakeGCE, key, versions.TargetHttpProxy); !utils.IsNotFoundError(err) {
+		t.Errorf("composite.GetTargetHttpProxy(%q) = %v, want not found", key.Name, err)
+	}
+
+	proxyName := l7.namer.TargetProxy(namer_util.HTTPSProtocol)
+	proxy, ok := fakeSslProxies.Proxies[proxyName]
+	if !ok {
+		t.Fatalf("target ssl proxy %q not created", proxyName)
+	}
+	if beName := gceUrlMap.DefaultBackend.BackendName(); !strings.HasSuffix(proxy.Service, "/backendServices/"+beName) {
+		t.Errorf("proxy.Service = %q, want backend service %q", proxy.Service, beName)
+	}
+	if len(proxy.SslCertificates) != 1 {
+		t.Errorf("got %d certificates on target ssl proxy, want 1", len(proxy.SslCertificates))
+	}
+
+	key.Name = l7.namer.ForwardingRule(namer_util.HTTPSProtocol)
+	fws, err := composite.GetForwardingRule(context.TODO(), j.fakeGCE, key, versions.ForwardingRule)
+	if err != nil {
+		t.Fatalf("composite.GetForwardingRule(%q) = _, %v; want nil", key.Name, err)
+	}
+	if fws.Target != proxy.SelfLink || fws.PortRange != "443-443" {
+		t.Errorf("fws = %+v, want target %q on port 443", fws, proxy.SelfLink)
+	}
+
+	annotationMap := l7.getFrontendAnnotations(nil)
+	if got := annotationMap[annotations.TargetSslProxyKey]; got != proxyName {
+		t.Errorf("annotation %s = %q, want %q", annotations.TargetSslProxyKey, got, proxyName)
+	}
+	if _, ok := annotationMap[annotations.UrlMapKey]; ok {
+		t.Errorf("unexpected annotation %s", annotations.UrlMapKey)
+	}
+
+	if err := j.pool.GCv2(context.TODO(), ing, l7.scope); err != nil {
+		t.Fatalf("j.pool.GCv2(%v) = %v", ing, err)
+	}
+	if _, ok := fakeSslProxies.Proxies[proxyName]; ok {
+		t.Errorf("target ssl proxy %q not deleted", proxyName)
+	}
+}
+
+func TestSwapURLMap(t *testing.T) {
+	j := newTestJig(t)
+	fakeValidator := j.pool.urlMapValidator.(*FakeUrlMapValidator)
+
+	ing := newIngress()
+	ing.Finalizers = []string{common.FinalizerKeyV2}
+	ing.Annotations = map[string]string{annotations.UrlMapSwapKey: "true"}
+	gceUrlMap := utils.NewGCEURLMap()
+	gceUrlMap.DefaultBackend = &utils.ServicePort{NodePort: 31234, BackendNamer: j.namer}
+	gceUrlMap.PutPathRulesForHost("bar.example.com", []utils.PathRule{{Path: "/bar", Backend: utils.ServicePort{NodePort: 30000, BackendNamer: j.namer}}})
+	lbInfo := &L7RuntimeInfo{AllowHTTP: true, UrlMap: gceUrlMap, Ingress: ing}
+
+	// ensure syncs the load balancer and records its status like the
+	// controller does.
+	ensure := func() (*L7, error) {
+		l7, err := j.pool.Ensure(context.TODO(), lbInfo)
+		if err == nil {
+			ing.Annotations = l7.getFrontendAnnotations(ing.Annotations)
+		}
+		return l7, err
+	}
+	l7, err := ensure()
+	if err != nil {
+		t.Fatalf("j.pool.Ensure() = %v", err)
+	}
+	umName := l7.namer.UrlMap()
+	swapName, _ := l7.namer.SwapUrlMap()
+	urlMapExists := func(name string) bool {
+		key, err := composite.CreateKey(j.fakeGCE, name, defaultScope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = composite.GetUrlMap(context.TODO(), j.fakeGCE, key, defaultVersion)
+		return err == nil
+	}
+	checkActive := func(l7 *L7, active, inactive string) {
+		t.Helper()
+		if l7.um.Name != active {
+			t.Errorf("l7.um.Name = %q, want %q", l7.um.Name, active)
+		}
+		key, err := composite.CreateKey(j.fakeGCE, l7.tp.Name, defaultScope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tp, err := composite.GetTargetHttpProxy(context.TODO(), j.fakeGCE, key, defaultVersion)
+		if err != nil {
+			t.Fatalf("composite.GetTargetHttpProxy(%q) = %v", key.Name, err)
+		}
+		if !strings.HasSuffix(tp.UrlMap, "/urlMaps/"+active) {
+			t.Errorf("tp.UrlMap = %q, want url map %q", tp.UrlMap, active)
+		}
+		if !urlMapExists(active) || urlMapExists(inactive) {
+			t.Errorf("url map %q exists = %t, url map %q exists = %t, want only %q", active, urlMapExists(active), inactive, urlMapExists(inactive), active)
+		}
+	}
+	// The first url map is created in place.
+	checkActive(l7, umName, swapName)
+	if len(fakeValidator.Validated) != 0 {
+		t.Errorf("Validated url maps %v, want none", fakeValidator.Validated)
+	}
+
+	// Changes are applied to the other url map.
+	gceUrlMap.PutPathRulesForHost("foo.example.com", []utils.PathRule{{Path: "/foo", Backend: utils.ServicePort{NodePort: 30001, BackendNamer: j.namer}}})
+	if l7, err = ensure(); err != nil {
+		t.Fatalf("j.pool.Ensure() = %v", err)
+	}
+	checkActive(l7, swapName, umName)
+	if diff := cmp.Diff([]string{swapName}, fakeValidator.Validated); diff != "" {
+		t.Errorf("Validated url maps mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{j.fakeGCE.ProjectID()}, fakeValidator.Projects); diff != "" {
+		t.Errorf("Projects of validated url maps mismatch (-want +got):\n%s", diff)
+	}
+	if hasMap, err := j.pool.HasUrlMap(context.TODO(), ing); err != nil || !hasMap {
+		t.Errorf("j.pool.HasUrlMap() = %t, %v; want true, nil", hasMap, err)
+	}
+
+	// Invalid url maps are not swapped in.
+	fakeValidator.LoadErrors[umName] = []string{"invalid"}
+	gceUrlMap.PutPathRulesForHost("baz.example.com", []utils.PathRule{{Path: "/baz", Backend: utils.ServicePort{NodePort: 30002, BackendNamer: j.namer}}})
+	if _, err := ensure(); err == nil {
+		t.Errorf("j.pool.Ensure() = nil, want error for invalid url map")
+	}
+	if !urlMapExists(swapName) || urlMapExists(umName) {
+		t.Errorf("url map %q was replaced by invalid url map %q", swapName, umName)
+	}
+	delete(fakeValidator.LoadErrors, umName)
+	if l7, err = ensure(); err != nil {
+		t.Fatalf("j.pool.Ensure() = %v", err)
+	}
+	checkActive(l7, umName, swapName)
+
+	// Swap back and stop swapping, the url map returns to its usual name.
+	gceUrlMap.PutPathRulesForHost("qux.example.com", []utils.PathRule{{Path: "/qux", Backend: utils.ServicePort{NodePort: 30003, BackendNamer: j.namer}}})
+	if l7, err = ensure(); err != nil {
+		t.Fatalf("j.pool.Ensure() = %v", err)
+	}
+	checkActive(l7, swapName, umName)
+	delete(ing.Annotations, annotations.UrlMapSwapKey)
+	if l7, err = ensure(); err != nil {
+		t.Fatalf("j.pool.Ensure() = %v", err)
+	}
+	checkActive(l7, umName, swapName)
+
+	if err := j.pool.GCv2(context.TODO(), ing, l7.scope); err != nil {
+		t.Fatalf("j.pool.GCv2() = %v", err)
+	}
+	if urlMapExists(umName) || urlMapExists(swapName) {
+		t.Errorf("url maps not deleted")
+	}
+}
+
 func TestCreateBothLoadBalancers(t *testing.T) {
 	// This should create 2 forwarding rules and target proxies
 	// but they should use the same urlmap, and have the same
@@ -1009,10 +1344,10 @@ func TestCreateBothLoadBalancers(t *testing.T) {
 		Ingress:   newIngress(),
 	}
 
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
-	l7, err := j.pool.Ensure(lbInfo)
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil || l7 == nil {
 		t.Fatalf("Expected l7 not created")
 	}
@@ -1027,9 +1362,9 @@ func TestCreateBothLoadBalancers(t *testing.T) {
 	}
 
 	key.Name = j.feNamer.ForwardingRule(namer_util.HTTPSProtocol)
-	fws, _ := composite.GetForwardingRule(j.fakeGCE, key, defaultVersion)
+	fws, _ := composite.GetForwardingRule(context.TODO(), j.fakeGCE, key, defaultVersion)
 	key.Name = j.feNamer.ForwardingRule(namer_util.HTTPProtocol)
-	fw, _ := composite.GetForwardingRule(j.fakeGCE, key, defaultVersion)
+	fw, _ := composite.GetForwardingRule(context.TODO(), j.fakeGCE, key, defaultVersion)
 	ip, err := j.fakeGCE.GetGlobalAddress(j.feNamer.ForwardingRule(namer_util.HTTPProtocol))
 	if err != nil {
 		t.Fatalf("%v", err)
@@ -1058,7 +1393,7 @@ func TestStaticIP(t *testing.T) {
 		StaticIPName: "teststaticip",
 	}
 
-	if _, err := j.pool.Ensure(lbInfo); err == nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err == nil {
 		t.Fatalf("expected error ensuring ingress with non-existent static ip")
 	}
 	// Create static IP
@@ -1066,7 +1401,7 @@ func TestStaticIP(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ip address reservation failed - %v", err)
 	}
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("unexpected error %v", err)
 	}
 }
@@ -1089,13 +1424,13 @@ func TestFrontendConfigSslPolicy(t *testing.T) {
 		FrontendConfig: &frontendconfigv1beta1.FrontendConfig{Spec: frontendconfigv1beta1.FrontendConfigSpec{SslPolicy: utils.NewStringPointer("test-policy")}},
 	}
 
-	l7, err := j.pool.Ensure(lbInfo)
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil {
 		t.Fatalf("j.pool.Ensure(%v) = %v, want nil", lbInfo, err)
 	}
 
 	tpsName := l7.tps.Name
-	tps, _ := composite.GetTargetHttpsProxy(j.fakeGCE, meta.GlobalKey(tpsName), meta.VersionGA)
+	tps, _ := composite.GetTargetHttpsProxy(context.TODO(), j.fakeGCE, meta.GlobalKey(tpsName), meta.VersionGA)
 
 	resourceID, err := cloud.ParseResourceURL(tps.SslPolicy)
 	if err != nil {
@@ -1108,6 +1443,9 @@ func TestFrontendConfigSslPolicy(t *testing.T) {
 	if path != want {
 		t.Errorf("tps ssl policy = %q, want %q", path, want)
 	}
+	if got := l7.getFrontendAnnotations(nil)[annotations.SslPolicyKey]; got != want {
+		t.Errorf("l7.getFrontendAnnotations()[%q] = %q, want %q", annotations.SslPolicyKey, got, want)
+	}
 }
 
 func TestFrontendConfigRedirects(t *testing.T) {
@@ -1131,7 +1469,7 @@ func TestFrontendConfigRedirects(t *testing.T) {
 		FrontendConfig: &frontendconfigv1beta1.FrontendConfig{Spec: frontendconfigv1beta1.FrontendConfigSpec{RedirectToHttps: &frontendconfigv1beta1.HttpsRedirectConfig{Enabled: true}}},
 	}
 
-	l7, err := j.pool.Ensure(lbInfo)
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil {
 		t.Fatalf("j.pool.Ensure(%v) = %v, want nil", lbInfo, err)
 	}
@@ -1144,7 +1482,7 @@ func TestFrontendConfigRedirects(t *testing.T) {
 	}
 
 	tpName := l7.tp.Name
-	tp, err := composite.GetTargetHttpProxy(j.fakeGCE, meta.GlobalKey(tpName), meta.VersionGA)
+	tp, err := composite.GetTargetHttpProxy(context.TODO(), j.fakeGCE, meta.GlobalKey(tpName), meta.VersionGA)
 	if err != nil {
 		t.Error(err)
 	}
@@ -1216,17 +1554,17 @@ func TestEnsureSslPolicy(t *testing.T) {
 
 	for _, tc := range testCases {
 		key := meta.GlobalKey(tc.proxy.Name)
-		if err := composite.CreateTargetHttpsProxy(j.fakeGCE, key, tc.proxy); err != nil {
+		if err := composite.CreateTargetHttpsProxy(context.TODO(), j.fakeGCE, key, tc.proxy); err != nil {
 			t.Error(err)
 		}
-		l7 := L7{runtimeInfo: &L7RuntimeInfo{FrontendConfig: tc.fc}, cloud: j.fakeGCE, scope: meta.Global}
+		l7 := L7{runtimeInfo: &L7RuntimeInfo{FrontendConfig: tc.fc}, cloud: j.fakeGCE, compositeCloud: composite.NewCloud(j.fakeGCE), scope: meta.Global}
 		env := &translator.Env{FrontendConfig: tc.fc}
 
-		if err := l7.ensureSslPolicy(env, tc.proxy, tc.policyLink); err != nil {
+		if err := l7.ensureSslPolicy(context.TODO(), env, tc.proxy, tc.policyLink); err != nil {
 			t.Errorf("desc: %q, l7.ensureSslPolicy() = %v, want nil", tc.desc, err)
 		}
 
-		result, err := composite.GetTargetHttpsProxy(j.fakeGCE, key, meta.VersionGA)
+		result, err := composite.GetTargetHttpsProxy(context.TODO(), j.fakeGCE, key, meta.VersionGA)
 		if err != nil {
 			t.Error(err)
 		}
@@ -1246,11 +1584,11 @@ func verifyURLMap(t *testing.T, j *testJig, feNamer namer_util.IngressFrontendNa
 	if err != nil {
 		t.Fatal(err)
 	}
-	um, err := composite.GetUrlMap(j.fakeGCE, key, defaultVersion)
+	um, err := composite.GetUrlMap(context.TODO(), j.fakeGCE, key, defaultVersion)
 	if err != nil || um == nil {
 		t.Errorf("j.fakeGCE.GetUrlMap(%q) = %v, %v; want _, nil", name, um, err)
 	}
-	wantComputeURLMap := translator.ToCompositeURLMap(wantGCEURLMap, feNamer, key)
+	wantComputeURLMap := urlmaps.ToCompositeURLMap(wantGCEURLMap, feNamer, key)
 	if !mapsEqual(wantComputeURLMap, um) {
 		t.Errorf("mapsEqual() = false, got\n%+v\n  want\n%+v", um, wantComputeURLMap)
 	}
@@ -1274,11 +1612,11 @@ func TestUrlMapChange(t *testing.T) {
 
 	lbInfo := &L7RuntimeInfo{AllowHTTP: true, UrlMap: um1, Ingress: newIngress()}
 
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 
-	l7, err := j.pool.Ensure(lbInfo)
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil {
 		t.Fatalf("%v", err)
 	}
@@ -1286,10 +1624,10 @@ func TestUrlMapChange(t *testing.T) {
 
 	// Change url map.
 	lbInfo.UrlMap = um2
-	if _, err = j.pool.Ensure(lbInfo); err != nil {
+	if _, err = j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
-	l7, err = j.pool.Ensure(lbInfo)
+	l7, err = j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil {
 		t.Fatalf("%v", err)
 	}
@@ -1327,12 +1665,12 @@ func TestPoolSyncNoChanges(t *testing.T) {
 	um2.DefaultBackend = &utils.ServicePort{NodePort: 30003, BackendNamer: j.namer}
 
 	lbInfo := &L7RuntimeInfo{AllowHTTP: true, UrlMap: um1, Ingress: newIngress()}
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 
 	lbInfo.UrlMap = um2
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 
@@ -1372,10 +1710,10 @@ func TestClusterNameChange(t *testing.T) {
 		UrlMap:    gceUrlMap,
 		Ingress:   newIngress(),
 	}
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("j.pool.Ensure() = err %v", err)
 	}
-	l7, err := j.pool.Ensure(lbInfo)
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil || l7 == nil {
 		t.Fatalf("Expected l7 not created")
 	}
@@ -1386,7 +1724,7 @@ func TestClusterNameChange(t *testing.T) {
 	j.namer.SetUID(newName)
 
 	// Now the components should get renamed with the next suffix.
-	l7, err = j.pool.Ensure(lbInfo)
+	l7, err = j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil || j.namer.ParseName(l7.namer.LoadBalancer().String()).ClusterName != newName {
 		t.Fatalf("Expected L7 name to change.")
 	}
@@ -1418,10 +1756,10 @@ func createCert(key string, contents string, name string) *translator.TLSCerts {
 }
 
 func syncPool(j *testJig, t *testing.T, lbInfo *L7RuntimeInfo) {
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("j.pool.Ensure() = err %v", err)
 	}
-	l7, err := j.pool.Ensure(lbInfo)
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 	if err != nil || l7 == nil {
 		t.Fatalf("Expected l7 not created")
 	}
@@ -1453,14 +1791,14 @@ func TestList(t *testing.T) {
 	}
 	for _, name := range names {
 		key.Name = name
-		composite.CreateUrlMap(j.fakeGCE, key, &composite.UrlMap{Name: name})
+		composite.CreateUrlMap(context.TODO(), j.fakeGCE, key, &composite.UrlMap{Name: name})
 	}
 
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("j.pool.Ensure() = %v; want nil", err)
 	}
 
-	urlMaps, err := j.pool.list(key, defaultVersion)
+	urlMaps, err := j.pool.list(context.TODO(), key, defaultVersion)
 	if err != nil {
 		t.Fatalf("j.pool.List(%q, %q) = %v, want nil", key, defaultVersion, err)
 	}
@@ -1503,20 +1841,20 @@ func TestSecretBasedAndPreSharedCerts(t *testing.T) {
 		t.Fatal(err)
 	}
 	key.Name = "test-pre-shared-cert"
-	composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name:        "test-pre-shared-cert",
 		Certificate: "abc",
 		SelfLink:    "existing",
 	})
-	preSharedCert1, _ := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+	preSharedCert1, _ := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 
 	key.Name = "test-pre-shared-cert2"
-	composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name:        "test-pre-shared-cert2",
 		Certificate: "xyz",
 		SelfLink:    "existing2",
 	})
-	preSharedCert2, _ := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+	preSharedCert2, _ := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 	lbInfo.TLSName = preSharedCert1.Name + "," + preSharedCert2.Name
 
 	// Secret based certs.
@@ -1533,7 +1871,7 @@ func TestSecretBasedAndPreSharedCerts(t *testing.T) {
 	}
 
 	// Verify that both secret-based and pre-shared certs are used.
-	j.pool.Ensure(lbInfo)
+	j.pool.Ensure(context.TODO(), lbInfo)
 	verifyCertAndProxyLink(expectCerts, expectCerts, j, t)
 }
 
@@ -1569,7 +1907,7 @@ func TestMaxSecretBasedAndPreSharedCerts(t *testing.T) {
 		Ingress:   ing,
 	}
 
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("j.pool.Ensure() = err %v", err)
 	}
 	verifyCertAndProxyLink(expectCerts, expectCerts, j, t)
@@ -1584,7 +1922,7 @@ func TestMaxSecretBasedAndPreSharedCerts(t *testing.T) {
 	for ix := TargetProxyCertLimit; ix < FakeCertQuota; ix++ {
 		str := strconv.Itoa(ix)
 		key.Name = "test-pre-shared-cert-" + str
-		err := composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+		err := composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 			Name:        "test-pre-shared-cert-" + str,
 			Certificate: "abc-" + str,
 			SelfLink:    "existing-" + str,
@@ -1592,7 +1930,7 @@ func TestMaxSecretBasedAndPreSharedCerts(t *testing.T) {
 		if err != nil {
 			t.Fatalf("j.fakeGCE.CreateSslCertificate() = err %v", err)
 		}
-		cert, _ := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+		cert, _ := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 		preSharedCerts = append(preSharedCerts, cert)
 		tlsNames = append(tlsNames, cert.Name)
 		expectCertsExtra[cert.Name] = cert.Certificate
@@ -1600,7 +1938,7 @@ func TestMaxSecretBasedAndPreSharedCerts(t *testing.T) {
 	lbInfo.TLSName = strings.Join(tlsNames, ",")
 
 	key.Name = "test-pre-shared-cert-100"
-	err = composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	err = composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name:        "test-pre-shared-cert-100",
 		Certificate: "abc-100",
 		SelfLink:    "existing-100",
@@ -1612,7 +1950,7 @@ func TestMaxSecretBasedAndPreSharedCerts(t *testing.T) {
 	// Trying to use more than TargetProxyCertLimit certs should fail.
 	// Verify that secret-based certs are still used,
 	// and the loadbalancer also contains pre-shared certs.
-	if _, err := j.pool.Ensure(lbInfo); err == nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err == nil {
 		t.Fatalf("Trying to use more than %d certs should have errored out", TargetProxyCertLimit)
 	}
 	verifyCertAndProxyLink(expectCertsExtra, expectCerts, j, t)
@@ -1627,7 +1965,7 @@ func TestMaxSecretBasedAndPreSharedCerts(t *testing.T) {
 		expectCerts[cert.Name] = cert.Certificate
 	}
 
-	if _, err = j.pool.Ensure(lbInfo); err != nil {
+	if _, err = j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("j.pool.Ensure() = err %v", err)
 	}
 	verifyCertAndProxyLink(expectCerts, expectCerts, j, t)
@@ -1655,7 +1993,7 @@ func TestSecretBasedToPreSharedCertUpdate(t *testing.T) {
 	// Sync secret based cert.
 	lbInfo.TLS = []*translator.TLSCerts{createCert("key", "cert", "name")}
 	lbInfo.TLSName = ""
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 	expectCerts := map[string]string{certName1: lbInfo.TLS[0].Cert}
@@ -1666,16 +2004,16 @@ func TestSecretBasedToPreSharedCertUpdate(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name:        "test-pre-shared-cert",
 		Certificate: "abc",
 		SelfLink:    "existing",
 	})
-	preSharedCert1, _ := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+	preSharedCert1, _ := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 	lbInfo.TLSName = preSharedCert1.Name
 
 	// Sync certs.
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("j.pool.Ensure() = err %v", err)
 	}
 	expectCerts[preSharedCert1.Name] = preSharedCert1.Certificate
@@ -1683,7 +2021,7 @@ func TestSecretBasedToPreSharedCertUpdate(t *testing.T) {
 
 	// Delete the secret.
 	lbInfo.TLS = []*translator.TLSCerts{}
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 	delete(expectCerts, certName1)
@@ -1711,7 +2049,7 @@ func TestSecretBasedToPreSharedCertUpdateWithErrors(t *testing.T) {
 	// Sync secret based cert.
 	lbInfo.TLS = []*translator.TLSCerts{createCert("key", "cert", "name")}
 	lbInfo.TLSName = ""
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 	expectCerts := map[string]string{certName1: lbInfo.TLS[0].Cert}
@@ -1722,16 +2060,16 @@ func TestSecretBasedToPreSharedCertUpdateWithErrors(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	composite.CreateSslCertificate(j.fakeGCE, key, &composite.SslCertificate{
+	composite.CreateSslCertificate(context.TODO(), j.fakeGCE, key, &composite.SslCertificate{
 		Name:        "test-pre-shared-cert",
 		Certificate: "abc",
 		SelfLink:    "existing",
 	})
-	preSharedCert1, _ := composite.GetSslCertificate(j.fakeGCE, key, defaultVersion)
+	preSharedCert1, _ := composite.GetSslCertificate(context.TODO(), j.fakeGCE, key, defaultVersion)
 
 	// Typo in the cert name.
 	lbInfo.TLSName = preSharedCert1.Name + "typo"
-	if _, err := j.pool.Ensure(lbInfo); err == nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err == nil {
 		t.Fatalf("pool.Ensure() should have errored out because of the wrong cert name")
 	}
 	expectCertsProxy := map[string]string{certName1: lbInfo.TLS[0].Cert}
@@ -1741,12 +2079,57 @@ func TestSecretBasedToPreSharedCertUpdateWithErrors(t *testing.T) {
 
 	// Fix the typo.
 	lbInfo.TLSName = preSharedCert1.Name
-	if _, err := j.pool.Ensure(lbInfo); err != nil {
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
 		t.Fatalf("pool.Ensure() = err %v", err)
 	}
 	verifyCertAndProxyLink(expectCerts, expectCerts, j, t)
 }
 
+func TestCrossProjectPreSharedCert(t *testing.T) {
+	j := newTestJig(t)
+
+	gceUrlMap := utils.NewGCEURLMap()
+	gceUrlMap.DefaultBackend = &utils.ServicePort{NodePort: 31234, BackendNamer: j.namer}
+	ing := newIngress()
+	feNamer := namer_util.NewFrontendNamerFactory(j.namer, "").Namer(ing)
+	certLink := "https://www.googleapis.com/compute/v1/projects/security/global/sslCertificates/shared"
+	lbInfo := &L7RuntimeInfo{
+		AllowHTTP: false,
+		UrlMap:    gceUrlMap,
+		Ingress:   ing,
+		TLSName:   "projects/security/global/sslCertificates/shared",
+	}
+	sharedCerts := j.pool.sharedSslCerts.(*FakeSharedSslCertificates)
+
+	// The certificate does not exist.
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err == nil {
+		t.Fatalf("pool.Ensure() = nil, want error for missing certificate")
+	}
+
+	// The controller is not allowed to get the certificate.
+	sharedCerts.Certs["projects/security/global/sslCertificates/shared"] = &composite.SslCertificate{Name: "shared", SelfLink: certLink}
+	sharedCerts.Forbidden["security"] = true
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err == nil {
+		t.Fatalf("pool.Ensure() = nil, want error for forbidden certificate")
+	}
+
+	sharedCerts.Forbidden["security"] = false
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
+		t.Fatalf("pool.Ensure() = %v", err)
+	}
+	key, err := composite.CreateKey(j.fakeGCE, feNamer.TargetProxy(namer_util.HTTPSProtocol), defaultScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy, err := composite.GetTargetHttpsProxy(context.TODO(), j.fakeGCE, key, defaultVersion)
+	if err != nil {
+		t.Fatalf("GetTargetHttpsProxy() = %v", err)
+	}
+	if diff := cmp.Diff([]string{certLink}, proxy.SslCertificates); diff != "" {
+		t.Errorf("Got diff for target proxy certificates (-want +got):\n%s", diff)
+	}
+}
+
 // TestResourceDeletionWithProtocol asserts that unused resources are cleaned up
 // on updating ingress configuration to disable http/https traffic.
 func TestResourceDeletionWithProtocol(t *testing.T) {
@@ -1781,7 +2164,7 @@ func TestResourceDeletionWithProtocol(t *testing.T) {
 				UrlMap:  gceUrlMap,
 				Ingress: ing,
 			}
-			lb, err := j.pool.Ensure(lbInfo)
+			lb, err := j.pool.Ensure(context.TODO(), lbInfo)
 			if err != nil {
 				t.Fatalf("pool.Ensure(%+v) = %v, want nil", lbInfo, err)
 			}
@@ -1806,7 +2189,7 @@ func TestResourceDeletionWithProtocol(t *testing.T) {
 				delete(expectCerts, certName1)
 			}
 
-			lb, err = j.pool.Ensure(lbInfo)
+			lb, err = j.pool.Ensure(context.TODO(), lbInfo)
 			if tc.disableHTTP && tc.disableHTTPS {
 				// we expect an invalid ingress configuration error here.
 				if err == nil || !strings.Contains(err.Error(), invalidConfigErrorMessage) {
@@ -1829,6 +2212,161 @@ func TestResourceDeletionWithProtocol(t *testing.T) {
 	}
 }
 
+// TestHTTPSOnlyKeepsIP asserts that an Ingress which switches from HTTPS-only
+// to serving both protocols keeps its IP.
+func TestHTTPSOnlyKeepsIP(t *testing.T) {
+	j := newTestJig(t)
+	// Hand out a different ephemeral IP to each forwarding rule.
+	ips := 0
+	j.mock.MockGlobalForwardingRules.InsertHook = func(ctx context.Context, key *meta.Key, obj *compute.ForwardingRule, m *cloud.MockGlobalForwardingRules) (bool, error) {
+		if obj.IPAddress == "" {
+			ips++
+			obj.IPAddress = fmt.Sprintf("0.0.0.%d", ips)
+		}
+		return false, nil
+	}
+
+	gceUrlMap := utils.NewGCEURLMap()
+	gceUrlMap.DefaultBackend = &utils.ServicePort{NodePort: 31234, BackendNamer: j.namer}
+	lbInfo := &L7RuntimeInfo{
+		AllowHTTP: false,
+		TLS:       []*translator.TLSCerts{createCert("key", "cert", "name")},
+		UrlMap:    gceUrlMap,
+		Ingress:   newIngress(),
+	}
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
+		t.Fatalf("pool.Ensure(%+v) = %v, want nil", lbInfo, err)
+	}
+	key, err := composite.CreateKey(j.fakeGCE, j.feNamer.ForwardingRule(namer_util.HTTPSProtocol), defaultScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fws, err := composite.GetForwardingRule(context.TODO(), j.fakeGCE, key, defaultVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantIP := fws.IPAddress
+
+	lbInfo.AllowHTTP = true
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
+		t.Fatalf("pool.Ensure(%+v) = %v, want nil", lbInfo, err)
+	}
+	for _, protocol := range []namer_util.NamerProtocol{namer_util.HTTPProtocol, namer_util.HTTPSProtocol} {
+		key.Name = j.feNamer.ForwardingRule(protocol)
+		fr, err := composite.GetForwardingRule(context.TODO(), j.fakeGCE, key, defaultVersion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fr.IPAddress != wantIP {
+			t.Errorf("Forwarding rule %s has IP %q, want %q", fr.Name, fr.IPAddress, wantIP)
+		}
+	}
+}
+
+// TestRecreateForwardingRuleKeepsIP asserts that a forwarding rule which is
+// recreated to change an immutable field keeps its ephemeral IP, which is
+// reserved as the static IP of the Ingress.
+func TestRecreateForwardingRuleKeepsIP(t *testing.T) {
+	j := newTestJig(t)
+	j.mock.MockGlobalForwardingRules.InsertHook = func(ctx context.Context, key *meta.Key, obj *compute.ForwardingRule, m *cloud.MockGlobalForwardingRules) (bool, error) {
+		if obj.IPAddress == "" {
+			obj.IPAddress = "0.0.0.1"
+		}
+		return false, nil
+	}
+
+	gceUrlMap := utils.NewGCEURLMap()
+	gceUrlMap.DefaultBackend = &utils.ServicePort{NodePort: 31234, BackendNamer: j.namer}
+	lbInfo := &L7RuntimeInfo{
+		AllowHTTP: true,
+		UrlMap:    gceUrlMap,
+		Ingress:   newIngress(),
+	}
+	if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
+		t.Fatalf("pool.Ensure(%+v) = %v, want nil", lbInfo, err)
+	}
+	key, err := composite.CreateKey(j.fakeGCE, j.feNamer.ForwardingRule(namer_util.HTTPProtocol), defaultScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw, err := composite.GetForwardingRule(context.TODO(), j.fakeGCE, key, defaultVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a forwarding rule whose port range differs, e.g. one created
+	// by an older version of the controller.
+	if err := composite.DeleteForwardingRule(context.TODO(), j.fakeGCE, key, defaultVersion); err != nil {
+		t.Fatal(err)
+	}
+	fw.IPAddress = "0.0.0.2"
+	fw.PortRange = "8080-8080"
+	if err := composite.CreateForwardingRule(context.TODO(), j.fakeGCE, key, fw); err != nil {
+		t.Fatal(err)
+	}
+
+	l7, err := j.pool.Ensure(context.TODO(), lbInfo)
+	if err != nil {
+		t.Fatalf("pool.Ensure(%+v) = %v, want nil", lbInfo, err)
+	}
+	fw, err = composite.GetForwardingRule(context.TODO(), j.fakeGCE, key, defaultVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fw.IPAddress != "0.0.0.2" || fw.PortRange != "80-80" {
+		t.Errorf("Forwarding rule has IP %q and port range %q, want %q and %q", fw.IPAddress, fw.PortRange, "0.0.0.2", "80-80")
+	}
+	ip, err := composite.GetAddress(context.TODO(), j.fakeGCE, key, meta.VersionGA)
+	if err != nil {
+		t.Fatalf("GetAddress(%v) = %v, want the reserved static IP", key, err)
+	}
+	if ip.Address != "0.0.0.2" {
+		t.Errorf("Static IP %v has address %q, want %q", ip.Name, ip.Address, "0.0.0.2")
+	}
+	if l7.ip == nil || l7.ip.Name != ip.Name {
+		t.Errorf("l7.ip = %+v, want static IP %v", l7.ip, ip.Name)
+	}
+}
+
+// TestHTTPSOnlyDeletesHTTPFrontend asserts that the HTTP frontend is deleted
+// once HTTP is disabled, even if the Ingress annotations do not record it, but
+// only if deleting unused frontends is enabled.
+func TestHTTPSOnlyDeletesHTTPFrontend(t *testing.T) {
+	oldFlag := flags.F.EnableDeleteUnusedFrontends
+	defer func() { flags.F.EnableDeleteUnusedFrontends = oldFlag }()
+
+	for _, deleteUnused := range []bool{true, false} {
+		t.Run(fmt.Sprintf("EnableDeleteUnusedFrontends=%t", deleteUnused), func(t *testing.T) {
+			flags.F.EnableDeleteUnusedFrontends = deleteUnused
+			j := newTestJig(t)
+
+			gceUrlMap := utils.NewGCEURLMap()
+			gceUrlMap.DefaultBackend = &utils.ServicePort{NodePort: 31234, BackendNamer: j.namer}
+			ing := newIngress()
+			lbInfo := &L7RuntimeInfo{
+				AllowHTTP: true,
+				TLS:       []*translator.TLSCerts{createCert("key", "cert", "name")},
+				UrlMap:    gceUrlMap,
+				Ingress:   ing,
+			}
+			if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
+				t.Fatalf("pool.Ensure(%+v) = %v, want nil", lbInfo, err)
+			}
+			if err := checkBothFakeLoadBalancers(j.fakeGCE, j.feNamer, features.GAResourceVersions, defaultScope, true, true); err != nil {
+				t.Fatalf("checkBothFakeLoadBalancers(..., true, true) = %v, want nil", err)
+			}
+
+			lbInfo.AllowHTTP = false
+			if _, err := j.pool.Ensure(context.TODO(), lbInfo); err != nil {
+				t.Fatalf("pool.Ensure(%+v) = %v, want nil", lbInfo, err)
+			}
+			wantHTTP := !deleteUnused
+			if err := checkBothFakeLoadBalancers(j.fakeGCE, j.feNamer, features.GAResourceVersions, defaultScope, wantHTTP, true); err != nil {
+				t.Errorf("checkBothFakeLoadBalancers(..., %t, true) = %v, want nil", wantHTTP, err)
+			}
+		})
+	}
+}
+
 // TestResourceDeletionWithScopeChange asserts that unused resources are cleaned up
 // on updating ingress configuration to change from ELB to ILB or vice versa.
 // This test applies to the V2 naming scheme only
@@ -1885,31 +2423,31 @@ func TestResourceDeletionWithScopeChange(t *testing.T) {
 			}
 
 			tc.ing.Annotations[annotations.IngressClassKey] = tc.beforeClass
-			l7, err := j.pool.Ensure(lbInfo)
+			l7, err := j.pool.Ensure(context.TODO(), lbInfo)
 			if err != nil || l7 == nil {
 				t.Fatalf("Expected l7 not created, err: %v", err)
 			}
 			verifyHTTPForwardingRuleAndProxyLinks(t, j, l7, "")
 
 			tc.ing.Annotations[annotations.IngressClassKey] = tc.afterClass
-			l7, err = j.pool.Ensure(lbInfo)
+			l7, err = j.pool.Ensure(context.TODO(), lbInfo)
 			if err != nil || l7 == nil {
 				t.Fatalf("Expected l7 not created, err: %v", err)
 			}
 			verifyHTTPForwardingRuleAndProxyLinks(t, j, l7, "")
 
 			// Check to make sure that there is something to GC
-			scope, err := j.pool.FrontendScopeChangeGC(tc.ing)
+			scope, err := j.pool.FrontendScopeChangeGC(context.TODO(), tc.ing)
 			if scope == nil || *scope != tc.gcScope || err != nil {
 				t.Errorf("FrontendScopeChangeGC(%v) = (%v, %v), want (%q, nil)", tc.ing, scope, err, tc.gcScope)
 			}
 
-			if err := j.pool.GCv2(tc.ing, tc.gcScope); err != nil {
+			if err := j.pool.GCv2(context.TODO(), tc.ing, tc.gcScope); err != nil {
 				t.Errorf("GCv2(%v, %q) = %v, want nil", tc.ing, tc.gcScope, err)
 			}
 
 			// Check to make sure that there is nothing to GC
-			scope, err = j.pool.FrontendScopeChangeGC(tc.ing)
+			scope, err = j.pool.FrontendScopeChangeGC(context.TODO(), tc.ing)
 			if scope != nil || err != nil {
 				t.Errorf("FrontendScopeChangeGC(%v) = (%v, %v), want (nil, nil)", tc.ing, scope, err)
 			}
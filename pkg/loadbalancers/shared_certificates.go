@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+// SslCertificateNotAccessible is the reason of the events of Ingresses which
+// reference a pre-shared certificate of another project which the controller
+// is not allowed to get.
+const SslCertificateNotAccessible = "SslCertificateNotAccessible"
+
+// SharedSslCertificates gets SSL certificates of any project.
+type SharedSslCertificates interface {
+	Get(ctx context.Context, project string, key *meta.Key) (*composite.SslCertificate, error)
+}
+
+// NewSharedSslCertificates returns SharedSslCertificates backed by the
+// given composite cloud, whose calls are rate limited and metered.
+func NewSharedSslCertificates(compositeCloud composite.Cloud) SharedSslCertificates {
+	return &sharedSslCertificates{compositeCloud: compositeCloud}
+}
+
+type sharedSslCertificates struct {
+	compositeCloud composite.Cloud
+}
+
+func (c *sharedSslCertificates) Get(ctx context.Context, project string, key *meta.Key) (*composite.SslCertificate, error) {
+	return c.compositeCloud.GetSslCertificateOfProject(ctx, project, key)
+}
+
+// checkSharedSslCerts checks that the pre-shared certificates of other
+// projects exist and can be read by the controller, which GCE requires to
+// attach them to the target proxies. Failures are reported as events of the
+// Ingress.
+func (l *L7) checkSharedSslCerts(ctx context.Context, certs []*composite.SslCertificate) error {
+	var errs []error
+	for _, cert := range certs {
+		// Certificates of Secrets are created in the project of the
+		// load balancer.
+		if cert.Certificate != "" {
+			continue
+		}
+		resID, err := cloud.ParseResourceURL(cert.SelfLink)
+		if err != nil || resID.ProjectID == l.cloud.ProjectID() {
+			continue
+		}
+		_, err = l.sharedSslCerts.Get(ctx, resID.ProjectID, resID.Key)
+		switch {
+		case err == nil:
+			continue
+		case utils.IsForbiddenError(err):
+			err = fmt.Errorf("the controller is not allowed to get pre-shared certificate %s of project %q, grant its service account compute.sslCertificates.get in that project: %v", resID.Key.Name, resID.ProjectID, err)
+			l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeWarning, SslCertificateNotAccessible, err.Error())
+		case utils.IsNotFoundError(err):
+			err = fmt.Errorf("pre-shared certificate %s of project %q does not exist", resID.Key.Name, resID.ProjectID)
+			l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeWarning, SslCertificateMissing, err.Error())
+		default:
+			err = fmt.Errorf("failed to get pre-shared certificate %s of project %q: %v", resID.Key.Name, resID.ProjectID, err)
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return utils.JoinErrs(errs)
+	}
+	return nil
+}
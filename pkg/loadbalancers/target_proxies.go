@@ -17,6 +17,8 @@ limitations under the License.
 package loadbalancers
 
 import (
+	"context"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/events"
@@ -33,7 +35,7 @@ const (
 )
 
 // checkProxy ensures the correct TargetHttpProxy for a loadbalancer
-func (l *L7) checkProxy() (err error) {
+func (l *L7) checkProxy(ctx context.Context) (err error) {
 	// Get UrlMap Name, could be the url map or the redirect url map
 	// TODO(shance): move to translator
 	var umName string
@@ -69,17 +71,17 @@ func (l *L7) checkProxy() (err error) {
 		return err
 	}
 
-	currentProxy, _ := composite.GetTargetHttpProxy(l.cloud, key, version)
+	currentProxy, _ := l.compositeCloud.GetTargetHttpProxy(ctx, key, version)
 	if currentProxy == nil {
 		klog.V(3).Infof("Creating new http proxy for urlmap %v", l.um.Name)
 		key, err := l.CreateKey(proxy.Name)
 		if err != nil {
 			return err
 		}
-		if err = composite.CreateTargetHttpProxy(l.cloud, key, proxy); err != nil {
+		if err = l.compositeCloud.CreateTargetHttpProxy(ctx, key, proxy); err != nil {
 			return err
 		}
-		currentProxy, err = composite.GetTargetHttpProxy(l.cloud, key, version)
+		currentProxy, err = l.compositeCloud.GetTargetHttpProxy(ctx, key, version)
 		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "TargetProxy %q created", key.Name)
 		if err != nil {
 			return err
@@ -94,7 +96,7 @@ func (l *L7) checkProxy() (err error) {
 		if err != nil {
 			return err
 		}
-		if err := composite.SetUrlMapForTargetHttpProxy(l.cloud, key, currentProxy, proxy.UrlMap); err != nil {
+		if err := l.compositeCloud.SetUrlMapForTargetHttpProxy(ctx, key, currentProxy, proxy.UrlMap); err != nil {
 			return err
 		}
 		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "TargetProxy %q updated", key.Name)
@@ -103,7 +105,7 @@ func (l *L7) checkProxy() (err error) {
 	return nil
 }
 
-func (l *L7) checkHttpsProxy() (err error) {
+func (l *L7) checkHttpsProxy(ctx context.Context) (err error) {
 	isL7ILB := utils.IsGCEL7ILBIngress(l.runtimeInfo.Ingress)
 	tr := translator.NewTranslator(isL7ILB, l.namer)
 	env := &translator.Env{FrontendConfig: l.runtimeInfo.FrontendConfig}
@@ -118,7 +120,7 @@ func (l *L7) checkHttpsProxy() (err error) {
 		return err
 	}
 	description, err := l.description()
-	version := l.Versions().TargetHttpProxy
+	version := l.Versions().TargetHttpsProxy
 	proxy, sslPolicySet, err := tr.ToCompositeTargetHttpsProxy(env, description, version, urlMapKey, l.sslCerts)
 	if err != nil {
 		return err
@@ -129,7 +131,7 @@ func (l *L7) checkHttpsProxy() (err error) {
 		return err
 	}
 
-	currentProxy, _ := composite.GetTargetHttpsProxy(l.cloud, key, version)
+	currentProxy, _ := l.compositeCloud.GetTargetHttpsProxy(ctx, key, version)
 	if err != nil {
 		return err
 	}
@@ -137,7 +139,7 @@ func (l *L7) checkHttpsProxy() (err error) {
 	if currentProxy == nil {
 		klog.V(3).Infof("Creating new https Proxy for urlmap %q", l.um.Name)
 
-		if err = composite.CreateTargetHttpsProxy(l.cloud, key, proxy); err != nil {
+		if err = l.compositeCloud.CreateTargetHttpsProxy(ctx, key, proxy); err != nil {
 			return err
 		}
 		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "TargetProxy %q created", key.Name)
@@ -146,7 +148,7 @@ func (l *L7) checkHttpsProxy() (err error) {
 		if err != nil {
 			return err
 		}
-		currentProxy, err = composite.GetTargetHttpsProxy(l.cloud, key, version)
+		currentProxy, err = l.compositeCloud.GetTargetHttpsProxy(ctx, key, version)
 		if err != nil {
 			return err
 		}
@@ -161,7 +163,7 @@ func (l *L7) checkHttpsProxy() (err error) {
 		if err != nil {
 			return err
 		}
-		if err := composite.SetUrlMapForTargetHttpsProxy(l.cloud, key, currentProxy, proxy.UrlMap); err != nil {
+		if err := l.compositeCloud.SetUrlMapForTargetHttpsProxy(ctx, key, currentProxy, proxy.UrlMap); err != nil {
 			return err
 		}
 		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "TargetProxy %q updated", key.Name)
@@ -178,14 +180,14 @@ func (l *L7) checkHttpsProxy() (err error) {
 		if err != nil {
 			return err
 		}
-		if err := composite.SetSslCertificateForTargetHttpsProxy(l.cloud, key, currentProxy, sslCertURLs); err != nil {
+		if err := l.compositeCloud.SetSslCertificateForTargetHttpsProxy(ctx, key, currentProxy, sslCertURLs); err != nil {
 			return err
 		}
 		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "TargetProxy %q certs updated", key.Name)
 	}
 
 	if flags.F.EnableFrontendConfig && sslPolicySet {
-		if err := l.ensureSslPolicy(env, currentProxy, proxy.SslPolicy); err != nil {
+		if err := l.ensureSslPolicy(ctx, env, currentProxy, proxy.SslPolicy); err != nil {
 			return err
 		}
 	}
@@ -194,13 +196,13 @@ func (l *L7) checkHttpsProxy() (err error) {
 	return nil
 }
 
-func (l *L7) getSslCertLinkInUse() ([]string, error) {
+func (l *L7) getSslCertLinkInUse(ctx context.Context) ([]string, error) {
 	proxyName := l.namer.TargetProxy(namer.HTTPSProtocol)
 	key, err := l.CreateKey(proxyName)
 	if err != nil {
 		return nil, err
 	}
-	proxy, err := composite.GetTargetHttpsProxy(l.cloud, key, l.Versions().TargetHttpsProxy)
+	proxy, err := l.compositeCloud.GetTargetHttpsProxy(ctx, key, l.Versions().TargetHttpsProxy)
 	if err != nil {
 		return nil, err
 	}
@@ -210,16 +212,17 @@ func (l *L7) getSslCertLinkInUse() ([]string, error) {
 
 // ensureSslPolicy ensures that the SslPolicy described in the frontendconfig is
 // properly applied to the proxy.
-func (l *L7) ensureSslPolicy(env *translator.Env, currentProxy *composite.TargetHttpsProxy, policyLink string) error {
+func (l *L7) ensureSslPolicy(ctx context.Context, env *translator.Env, currentProxy *composite.TargetHttpsProxy, policyLink string) error {
 	if !utils.EqualResourceIDs(policyLink, currentProxy.SslPolicy) {
 		key, err := l.CreateKey(currentProxy.Name)
 		if err != nil {
 			return err
 		}
-		if err := composite.SetSslPolicyForTargetHttpsProxy(l.cloud, key, currentProxy, policyLink); err != nil {
+		if err := l.compositeCloud.SetSslPolicyForTargetHttpsProxy(ctx, key, currentProxy, policyLink); err != nil {
 			l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "TargetProxy %q SSLPolicy updated", key.Name)
 			return err
 		}
+		currentProxy.SslPolicy = policyLink
 	}
 	return nil
 }
@@ -0,0 +1,272 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/compute/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/ingress-gce/pkg/annotations"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/events"
+	"k8s.io/ingress-gce/pkg/flags"
+	"k8s.io/ingress-gce/pkg/loadbalancers/features"
+	"k8s.io/ingress-gce/pkg/utils"
+	"k8s.io/ingress-gce/pkg/utils/namer"
+	"k8s.io/klog"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+// TargetSslProxies manages global target SSL proxies. These are not
+// available through the composite types.
+type TargetSslProxies interface {
+	Get(ctx context.Context, name string) (*compute.TargetSslProxy, error)
+	Insert(ctx context.Context, proxy *compute.TargetSslProxy) error
+	SetBackendService(ctx context.Context, name, backendServiceLink string) error
+	SetSslCertificates(ctx context.Context, name string, certLinks []string) error
+	Delete(ctx context.Context, name string) error
+}
+
+// NewTargetSslProxies returns TargetSslProxies backed by the GA compute API
+// of the given cloud. Calls share the rate limiter, metrics and audit log of
+// the composite types.
+func NewTargetSslProxies(gceCloud *gce.Cloud) TargetSslProxies {
+	return &targetSslProxies{cloud: gceCloud}
+}
+
+type targetSslProxies struct {
+	cloud *gce.Cloud
+}
+
+func (c *targetSslProxies) service() *compute.TargetSslProxiesService {
+	return c.cloud.ComputeServices().GA.TargetSslProxies
+}
+
+// start blocks until the call of the given method on the named proxy is
+// accepted by the shared rate limiter.
+func (c *targetSslProxies) start(ctx context.Context, method, request, name, description string, mutating bool) (composite.CallObserver, error) {
+	call := &composite.Call{
+		Service:     "TargetSslProxies",
+		Method:      method,
+		Resource:    "TargetSslProxy",
+		Request:     request,
+		Key:         meta.GlobalKey(name),
+		Version:     meta.VersionGA,
+		Mutating:    mutating,
+		Description: description,
+	}
+	return call.Start(ctx, c.cloud.ProjectID())
+}
+
+// do runs a mutating call on the named proxy and waits for its operation.
+func (c *targetSslProxies) do(ctx context.Context, method, request, name, description string, call func(ctx context.Context) (*compute.Operation, error)) error {
+	ctx, cancel := composite.ContextWithCallTimeout(ctx)
+	defer cancel()
+	mc, err := c.start(ctx, method, request, name, description, true)
+	if err != nil {
+		return err
+	}
+	op, err := call(ctx)
+	if err == nil {
+		err = composite.WaitForCompletion(ctx, c.cloud, c.cloud.ProjectID(), op)
+	}
+	return mc.Observe(err)
+}
+
+func (c *targetSslProxies) Get(ctx context.Context, name string) (*compute.TargetSslProxy, error) {
+	ctx, cancel := composite.ContextWithCallTimeout(ctx)
+	defer cancel()
+	mc, err := c.start(ctx, "Get", "get", name, "", false)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := c.service().Get(c.cloud.ProjectID(), name).Context(ctx).Do()
+	return proxy, mc.Observe(err)
+}
+
+func (c *targetSslProxies) Insert(ctx context.Context, proxy *compute.TargetSslProxy) error {
+	return c.do(ctx, "Insert", "create", proxy.Name, proxy.Description, func(ctx context.Context) (*compute.Operation, error) {
+		return c.service().Insert(c.cloud.ProjectID(), proxy).Context(ctx).Do()
+	})
+}
+
+func (c *targetSslProxies) SetBackendService(ctx context.Context, name, backendServiceLink string) error {
+	req := &compute.TargetSslProxiesSetBackendServiceRequest{Service: backendServiceLink}
+	return c.do(ctx, "SetBackendService", "set_backend_service", name, "", func(ctx context.Context) (*compute.Operation, error) {
+		return c.service().SetBackendService(c.cloud.ProjectID(), name, req).Context(ctx).Do()
+	})
+}
+
+func (c *targetSslProxies) SetSslCertificates(ctx context.Context, name string, certLinks []string) error {
+	req := &compute.TargetSslProxiesSetSslCertificatesRequest{SslCertificates: certLinks}
+	return c.do(ctx, "SetSslCertificates", "set_ssl_certificates", name, "", func(ctx context.Context) (*compute.Operation, error) {
+		return c.service().SetSslCertificates(c.cloud.ProjectID(), name, req).Context(ctx).Do()
+	})
+}
+
+func (c *targetSslProxies) Delete(ctx context.Context, name string) error {
+	return c.do(ctx, "Delete", "delete", name, "", func(ctx context.Context) (*compute.Operation, error) {
+		return c.service().Delete(c.cloud.ProjectID(), name).Context(ctx).Do()
+	})
+}
+
+// edgeHopSslProxy ensures the frontend of a gce-ssl-proxy Ingress:
+// ssl certificates -> target ssl proxy -> forwarding rule on :443.
+// The target ssl proxy points directly at the backend service of the default
+// backend, there is no url map.
+func (l *L7) edgeHopSslProxy(ctx context.Context) error {
+	if l.runtimeInfo.TLS == nil && l.runtimeInfo.TLSName == "" {
+		return fmt.Errorf("%s Ingress requires TLS certificates", annotations.GceSSLProxyIngressClass)
+	}
+	if l.runtimeInfo.UrlMap == nil || l.runtimeInfo.UrlMap.DefaultBackend == nil {
+		return fmt.Errorf("%s Ingress requires a default backend", annotations.GceSSLProxyIngressClass)
+	}
+	if l.sslProxies == nil {
+		return fmt.Errorf("target ssl proxies are not supported by this load balancer pool")
+	}
+
+	defer l.deleteOldSSLCerts(ctx)
+	if err := l.checkSSLCert(ctx); err != nil {
+		return err
+	}
+	if err := l.checkSslProxy(ctx); err != nil {
+		return err
+	}
+	if err := l.checkSslProxyForwardingRule(ctx); err != nil {
+		return err
+	}
+
+	// Clean up the frontend of an Ingress which switched to this class. The
+	// https forwarding rule was already moved to the target ssl proxy.
+	if flags.F.EnableDeleteUnusedFrontends {
+		versions := features.VersionsFromIngress(&l.ingress)
+		if requireDeleteFrontend(l.ingress, namer.HTTPProtocol) {
+			if err := l.deleteHttp(ctx, versions); err != nil {
+				return err
+			}
+		}
+		if _, ok := l.ingress.Annotations[annotations.TargetHttpsProxyKey]; ok {
+			if err := l.deleteTargetProxy(ctx, versions, namer.HTTPSProtocol); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkSslProxy creates or updates the target ssl proxy so that it serves the
+// current certificates and forwards to the default backend.
+func (l *L7) checkSslProxy(ctx context.Context) error {
+	if len(l.sslCerts) == 0 {
+		return fmt.Errorf("no SSL certificates for %q, cannot create target SSL proxy", l)
+	}
+	var certLinks []string
+	for _, cert := range l.sslCerts {
+		certLinks = append(certLinks, cert.SelfLink)
+	}
+	beLink := cloud.SelfLink(meta.VersionGA, l.backendProject(), "backendServices", meta.GlobalKey(l.runtimeInfo.UrlMap.DefaultBackend.BackendName()))
+	name := l.namer.TargetProxy(namer.HTTPSProtocol)
+
+	currentProxy, err := l.sslProxies.Get(ctx, name)
+	if utils.IgnoreHTTPNotFound(err) != nil {
+		return err
+	}
+	if currentProxy == nil {
+		description, err := l.description()
+		if err != nil {
+			return err
+		}
+		klog.V(3).Infof("Creating target ssl proxy %q for backend service %q", name, beLink)
+		proxy := &compute.TargetSslProxy{
+			Name:            name,
+			Description:     description,
+			Service:         beLink,
+			SslCertificates: certLinks,
+		}
+		if err := l.sslProxies.Insert(ctx, proxy); err != nil {
+			return err
+		}
+		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "TargetSslProxy %q created", name)
+		if currentProxy, err = l.sslProxies.Get(ctx, name); err != nil {
+			return err
+		}
+		l.sslProxy = currentProxy
+		return nil
+	}
+
+	if !utils.EqualResourceIDs(currentProxy.Service, beLink) {
+		klog.V(2).Infof("Target ssl proxy %v has the wrong backend service, setting %v overwriting %v", name, beLink, currentProxy.Service)
+		if err := l.sslProxies.SetBackendService(ctx, name, beLink); err != nil {
+			return err
+		}
+		currentProxy.Service = beLink
+		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "TargetSslProxy %q updated", name)
+	}
+	if !l.compareCerts(currentProxy.SslCertificates) {
+		klog.V(2).Infof("Target ssl proxy %q has the wrong ssl certs, setting %v overwriting %v", name, toCertNames(l.sslCerts), currentProxy.SslCertificates)
+		if err := l.sslProxies.SetSslCertificates(ctx, name, certLinks); err != nil {
+			return err
+		}
+		currentProxy.SslCertificates = certLinks
+		l.recorder.Eventf(l.runtimeInfo.Ingress, corev1.EventTypeNormal, events.SyncIngress, "TargetSslProxy %q certs updated", name)
+	}
+	l.sslProxy = currentProxy
+	return nil
+}
+
+// checkSslProxyForwardingRule ensures the forwarding rule on :443 which points
+// to the target ssl proxy. It shares the name of the https forwarding rule.
+func (l *L7) checkSslProxyForwardingRule(ctx context.Context) error {
+	name := l.namer.ForwardingRule(namer.HTTPSProtocol)
+	address, _, err := l.getEffectiveIP(ctx)
+	if err != nil {
+		return err
+	}
+	fws, err := l.checkForwardingRule(ctx, namer.HTTPSProtocol, name, l.sslProxy.SelfLink, address)
+	if err != nil {
+		return err
+	}
+	l.fws = fws
+	return nil
+}
+
+// getSslProxyBackendNames returns the name of the backend service behind the
+// given target ssl proxy.
+func getSslProxyBackendNames(proxy *compute.TargetSslProxy) ([]string, error) {
+	name, err := utils.KeyName(proxy.Service)
+	if err != nil {
+		return nil, err
+	}
+	return []string{name}, nil
+}
+
+// deleteTargetSslProxy deletes the target ssl proxy of a gce-ssl-proxy Ingress.
+// This is a no-op for Ingresses which neither are of that class nor recorded
+// a target ssl proxy in their status annotations.
+func (l *L7) deleteTargetSslProxy(ctx context.Context) error {
+	_, annotated := l.ingress.Annotations[annotations.TargetSslProxyKey]
+	if l.sslProxies == nil || !(annotated || utils.IsGCESSLProxyIngress(&l.ingress)) {
+		return nil
+	}
+	name := l.namer.TargetProxy(namer.HTTPSProtocol)
+	klog.V(2).Infof("Deleting target ssl proxy %v", name)
+	return utils.IgnoreHTTPNotFound(l.sslProxies.Delete(ctx, name))
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/compute/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/ingress-gce/pkg/annotations"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/events"
+	"k8s.io/ingress-gce/pkg/utils"
+	"k8s.io/klog"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+// UrlMapValidator validates global url maps. This is not available through
+// the composite types.
+type UrlMapValidator interface {
+	// Validate runs the static validation of the url map as if it was named
+	// name in the project of the given cloud.
+	Validate(ctx context.Context, cloud *gce.Cloud, name string, um *compute.UrlMap) (*compute.UrlMapValidationResult, error)
+}
+
+// NewUrlMapValidator returns an UrlMapValidator backed by the GA compute API.
+// Calls share the rate limiter and metrics of the composite types.
+func NewUrlMapValidator() UrlMapValidator {
+	return &urlMapValidator{}
+}
+
+type urlMapValidator struct{}
+
+func (v *urlMapValidator) Validate(ctx context.Context, gceCloud *gce.Cloud, name string, um *compute.UrlMap) (*compute.UrlMapValidationResult, error) {
+	ctx, cancel := composite.ContextWithCallTimeout(ctx)
+	defer cancel()
+	call := &composite.Call{
+		Service:  "UrlMaps",
+		Method:   "Validate",
+		Resource: "UrlMap",
+		Request:  "validate",
+		Key:      meta.GlobalKey(name),
+		Version:  meta.VersionGA,
+	}
+	mc, err := call.Start(ctx, gceCloud.ProjectID())
+	if err != nil {
+		return nil, err
+	}
+	req := &compute.UrlMapsValidateRequest{Resource: um}
+	resp, err := gceCloud.ComputeServices().GA.UrlMaps.Validate(gceCloud.ProjectID(), name, req).Context(ctx).Do()
+	if err = mc.Observe(err); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
+// ensureComputeURLMapBySwap ensures the url map of an Ingress which applies
+// changes by swapping url maps. The url map alternates between the names
+// UrlMap and SwapUrlMap of the namer. If the url map in use is out of date,
+// the expected url map is validated and created under the other name. The
+// target proxies are then moved to it as they are synced, and the previous
+// url map is deleted by deleteSwappedURLMap once the frontend is in sync.
+func (l *L7) ensureComputeURLMapBySwap(ctx context.Context, expectedMap *composite.UrlMap) error {
+	swapName, supported := l.namer.SwapUrlMap()
+	if !supported {
+		return fmt.Errorf("cannot swap URL maps with the V1 Ingress naming scheme. Please recreate your ingress to use the newest naming scheme")
+	}
+	if l.Regional() {
+		return fmt.Errorf("URL map swaps are only supported by global Ingresses")
+	}
+
+	activeName, inactiveName := l.namer.UrlMap(), swapName
+	if l.ingress.Annotations[annotations.UrlMapKey] == swapName {
+		activeName, inactiveName = swapName, l.namer.UrlMap()
+	}
+	key, err := l.CreateKey(activeName)
+	if err != nil {
+		return err
+	}
+	currentMap, err := l.compositeCloud.GetUrlMap(ctx, key, expectedMap.Version)
+	if utils.IgnoreHTTPNotFound(err) != nil {
+		return err
+	}
+
+	if currentMap == nil {
+		// There is no url map in use, there is nothing to swap.
+		klog.V(2).Infof("Creating URLMap %q", activeName)
+		expectedMap.Name = activeName
+		if err := l.compositeCloud.CreateUrlMap(ctx, key, expectedMap); err != nil {
+			return fmt.Errorf("CreateUrlMap: %v", err)
+		}
+		l.recorder.Eventf(&l.ingress, apiv1.EventTypeNormal, events.SyncIngress, "UrlMap %q created", activeName)
+		l.um = expectedMap
+		return nil
+	}
+
+	if mapsEqual(currentMap, expectedMap) {
+		klog.V(4).Infof("URLMap for %q is unchanged", l)
+		l.um = currentMap
+		return nil
+	}
+
+	expectedMap.Name = inactiveName
+	if err := l.validateURLMap(ctx, expectedMap); err != nil {
+		return err
+	}
+	key.Name = inactiveName
+	// The url map is left over if a previous swap was interrupted.
+	leftoverMap, err := l.compositeCloud.GetUrlMap(ctx, key, expectedMap.Version)
+	if utils.IgnoreHTTPNotFound(err) != nil {
+		return err
+	}
+	if leftoverMap == nil {
+		klog.V(2).Infof("Creating URLMap %q to replace URLMap %q for %q", inactiveName, activeName, l)
+		if err := l.compositeCloud.CreateUrlMap(ctx, key, expectedMap); err != nil {
+			return fmt.Errorf("CreateUrlMap: %v", err)
+		}
+	} else {
+		klog.V(2).Infof("Updating left over URLMap %q to replace URLMap %q for %q", inactiveName, activeName, l)
+		expectedMap.Fingerprint = leftoverMap.Fingerprint
+		if err := l.compositeCloud.UpdateUrlMap(ctx, key, expectedMap); err != nil {
+			return fmt.Errorf("UpdateURLMap: %v", err)
+		}
+	}
+	l.recorder.Eventf(&l.ingress, apiv1.EventTypeNormal, events.SyncIngress, "UrlMap %q created, swapping from UrlMap %q", inactiveName, activeName)
+	l.um = expectedMap
+	return nil
+}
+
+// validateURLMap returns an error if the url map fails the static validation
+// of GCE.
+func (l *L7) validateURLMap(ctx context.Context, um *composite.UrlMap) error {
+	if l.urlMapValidator == nil {
+		return fmt.Errorf("url map validation is not supported by this load balancer pool")
+	}
+	gaMap, err := um.ToGA()
+	if err != nil {
+		return err
+	}
+	result, err := l.urlMapValidator.Validate(ctx, l.cloud, um.Name, gaMap)
+	if err != nil {
+		return fmt.Errorf("error validating URL map %q: %v", um.Name, err)
+	}
+	if !result.LoadSucceeded {
+		return fmt.Errorf("URL map %q is invalid: %s", um.Name, strings.Join(result.LoadErrors, "; "))
+	}
+	return nil
+}
+
+// deleteSwappedURLMap deletes the url map recorded in the status of the
+// Ingress once the target proxies were moved to another url map. This is
+// either the url map replaced by a swap, or the swapped url map of an Ingress
+// which stopped swapping url maps.
+func (l *L7) deleteSwappedURLMap(ctx context.Context) error {
+	swapName, supported := l.namer.SwapUrlMap()
+	if !supported || l.um == nil {
+		return nil
+	}
+	recordedName := l.ingress.Annotations[annotations.UrlMapKey]
+	if recordedName == l.um.Name || (recordedName != swapName && recordedName != l.namer.UrlMap()) {
+		return nil
+	}
+	key, err := l.CreateKey(recordedName)
+	if err != nil {
+		return err
+	}
+	klog.V(2).Infof("Deleting swapped URLMap %q of %q", recordedName, l)
+	if err := utils.IgnoreHTTPNotFound(l.compositeCloud.DeleteUrlMap(ctx, key, l.Versions().UrlMap)); err != nil {
+		return err
+	}
+	l.recorder.Eventf(&l.ingress, apiv1.EventTypeNormal, events.SyncIngress, "UrlMap %q deleted", recordedName)
+	return nil
+}
@@ -17,21 +17,24 @@ limitations under the License.
 package loadbalancers
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/ingress-gce/pkg/annotations"
 	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/events"
 	"k8s.io/ingress-gce/pkg/translator"
+	"k8s.io/ingress-gce/pkg/urlmaps"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/klog"
 )
 
 // ensureComputeURLMap retrieves the current URLMap and overwrites it if incorrect. If the resource
 // does not exist, the map is created.
-func (l *L7) ensureComputeURLMap() error {
+func (l *L7) ensureComputeURLMap(ctx context.Context) error {
 	if l.runtimeInfo.UrlMap == nil {
 		return fmt.Errorf("cannot create urlmap without internal representation")
 	}
@@ -43,11 +46,23 @@ func (l *L7) ensureComputeURLMap() error {
 	if err != nil {
 		return err
 	}
-	expectedMap := translator.ToCompositeURLMap(l.runtimeInfo.UrlMap, l.namer, key)
+	expectedMap := urlmaps.ToCompositeURLMap(l.runtimeInfo.UrlMap, l.namer, key)
 	key.Name = expectedMap.Name
+	if l.backendProject() != l.cloud.ProjectID() {
+		if err := setBackendServiceProject(expectedMap, l.backendProject()); err != nil {
+			return err
+		}
+	}
 
 	expectedMap.Version = l.Versions().UrlMap
-	currentMap, err := composite.GetUrlMap(l.cloud, key, expectedMap.Version)
+	// The description is only written when the url map is created or updated.
+	if expectedMap.Description, err = l.description(); err != nil {
+		return err
+	}
+	if annotations.FromIngress(&l.ingress).UrlMapSwap() {
+		return l.ensureComputeURLMapBySwap(ctx, expectedMap)
+	}
+	currentMap, err := l.compositeCloud.GetUrlMap(ctx, key, expectedMap.Version)
 	if utils.IgnoreHTTPNotFound(err) != nil {
 		return err
 	}
@@ -56,7 +71,7 @@ func (l *L7) ensureComputeURLMap() error {
 		// Check for transitions between elb and ilb
 
 		klog.V(2).Infof("Creating URLMap %q", expectedMap.Name)
-		if err := composite.CreateUrlMap(l.cloud, key, expectedMap); err != nil {
+		if err := l.compositeCloud.CreateUrlMap(ctx, key, expectedMap); err != nil {
 			return fmt.Errorf("CreateUrlMap: %v", err)
 		}
 		l.recorder.Eventf(&l.ingress, apiv1.EventTypeNormal, events.SyncIngress, "UrlMap %q created", key.Name)
@@ -73,7 +88,7 @@ func (l *L7) ensureComputeURLMap() error {
 
 	klog.V(2).Infof("Updating URLMap for %q", l)
 	expectedMap.Fingerprint = currentMap.Fingerprint
-	if err := composite.UpdateUrlMap(l.cloud, key, expectedMap); err != nil {
+	if err := l.compositeCloud.UpdateUrlMap(ctx, key, expectedMap); err != nil {
 		return fmt.Errorf("UpdateURLMap: %v", err)
 	}
 
@@ -83,7 +98,7 @@ func (l *L7) ensureComputeURLMap() error {
 	return nil
 }
 
-func (l *L7) ensureRedirectURLMap() error {
+func (l *L7) ensureRedirectURLMap(ctx context.Context) error {
 	feConfig := l.runtimeInfo.FrontendConfig
 	isL7ILB := utils.IsGCEL7ILBIngress(&l.ingress)
 
@@ -118,25 +133,25 @@ func (l *L7) ensureRedirectURLMap() error {
 		if !ok || status == "" {
 			return nil
 		} else {
-			if err := composite.DeleteUrlMap(l.cloud, key, l.Versions().UrlMap); err != nil {
+			if err := l.compositeCloud.DeleteUrlMap(ctx, key, l.Versions().UrlMap); err != nil {
 				return err
 			}
 		}
 		return nil
 	}
 
-	currentMap, err := composite.GetUrlMap(l.cloud, key, l.Versions().UrlMap)
+	currentMap, err := l.compositeCloud.GetUrlMap(ctx, key, l.Versions().UrlMap)
 	if utils.IgnoreHTTPNotFound(err) != nil {
 		return err
 	}
 
 	if currentMap == nil {
-		if err := composite.CreateUrlMap(l.cloud, key, expectedMap); err != nil {
+		if err := l.compositeCloud.CreateUrlMap(ctx, key, expectedMap); err != nil {
 			return err
 		}
 	} else if compareRedirectUrlMaps(expectedMap, currentMap) {
 		expectedMap.Fingerprint = currentMap.Fingerprint
-		if err := composite.UpdateUrlMap(l.cloud, key, expectedMap); err != nil {
+		if err := l.compositeCloud.UpdateUrlMap(ctx, key, expectedMap); err != nil {
 			return err
 		}
 	}
@@ -155,6 +170,33 @@ func compareRedirectUrlMaps(a, b *composite.UrlMap) bool {
 	return false
 }
 
+// setBackendServiceProject rewrites the backend service links of the url map
+// to reference backend services in the given project.
+func setBackendServiceProject(m *composite.UrlMap, projectID string) error {
+	withProject := func(link string) (string, error) {
+		id, err := cloud.ParseResourceURL(link)
+		if err != nil {
+			return "", err
+		}
+		return cloud.RelativeResourceName(projectID, id.Resource, id.Key), nil
+	}
+	var err error
+	if m.DefaultService, err = withProject(m.DefaultService); err != nil {
+		return err
+	}
+	for _, pm := range m.PathMatchers {
+		if pm.DefaultService, err = withProject(pm.DefaultService); err != nil {
+			return err
+		}
+		for _, rule := range pm.PathRules {
+			if rule.Service, err = withProject(rule.Service); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // getBackendNames returns the names of backends in this L7 urlmap.
 func getBackendNames(computeURLMap *composite.UrlMap) ([]string, error) {
 	beNames := sets.NewString()
@@ -184,67 +226,29 @@ func getBackendNames(computeURLMap *composite.UrlMap) ([]string, error) {
 	return beNames.List(), nil
 }
 
+// urlMapFields are the fields of a URL map compared by mapsEqual. The service
+// strings are compared as resource paths (such as
+// "global/backendServices/my-service") to ignore variables: endpoint, version,
+// and project.
+var urlMapFields = []utils.FieldSpec{
+	{Path: "DefaultService", ResourcePath: true},
+	{Path: "HostRules", Elem: []utils.FieldSpec{
+		{Path: "Description"},
+		{Path: "Hosts"},
+		{Path: "PathMatcher"},
+	}},
+	{Path: "PathMatchers", Elem: []utils.FieldSpec{
+		{Path: "DefaultService", ResourcePath: true},
+		{Path: "Description"},
+		{Path: "Name"},
+		{Path: "PathRules", Elem: []utils.FieldSpec{
+			{Path: "Paths"},
+			{Path: "Service", ResourcePath: true},
+		}},
+	}},
+}
+
 // mapsEqual compares the structure of two compute.UrlMaps.
-// The service strings are parsed and compared as resource paths (such as
-// "global/backendServices/my-service") to ignore variables: endpoint, version, and project.
 func mapsEqual(a, b *composite.UrlMap) bool {
-	if !utils.EqualResourcePaths(a.DefaultService, b.DefaultService) {
-		return false
-	}
-	if len(a.HostRules) != len(b.HostRules) {
-		return false
-	}
-	for i := range a.HostRules {
-		a := a.HostRules[i]
-		b := b.HostRules[i]
-		if a.Description != b.Description {
-			return false
-		}
-		if len(a.Hosts) != len(b.Hosts) {
-			return false
-		}
-		for i := range a.Hosts {
-			if a.Hosts[i] != b.Hosts[i] {
-				return false
-			}
-		}
-		if a.PathMatcher != b.PathMatcher {
-			return false
-		}
-	}
-	if len(a.PathMatchers) != len(b.PathMatchers) {
-		return false
-	}
-	for i := range a.PathMatchers {
-		a := a.PathMatchers[i]
-		b := b.PathMatchers[i]
-		if !utils.EqualResourcePaths(a.DefaultService, b.DefaultService) {
-			return false
-		}
-		if a.Description != b.Description {
-			return false
-		}
-		if a.Name != b.Name {
-			return false
-		}
-		if len(a.PathRules) != len(b.PathRules) {
-			return false
-		}
-		for i := range a.PathRules {
-			a := a.PathRules[i]
-			b := b.PathRules[i]
-			if len(a.Paths) != len(b.Paths) {
-				return false
-			}
-			for i := range a.Paths {
-				if a.Paths[i] != b.Paths[i] {
-					return false
-				}
-			}
-			if !utils.EqualResourcePaths(a.Service, b.Service) {
-				return false
-			}
-		}
-	}
-	return true
+	return len(utils.DiffFields(a, b, urlMapFields)) == 0
 }
@@ -0,0 +1,45 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog"
+)
+
+var (
+	syncDeadlineExceededCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sync_deadline_exceeded_count",
+			Help: "Number of syncs which exceeded the sync deadline",
+		},
+		[]string{"resource"},
+	)
+)
+
+// init registers sync metrics.
+func init() {
+	klog.V(3).Infof("Registering sync metrics %v", syncDeadlineExceededCount)
+	prometheus.MustRegister(syncDeadlineExceededCount)
+}
+
+// PublishSyncDeadlineExceeded records that a sync of the given resource
+// exceeded the sync deadline. Services are labeled by the controller which
+// syncs them, e.g. "l4ilb".
+func PublishSyncDeadlineExceeded(resource string) {
+	syncDeadlineExceededCount.WithLabelValues(resource).Inc()
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package multiproject maps namespaces to the GCP projects which host the
+// load balancers of their Ingresses. This allows a single controller to serve
+// a multi-tenant cluster whose tenants own separate projects. Backend
+// services, health checks, instance groups and NEGs always live in the
+// cluster project.
+package multiproject
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/klog"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+// ProjectConfig maps a set of namespaces to a GCP project.
+type ProjectConfig struct {
+	// ProjectID is the project which hosts the load balancers.
+	ProjectID string `json:"projectID"`
+	// ConfigFilePath is the path of the gce config file used to create the
+	// cloud for the project. It must be set for every project other than the
+	// cluster project, whose cloud is reused.
+	ConfigFilePath string `json:"configFilePath,omitempty"`
+	// Namespaces are the namespaces mapped to the project.
+	Namespaces []string `json:"namespaces"`
+}
+
+// Config is the namespace to project mapping.
+// Example:
+//
+//	{"projects": [{"projectID": "tenant-a", "configFilePath": "/etc/gce/tenant-a.conf", "namespaces": ["team-a"]}]}
+type Config struct {
+	Projects []ProjectConfig `json:"projects"`
+}
+
+// LoadConfig reads and validates the JSON mapping from the given reader.
+func LoadConfig(r io.Reader) (*Config, error) {
+	config := &Config{}
+	if err := json.NewDecoder(r).Decode(config); err != nil {
+		return nil, fmt.Errorf("failed to decode namespace project config: %v", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate returns an error if a project is incomplete or if a namespace is
+// mapped more than once.
+func (c *Config) Validate() error {
+	projects := map[string]bool{}
+	namespaces := map[string]string{}
+	for _, project := range c.Projects {
+		if project.ProjectID == "" {
+			return fmt.Errorf("projectID must be set for every project")
+		}
+		if projects[project.ProjectID] {
+			return fmt.Errorf("project %q is configured more than once", project.ProjectID)
+		}
+		projects[project.ProjectID] = true
+		if len(project.Namespaces) == 0 {
+			return fmt.Errorf("project %q has no namespaces", project.ProjectID)
+		}
+		for _, ns := range project.Namespaces {
+			if other, ok := namespaces[ns]; ok {
+				return fmt.Errorf("namespace %q is mapped to projects %q and %q", ns, other, project.ProjectID)
+			}
+			namespaces[ns] = project.ProjectID
+		}
+	}
+	return nil
+}
+
+// CloudFactory creates the cloud for a mapped project.
+type CloudFactory func(project ProjectConfig) (*gce.Cloud, error)
+
+// Router returns the cloud, and therefore the project, which hosts the load
+// balancer of an Ingress. All composite calls made with the returned cloud
+// are routed to its project.
+type Router struct {
+	defaultCloud *gce.Cloud
+	// clouds maps namespaces to the cloud of their project.
+	clouds map[string]*gce.Cloud
+}
+
+// NewSingleProjectRouter returns a Router which maps all namespaces to the
+// given cloud.
+func NewSingleProjectRouter(cloud *gce.Cloud) *Router {
+	return &Router{defaultCloud: cloud, clouds: map[string]*gce.Cloud{}}
+}
+
+// NewRouter returns a Router for the given config. Namespaces which are not
+// mapped use the default cloud.
+func NewRouter(defaultCloud *gce.Cloud, config *Config, newCloud CloudFactory) (*Router, error) {
+	r := NewSingleProjectRouter(defaultCloud)
+	for _, project := range config.Projects {
+		cloud := defaultCloud
+		if project.ProjectID != defaultCloud.ProjectID() {
+			var err error
+			if cloud, err = newCloud(project); err != nil {
+				return nil, fmt.Errorf("failed to create cloud for project %q: %v", project.ProjectID, err)
+			}
+			if cloud.ProjectID() != project.ProjectID {
+				return nil, fmt.Errorf("cloud for project %q is configured for project %q", project.ProjectID, cloud.ProjectID())
+			}
+		}
+		for _, ns := range project.Namespaces {
+			klog.V(2).Infof("Mapping namespace %q to project %q", ns, project.ProjectID)
+			r.clouds[ns] = cloud
+		}
+	}
+	return r, nil
+}
+
+// Default returns the cloud of the cluster project.
+func (r *Router) Default() *gce.Cloud {
+	return r.defaultCloud
+}
+
+// CloudForNamespace returns the cloud of the project mapped to the namespace.
+func (r *Router) CloudForNamespace(namespace string) *gce.Cloud {
+	if cloud, ok := r.clouds[namespace]; ok {
+		return cloud
+	}
+	return r.defaultCloud
+}
+
+// CloudForIngress returns the cloud of the project which hosts the load
+// balancer of the Ingress.
+func (r *Router) CloudForIngress(ing *v1.Ingress) *gce.Cloud {
+	if ing == nil {
+		return r.defaultCloud
+	}
+	return r.CloudForNamespace(ing.Namespace)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiproject
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+func fakeCloud(projectID string) *gce.Cloud {
+	vals := gce.DefaultTestClusterValues()
+	vals.ProjectID = projectID
+	return gce.NewFakeGCECloud(vals)
+}
+
+func TestLoadConfig(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		config  string
+		wantErr bool
+	}{
+		{
+			desc:   "valid config",
+			config: `{"projects": [{"projectID": "a", "configFilePath": "/a.conf", "namespaces": ["ns1", "ns2"]}, {"projectID": "b", "namespaces": ["ns3"]}]}`,
+		},
+		{
+			desc:    "invalid json",
+			config:  `{"projects": [`,
+			wantErr: true,
+		},
+		{
+			desc:    "missing project id",
+			config:  `{"projects": [{"namespaces": ["ns1"]}]}`,
+			wantErr: true,
+		},
+		{
+			desc:    "duplicate project",
+			config:  `{"projects": [{"projectID": "a", "namespaces": ["ns1"]}, {"projectID": "a", "namespaces": ["ns2"]}]}`,
+			wantErr: true,
+		},
+		{
+			desc:    "no namespaces",
+			config:  `{"projects": [{"projectID": "a"}]}`,
+			wantErr: true,
+		},
+		{
+			desc:    "namespace mapped twice",
+			config:  `{"projects": [{"projectID": "a", "namespaces": ["ns1"]}, {"projectID": "b", "namespaces": ["ns1"]}]}`,
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := LoadConfig(strings.NewReader(tc.config))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("LoadConfig() = %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRouter(t *testing.T) {
+	defaultCloud := fakeCloud("cluster-project")
+	tenantCloud := fakeCloud("tenant-project")
+	config := &Config{Projects: []ProjectConfig{
+		{ProjectID: "tenant-project", ConfigFilePath: "/tenant.conf", Namespaces: []string{"tenant"}},
+		{ProjectID: "cluster-project", Namespaces: []string{"shared"}},
+	}}
+	var created []string
+	newCloud := func(project ProjectConfig) (*gce.Cloud, error) {
+		created = append(created, project.ProjectID)
+		return tenantCloud, nil
+	}
+
+	r, err := NewRouter(defaultCloud, config, newCloud)
+	if err != nil {
+		t.Fatalf("NewRouter() = %v", err)
+	}
+	if len(created) != 1 || created[0] != "tenant-project" {
+		t.Errorf("created clouds for %v, want only [tenant-project]", created)
+	}
+
+	for _, tc := range []struct {
+		ing  *v1.Ingress
+		want *gce.Cloud
+	}{
+		{ing: nil, want: defaultCloud},
+		{ing: &v1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant"}}, want: tenantCloud},
+		{ing: &v1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "shared"}}, want: defaultCloud},
+		{ing: &v1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "other"}}, want: defaultCloud},
+	} {
+		if got := r.CloudForIngress(tc.ing); got != tc.want {
+			t.Errorf("CloudForIngress(%v) routed to project %q, want %q", tc.ing, got.ProjectID(), tc.want.ProjectID())
+		}
+	}
+}
+
+func TestRouterErrors(t *testing.T) {
+	defaultCloud := fakeCloud("cluster-project")
+	config := &Config{Projects: []ProjectConfig{{ProjectID: "tenant-project", Namespaces: []string{"tenant"}}}}
+
+	failingFactory := func(ProjectConfig) (*gce.Cloud, error) { return nil, fmt.Errorf("no credentials") }
+	if _, err := NewRouter(defaultCloud, config, failingFactory); err == nil {
+		t.Errorf("NewRouter() = nil, want error for failing cloud factory")
+	}
+
+	wrongProjectFactory := func(ProjectConfig) (*gce.Cloud, error) { return fakeCloud("other-project"), nil }
+	if _, err := NewRouter(defaultCloud, config, wrongProjectFactory); err == nil {
+		t.Errorf("NewRouter() = nil, want error for cloud of a different project")
+	}
+}
@@ -19,6 +19,8 @@ package neg
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strconv"
 	"time"
 
 	istioV1alpha3 "istio.io/api/networking/v1alpha3"
@@ -29,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	apimachinerytypes "k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
@@ -42,10 +45,13 @@ import (
 	"k8s.io/ingress-gce/pkg/annotations"
 	svcnegv1beta1 "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1"
 	"k8s.io/ingress-gce/pkg/controller/translator"
+	"k8s.io/ingress-gce/pkg/events"
+	"k8s.io/ingress-gce/pkg/flags"
 	usage "k8s.io/ingress-gce/pkg/metrics"
 	"k8s.io/ingress-gce/pkg/neg/metrics"
 	"k8s.io/ingress-gce/pkg/neg/readiness"
 	negtypes "k8s.io/ingress-gce/pkg/neg/types"
+	"k8s.io/ingress-gce/pkg/quota"
 	svcnegclient "k8s.io/ingress-gce/pkg/svcneg/client/clientset/versioned"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/ingress-gce/pkg/utils/common"
@@ -74,11 +80,15 @@ type Controller struct {
 	ingressLister               cache.Indexer
 	serviceLister               cache.Indexer
 	client                      kubernetes.Interface
+	svcNegClient                svcnegclient.Interface
 	defaultBackendService       utils.ServicePort
 	destinationRuleLister       cache.Indexer
 	destinationRuleClient       dynamic.NamespaceableResourceInterface
 	enableASM                   bool
 	asmServiceNEGSkipNamespaces []string
+	// maxNEGServicesPerNamespace is the maximum number of Services with NEGs
+	// enabled by annotation per namespace. Zero means unlimited.
+	maxNEGServicesPerNamespace int
 
 	// serviceQueue takes service key as work item. Service key with format "namespace/name".
 	serviceQueue workqueue.RateLimitingInterface
@@ -133,6 +143,7 @@ func NewController(
 	enableNonGcpMode bool,
 	enableAsm bool,
 	asmServiceNEGSkipNamespaces []string,
+	maxNEGServicesPerNamespace int,
 ) *Controller {
 	// init event recorder
 	// TODO: move event recorder initializer to main. Reuse it among controllers.
@@ -180,6 +191,7 @@ func NewController(
 
 	negController := &Controller{
 		client:                kubeClient,
+		svcNegClient:          svcNegClient,
 		manager:               manager,
 		resyncPeriod:          resyncPeriod,
 		gcPeriod:              gcPeriod,
@@ -199,6 +211,7 @@ func NewController(
 		collector:             controllerMetrics,
 		runL4:                 runL4Controller,
 	}
+	negController.maxNEGServicesPerNamespace = maxNEGServicesPerNamespace
 	if runIngress {
 		ingressInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
@@ -427,6 +440,14 @@ func (c *Controller) processService(key string) error {
 	if service == nil {
 		return fmt.Errorf("cannot convert to Service (%T)", obj)
 	}
+	if err := c.checkNEGServiceLimit(service); err != nil {
+		c.recorder.Eventf(service, apiv1.EventTypeWarning, events.QuotaExceeded, "NEGs not created: %v", err)
+		klog.V(2).Infof("Skipping NEGs of service %q: %v", key, err)
+		c.collector.DeleteNegService(key)
+		c.manager.StopSyncer(namespace, name)
+		_, err := c.syncNegStatusAnnotation(namespace, name, make(negtypes.PortInfoMap))
+		return err
+	}
 	negUsage := usage.NegServiceState{}
 	svcPortInfoMap := make(negtypes.PortInfoMap)
 	if err := c.mergeDefaultBackendServicePortInfoMap(key, service, svcPortInfoMap); err != nil {
@@ -459,7 +480,8 @@ func (c *Controller) processService(key string) error {
 	}
 	if len(svcPortInfoMap) != 0 || len(destinationRulesPortInfoMap) != 0 {
 		klog.V(2).Infof("Syncing service %q", key)
-		if err = c.syncNegStatusAnnotation(namespace, name, svcPortInfoMap); err != nil {
+		negStatus, err := c.syncNegStatusAnnotation(namespace, name, svcPortInfoMap)
+		if err != nil {
 			return err
 		}
 		// Merge destinationRule related NEG after the Service NEGStatus Sync, we don't want DR related NEG status go into service.
@@ -469,7 +491,11 @@ func (c *Controller) processService(key string) error {
 
 		negUsage.SuccessfulNeg, negUsage.ErrorNeg, err = c.manager.EnsureSyncers(namespace, name, svcPortInfoMap)
 		c.collector.SetNegService(key, negUsage)
-		return err
+		if err != nil {
+			return err
+		}
+		// The NEG CRs are ensured by the syncers.
+		return c.publishNegStatus(namespace, negStatus)
 	}
 
 	// do not need Neg
@@ -479,7 +505,32 @@ func (c *Controller) processService(key string) error {
 	c.manager.StopSyncer(namespace, name)
 
 	// delete the annotation
-	return c.syncNegStatusAnnotation(namespace, name, make(negtypes.PortInfoMap))
+	_, err = c.syncNegStatusAnnotation(namespace, name, make(negtypes.PortInfoMap))
+	return err
+}
+
+// checkNEGServiceLimit returns an error if enabling NEGs for service would
+// exceed the limit on NEG enabled Services of its namespace.
+func (c *Controller) checkNEGServiceLimit(service *apiv1.Service) error {
+	if c.maxNEGServicesPerNamespace <= 0 || !negEnabledByAnnotation(service) {
+		return nil
+	}
+	var services []*apiv1.Service
+	objs, err := c.serviceLister.ByIndex(cache.NamespaceIndex, service.Namespace)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		services = append(services, obj.(*apiv1.Service))
+	}
+	return quota.CheckNEGService(service, services, c.maxNEGServicesPerNamespace, negEnabledByAnnotation)
+}
+
+// negEnabledByAnnotation returns true if the NEG annotation of service
+// enables NEGs.
+func negEnabledByAnnotation(service *apiv1.Service) bool {
+	negAnnotation, found, err := annotations.FromService(service).NEGAnnotation()
+	return err == nil && found && negAnnotation.NEGEnabled()
 }
 
 // mergeIngressPortInfo merges Ingress PortInfo into portInfoMap if the service has Enable Ingress annotation.
@@ -488,12 +539,13 @@ func (c *Controller) mergeIngressPortInfo(service *apiv1.Service, name types.Nam
 	if err != nil {
 		return err
 	}
-	if !foundNEGAnnotation {
+	// With restricted node access, Ingresses always use NEGs.
+	if !foundNEGAnnotation && !flags.F.EnableRestrictedNodeAccess {
 		return nil
 	}
 
 	// handle NEGs used by ingress
-	if negAnnotation != nil && negAnnotation.NEGEnabledForIngress() {
+	if flags.F.EnableRestrictedNodeAccess || negAnnotation != nil && negAnnotation.NEGEnabledForIngress() {
 		// Only service ports referenced by ingress are synced for NEG
 		ings := getIngressServicesFromStore(c.ingressLister, service)
 		ingressSvcPortTuples := gatherPortMappingUsedByIngress(ings, service)
@@ -600,6 +652,9 @@ func (c *Controller) mergeDefaultBackendServicePortInfoMap(key string, service *
 	if err != nil {
 		return err
 	}
+	if flags.F.EnableRestrictedNodeAccess {
+		return scanIngress(utils.IsGCEIngress)
+	}
 	if !foundNEGAnnotation {
 		return nil
 	}
@@ -647,15 +702,16 @@ func (c *Controller) getCSMPortInfoMap(namespace, name string, service *apiv1.Se
 
 // syncNegStatusAnnotation syncs the neg status annotation
 // it takes service namespace, name and the expected service ports for NEGs.
-func (c *Controller) syncNegStatusAnnotation(namespace, name string, portMap negtypes.PortInfoMap) error {
+// It returns the NEG status of the annotation.
+func (c *Controller) syncNegStatusAnnotation(namespace, name string, portMap negtypes.PortInfoMap) (annotations.NegStatus, error) {
 	zones, err := c.zoneGetter.ListZones()
 	if err != nil {
-		return err
+		return annotations.NegStatus{}, err
 	}
 	coreClient := c.client.CoreV1()
 	service, err := coreClient.Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	if err != nil {
-		return err
+		return annotations.NegStatus{}, err
 	}
 
 	// Remove NEG Status Annotation when no NEG is needed
@@ -664,20 +720,20 @@ func (c *Controller) syncNegStatusAnnotation(namespace, name string, portMap neg
 			newSvcObjectMeta := service.ObjectMeta.DeepCopy()
 			delete(newSvcObjectMeta.Annotations, annotations.NEGStatusKey)
 			klog.V(2).Infof("Removing NEG status annotation from service: %s/%s", namespace, name)
-			return patch.PatchServiceObjectMetadata(coreClient, service, *newSvcObjectMeta)
+			return annotations.NegStatus{}, patch.PatchServiceObjectMetadata(coreClient, service, *newSvcObjectMeta)
 		}
 		// service doesn't have the expose NEG annotation and doesn't need update
-		return nil
+		return annotations.NegStatus{}, nil
 	}
 
 	negStatus := annotations.NewNegStatus(zones, portMap.ToPortNegMap())
 	annotation, err := negStatus.Marshal()
 	if err != nil {
-		return err
+		return negStatus, err
 	}
 	existingAnnotation, ok := service.Annotations[annotations.NEGStatusKey]
 	if ok && existingAnnotation == annotation {
-		return nil
+		return negStatus, nil
 	}
 	newSvcObjectMeta := service.ObjectMeta.DeepCopy()
 	// If enableCSM=true, it's possible a service having nil Annotations.
@@ -686,7 +742,53 @@ func (c *Controller) syncNegStatusAnnotation(namespace, name string, portMap neg
 	}
 	newSvcObjectMeta.Annotations[annotations.NEGStatusKey] = annotation
 	klog.V(2).Infof("Updating NEG visibility annotation %q on service %s/%s.", annotation, namespace, name)
-	return patch.PatchServiceObjectMetadata(coreClient, service, *newSvcObjectMeta)
+	return negStatus, patch.PatchServiceObjectMetadata(coreClient, service, *newSvcObjectMeta)
+}
+
+// publishNegStatus publishes the NEG status of the annotation of a service in
+// the status of the NEG CRs of the service ports, so that other controllers
+// can consume structured references to the NEGs.
+func (c *Controller) publishNegStatus(namespace string, negStatus annotations.NegStatus) error {
+	if c.svcNegClient == nil {
+		return nil
+	}
+	var errList []error
+	for port, negName := range negStatus.NetworkEndpointGroups {
+		portNum, err := strconv.Atoi(port)
+		if err != nil {
+			errList = append(errList, fmt.Errorf("invalid port %q of NEG %s/%s: %w", port, namespace, negName, err))
+			continue
+		}
+		published := &svcnegv1beta1.PublishedNegStatus{Name: negName, Port: int32(portNum), Zones: negStatus.Zones}
+		if err := c.ensurePublishedNegStatus(namespace, published); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	return utilerrors.NewAggregate(errList)
+}
+
+// ensurePublishedNegStatus patches the status of the NEG CR named after the
+// NEGs if it does not publish the given status yet.
+func (c *Controller) ensurePublishedNegStatus(namespace string, published *svcnegv1beta1.PublishedNegStatus) error {
+	svcNegs := c.svcNegClient.NetworkingV1beta1().ServiceNetworkEndpointGroups(namespace)
+	negCR, err := svcNegs.Get(context.TODO(), published.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get NEG CR %s/%s: %w", namespace, published.Name, err)
+	}
+	if reflect.DeepEqual(negCR.Status.Published, published) {
+		return nil
+	}
+	newStatus := negCR.Status.DeepCopy()
+	newStatus.Published = published
+	patchBytes, err := patch.MergePatchBytes(svcnegv1beta1.ServiceNetworkEndpointGroup{Status: negCR.Status}, svcnegv1beta1.ServiceNetworkEndpointGroup{Status: *newStatus})
+	if err != nil {
+		return fmt.Errorf("failed to prepare patch bytes: %w", err)
+	}
+	klog.V(2).Infof("Publishing NEG status %+v on NEG CR %s/%s", published, namespace, published.Name)
+	if _, err := svcNegs.Patch(context.TODO(), published.Name, apimachinerytypes.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to publish NEG status on NEG CR %s/%s: %w", namespace, published.Name, err)
+	}
+	return nil
 }
 
 // syncDestinationRuleNegStatusAnnotation syncs the destinationrule related neg status annotation
@@ -46,6 +46,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/ingress-gce/pkg/annotations"
+	negv1beta1 "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1"
 	negtypes "k8s.io/ingress-gce/pkg/neg/types"
 	svcnegclient "k8s.io/ingress-gce/pkg/svcneg/client/clientset/versioned"
 	"k8s.io/ingress-gce/pkg/utils"
@@ -150,6 +151,7 @@ func newTestController(kubeClient kubernetes.Interface) *Controller {
 		false, //enableNonGcpMode
 		true,  //eanbleAsm
 		[]string{},
+		0, // maxNEGServicesPerNamespace
 	)
 
 	return controller
@@ -1205,6 +1207,16 @@ func validateNegCRs(t *testing.T, svc *v1.Service, svcNegClient svcnegclient.Int
 		if ownerReferences[0].UID != svc.UID {
 			t.Fatalf("neg cr owner reference does not point to service %s/%s", svc.Namespace, svc.Name)
 		}
+
+		// The published status matches the NEG status annotation.
+		negStatus, err := annotations.ParseNegStatus(svc.Annotations[annotations.NEGStatusKey])
+		if err != nil {
+			t.Fatalf("failed to parse NEG status annotation: %v", err)
+		}
+		expectedPublished := &negv1beta1.PublishedNegStatus{Name: name, Port: port, Zones: negStatus.Zones}
+		if !reflect.DeepEqual(neg.Status.Published, expectedPublished) {
+			t.Errorf("neg cr %s has published status %+v, want %+v", name, neg.Status.Published, expectedPublished)
+		}
 	}
 }
 
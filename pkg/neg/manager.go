@@ -491,10 +491,20 @@ func (manager *syncerManager) garbageCollectNEGWithCRD() error {
 		return true
 	}
 
+	// NEGs still referenced by a backend service must not be deleted, e.g. when
+	// the controller restarted before the backends were detached. The CR
+	// finalizer is kept so that the NEGs are garbage collected in a later pass.
+	var negsInUse sets.String
+	if len(deletionCandidates) > 0 {
+		if negsInUse, err = manager.negsInUse(); err != nil {
+			return utilerrors.NewAggregate(append(errList, fmt.Errorf("failed to list backend services during garbage collection: %w", err)))
+		}
+	}
+
 	for _, cr := range deletionCandidates {
-		shouldDeleteNegCR := true
 		deleteByZone := len(cr.Status.NetworkEndpointGroups) == 0
 		klog.V(2).Infof("Deletion candidate %s/%s has %d NEG references", cr.Namespace, cr.Name, len(cr.Status.NetworkEndpointGroups))
+		var negKeys []*meta.Key
 		for _, negRef := range cr.Status.NetworkEndpointGroups {
 			resourceID, err := cloud.ParseResourceURL(negRef.SelfLink)
 			if err != nil {
@@ -502,17 +512,27 @@ func (manager *syncerManager) garbageCollectNEGWithCRD() error {
 				deleteByZone = true
 				continue
 			}
-
-			shouldDeleteNegCR = shouldDeleteNegCR && deleteNegOrReportErr(resourceID.Key.Name, resourceID.Key.Zone, cr)
+			negKeys = append(negKeys, resourceID.Key)
 		}
 
 		if deleteByZone {
 			klog.V(2).Infof("Deletion candidate %s/%s has 0 NEG reference: %+v", cr.Namespace, cr.Name, cr)
 			for _, zone := range zones {
-				shouldDeleteNegCR = shouldDeleteNegCR && deleteNegOrReportErr(cr.Name, zone, cr)
+				negKeys = append(negKeys, meta.ZonalKey(cr.Name, zone))
 			}
 		}
 
+		if inUse := negKeysInUse(negKeys, negsInUse); len(inUse) > 0 {
+			klog.V(2).Infof("Skipping deletion of NEG CR %s/%s because NEGs %v are still referenced by backend services", cr.Namespace, cr.Name, inUse)
+			manager.recorder.Eventf(cr, v1.EventTypeWarning, negtypes.NegGCError, "NEGs %v are still in use by backend services, skipping garbage collection", inUse)
+			continue
+		}
+
+		shouldDeleteNegCR := true
+		for _, key := range negKeys {
+			shouldDeleteNegCR = shouldDeleteNegCR && deleteNegOrReportErr(key.Name, key.Zone, cr)
+		}
+
 		if !shouldDeleteNegCR {
 			continue
 		}
@@ -542,6 +562,41 @@ func (manager *syncerManager) garbageCollectNEGWithCRD() error {
 	return utilerrors.NewAggregate(errList)
 }
 
+// negsInUse returns the zonal NEGs referenced as backends by any backend
+// service, keyed by zone and name.
+func (manager *syncerManager) negsInUse() (sets.String, error) {
+	backendServices, err := manager.cloud.ListBackendServices(meta.VersionGA)
+	if err != nil {
+		return nil, err
+	}
+	ret := sets.NewString()
+	for _, bs := range backendServices {
+		for _, backend := range bs.Backends {
+			resourceID, err := cloud.ParseResourceURL(backend.Group)
+			if err != nil || resourceID.Resource != "networkEndpointGroups" || resourceID.Key.Type() != meta.Zonal {
+				continue
+			}
+			ret.Insert(negKey(resourceID.Key.Name, resourceID.Key.Zone))
+		}
+	}
+	return ret, nil
+}
+
+// negKeysInUse returns the NEGs in keys which are contained in negsInUse.
+func negKeysInUse(keys []*meta.Key, negsInUse sets.String) []string {
+	var ret []string
+	for _, key := range keys {
+		if k := negKey(key.Name, key.Zone); negsInUse.Has(k) {
+			ret = append(ret, k)
+		}
+	}
+	return ret
+}
+
+func negKey(name, zone string) string {
+	return fmt.Sprintf("%s/%s", zone, name)
+}
+
 // ensureDeleteNetworkEndpointGroup ensures neg is delete from zone
 func (manager *syncerManager) ensureDeleteNetworkEndpointGroup(name, zone string, expectedDesc *utils.NegDescription) error {
 	neg, err := manager.cloud.GetNetworkEndpointGroup(name, zone, meta.VersionGA)
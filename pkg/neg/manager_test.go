@@ -1183,6 +1183,7 @@ func TestGarbageCollectionNegCrdEnabled(t *testing.T) {
 		emptyNegRefList      bool
 		negDesc              string
 		malformedNegSelflink bool
+		negInUse             bool
 		expectNegGC          bool
 		expectCrGC           bool
 		expectErr            bool
@@ -1256,6 +1257,14 @@ func TestGarbageCollectionNegCrdEnabled(t *testing.T) {
 			expectCrGC:          true,
 			negDesc:             "",
 		},
+		{desc: "neg config not in svcPortMap, marked for deletion, neg referenced by backend service",
+			negsExist:         true,
+			markedForDeletion: true,
+			negInUse:          true,
+			expectNegGC:       false,
+			expectCrGC:        false,
+			negDesc:           matchingDesc.String(),
+		},
 		{desc: "neg config in svcPortMap, marked for deletion",
 			negsExist:         true,
 			markedForDeletion: true,
@@ -1367,6 +1376,20 @@ func TestGarbageCollectionNegCrdEnabled(t *testing.T) {
 						}
 					}
 
+					if tc.negInUse {
+						neg, err := fakeNegCloud.GetNetworkEndpointGroup(negName, negtypes.TestZone1, version)
+						if err != nil {
+							t.Fatalf("failed to get neg %s: %v", negName, err)
+						}
+						bs := &composite.BackendService{
+							Name:     "bs-" + negName,
+							Backends: []*composite.Backend{{Group: neg.SelfLink}},
+						}
+						if err := composite.CreateBackendService(context2.TODO(), testCloud, meta.GlobalKey(bs.Name), bs); err != nil {
+							t.Fatalf("failed to create backend service: %v", err)
+						}
+					}
+
 					if tc.gcError != nil {
 						mockCloud := testCloud.Compute().(*cloud.MockGCE)
 						mockNEG := mockCloud.NetworkEndpointGroups().(*cloud.MockNetworkEndpointGroups)
@@ -31,9 +31,13 @@ const (
 	negOpLatencyKey          = "neg_operation_duration_seconds"
 	negOpEndpointsKey        = "neg_operation_endpoints"
 	lastSyncTimestampKey     = "sync_timestamp"
+	endpointsCacheKey        = "endpoints_calculation_cache_total"
+	endpointZoneSkewKey      = "endpoint_zone_skew"
 
 	resultSuccess = "success"
 	resultError   = "error"
+	resultHit     = "hit"
+	resultMiss    = "miss"
 
 	GCProcess   = "GC"
 	SyncProcess = "Sync"
@@ -118,6 +122,30 @@ var (
 		},
 	)
 
+	EndpointsCalculationCache = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: negControllerSubsystem,
+			Name:      endpointsCacheKey,
+			Help:      "Number of endpoints calculations of NEG syncers served from the cache or computed",
+		},
+		[]string{
+			"result", // hit or miss
+		},
+	)
+
+	EndpointZoneSkew = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: negControllerSubsystem,
+			Name:      endpointZoneSkewKey,
+			Help:      "Largest difference between the share of the endpoints and the share of the nodes of a zone, observed on every NEG sync",
+			// custom buckets - [0.05, 0.1, 0.15, ..., 1, +Inf]
+			Buckets: prometheus.LinearBuckets(0.05, 0.05, 20),
+		},
+		[]string{
+			"neg_type", // type of neg
+		},
+	)
+
 	LastSyncTimestamp = prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Subsystem: negControllerSubsystem,
@@ -137,6 +165,8 @@ func RegisterMetrics() {
 		prometheus.MustRegister(SyncerSyncLatency)
 		prometheus.MustRegister(LastSyncTimestamp)
 		prometheus.MustRegister(InitializationLatency)
+		prometheus.MustRegister(EndpointsCalculationCache)
+		prometheus.MustRegister(EndpointZoneSkew)
 	})
 }
 
@@ -166,6 +196,20 @@ func PublishNegInitializationMetrics(latency time.Duration) {
 	InitializationLatency.Observe(latency.Seconds())
 }
 
+// PublishEndpointsCalculationCacheMetrics publishes whether an endpoints calculation was served from the cache
+func PublishEndpointsCalculationCacheMetrics(hit bool) {
+	result := resultMiss
+	if hit {
+		result = resultHit
+	}
+	EndpointsCalculationCache.WithLabelValues(result).Inc()
+}
+
+// PublishEndpointZoneSkewMetrics publishes the zone skew of the endpoints of a NEG
+func PublishEndpointZoneSkewMetrics(negType string, skew float64) {
+	EndpointZoneSkew.WithLabelValues(negType).Observe(skew)
+}
+
 func getResult(err error) string {
 	if err != nil {
 		return resultError
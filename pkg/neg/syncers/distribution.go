@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncers
+
+import (
+	"math"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	negv1beta1 "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1"
+	negtypes "k8s.io/ingress-gce/pkg/neg/types"
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+// maxZoneSkew is the largest difference between the share of the endpoints
+// and the share of the nodes of a zone under which the endpoints of a
+// service are considered balanced.
+const maxZoneSkew = 0.25
+
+// nodesPerZone returns the number of ready nodes in every zone.
+func nodesPerZone(nodeLister cache.Indexer, zoneGetter negtypes.ZoneGetter) (map[string]int, error) {
+	nodes, err := utils.ListWithPredicate(listers.NewNodeLister(nodeLister), utils.GetNodeConditionPredicate())
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]int{}
+	for _, node := range nodes {
+		zone, err := zoneGetter.GetZoneForNode(node.Name)
+		if err != nil {
+			return nil, err
+		}
+		ret[zone]++
+	}
+	return ret, nil
+}
+
+// endpointDistribution returns the distribution of the endpoints across
+// zones compared to the distribution of the nodes, and its skew: the largest
+// difference between the share of the endpoints and the share of the nodes of
+// a zone.
+func endpointDistribution(endpoints map[string]negtypes.NetworkEndpointSet, nodes map[string]int) (*negv1beta1.EndpointDistribution, float64) {
+	zones := sets.NewString()
+	totalEndpoints, totalNodes := 0, 0
+	for zone, set := range endpoints {
+		zones.Insert(zone)
+		totalEndpoints += set.Len()
+	}
+	for zone, count := range nodes {
+		zones.Insert(zone)
+		totalNodes += count
+	}
+
+	distribution := &negv1beta1.EndpointDistribution{}
+	skew := 0.0
+	for _, zone := range zones.List() {
+		count := negv1beta1.ZoneEndpointCount{Zone: zone, Nodes: int32(nodes[zone])}
+		if set, ok := endpoints[zone]; ok {
+			count.Endpoints = int32(set.Len())
+		}
+		distribution.Zones = append(distribution.Zones, count)
+		if totalEndpoints == 0 || totalNodes == 0 {
+			continue
+		}
+		endpointShare := float64(count.Endpoints) / float64(totalEndpoints)
+		nodeShare := float64(count.Nodes) / float64(totalNodes)
+		skew = math.Max(skew, math.Abs(endpointShare-nodeShare))
+	}
+	distribution.Skewed = skew > maxZoneSkew
+	return distribution, skew
+}
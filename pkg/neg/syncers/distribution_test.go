@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncers
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	negv1beta1 "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1"
+	negtypes "k8s.io/ingress-gce/pkg/neg/types"
+)
+
+func TestEndpointDistribution(t *testing.T) {
+	endpoints := func(count int) negtypes.NetworkEndpointSet {
+		set := negtypes.NewNetworkEndpointSet()
+		for i := 0; i < count; i++ {
+			set.Insert(negtypes.NetworkEndpoint{IP: fmt.Sprintf("10.0.0.%d", i), Port: "80"})
+		}
+		return set
+	}
+
+	for _, tc := range []struct {
+		desc      string
+		endpoints map[string]negtypes.NetworkEndpointSet
+		nodes     map[string]int
+		wantZones []negv1beta1.ZoneEndpointCount
+		wantSkew  float64
+	}{
+		{
+			desc:      "balanced",
+			endpoints: map[string]negtypes.NetworkEndpointSet{"zone1": endpoints(2), "zone2": endpoints(2)},
+			nodes:     map[string]int{"zone1": 3, "zone2": 3},
+			wantZones: []negv1beta1.ZoneEndpointCount{{Zone: "zone1", Endpoints: 2, Nodes: 3}, {Zone: "zone2", Endpoints: 2, Nodes: 3}},
+			wantSkew:  0,
+		},
+		{
+			desc:      "skewed to one zone",
+			endpoints: map[string]negtypes.NetworkEndpointSet{"zone1": endpoints(9), "zone2": endpoints(1)},
+			nodes:     map[string]int{"zone1": 1, "zone2": 1},
+			wantZones: []negv1beta1.ZoneEndpointCount{{Zone: "zone1", Endpoints: 9, Nodes: 1}, {Zone: "zone2", Endpoints: 1, Nodes: 1}},
+			wantSkew:  0.4,
+		},
+		{
+			desc:      "zone without endpoints",
+			endpoints: map[string]negtypes.NetworkEndpointSet{"zone1": endpoints(4)},
+			nodes:     map[string]int{"zone1": 1, "zone2": 1},
+			wantZones: []negv1beta1.ZoneEndpointCount{{Zone: "zone1", Endpoints: 4, Nodes: 1}, {Zone: "zone2", Endpoints: 0, Nodes: 1}},
+			wantSkew:  0.5,
+		},
+		{
+			desc:      "no endpoints",
+			endpoints: map[string]negtypes.NetworkEndpointSet{},
+			nodes:     map[string]int{"zone1": 1},
+			wantZones: []negv1beta1.ZoneEndpointCount{{Zone: "zone1", Endpoints: 0, Nodes: 1}},
+			wantSkew:  0,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			distribution, skew := endpointDistribution(tc.endpoints, tc.nodes)
+			if !reflect.DeepEqual(distribution.Zones, tc.wantZones) {
+				t.Errorf("endpointDistribution() zones = %+v, want %+v", distribution.Zones, tc.wantZones)
+			}
+			if math.Abs(skew-tc.wantSkew) > 1e-9 {
+				t.Errorf("endpointDistribution() skew = %v, want %v", skew, tc.wantSkew)
+			}
+			if wantSkewed := tc.wantSkew > maxZoneSkew; distribution.Skewed != wantSkewed {
+				t.Errorf("endpointDistribution() skewed = %v, want %v", distribution.Skewed, wantSkewed)
+			}
+		})
+	}
+}
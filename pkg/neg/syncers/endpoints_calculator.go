@@ -17,10 +17,13 @@ limitations under the License.
 package syncers
 
 import (
+	"sync"
+
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/ingress-gce/pkg/neg/metrics"
 	"k8s.io/ingress-gce/pkg/neg/types"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/klog"
@@ -98,6 +101,8 @@ func (l *LocalL4ILBEndpointsCalculator) CalculateEndpoints(ep *v1.Endpoints, cur
 // uses "ExternalTrafficPolicy: Cluster" mode This is the default mode.
 // In this mode, the endpoints of the NEG are calculated by selecting nodes at random. Upto 25(subset size limit in this
 // mode) are selected.
+// In clusters with both Linux and Windows node pools, only nodes running the operating systems of the nodes which host
+// the service endpoints are selected, since traffic is not forwarded across node pools of different operating systems.
 type ClusterL4ILBEndpointsCalculator struct {
 	// nodeLister is used for listing all the nodes in the cluster when calculating the subset.
 	nodeLister listers.NodeLister
@@ -123,6 +128,7 @@ func (l *ClusterL4ILBEndpointsCalculator) Mode() types.EndpointsCalculatorMode {
 func (l *ClusterL4ILBEndpointsCalculator) CalculateEndpoints(ep *v1.Endpoints, currentMap map[string]types.NetworkEndpointSet) (map[string]types.NetworkEndpointSet, types.EndpointPodMap, error) {
 	// In this mode, any of the cluster nodes can be part of the subset, whether or not a matching pod runs on it.
 	nodes, _ := utils.ListWithPredicate(l.nodeLister, utils.GetNodeConditionPredicate())
+	nodes = l.nodesWithEndpointsOS(nodes, ep)
 
 	nodeZoneMap := make(map[string][]*v1.Node)
 	for _, node := range nodes {
@@ -138,6 +144,36 @@ func (l *ClusterL4ILBEndpointsCalculator) CalculateEndpoints(ep *v1.Endpoints, c
 	return subsetMap, nil, err
 }
 
+// nodesWithEndpointsOS returns the nodes which run one of the operating
+// systems of the nodes hosting the endpoints in ep. All nodes are returned if
+// no endpoint has a known node.
+func (l *ClusterL4ILBEndpointsCalculator) nodesWithEndpointsOS(nodes []*v1.Node, ep *v1.Endpoints) []*v1.Node {
+	endpointsOS := sets.String{}
+	for _, curEp := range ep.Subsets {
+		for _, addr := range curEp.Addresses {
+			if addr.NodeName == nil {
+				continue
+			}
+			node, err := l.nodeLister.Get(*addr.NodeName)
+			if err != nil {
+				klog.V(2).Infof("Unable to find node %q of endpoint %q in Endpoints %s/%s, skipping: %v", *addr.NodeName, addr.IP, ep.Namespace, ep.Name, err)
+				continue
+			}
+			endpointsOS.Insert(utils.NodeOS(node))
+		}
+	}
+	if endpointsOS.Len() == 0 {
+		return nodes
+	}
+	var ret []*v1.Node
+	for _, node := range nodes {
+		if endpointsOS.Has(utils.NodeOS(node)) {
+			ret = append(ret, node)
+		}
+	}
+	return ret
+}
+
 // L7EndpointsCalculator implements methods to calculate Network endpoints for VM_IP_PORT NEGs
 type L7EndpointsCalculator struct {
 	zoneGetter          types.ZoneGetter
@@ -145,6 +181,13 @@ type L7EndpointsCalculator struct {
 	podLister           cache.Indexer
 	subsetLabels        string
 	networkEndpointType types.NetworkEndpointType
+
+	// cache holds the endpoints calculated from the Endpoints with the
+	// resource version cachedVersion.
+	cacheLock     sync.Mutex
+	cachedVersion string
+	cachedMap     map[string]types.NetworkEndpointSet
+	cachedPodMap  types.EndpointPodMap
 }
 
 func NewL7EndpointsCalculator(zoneGetter types.ZoneGetter, podLister cache.Indexer, svcPortName, subsetLabels string, endpointType types.NetworkEndpointType) *L7EndpointsCalculator {
@@ -163,6 +206,37 @@ func (l *L7EndpointsCalculator) Mode() types.EndpointsCalculatorMode {
 }
 
 // CalculateEndpoints determines the endpoints in the NEGs based on the current service endpoints and the current NEGs.
+// The endpoints are cached per resource version of the Endpoints, so that repeated syncs of unchanged Endpoints
+// skip the calculation. The returned maps must not be modified.
 func (l *L7EndpointsCalculator) CalculateEndpoints(ep *v1.Endpoints, currentMap map[string]types.NetworkEndpointSet) (map[string]types.NetworkEndpointSet, types.EndpointPodMap, error) {
-	return toZoneNetworkEndpointMap(ep, l.zoneGetter, l.servicePortName, l.podLister, l.subsetLabels, l.networkEndpointType)
+	cacheable := l.cacheable(ep)
+	l.cacheLock.Lock()
+	defer l.cacheLock.Unlock()
+	if cacheable && l.cachedVersion == ep.ResourceVersion {
+		metrics.PublishEndpointsCalculationCacheMetrics(true)
+		return l.cachedMap, l.cachedPodMap, nil
+	}
+	metrics.PublishEndpointsCalculationCacheMetrics(false)
+	endpointMap, podMap, err := toZoneNetworkEndpointMap(ep, l.zoneGetter, l.servicePortName, l.podLister, l.subsetLabels, l.networkEndpointType)
+	l.cachedVersion, l.cachedMap, l.cachedPodMap = "", nil, nil
+	if err == nil && cacheable {
+		l.cachedVersion, l.cachedMap, l.cachedPodMap = ep.ResourceVersion, endpointMap, podMap
+	}
+	return endpointMap, podMap, err
+}
+
+// cacheable returns true if the endpoints calculated from ep only depend on
+// ep itself. Otherwise they depend on the pods, which can change without a
+// change of ep: the labels of the pods select the endpoints of subset NEGs,
+// and the readiness gates of the pods select the not ready endpoints.
+func (l *L7EndpointsCalculator) cacheable(ep *v1.Endpoints) bool {
+	if ep == nil || ep.ResourceVersion == "" || l.subsetLabels != "" {
+		return false
+	}
+	for _, subset := range ep.Subsets {
+		if len(subset.NotReadyAddresses) > 0 {
+			return false
+		}
+	}
+	return true
 }
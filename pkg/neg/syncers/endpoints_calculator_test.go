@@ -24,6 +24,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	negtypes "k8s.io/ingress-gce/pkg/neg/types"
 	"k8s.io/legacy-cloud-providers/gce"
 )
@@ -174,3 +175,184 @@ func TestClusterGetEndpointSet(t *testing.T) {
 		}
 	}
 }
+
+// TestClusterGetEndpointSetMixedOS verifies that the ClusterL4ILBEndpointsCalculator only picks nodes of the operating
+// systems of the nodes running the service endpoints in clusters with Linux and Windows nodes.
+func TestClusterGetEndpointSetMixedOS(t *testing.T) {
+	t.Parallel()
+	mode := negtypes.L4ClusterMode
+	_, transactionSyncer := newL4ILBTestTransactionSyncer(negtypes.NewAdapter(gce.NewFakeGCECloud(gce.DefaultTestClusterValues())), mode)
+	// testInstance1 has no OS label and is assumed to run Linux.
+	nodeOS := map[string]string{
+		testInstance1: "",
+		testInstance2: "linux",
+		testInstance3: "linux",
+		testInstance4: "windows",
+		testInstance5: "windows",
+		testInstance6: "windows",
+	}
+	nodeNames := []string{testInstance1, testInstance2, testInstance3, testInstance4, testInstance5, testInstance6}
+	for i, name := range nodeNames {
+		node := &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: fmt.Sprintf("1.2.3.%d", i+1),
+					},
+				},
+				Conditions: []v1.NodeCondition{
+					{
+						Type:   v1.NodeReady,
+						Status: v1.ConditionTrue,
+					},
+				},
+			},
+		}
+		if os := nodeOS[name]; os != "" {
+			node.Labels = map[string]string{v1.LabelOSStable: os}
+		}
+		if err := transactionSyncer.nodeLister.Add(node); err != nil {
+			t.Errorf("Failed to add node %s to syncer's nodeLister, err %v", name, err)
+		}
+	}
+	zoneGetter := negtypes.NewFakeZoneGetter()
+	nodeLister := listers.NewNodeLister(transactionSyncer.nodeLister)
+
+	endpointsOnNodes := func(nodes ...string) *v1.Endpoints {
+		ep := &v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: testServiceName, Namespace: testServiceNamespace}}
+		var addresses []v1.EndpointAddress
+		for i := range nodes {
+			addresses = append(addresses, v1.EndpointAddress{
+				IP:        fmt.Sprintf("10.100.%d.1", i+1),
+				NodeName:  &nodes[i],
+				TargetRef: &v1.ObjectReference{Namespace: testServiceNamespace, Name: fmt.Sprintf("pod%d", i+1)},
+			})
+		}
+		ep.Subsets = []v1.EndpointSubset{{Addresses: addresses}}
+		return ep
+	}
+	linuxEndpoints := map[string]negtypes.NetworkEndpointSet{
+		negtypes.TestZone1: negtypes.NewNetworkEndpointSet(negtypes.NetworkEndpoint{IP: "1.2.3.1", Node: testInstance1}, negtypes.NetworkEndpoint{IP: "1.2.3.2", Node: testInstance2}),
+		negtypes.TestZone2: negtypes.NewNetworkEndpointSet(negtypes.NetworkEndpoint{IP: "1.2.3.3", Node: testInstance3}),
+	}
+	windowsEndpoints := map[string]negtypes.NetworkEndpointSet{
+		negtypes.TestZone2: negtypes.NewNetworkEndpointSet(negtypes.NetworkEndpoint{IP: "1.2.3.4", Node: testInstance4},
+			negtypes.NetworkEndpoint{IP: "1.2.3.5", Node: testInstance5}, negtypes.NetworkEndpoint{IP: "1.2.3.6", Node: testInstance6}),
+	}
+	allEndpoints := map[string]negtypes.NetworkEndpointSet{
+		negtypes.TestZone1: negtypes.NewNetworkEndpointSet(negtypes.NetworkEndpoint{IP: "1.2.3.1", Node: testInstance1}, negtypes.NetworkEndpoint{IP: "1.2.3.2", Node: testInstance2}),
+		negtypes.TestZone2: negtypes.NewNetworkEndpointSet(negtypes.NetworkEndpoint{IP: "1.2.3.3", Node: testInstance3}, negtypes.NetworkEndpoint{IP: "1.2.3.4", Node: testInstance4},
+			negtypes.NetworkEndpoint{IP: "1.2.3.5", Node: testInstance5}, negtypes.NetworkEndpoint{IP: "1.2.3.6", Node: testInstance6}),
+	}
+
+	testCases := []struct {
+		desc         string
+		endpoints    *v1.Endpoints
+		endpointSets map[string]negtypes.NetworkEndpointSet
+	}{
+		{
+			desc:         "endpoints on Linux nodes",
+			endpoints:    endpointsOnNodes(testInstance1, testInstance3),
+			endpointSets: linuxEndpoints,
+		},
+		{
+			desc:         "endpoints on Windows nodes",
+			endpoints:    endpointsOnNodes(testInstance5),
+			endpointSets: windowsEndpoints,
+		},
+		{
+			desc:         "endpoints on Linux and Windows nodes",
+			endpoints:    endpointsOnNodes(testInstance2, testInstance6),
+			endpointSets: allEndpoints,
+		},
+		{
+			desc:         "endpoints on unknown nodes",
+			endpoints:    endpointsOnNodes("unknown-node"),
+			endpointSets: allEndpoints,
+		},
+		{
+			desc:         "no endpoints",
+			endpoints:    &v1.Endpoints{},
+			endpointSets: allEndpoints,
+		},
+	}
+	svcKey := fmt.Sprintf("%s/%s", testServiceName, testServiceNamespace)
+	ec := NewClusterL4ILBEndpointsCalculator(nodeLister, zoneGetter, svcKey)
+	for _, tc := range testCases {
+		retSet, _, err := ec.CalculateEndpoints(tc.endpoints, nil)
+		if err != nil {
+			t.Errorf("For case %q, expect nil error, but got %v.", tc.desc, err)
+		}
+		if !reflect.DeepEqual(retSet, tc.endpointSets) {
+			t.Errorf("For case %q, expecting endpoint set %v, but got %v.", tc.desc, tc.endpointSets, retSet)
+		}
+	}
+}
+
+// countingZoneGetter counts the zone lookups of nodes.
+type countingZoneGetter struct {
+	negtypes.ZoneGetter
+	lookups int
+}
+
+func (z *countingZoneGetter) GetZoneForNode(name string) (string, error) {
+	z.lookups++
+	return z.ZoneGetter.GetZoneForNode(name)
+}
+
+// TestL7EndpointsCalculatorCache verifies that the L7EndpointsCalculator only
+// recalculates the endpoints when the Endpoints change.
+func TestL7EndpointsCalculatorCache(t *testing.T) {
+	t.Parallel()
+	zoneGetter := &countingZoneGetter{ZoneGetter: negtypes.NewFakeZoneGetter()}
+	ec := NewL7EndpointsCalculator(zoneGetter, nil, testNamedPort, "", negtypes.VmIpPortEndpointType)
+
+	ep := getDefaultEndpoint()
+	ep.ResourceVersion = "1"
+	var notReady [][]v1.EndpointAddress
+	for i := range ep.Subsets {
+		notReady = append(notReady, ep.Subsets[i].NotReadyAddresses)
+		ep.Subsets[i].NotReadyAddresses = nil
+	}
+
+	calculate := func(wantRecalculated bool) map[string]negtypes.NetworkEndpointSet {
+		t.Helper()
+		before := zoneGetter.lookups
+		endpoints, _, err := ec.CalculateEndpoints(ep, nil)
+		if err != nil {
+			t.Fatalf("CalculateEndpoints() = %v", err)
+		}
+		if recalculated := zoneGetter.lookups > before; recalculated != wantRecalculated {
+			t.Errorf("CalculateEndpoints() recalculated = %v, want %v", recalculated, wantRecalculated)
+		}
+		return endpoints
+	}
+
+	want := calculate(true)
+	if got := calculate(false); !reflect.DeepEqual(got, want) {
+		t.Errorf("CalculateEndpoints() from cache = %v, want %v", got, want)
+	}
+
+	// A new version of the Endpoints is recalculated.
+	ep.ResourceVersion = "2"
+	for i := range ep.Subsets {
+		ep.Subsets[i].Addresses = ep.Subsets[i].Addresses[1:]
+	}
+	if got := calculate(true); reflect.DeepEqual(got, want) {
+		t.Errorf("CalculateEndpoints() after change = %v, want different endpoints", got)
+	}
+	calculate(false)
+
+	// Not ready endpoints depend on the pods and are never cached.
+	ep.ResourceVersion = "3"
+	for i := range ep.Subsets {
+		ep.Subsets[i].NotReadyAddresses = notReady[i]
+	}
+	ec.podLister = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	calculate(true)
+	calculate(true)
+}
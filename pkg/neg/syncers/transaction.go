@@ -85,6 +85,13 @@ type transactionSyncer struct {
 
 	// customName indicates whether the NEG name is a generated one or custom one
 	customName bool
+
+	// negAnnotations are the annotations the NEGs were last ensured with.
+	negAnnotations map[string]string
+
+	// endpointDistribution is the distribution of the desired endpoints
+	// across zones as of the last sync. It is published in the NEG CR status.
+	endpointDistribution *negv1beta1.EndpointDistribution
 }
 
 func NewTransactionSyncer(negSyncerKey negtypes.NegSyncerKey, recorder record.EventRecorder, cloud negtypes.NetworkEndpointGroupCloud, zoneGetter negtypes.ZoneGetter, podLister cache.Indexer, serviceLister cache.Indexer, endpointLister cache.Indexer, nodeLister cache.Indexer, svcNegLister cache.Indexer, reflector readiness.Reflector, epc negtypes.NetworkEndpointsCalculator, kubeSystemUID string, svcNegClient svcnegclient.Interface, customName bool) negtypes.NegSyncer {
@@ -151,6 +158,10 @@ func (s *transactionSyncer) syncInternal() error {
 	start := time.Now()
 	defer metrics.PublishNegSyncMetrics(string(s.NegSyncerKey.NegType), string(s.endpointsCalculator.Mode()), err, start)
 
+	// NEG annotations track the service labels, re-ensure the NEGs when they change.
+	if !equalAnnotations(s.negAnnotations, negAnnotations(s.serviceLister, s.Namespace, s.Name)) {
+		s.needInit = true
+	}
 	if s.needInit {
 		if err := s.ensureNetworkEndpointGroups(); err != nil {
 			return err
@@ -199,6 +210,7 @@ func (s *transactionSyncer) syncInternal() error {
 		return err
 	}
 	s.logStats(targetMap, "desired NEG endpoints")
+	s.updateEndpointDistribution(targetMap)
 
 	// Calculate the endpoints to add and delete to transform the current state to desire state
 	addEndpoints, removeEndpoints := calculateNetworkEndpointDifference(targetMap, currentMap)
@@ -237,11 +249,12 @@ func (s *transactionSyncer) ensureNetworkEndpointGroups() error {
 		return err
 	}
 
+	var lock sync.Mutex
 	var errList []error
-	var negObjRefs []negv1beta1.NegObjectReference
-	for _, zone := range zones {
-		var negObj negv1beta1.NegObjectReference
-		negObj, err = ensureNetworkEndpointGroup(
+	negObjs := map[string]negv1beta1.NegObjectReference{}
+	annotations := negAnnotations(s.serviceLister, s.Namespace, s.Name)
+	forEachZone(zones, func(zone string) error {
+		negObj, err := ensureNetworkEndpointGroup(
 			s.Namespace,
 			s.Name,
 			s.NegSyncerKey.NegName,
@@ -255,16 +268,29 @@ func (s *transactionSyncer) ensureNetworkEndpointGroups() error {
 			s.recorder,
 			s.NegSyncerKey.GetAPIVersion(),
 			s.customName,
+			annotations,
 		)
+		lock.Lock()
+		defer lock.Unlock()
 		if err != nil {
 			errList = append(errList, err)
+		} else if s.svcNegClient != nil {
+			negObjs[zone] = negObj
 		}
+		return err
+	})
 
-		if s.svcNegClient != nil && err == nil {
+	// Keep the NEG references in the order of the zones.
+	var negObjRefs []negv1beta1.NegObjectReference
+	for _, zone := range zones {
+		if negObj, ok := negObjs[zone]; ok {
 			negObjRefs = append(negObjRefs, negObj)
 		}
 	}
 
+	if len(errList) == 0 {
+		s.negAnnotations = annotations
+	}
 	s.updateInitStatus(negObjRefs, errList)
 	return utilerrors.NewAggregate(errList)
 }
@@ -477,6 +503,23 @@ func (s *transactionSyncer) logEndpoints(endpointMap map[string]negtypes.Network
 	klog.V(3).Infof("For NEG %q, %s: %+v", s.NegSyncerKey.NegName, desc, endpointMap)
 }
 
+// updateEndpointDistribution computes the distribution of the endpoints of
+// targetMap across zones compared to the nodes, and flags the NEGs whose
+// endpoints are heavily skewed towards some zones.
+func (s *transactionSyncer) updateEndpointDistribution(targetMap map[string]negtypes.NetworkEndpointSet) {
+	nodes, err := nodesPerZone(s.nodeLister, s.zoneGetter)
+	if err != nil {
+		klog.Warningf("Failed to count nodes per zone for NEG %q: %v", s.NegSyncerKey.NegName, err)
+		return
+	}
+	distribution, skew := endpointDistribution(targetMap, nodes)
+	metrics.PublishEndpointZoneSkewMetrics(string(s.NegSyncerKey.NegType), skew)
+	if distribution.Skewed && (s.endpointDistribution == nil || !s.endpointDistribution.Skewed) {
+		klog.V(2).Infof("Endpoints of NEG %q for %s are skewed across zones: %+v", s.NegSyncerKey.NegName, s.NegSyncerKey.String(), distribution.Zones)
+	}
+	s.endpointDistribution = distribution
+}
+
 // updateInitStatus queries the k8s api server for the current NEG CR and updates the Initialized condition and neg objects as appropriate.
 // If neg client is nil, will return immediately
 func (s *transactionSyncer) updateInitStatus(negObjRefs []negv1beta1.NegObjectReference, errList []error) {
@@ -525,6 +568,9 @@ func (s *transactionSyncer) updateStatus(syncErr error) {
 
 	ensureCondition(neg, getSyncedCondition(syncErr))
 	neg.Status.LastSyncTime = ts
+	if s.endpointDistribution != nil {
+		neg.Status.EndpointDistribution = s.endpointDistribution
+	}
 
 	if len(neg.Status.NetworkEndpointGroups) == 0 {
 		s.needInit = true
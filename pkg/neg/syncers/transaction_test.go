@@ -223,6 +223,73 @@ func TestTransactionSyncNetworkEndpoints(t *testing.T) {
 	}
 }
 
+func TestTransactionSyncNetworkEndpointsWithFaults(t *testing.T) {
+	t.Parallel()
+
+	targetPort := "8080"
+	testCases := []struct {
+		desc          string
+		fault         negtypes.MockFault
+		expectInZone1 int
+	}{
+		{
+			desc:          "quota exceeded",
+			fault:         negtypes.MockFault{Err: negtypes.MockQuotaExceededError()},
+			expectInZone1: 0,
+		},
+		{
+			desc:          "timeout",
+			fault:         negtypes.MockFault{Err: negtypes.MockTimeoutError()},
+			expectInZone1: 0,
+		},
+		{
+			desc:          "partial attach",
+			fault:         negtypes.MockFault{Err: negtypes.MockTimeoutError(), PartialAttach: 4},
+			expectInZone1: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+		cloudErrors := negtypes.MockNetworkEndpointAPIs(fakeGCE)
+		fakeCloud := negtypes.NewAdapter(fakeGCE)
+		_, transactionSyncer := newTestTransactionSyncer(fakeCloud, negtypes.VmIpPortEndpointType, false)
+		if err := transactionSyncer.ensureNetworkEndpointGroups(); err != nil {
+			t.Fatalf("For case %q, ensureNetworkEndpointGroups() = %v, want nil", tc.desc, err)
+		}
+
+		cloudErrors.Inject(testZone1, negtypes.MockOpAttach, tc.fault)
+		// syncNetworkEndpoints consumes the endpoint sets, so build them per case.
+		addEndpoints := map[string]negtypes.NetworkEndpointSet{
+			testZone1: negtypes.NewNetworkEndpointSet().Union(generateEndpointSet(net.ParseIP("1.1.1.1"), 10, testInstance1, targetPort)),
+			testZone2: negtypes.NewNetworkEndpointSet().Union(generateEndpointSet(net.ParseIP("1.1.3.1"), 10, testInstance3, targetPort)),
+		}
+		if err := transactionSyncer.syncNetworkEndpoints(addEndpoints, nil); err != nil {
+			t.Errorf("For case %q, syncNetworkEndpoints() = %v, want nil", tc.desc, err)
+		}
+		if err := waitForTransactions(transactionSyncer); err != nil {
+			t.Errorf("For case %q, waitForTransactions() = %v, want nil", tc.desc, err)
+		}
+
+		for zone, want := range map[string]int{testZone1: tc.expectInZone1, testZone2: 10} {
+			list, err := fakeCloud.ListNetworkEndpoints(transactionSyncer.NegName, zone, false, transactionSyncer.NegSyncerKey.GetAPIVersion())
+			if err != nil {
+				t.Errorf("For case %q, ListNetworkEndpoints(%q) = %v, want nil", tc.desc, zone, err)
+			}
+			if len(list) != want {
+				t.Errorf("For case %q, got %d endpoints in zone %q, want %d", tc.desc, len(list), zone, want)
+			}
+		}
+
+		transactionSyncer.syncLock.Lock()
+		needInit := transactionSyncer.needInit
+		transactionSyncer.syncLock.Unlock()
+		if !needInit {
+			t.Errorf("For case %q, needInit = false after a failed attach, want true", tc.desc)
+		}
+	}
+}
+
 func TestCommitTransaction(t *testing.T) {
 	t.Parallel()
 	s, transactionSyncer := newTestTransactionSyncer(negtypes.NewAdapter(gce.NewFakeGCECloud(gce.DefaultTestClusterValues())), negtypes.VmIpPortEndpointType, false)
@@ -1384,11 +1451,12 @@ func waitForTransactions(syncer *transactionSyncer) error {
 func negObjectReferences(negs map[*meta.Key]*composite.NetworkEndpointGroup) map[string]negv1beta1.NegObjectReference {
 
 	negObjs := make(map[string]negv1beta1.NegObjectReference)
-	for _, neg := range negs {
+	for key, neg := range negs {
 		negObjs[neg.SelfLink] = negv1beta1.NegObjectReference{
 			Id:                  fmt.Sprint(neg.Id),
 			SelfLink:            neg.SelfLink,
 			NetworkEndpointType: negv1beta1.NetworkEndpointType(neg.NetworkEndpointType),
+			Zone:                key.Zone,
 		}
 	}
 	return negObjs
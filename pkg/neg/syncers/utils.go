@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
@@ -27,11 +28,13 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	negv1beta1 "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1"
 	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/flags"
 	negtypes "k8s.io/ingress-gce/pkg/neg/types"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/klog"
@@ -45,8 +48,38 @@ const (
 	minRetryDelay = 5 * time.Second
 	maxRetryDelay = 600 * time.Second
 	separator     = "||"
+	// maxZoneConcurrency is the maximum number of zones whose NEG API calls
+	// run concurrently.
+	maxZoneConcurrency = 5
 )
 
+// forEachZone calls fn for every zone concurrently, with at most
+// maxZoneConcurrency calls in flight. It waits for all calls to return and
+// returns the aggregate of their errors.
+func forEachZone(zones []string, fn func(zone string) error) error {
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var errList []error
+	sem := make(chan struct{}, maxZoneConcurrency)
+	for _, zone := range zones {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(zone string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(zone); err != nil {
+				lock.Lock()
+				errList = append(errList, err)
+				lock.Unlock()
+			}
+		}(zone)
+	}
+	wg.Wait()
+	return utilerrors.NewAggregate(errList)
+}
+
 // encodeEndpoint encodes ip and instance into a single string
 func encodeEndpoint(ip, instance, port string) string {
 	return strings.Join([]string{ip, instance, port}, separator)
@@ -113,8 +146,48 @@ func getService(serviceLister cache.Indexer, namespace, name string) *apiv1.Serv
 	return nil
 }
 
+// negAnnotations returns the labels of the service whose keys are listed in
+// the --neg-label-propagation-allowlist flag. These are set as annotations on
+// the NEGs of the service.
+func negAnnotations(serviceLister cache.Indexer, namespace, name string) map[string]string {
+	if flags.F.NegLabelPropagationAllowList == "" {
+		return nil
+	}
+	svc := getService(serviceLister, namespace, name)
+	if svc == nil {
+		return nil
+	}
+	var ret map[string]string
+	for _, key := range strings.Split(flags.F.NegLabelPropagationAllowList, ",") {
+		key = strings.TrimSpace(key)
+		if value, ok := svc.Labels[key]; ok && key != "" {
+			if ret == nil {
+				ret = map[string]string{}
+			}
+			ret[key] = value
+		}
+	}
+	return ret
+}
+
+// equalAnnotations returns true if both maps hold the same entries. A nil
+// map equals an empty one.
+func equalAnnotations(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
 // ensureNetworkEndpointGroup ensures corresponding NEG is configured correctly in the specified zone.
-func ensureNetworkEndpointGroup(svcNamespace, svcName, negName, zone, negServicePortName, kubeSystemUID, port string, networkEndpointType negtypes.NetworkEndpointType, cloud negtypes.NetworkEndpointGroupCloud, serviceLister cache.Indexer, recorder record.EventRecorder, version meta.Version, customName bool) (negv1beta1.NegObjectReference, error) {
+// NEGs cannot be updated, so a NEG whose annotations differ from annotations is recreated unless
+// it is in use.
+func ensureNetworkEndpointGroup(svcNamespace, svcName, negName, zone, negServicePortName, kubeSystemUID, port string, networkEndpointType negtypes.NetworkEndpointType, cloud negtypes.NetworkEndpointGroupCloud, serviceLister cache.Indexer, recorder record.EventRecorder, version meta.Version, customName bool, annotations map[string]string) (negv1beta1.NegObjectReference, error) {
 	var negRef negv1beta1.NegObjectReference
 	neg, err := cloud.GetNetworkEndpointGroup(negName, zone, version)
 	if err != nil {
@@ -162,6 +235,30 @@ func ensureNetworkEndpointGroup(svcNamespace, svcName, negName, zone, negService
 				}
 			}
 		}
+
+		if !needToCreate && !equalAnnotations(neg.Annotations, annotations) {
+			klog.V(2).Infof("NEG %q in %q has annotations %v, want %v. Deleting NEG.", negName, zone, neg.Annotations, annotations)
+			err = cloud.DeleteNetworkEndpointGroup(negName, zone, version)
+			switch {
+			case utils.IsInUsedByError(err):
+				klog.V(2).Infof("NEG %q in %q is in use, keeping its annotations: %v", negName, zone, err)
+				if recorder != nil && serviceLister != nil {
+					if svc := getService(serviceLister, svcNamespace, svcName); svc != nil {
+						recorder.Eventf(svc, apiv1.EventTypeWarning, "AnnotationsNotUpdated", "NEG %q for %s in %q is in use and its annotations cannot be updated.", negName, negServicePortName, zone)
+					}
+				}
+			case err != nil:
+				return negRef, err
+			default:
+				needToCreate = true
+				neg = nil
+				if recorder != nil && serviceLister != nil {
+					if svc := getService(serviceLister, svcNamespace, svcName); svc != nil {
+						recorder.Eventf(svc, apiv1.EventTypeNormal, "Delete", "Deleted NEG %q for %s in %q.", negName, negServicePortName, zone)
+					}
+				}
+			}
+		}
 	}
 
 	if needToCreate {
@@ -190,6 +287,7 @@ func ensureNetworkEndpointGroup(svcNamespace, svcName, negName, zone, negService
 			Network:             cloud.NetworkURL(),
 			Subnetwork:          subnetwork,
 			Description:         desc,
+			Annotations:         annotations,
 		}, zone)
 		if err != nil {
 			return negRef, err
@@ -214,6 +312,7 @@ func ensureNetworkEndpointGroup(svcNamespace, svcName, negName, zone, negService
 		Id:                  fmt.Sprint(neg.Id),
 		SelfLink:            neg.SelfLink,
 		NetworkEndpointType: negv1beta1.NetworkEndpointType(neg.NetworkEndpointType),
+		Zone:                zone,
 	}
 	return negRef, nil
 }
@@ -311,20 +410,28 @@ func retrieveExistingZoneNetworkEndpointMap(negName string, zoneGetter negtypes.
 		return nil, err
 	}
 
+	var lock sync.Mutex
 	zoneNetworkEndpointMap := map[string]negtypes.NetworkEndpointSet{}
-	for _, zone := range zones {
-		zoneNetworkEndpointMap[zone] = negtypes.NewNetworkEndpointSet()
+	err = forEachZone(zones, func(zone string) error {
 		networkEndpointsWithHealthStatus, err := cloud.ListNetworkEndpoints(negName, zone, false, version)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("failed to list network endpoints of NEG %s in zone %s: %w", negName, zone, err)
 		}
+		endpointSet := negtypes.NewNetworkEndpointSet()
 		for _, ne := range networkEndpointsWithHealthStatus {
 			newNE := negtypes.NetworkEndpoint{IP: ne.NetworkEndpoint.IpAddress, Node: ne.NetworkEndpoint.Instance}
 			if ne.NetworkEndpoint.Port != 0 {
 				newNE.Port = strconv.FormatInt(ne.NetworkEndpoint.Port, 10)
 			}
-			zoneNetworkEndpointMap[zone].Insert(newNE)
+			endpointSet.Insert(newNE)
 		}
+		lock.Lock()
+		zoneNetworkEndpointMap[zone] = endpointSet
+		lock.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return zoneNetworkEndpointMap, nil
 }
@@ -19,23 +19,73 @@ package syncers
 import (
 	"fmt"
 	"net"
+	"net/http"
 	"reflect"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/api/googleapi"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
 	negv1beta1 "k8s.io/ingress-gce/pkg/apis/svcneg/v1beta1"
 	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/flags"
 	negtypes "k8s.io/ingress-gce/pkg/neg/types"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/legacy-cloud-providers/gce"
 )
 
+func TestForEachZone(t *testing.T) {
+	var zones []string
+	for i := 0; i < 3*maxZoneConcurrency; i++ {
+		zones = append(zones, fmt.Sprintf("zone%d", i))
+	}
+	var lock sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	visited := sets.NewString()
+	err := forEachZone(zones, func(zone string) error {
+		lock.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		visited.Insert(zone)
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		inFlight--
+		lock.Unlock()
+		if zone == "zone1" || zone == "zone2" {
+			return fmt.Errorf("error in %s", zone)
+		}
+		return nil
+	})
+
+	if visited.Len() != len(zones) {
+		t.Errorf("forEachZone() visited %d zones, want %d", visited.Len(), len(zones))
+	}
+	if maxInFlight > maxZoneConcurrency {
+		t.Errorf("forEachZone() ran %d zones concurrently, want at most %d", maxInFlight, maxZoneConcurrency)
+	}
+	if maxInFlight < 2 {
+		t.Errorf("forEachZone() ran %d zones concurrently, want concurrent calls", maxInFlight)
+	}
+	agg, ok := err.(utilerrors.Aggregate)
+	if !ok || len(agg.Errors()) != 2 {
+		t.Errorf("forEachZone() = %v, want the errors of 2 zones", err)
+	}
+}
+
 func TestEncodeDecodeEndpoint(t *testing.T) {
 	ip := "10.0.0.10"
 	instance := "somehost"
@@ -381,6 +431,7 @@ func TestEnsureNetworkEndpointGroup(t *testing.T) {
 			nil,
 			tc.apiVersion,
 			false,
+			nil,
 		)
 		if err != nil {
 			t.Errorf("unexpected error: %s", err)
@@ -430,6 +481,7 @@ func TestEnsureNetworkEndpointGroup(t *testing.T) {
 			nil,
 			tc.apiVersion,
 			false,
+			nil,
 		)
 
 		if err != nil {
@@ -901,6 +953,7 @@ func TestNameUniqueness(t *testing.T) {
 		nil,
 		apiVersion,
 		false,
+		nil,
 	)
 	if err != nil {
 		t.Errorf("Errored while ensuring network endpoint groups: %s", err)
@@ -930,6 +983,7 @@ func TestNameUniqueness(t *testing.T) {
 		nil,
 		apiVersion,
 		false,
+		nil,
 	)
 
 	if err == nil {
@@ -972,6 +1026,7 @@ func TestNegObjectCrd(t *testing.T) {
 			nil,
 			apiVersion,
 			false,
+			nil,
 		)
 		if err != nil {
 			t.Errorf("Errored while ensuring network endpoint groups: %s", err)
@@ -991,6 +1046,7 @@ func TestNegObjectCrd(t *testing.T) {
 			Id:                  fmt.Sprint(neg.Id),
 			SelfLink:            neg.SelfLink,
 			NetworkEndpointType: negv1beta1.NetworkEndpointType(networkEndpointType),
+			Zone:                testZone,
 		}
 
 		if negObj != expectedNegObj {
@@ -1012,6 +1068,7 @@ func TestNegObjectCrd(t *testing.T) {
 			nil,
 			apiVersion,
 			false,
+			nil,
 		)
 
 		if err != nil {
@@ -1177,6 +1234,7 @@ func TestNEGRecreate(t *testing.T) {
 			nil,
 			apiVersion,
 			tc.customName,
+			nil,
 		)
 		if !tc.expectError && err != nil {
 			t.Errorf("TestCase: %s, Errored while ensuring network endpoint groups: %s", tc.desc, err)
@@ -1398,3 +1456,87 @@ func getDefaultEndpoint() *v1.Endpoints {
 		},
 	}
 }
+
+func TestEnsureNetworkEndpointGroupAnnotations(t *testing.T) {
+	var (
+		testZone       = "test-zone"
+		testNetwork    = cloud.ResourcePath("network", &meta.Key{Name: "test-network"})
+		testSubnetwork = cloud.ResourcePath("subnetwork", &meta.Key{Region: "test-region", Name: "test-subnetwork"})
+		negName        = "test-neg"
+		apiVersion     = meta.VersionGA
+		oldAnnotations = map[string]string{"team": "a"}
+		newAnnotations = map[string]string{"team": "b"}
+	)
+
+	for _, tc := range []struct {
+		desc              string
+		inUse             bool
+		expectAnnotations map[string]string
+	}{
+		{
+			desc:              "unused NEG is recreated",
+			expectAnnotations: newAnnotations,
+		},
+		{
+			desc:              "NEG in use is kept",
+			inUse:             true,
+			expectAnnotations: oldAnnotations,
+		},
+	} {
+		fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+		cloudErrors := negtypes.MockNetworkEndpointAPIs(fakeGCE)
+		fakeCloud := negtypes.NewAdapterWithNetwork(fakeGCE, testNetwork, testSubnetwork)
+		ensure := func(annotations map[string]string) error {
+			_, err := ensureNetworkEndpointGroup(testServiceNamespace, testServiceName, negName, testZone, "", "kube-system-uid", "80",
+				negtypes.VmIpPortEndpointType, fakeCloud, nil, nil, apiVersion, false, annotations)
+			return err
+		}
+
+		if err := ensure(oldAnnotations); err != nil {
+			t.Fatalf("%s: ensureNetworkEndpointGroup() = %v", tc.desc, err)
+		}
+		if tc.inUse {
+			cloudErrors.Inject(testZone, negtypes.MockOpDelete, negtypes.MockFault{
+				Err: &googleapi.Error{Code: http.StatusBadRequest, Message: "The network_endpoint_group resource is already being used by a backend service"},
+			})
+		}
+		if err := ensure(newAnnotations); err != nil {
+			t.Errorf("%s: ensureNetworkEndpointGroup() = %v, want nil", tc.desc, err)
+		}
+
+		neg, err := fakeCloud.GetNetworkEndpointGroup(negName, testZone, apiVersion)
+		if err != nil {
+			t.Fatalf("%s: GetNetworkEndpointGroup() = %v", tc.desc, err)
+		}
+		if !reflect.DeepEqual(neg.Annotations, tc.expectAnnotations) {
+			t.Errorf("%s: got annotations %v, want %v", tc.desc, neg.Annotations, tc.expectAnnotations)
+		}
+	}
+}
+
+func TestNegAnnotations(t *testing.T) {
+	defer func(allowList string) { flags.F.NegLabelPropagationAllowList = allowList }(flags.F.NegLabelPropagationAllowList)
+
+	serviceLister := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	serviceLister.Add(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testServiceNamespace,
+			Name:      testServiceName,
+			Labels:    map[string]string{"team": "a", "cost-center": "42", "app": "foo"},
+		},
+	})
+
+	for _, tc := range []struct {
+		allowList string
+		expect    map[string]string
+	}{
+		{allowList: "", expect: nil},
+		{allowList: "owner", expect: nil},
+		{allowList: "team, cost-center,owner", expect: map[string]string{"team": "a", "cost-center": "42"}},
+	} {
+		flags.F.NegLabelPropagationAllowList = tc.allowList
+		if got := negAnnotations(serviceLister, testServiceNamespace, testServiceName); !reflect.DeepEqual(got, tc.expect) {
+			t.Errorf("negAnnotations() with allow list %q = %v, want %v", tc.allowList, got, tc.expect)
+		}
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package types
 
 import (
+	"context"
+
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
 	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/legacy-cloud-providers/gce"
@@ -56,41 +58,41 @@ type cloudProviderAdapter struct {
 
 // GetNetworkEndpointGroup inmplements NetworkEndpointGroupCloud.
 func (a *cloudProviderAdapter) GetNetworkEndpointGroup(name string, zone string, version meta.Version) (*composite.NetworkEndpointGroup, error) {
-	return composite.GetNetworkEndpointGroup(a.c, meta.ZonalKey(name, zone), version)
+	return composite.GetNetworkEndpointGroup(context.TODO(), a.c, meta.ZonalKey(name, zone), version)
 
 }
 
 // ListNetworkEndpointGroup implements NetworkEndpointGroupCloud.
 func (a *cloudProviderAdapter) ListNetworkEndpointGroup(zone string, version meta.Version) ([]*composite.NetworkEndpointGroup, error) {
-	return composite.ListNetworkEndpointGroups(a.c, meta.ZonalKey("", zone), version)
+	return composite.ListNetworkEndpointGroups(context.TODO(), a.c, meta.ZonalKey("", zone), version)
 }
 
 // AggregatedListNetworkEndpointGroup returns a map of zone -> endpoint group.
 func (a *cloudProviderAdapter) AggregatedListNetworkEndpointGroup(version meta.Version) (map[*meta.Key]*composite.NetworkEndpointGroup, error) {
 	// TODO: filter for the region the cluster is in.
-	return composite.AggregatedListNetworkEndpointGroup(a.c, version)
+	return composite.AggregatedListNetworkEndpointGroup(context.TODO(), a.c, version)
 }
 
 // CreateNetworkEndpointGroup implements NetworkEndpointGroupCloud.
 func (a *cloudProviderAdapter) CreateNetworkEndpointGroup(neg *composite.NetworkEndpointGroup, zone string) error {
-	return composite.CreateNetworkEndpointGroup(a.c, meta.ZonalKey(neg.Name, zone), neg)
+	return composite.CreateNetworkEndpointGroup(context.TODO(), a.c, meta.ZonalKey(neg.Name, zone), neg)
 }
 
 // DeleteNetworkEndpointGroup implements NetworkEndpointGroupCloud.
 func (a *cloudProviderAdapter) DeleteNetworkEndpointGroup(name string, zone string, version meta.Version) error {
-	return composite.DeleteNetworkEndpointGroup(a.c, meta.ZonalKey(name, zone), version)
+	return composite.DeleteNetworkEndpointGroup(context.TODO(), a.c, meta.ZonalKey(name, zone), version)
 }
 
 // AttachNetworkEndpoints implements NetworkEndpointGroupCloud.
 func (a cloudProviderAdapter) AttachNetworkEndpoints(name, zone string, endpoints []*composite.NetworkEndpoint, version meta.Version) error {
 	req := &composite.NetworkEndpointGroupsAttachEndpointsRequest{NetworkEndpoints: endpoints}
-	return composite.AttachNetworkEndpoints(a.c, meta.ZonalKey(name, zone), version, req)
+	return composite.AttachNetworkEndpoints(context.TODO(), a.c, meta.ZonalKey(name, zone), version, req)
 }
 
 // DetachNetworkEndpoints implements NetworkEndpointGroupCloud.
 func (a *cloudProviderAdapter) DetachNetworkEndpoints(name, zone string, endpoints []*composite.NetworkEndpoint, version meta.Version) error {
 	req := &composite.NetworkEndpointGroupsDetachEndpointsRequest{NetworkEndpoints: endpoints}
-	return composite.DetachNetworkEndpoints(a.c, meta.ZonalKey(name, zone), version, req)
+	return composite.DetachNetworkEndpoints(context.TODO(), a.c, meta.ZonalKey(name, zone), version, req)
 }
 
 // ListNetworkEndpoints implements NetworkEndpointGroupCloud.
@@ -100,7 +102,21 @@ func (a *cloudProviderAdapter) ListNetworkEndpoints(name, zone string, showHealt
 		healthStatus = "SHOW"
 	}
 	req := &composite.NetworkEndpointGroupsListEndpointsRequest{HealthStatus: healthStatus}
-	return composite.ListNetworkEndpoints(a.c, meta.ZonalKey(name, zone), version, req)
+	return composite.ListNetworkEndpoints(context.TODO(), a.c, meta.ZonalKey(name, zone), version, req)
+}
+
+// ListBackendServices returns the global backend services and the backend
+// services in the region of the cluster.
+func (a *cloudProviderAdapter) ListBackendServices(version meta.Version) ([]*composite.BackendService, error) {
+	backendServices, err := composite.ListBackendServices(context.TODO(), a.c, meta.GlobalKey(""), version)
+	if err != nil {
+		return nil, err
+	}
+	regionalBackendServices, err := composite.ListBackendServices(context.TODO(), a.c, meta.RegionalKey("", a.c.Region()), version)
+	if err != nil {
+		return nil, err
+	}
+	return append(backendServices, regionalBackendServices...), nil
 }
 
 // NetworkURL implements NetworkEndpointGroupCloud.
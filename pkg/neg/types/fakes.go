@@ -71,6 +71,7 @@ func (f *fakeZoneGetter) GetZoneForNode(name string) (string, error) {
 type FakeNetworkEndpointGroupCloud struct {
 	NetworkEndpointGroups map[string][]*composite.NetworkEndpointGroup
 	NetworkEndpoints      map[string][]*composite.NetworkEndpoint
+	BackendServices       []*composite.BackendService
 	Subnetwork            string
 	Network               string
 	mu                    sync.Mutex
@@ -199,6 +200,12 @@ func (f *FakeNetworkEndpointGroupCloud) ListNetworkEndpoints(name, zone string,
 	return ret, nil
 }
 
+func (f *FakeNetworkEndpointGroupCloud) ListBackendServices(version meta.Version) ([]*composite.BackendService, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.BackendServices, nil
+}
+
 func (f *FakeNetworkEndpointGroupCloud) NetworkURL() string {
 	return f.Network
 }
@@ -38,6 +38,7 @@ type NetworkEndpointGroupCloud interface {
 	AttachNetworkEndpoints(name, zone string, endpoints []*composite.NetworkEndpoint, version meta.Version) error
 	DetachNetworkEndpoints(name, zone string, endpoints []*composite.NetworkEndpoint, version meta.Version) error
 	ListNetworkEndpoints(name, zone string, showHealthStatus bool, version meta.Version) ([]*composite.NetworkEndpointWithHealthStatus, error)
+	ListBackendServices(version meta.Version) ([]*composite.BackendService, error)
 	NetworkURL() string
 	SubnetworkURL() string
 }
@@ -51,13 +51,65 @@ func GetNetworkEndpointStore(negCloud NetworkEndpointGroupCloud) NetworkEndpoint
 	return ret
 }
 
-func MockNetworkEndpointAPIs(fakeGCE *gce.Cloud) {
+// MockNetworkEndpointAPIs installs the NEG API mocks on fakeGCE. Faults
+// injected into the returned MockErrorInjector make the mocked calls fail.
+func MockNetworkEndpointAPIs(fakeGCE *gce.Cloud) *MockErrorInjector {
+	injector := NewMockErrorInjector()
 	m := (fakeGCE.Compute().(*cloud.MockGCE))
 	m.MockNetworkEndpointGroups.X = NetworkEndpointStore{}
-	m.MockNetworkEndpointGroups.AttachNetworkEndpointsHook = MockAttachNetworkEndpointsHook
-	m.MockNetworkEndpointGroups.DetachNetworkEndpointsHook = MockDetachNetworkEndpointsHook
-	m.MockNetworkEndpointGroups.ListNetworkEndpointsHook = MockListNetworkEndpointsHook
-	m.MockNetworkEndpointGroups.AggregatedListHook = MockAggregatedListNetworkEndpointGroupHook
+	m.MockNetworkEndpointGroups.InsertHook = func(ctx context.Context, key *meta.Key, obj *compute.NetworkEndpointGroup, m *cloud.MockNetworkEndpointGroups) (bool, error) {
+		if f := injector.fault(key.Zone, MockOpCreate); f != nil {
+			return true, f.Err
+		}
+		return false, nil
+	}
+	m.MockNetworkEndpointGroups.DeleteHook = func(ctx context.Context, key *meta.Key, m *cloud.MockNetworkEndpointGroups) (bool, error) {
+		if f := injector.fault(key.Zone, MockOpDelete); f != nil {
+			return true, f.Err
+		}
+		return false, nil
+	}
+	m.MockNetworkEndpointGroups.GetHook = func(ctx context.Context, key *meta.Key, m *cloud.MockNetworkEndpointGroups) (bool, *compute.NetworkEndpointGroup, error) {
+		if f := injector.fault(key.Zone, MockOpGet); f != nil {
+			return true, nil, f.Err
+		}
+		return false, nil, nil
+	}
+	m.MockNetworkEndpointGroups.AttachNetworkEndpointsHook = func(ctx context.Context, key *meta.Key, obj *compute.NetworkEndpointGroupsAttachEndpointsRequest, m *cloud.MockNetworkEndpointGroups) error {
+		f := injector.fault(key.Zone, MockOpAttach)
+		if f == nil {
+			return MockAttachNetworkEndpointsHook(ctx, key, obj, m)
+		}
+		if f.PartialAttach > 0 && len(obj.NetworkEndpoints) > 0 {
+			partial := *obj
+			if f.PartialAttach < len(obj.NetworkEndpoints) {
+				partial.NetworkEndpoints = obj.NetworkEndpoints[:f.PartialAttach]
+			}
+			if err := MockAttachNetworkEndpointsHook(ctx, key, &partial, m); err != nil {
+				return err
+			}
+		}
+		return f.Err
+	}
+	m.MockNetworkEndpointGroups.DetachNetworkEndpointsHook = func(ctx context.Context, key *meta.Key, obj *compute.NetworkEndpointGroupsDetachEndpointsRequest, m *cloud.MockNetworkEndpointGroups) error {
+		if f := injector.fault(key.Zone, MockOpDetach); f != nil {
+			return f.Err
+		}
+		return MockDetachNetworkEndpointsHook(ctx, key, obj, m)
+	}
+	m.MockNetworkEndpointGroups.ListNetworkEndpointsHook = func(ctx context.Context, key *meta.Key, obj *compute.NetworkEndpointGroupsListEndpointsRequest, fl *filter.F, m *cloud.MockNetworkEndpointGroups) ([]*compute.NetworkEndpointWithHealthStatus, error) {
+		if f := injector.fault(key.Zone, MockOpListEndpoints); f != nil {
+			return nil, f.Err
+		}
+		return MockListNetworkEndpointsHook(ctx, key, obj, fl, m)
+	}
+	m.MockNetworkEndpointGroups.AggregatedListHook = func(ctx context.Context, fl *filter.F, m *cloud.MockNetworkEndpointGroups) (bool, map[string][]*compute.NetworkEndpointGroup, error) {
+		if f := injector.fault(MockAnyZone, MockOpAggregatedList); f != nil {
+			return true, nil, f.Err
+		}
+		return MockAggregatedListNetworkEndpointGroupHook(ctx, fl, m)
+	}
+	return injector
 }
 
 // TODO: move this logic into code gen
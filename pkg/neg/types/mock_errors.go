@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"google.golang.org/api/googleapi"
+)
+
+// MockOperation identifies a mocked NEG API call that faults can be injected into.
+type MockOperation string
+
+const (
+	MockOpCreate         = MockOperation("Create")
+	MockOpDelete         = MockOperation("Delete")
+	MockOpGet            = MockOperation("Get")
+	MockOpAggregatedList = MockOperation("AggregatedList")
+	MockOpAttach         = MockOperation("AttachNetworkEndpoints")
+	MockOpDetach         = MockOperation("DetachNetworkEndpoints")
+	MockOpListEndpoints  = MockOperation("ListNetworkEndpoints")
+
+	// MockAnyZone matches calls in every zone. AggregatedList calls are
+	// not zonal and are only matched by MockAnyZone.
+	MockAnyZone = ""
+)
+
+// MockFault describes an injected failure.
+type MockFault struct {
+	// Err is returned by the faulted call.
+	Err error
+	// Times is the number of calls that fail before the fault clears
+	// itself. Zero means every call fails until the fault is cleared.
+	Times int
+	// PartialAttach is the number of endpoints that are attached before an
+	// AttachNetworkEndpoints call fails. It is ignored for other operations.
+	PartialAttach int
+}
+
+type mockFaultKey struct {
+	zone string
+	op   MockOperation
+}
+
+// MockErrorInjector holds the faults injected into the mocked NEG APIs.
+// It is safe for concurrent use.
+type MockErrorInjector struct {
+	lock   sync.Mutex
+	faults map[mockFaultKey]*MockFault
+}
+
+// NewMockErrorInjector returns an injector without faults.
+func NewMockErrorInjector() *MockErrorInjector {
+	return &MockErrorInjector{faults: make(map[mockFaultKey]*MockFault)}
+}
+
+// Inject makes op calls in zone fail as described by fault, replacing any
+// fault previously injected for the same zone and operation.
+func (i *MockErrorInjector) Inject(zone string, op MockOperation, fault MockFault) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.faults[mockFaultKey{zone, op}] = &fault
+}
+
+// Clear removes the fault injected for op in zone.
+func (i *MockErrorInjector) Clear(zone string, op MockOperation) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	delete(i.faults, mockFaultKey{zone, op})
+}
+
+// fault returns the fault to apply to an op call in zone, if any. Faults
+// injected for the zone take precedence over MockAnyZone faults.
+func (i *MockErrorInjector) fault(zone string, op MockOperation) *MockFault {
+	if i == nil {
+		return nil
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for _, key := range []mockFaultKey{{zone, op}, {MockAnyZone, op}} {
+		f, ok := i.faults[key]
+		if !ok {
+			continue
+		}
+		ret := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				delete(i.faults, key)
+			}
+		}
+		return &ret
+	}
+	return nil
+}
+
+// MockQuotaExceededError returns an error like the one GCE returns when a
+// quota is exhausted.
+func MockQuotaExceededError() error {
+	return &googleapi.Error{
+		Code:    http.StatusForbidden,
+		Message: "Quota exceeded",
+		Errors:  []googleapi.ErrorItem{{Reason: "quotaExceeded", Message: "Quota exceeded"}},
+	}
+}
+
+// MockTimeoutError returns the error seen when a call exceeds its timeout.
+func MockTimeoutError() error {
+	return context.DeadlineExceeded
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/utils"
+	"k8s.io/legacy-cloud-providers/gce"
+)
+
+func TestMockErrorInjector(t *testing.T) {
+	t.Parallel()
+	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
+	injector := MockNetworkEndpointAPIs(fakeGCE)
+	negCloud := NewAdapter(fakeGCE)
+
+	for _, zone := range []string{"zone1", "zone2"} {
+		if err := negCloud.CreateNetworkEndpointGroup(&composite.NetworkEndpointGroup{Name: "neg", Version: meta.VersionGA}, zone); err != nil {
+			t.Fatalf("CreateNetworkEndpointGroup(%q) = %v", zone, err)
+		}
+	}
+
+	injector.Inject(MockAnyZone, MockOpGet, MockFault{Err: MockTimeoutError()})
+	injector.Inject("zone1", MockOpGet, MockFault{Err: MockQuotaExceededError(), Times: 1})
+
+	// The zonal fault takes precedence and clears itself after one call.
+	if _, err := negCloud.GetNetworkEndpointGroup("neg", "zone1", meta.VersionGA); !utils.IsHTTPErrorCode(err, 403) {
+		t.Errorf("GetNetworkEndpointGroup(zone1) = %v, want quota exceeded", err)
+	}
+	for _, zone := range []string{"zone1", "zone2"} {
+		if _, err := negCloud.GetNetworkEndpointGroup("neg", zone, meta.VersionGA); err != MockTimeoutError() {
+			t.Errorf("GetNetworkEndpointGroup(%q) = %v, want timeout", zone, err)
+		}
+	}
+
+	injector.Clear(MockAnyZone, MockOpGet)
+	if _, err := negCloud.GetNetworkEndpointGroup("neg", "zone2", meta.VersionGA); err != nil {
+		t.Errorf("GetNetworkEndpointGroup(zone2) = %v, want nil after clearing the fault", err)
+	}
+}
@@ -46,6 +46,8 @@ type TestContext struct {
 	KubeClient   kubernetes.Interface
 	SvcNegClient svcnegclient.Interface
 	Cloud        *gce.Cloud
+	// CloudErrors injects failures into the mocked NEG APIs of Cloud.
+	CloudErrors *MockErrorInjector
 
 	NegNamer NetworkEndpointGroupNamer
 	L4Namer  namer.L4ResourcesNamer
@@ -69,7 +71,7 @@ func NewTestContext() *TestContext {
 func NewTestContextWithKubeClient(kubeClient kubernetes.Interface) *TestContext {
 	negClient := negfake.NewSimpleClientset()
 	fakeGCE := gce.NewFakeGCECloud(gce.DefaultTestClusterValues())
-	MockNetworkEndpointAPIs(fakeGCE)
+	cloudErrors := MockNetworkEndpointAPIs(fakeGCE)
 
 	clusterNamer := namer.NewNamer(clusterID, "")
 	l4namer := namer.NewL4Namer(kubeSystemUID, clusterNamer)
@@ -78,6 +80,7 @@ func NewTestContextWithKubeClient(kubeClient kubernetes.Interface) *TestContext
 		KubeClient:       kubeClient,
 		SvcNegClient:     negClient,
 		Cloud:            fakeGCE,
+		CloudErrors:      cloudErrors,
 		NegNamer:         clusterNamer,
 		L4Namer:          l4namer,
 		IngressInformer:  informernetworking.NewIngressInformer(kubeClient, namespace, resyncPeriod, utils.NewNamespaceIndexer()),
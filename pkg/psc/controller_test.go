@@ -745,11 +745,11 @@ func createForwardingRule(c *gce.Cloud, frName, ipAddr string) (*composite.Forwa
 		LoadBalancingScheme: string(cloud.SchemeInternal),
 		IPAddress:           ipAddr,
 	}
-	if err = composite.CreateForwardingRule(c, key, fwdRule); err != nil {
+	if err = composite.CreateForwardingRule(context2.TODO(), c, key, fwdRule); err != nil {
 		return nil, fmt.Errorf("Failed to create fake forwarding rule %s:  %q", frName, err)
 	}
 
-	rule, err := composite.GetForwardingRule(c, key, meta.VersionGA)
+	rule, err := composite.GetForwardingRule(context2.TODO(), c, key, meta.VersionGA)
 	if err != nil {
 		return rule, fmt.Errorf("Failed to get forwarding rule: %q", err)
 	}
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package quota implements per-namespace limits on the resources provisioned
+// by the controller, so that a single tenant cannot exhaust the GCE quota of
+// a shared project.
+//
+// Objects of a namespace are ranked by creation time. An object is rejected if
+// it would exceed a limit once all objects created before it are counted, so
+// that objects which are already provisioned keep precedence over new ones.
+package quota
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/ingress-gce/pkg/annotations"
+)
+
+// Limits are the per-namespace limits on resources provisioned by the
+// controller. Zero means unlimited.
+type Limits struct {
+	// Ingresses is the maximum number of Ingresses.
+	Ingresses int
+	// Certificates is the maximum number of certificates, summed over the
+	// TLS secrets and pre-shared certificates of all Ingresses.
+	Certificates int
+	// NEGServices is the maximum number of Services with NEGs enabled.
+	NEGServices int
+}
+
+// ExceededError is returned when provisioning an object would exceed a limit.
+type ExceededError struct {
+	Resource  string
+	Namespace string
+	Limit     int
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("limit of %d %s in namespace %s exceeded", e.Limit, e.Resource, e.Namespace)
+}
+
+// IsExceeded returns true if err is an ExceededError.
+func IsExceeded(err error) bool {
+	_, ok := err.(*ExceededError)
+	return ok
+}
+
+// CheckIngress returns an ExceededError if provisioning ing would exceed the
+// limits, given ings, the Ingresses handled by the controller. Only Ingresses
+// in the namespace of ing are counted.
+func CheckIngress(ing *v1.Ingress, ings []*v1.Ingress, limits Limits) error {
+	if limits.Ingresses <= 0 && limits.Certificates <= 0 {
+		return nil
+	}
+	var objs []metav1.Object
+	byKey := map[string]*v1.Ingress{}
+	for _, other := range ings {
+		if other.Namespace != ing.Namespace || other.DeletionTimestamp != nil {
+			continue
+		}
+		objs = append(objs, other)
+		byKey[other.Name] = other
+	}
+	if _, ok := byKey[ing.Name]; !ok {
+		objs = append(objs, ing)
+		byKey[ing.Name] = ing
+	}
+
+	ranked := rank(objs)
+	certs := 0
+	for i, obj := range ranked {
+		certs += CertificateCount(byKey[obj.GetName()])
+		if obj.GetName() != ing.Name {
+			continue
+		}
+		if limits.Ingresses > 0 && i+1 > limits.Ingresses {
+			return &ExceededError{Resource: "Ingresses", Namespace: ing.Namespace, Limit: limits.Ingresses}
+		}
+		if limits.Certificates > 0 && certs > limits.Certificates {
+			return &ExceededError{Resource: "certificates", Namespace: ing.Namespace, Limit: limits.Certificates}
+		}
+		break
+	}
+	return nil
+}
+
+// CertificateCount returns the number of certificates used by ing, which are
+// its TLS secrets and its pre-shared certificates.
+func CertificateCount(ing *v1.Ingress) int {
+	count := len(ing.Spec.TLS)
+	if preShared := annotations.FromIngress(ing).UseNamedTLS(); preShared != "" {
+		for _, name := range strings.Split(preShared, ",") {
+			if strings.TrimSpace(name) != "" {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// CheckNEGService returns an ExceededError if provisioning NEGs for svc would
+// exceed limit, given svcs, the Services of the cluster. Only Services in the
+// namespace of svc for which negEnabled returns true are counted.
+func CheckNEGService(svc *apiv1.Service, svcs []*apiv1.Service, limit int, negEnabled func(*apiv1.Service) bool) error {
+	if limit <= 0 {
+		return nil
+	}
+	objs := []metav1.Object{svc}
+	for _, other := range svcs {
+		if other.Namespace != svc.Namespace || other.Name == svc.Name || !negEnabled(other) {
+			continue
+		}
+		objs = append(objs, other)
+	}
+	for i, obj := range rank(objs) {
+		if obj.GetName() == svc.Name {
+			if i+1 > limit {
+				return &ExceededError{Resource: "NEG enabled Services", Namespace: svc.Namespace, Limit: limit}
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// rank sorts objs by creation time, oldest first. Ties are broken by name.
+func rank(objs []metav1.Object) []metav1.Object {
+	sort.SliceStable(objs, func(i, j int) bool {
+		ti, tj := objs[i].GetCreationTimestamp(), objs[j].GetCreationTimestamp()
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return objs[i].GetName() < objs[j].GetName()
+	})
+	return objs
+}
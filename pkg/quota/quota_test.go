@@ -0,0 +1,140 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/ingress-gce/pkg/annotations"
+)
+
+var baseTime = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newIngress(namespace, name string, age time.Duration, tls int, preShared string) *v1.Ingress {
+	ing := &v1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(baseTime.Add(-age)),
+			Annotations:       map[string]string{},
+		},
+	}
+	for i := 0; i < tls; i++ {
+		ing.Spec.TLS = append(ing.Spec.TLS, v1.IngressTLS{SecretName: "secret"})
+	}
+	if preShared != "" {
+		ing.Annotations[annotations.PreSharedCertKey] = preShared
+	}
+	return ing
+}
+
+func TestCertificateCount(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		ing  *v1.Ingress
+		want int
+	}{
+		{desc: "no certificates", ing: newIngress("ns", "ing", 0, 0, ""), want: 0},
+		{desc: "TLS secrets", ing: newIngress("ns", "ing", 0, 2, ""), want: 2},
+		{desc: "pre-shared certificates", ing: newIngress("ns", "ing", 0, 0, "cert1, cert2,"), want: 2},
+		{desc: "TLS secrets and pre-shared certificates", ing: newIngress("ns", "ing", 0, 1, "cert1"), want: 2},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := CertificateCount(tc.ing); got != tc.want {
+				t.Errorf("CertificateCount() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckIngress(t *testing.T) {
+	oldest := newIngress("ns", "oldest", 3*time.Hour, 1, "")
+	older := newIngress("ns", "older", 2*time.Hour, 2, "")
+	newest := newIngress("ns", "newest", time.Hour, 1, "")
+	otherNamespace := newIngress("other", "ing", 4*time.Hour, 5, "")
+	deleted := newIngress("ns", "deleted", 5*time.Hour, 5, "")
+	deleted.DeletionTimestamp = &metav1.Time{Time: baseTime}
+	all := []*v1.Ingress{newest, otherNamespace, older, deleted, oldest}
+
+	for _, tc := range []struct {
+		desc    string
+		ing     *v1.Ingress
+		limits  Limits
+		wantErr bool
+	}{
+		{desc: "unlimited", ing: newest, limits: Limits{}},
+		{desc: "within ingress limit", ing: newest, limits: Limits{Ingresses: 3}},
+		{desc: "older ingress within limit", ing: older, limits: Limits{Ingresses: 2}},
+		{desc: "newest ingress over limit", ing: newest, limits: Limits{Ingresses: 2}, wantErr: true},
+		{desc: "within certificate limit", ing: newest, limits: Limits{Certificates: 4}},
+		{desc: "older ingress within certificate limit", ing: older, limits: Limits{Certificates: 3}},
+		{desc: "newest ingress over certificate limit", ing: newest, limits: Limits{Certificates: 3}, wantErr: true},
+		{desc: "ingress not yet in store", ing: newIngress("ns", "new", 0, 0, ""), limits: Limits{Ingresses: 3}, wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := CheckIngress(tc.ing, all, tc.limits)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("CheckIngress() = %v, want error %t", err, tc.wantErr)
+			}
+			if err != nil && !IsExceeded(err) {
+				t.Errorf("IsExceeded(%v) = false, want true", err)
+			}
+		})
+	}
+}
+
+func TestCheckNEGService(t *testing.T) {
+	newService := func(name string, age time.Duration, neg bool) *apiv1.Service {
+		svc := &apiv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "ns",
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(baseTime.Add(-age)),
+				Annotations:       map[string]string{},
+			},
+		}
+		if neg {
+			svc.Annotations[annotations.NEGAnnotationKey] = `{"ingress":true}`
+		}
+		return svc
+	}
+	negEnabled := func(svc *apiv1.Service) bool {
+		_, ok := svc.Annotations[annotations.NEGAnnotationKey]
+		return ok
+	}
+	older := newService("older", 3*time.Hour, true)
+	withoutNEG := newService("without-neg", 2*time.Hour, false)
+	newer := newService("newer", time.Hour, true)
+	all := []*apiv1.Service{newer, withoutNEG, older}
+
+	if err := CheckNEGService(older, all, 1, negEnabled); err != nil {
+		t.Errorf("CheckNEGService(%s) = %v, want nil", older.Name, err)
+	}
+	if err := CheckNEGService(newer, all, 2, negEnabled); err != nil {
+		t.Errorf("CheckNEGService(%s) = %v, want nil", newer.Name, err)
+	}
+	if err := CheckNEGService(newer, all, 1, negEnabled); !IsExceeded(err) {
+		t.Errorf("CheckNEGService(%s) = %v, want ExceededError", newer.Name, err)
+	}
+	if err := CheckNEGService(newer, all, 0, negEnabled); err != nil {
+		t.Errorf("CheckNEGService(%s) with no limit = %v, want nil", newer.Name, err)
+	}
+}
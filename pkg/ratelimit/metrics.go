@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+const (
+	rateLimitSubsystem = "ratelimit"
+	// usageWindow is the window over which the utilization of a rate limiter
+	// is measured.
+	usageWindow = time.Minute
+)
+
+var (
+	rateLimitLabels = []string{"version", "service", "operation"}
+
+	qpsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("", rateLimitSubsystem, "qps"),
+		"Configured QPS of the GCE API rate limiter",
+		rateLimitLabels, nil,
+	)
+	burstDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("", rateLimitSubsystem, "burst"),
+		"Configured burst of the GCE API rate limiter",
+		rateLimitLabels, nil,
+	)
+	utilizationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("", rateLimitSubsystem, "utilization_ratio"),
+		"Ratio of the tokens consumed to the tokens made available by the GCE API rate limiter over the last completed minute",
+		rateLimitLabels, nil,
+	)
+)
+
+// rateLimit is a configured rate limiter and its utilization.
+type rateLimit struct {
+	limiter flowcontrol.RateLimiter
+	qps     float64
+	burst   int
+	usage   *usage
+}
+
+// usage counts the tokens consumed from a rate limiter in fixed windows.
+type usage struct {
+	lock sync.Mutex
+	qps  float64
+	// windowStart is the start of the current window.
+	windowStart time.Time
+	// consumed is the number of tokens consumed in the current window.
+	consumed int
+	// ratio is the utilization of the last completed window.
+	ratio float64
+	now   func() time.Time
+}
+
+func newUsage(qps float64, now func() time.Time) *usage {
+	return &usage{qps: qps, windowStart: now(), now: now}
+}
+
+// consume records that a token was consumed.
+func (u *usage) consume() {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	u.roll()
+	u.consumed++
+}
+
+// utilization returns the ratio of the tokens consumed to the tokens made
+// available in the last completed window.
+func (u *usage) utilization() float64 {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	u.roll()
+	return u.ratio
+}
+
+// roll completes the current window if it is over. A window without any
+// consumed token completed in the meantime has a zero utilization.
+func (u *usage) roll() {
+	elapsed := u.now().Sub(u.windowStart)
+	if elapsed < usageWindow {
+		return
+	}
+	windows := elapsed / usageWindow
+	u.ratio = 0
+	if windows == 1 {
+		u.ratio = float64(u.consumed) / (u.qps * usageWindow.Seconds())
+	}
+	u.windowStart = u.windowStart.Add(windows * usageWindow)
+	u.consumed = 0
+}
+
+// countingRateLimiter records the tokens accepted by a rate limiter.
+type countingRateLimiter struct {
+	cloud.RateLimiter
+	usage *usage
+}
+
+// Accept implements cloud.RateLimiter.
+func (rl *countingRateLimiter) Accept(ctx context.Context, key *cloud.RateLimitKey) error {
+	if err := rl.RateLimiter.Accept(ctx, key); err != nil {
+		return err
+	}
+	rl.usage.consume()
+	return nil
+}
+
+// Describe implements prometheus.Collector.
+func (l *GCERateLimiter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- qpsDesc
+	ch <- burstDesc
+	ch <- utilizationDesc
+}
+
+// Collect implements prometheus.Collector. It exports the settings and
+// utilization of every configured rate limiter, so that operators can tune
+// them based on data.
+func (l *GCERateLimiter) Collect(ch chan<- prometheus.Metric) {
+	for key, impl := range l.rateLimitImpls {
+		labels := []string{string(key.Version), key.Service, key.Operation}
+		ch <- prometheus.MustNewConstMetric(qpsDesc, prometheus.GaugeValue, impl.qps, labels...)
+		ch <- prometheus.MustNewConstMetric(burstDesc, prometheus.GaugeValue, float64(impl.burst), labels...)
+		ch <- prometheus.MustNewConstMetric(utilizationDesc, prometheus.GaugeValue, impl.usage.utilization(), labels...)
+	}
+}
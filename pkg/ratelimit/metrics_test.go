@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestUsage(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	u := newUsage(2, func() time.Time { return now })
+
+	// 2 QPS make 120 tokens available per window.
+	for i := 0; i < 60; i++ {
+		u.consume()
+	}
+	if got := u.utilization(); got != 0 {
+		t.Errorf("utilization() = %v during the first window, want 0", got)
+	}
+	now = now.Add(usageWindow)
+	if got := u.utilization(); got != 0.5 {
+		t.Errorf("utilization() = %v, want 0.5", got)
+	}
+	// An idle window has no utilization.
+	now = now.Add(usageWindow)
+	if got := u.utilization(); got != 0 {
+		t.Errorf("utilization() = %v after an idle window, want 0", got)
+	}
+	u.consume()
+	now = now.Add(3 * usageWindow)
+	if got := u.utilization(); got != 0 {
+		t.Errorf("utilization() = %v after idle windows, want 0", got)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	rl, err := NewGCERateLimiter([]string{"ga.BackendServices.Get,qps,2,10"}, time.Second)
+	if err != nil {
+		t.Fatalf("NewGCERateLimiter() = %v", err)
+	}
+	now := time.Unix(1600000000, 0)
+	impl := rl.rateLimitImpls[cloud.RateLimitKey{Version: meta.VersionGA, Service: "BackendServices", Operation: "Get"}]
+	impl.usage = newUsage(impl.qps, func() time.Time { return now })
+
+	key := &cloud.RateLimitKey{ProjectID: "project", Version: meta.VersionGA, Service: "BackendServices", Operation: "Get"}
+	for i := 0; i < 6; i++ {
+		if err := rl.Accept(context.Background(), key); err != nil {
+			t.Fatalf("Accept() = %v", err)
+		}
+	}
+	now = now.Add(usageWindow)
+
+	ch := make(chan prometheus.Metric, 10)
+	rl.Collect(ch)
+	close(ch)
+	got := map[string]float64{}
+	for m := range ch {
+		var pb dto.Metric
+		if err := m.Write(&pb); err != nil {
+			t.Fatalf("Write() = %v", err)
+		}
+		got[m.Desc().String()] = pb.GetGauge().GetValue()
+	}
+	for desc, want := range map[*prometheus.Desc]float64{
+		qpsDesc:         2,
+		burstDesc:       10,
+		utilizationDesc: 0.05,
+	} {
+		if got[desc.String()] != want {
+			t.Errorf("%v = %v, want %v", desc, got[desc.String()], want)
+		}
+	}
+}
@@ -32,7 +32,7 @@ import (
 // GCERateLimiter implements cloud.RateLimiter
 type GCERateLimiter struct {
 	// Map a RateLimitKey to its rate limiter implementation.
-	rateLimitImpls map[cloud.RateLimitKey]flowcontrol.RateLimiter
+	rateLimitImpls map[cloud.RateLimitKey]*rateLimit
 	// Minimum polling interval for getting operations. Underlying operations rate limiter
 	// may increase the time.
 	operationPollInterval time.Duration
@@ -42,7 +42,7 @@ type GCERateLimiter struct {
 // returns a properly configured cloud.RateLimiter implementation.
 // Expected format of specs: {"[version].[service].[operation],[type],[param1],[param2],..", "..."}
 func NewGCERateLimiter(specs []string, operationPollInterval time.Duration) (*GCERateLimiter, error) {
-	rateLimitImpls := make(map[cloud.RateLimitKey]flowcontrol.RateLimiter)
+	rateLimitImpls := make(map[cloud.RateLimitKey]*rateLimit)
 	// Within each specification, split on comma to get the operation,
 	// rate limiter type, and extra parameters.
 	for _, spec := range specs {
@@ -79,7 +79,7 @@ func (l *GCERateLimiter) Accept(ctx context.Context, key *cloud.RateLimitKey) er
 	impl := l.rateLimitImpl(key)
 	if impl != nil {
 		// Wrap the flowcontrol.RateLimiter with a AcceptRateLimiter and handle context.
-		rl = &cloud.AcceptRateLimiter{Acceptor: impl}
+		rl = &countingRateLimiter{RateLimiter: &cloud.AcceptRateLimiter{Acceptor: impl.limiter}, usage: impl.usage}
 	} else {
 		// Check the context then use the cloud NopRateLimiter which accepts immediately.
 		select {
@@ -101,9 +101,9 @@ func (l *GCERateLimiter) Accept(ctx context.Context, key *cloud.RateLimitKey) er
 	return rl.Accept(ctx, key)
 }
 
-// rateLimitImpl returns the flowcontrol.RateLimiter implementation
-// associated with the passed in key.
-func (l *GCERateLimiter) rateLimitImpl(key *cloud.RateLimitKey) flowcontrol.RateLimiter {
+// rateLimitImpl returns the rate limiter implementation associated with the
+// passed in key.
+func (l *GCERateLimiter) rateLimitImpl(key *cloud.RateLimitKey) *rateLimit {
 	// Since the passed in key will have the ProjectID field filled in, we need to
 	// create a copy which does not, so that retreiving the rate limiter implementation
 	// through the map works as expected.
@@ -136,9 +136,9 @@ func constructRateLimitKey(param string) (cloud.RateLimitKey, error) {
 	return retVal, nil
 }
 
-// constructRateLimitImpl parses the slice and returns a flowcontrol.RateLimiter
+// constructRateLimitImpl parses the slice and returns a rate limiter.
 // Expected format is [type],[param1],[param2],...
-func constructRateLimitImpl(params []string) (flowcontrol.RateLimiter, error) {
+func constructRateLimitImpl(params []string) (*rateLimit, error) {
 	// For now, only the "qps" type is supported.
 	rlType := params[0]
 	implArgs := params[1:]
@@ -154,7 +154,12 @@ func constructRateLimitImpl(params []string) (flowcontrol.RateLimiter, error) {
 		if err != nil {
 			return nil, fmt.Errorf("invalid argument for rate limiter type %v. Expected %v to be a int.", rlType, implArgs[1])
 		}
-		return flowcontrol.NewTokenBucketRateLimiter(float32(qps), burst), nil
+		return &rateLimit{
+			limiter: flowcontrol.NewTokenBucketRateLimiter(float32(qps), burst),
+			qps:     qps,
+			burst:   burst,
+			usage:   newUsage(qps, time.Now),
+		}, nil
 	}
 	return nil, fmt.Errorf("invalid rate limiter type provided: %v", rlType)
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package sync
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -65,13 +66,13 @@ func (s *IngressSyncer) Sync(state interface{}) error {
 }
 
 // GC implements Syncer.
-func (s *IngressSyncer) GC(ings []*v1.Ingress, currIng *v1.Ingress, frontendGCAlgorithm utils.FrontendGCAlgorithm, scope meta.KeyType) error {
+func (s *IngressSyncer) GC(ctx context.Context, ings []*v1.Ingress, currIng *v1.Ingress, frontendGCAlgorithm utils.FrontendGCAlgorithm, scope meta.KeyType) error {
 	var lbErr, err error
 	var errs []error
 	switch frontendGCAlgorithm {
 	case utils.CleanupV2FrontendResources:
 		klog.V(3).Infof("Using algorithm CleanupV2FrontendResources to GC frontend of ingress %s", common.NamespacedName(currIng))
-		lbErr = s.controller.GCv2LoadBalancer(currIng, scope)
+		lbErr = s.controller.GCv2LoadBalancer(ctx, currIng, scope)
 
 		defer func() {
 			if err != nil {
@@ -81,7 +82,7 @@ func (s *IngressSyncer) GC(ings []*v1.Ingress, currIng *v1.Ingress, frontendGCAl
 		}()
 	case utils.CleanupV2FrontendResourcesScopeChange:
 		klog.V(3).Infof("Using algorithm CleanupV2FrontendResourcesScopeChange to GC frontend of ingress %s", common.NamespacedName(currIng))
-		lbErr = s.controller.GCv2LoadBalancer(currIng, scope)
+		lbErr = s.controller.GCv2LoadBalancer(ctx, currIng, scope)
 	case utils.CleanupV1FrontendResources:
 		klog.V(3).Infof("Using algorithm CleanupV1FrontendResources to GC frontend of ingress %s", common.NamespacedName(currIng))
 		// Filter GCE ingresses that use v1 naming scheme.
@@ -92,7 +93,7 @@ func (s *IngressSyncer) GC(ings []*v1.Ingress, currIng *v1.Ingress, frontendGCAl
 		toCleanupV1, toKeepV1 := v1Ingresses.Partition(utils.NeedsCleanup)
 		// Note that only GCE ingress associated resources are managed by this controller.
 		toKeepV1Gce := toKeepV1.Filter(utils.IsGCEIngress)
-		lbErr = s.controller.GCv1LoadBalancers(toKeepV1Gce.AsList())
+		lbErr = s.controller.GCv1LoadBalancers(ctx, toKeepV1Gce.AsList())
 
 		defer func() {
 			if err != nil {
@@ -116,7 +117,7 @@ func (s *IngressSyncer) GC(ings []*v1.Ingress, currIng *v1.Ingress, frontendGCAl
 	toKeep := operator.Ingresses(ings).Filter(func(ing *v1.Ingress) bool {
 		return !utils.NeedsCleanup(ing)
 	}).AsList()
-	if beErr := s.controller.GCBackends(toKeep); beErr != nil {
+	if beErr := s.controller.GCBackends(ctx, toKeep); beErr != nil {
 		errs = append(errs, fmt.Errorf("error running backend garbage collection routine: %v", beErr))
 	}
 	if errs != nil {
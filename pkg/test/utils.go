@@ -19,6 +19,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/ingress-gce/pkg/annotations"
 	backendconfig "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
+	compositemetrics "k8s.io/ingress-gce/pkg/composite/metrics"
 	"k8s.io/ingress-gce/pkg/utils"
 	"k8s.io/legacy-cloud-providers/gce"
 )
@@ -255,6 +256,11 @@ func Int64ToPtr(val int64) *int64 {
 	return &val
 }
 
+// BoolToPtr returns bool ptr for given bool.
+func BoolToPtr(val bool) *bool {
+	return &val
+}
+
 type FakeRecorderSource struct{}
 
 func (_ *FakeRecorderSource) Recorder(ns string) record.EventRecorder {
@@ -406,3 +412,18 @@ func (old *L4ILBErrorMetricInfo) ValidateDiff(new, expect *L4ILBErrorMetricInfo,
 	}
 
 }
+
+// ExpectAPICallBudget runs the scenario and fails the test if the GCE API calls
+// it made through composite exceed the budget. It returns the calls made by the
+// scenario. The test must not run in parallel with other tests which call GCE.
+func ExpectAPICallBudget(t *testing.T, budget compositemetrics.Budget, scenario func()) compositemetrics.CallCounts {
+	t.Helper()
+
+	before := compositemetrics.Snapshot()
+	scenario()
+	calls := compositemetrics.Snapshot().Sub(before)
+	if err := calls.CheckBudget(budget); err != nil {
+		t.Errorf("%v, calls: %v", err, calls)
+	}
+	return calls
+}
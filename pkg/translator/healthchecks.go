@@ -80,7 +80,8 @@ type HealthCheck struct {
 	// As the {HTTP, HTTPS, HTTP2} settings are identical, we mantain the
 	// settings at the outer-level and copy into the appropriate struct
 	// in the HealthCheck embedded struct (see `merge()`) when getting the
-	// compute struct back.
+	// compute struct back. Only the port settings and the proxy header are
+	// used for {TCP, SSL} health checks.
 	computealpha.HTTPHealthCheck
 	computealpha.HealthCheck
 }
@@ -109,6 +110,18 @@ func NewHealthCheck(hc *computealpha.HealthCheck) (*HealthCheck, error) {
 			return nil, fmt.Errorf(newHealthCheckErrorMessageTemplate, annotations.ProtocolHTTP2, hc.Name)
 		}
 		v.HTTPHealthCheck = computealpha.HTTPHealthCheck(*hc.Http2HealthCheck)
+	case annotations.ProtocolTCP:
+		if hc.TcpHealthCheck == nil {
+			return nil, fmt.Errorf(newHealthCheckErrorMessageTemplate, annotations.ProtocolTCP, hc.Name)
+		}
+		t := hc.TcpHealthCheck
+		v.HTTPHealthCheck = computealpha.HTTPHealthCheck{Port: t.Port, PortName: t.PortName, PortSpecification: t.PortSpecification, ProxyHeader: t.ProxyHeader}
+	case annotations.ProtocolSSL:
+		if hc.SslHealthCheck == nil {
+			return nil, fmt.Errorf(newHealthCheckErrorMessageTemplate, annotations.ProtocolSSL, hc.Name)
+		}
+		s := hc.SslHealthCheck
+		v.HTTPHealthCheck = computealpha.HTTPHealthCheck{Port: s.Port, PortName: s.PortName, PortSpecification: s.PortSpecification, ProxyHeader: s.ProxyHeader}
 	}
 
 	// Users should be modifying HTTP(S) specific settings on the embedded
@@ -116,6 +129,8 @@ func NewHealthCheck(hc *computealpha.HealthCheck) (*HealthCheck, error) {
 	v.HealthCheck.HttpHealthCheck = nil
 	v.HealthCheck.HttpsHealthCheck = nil
 	v.HealthCheck.Http2HealthCheck = nil
+	v.HealthCheck.TcpHealthCheck = nil
+	v.HealthCheck.SslHealthCheck = nil
 
 	return v, nil
 }
@@ -125,6 +140,16 @@ func (hc *HealthCheck) Protocol() annotations.AppProtocol {
 	return annotations.AppProtocol(hc.Type)
 }
 
+// IsHTTP returns true if the health check sends HTTP requests, i.e. if it has
+// a request path and a host.
+func (hc *HealthCheck) IsHTTP() bool {
+	switch hc.Protocol() {
+	case annotations.ProtocolTCP, annotations.ProtocolSSL:
+		return false
+	}
+	return true
+}
+
 // ToComputeHealthCheck returns a valid compute.HealthCheck object
 func (hc *HealthCheck) ToComputeHealthCheck() (*compute.HealthCheck, error) {
 	hc.merge()
@@ -156,6 +181,8 @@ func (hc *HealthCheck) merge() {
 	hc.HealthCheck.Http2HealthCheck = nil
 	hc.HealthCheck.HttpsHealthCheck = nil
 	hc.HealthCheck.HttpHealthCheck = nil
+	hc.HealthCheck.TcpHealthCheck = nil
+	hc.HealthCheck.SslHealthCheck = nil
 
 	switch hc.Protocol() {
 	case annotations.ProtocolHTTP:
@@ -167,6 +194,12 @@ func (hc *HealthCheck) merge() {
 	case annotations.ProtocolHTTP2:
 		http2 := computealpha.HTTP2HealthCheck(hc.HTTPHealthCheck)
 		hc.HealthCheck.Http2HealthCheck = &http2
+	case annotations.ProtocolTCP:
+		x := hc.HTTPHealthCheck
+		hc.HealthCheck.TcpHealthCheck = &computealpha.TCPHealthCheck{Port: x.Port, PortName: x.PortName, PortSpecification: x.PortSpecification, ProxyHeader: x.ProxyHeader}
+	case annotations.ProtocolSSL:
+		x := hc.HTTPHealthCheck
+		hc.HealthCheck.SslHealthCheck = &computealpha.SSLHealthCheck{Port: x.Port, PortName: x.PortName, PortSpecification: x.PortSpecification, ProxyHeader: x.ProxyHeader}
 	}
 }
 
@@ -198,7 +231,7 @@ func (hc *HealthCheck) UpdateFromBackendConfig(c *backendconfigv1.HealthCheckCon
 	if c.Type != nil {
 		hc.Type = *c.Type
 	}
-	if c.RequestPath != nil {
+	if c.RequestPath != nil && hc.IsHTTP() {
 		hc.RequestPath = *c.RequestPath
 	}
 	if c.Port != nil {
@@ -206,6 +239,12 @@ func (hc *HealthCheck) UpdateFromBackendConfig(c *backendconfigv1.HealthCheckCon
 		// This override is necessary regardless of type
 		hc.PortSpecification = "USE_FIXED_PORT"
 	}
+	if c.PortSpecification != nil {
+		hc.PortSpecification = *c.PortSpecification
+	}
+	if c.ProxyHeader != nil {
+		hc.ProxyHeader = *c.ProxyHeader
+	}
 }
 
 // DefaultHealthCheck simply returns the default health check.
@@ -289,6 +328,12 @@ func ApplyProbeSettingsToHC(p *v1.Probe, hc *HealthCheck) {
 		return
 	}
 
+	if !hc.IsHTTP() {
+		// Only the timeouts of the probe apply to {TCP, SSL} health checks.
+		applyProbeTimeouts(p, hc)
+		return
+	}
+
 	healthPath := p.Handler.HTTPGet.Path
 	// GCE requires a leading "/" for health check urls.
 	if !strings.HasPrefix(healthPath, "/") {
@@ -306,6 +351,12 @@ func ApplyProbeSettingsToHC(p *v1.Probe, hc *HealthCheck) {
 	}
 	hc.Host = host
 
+	applyProbeTimeouts(p, hc)
+}
+
+// applyProbeTimeouts applies the timeout and period of the Pod healthcheck to
+// the healthcheck.
+func applyProbeTimeouts(p *v1.Probe, hc *HealthCheck) {
 	hc.TimeoutSec = int64(p.TimeoutSeconds)
 	if hc.ForNEG {
 		// For NEG mode, we can support more aggressive healthcheck interval.
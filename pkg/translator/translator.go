@@ -17,10 +17,9 @@ package translator
 
 import (
 	"context"
-	"crypto/md5"
 	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
 	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
@@ -28,6 +27,7 @@ import (
 	v1 "k8s.io/api/networking/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/ingress-gce/pkg/annotations"
 	frontendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/frontendconfig/v1beta1"
 	"k8s.io/ingress-gce/pkg/composite"
 	"k8s.io/ingress-gce/pkg/flags"
@@ -53,6 +53,9 @@ type Env struct {
 	Subnetwork string
 	Region     string
 	Project    string
+	// IPVersion is the IP version of the forwarding rules. IPv4 is used if
+	// empty.
+	IPVersion string
 }
 
 // NewEnv returns an Env for the given Ingress.
@@ -129,91 +132,6 @@ func secrets(env *Env) ([]*api_v1.Secret, []error) {
 	return ret, errors
 }
 
-// The gce api uses the name of a path rule to match a host rule.
-const hostRulePrefix = "host"
-
-// ToCompositeURLMap translates the given hostname: endpoint->port mapping into a gce url map.
-//
-// HostRule: Conceptually contains all PathRules for a given host.
-// PathMatcher: Associates a path rule with a host rule. Mostly an optimization.
-// PathRule: Maps a single path regex to a backend.
-//
-// The GCE url map allows multiple hosts to share url->backend mappings without duplication, eg:
-//   Host: foo(PathMatcher1), bar(PathMatcher1,2)
-//   PathMatcher1:
-//     /a -> b1
-//     /b -> b2
-//   PathMatcher2:
-//     /c -> b1
-// This leads to a lot of complexity in the common case, where all we want is a mapping of
-// host->{/path: backend}.
-//
-// Consider some alternatives:
-// 1. Using a single backend per PathMatcher:
-//   Host: foo(PathMatcher1,3) bar(PathMatcher1,2,3)
-//   PathMatcher1:
-//     /a -> b1
-//   PathMatcher2:
-//     /c -> b1
-//   PathMatcher3:
-//     /b -> b2
-// 2. Using a single host per PathMatcher:
-//   Host: foo(PathMatcher1)
-//   PathMatcher1:
-//     /a -> b1
-//     /b -> b2
-//   Host: bar(PathMatcher2)
-//   PathMatcher2:
-//     /a -> b1
-//     /b -> b2
-//     /c -> b1
-// In the context of kubernetes services, 2 makes more sense, because we
-// rarely want to lookup backends (service:nodeport). When a service is
-// deleted, we need to find all host PathMatchers that have the backend
-// and remove the mapping. When a new path is added to a host (happens
-// more frequently than service deletion) we just need to lookup the 1
-// pathmatcher of the host.
-func ToCompositeURLMap(g *utils.GCEURLMap, namer namer.IngressFrontendNamer, key *meta.Key) *composite.UrlMap {
-	defaultBackendName := g.DefaultBackend.BackendName()
-	key.Name = defaultBackendName
-	resourceID := cloud.ResourceID{ProjectID: "", Resource: "backendServices", Key: key}
-	m := &composite.UrlMap{
-		Name:           namer.UrlMap(),
-		DefaultService: resourceID.ResourcePath(),
-	}
-
-	for _, hostRule := range g.HostRules {
-		// Create a host rule
-		// Create a path matcher
-		// Add all given endpoint:backends to pathRules in path matcher
-		pmName := getNameForPathMatcher(hostRule.Hostname)
-		m.HostRules = append(m.HostRules, &composite.HostRule{
-			Hosts:       []string{hostRule.Hostname},
-			PathMatcher: pmName,
-		})
-
-		pathMatcher := &composite.PathMatcher{
-			Name:           pmName,
-			DefaultService: m.DefaultService,
-			PathRules:      []*composite.PathRule{},
-		}
-
-		// GCE ensures that matched rule with longest prefix wins.
-		for _, rule := range hostRule.Paths {
-			beName := rule.Backend.BackendName()
-			key.Name = beName
-			resourceID := cloud.ResourceID{ProjectID: "", Resource: "backendServices", Key: key}
-			beLink := resourceID.ResourcePath()
-			pathMatcher.PathRules = append(pathMatcher.PathRules, &composite.PathRule{
-				Paths:   []string{rule.Path},
-				Service: beLink,
-			})
-		}
-		m.PathMatchers = append(m.PathMatchers, pathMatcher)
-	}
-	return m
-}
-
 // ToRedirectUrlMap returns the UrlMap used for HTTPS Redirects on a L7 ELB
 // This function returns nil if no url map needs to be created
 func (t *Translator) ToRedirectUrlMap(env *Env, version meta.Version) *composite.UrlMap {
@@ -236,14 +154,6 @@ func (t *Translator) ToRedirectUrlMap(env *Env, version meta.Version) *composite
 	return expectedMap
 }
 
-// getNameForPathMatcher returns a name for a pathMatcher based on the given host rule.
-// The host rule can be a regex, the path matcher name used to associate the 2 cannot.
-func getNameForPathMatcher(hostRule string) string {
-	hasher := md5.New()
-	hasher.Write([]byte(hostRule))
-	return fmt.Sprintf("%v%v", hostRulePrefix, hex.EncodeToString(hasher.Sum(nil)))
-}
-
 const (
 	httpDefaultPortRange  = "80-80"
 	httpsDefaultPortRange = "443-443"
@@ -267,6 +177,9 @@ func (t *Translator) ToCompositeForwardingRule(env *Env, protocol namer.NamerPro
 		Description: description,
 		Version:     version,
 	}
+	if env.IPVersion == annotations.IPv6Version {
+		fr.IpVersion = annotations.IPv6Version
+	}
 
 	if t.IsL7ILB {
 		fr.LoadBalancingScheme = "INTERNAL_MANAGED"
@@ -329,18 +242,23 @@ func (t *Translator) ToCompositeTargetHttpsProxy(env *Env, description string, v
 	return proxy, sslPolicySet, nil
 }
 
-func (t *Translator) ToCompositeSSLCertificates(env *Env, tlsName string, tls []*TLSCerts, version meta.Version) []*composite.SslCertificate {
+// ToCompositeSSLCertificates returns the pre-shared certificates listed in
+// tlsName followed by the certificates of the TLS Secrets. Invalid pre-shared
+// certificates are left out and returned as an error.
+func (t *Translator) ToCompositeSSLCertificates(env *Env, tlsName string, tls []*TLSCerts, version meta.Version) ([]*composite.SslCertificate, error) {
 	var certs []*composite.SslCertificate
+	var errs []error
 
 	// Pre-shared certs
 	tlsNames := utils.SplitAnnotation(tlsName)
 	for _, name := range tlsNames {
-		resID := cloud.ResourceID{Resource: "sslCertificates", Key: &meta.Key{Name: name}, ProjectID: env.Project}
-		if t.IsL7ILB {
-			resID.Key.Region = env.Region
+		resID, err := t.preSharedCertID(env, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
 		}
 		preSharedCert := &composite.SslCertificate{
-			Name:     name,
+			Name:     resID.Key.Name,
 			SelfLink: resID.SelfLink(version),
 		}
 		certs = append(certs, preSharedCert)
@@ -363,7 +281,39 @@ func (t *Translator) ToCompositeSSLCertificates(env *Env, tlsName string, tls []
 		certs = append(certs, cert)
 	}
 
-	return certs
+	if len(errs) > 0 {
+		return certs, utils.JoinErrs(errs)
+	}
+	return certs, nil
+}
+
+// preSharedCertID returns the resource ID of a pre-shared certificate given
+// either its name, for a certificate of the project of the load balancer, or
+// its URL, for a certificate of any project. The certificate must be regional
+// in the region of the load balancer for L7-ILB and global otherwise.
+func (t *Translator) preSharedCertID(env *Env, name string) (*cloud.ResourceID, error) {
+	if !strings.Contains(name, "/") {
+		resID := &cloud.ResourceID{Resource: "sslCertificates", Key: &meta.Key{Name: name}, ProjectID: env.Project}
+		if t.IsL7ILB {
+			resID.Key.Region = env.Region
+		}
+		return resID, nil
+	}
+	resID, err := cloud.ParseResourceURL(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pre-shared certificate %q: %v", name, err)
+	}
+	if resID.Resource != "sslCertificates" || resID.ProjectID == "" {
+		return nil, fmt.Errorf("invalid pre-shared certificate %q: not the URL of an SSL certificate", name)
+	}
+	if t.IsL7ILB {
+		if resID.Key.Type() != meta.Regional || resID.Key.Region != env.Region {
+			return nil, fmt.Errorf("invalid pre-shared certificate %q: must be an SSL certificate of region %q", name, env.Region)
+		}
+	} else if resID.Key.Type() != meta.Global {
+		return nil, fmt.Errorf("invalid pre-shared certificate %q: must be a global SSL certificate", name)
+	}
+	return resID, nil
 }
 
 // sslPolicyLink returns the ref to the ssl policy that is described by the
@@ -25,6 +25,7 @@ import (
 	v1 "k8s.io/api/networking/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/ingress-gce/pkg/annotations"
 	frontendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/frontendconfig/v1beta1"
 	"k8s.io/ingress-gce/pkg/flags"
 
@@ -54,6 +55,10 @@ func (n *testNamer) RedirectUrlMap() (string, bool) {
 	return fmt.Sprintf("%s-rm", n.prefix), true
 }
 
+func (n *testNamer) SwapUrlMap() (string, bool) {
+	return fmt.Sprintf("%s-sm", n.prefix), true
+}
+
 func (n *testNamer) SSLCertName(secretHash string) string {
 	return fmt.Sprintf("%s-cert-%s", n.prefix, secretHash)
 }
@@ -74,51 +79,6 @@ func (n *testNamer) IsValidLoadBalancer() bool {
 	panic("Unimplemented")
 }
 
-func TestToComputeURLMap(t *testing.T) {
-	t.Parallel()
-
-	wantComputeMap := testCompositeURLMap()
-	namer := namer_util.NewNamer("uid1", "fw1")
-	gceURLMap := &utils.GCEURLMap{
-		DefaultBackend: &utils.ServicePort{NodePort: 30000, BackendNamer: namer},
-		HostRules: []utils.HostRule{
-			{
-				Hostname: "abc.com",
-				Paths: []utils.PathRule{
-					{
-						Path:    "/web",
-						Backend: utils.ServicePort{NodePort: 32000, BackendNamer: namer},
-					},
-					{
-						Path:    "/other",
-						Backend: utils.ServicePort{NodePort: 32500, BackendNamer: namer},
-					},
-				},
-			},
-			{
-				Hostname: "foo.bar.com",
-				Paths: []utils.PathRule{
-					{
-						Path:    "/",
-						Backend: utils.ServicePort{NodePort: 33000, BackendNamer: namer},
-					},
-					{
-						Path:    "/*",
-						Backend: utils.ServicePort{NodePort: 33500, BackendNamer: namer},
-					},
-				},
-			},
-		},
-	}
-
-	namerFactory := namer_util.NewFrontendNamerFactory(namer, "")
-	feNamer := namerFactory.NamerForLoadBalancer("lb-name")
-	gotComputeURLMap := ToCompositeURLMap(gceURLMap, feNamer, meta.GlobalKey("ns-lb-name"))
-	if diff := cmp.Diff(wantComputeMap, gotComputeURLMap); diff != "" {
-		t.Errorf("Unexpected diff from ToComputeURLMap() (-want +got):\n%s", diff)
-	}
-}
-
 func TestToRedirectUrlMap(t *testing.T) {
 	t.Parallel()
 
@@ -166,53 +126,6 @@ func TestToRedirectUrlMap(t *testing.T) {
 	}
 }
 
-func testCompositeURLMap() *composite.UrlMap {
-	return &composite.UrlMap{
-		Name:           "k8s-um-lb-name",
-		DefaultService: "global/backendServices/k8s-be-30000--uid1",
-		HostRules: []*composite.HostRule{
-			{
-				Hosts:       []string{"abc.com"},
-				PathMatcher: "host929ba26f492f86d4a9d66a080849865a",
-			},
-			{
-				Hosts:       []string{"foo.bar.com"},
-				PathMatcher: "host2d50cf9711f59181be6a5e5658e42c21",
-			},
-		},
-		PathMatchers: []*composite.PathMatcher{
-			{
-				DefaultService: "global/backendServices/k8s-be-30000--uid1",
-				Name:           "host929ba26f492f86d4a9d66a080849865a",
-				PathRules: []*composite.PathRule{
-					{
-						Paths:   []string{"/web"},
-						Service: "global/backendServices/k8s-be-32000--uid1",
-					},
-					{
-						Paths:   []string{"/other"},
-						Service: "global/backendServices/k8s-be-32500--uid1",
-					},
-				},
-			},
-			{
-				DefaultService: "global/backendServices/k8s-be-30000--uid1",
-				Name:           "host2d50cf9711f59181be6a5e5658e42c21",
-				PathRules: []*composite.PathRule{
-					{
-						Paths:   []string{"/"},
-						Service: "global/backendServices/k8s-be-33000--uid1",
-					},
-					{
-						Paths:   []string{"/*"},
-						Service: "global/backendServices/k8s-be-33500--uid1",
-					},
-				},
-			},
-		},
-	}
-}
-
 func TestSecrets(t *testing.T) {
 	secretsMap := map[string]*api_v1.Secret{
 		"first-secret": &api_v1.Secret{
@@ -381,11 +294,12 @@ func TestToForwardingRule(t *testing.T) {
 	vip := "127.0.0.1"
 
 	cases := []struct {
-		desc     string
-		isL7ILB  bool
-		protocol namer_util.NamerProtocol
-		ipSubnet string
-		want     *composite.ForwardingRule
+		desc      string
+		isL7ILB   bool
+		protocol  namer_util.NamerProtocol
+		ipSubnet  string
+		ipVersion string
+		want      *composite.ForwardingRule
 	}{
 		{
 			desc:     "http-xlb",
@@ -465,12 +379,27 @@ func TestToForwardingRule(t *testing.T) {
 				Subnetwork:          "different-subnet",
 			},
 		},
+		{
+			desc:      "https-xlb ipv6",
+			protocol:  namer_util.HTTPSProtocol,
+			ipVersion: annotations.IPv6Version,
+			want: &composite.ForwardingRule{
+				Name:        "foo-fr",
+				IPAddress:   vip,
+				Target:      proxyLink,
+				PortRange:   httpsDefaultPortRange,
+				IPProtocol:  "TCP",
+				IpVersion:   annotations.IPv6Version,
+				Description: description,
+				Version:     version,
+			},
+		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
 			tr := NewTranslator(tc.isL7ILB, &testNamer{"foo"})
-			env := &Env{VIP: vip, Network: network, Subnetwork: subnetwork}
+			env := &Env{VIP: vip, Network: network, Subnetwork: subnetwork, IPVersion: tc.ipVersion}
 			got := tr.ToCompositeForwardingRule(env, tc.protocol, version, proxyLink, description, tc.ipSubnet)
 			if diff := cmp.Diff(tc.want, got); diff != "" {
 				t.Fatalf("Got diff for ForwardingRule (-want +got):\n%s", diff)
@@ -622,7 +551,9 @@ func TestToCompositeSSLCertificates(t *testing.T) {
 	testCases := []struct {
 		desc     string
 		region   string
+		ilb      bool
 		want     []*composite.SslCertificate
+		wantErr  bool
 		tlsName  string
 		tlsCerts []*TLSCerts
 	}{
@@ -686,13 +617,53 @@ func TestToCompositeSSLCertificates(t *testing.T) {
 				&composite.SslCertificate{Name: "foo-cert-hash-2", Certificate: "cert-2", PrivateKey: "key-2", SelfLink: "https://www.googleapis.com/compute/v1/projects//global/sslCertificates/foo-cert-hash-2"},
 			},
 		},
+		{
+			desc:    "Pre-shared cert of another project",
+			tlsName: "projects/security/global/sslCertificates/shared,https://www.googleapis.com/compute/v1/projects/security/global/sslCertificates/shared-2",
+			want: []*composite.SslCertificate{
+				&composite.SslCertificate{Name: "shared", SelfLink: "https://www.googleapis.com/compute/v1/projects/security/global/sslCertificates/shared"},
+				&composite.SslCertificate{Name: "shared-2", SelfLink: "https://www.googleapis.com/compute/v1/projects/security/global/sslCertificates/shared-2"},
+			},
+		},
+		{
+			desc:    "Regional pre-shared cert of another project",
+			ilb:     true,
+			region:  "us-central1",
+			tlsName: "projects/security/regions/us-central1/sslCertificates/shared",
+			want: []*composite.SslCertificate{
+				&composite.SslCertificate{Name: "shared", SelfLink: "https://www.googleapis.com/compute/v1/projects/security/regions/us-central1/sslCertificates/shared"},
+			},
+		},
+		{
+			desc:    "Regional pre-shared cert for global load balancer",
+			tlsName: "pre-shared-1,projects/security/regions/us-central1/sslCertificates/shared",
+			want: []*composite.SslCertificate{
+				&composite.SslCertificate{Name: "pre-shared-1", SelfLink: "https://www.googleapis.com/compute/v1/projects//global/sslCertificates/pre-shared-1"},
+			},
+			wantErr: true,
+		},
+		{
+			desc:    "Pre-shared cert of another region",
+			ilb:     true,
+			region:  "us-central1",
+			tlsName: "projects/security/regions/europe-west1/sslCertificates/shared",
+			wantErr: true,
+		},
+		{
+			desc:    "Invalid pre-shared cert URL",
+			tlsName: "projects/security/global/backendServices/shared",
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			tr := NewTranslator(false, &testNamer{"foo"})
+			tr := NewTranslator(tc.ilb, &testNamer{"foo"})
 			env := &Env{Region: tc.region}
-			got := tr.ToCompositeSSLCertificates(env, tc.tlsName, tc.tlsCerts, meta.VersionGA)
+			got, err := tr.ToCompositeSSLCertificates(env, tc.tlsName, tc.tlsCerts, meta.VersionGA)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("ToCompositeSSLCertificates() = %v, want error %v", err, tc.wantErr)
+			}
 
 			if diff := cmp.Diff(tc.want, got); diff != "" {
 				t.Fatalf("Got diff for SSLCertificates (-want +got):\n%s", diff)
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package urlmaps
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+// Limits of GCE URL maps, see
+// https://cloud.google.com/load-balancing/docs/quotas#url_maps.
+const (
+	// MaxHostRules is the maximum number of host rules of a URL map. Every
+	// host of an Ingress is translated into a host rule.
+	MaxHostRules = 1000
+	// MaxPathRulesPerPathMatcher is the maximum number of path rules of a
+	// path matcher. Every path of a host is translated into a path rule of
+	// the path matcher of the host.
+	MaxPathRulesPerPathMatcher = 1000
+
+	// maxListedItems is the number of offending hosts or paths listed in a
+	// LimitExceededError.
+	maxListedItems = 5
+)
+
+// LimitExceededError is returned if the URL map of an Ingress exceeds a GCE
+// limit. It lists the hosts or paths of the Ingress beyond the limit.
+type LimitExceededError struct {
+	// Limit describes the exceeded limit, e.g. "paths of host foo.com".
+	Limit string
+	// Max is the value of the limit.
+	Max int
+	// Excess are the hosts or paths beyond the limit.
+	Excess []string
+}
+
+func (e *LimitExceededError) Error() string {
+	excess := e.Excess
+	more := ""
+	if len(excess) > maxListedItems {
+		more = fmt.Sprintf(" and %d more", len(excess)-maxListedItems)
+		excess = excess[:maxListedItems]
+	}
+	return fmt.Sprintf("number of %s exceeds the GCE limit of %d by %d, remove %s%s", e.Limit, e.Max, len(e.Excess), strings.Join(excess, ", "), more)
+}
+
+// CheckLimits validates the URL map g against the GCE limits before it is
+// sent to GCE, which rejects it with a generic error otherwise.
+func CheckLimits(g *utils.GCEURLMap) []error {
+	var errs []error
+	if len(g.HostRules) > MaxHostRules {
+		var excess []string
+		for _, hostRule := range g.HostRules[MaxHostRules:] {
+			excess = append(excess, fmt.Sprintf("host %q", hostRule.Hostname))
+		}
+		errs = append(errs, &LimitExceededError{Limit: "hosts", Max: MaxHostRules, Excess: excess})
+	}
+	for _, hostRule := range g.HostRules {
+		if len(hostRule.Paths) <= MaxPathRulesPerPathMatcher {
+			continue
+		}
+		var excess []string
+		for _, pathRule := range hostRule.Paths[MaxPathRulesPerPathMatcher:] {
+			excess = append(excess, fmt.Sprintf("path %q", pathRule.Path))
+		}
+		errs = append(errs, &LimitExceededError{Limit: fmt.Sprintf("paths of host %q", hostRule.Hostname), Max: MaxPathRulesPerPathMatcher, Excess: excess})
+	}
+	return errs
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package urlmaps
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/ingress-gce/pkg/utils"
+)
+
+func TestCheckLimits(t *testing.T) {
+	t.Parallel()
+
+	backend := utils.ServicePort{NodePort: 30000}
+	paths := func(n int) []utils.PathRule {
+		var rules []utils.PathRule
+		for i := 0; i < n; i++ {
+			rules = append(rules, utils.PathRule{Path: fmt.Sprintf("/path%d", i), Backend: backend})
+		}
+		return rules
+	}
+	hosts := func(n int) *utils.GCEURLMap {
+		g := utils.NewGCEURLMap()
+		for i := 0; i < n; i++ {
+			g.PutPathRulesForHost(fmt.Sprintf("host%d.com", i), paths(1))
+		}
+		return g
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		urlMap  *utils.GCEURLMap
+		wantErr string
+	}{
+		{
+			desc:   "within limits",
+			urlMap: hosts(MaxHostRules),
+		},
+		{
+			desc:    "too many hosts",
+			urlMap:  hosts(MaxHostRules + 2),
+			wantErr: `number of hosts exceeds the GCE limit of 1000 by 2, remove host "host1000.com", host "host1001.com"`,
+		},
+		{
+			desc: "too many paths",
+			urlMap: func() *utils.GCEURLMap {
+				g := utils.NewGCEURLMap()
+				g.PutPathRulesForHost("foo.com", paths(MaxPathRulesPerPathMatcher+7))
+				return g
+			}(),
+			wantErr: `number of paths of host "foo.com" exceeds the GCE limit of 1000 by 7, remove path "/path1000", path "/path1001", path "/path1002", path "/path1003", path "/path1004" and 2 more`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			errs := CheckLimits(tc.urlMap)
+			if tc.wantErr == "" {
+				if errs != nil {
+					t.Errorf("CheckLimits() = %v, want nil", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Error() != tc.wantErr {
+				t.Errorf("CheckLimits() = %v, want [%s]", errs, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package urlmaps builds GCE URL maps from Ingress specs. The functions in
+// this package do not access the API server or GCE, all inputs are passed in
+// explicitly, so they can be used both by the controller and by tools that
+// compare the expected URL map of an Ingress with the one in GCE.
+package urlmaps
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud"
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	v1 "k8s.io/api/networking/v1"
+
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/utils"
+	"k8s.io/ingress-gce/pkg/utils/namer"
+)
+
+const (
+	// DefaultHost is the host used if none is specified. It is a valid value
+	// for the "Host" field recognized by GCE.
+	DefaultHost = "*"
+
+	// DefaultPath is the path used if none is specified. It is a valid path
+	// recognized by GCE.
+	DefaultPath = "/*"
+
+	// The gce api uses the name of a path rule to match a host rule.
+	hostRulePrefix = "host"
+)
+
+// ServicePorts maps the backends referenced by an Ingress to the resolved
+// ServicePorts.
+type ServicePorts map[utils.ServicePortID]utils.ServicePort
+
+// Policy contains the settings that affect the URL map of an Ingress but are
+// not part of the Ingress spec.
+type Policy struct {
+	// SystemDefaultBackend is the backend used if the Ingress does not
+	// specify a default backend.
+	SystemDefaultBackend utils.ServicePortID
+	// EnableGAPathTypes allows the Exact and Prefix path types. If false,
+	// only ImplementationSpecific paths are accepted.
+	EnableGAPathTypes bool
+	// PathBackendConfigs maps Ingress paths to the name of the BackendConfig
+	// which overrides the BackendConfig of the Service port of their
+	// backend.
+	PathBackendConfigs map[string]string
+}
+
+// PathServicePortID returns the ServicePortID of the backend of the given
+// Ingress path, including its BackendConfig override, if any.
+func PathServicePortID(p v1.HTTPIngressPath, namespace string, policy Policy) (utils.ServicePortID, error) {
+	id, err := utils.BackendToServicePortID(p.Backend, namespace)
+	if err != nil {
+		return id, err
+	}
+	id.BackendConfig = policy.PathBackendConfigs[p.Path]
+	return id, nil
+}
+
+// TranslateIngress converts an Ingress into our internal UrlMap representation.
+// Backends which are not present in svcPorts are skipped, it is up to the
+// caller to report why they could not be resolved.
+func TranslateIngress(ing *v1.Ingress, svcPorts ServicePorts, policy Policy) (*utils.GCEURLMap, []error) {
+	var errs []error
+	urlMap := utils.NewGCEURLMap()
+
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		pathRules := []utils.PathRule{}
+		for _, p := range rule.HTTP.Paths {
+			svcPortID, err := PathServicePortID(p, ing.Namespace, policy)
+			if err != nil {
+				// Only error possible is Backend is not a Service Backend, so move to next path
+				errs = append(errs, err)
+				continue
+			}
+			svcPort, ok := svcPorts[svcPortID]
+			if !ok {
+				continue
+			}
+			// The Ingress spec defines empty path as catch-all, so if a user
+			// asks for a single host and multiple empty paths, all traffic is
+			// sent to one of the last backend in the rules list.
+			paths, err := validateAndGetPaths(p, policy.EnableGAPathTypes)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for _, path := range paths {
+				if path == "" {
+					path = DefaultPath
+				}
+				pathRules = append(pathRules, utils.PathRule{Path: path, Backend: svcPort})
+			}
+		}
+
+		host := rule.Host
+		if host == "" {
+			host = DefaultHost
+		}
+		urlMap.PutPathRulesForHost(host, pathRules)
+	}
+
+	defaultBackend := policy.SystemDefaultBackend
+	if ing.Spec.DefaultBackend != nil {
+		svcPortID, err := utils.BackendToServicePortID(*ing.Spec.DefaultBackend, ing.Namespace)
+		if err != nil {
+			errs = append(errs, err)
+			return urlMap, errs
+		}
+		defaultBackend = svcPortID
+	}
+	if svcPort, ok := svcPorts[defaultBackend]; ok {
+		urlMap.DefaultBackend = &svcPort
+	}
+	return urlMap, errs
+}
+
+// ToCompositeURLMapForIngress returns the URL map for the given Ingress. See
+// TranslateIngress and ToCompositeURLMap.
+func ToCompositeURLMapForIngress(ing *v1.Ingress, svcPorts ServicePorts, policy Policy, namer namer.IngressFrontendNamer, key *meta.Key) (*composite.UrlMap, []error) {
+	urlMap, errs := TranslateIngress(ing, svcPorts, policy)
+	if urlMap.DefaultBackend == nil {
+		errs = append(errs, fmt.Errorf("failed to resolve the default backend of Ingress %s/%s", ing.Namespace, ing.Name))
+		return nil, errs
+	}
+	return ToCompositeURLMap(urlMap, namer, key), errs
+}
+
+// ToCompositeURLMap translates the given hostname: endpoint->port mapping into a gce url map.
+//
+// HostRule: Conceptually contains all PathRules for a given host.
+// PathMatcher: Associates a path rule with a host rule. Mostly an optimization.
+// PathRule: Maps a single path regex to a backend.
+//
+// The GCE url map allows multiple hosts to share url->backend mappings without duplication, eg:
+//   Host: foo(PathMatcher1), bar(PathMatcher1,2)
+//   PathMatcher1:
+//     /a -> b1
+//     /b -> b2
+//   PathMatcher2:
+//     /c -> b1
+// This leads to a lot of complexity in the common case, where all we want is a mapping of
+// host->{/path: backend}.
+//
+// Consider some alternatives:
+// 1. Using a single backend per PathMatcher:
+//   Host: foo(PathMatcher1,3) bar(PathMatcher1,2,3)
+//   PathMatcher1:
+//     /a -> b1
+//   PathMatcher2:
+//     /c -> b1
+//   PathMatcher3:
+//     /b -> b2
+// 2. Using a single host per PathMatcher:
+//   Host: foo(PathMatcher1)
+//   PathMatcher1:
+//     /a -> b1
+//     /b -> b2
+//   Host: bar(PathMatcher2)
+//   PathMatcher2:
+//     /a -> b1
+//     /b -> b2
+//     /c -> b1
+// In the context of kubernetes services, 2 makes more sense, because we
+// rarely want to lookup backends (service:nodeport). When a service is
+// deleted, we need to find all host PathMatchers that have the backend
+// and remove the mapping. When a new path is added to a host (happens
+// more frequently than service deletion) we just need to lookup the 1
+// pathmatcher of the host.
+func ToCompositeURLMap(g *utils.GCEURLMap, namer namer.IngressFrontendNamer, key *meta.Key) *composite.UrlMap {
+	m := &composite.UrlMap{
+		Name:           namer.UrlMap(),
+		DefaultService: backendServicePath(g.DefaultBackend.BackendName(), key),
+	}
+
+	for _, hostRule := range g.HostRules {
+		// Create a host rule
+		// Create a path matcher
+		// Add all given endpoint:backends to pathRules in path matcher
+		pmName := getNameForPathMatcher(hostRule.Hostname)
+		m.HostRules = append(m.HostRules, &composite.HostRule{
+			Hosts:       []string{hostRule.Hostname},
+			PathMatcher: pmName,
+		})
+
+		pathMatcher := &composite.PathMatcher{
+			Name:           pmName,
+			DefaultService: m.DefaultService,
+			PathRules:      []*composite.PathRule{},
+		}
+
+		// GCE ensures that matched rule with longest prefix wins.
+		for _, rule := range hostRule.Paths {
+			pathMatcher.PathRules = append(pathMatcher.PathRules, &composite.PathRule{
+				Paths:   []string{rule.Path},
+				Service: backendServicePath(rule.Backend.BackendName(), key),
+			})
+		}
+		m.PathMatchers = append(m.PathMatchers, pathMatcher)
+	}
+	return m
+}
+
+// backendServicePath returns the relative path of the named backend service
+// in the scope of the given key.
+func backendServicePath(name string, scope *meta.Key) string {
+	key := *scope
+	key.Name = name
+	resourceID := cloud.ResourceID{ProjectID: "", Resource: "backendServices", Key: &key}
+	return resourceID.ResourcePath()
+}
+
+// getNameForPathMatcher returns a name for a pathMatcher based on the given host rule.
+// The host rule can be a regex, the path matcher name used to associate the 2 cannot.
+func getNameForPathMatcher(hostRule string) string {
+	hasher := md5.New()
+	hasher.Write([]byte(hostRule))
+	return fmt.Sprintf("%v%v", hostRulePrefix, hex.EncodeToString(hasher.Sum(nil)))
+}
+
+// validateAndGetPaths will validate the path based on the specifed path type and will return the
+// the path rules that should be used. If no path type is provided, the path type will be assumed
+// to be ImplementationSpecific. If a non existent path type is provided, an error will be returned.
+func validateAndGetPaths(path v1.HTTPIngressPath, enableGAPathTypes bool) ([]string, error) {
+	pathType := v1.PathTypeImplementationSpecific
+
+	if path.PathType != nil {
+		if !enableGAPathTypes && *path.PathType != v1.PathTypeImplementationSpecific {
+			return nil, fmt.Errorf("only \"ImplementationSpecific\" path type is supported")
+		}
+		pathType = *path.PathType
+	}
+
+	switch pathType {
+	case v1.PathTypeImplementationSpecific:
+		// ImplementationSpecific will have no validation to continue backwards compatibility
+		return []string{path.Path}, nil
+	case v1.PathTypeExact:
+		return validateExactPathType(path)
+	case v1.PathTypePrefix:
+		return validateAndModifyPrefixPathType(path)
+	default:
+		return nil, fmt.Errorf("unsupported path type: %s", pathType)
+	}
+}
+
+// validateExactPathType will validate the path provided does not have any wildcards and will
+// return the path unmodified. If the path is in valid, an empty list and error is returned.
+func validateExactPathType(path v1.HTTPIngressPath) ([]string, error) {
+	if path.Path == "" {
+		return nil, fmt.Errorf("failed to validate exact path type due to empty path")
+	}
+
+	if strings.Contains(path.Path, "*") {
+		return nil, fmt.Errorf("failed to validate exact path %s due to invalid wildcard", path.Path)
+	}
+	return []string{path.Path}, nil
+}
+
+// validateAndModifyPrefixPathType will validate the path provided does not have any wildcards
+// and will return the path unmodified. If the path is in valid, an empty list and error is
+// returned.
+func validateAndModifyPrefixPathType(path v1.HTTPIngressPath) ([]string, error) {
+	if path.Path == "" {
+		return nil, fmt.Errorf("failed to validate prefix path type due to empty path")
+	}
+
+	// The Ingress spec defines Prefx path "/" as matching all paths
+	if path.Path == "/" {
+		return []string{"/*"}, nil
+	}
+
+	if strings.Contains(path.Path, "*") {
+		return nil, fmt.Errorf("failed to validate prefix path %s due to invalid wildcard", path.Path)
+	}
+
+	// Prefix path `/foo` or `/foo/` should support requests for `/foo`, `/foo/` and `/foo/bar`. URLMap requires two
+	// path rules 1) `/foo` & 2) `/foo/*` to support all three requests.
+	// Therefore each prefix path should result in two paths for the URLMap, one without a
+	// trailing '/' and one that ends with '/*'
+	if path.Path[len(path.Path)-1] == '/' {
+		return []string{path.Path[0 : len(path.Path)-1], path.Path + "*"}, nil
+	}
+	return []string{path.Path, path.Path + "/*"}, nil
+}
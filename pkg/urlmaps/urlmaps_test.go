@@ -0,0 +1,250 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package urlmaps
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/ingress-gce/pkg/composite"
+	"k8s.io/ingress-gce/pkg/test"
+	"k8s.io/ingress-gce/pkg/utils"
+	namer_util "k8s.io/ingress-gce/pkg/utils/namer"
+)
+
+func TestToComputeURLMap(t *testing.T) {
+	t.Parallel()
+
+	wantComputeMap := testCompositeURLMap()
+	namer := namer_util.NewNamer("uid1", "fw1")
+	gceURLMap := &utils.GCEURLMap{
+		DefaultBackend: &utils.ServicePort{NodePort: 30000, BackendNamer: namer},
+		HostRules: []utils.HostRule{
+			{
+				Hostname: "abc.com",
+				Paths: []utils.PathRule{
+					{
+						Path:    "/web",
+						Backend: utils.ServicePort{NodePort: 32000, BackendNamer: namer},
+					},
+					{
+						Path:    "/other",
+						Backend: utils.ServicePort{NodePort: 32500, BackendNamer: namer},
+					},
+				},
+			},
+			{
+				Hostname: "foo.bar.com",
+				Paths: []utils.PathRule{
+					{
+						Path:    "/",
+						Backend: utils.ServicePort{NodePort: 33000, BackendNamer: namer},
+					},
+					{
+						Path:    "/*",
+						Backend: utils.ServicePort{NodePort: 33500, BackendNamer: namer},
+					},
+				},
+			},
+		},
+	}
+
+	namerFactory := namer_util.NewFrontendNamerFactory(namer, "")
+	feNamer := namerFactory.NamerForLoadBalancer("lb-name")
+	gotComputeURLMap := ToCompositeURLMap(gceURLMap, feNamer, meta.GlobalKey("ns-lb-name"))
+	if diff := cmp.Diff(wantComputeMap, gotComputeURLMap); diff != "" {
+		t.Errorf("Unexpected diff from ToComputeURLMap() (-want +got):\n%s", diff)
+	}
+}
+
+func testCompositeURLMap() *composite.UrlMap {
+	return &composite.UrlMap{
+		Name:           "k8s-um-lb-name",
+		DefaultService: "global/backendServices/k8s-be-30000--uid1",
+		HostRules: []*composite.HostRule{
+			{
+				Hosts:       []string{"abc.com"},
+				PathMatcher: "host929ba26f492f86d4a9d66a080849865a",
+			},
+			{
+				Hosts:       []string{"foo.bar.com"},
+				PathMatcher: "host2d50cf9711f59181be6a5e5658e42c21",
+			},
+		},
+		PathMatchers: []*composite.PathMatcher{
+			{
+				DefaultService: "global/backendServices/k8s-be-30000--uid1",
+				Name:           "host929ba26f492f86d4a9d66a080849865a",
+				PathRules: []*composite.PathRule{
+					{
+						Paths:   []string{"/web"},
+						Service: "global/backendServices/k8s-be-32000--uid1",
+					},
+					{
+						Paths:   []string{"/other"},
+						Service: "global/backendServices/k8s-be-32500--uid1",
+					},
+				},
+			},
+			{
+				DefaultService: "global/backendServices/k8s-be-30000--uid1",
+				Name:           "host2d50cf9711f59181be6a5e5658e42c21",
+				PathRules: []*composite.PathRule{
+					{
+						Paths:   []string{"/"},
+						Service: "global/backendServices/k8s-be-33000--uid1",
+					},
+					{
+						Paths:   []string{"/*"},
+						Service: "global/backendServices/k8s-be-33500--uid1",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestTranslateIngress(t *testing.T) {
+	t.Parallel()
+
+	namer := namer_util.NewNamer("uid1", "fw1")
+	port80 := v1.ServiceBackendPort{Number: 80}
+	svcPort := func(name string, nodePort int64) utils.ServicePort {
+		return utils.ServicePort{
+			ID:           utils.ServicePortID{Service: types.NamespacedName{Namespace: "default", Name: name}, Port: port80},
+			NodePort:     nodePort,
+			BackendNamer: namer,
+		}
+	}
+	defaultSvcPort := svcPort("default-http-backend", 30000)
+	fooSvcPort := svcPort("foo", 30001)
+	svcPorts := ServicePorts{
+		defaultSvcPort.ID: defaultSvcPort,
+		fooSvcPort.ID:     fooSvcPort,
+	}
+	exact := v1.PathTypeExact
+	prefix := v1.PathTypePrefix
+
+	ingressWithPath := func(path v1.HTTPIngressPath) *v1.Ingress {
+		return test.NewIngress(types.NamespacedName{Name: "my-ingress", Namespace: "default"},
+			v1.IngressSpec{
+				Rules: []v1.IngressRule{{
+					IngressRuleValue: v1.IngressRuleValue{
+						HTTP: &v1.HTTPIngressRuleValue{Paths: []v1.HTTPIngressPath{path}},
+					},
+				}},
+			})
+	}
+
+	for _, tc := range []struct {
+		desc              string
+		ing               *v1.Ingress
+		enableGAPathTypes bool
+		wantPaths         []string
+		wantErrCount      int
+	}{
+		{
+			desc:      "empty path is catch-all",
+			ing:       ingressWithPath(v1.HTTPIngressPath{Backend: *test.Backend("foo", port80)}),
+			wantPaths: []string{DefaultPath},
+		},
+		{
+			desc:      "prefix path",
+			ing:       ingressWithPath(v1.HTTPIngressPath{Path: "/foo/", PathType: &prefix, Backend: *test.Backend("foo", port80)}),
+			wantPaths: []string{"/foo", "/foo/*"},
+
+			enableGAPathTypes: true,
+		},
+		{
+			desc:         "prefix path without GA path types",
+			ing:          ingressWithPath(v1.HTTPIngressPath{Path: "/foo", PathType: &prefix, Backend: *test.Backend("foo", port80)}),
+			wantErrCount: 1,
+		},
+		{
+			desc:         "exact path with wildcard",
+			ing:          ingressWithPath(v1.HTTPIngressPath{Path: "/foo/*", PathType: &exact, Backend: *test.Backend("foo", port80)}),
+			wantErrCount: 1,
+
+			enableGAPathTypes: true,
+		},
+		{
+			desc: "unresolved backend is skipped",
+			ing:  ingressWithPath(v1.HTTPIngressPath{Path: "/bar", Backend: *test.Backend("bar", port80)}),
+		},
+		{
+			desc:         "non service backend",
+			ing:          ingressWithPath(v1.HTTPIngressPath{Path: "/bar"}),
+			wantErrCount: 1,
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			policy := Policy{SystemDefaultBackend: defaultSvcPort.ID, EnableGAPathTypes: tc.enableGAPathTypes}
+			urlMap, errs := TranslateIngress(tc.ing, svcPorts, policy)
+			if len(errs) != tc.wantErrCount {
+				t.Errorf("TranslateIngress() = _, %v, want %d errs", errs, tc.wantErrCount)
+			}
+			if urlMap.DefaultBackend == nil || urlMap.DefaultBackend.ID != defaultSvcPort.ID {
+				t.Errorf("TranslateIngress() default backend = %v, want %v", urlMap.DefaultBackend, defaultSvcPort.ID)
+			}
+			var gotPaths []string
+			for _, hostRule := range urlMap.HostRules {
+				for _, pathRule := range hostRule.Paths {
+					gotPaths = append(gotPaths, pathRule.Path)
+				}
+			}
+			if diff := cmp.Diff(tc.wantPaths, gotPaths); diff != "" {
+				t.Errorf("TranslateIngress() paths diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToCompositeURLMapForIngress(t *testing.T) {
+	t.Parallel()
+
+	namer := namer_util.NewNamer("uid1", "fw1")
+	feNamer := namer_util.NewFrontendNamerFactory(namer, "").NamerForLoadBalancer("lb-name")
+	port80 := v1.ServiceBackendPort{Number: 80}
+	defaultSvcPort := utils.ServicePort{
+		ID:           utils.ServicePortID{Service: types.NamespacedName{Namespace: "default", Name: "default-http-backend"}, Port: port80},
+		NodePort:     30000,
+		BackendNamer: namer,
+	}
+	ing := test.NewIngress(types.NamespacedName{Name: "my-ingress", Namespace: "default"}, v1.IngressSpec{})
+
+	policy := Policy{SystemDefaultBackend: defaultSvcPort.ID}
+	if _, errs := ToCompositeURLMapForIngress(ing, ServicePorts{}, policy, feNamer, meta.GlobalKey("")); len(errs) != 1 {
+		t.Errorf("ToCompositeURLMapForIngress() = _, %v, want 1 err for unresolved default backend", errs)
+	}
+
+	urlMap, errs := ToCompositeURLMapForIngress(ing, ServicePorts{defaultSvcPort.ID: defaultSvcPort}, policy, feNamer, meta.GlobalKey(""))
+	if len(errs) != 0 {
+		t.Fatalf("ToCompositeURLMapForIngress() = _, %v, want no errs", errs)
+	}
+	want := &composite.UrlMap{
+		Name:           "k8s-um-lb-name",
+		DefaultService: "global/backendServices/k8s-be-30000--uid1",
+	}
+	if diff := cmp.Diff(want, urlMap); diff != "" {
+		t.Errorf("Unexpected diff from ToCompositeURLMapForIngress() (-want +got):\n%s", diff)
+	}
+}
@@ -18,15 +18,22 @@ package utils
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"k8s.io/klog"
 )
 
 // Description stores the description for a BackendService.
 type Description struct {
-	ServiceName string   `json:"kubernetes.io/service-name"`
-	ServicePort string   `json:"kubernetes.io/service-port"`
-	XFeatures   []string `json:"x-features,omitempty"`
+	ServiceName string `json:"kubernetes.io/service-name"`
+	ServicePort string `json:"kubernetes.io/service-port"`
+	// BackendConfig is the namespace/name of the BackendConfig applied to the
+	// BackendService, if any.
+	BackendConfig string `json:"kubernetes.io/backend-config,omitempty"`
+	// BackendConfigHash is a hash of the spec of the BackendConfig, which
+	// changes whenever the BackendConfig linked to the BackendService does.
+	BackendConfigHash string   `json:"kubernetes.io/backend-config-hash,omitempty"`
+	XFeatures         []string `json:"x-features,omitempty"`
 }
 
 // String returns the string representation of a Description.
@@ -43,6 +50,19 @@ func (desc Description) String() string {
 	return string(descJson)
 }
 
+// Owner returns the Service port which owns the BackendService, and its
+// BackendConfig if any. It is empty if the description has no owner.
+func (desc Description) Owner() string {
+	if desc.ServiceName == "" || desc.ServicePort == "" {
+		return ""
+	}
+	owner := fmt.Sprintf("%s/%s", desc.ServiceName, desc.ServicePort)
+	if desc.BackendConfig != "" {
+		owner = fmt.Sprintf("%s (BackendConfig %s@%s)", owner, desc.BackendConfig, desc.BackendConfigHash)
+	}
+	return owner
+}
+
 // DescriptionFromString gets a Description from string,
 func DescriptionFromString(descString string) *Description {
 	if descString == "" {
@@ -57,6 +57,16 @@ func TestString(t *testing.T) {
 			},
 			expectedString: `{"kubernetes.io/service-name":"my-service","kubernetes.io/service-port":"my-port","x-features":["feature1","feature2"]}`,
 		},
+		{
+			desc: "backend config",
+			description: Description{
+				ServiceName:       "my-service",
+				ServicePort:       "my-port",
+				BackendConfig:     "ns/my-config",
+				BackendConfigHash: "abcd1234",
+			},
+			expectedString: `{"kubernetes.io/service-name":"my-service","kubernetes.io/service-port":"my-port","kubernetes.io/backend-config":"ns/my-config","kubernetes.io/backend-config-hash":"abcd1234"}`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -97,6 +107,11 @@ func TestDescriptionFromString(t *testing.T) {
 			backendServiceDesc: `{"kubernetes.io/service-name":"my-service","kubernetes.io/service-port":"my-port","x-features":["feature1","feature2"]}`,
 			expectedDesc:       Description{ServiceName: "my-service", ServicePort: "my-port", XFeatures: []string{"feature1", "feature2"}},
 		},
+		{
+			desc:               "backend config",
+			backendServiceDesc: `{"kubernetes.io/service-name":"my-service","kubernetes.io/service-port":"my-port","kubernetes.io/backend-config":"ns/my-config","kubernetes.io/backend-config-hash":"abcd1234"}`,
+			expectedDesc:       Description{ServiceName: "my-service", ServicePort: "my-port", BackendConfig: "ns/my-config", BackendConfigHash: "abcd1234"},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -106,3 +121,30 @@ func TestDescriptionFromString(t *testing.T) {
 		}
 	}
 }
+
+func TestDescriptionOwner(t *testing.T) {
+	for _, tc := range []struct {
+		desc        string
+		description Description
+		want        string
+	}{
+		{
+			desc:        "empty description",
+			description: Description{},
+		},
+		{
+			desc:        "no backend config",
+			description: Description{ServiceName: "ns/my-service", ServicePort: "my-port"},
+			want:        "ns/my-service/my-port",
+		},
+		{
+			desc:        "backend config",
+			description: Description{ServiceName: "ns/my-service", ServicePort: "my-port", BackendConfig: "ns/my-config", BackendConfigHash: "abcd1234"},
+			want:        "ns/my-service/my-port (BackendConfig ns/my-config@abcd1234)",
+		},
+	} {
+		if got := tc.description.Owner(); got != tc.want {
+			t.Errorf("%s: Owner() = %q, want %q", tc.desc, got, tc.want)
+		}
+	}
+}
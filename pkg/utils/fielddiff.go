@@ -0,0 +1,164 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldSpec describes a field of a GCE resource that is owned by the
+// controller. Ensure functions only compare the fields they own, so that
+// fields filled in by GCE or owned by another feature never trigger an update.
+type FieldSpec struct {
+	// Path is the dotted path of Go field names from the resource struct to
+	// the field, e.g. "ConnectionDraining.DrainingTimeoutSec". Pointers along
+	// the path are followed and a nil pointer reads as its zero value.
+	Path string
+	// ServerDefault is the value GCE reports for the field when it is not
+	// set in a request. It is only meaningful for fields whose zero value is
+	// never sent on purpose, e.g. SessionAffinity.
+	ServerDefault interface{}
+	// Unordered compares a []string field as a set.
+	Unordered bool
+	// ResourceID compares a string field holding a resource URL by its
+	// project, location, resource type and name, ignoring the endpoint and
+	// API version.
+	ResourceID bool
+	// ResourcePath compares a string field holding a resource URL by its
+	// location, resource type and name, also ignoring the project.
+	ResourcePath bool
+	// Optional only compares the field if the wanted value is set.
+	Optional bool
+	// Minimum compares an integer field as a lower bound: the existing value
+	// only differs if it is less than the wanted one.
+	Minimum bool
+	// Elem is the mask of the elements of a slice field. The elements are
+	// compared in order and the field differs if any element differs.
+	Elem []FieldSpec
+}
+
+// DiffFields returns the paths of the fields in mask for which want differs
+// from have. Values are normalized before they are compared:
+//   - a nil pointer equals a pointer to the zero value,
+//   - a nil slice or map equals an empty one,
+//   - an unset field equals its server default, if it has one.
+//
+// DiffFields panics if a path does not resolve to a field or a server default
+// does not have the type of its field, which can only be caused by an
+// incorrect mask.
+func DiffFields(want, have interface{}, mask []FieldSpec) []string {
+	var diff []string
+	for _, f := range mask {
+		w := normalizedFieldValue(want, f)
+		h := normalizedFieldValue(have, f)
+		if !fieldValuesEqual(w, h, f) {
+			diff = append(diff, f.Path)
+		}
+	}
+	return diff
+}
+
+// FieldValue returns the normalized value of the field at path in obj, e.g.
+// to log a difference returned by DiffFields.
+func FieldValue(obj interface{}, path string) interface{} {
+	return normalizedFieldValue(obj, FieldSpec{Path: path}).Interface()
+}
+
+// normalizedFieldValue returns the value of the field described by f in obj,
+// with the server default substituted for an unset value.
+func normalizedFieldValue(obj interface{}, f FieldSpec) reflect.Value {
+	v := reflect.ValueOf(obj)
+	for _, name := range strings.Split(f.Path, ".") {
+		v = indirect(v)
+		if v.Kind() != reflect.Struct {
+			panic(fmt.Sprintf("field path %q of %T: %s is not a struct", f.Path, obj, v.Type()))
+		}
+		v = v.FieldByName(name)
+		if !v.IsValid() {
+			panic(fmt.Sprintf("field path %q of %T: no field %q", f.Path, obj, name))
+		}
+	}
+	v = indirect(v)
+	if isUnset(v) && f.ServerDefault != nil {
+		d := reflect.ValueOf(f.ServerDefault)
+		if d.Type() != v.Type() {
+			panic(fmt.Sprintf("field path %q of %T: server default has type %s, want %s", f.Path, obj, d.Type(), v.Type()))
+		}
+		return d
+	}
+	return v
+}
+
+// indirect follows pointers, returning the zero value of the pointed-to
+// type for nil pointers.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Zero(v.Type().Elem())
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isUnset returns true if v is the zero value or an empty slice or map.
+func isUnset(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// fieldValuesEqual returns true if the wanted value w equals the existing
+// value h of the field described by f.
+func fieldValuesEqual(w, h reflect.Value, f FieldSpec) bool {
+	if f.Minimum {
+		return h.Int() >= w.Int()
+	}
+	if f.Optional && isUnset(w) {
+		return true
+	}
+	if isUnset(w) || isUnset(h) {
+		return isUnset(w) && isUnset(h)
+	}
+	switch {
+	case f.Elem != nil:
+		if w.Len() != h.Len() {
+			return false
+		}
+		for i := 0; i < w.Len(); i++ {
+			if len(DiffFields(w.Index(i).Interface(), h.Index(i).Interface(), f.Elem)) > 0 {
+				return false
+			}
+		}
+		return true
+	case f.Unordered:
+		ws, wok := w.Interface().([]string)
+		hs, hok := h.Interface().([]string)
+		if wok && hok {
+			return EqualStringSets(ws, hs)
+		}
+	case f.ResourceID:
+		return EqualResourceIDs(w.String(), h.String())
+	case f.ResourcePath:
+		return EqualResourcePaths(w.String(), h.String())
+	}
+	return reflect.DeepEqual(w.Interface(), h.Interface())
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/ingress-gce/pkg/composite"
+)
+
+func TestDiffFields(t *testing.T) {
+	mask := []FieldSpec{
+		{Path: "SessionAffinity", ServerDefault: "NONE"},
+		{Path: "ConnectionDraining.DrainingTimeoutSec"},
+		{Path: "CustomRequestHeaders"},
+		{Path: "SecuritySettings.SubjectAltNames", Unordered: true},
+		{Path: "MaxStreamDuration"},
+	}
+	testCases := []struct {
+		desc string
+		want *composite.BackendService
+		have *composite.BackendService
+		diff []string
+	}{
+		{
+			desc: "empty",
+			want: &composite.BackendService{},
+			have: &composite.BackendService{},
+		},
+		{
+			desc: "identical",
+			want: &composite.BackendService{
+				SessionAffinity:      "CLIENT_IP",
+				ConnectionDraining:   &composite.ConnectionDraining{DrainingTimeoutSec: 60},
+				CustomRequestHeaders: []string{"a:b"},
+				MaxStreamDuration:    &composite.Duration{Seconds: 10},
+			},
+			have: &composite.BackendService{
+				SessionAffinity:      "CLIENT_IP",
+				ConnectionDraining:   &composite.ConnectionDraining{DrainingTimeoutSec: 60},
+				CustomRequestHeaders: []string{"a:b"},
+				MaxStreamDuration:    &composite.Duration{Seconds: 10},
+			},
+		},
+		{
+			desc: "unset field matches server default",
+			want: &composite.BackendService{},
+			have: &composite.BackendService{SessionAffinity: "NONE"},
+		},
+		{
+			desc: "set field differs from server default",
+			want: &composite.BackendService{SessionAffinity: "CLIENT_IP"},
+			have: &composite.BackendService{SessionAffinity: "NONE"},
+			diff: []string{"SessionAffinity"},
+		},
+		{
+			desc: "nil parent matches zero value",
+			want: &composite.BackendService{ConnectionDraining: &composite.ConnectionDraining{}},
+			have: &composite.BackendService{},
+		},
+		{
+			desc: "nil parent differs from non-zero value",
+			want: &composite.BackendService{ConnectionDraining: &composite.ConnectionDraining{DrainingTimeoutSec: 60}},
+			have: &composite.BackendService{},
+			diff: []string{"ConnectionDraining.DrainingTimeoutSec"},
+		},
+		{
+			desc: "nil slice matches empty slice",
+			want: &composite.BackendService{CustomRequestHeaders: []string{}},
+			have: &composite.BackendService{},
+		},
+		{
+			desc: "ordered slice",
+			want: &composite.BackendService{CustomRequestHeaders: []string{"a:b", "c:d"}},
+			have: &composite.BackendService{CustomRequestHeaders: []string{"c:d", "a:b"}},
+			diff: []string{"CustomRequestHeaders"},
+		},
+		{
+			desc: "unordered slice",
+			want: &composite.BackendService{SecuritySettings: &composite.SecuritySettings{SubjectAltNames: []string{"a", "b"}}},
+			have: &composite.BackendService{SecuritySettings: &composite.SecuritySettings{SubjectAltNames: []string{"b", "a"}}},
+		},
+		{
+			desc: "nil struct pointer matches zero struct",
+			want: &composite.BackendService{MaxStreamDuration: &composite.Duration{}},
+			have: &composite.BackendService{},
+		},
+		{
+			desc: "fields outside of the mask are ignored",
+			want: &composite.BackendService{TimeoutSec: 10},
+			have: &composite.BackendService{TimeoutSec: 30, Description: "foo"},
+		},
+		{
+			desc: "multiple fields differ",
+			want: &composite.BackendService{SessionAffinity: "CLIENT_IP", MaxStreamDuration: &composite.Duration{Seconds: 10}},
+			have: &composite.BackendService{},
+			diff: []string{"SessionAffinity", "MaxStreamDuration"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			diff := DiffFields(tc.want, tc.have, mask)
+			if !reflect.DeepEqual(diff, tc.diff) {
+				t.Errorf("DiffFields() = %v, want %v", diff, tc.diff)
+			}
+		})
+	}
+}
+
+func TestDiffFieldsOptions(t *testing.T) {
+	const (
+		bs1      = "https://www.googleapis.com/compute/v1/projects/p/global/backendServices/bs1"
+		bs1Beta  = "https://www.googleapis.com/compute/beta/projects/p/global/backendServices/bs1"
+		bs1Other = "https://www.googleapis.com/compute/v1/projects/other/global/backendServices/bs1"
+		bs2      = "https://www.googleapis.com/compute/v1/projects/p/global/backendServices/bs2"
+	)
+	hostRule := func(hosts ...string) *composite.HostRule {
+		return &composite.HostRule{Hosts: hosts, PathMatcher: "pm"}
+	}
+	testCases := []struct {
+		desc string
+		mask []FieldSpec
+		want interface{}
+		have interface{}
+		diff []string
+	}{
+		{
+			desc: "resource ID ignores the API version",
+			mask: []FieldSpec{{Path: "BackendService", ResourceID: true}},
+			want: &composite.ForwardingRule{BackendService: bs1},
+			have: &composite.ForwardingRule{BackendService: bs1Beta},
+		},
+		{
+			desc: "resource ID compares the project",
+			mask: []FieldSpec{{Path: "BackendService", ResourceID: true}},
+			want: &composite.ForwardingRule{BackendService: bs1},
+			have: &composite.ForwardingRule{BackendService: bs1Other},
+			diff: []string{"BackendService"},
+		},
+		{
+			desc: "resource path ignores the project",
+			mask: []FieldSpec{{Path: "DefaultService", ResourcePath: true}},
+			want: &composite.UrlMap{DefaultService: bs1},
+			have: &composite.UrlMap{DefaultService: bs1Other},
+		},
+		{
+			desc: "resource path compares the name",
+			mask: []FieldSpec{{Path: "DefaultService", ResourcePath: true}},
+			want: &composite.UrlMap{DefaultService: bs1},
+			have: &composite.UrlMap{DefaultService: bs2},
+			diff: []string{"DefaultService"},
+		},
+		{
+			desc: "minimum is met by a larger value",
+			mask: []FieldSpec{{Path: "CheckIntervalSec", Minimum: true}},
+			want: &composite.HealthCheck{CheckIntervalSec: 8},
+			have: &composite.HealthCheck{CheckIntervalSec: 10},
+		},
+		{
+			desc: "minimum is not met by a smaller value",
+			mask: []FieldSpec{{Path: "CheckIntervalSec", Minimum: true}},
+			want: &composite.HealthCheck{CheckIntervalSec: 8},
+			have: &composite.HealthCheck{CheckIntervalSec: 5},
+			diff: []string{"CheckIntervalSec"},
+		},
+		{
+			desc: "optional field not set",
+			mask: []FieldSpec{{Path: "IPAddress", Optional: true}},
+			want: &composite.ForwardingRule{},
+			have: &composite.ForwardingRule{IPAddress: "1.2.3.4"},
+		},
+		{
+			desc: "optional field set",
+			mask: []FieldSpec{{Path: "IPAddress", Optional: true}},
+			want: &composite.ForwardingRule{IPAddress: "1.2.3.5"},
+			have: &composite.ForwardingRule{IPAddress: "1.2.3.4"},
+			diff: []string{"IPAddress"},
+		},
+		{
+			desc: "elements compared through their mask",
+			mask: []FieldSpec{{Path: "HostRules", Elem: []FieldSpec{{Path: "Hosts"}, {Path: "PathMatcher"}}}},
+			want: &composite.UrlMap{HostRules: []*composite.HostRule{hostRule("a.com")}},
+			have: &composite.UrlMap{HostRules: []*composite.HostRule{{Hosts: []string{"a.com"}, PathMatcher: "pm", Description: "foo"}}},
+		},
+		{
+			desc: "element differs",
+			mask: []FieldSpec{{Path: "HostRules", Elem: []FieldSpec{{Path: "Hosts"}, {Path: "PathMatcher"}}}},
+			want: &composite.UrlMap{HostRules: []*composite.HostRule{hostRule("a.com"), hostRule("b.com")}},
+			have: &composite.UrlMap{HostRules: []*composite.HostRule{hostRule("b.com"), hostRule("a.com")}},
+			diff: []string{"HostRules"},
+		},
+		{
+			desc: "number of elements differs",
+			mask: []FieldSpec{{Path: "HostRules", Elem: []FieldSpec{{Path: "Hosts"}, {Path: "PathMatcher"}}}},
+			want: &composite.UrlMap{HostRules: []*composite.HostRule{hostRule("a.com")}},
+			have: &composite.UrlMap{},
+			diff: []string{"HostRules"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			diff := DiffFields(tc.want, tc.have, tc.mask)
+			if !reflect.DeepEqual(diff, tc.diff) {
+				t.Errorf("DiffFields() = %v, want %v", diff, tc.diff)
+			}
+		})
+	}
+}
+
+func TestDiffFieldsInvalidPath(t *testing.T) {
+	for _, f := range []FieldSpec{
+		{Path: "NoSuchField"},
+		{Path: "TimeoutSec.Foo"},
+		{Path: "TimeoutSec", ServerDefault: 30},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("DiffFields() with %+v did not panic", f)
+				}
+			}()
+			DiffFields(&composite.BackendService{}, &composite.BackendService{}, []FieldSpec{f})
+		}()
+	}
+}
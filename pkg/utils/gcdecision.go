@@ -0,0 +1,43 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import "fmt"
+
+// GCDecision explains whether garbage collection retains or deletes a GCE
+// resource.
+type GCDecision struct {
+	// Kind is the kind of the resource, e.g. BackendService.
+	Kind string
+	// Name is the name of the resource.
+	Name string
+	// Scope is the scope of the resource, e.g. global.
+	Scope string
+	// Evidence is the ownership evidence the decision is based on.
+	Evidence string
+	// Delete is true if the resource is deleted.
+	Delete bool
+}
+
+// String implements fmt.Stringer.
+func (d GCDecision) String() string {
+	action := "retain"
+	if d.Delete {
+		action = "delete"
+	}
+	return fmt.Sprintf("%s %s %s (%s): %s", action, d.Kind, d.Name, d.Scope, d.Evidence)
+}
@@ -40,6 +40,9 @@ const (
 	urlMapPrefixV2 = "um"
 	// urlMapPrefixV2 is Https-Redirect-Only URL map prefix for v2 naming scheme.
 	redirectUrlMapPrefixV2 = "rm"
+	// swapUrlMapPrefixV2 is the prefix of the URL map which alternates with
+	// the URL map when URL maps are swapped, for v2 naming scheme.
+	swapUrlMapPrefixV2 = "sm"
 	// forwardingRulePrefixV2 is http forwarding rule prefix for v2 naming scheme.
 	forwardingRulePrefixV2 = "fr"
 	// httpsForwardingRulePrefixV2 is https forwarding rule prefix for v2 naming scheme.
@@ -95,6 +98,11 @@ func (ln *V1IngressFrontendNamer) RedirectUrlMap() (string, bool) {
 	return "", false
 }
 
+// SwapUrlMap implements IngressFrontendNamer.
+func (ln *V1IngressFrontendNamer) SwapUrlMap() (string, bool) {
+	return "", false
+}
+
 // SSLCertName implements IngressFrontendNamer.
 func (ln *V1IngressFrontendNamer) SSLCertName(secretHash string) string {
 	return ln.namer.SSLCertName(ln.lbName, secretHash)
@@ -193,6 +201,12 @@ func (vn *V2IngressFrontendNamer) RedirectUrlMap() (string, bool) {
 	return fmt.Sprintf("%s%s-%s-%s", vn.prefix, schemaVersionV2, redirectUrlMapPrefixV2, vn.lbName), true
 }
 
+// SwapUrlMap returns the name of the URL map which alternates with the URL
+// map when URL maps are swapped.
+func (vn *V2IngressFrontendNamer) SwapUrlMap() (string, bool) {
+	return fmt.Sprintf("%s%s-%s-%s", vn.prefix, schemaVersionV2, swapUrlMapPrefixV2, vn.lbName), true
+}
+
 // SSLCertName returns the name of the certificate.
 func (vn *V2IngressFrontendNamer) SSLCertName(secretHash string) string {
 	return fmt.Sprintf("%s%s-%s-%s-%s-%s", vn.prefix, schemaVersionV2, sslCertPrefixV2, vn.clusterUID, vn.lbNameToHash(), secretHash)
@@ -234,6 +248,27 @@ func (vn *V2IngressFrontendNamer) lbNameToHash() string {
 	return common.ContentHash(vn.lbName.String(), 16)
 }
 
+// V2FrontendNameComponents are the components of a frontend resource name
+// generated by the v2 naming scheme.
+type V2FrontendNameComponents struct {
+	// Resource is the resource prefix, e.g. "um" for URL maps.
+	Resource string
+	// Remainder is the rest of the name. For all resources but SSL
+	// certificates, this is the load balancer name.
+	Remainder string
+}
+
+// ParseV2FrontendName parses the name of a frontend resource generated by the
+// v2 naming scheme with the given prefix. It returns false if the name was not
+// generated by the v2 naming scheme.
+func ParseV2FrontendName(prefix, name string) (*V2FrontendNameComponents, bool) {
+	c := strings.SplitN(name, "-", 3)
+	if len(c) != 3 || c[0] != prefix+schemaVersionV2 || len(c[1]) != 2 || c[2] == "" {
+		return nil, false
+	}
+	return &V2FrontendNameComponents{Resource: c[1], Remainder: c[2]}, true
+}
+
 // FrontendNamerFactory implements IngressFrontendNamerFactory.
 type FrontendNamerFactory struct {
 	namer *Namer
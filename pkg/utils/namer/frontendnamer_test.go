@@ -19,6 +19,7 @@ package namer
 import (
 	"crypto/sha256"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -212,6 +213,9 @@ func TestV1IngressFrontendNamer(t *testing.T) {
 				if diff := cmp.Diff(tc.urlMap, urlMapName); diff != "" {
 					t.Errorf("namer.UrlMap() mismatch (-want +got):\n%s", diff)
 				}
+				if _, supported := namer.SwapUrlMap(); supported {
+					t.Errorf("namer.SwapUrlMap() is supported by the v1 naming scheme, want unsupported")
+				}
 				if gotIsValidName := namer.IsValidLoadBalancer(); gotIsValidName != tc.isValidName {
 					t.Errorf("IsValidLoadBalancer(%s) = %t, want %t", key, gotIsValidName, tc.isValidName)
 				}
@@ -433,6 +437,10 @@ func TestV2IngressFrontendNamer(t *testing.T) {
 				if diff := cmp.Diff(tc.urlMap, name); diff != "" {
 					t.Errorf("namer.UrlMap() mismatch (-want +got):\n%s", diff)
 				}
+				name, _ = namer.SwapUrlMap()
+				if diff := cmp.Diff(strings.Replace(tc.urlMap, "2-um-", "2-sm-", 1), name); diff != "" {
+					t.Errorf("namer.SwapUrlMap() mismatch (-want +got):\n%s", diff)
+				}
 				if gotIsValidName := namer.IsValidLoadBalancer(); gotIsValidName != tc.isValidName {
 					t.Errorf("namer.IsValidLoadBalancer() = %t, want %t", gotIsValidName, tc.isValidName)
 				}
@@ -440,3 +448,44 @@ func TestV2IngressFrontendNamer(t *testing.T) {
 		}
 	}
 }
+
+func TestParseV2FrontendName(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		want   *V2FrontendNameComponents
+		wantOk bool
+	}{
+		{
+			name:   "k8s2-um-7kpbhpki-namespace-name-uhmwf5xi",
+			want:   &V2FrontendNameComponents{Resource: "um", Remainder: "7kpbhpki-namespace-name-uhmwf5xi"},
+			wantOk: true,
+		},
+		{
+			name:   "k8s2-cr-7kpbhpki-lb0hash0123456-secrethash",
+			want:   &V2FrontendNameComponents{Resource: "cr", Remainder: "7kpbhpki-lb0hash0123456-secrethash"},
+			wantOk: true,
+		},
+		{
+			// L4 forwarding rule.
+			name: "k8s2-tcp-7kpbhpki-namespace-name-uhmwf5xi",
+		},
+		{
+			// v1 naming scheme.
+			name: "k8s-um-namespace-name--uid1",
+		},
+		{
+			name: "xyz2-um-7kpbhpki-namespace-name-uhmwf5xi",
+		},
+		{
+			name: "k8s2-um",
+		},
+	} {
+		got, gotOk := ParseV2FrontendName("k8s", tc.name)
+		if gotOk != tc.wantOk {
+			t.Errorf("ParseV2FrontendName(k8s, %q) = _, %t, want %t", tc.name, gotOk, tc.wantOk)
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ParseV2FrontendName(k8s, %q) mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+}
@@ -25,6 +25,9 @@ type IngressFrontendNamer interface {
 	UrlMap() string
 	// RedirectUrlMap returns the name of the URL Map and if the namer supports naming redirectUrlMap
 	RedirectUrlMap() (string, bool)
+	// SwapUrlMap returns the name of the URL Map which alternates with UrlMap
+	// when URL maps are swapped, and if the namer supports naming it.
+	SwapUrlMap() (string, bool)
 	// SSLCertName returns the SSL certificate name given secret hash.
 	SSLCertName(secretHash string) string
 	// IsCertNameForLB returns true if certName belongs to this ingress.
@@ -62,11 +65,21 @@ type BackendNamer interface {
 	VMIPNEG(namespace, name string) (string, bool)
 	// InstanceGroup constructs the name for an Instance Group.
 	InstanceGroup() string
+	// BackendWithConfig constructs the name for a backend service which
+	// serves the same backends as the backend service named beName with the
+	// settings of the given BackendConfig.
+	BackendWithConfig(beName, backendConfig string) string
+	// SharedHealthCheck constructs the name for a health check shared by
+	// backend services, given the hash of its settings.
+	SharedHealthCheck(hash string) string
 	// NamedPort returns the name for a named port.
 	NamedPort(port int64) string
 	// NameBelongsToCluster checks if a given backend resource name is tagged with
 	// this cluster's UID.
 	NameBelongsToCluster(resourceName string) bool
+	// NamePrefix returns the prefix shared by the names of all resources of
+	// this cluster.
+	NamePrefix() string
 }
 
 // V1FrontendNamer wraps frontend naming policy helper functions of namer.Namer.
@@ -78,6 +91,9 @@ type V1FrontendNamer interface {
 	// NameBelongsToCluster checks if a given frontend resource name is tagged with
 	// this cluster's UID.
 	NameBelongsToCluster(resourceName string) bool
+	// NamePrefix returns the prefix shared by the names of all resources of
+	// this cluster.
+	NamePrefix() string
 }
 
 // L4ResourcesNamer is an interface to name L4 LoadBalancing resources.
@@ -50,6 +50,9 @@ const (
 	// Prefix used for instance groups involved in L7 balancing.
 	igPrefix = "ig"
 
+	// Prefix used for health checks shared by backend services.
+	sharedHealthCheckPrefix = "shc"
+
 	// Suffix used in the l7 firewall rule. There is currently only one.
 	// Note that this name is used by the cloudprovider lib that inserts
 	// its own k8s-fw prefix.
@@ -256,6 +259,12 @@ func (n *Namer) ParseName(name string) *NameComponents {
 	}
 }
 
+// NamePrefix returns the prefix shared by the names of all resources of this
+// cluster, which can be used to filter lists of GCE resources.
+func (n *Namer) NamePrefix() string {
+	return n.prefix
+}
+
 // NameBelongsToCluster checks if a given name is tagged with this
 // cluster's UID.
 func (n *Namer) NameBelongsToCluster(name string) bool {
@@ -317,6 +326,30 @@ func (n *Namer) IGBackendPort(beName string) (string, error) {
 	return match[1], nil
 }
 
+// BackendWithConfig constructs the name for a backend service which serves
+// the same backends as the backend service named beName with the settings of
+// the given BackendConfig. The name follows the naming scheme of beName, so
+// it is recognized as a resource of this cluster.
+func (n *Namer) BackendWithConfig(beName, backendConfig string) string {
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(beName+";"+backendConfig)))[:8]
+	if n.IsNEG(beName) {
+		// NEG names are at most 63 characters, make room for the hash.
+		if len(beName) > nameLenLimit+1-len(hash)-1 {
+			beName = beName[:nameLenLimit+1-len(hash)-1]
+		}
+		return fmt.Sprintf("%s-%s", strings.TrimRight(beName, "-"), hash)
+	}
+	// Insert the hash before the cluster UID.
+	base := strings.SplitN(beName, clusterNameDelimiter, 2)[0]
+	return n.decorateName(fmt.Sprintf("%s-%s", base, hash))
+}
+
+// SharedHealthCheck constructs the name for a health check shared by backend
+// services. The hash identifies the settings of the health check.
+func (n *Namer) SharedHealthCheck(hash string) string {
+	return n.decorateName(fmt.Sprintf("%v-%v-%v", n.prefix, sharedHealthCheckPrefix, hash))
+}
+
 // InstanceGroup constructs the name for an Instance Group.
 func (n *Namer) InstanceGroup() string {
 	return n.decorateName(n.prefix + "-" + igPrefix)
@@ -281,6 +281,43 @@ func TestNamerInstanceGroup(t *testing.T) {
 	}
 }
 
+func TestNamerBackendWithConfig(t *testing.T) {
+	newNamer := NewNamer("uid1", "fw1")
+	for _, beName := range []string{
+		newNamer.IGBackend(30001),
+		newNamer.NEG("namespace", "name", 80),
+		newNamer.NEG(longString, longString, 80),
+	} {
+		name := newNamer.BackendWithConfig(beName, "config")
+		if name == beName || name == newNamer.BackendWithConfig(beName, "other") {
+			t.Errorf("newNamer.BackendWithConfig(%q, _) = %q, want distinct names per BackendConfig", beName, name)
+		}
+		if len(name) > nameLenLimit+1 {
+			t.Errorf("newNamer.BackendWithConfig(%q, _) = %q, longer than %d characters", beName, name, nameLenLimit+1)
+		}
+		if !newNamer.NameBelongsToCluster(name) {
+			t.Errorf("newNamer.NameBelongsToCluster(%q) = false, want true", name)
+		}
+		if newNamer.IsNEG(name) != newNamer.IsNEG(beName) {
+			t.Errorf("newNamer.IsNEG(%q) = %v, want %v", name, newNamer.IsNEG(name), newNamer.IsNEG(beName))
+		}
+	}
+	if port, err := newNamer.IGBackendPort(newNamer.BackendWithConfig(newNamer.IGBackend(30001), "config")); err != nil || port != "30001" {
+		t.Errorf("newNamer.IGBackendPort() = %q, %v, want %q, nil", port, err, "30001")
+	}
+}
+
+func TestNamerSharedHealthCheck(t *testing.T) {
+	newNamer := NewNamer("uid1", "fw1")
+	name := newNamer.SharedHealthCheck("0123456789abcdef")
+	if want := "k8s-shc-0123456789abcdef--uid1"; name != want {
+		t.Errorf("newNamer.SharedHealthCheck() = %q, want %q", name, want)
+	}
+	if !newNamer.NameBelongsToCluster(name) {
+		t.Errorf("newNamer.NameBelongsToCluster(%q) = false, want true", name)
+	}
+}
+
 func TestNamerFirewallRule(t *testing.T) {
 	newNamer := NewNamer("uid1", "fw1")
 	name := newNamer.FirewallRule()
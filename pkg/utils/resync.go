@@ -0,0 +1,36 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// ResyncDelay returns the delay of the periodic resync of the given key, so
+// that the resyncs of all keys are spread uniformly over the spread window
+// instead of being enqueued at once. The delay of a key is derived from its
+// hash and thus stable across resyncs, so every key is still synced once per
+// resync period. A spread of zero disables spreading.
+func ResyncDelay(key string, spread time.Duration) time.Duration {
+	if spread <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return time.Duration(h.Sum64() % uint64(spread))
+}
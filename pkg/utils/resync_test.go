@@ -0,0 +1,55 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestResyncDelay(t *testing.T) {
+	t.Parallel()
+
+	if got := ResyncDelay("ns/name", 0); got != 0 {
+		t.Errorf("ResyncDelay(_, 0) = %v, want 0", got)
+	}
+
+	const (
+		spread  = time.Minute
+		keys    = 1000
+		buckets = 10
+	)
+	counts := make([]int, buckets)
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("ns-%d/ing-%d", i%7, i)
+		delay := ResyncDelay(key, spread)
+		if delay < 0 || delay >= spread {
+			t.Fatalf("ResyncDelay(%q, %v) = %v, want in [0, %v)", key, spread, delay, spread)
+		}
+		if again := ResyncDelay(key, spread); again != delay {
+			t.Fatalf("ResyncDelay(%q, %v) = %v, then %v, want a stable delay", key, spread, delay, again)
+		}
+		counts[delay*buckets/spread]++
+	}
+	// Keys are spread uniformly: every bucket holds roughly keys/buckets keys.
+	for i, c := range counts {
+		if c < keys/buckets/2 || c > keys/buckets*2 {
+			t.Errorf("%d keys delayed within bucket %d of %v, want about %d (counts %v)", c, i, spread/buckets, keys/buckets, counts)
+		}
+	}
+}
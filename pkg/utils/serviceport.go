@@ -17,22 +17,32 @@ limitations under the License.
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 
 	v1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/ingress-gce/pkg/annotations"
 	backendconfigv1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1"
+	"k8s.io/ingress-gce/pkg/utils/common"
 	"k8s.io/ingress-gce/pkg/utils/namer"
+	"k8s.io/klog"
 )
 
 // ServicePortID contains the Service and Port fields.
 type ServicePortID struct {
 	Service types.NamespacedName
 	Port    v1.ServiceBackendPort
+	// BackendConfig is the name of a BackendConfig which overrides the
+	// BackendConfig of the Service port for some paths of an Ingress. Empty
+	// if none.
+	BackendConfig string
 }
 
 func (id ServicePortID) String() string {
+	if id.BackendConfig != "" {
+		return fmt.Sprintf("%v/%v/%v", id.Service.String(), id.Port.String(), id.BackendConfig)
+	}
 	return fmt.Sprintf("%v/%v", id.Service.String(), id.Port.String())
 }
 
@@ -49,27 +59,60 @@ type ServicePort struct {
 	NEGEnabled     bool
 	VMIPNEGEnabled bool
 	L7ILBEnabled   bool
-	BackendConfig  *backendconfigv1.BackendConfig
-	BackendNamer   namer.BackendNamer
+	// DefaultTimeoutSec is the timeout of the backend service when it is
+	// created. Zero means the GCE default. It is overridden by the timeout
+	// of the BackendConfig, if any.
+	DefaultTimeoutSec int64
+	BackendConfig     *backendconfigv1.BackendConfig
+	BackendNamer      namer.BackendNamer
 }
 
 // GetDescription returns a Description for this ServicePort.
 func (sp ServicePort) GetDescription() Description {
-	return Description{
+	desc := Description{
 		ServiceName: sp.ID.Service.String(),
 		ServicePort: sp.ID.Port.String(),
 	}
+	if sp.BackendConfig != nil {
+		desc.BackendConfig = fmt.Sprintf("%s/%s", sp.BackendConfig.Namespace, sp.BackendConfig.Name)
+		desc.BackendConfigHash = backendConfigHash(sp.BackendConfig)
+	}
+	return desc
+}
+
+// backendConfigHash returns a hash of the spec of the given BackendConfig.
+func backendConfigHash(backendConfig *backendconfigv1.BackendConfig) string {
+	spec, err := json.Marshal(backendConfig.Spec)
+	if err != nil {
+		klog.Errorf("Failed to marshal the spec of BackendConfig %s/%s: %v", backendConfig.Namespace, backendConfig.Name, err)
+		return ""
+	}
+	return common.ContentHash(string(spec), 8)
 }
 
 // BackendName returns the name of the backend which would be used for this ServicePort.
+// The backend of a ServicePort with a BackendConfig override is distinct from
+// the backend of the Service port.
 func (sp ServicePort) BackendName() string {
-	if sp.NEGEnabled {
-		return sp.BackendNamer.NEG(sp.ID.Service.Namespace, sp.ID.Service.Name, sp.Port)
-	} else if sp.VMIPNEGEnabled {
+	var name string
+	if sp.NEGEnabled || sp.VMIPNEGEnabled {
+		name = sp.NEGName()
+	} else {
+		name = sp.BackendNamer.IGBackend(sp.NodePort)
+	}
+	if sp.ID.BackendConfig != "" {
+		return sp.BackendNamer.BackendWithConfig(name, sp.ID.BackendConfig)
+	}
+	return name
+}
+
+// NEGName returns the name of the NEGs of this ServicePort, if NEG is enabled.
+func (sp ServicePort) NEGName() string {
+	if !sp.NEGEnabled && sp.VMIPNEGEnabled {
 		negName, _ := sp.BackendNamer.VMIPNEG(sp.ID.Service.Namespace, sp.ID.Service.Name)
 		return negName
 	}
-	return sp.BackendNamer.IGBackend(sp.NodePort)
+	return sp.BackendNamer.NEG(sp.ID.Service.Namespace, sp.ID.Service.Name, sp.Port)
 }
 
 // IGName returns the name of the instance group which would be used for this ServicePort.
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// WithSyncDeadline returns a sync function which calls syncFn with a context
+// that expires once the sync of a key has been running for deadline, so that
+// a hung GCE operation does not block a worker indefinitely. A deadline of
+// zero disables it.
+//
+// syncFn passes the context to its GCE calls, so that a call made through the
+// composite types, including the wait for its operation, is cancelled at the
+// deadline. Calls through the legacy cloud provider, e.g. to instance groups
+// and firewalls, cannot be cancelled. syncFn also checks the context with
+// CheckSyncDeadline between its steps. Either way the error is returned, which
+// requeues the key. onExceeded, if not nil, is called with the key when a sync
+// is aborted.
+func WithSyncDeadline(resource string, deadline time.Duration, syncFn func(context.Context, string) error, onExceeded func(string)) func(string) error {
+	return func(key string) error {
+		if deadline <= 0 {
+			return syncFn(context.Background(), key)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), deadline)
+		defer cancel()
+		start := time.Now()
+		err := syncFn(ctx, key)
+		if ctx.Err() == nil {
+			return err
+		}
+		if err == nil {
+			klog.Warningf("Sync of %q (%s) returned after %v, exceeding deadline of %v", key, resource, time.Since(start), deadline)
+			return nil
+		}
+		klog.Errorf("Sync of %q (%s) aborted after %v, exceeding deadline of %v, requeuing: %v", key, resource, time.Since(start), deadline, err)
+		if onExceeded != nil {
+			onExceeded(key)
+		}
+		return err
+	}
+}
+
+// CheckSyncDeadline returns an error if the deadline of the sync with the
+// given context has passed. Syncs call it between GCE operations, so that a
+// sync exceeding its deadline is aborted at the next step.
+func CheckSyncDeadline(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("sync aborted: %w", err)
+	}
+	return nil
+}
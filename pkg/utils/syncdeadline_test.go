@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithSyncDeadline(t *testing.T) {
+	t.Parallel()
+	syncErr := errors.New("sync error")
+	syncFn := func(ctx context.Context, key string) error {
+		switch key {
+		case "hung":
+			// A sync which is blocked past its deadline is aborted at
+			// the next step.
+			<-ctx.Done()
+			return CheckSyncDeadline(ctx)
+		case "slow":
+			// A sync which completes past its deadline is not requeued.
+			<-ctx.Done()
+			return nil
+		case "error":
+			return syncErr
+		}
+		return CheckSyncDeadline(ctx)
+	}
+	var exceeded []string
+	syncWithDeadline := WithSyncDeadline("test", 50*time.Millisecond, syncFn, func(key string) {
+		exceeded = append(exceeded, key)
+	})
+
+	if err := syncWithDeadline("ok"); err != nil {
+		t.Errorf("sync(ok) = %v, want nil", err)
+	}
+	if err := syncWithDeadline("error"); err != syncErr {
+		t.Errorf("sync(error) = %v, want %v", err, syncErr)
+	}
+	if err := syncWithDeadline("hung"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("sync(hung) = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := syncWithDeadline("slow"); err != nil {
+		t.Errorf("sync(slow) = %v, want nil", err)
+	}
+	if len(exceeded) != 1 || exceeded[0] != "hung" {
+		t.Errorf("Keys which exceeded the deadline = %v, want [hung]", exceeded)
+	}
+}
+
+func TestWithSyncDeadlineDisabled(t *testing.T) {
+	t.Parallel()
+	called := false
+	syncWithDeadline := WithSyncDeadline("test", 0, func(ctx context.Context, key string) error {
+		called = true
+		if _, ok := ctx.Deadline(); ok {
+			t.Errorf("Context of sync(%s) has a deadline, want none", key)
+		}
+		return nil
+	}, nil)
+	if err := syncWithDeadline("key"); err != nil || !called {
+		t.Errorf("sync(key) = %v, called = %t, want nil, true", err, called)
+	}
+}
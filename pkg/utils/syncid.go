@@ -0,0 +1,39 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"k8s.io/klog"
+)
+
+// syncIDBytes is the number of random bytes of a sync ID.
+const syncIDBytes = 6
+
+// NewSyncID returns a random ID for a controller sync. The ID is included in
+// the logs and events of the sync and in the descriptions of the GCE
+// resources it writes, to trace a change of a resource back to its sync.
+func NewSyncID() string {
+	b := make([]byte, syncIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		klog.Errorf("Failed to generate sync ID: %v", err)
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
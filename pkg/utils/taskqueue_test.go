@@ -144,3 +144,28 @@ func TestPeriodicQueueWithMultipleWorkers(t *testing.T) {
 		})
 	}
 }
+
+func TestEnqueueAfter(t *testing.T) {
+	t.Parallel()
+
+	synced := make(chan string, 2)
+	tq := NewPeriodicTaskQueue("", "test", func(key string) error {
+		synced <- key
+		return nil
+	})
+	go tq.Run()
+	defer tq.Shutdown()
+
+	start := time.Now()
+	tq.EnqueueAfter(cache.ExplicitKey("later"), 200*time.Millisecond)
+	tq.EnqueueAfter(cache.ExplicitKey("now"), 0)
+	if got := <-synced; got != "now" {
+		t.Errorf("First synced key = %q, want %q", got, "now")
+	}
+	if got := <-synced; got != "later" {
+		t.Errorf("Second synced key = %q, want %q", got, "later")
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Delayed key synced after %v, want at least 200ms", elapsed)
+	}
+}
@@ -308,6 +308,8 @@ func IsGCEIngress(ing *networkingv1.Ingress) bool {
 		return true
 	case annotations.GceL7ILBIngressClass:
 		return true
+	case annotations.GceSSLProxyIngressClass:
+		return true
 	default:
 		return false
 	}
@@ -327,6 +329,19 @@ func IsGCEL7ILBIngress(ing *networkingv1.Ingress) bool {
 	return class == annotations.GceL7ILBIngressClass
 }
 
+// IsGCESSLProxyIngress returns true if the given Ingress has
+// ingress.class annotation set to "gce-ssl-proxy".
+func IsGCESSLProxyIngress(ing *networkingv1.Ingress) bool {
+	class := annotations.FromIngress(ing).IngressClass()
+	return class == annotations.GceSSLProxyIngressClass
+}
+
+// IsRegionalIngress returns true if the load balancer resources for the given
+// Ingress are regional rather than global. Only L7-ILB Ingresses are.
+func IsRegionalIngress(ing *networkingv1.Ingress) bool {
+	return IsGCEL7ILBIngress(ing)
+}
+
 // IsGLBCIngress returns true if the given Ingress should be processed by GLBC
 func IsGLBCIngress(ing *networkingv1.Ingress) bool {
 	return IsGCEIngress(ing) || IsGCEMultiClusterIngress(ing)
@@ -358,6 +373,15 @@ func NodeIsReady(node *api_v1.Node) bool {
 	return false
 }
 
+// NodeOS returns the operating system of the node as set in its
+// kubernetes.io/os label. Nodes without the label are assumed to run Linux.
+func NodeOS(node *api_v1.Node) string {
+	if os, ok := node.Labels[api_v1.LabelOSStable]; ok && os != "" {
+		return os
+	}
+	return "linux"
+}
+
 // NodeConditionPredicate is a function that indicates whether the given node's conditions meet
 // some set of criteria defined by the function.
 type NodeConditionPredicate func(node *api_v1.Node) bool
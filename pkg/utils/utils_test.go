@@ -597,6 +597,16 @@ func TestIsGCEIngress(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			desc: "SSL proxy ingress class",
+			ingress: &networkingv1.Ingress{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						annotations.IngressClassKey: annotations.GceSSLProxyIngressClass},
+				},
+			},
+			expected: true,
+		},
 		{
 			desc: "Set by flag with non-matching class",
 			ingress: &networkingv1.Ingress{
@@ -714,6 +724,41 @@ func TestIsGCEL7ILBIngress(t *testing.T) {
 	}
 }
 
+func TestIsRegionalIngress(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc     string
+		class    string
+		expected bool
+	}{
+		{
+			desc:     "No ingress class",
+			expected: false,
+		},
+		{
+			desc:     "gce ingress class",
+			class:    annotations.GceIngressClass,
+			expected: false,
+		},
+		{
+			desc:     "L7 ILB ingress class",
+			class:    annotations.GceL7ILBIngressClass,
+			expected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ing := &networkingv1.Ingress{}
+			if tc.class != "" {
+				ing.Annotations = map[string]string{annotations.IngressClassKey: tc.class}
+			}
+			if result := IsRegionalIngress(ing); result != tc.expected {
+				t.Fatalf("IsRegionalIngress() = %v, want %v", result, tc.expected)
+			}
+		})
+	}
+}
+
 func TestNeedsCleanup(t *testing.T) {
 	testCases := []struct {
 		isGLBCIngress       bool